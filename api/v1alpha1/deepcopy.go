@@ -0,0 +1,351 @@
+package v1alpha1
+
+// Deep copy functions for the custom resource types; keep them in sync with
+// the type definitions when fields are added.
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto copies the receiver into out
+func (in *ScalingRecommendationSpec) DeepCopyInto(out *ScalingRecommendationSpec) {
+	*out = *in
+	if in.Ingresses != nil {
+		out.Ingresses = make([]string, len(in.Ingresses))
+		copy(out.Ingresses, in.Ingresses)
+	}
+	if in.Reasons != nil {
+		out.Reasons = make([]DecisionReason, len(in.Reasons))
+		for i := range in.Reasons {
+			in.Reasons[i].DeepCopyInto(&out.Reasons[i])
+		}
+	}
+	in.DecidedAt.DeepCopyInto(&out.DecidedAt)
+	if in.Approval != nil {
+		out.Approval = new(ApprovalRequest)
+		in.Approval.DeepCopyInto(out.Approval)
+	}
+	if in.LastAction != nil {
+		out.LastAction = new(ReplicaChange)
+		in.LastAction.DeepCopyInto(out.LastAction)
+	}
+}
+
+// DeepCopy creates a new ScalingRecommendationSpec
+func (in *ScalingRecommendationSpec) DeepCopy() *ScalingRecommendationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingRecommendationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ApprovalRequest) DeepCopyInto(out *ApprovalRequest) {
+	*out = *in
+	in.RequestedAt.DeepCopyInto(&out.RequestedAt)
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+	if in.ApprovedAt != nil {
+		out.ApprovedAt = in.ApprovedAt.DeepCopy()
+	}
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ReplicaChange) DeepCopyInto(out *ReplicaChange) {
+	*out = *in
+	in.AppliedAt.DeepCopyInto(&out.AppliedAt)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *DecisionReason) DeepCopyInto(out *DecisionReason) {
+	*out = *in
+	if in.Parameters != nil {
+		out.Parameters = make(map[string]string, len(in.Parameters))
+		for name, value := range in.Parameters {
+			out.Parameters[name] = value
+		}
+	}
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ScalingRecommendation) DeepCopyInto(out *ScalingRecommendation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy creates a new ScalingRecommendation
+func (in *ScalingRecommendation) DeepCopy() *ScalingRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *ScalingRecommendation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ScalingRecommendationList) DeepCopyInto(out *ScalingRecommendationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]ScalingRecommendation, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy creates a new ScalingRecommendationList
+func (in *ScalingRecommendationList) DeepCopy() *ScalingRecommendationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingRecommendationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *ScalingRecommendationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *HydraRoutePolicySpec) DeepCopyInto(out *HydraRoutePolicySpec) {
+	*out = *in
+	for _, field := range []struct{ in, out **int32 }{
+		{&in.MinReplicas, &out.MinReplicas},
+		{&in.MaxReplicas, &out.MaxReplicas},
+		{&in.TargetCPUUtilization, &out.TargetCPUUtilization},
+		{&in.TargetMemoryUtilization, &out.TargetMemoryUtilization},
+		{&in.TargetRequestsPerReplica, &out.TargetRequestsPerReplica},
+		{&in.TargetConcurrency, &out.TargetConcurrency},
+		{&in.TargetTokensPerSecond, &out.TargetTokensPerSecond},
+	} {
+		if *field.in != nil {
+			value := **field.in
+			*field.out = &value
+		}
+	}
+	if in.TimeToFirstTokenSLO != nil {
+		slo := *in.TimeToFirstTokenSLO
+		out.TimeToFirstTokenSLO = &slo
+	}
+	if in.DisabledFeatures != nil {
+		out.DisabledFeatures = append([]string(nil), in.DisabledFeatures...)
+	}
+	if in.MaintenanceWindows != nil {
+		out.MaintenanceWindows = make([]MaintenanceWindow, len(in.MaintenanceWindows))
+		for i, window := range in.MaintenanceWindows {
+			out.MaintenanceWindows[i] = window
+			out.MaintenanceWindows[i].Days = append([]string(nil), window.Days...)
+		}
+	}
+	if in.Behavior != nil {
+		out.Behavior = in.Behavior.DeepCopy()
+	}
+}
+
+// DeepCopy creates a new HydraRoutePolicySpec
+func (in *HydraRoutePolicySpec) DeepCopy() *HydraRoutePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HydraRoutePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *HydraRoutePolicyStatus) DeepCopyInto(out *HydraRoutePolicyStatus) {
+	*out = *in
+	if in.Services != nil {
+		out.Services = make([]string, len(in.Services))
+		copy(out.Services, in.Services)
+	}
+	if in.Conditions != nil {
+		out.Conditions = make([]metav1.Condition, len(in.Conditions))
+		for i := range in.Conditions {
+			in.Conditions[i].DeepCopyInto(&out.Conditions[i])
+		}
+	}
+	if in.Quarantined != nil {
+		out.Quarantined = make([]QuarantinedService, len(in.Quarantined))
+		for i := range in.Quarantined {
+			in.Quarantined[i].DeepCopyInto(&out.Quarantined[i])
+		}
+	}
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *QuarantinedService) DeepCopyInto(out *QuarantinedService) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+	in.Until.DeepCopyInto(&out.Until)
+}
+
+// DeepCopy creates a new HydraRoutePolicyStatus
+func (in *HydraRoutePolicyStatus) DeepCopy() *HydraRoutePolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HydraRoutePolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *HydraRoutePolicy) DeepCopyInto(out *HydraRoutePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy creates a new HydraRoutePolicy
+func (in *HydraRoutePolicy) DeepCopy() *HydraRoutePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HydraRoutePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *HydraRoutePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *HydraRoutePolicyList) DeepCopyInto(out *HydraRoutePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]HydraRoutePolicy, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy creates a new HydraRoutePolicyList
+func (in *HydraRoutePolicyList) DeepCopy() *HydraRoutePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(HydraRoutePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *HydraRoutePolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ScalingReportSpec) DeepCopyInto(out *ScalingReportSpec) {
+	*out = *in
+	in.PeriodStart.DeepCopyInto(&out.PeriodStart)
+	in.PeriodEnd.DeepCopyInto(&out.PeriodEnd)
+	if in.TopServices != nil {
+		out.TopServices = make([]ServiceActivity, len(in.TopServices))
+		copy(out.TopServices, in.TopServices)
+	}
+	if in.Clamped != nil {
+		out.Clamped = make([]ServiceClamping, len(in.Clamped))
+		copy(out.Clamped, in.Clamped)
+	}
+}
+
+// DeepCopy creates a new ScalingReportSpec
+func (in *ScalingReportSpec) DeepCopy() *ScalingReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ScalingReport) DeepCopyInto(out *ScalingReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy creates a new ScalingReport
+func (in *ScalingReport) DeepCopy() *ScalingReport {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *ScalingReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ScalingReportList) DeepCopyInto(out *ScalingReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]ScalingReport, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy creates a new ScalingReportList
+func (in *ScalingReportList) DeepCopy() *ScalingReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *ScalingReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
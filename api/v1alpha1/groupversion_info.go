@@ -0,0 +1,18 @@
+// Package v1alpha1 contains the custom resource types of the hydra-route.ai API group
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is the group and version of the hydra-route custom resources
+	GroupVersion = schema.GroupVersion{Group: "hydra-route.ai", Version: "v1alpha1"}
+
+	// SchemeBuilder registers the custom resource types with a scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the custom resource types to a scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
@@ -0,0 +1,169 @@
+package v1alpha1
+
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types reported on HydraRoutePolicy status
+const (
+	// ConditionReady is true when the policy's services are being scaled normally
+	ConditionReady = "Ready"
+
+	// ConditionMetricsAvailable is true when fresh metrics exist for every service
+	ConditionMetricsAvailable = "MetricsAvailable"
+
+	// ConditionModelTrained is true when the active model has been trained;
+	// otherwise decisions come from the heuristic fallback
+	ConditionModelTrained = "ModelTrained"
+
+	// ConditionActuationHealthy is true when the latest scaling actions succeeded
+	ConditionActuationHealthy = "ActuationHealthy"
+
+	// ConditionInCooldown is true when a service is in its post-scaling cooldown
+	ConditionInCooldown = "InCooldown"
+
+	// ConditionSpecOwnershipConflict is true when another actor changed the
+	// replicas the controller applied to a service
+	ConditionSpecOwnershipConflict = "SpecOwnershipConflict"
+
+	// ConditionCrashLooping is true when pods of a service are crash-looping;
+	// scale-downs of those services are blocked until they recover
+	ConditionCrashLooping = "CrashLooping"
+
+	// ConditionModelStale is true when no training of the model succeeded
+	// within scaling.ai_model.freshness_sla
+	ConditionModelStale = "ModelStale"
+
+	// ConditionControllerDegraded is true while the controller throttles
+	// itself because it nears its own resource limits
+	ConditionControllerDegraded = "ControllerDegraded"
+)
+
+// HydraRoutePolicySpec defines the ingress or service a policy applies to and
+// the per-service scaling settings that override the controller configuration
+type HydraRoutePolicySpec struct {
+	// Name of the ingress, in the policy's namespace, whose backend services the policy covers
+	IngressName string `json:"ingressName,omitempty"`
+
+	// Name of a service, in the policy's namespace, scaled without any
+	// ingress routing to it; the policy enables HydraRoute for it. Exactly
+	// one of ingressName and serviceName is set.
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// Minimum number of replicas
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// Maximum number of replicas
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+
+	// CPU utilization (percent of requests) above which the service needs more replicas
+	TargetCPUUtilization *int32 `json:"targetCPUUtilization,omitempty"`
+
+	// Memory utilization (percent of requests) above which the service needs more replicas
+	TargetMemoryUtilization *int32 `json:"targetMemoryUtilization,omitempty"`
+
+	// Requests per second one replica serves at its target load, which turns
+	// capacity targets into replicas in the absolute target mode
+	TargetRequestsPerReplica *int32 `json:"targetRequestsPerReplica,omitempty"`
+
+	// Requests in flight per replica in the concurrency target mode
+	TargetConcurrency *int32 `json:"targetConcurrency,omitempty"`
+
+	// Generated tokens per second one replica of an LLM endpoint serves
+	TargetTokensPerSecond *int32 `json:"targetTokensPerSecond,omitempty"`
+
+	// 95th percentile time to first token an LLM endpoint should hold
+	TimeToFirstTokenSLO *metav1.Duration `json:"timeToFirstTokenSLO,omitempty"`
+
+	// Scale factors of the strongest scale-down and scale-up predictions,
+	// as decimals such as "0.8" and "1.5"; a narrower envelope scales more
+	// conservatively, a wider one more aggressively
+	MinScaleFactor string `json:"minScaleFactor,omitempty"`
+	MaxScaleFactor string `json:"maxScaleFactor,omitempty"`
+
+	// Model features zeroed for the policy's services, in addition to
+	// scaling.ai_model.disabled_features, e.g. io_bandwidth for stateless
+	// services
+	DisabledFeatures []string `json:"disabledFeatures,omitempty"`
+
+	// Planned downtime of the policy's services, whose samples and outcomes
+	// are left out of training and prediction accuracy
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// Scale-up and scale-down behavior with the semantics of the HPA v2
+	// behavior field, so blocks tuned for an HPA can be copied verbatim
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+}
+
+// MaintenanceWindow is a planned downtime: once, from an RFC 3339 start, or
+// every day or listed weekday, from a wall-clock start
+type MaintenanceWindow struct {
+	// RFC 3339 time of a one-off window, or HH:MM of a recurring one
+	Start string `json:"start"`
+
+	// Weekdays (Monday or Mon) a recurring window opens on; empty is every day
+	Days []string `json:"days,omitempty"`
+
+	// How long the window lasts
+	Duration metav1.Duration `json:"duration"`
+
+	// IANA time zone of a recurring start; UTC when empty
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// HydraRoutePolicyStatus reports the health of the policy's services
+type HydraRoutePolicyStatus struct {
+	// Generation of the spec the status was computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Backend services covered by the policy
+	Services []string `json:"services,omitempty"`
+
+	// Standard conditions: Ready, MetricsAvailable, ModelTrained, ActuationHealthy,
+	// InCooldown and SpecOwnershipConflict
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Services left out of collection and evaluation after exhausting their
+	// error budget
+	Quarantined []QuarantinedService `json:"quarantined,omitempty"`
+}
+
+// QuarantinedService is a service quarantined after too many of its
+// evaluations failed
+type QuarantinedService struct {
+	// Name of the service
+	Name string `json:"name"`
+
+	// Failed evaluations within general.quarantine.window
+	Failures int32 `json:"failures"`
+
+	// Error of the latest failed evaluation
+	LastError string `json:"lastError,omitempty"`
+
+	// Start of the quarantine
+	Since metav1.Time `json:"since"`
+
+	// When the service is evaluated again as a probe
+	Until metav1.Time `json:"until"`
+}
+
+// HydraRoutePolicy attaches HydraRoute scaling to an ingress or a service and reports its health
+type HydraRoutePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HydraRoutePolicySpec   `json:"spec,omitempty"`
+	Status HydraRoutePolicyStatus `json:"status,omitempty"`
+}
+
+// HydraRoutePolicyList contains a list of HydraRoutePolicy
+type HydraRoutePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HydraRoutePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HydraRoutePolicy{}, &HydraRoutePolicyList{})
+}
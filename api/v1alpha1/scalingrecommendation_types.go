@@ -0,0 +1,111 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScalingRecommendationSpec records the latest scaling decision for a service
+type ScalingRecommendationSpec struct {
+	// Backend service the recommendation is for
+	ServiceName string `json:"serviceName"`
+
+	// Ingresses that route to the service
+	Ingresses []string `json:"ingresses,omitempty"`
+
+	// Replica count when the decision was made
+	CurrentReplicas int32 `json:"currentReplicas"`
+
+	// Replica count recommended by the controller
+	RecommendedReplicas int32 `json:"recommendedReplicas"`
+
+	// Model confidence as a decimal string, e.g. "0.85"
+	Confidence string `json:"confidence,omitempty"`
+
+	// Human-readable explanation of the decision, rendered from reasons
+	Reasoning string `json:"reasoning,omitempty"`
+
+	// Machine-readable reasons behind the decision
+	Reasons []DecisionReason `json:"reasons,omitempty"`
+
+	// Model version that produced the decision
+	ModelVersion string `json:"modelVersion,omitempty"`
+
+	// Estimated change in hourly cost from the replicas added or removed, as a
+	// decimal string in the currency of the configured rates
+	EstimatedCostPerHour string `json:"estimatedCostPerHour,omitempty"`
+
+	// Estimated change in emissions from the replicas added or removed, in
+	// grams of CO2e per hour, as a decimal string
+	EstimatedCarbonGramsPerHour string `json:"estimatedCarbonGramsPerHour,omitempty"`
+
+	// Time the decision was made
+	DecidedAt metav1.Time `json:"decidedAt"`
+
+	// Operator approval of a large replica change, when one was required
+	Approval *ApprovalRequest `json:"approval,omitempty"`
+
+	// Latest replica change the controller applied to the service; unlike
+	// the decision fields it only changes when the service is scaled
+	LastAction *ReplicaChange `json:"lastAction,omitempty"`
+}
+
+// ReplicaChange is a replica change applied to a service
+type ReplicaChange struct {
+	// Replica count before and after the change
+	FromReplicas int32 `json:"fromReplicas"`
+	ToReplicas   int32 `json:"toReplicas"`
+
+	// Time the change was applied
+	AppliedAt metav1.Time `json:"appliedAt"`
+}
+
+// Approval phases
+const (
+	ApprovalPending  = "Pending"
+	ApprovalApproved = "Approved"
+	ApprovalExpired  = "Expired"
+)
+
+// ApprovalRequest is a replica change held until an operator approves it
+type ApprovalRequest struct {
+	// Pending, Approved or Expired
+	Phase string `json:"phase"`
+
+	// Replica count the change would scale to, from the latest decision
+	Replicas int32 `json:"replicas"`
+
+	// Time approval was requested
+	RequestedAt metav1.Time `json:"requestedAt"`
+
+	// Time after which the request expires unless approved
+	ExpiresAt metav1.Time `json:"expiresAt"`
+
+	// Time the change was approved
+	ApprovedAt *metav1.Time `json:"approvedAt,omitempty"`
+}
+
+// DecisionReason is a reason code, such as CPU_HIGH, with its parameters,
+// such as value and threshold. Numeric parameters are decimal strings.
+type DecisionReason struct {
+	Code       string            `json:"code"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// ScalingRecommendation is the latest scaling recommendation for a service
+type ScalingRecommendation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ScalingRecommendationSpec `json:"spec,omitempty"`
+}
+
+// ScalingRecommendationList contains a list of ScalingRecommendation
+type ScalingRecommendationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScalingRecommendation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScalingRecommendation{}, &ScalingRecommendationList{})
+}
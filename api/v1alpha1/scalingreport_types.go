@@ -0,0 +1,93 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScalingReportSpec summarizes what the controller did over one period.
+// Fractional figures are decimal strings.
+type ScalingReportSpec struct {
+	// Period covered by the report
+	PeriodStart metav1.Time `json:"periodStart"`
+	PeriodEnd   metav1.Time `json:"periodEnd"`
+
+	// Services with collected metrics in the period
+	Services int32 `json:"services"`
+
+	// Scaling actions applied in the period
+	ScaleUps   int32 `json:"scaleUps"`
+	ScaleDowns int32 `json:"scaleDowns"`
+
+	// Scale-ups taken while the service met the SLO, after which its load
+	// grew into the added replicas and it kept meeting the SLO
+	SLOBreachesAvoided int32 `json:"sloBreachesAvoided"`
+
+	// Percentage of samples within the SLO response time and error rate
+	SLOAttainment string `json:"sloAttainment,omitempty"`
+
+	// Replica-hours below provisioning every service for its peak in the
+	// period, and their cost in the currency of the configured rates
+	ReplicaHoursSaved string `json:"replicaHoursSaved,omitempty"`
+	EstimatedSavings  string `json:"estimatedSavings,omitempty"`
+
+	// Active model version, and the mean absolute error of its scored
+	// predictions and their number
+	ModelVersion     string `json:"modelVersion,omitempty"`
+	ModelMAE         string `json:"modelMAE,omitempty"`
+	ModelPredictions int32  `json:"modelPredictions,omitempty"`
+
+	// Services with the most scaling actions, most first
+	TopServices []ServiceActivity `json:"topServices,omitempty"`
+
+	// Services whose recommendations were clamped by their limits, those
+	// most often clamped at max replicas first
+	Clamped []ServiceClamping `json:"clamped,omitempty"`
+}
+
+// ServiceActivity is the scaling activity of one service in a report period
+type ServiceActivity struct {
+	// "namespace/service"
+	Service    string `json:"service"`
+	ScaleUps   int32  `json:"scaleUps"`
+	ScaleDowns int32  `json:"scaleDowns"`
+
+	// Percentage of samples within the SLO
+	SLOAttainment string `json:"sloAttainment,omitempty"`
+}
+
+// ServiceClamping counts how often the limits of one service clamped its
+// recommendations in a report period. Persistent clamping at max replicas
+// means the service needs more capacity than its limit allows.
+type ServiceClamping struct {
+	// "namespace/service"
+	Service string `json:"service"`
+
+	// Evaluations in the period, and those clamped by the min replicas, the
+	// max replicas and the step limits of the HPA behavior
+	Evaluations int32 `json:"evaluations"`
+	AtMin       int32 `json:"atMin,omitempty"`
+	AtMax       int32 `json:"atMax,omitempty"`
+	Step        int32 `json:"step,omitempty"`
+
+	// Capacity alerts raised for the service
+	CapacityAlerts int32 `json:"capacityAlerts,omitempty"`
+}
+
+// ScalingReport is a periodic summary of the controller's scaling activity
+type ScalingReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ScalingReportSpec `json:"spec,omitempty"`
+}
+
+// ScalingReportList contains a list of ScalingReport
+type ScalingReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScalingReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScalingReport{}, &ScalingReportList{})
+}
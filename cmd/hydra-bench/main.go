@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/pprof"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/internal/loadtest"
+	hydraconfig "github.com/hydraai/hydra-route/pkg/config"
+)
+
+// hydra-bench pushes synthetic metrics for thousands of services through the
+// scaler's decision pipeline concurrently, without a cluster, and reports
+// decisions per second, lock contention and memory. Profiles of the run show
+// where the time and the contention go.
+func main() {
+	var (
+		configPath    = flag.String("config", "", "Optional controller configuration file to take the scaling settings from.")
+		modelType     = flag.String("model-type", "", "Model type to decide with (linear, neural_network, quantile, gru, littles_law, ensemble). Defaults to the configured type.")
+		services      = flag.Int("services", 5000, "Number of synthetic services.")
+		rounds        = flag.Int("rounds", 10, "Samples decided on per service.")
+		workers       = flag.Int("workers", runtime.GOMAXPROCS(0), "Goroutines making decisions concurrently.")
+		interval      = flag.Duration("interval", 30*time.Second, "Simulated time between a service's samples.")
+		seed          = flag.Int64("seed", 1, "Seed of the synthetic traffic.")
+		keepCooldowns = flag.Bool("keep-cooldowns", false, "Apply the configured cooldowns, which skip most decisions after a replica change.")
+		cpuProfile    = flag.String("cpu-profile", "", "Write a CPU profile of the run to this file.")
+		mutexProfile  = flag.String("mutex-profile", "", "Write a mutex contention profile of the run to this file.")
+		memProfile    = flag.String("mem-profile", "", "Write a heap profile taken after the run to this file.")
+		logLevel      = flag.String("log-level", "warn", "Log level (debug, info, warn, error)")
+	)
+	flag.Parse()
+
+	logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	if level, err := logrus.ParseLevel(*logLevel); err == nil {
+		logrus.SetLevel(level)
+	}
+
+	cfg := hydraconfig.DefaultConfig()
+	if *configPath != "" {
+		loaded, err := hydraconfig.LoadConfig(*configPath)
+		if err != nil {
+			logrus.Fatalf("Failed to load config: %v", err)
+		}
+		cfg = loaded
+	}
+	scaling := cfg.Scaling
+	if *modelType != "" {
+		scaling.AIModel.ModelType = *modelType
+	}
+	if !*keepCooldowns {
+		scaling.Cooldown = hydraconfig.CooldownConfig{}
+	}
+
+	if *mutexProfile != "" {
+		runtime.SetMutexProfileFraction(1)
+	}
+	if *cpuProfile != "" {
+		file, err := os.Create(*cpuProfile)
+		if err != nil {
+			logrus.Fatalf("Failed to create CPU profile: %v", err)
+		}
+		defer file.Close()
+		if err := pprof.StartCPUProfile(file); err != nil {
+			logrus.Fatalf("Failed to start CPU profile: %v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := loadtest.Run(ctx, loadtest.Options{
+		Config:   scaling,
+		Services: *services,
+		Rounds:   *rounds,
+		Workers:  *workers,
+		Interval: *interval,
+		Seed:     *seed,
+	})
+	if *cpuProfile != "" {
+		pprof.StopCPUProfile()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Load test failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	writeProfile("mutex", *mutexProfile)
+	writeProfile("heap", *memProfile)
+
+	report(result, scaling.AIModel.ModelType)
+	if result.Errors > 0 {
+		os.Exit(1)
+	}
+}
+
+// writeProfile writes a named runtime profile, if a path is given
+func writeProfile(name, path string) {
+	if path == "" {
+		return
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to create %s profile", name)
+		return
+	}
+	defer file.Close()
+	if err := pprof.Lookup(name).WriteTo(file, 0); err != nil {
+		logrus.WithError(err).Errorf("Failed to write %s profile", name)
+	}
+}
+
+func report(result *loadtest.Result, modelType string) {
+	if modelType == "" {
+		modelType = "linear"
+	}
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(out, "model\t%s\n", modelType)
+	fmt.Fprintf(out, "services\t%d\n", result.Services)
+	fmt.Fprintf(out, "workers\t%d\n", result.Workers)
+	fmt.Fprintf(out, "calls\t%d (%d decisions, %d replica changes, %d skipped, %d errors)\n",
+		result.Calls, result.Decisions, result.Changes, result.Skipped, result.Errors)
+	fmt.Fprintf(out, "duration\t%s\n", result.Duration.Round(time.Millisecond))
+	fmt.Fprintf(out, "decisions/sec\t%.0f\n", result.CallsPerSecond)
+	fmt.Fprintf(out, "latency\tp50 %s, p99 %s, max %s\n", result.LatencyP50, result.LatencyP99, result.LatencyMax)
+	fmt.Fprintf(out, "mutex wait\t%s (%.1f%% of worker time)\n", result.MutexWait.Round(time.Microsecond), result.MutexWaitShare*100)
+	fmt.Fprintf(out, "allocated/call\t%.0f B in %.1f allocations\n", result.BytesPerCall, result.AllocsPerCall)
+	fmt.Fprintf(out, "gc\t%d cycles, %s paused, longest %s\n", result.GCCycles, result.GCPauseTotal, result.GCPauseMax)
+	fmt.Fprintf(out, "live heap growth\t%.1f MiB (%.0f B per service)\n", float64(result.HeapGrowth)/(1<<20), result.HeapPerService)
+	out.Flush()
+
+	if result.Interrupted {
+		fmt.Fprintln(os.Stderr, "Interrupted; the figures cover the calls made until then")
+	}
+	if result.FirstError != nil {
+		fmt.Fprintf(os.Stderr, "First error: %v\n", result.FirstError)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/hydraai/hydra-route/internal/e2e"
+)
+
+// hydra-e2e runs the controller in-process against an envtest control plane
+// or a kind cluster, drives it with a fake nginx metrics source and checks
+// that deployments are scaled as each scenario expects. With --record or
+// --replay it runs the cluster-state scenarios against recorded API
+// interactions instead, one cassette per scenario.
+func main() {
+	var (
+		useExistingCluster = flag.Bool("use-existing-cluster", false, "Run against the cluster of the current kubeconfig (e.g. kind) instead of envtest.")
+		crdDirectory       = flag.String("crd-dir", "deploy/kubernetes/crds", "Directory with the CRD manifests to install.")
+		timeout            = flag.Duration("timeout", 3*time.Minute, "How long a scenario may take to reach its expected replicas.")
+		hold               = flag.Duration("hold", 45*time.Second, "How long scenarios expecting unchanged replicas are watched.")
+		keepNamespaces     = flag.Bool("keep-namespaces", false, "Leave the scenario namespaces in place after the run.")
+		scenarioNames      = flag.String("scenarios", "", "Comma-separated scenarios to run (all when empty).")
+		logLevel           = flag.String("log-level", "warn", "Controller log level (debug, info, warn, error)")
+		record             = flag.String("record", "", "Run the cluster-state scenarios and record the controller's API interactions to cassettes in this directory.")
+		replay             = flag.String("replay", "", "Run the cluster-state scenarios against the cassettes in this directory, without a control plane.")
+		fakeClient         = flag.Bool("fake", false, "With --record, record against controller-runtime's fake client instead of a control plane.")
+	)
+	flag.Parse()
+
+	level, err := logrus.ParseLevel(*logLevel)
+	if err != nil {
+		level = logrus.WarnLevel
+	}
+	logrus.SetLevel(level)
+	log.SetLogger(zap.New(zap.UseDevMode(level >= logrus.DebugLevel)))
+
+	if *record != "" && *replay != "" {
+		fmt.Fprintln(os.Stderr, "--record and --replay are mutually exclusive")
+		os.Exit(1)
+	}
+	cassette, replaying := *record, *replay != ""
+	if replaying {
+		cassette = *replay
+	}
+
+	scenarios, err := selectScenarios(*scenarioNames, cassette != "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var results []e2e.Result
+	if cassette != "" {
+		results, err = e2e.RunCassette(ctx, e2e.CassetteOptions{
+			Directory:          cassette,
+			Replay:             replaying,
+			Fake:               *fakeClient,
+			UseExistingCluster: *useExistingCluster,
+			CRDDirectory:       *crdDirectory,
+			Scenarios:          scenarios,
+		})
+	} else {
+		results, err = e2e.Run(ctx, e2e.Options{
+			UseExistingCluster: *useExistingCluster,
+			CRDDirectory:       *crdDirectory,
+			Timeout:            *timeout,
+			Hold:               *hold,
+			KeepNamespaces:     *keepNamespaces,
+			Scenarios:          scenarios,
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "End-to-end run failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, result := range results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(out, "%s\t%s\t%d replicas\t%s\t%s\n", status, result.Scenario, result.Replicas, result.Duration.Round(time.Second), result.Message)
+	}
+	out.Flush()
+
+	if e2e.Failed(results) {
+		os.Exit(1)
+	}
+}
+
+// selectScenarios returns the named scenarios, or all of them: the
+// cluster-state scenarios for cassette runs and the default ones otherwise
+func selectScenarios(names string, cassette bool) ([]e2e.Scenario, error) {
+	all := e2e.DefaultScenarios()
+	if cassette {
+		all = e2e.ClusterStateScenarios()
+	}
+	if names == "" {
+		return all, nil
+	}
+
+	byName := make(map[string]e2e.Scenario, len(all))
+	for _, scenario := range all {
+		byName[scenario.Name] = scenario
+	}
+
+	var selected []e2e.Scenario
+	for _, name := range strings.Split(names, ",") {
+		scenario, ok := byName[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown scenario %q (cluster-state scenarios need --record or --replay)", name)
+		}
+		selected = append(selected, scenario)
+	}
+	return selected, nil
+}
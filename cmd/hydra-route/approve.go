@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	hydracontroller "github.com/hydraai/hydra-route/internal/controller"
+)
+
+// runApprove implements "hydra-route approve": it approves the pending
+// replica change of a service by annotating its ScalingRecommendation, or
+// lists the pending changes with --list
+func runApprove(args []string) int {
+	flags := flag.NewFlagSet("approve", flag.ExitOnError)
+	namespace := flags.String("namespace", "default", "Namespace of the service.")
+	list := flags.Bool("list", false, "List pending approvals in all namespaces instead of approving one.")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: hydra-route approve [flags] <service>\n       hydra-route approve --list\n\nApprove a large replica change held for approval.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if !*list && flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create client: %v\n", err)
+		return 1
+	}
+	ctx := context.Background()
+
+	if *list {
+		return listPendingApprovals(ctx, c)
+	}
+
+	recommendation := &hydrav1alpha1.ScalingRecommendation{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: *namespace, Name: flags.Arg(0)}, recommendation); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get scaling recommendation: %v\n", err)
+		return 1
+	}
+
+	approval := recommendation.Spec.Approval
+	if approval == nil || approval.Phase != hydrav1alpha1.ApprovalPending {
+		fmt.Fprintf(os.Stderr, "No approval pending for %s/%s\n", *namespace, flags.Arg(0))
+		return 1
+	}
+	if time.Now().After(approval.ExpiresAt.Time) {
+		fmt.Fprintf(os.Stderr, "Approval for %s/%s expired at %s\n", *namespace, flags.Arg(0), approval.ExpiresAt.Format(time.RFC3339))
+		return 1
+	}
+
+	patched := recommendation.DeepCopy()
+	if patched.Annotations == nil {
+		patched.Annotations = make(map[string]string)
+	}
+	patched.Annotations[hydracontroller.HydraRouteApproveAnnotation] = "true"
+	if err := c.Patch(ctx, patched, client.MergeFrom(recommendation)); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to approve: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Approved scaling %s/%s from %d to %d replicas. It is applied at the next evaluation.\n",
+		*namespace, flags.Arg(0), recommendation.Spec.CurrentReplicas, approval.Replicas)
+	return 0
+}
+
+// listPendingApprovals prints the pending approvals of every namespace
+func listPendingApprovals(ctx context.Context, c client.Client) int {
+	recommendations := &hydrav1alpha1.ScalingRecommendationList{}
+	if err := c.List(ctx, recommendations); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list scaling recommendations: %v\n", err)
+		return 1
+	}
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(out, "NAMESPACE\tSERVICE\tCURRENT\tREPLICAS\tEXPIRES")
+	for _, recommendation := range recommendations.Items {
+		approval := recommendation.Spec.Approval
+		if approval == nil || approval.Phase != hydrav1alpha1.ApprovalPending {
+			continue
+		}
+		fmt.Fprintf(out, "%s\t%s\t%d\t%d\t%s\n", recommendation.Namespace, recommendation.Name,
+			recommendation.Spec.CurrentReplicas, approval.Replicas, approval.ExpiresAt.Format(time.RFC3339))
+	}
+	out.Flush()
+	return 0
+}
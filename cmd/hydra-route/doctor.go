@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hydraai/hydra-route/internal/doctor"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// runDoctor implements "hydra-route doctor": it checks the cluster
+// prerequisites of the controller and prints what to fix. It exits non-zero
+// when any check fails.
+func runDoctor(args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	configPath := flags.String("config", "", "Configuration file the controller runs with (defaults when empty).")
+	serviceAccount := flags.String("service-account", "hydra-route-system/hydra-route-controller", "Namespace/name of the controller's service account.")
+	timeout := flags.Duration("timeout", 10*time.Second, "Timeout of each endpoint check.")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: hydra-route doctor [flags]\n\nCheck RBAC, CRDs, metrics-server, metrics endpoints and webhook certificates.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	cfg := config.DefaultConfig()
+	if *configPath != "" {
+		loaded, err := config.LoadConfig(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+			return 1
+		}
+		cfg = loaded
+	}
+
+	namespace, name, ok := strings.Cut(*serviceAccount, "/")
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid service account %q, expected namespace/name\n", *serviceAccount)
+		return 1
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create client: %v\n", err)
+		return 1
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create discovery client: %v\n", err)
+		return 1
+	}
+
+	results := doctor.Run(context.Background(), doctor.Options{
+		Client:                  c,
+		Discovery:               discoveryClient,
+		HTTP:                    &http.Client{Timeout: *timeout},
+		Config:                  cfg,
+		ServiceAccountNamespace: namespace,
+		ServiceAccountName:      name,
+	})
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, result := range results {
+		fmt.Fprintf(out, "%s\t%s\t%s\n", strings.ToUpper(result.Status), result.Check, result.Message)
+		if result.Hint != "" {
+			fmt.Fprintf(out, "\t\t-> %s\n", result.Hint)
+		}
+	}
+	out.Flush()
+
+	if doctor.Failed(results) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	hydracontroller "github.com/hydraai/hydra-route/internal/controller"
+	"github.com/hydraai/hydra-route/internal/lint"
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// runLintPolicy implements "hydra-route lint-policy": it checks the scaling
+// configuration and the cluster's HydraRoutePolicies for contradictions,
+// against archived metrics history when given. It exits non-zero when any
+// finding is an error.
+func runLintPolicy(args []string) int {
+	flags := flag.NewFlagSet("lint-policy", flag.ExitOnError)
+	configPath := flags.String("config", "", "Configuration file the controller runs with (defaults when empty).")
+	historyPath := flags.String("history", "", "Archived metrics samples in JSON lines format, as read by hydra-train --format metrics.")
+	namespace := flags.String("namespace", "", "Namespace of the HydraRoutePolicies to check (all namespaces when empty).")
+	offline := flags.Bool("offline", false, "Check the configuration and history only, without reading policies from the cluster.")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: hydra-route lint-policy [flags]\n\nReport contradictions in scaling policies, on their own and against observed history.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	cfg := config.DefaultConfig()
+	if *configPath != "" {
+		loaded, err := config.LoadConfig(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+			return 1
+		}
+		cfg = loaded
+	}
+
+	opts := lint.Options{Config: cfg}
+	if *historyPath != "" {
+		history, err := loadHistory(*historyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load history: %v\n", err)
+			return 1
+		}
+		opts.History = history
+	}
+
+	if !*offline {
+		c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create client: %v\n", err)
+			return 1
+		}
+		if opts.Policies, opts.Services, err = policyServices(context.Background(), c, *namespace); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read policies: %v\n", err)
+			return 1
+		}
+	}
+
+	findings := lint.Run(opts)
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, finding := range findings {
+		fmt.Fprintf(out, "%s\t%s\t%s\n", strings.ToUpper(finding.Severity), finding.Subject, finding.Message)
+	}
+	out.Flush()
+	if len(findings) == 0 {
+		fmt.Println("No problems found")
+	}
+
+	if lint.Failed(findings) {
+		return 1
+	}
+	return 0
+}
+
+// policyServices lists the HydraRoutePolicies and the backend services of
+// the ingress each references, by policy key (namespace/name)
+func policyServices(ctx context.Context, c client.Client, namespace string) ([]hydrav1alpha1.HydraRoutePolicy, map[string][]string, error) {
+	policies := &hydrav1alpha1.HydraRoutePolicyList{}
+	if err := c.List(ctx, policies, client.InNamespace(namespace)); err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(policies.Items, func(i, j int) bool {
+		a, b := policies.Items[i], policies.Items[j]
+		return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+	})
+
+	services := make(map[string][]string, len(policies.Items))
+	for _, policy := range policies.Items {
+		ingress := &networkingv1.Ingress{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: policy.Namespace, Name: policy.Spec.IngressName}, ingress); err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				continue
+			}
+			return nil, nil, err
+		}
+		key := policy.Namespace + "/" + policy.Name
+		for _, service := range hydracontroller.BackendServices(ingress) {
+			services[key] = append(services[key], policy.Namespace+"/"+service)
+		}
+	}
+	return policies.Items, services, nil
+}
+
+// loadHistory reads archived metrics samples, grouped by service and sorted
+// by time
+func loadHistory(path string) (map[string][]*metrics.MetricsData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	history := make(map[string][]*metrics.MetricsData)
+	reader := bufio.NewScanner(file)
+	reader.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	line := 0
+	for reader.Scan() {
+		line++
+		text := strings.TrimSpace(reader.Text())
+		if text == "" {
+			continue
+		}
+		sample := &metrics.MetricsData{}
+		if err := json.Unmarshal([]byte(text), sample); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		key := sample.Namespace + "/" + sample.ServiceName
+		history[key] = append(history[key], sample)
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, samples := range history {
+		sort.Slice(samples, func(i, j int) bool { return samples[i].Timestamp.Before(samples[j].Timestamp) })
+	}
+	return history, nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
 
 	"github.com/sirupsen/logrus"
@@ -10,15 +11,27 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	"github.com/hydraai/hydra-route/internal/api"
 	hydracontroller "github.com/hydraai/hydra-route/internal/controller"
+	"github.com/hydraai/hydra-route/internal/egress"
+	"github.com/hydraai/hydra-route/internal/fips"
 	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/registry"
 	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/internal/signing"
 	hydraconfig "github.com/hydraai/hydra-route/pkg/config"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 )
 
@@ -31,14 +44,36 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(appsv1.AddToScheme(scheme))
 	utilruntime.Must(networkingv1.AddToScheme(scheme))
+	utilruntime.Must(autoscalingv2.AddToScheme(scheme))
+	utilruntime.Must(hydrav1alpha1.AddToScheme(scheme))
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:]))
+		case "recording-rules":
+			os.Exit(runRecordingRules(os.Args[2:]))
+		case "doctor":
+			os.Exit(runDoctor(os.Args[2:]))
+		case "approve":
+			os.Exit(runApprove(os.Args[2:]))
+		case "lint-policy":
+			os.Exit(runLintPolicy(os.Args[2:]))
+		case "rbac":
+			os.Exit(runRBAC(os.Args[2:]))
+		case "verify-decision":
+			os.Exit(runVerifyDecision(os.Args[2:]))
+		}
+	}
+
 	var (
 		probeAddr            = flag.String("health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 		enableLeaderElection = flag.Bool("leader-elect", false, "Enable leader election for controller manager.")
 		configPath           = flag.String("config", "/etc/hydra-route/config.yaml", "Path to the configuration file.")
 		logLevel             = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+		runtimeOverrides     = registerRuntimeFlags()
 	)
 	flag.Parse()
 
@@ -50,13 +85,44 @@ func main() {
 	if err != nil {
 		logrus.Fatalf("Failed to load config: %v", err)
 	}
+	runtimeOverrides.apply(&cfg.General.Runtime)
+	tuneRuntime(cfg.General.Runtime)
+	if cfg.General.FIPSRequired && !fips.Enabled() {
+		logrus.Fatal("fips_required is set but this binary was built without FIPS-validated crypto; use a build made with GOEXPERIMENT=boringcrypto")
+	}
+	if fips.Enabled() {
+		logrus.Info("FIPS mode: crypto and TLS are restricted to the BoringCrypto module")
+	}
+	if cfg.General.AirGapped.Enabled {
+		egress.Restrict(cfg.General.AirGapped)
+		logrus.WithField("allowed", cfg.General.AirGapped.AllowedEndpoints).Info("Air-gapped mode: outbound connections restricted to the API server and allowed endpoints")
+	}
+
+	if _, err := scaler.CompilePolicies(cfg.Scaling.Policies); err != nil {
+		logrus.Fatalf("Invalid scaling policies: %v", err)
+	}
+	if _, err := scaler.CompileDerivedFeatures(cfg.Scaling.AIModel.DerivedFeatures); err != nil {
+		logrus.Fatalf("Invalid derived features: %v", err)
+	}
+	if _, err := scaler.ParseFeatureSet(cfg.Scaling.AIModel.DisabledFeatures); err != nil {
+		logrus.Fatalf("Invalid disabled features: %v", err)
+	}
 
 	// Setup manager
 	opts := ctrl.Options{
 		Scheme:                 scheme,
 		HealthProbeBindAddress: *probeAddr,
+		PprofBindAddress:       cfg.General.Runtime.PprofBindAddress,
 		LeaderElection:         *enableLeaderElection,
 		LeaderElectionID:       "hydra-route-leader-election",
+
+		// Time for the evaluation loops and the actuation queue to finish
+		// what they are applying once SIGTERM arrives
+		GracefulShutdownTimeout: &cfg.General.ShutdownTimeout,
+	}
+
+	if len(cfg.General.WatchNamespaces) > 0 {
+		opts.Cache = watchedNamespacesCache(cfg)
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), opts)
@@ -67,17 +133,48 @@ func main() {
 
 	// Setup metrics collector
 	metricsCollector := metrics.NewCollector(mgr.GetClient(), cfg.Metrics)
+	metricsCollector.ArgoRollouts = cfg.General.ArgoRollouts.Enabled
+	if cfg.Scaling.Deterministic {
+		metricsCollector.SetSeed(cfg.Scaling.AIModel.Seed)
+	}
 
 	// Setup AI scaler
 	aiScaler := scaler.NewAIScaler(cfg.Scaling)
+	metricsCollector.OnBootstrap = aiScaler.Bootstrap
+
+	// Setup model registry
+	var modelRegistry *registry.Registry
+	if cfg.Scaling.AIModel.Registry.Backend != "" {
+		store, err := newRegistryStore(mgr, cfg.Scaling.AIModel.Registry)
+		if err != nil {
+			setupLog.Error(err, "unable to create model registry")
+			os.Exit(1)
+		}
+		modelRegistry = registry.New(store, cfg.Scaling.AIModel, cfg.Scaling.AIModel.Registry.SyncInterval)
+	}
 
 	// Setup controller
+	var modelReady func() bool
+	if modelRegistry != nil {
+		modelReady = modelRegistry.Synced
+	}
+	statuses := hydracontroller.NewStatusTracker()
+	statuses.CountEvaluations(cfg.General.Quarantine)
+	signer, err := signing.New(cfg.General.Signing)
+	if err != nil {
+		logrus.Fatalf("Failed to set up decision signing: %v", err)
+	}
+
 	hydraController := &hydracontroller.HydraRouteReconciler{
 		Client:           mgr.GetClient(),
 		Scheme:           mgr.GetScheme(),
 		MetricsCollector: metricsCollector,
 		AIScaler:         aiScaler,
 		Config:           cfg,
+		Statuses:         statuses,
+		Recorder:         mgr.GetEventRecorderFor("hydra-route"),
+		ModelReady:       modelReady,
+		Signer:           signer,
 	}
 
 	// Setup controller with manager
@@ -86,15 +183,192 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Start metrics collection
-	ctx := context.Background()
-	go metricsCollector.Start(ctx)
+	// Setup policy status controller
+	if cfg.General.EnablePolicies {
+		policyController := &hydracontroller.HydraRoutePolicyReconciler{
+			Client:           mgr.GetClient(),
+			Scheme:           mgr.GetScheme(),
+			MetricsCollector: metricsCollector,
+			AIScaler:         aiScaler,
+			Config:           cfg,
+			Statuses:         statuses,
+		}
+		if err := policyController.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create policy controller")
+			os.Exit(1)
+		}
+	}
+
+	// Keep prometheus-operator monitors for the scraped endpoints in place
+	if cfg.General.ServiceMonitors.Enabled {
+		if err := mgr.Add(&hydracontroller.MonitorManager{
+			Client:  mgr.GetClient(),
+			Config:  cfg.General.ServiceMonitors,
+			Metrics: cfg.Metrics,
+
+			NamespaceOptIn: cfg.General.NamespaceOptIn,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up monitor manager")
+			os.Exit(1)
+		}
+	}
+
+	// Right-sizing, experiment analysis and scaling reports work on the
+	// collected metrics and the scaler's decisions
+	if cfg.Scaling.RightSizing.Enabled {
+		if err := mgr.Add(&hydracontroller.RightSizer{
+			Collector:          metricsCollector,
+			Scaler:             aiScaler,
+			Config:             cfg.Scaling.RightSizing,
+			CollectionInterval: cfg.Metrics.CollectionInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up right-sizing analysis")
+			os.Exit(1)
+		}
+	}
+
+	if cfg.Scaling.Experiment.Name != "" {
+		if err := mgr.Add(&hydracontroller.ExperimentAnalyzer{
+			Collector:          metricsCollector,
+			Scaler:             aiScaler,
+			Config:             cfg.Scaling.Experiment,
+			CollectionInterval: cfg.Metrics.CollectionInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up experiment analysis")
+			os.Exit(1)
+		}
+	}
+
+	if cfg.Scaling.SummaryReport.Enabled {
+		if err := mgr.Add(&hydracontroller.SummaryReporter{
+			Client:             mgr.GetClient(),
+			Collector:          metricsCollector,
+			Scaler:             aiScaler,
+			Statuses:           statuses,
+			Config:             cfg.Scaling.SummaryReport,
+			CollectionInterval: cfg.Metrics.CollectionInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up scaling reports")
+			os.Exit(1)
+		}
+	}
+
+	if cfg.General.Runtime.SelfThrottling.Enabled {
+		if err := mgr.Add(&hydracontroller.SelfThrottler{
+			Collector: metricsCollector,
+			Scaler:    aiScaler,
+			Config:    cfg.General.Runtime.SelfThrottling,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up self-throttling")
+			os.Exit(1)
+		}
+	}
+
+	// Health and readiness checks; the controller reports ready only after the
+	// first metrics cycle and, with a registry, the initial model sync
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("metrics", metricsCollector.ReadyCheck); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+	if modelRegistry != nil {
+		if err := mgr.AddReadyzCheck("model-registry", modelRegistry.ReadyCheck); err != nil {
+			setupLog.Error(err, "unable to set up ready check")
+			os.Exit(1)
+		}
+	}
+
+	// Metrics collection, model registry sync and the admin API run on every
+	// replica; hyperparameter search only on the leader
+	if err := mgr.Add(metricsCollector); err != nil {
+		setupLog.Error(err, "unable to set up metrics collector")
+		os.Exit(1)
+	}
+	if modelRegistry != nil {
+		if err := mgr.Add(modelRegistry.Runnable(aiScaler)); err != nil {
+			setupLog.Error(err, "unable to set up model registry sync")
+			os.Exit(1)
+		}
+	}
+	if cfg.Scaling.AIModel.Hyperparameters.Search.Enabled {
+		if modelRegistry != nil {
+			logrus.Warn("Hyperparameter search is disabled while the model registry manages model versions")
+		} else if err := mgr.Add(manager.RunnableFunc(aiScaler.StartHyperparameterSearch)); err != nil {
+			setupLog.Error(err, "unable to set up hyperparameter search")
+			os.Exit(1)
+		}
+	}
+	if ai := cfg.Scaling.AIModel; ai.RetrainInterval > 0 || ai.FreshnessSLA > 0 {
+		if err := mgr.Add(manager.RunnableFunc(aiScaler.StartModelFreshness)); err != nil {
+			setupLog.Error(err, "unable to set up model freshness checks")
+			os.Exit(1)
+		}
+	}
+	if cfg.General.AdminAPI.Enabled {
+		if err := mgr.Add(api.NewServer(cfg.General.AdminAPI, modelRegistry, aiScaler)); err != nil {
+			setupLog.Error(err, "unable to set up admin API")
+			os.Exit(1)
+		}
+	}
+
+	// The context is cancelled on SIGTERM or SIGINT
+	ctx := ctrl.SetupSignalHandler()
 
 	logrus.Info("Starting Hydra Route Controller")
 	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	// The manager returns once no new decisions are made and the actions in
+	// flight have been applied; what is left is saving the model
+	logrus.Info("Shutting down Hydra Route Controller")
+	if path := cfg.Scaling.AIModel.CheckpointPath; path != "" {
+		checkpointCtx, cancel := context.WithTimeout(context.Background(), cfg.General.ShutdownTimeout)
+		defer cancel()
+		if err := aiScaler.Checkpoint(checkpointCtx, path); err != nil {
+			logrus.WithError(err).Error("Failed to write model checkpoint")
+		}
+	}
+	logrus.Info("Shutdown complete")
+}
+
+// newRegistryStore creates the storage backend for the model registry
+func newRegistryStore(mgr ctrl.Manager, cfg hydraconfig.ModelRegistryConfig) (registry.Store, error) {
+	switch cfg.Backend {
+	case "configmap":
+		return registry.NewConfigMapStore(mgr.GetAPIReader(), mgr.GetClient(), cfg.Namespace, cfg.Name), nil
+	case "directory":
+		return registry.NewDirectoryStore(cfg.Directory)
+	default:
+		return nil, fmt.Errorf("unknown registry backend %q", cfg.Backend)
+	}
+}
+
+// watchedNamespacesCache limits the cache to general.watch_namespaces, so
+// the controller runs with the namespaced roles "hydra-route rbac" generates.
+// Summary reports are cached in their own namespace, and pods everywhere
+// when Job pods on shared nodes are looked for.
+func watchedNamespacesCache(cfg *hydraconfig.Config) cache.Options {
+	options := cache.Options{
+		DefaultNamespaces: make(map[string]cache.Config),
+		ByObject:          make(map[client.Object]cache.ByObject),
+	}
+	for _, namespace := range cfg.General.WatchNamespaces {
+		options.DefaultNamespaces[namespace] = cache.Config{}
+	}
+	if cfg.Scaling.SummaryReport.Enabled {
+		options.ByObject[&hydrav1alpha1.ScalingReport{}] = cache.ByObject{
+			Namespaces: map[string]cache.Config{cfg.Scaling.SummaryReport.Namespace: {}},
+		}
+	}
+	if cfg.Metrics.BatchContention.Enabled {
+		options.ByObject[&corev1.Pod{}] = cache.ByObject{Namespaces: map[string]cache.Config{}}
+	}
+	return options
 }
 
 func setupLogger(level string) {
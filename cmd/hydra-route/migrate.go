@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	"github.com/hydraai/hydra-route/internal/migrate"
+)
+
+// policyManifest is the YAML form of a generated policy, without the
+// server-populated fields of the API type
+type policyManifest struct {
+	APIVersion string                             `json:"apiVersion"`
+	Kind       string                             `json:"kind"`
+	Metadata   policyMetadata                     `json:"metadata"`
+	Spec       hydrav1alpha1.HydraRoutePolicySpec `json:"spec"`
+}
+
+type policyMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// runMigrate implements "hydra-route migrate": it reads HPAs and KEDA
+// ScaledObjects from the cluster and prints equivalent HydraRoutePolicy
+// manifests for review. Nothing in the cluster is changed.
+func runMigrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	namespace := flags.String("namespace", "", "Namespace to scan (all namespaces when empty).")
+	outputPath := flags.String("output", "", "File to write the generated manifests to (stdout when empty).")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: hydra-route migrate [flags]\n\nGenerate HydraRoutePolicy manifests from existing HorizontalPodAutoscalers and KEDA ScaledObjects.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create client: %v\n", err)
+		return 1
+	}
+
+	results, warnings, err := migrate.Generate(context.Background(), c, *namespace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+		return 1
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+
+	out := io.Writer(os.Stdout)
+	if *outputPath != "" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create output file: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := writePolicies(out, results); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write manifests: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "Generated %d policies. Annotate each ingress with %s: \"true\" and remove the original autoscalers once the policies are applied, so two autoscalers do not fight over replicas.\n", len(results), "hydra-route.ai/enabled")
+	return 0
+}
+
+// writePolicies writes the generated policies as a multi-document YAML stream,
+// each preceded by comments naming its sources and anything not converted
+func writePolicies(out io.Writer, results []migrate.Result) error {
+	for i, result := range results {
+		if i > 0 {
+			fmt.Fprintln(out, "---")
+		}
+		for _, source := range result.Sources {
+			fmt.Fprintf(out, "# Converted from %s %s/%s\n", source.Kind, source.Namespace, source.Name)
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(out, "# WARNING: %s\n", strings.ReplaceAll(warning, "\n", " "))
+		}
+
+		manifest := policyManifest{
+			APIVersion: result.Policy.APIVersion,
+			Kind:       result.Policy.Kind,
+			Metadata:   policyMetadata{Name: result.Policy.Name, Namespace: result.Policy.Namespace},
+			Spec:       result.Policy.Spec,
+		}
+		data, err := yaml.Marshal(manifest)
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/hydraai/hydra-route/internal/rbac"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// runRBAC implements "hydra-route rbac": it prints the RBAC manifests the
+// controller needs with a configuration, limited to the watched namespaces
+// and the features that are enabled
+func runRBAC(args []string) int {
+	flags := flag.NewFlagSet("rbac", flag.ExitOnError)
+	configPath := flags.String("config", "", "Configuration file the controller runs with (defaults when empty).")
+	serviceAccount := flags.String("service-account", "hydra-route-system/hydra-route-controller", "Namespace/name of the controller's service account.")
+	name := flags.String("name", "hydra-route-controller", "Name of the generated roles and bindings.")
+	outputPath := flags.String("output", "", "File to write the manifests to (stdout when empty).")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: hydra-route rbac [flags]\n\nGenerate the least-privilege RBAC manifests for a configuration.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	cfg := config.DefaultConfig()
+	if *configPath != "" {
+		loaded, err := config.LoadConfig(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+			return 1
+		}
+		cfg = loaded
+	}
+
+	namespace, account, ok := strings.Cut(*serviceAccount, "/")
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid service account %q, expected namespace/name\n", *serviceAccount)
+		return 1
+	}
+	opts := rbac.Options{Name: *name, Namespace: namespace, ServiceAccountName: account}
+
+	var buf bytes.Buffer
+	for i, object := range rbac.Manifests(rbac.Required(cfg, opts), opts) {
+		data, err := manifestYAML(object)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode manifests: %v\n", err)
+			return 1
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+
+	out := io.Writer(os.Stdout)
+	if *outputPath != "" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create output file: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if _, err := out.Write(buf.Bytes()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write manifests: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// manifestYAML encodes an object without the server-populated fields left empty
+func manifestYAML(object runtime.Object) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+	return yaml.Marshal(content)
+}
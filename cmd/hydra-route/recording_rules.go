@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// rulesFile is a Prometheus rules file as loaded through rule_files
+type rulesFile struct {
+	Groups []metrics.RuleGroup `json:"groups"`
+}
+
+// prometheusRuleManifest is a prometheus-operator PrometheusRule resource
+type prometheusRuleManifest struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Metadata   policyMetadata `json:"metadata"`
+	Spec       rulesFile      `json:"spec"`
+}
+
+// runRecordingRules implements "hydra-route recording-rules": it prints the
+// Prometheus recording rules for the per-service series HydraRoute queries,
+// using the request rate window and collection interval from the config
+func runRecordingRules(args []string) int {
+	flags := flag.NewFlagSet("recording-rules", flag.ExitOnError)
+	configPath := flags.String("config", "", "Configuration file to read windows and intervals from (defaults when empty).")
+	format := flags.String("format", "rules", "Output format: rules (Prometheus rules file) or prometheusrule (prometheus-operator resource).")
+	name := flags.String("name", "hydra-route", "Name of the PrometheusRule resource.")
+	namespace := flags.String("namespace", "monitoring", "Namespace of the PrometheusRule resource.")
+	namespaceLabel := flags.String("namespace-label", "", "Label holding the service namespace on nginx ingress controller series (overrides metrics.label_mapping.namespace_label).")
+	outputPath := flags.String("output", "", "File to write the rules to (stdout when empty).")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: hydra-route recording-rules [flags]\n\nGenerate Prometheus recording rules for the queries HydraRoute runs.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	cfg := config.DefaultConfig()
+	if *configPath != "" {
+		loaded, err := config.LoadConfig(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+			return 1
+		}
+		cfg = loaded
+	}
+
+	if *namespaceLabel != "" {
+		cfg.Metrics.LabelMapping.NamespaceLabel = *namespaceLabel
+	}
+	group := metrics.RecordingRules(cfg.Metrics)
+
+	var document interface{}
+	switch *format {
+	case "rules":
+		document = rulesFile{Groups: []metrics.RuleGroup{group}}
+	case "prometheusrule":
+		document = prometheusRuleManifest{
+			APIVersion: "monitoring.coreos.com/v1",
+			Kind:       "PrometheusRule",
+			Metadata:   policyMetadata{Name: *name, Namespace: *namespace},
+			Spec:       rulesFile{Groups: []metrics.RuleGroup{group}},
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format %q\n", *format)
+		return 1
+	}
+
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode rules: %v\n", err)
+		return 1
+	}
+
+	out := io.Writer(os.Stdout)
+	if *outputPath != "" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create output file: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if _, err := out.Write(data); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write rules: %v\n", err)
+		return 1
+	}
+	return 0
+}
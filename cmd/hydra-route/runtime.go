@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"math"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/sirupsen/logrus"
+
+	hydraconfig "github.com/hydraai/hydra-route/pkg/config"
+)
+
+// runtimeFlags override the general.runtime settings of the config file
+type runtimeFlags struct {
+	pprofBindAddress        *string
+	gomaxprocs              *int
+	gcPercent               *int
+	memoryLimitMiB          *int64
+	maxConcurrentReconciles *int
+}
+
+func registerRuntimeFlags() runtimeFlags {
+	return runtimeFlags{
+		pprofBindAddress:        flag.String("pprof-bind-address", "", "The address the pprof endpoints bind to, e.g. :6060. Overrides general.runtime.pprof_bind_address."),
+		gomaxprocs:              flag.Int("gomaxprocs", 0, "Maximum number of CPUs executing Go code at once. Overrides general.runtime.gomaxprocs."),
+		gcPercent:               flag.Int("gc-percent", 0, "Heap growth in percent that triggers a garbage collection, -1 to turn it off. Overrides general.runtime.gc_percent."),
+		memoryLimitMiB:          flag.Int64("memory-limit-mib", 0, "Soft memory limit of the Go runtime in MiB. Overrides general.runtime.memory_limit_mib."),
+		maxConcurrentReconciles: flag.Int("max-concurrent-reconciles", 0, "Reconciles run in parallel by each controller. Overrides general.runtime.max_concurrent_reconciles."),
+	}
+}
+
+// apply copies the flags given on the command line into the config
+func (f runtimeFlags) apply(cfg *hydraconfig.RuntimeConfig) {
+	flag.Visit(func(set *flag.Flag) {
+		switch set.Name {
+		case "pprof-bind-address":
+			cfg.PprofBindAddress = *f.pprofBindAddress
+		case "gomaxprocs":
+			cfg.GOMAXPROCS = *f.gomaxprocs
+		case "gc-percent":
+			cfg.GCPercent = *f.gcPercent
+		case "memory-limit-mib":
+			cfg.MemoryLimitMiB = *f.memoryLimitMiB
+		case "max-concurrent-reconciles":
+			cfg.MaxConcurrentReconciles = *f.maxConcurrentReconciles
+		}
+	})
+}
+
+// tuneRuntime applies the Go runtime settings. Settings left at zero keep
+// the Go defaults, including those taken from the GOMAXPROCS, GOGC and
+// GOMEMLIMIT environment variables.
+func tuneRuntime(cfg hydraconfig.RuntimeConfig) {
+	if cfg.GOMAXPROCS > 0 {
+		runtime.GOMAXPROCS(cfg.GOMAXPROCS)
+	}
+	// SetGCPercent returns the previous setting, so the one in effect is
+	// read by setting it again
+	gcPercent := debug.SetGCPercent(100)
+	if cfg.GCPercent != 0 {
+		gcPercent = cfg.GCPercent
+	}
+	debug.SetGCPercent(gcPercent)
+	if cfg.MemoryLimitMiB > 0 {
+		debug.SetMemoryLimit(cfg.MemoryLimitMiB << 20)
+	}
+	// A negative limit reads the one in effect; without one it is MaxInt64
+	var memoryLimitMiB int64
+	if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+		memoryLimitMiB = limit >> 20
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"gomaxprocs":                runtime.GOMAXPROCS(0),
+		"gc_percent":                gcPercent,
+		"memory_limit_mib":          memoryLimitMiB,
+		"max_concurrent_reconciles": cfg.MaxConcurrentReconciles,
+		"pprof_bind_address":        cfg.PprofBindAddress,
+	}).Info("Runtime settings")
+}
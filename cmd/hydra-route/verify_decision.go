@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	hydracontroller "github.com/hydraai/hydra-route/internal/controller"
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/signing"
+)
+
+// verifiableKinds maps the kinds accepted by verify-decision to the
+// resources carrying signed decision records
+var verifiableKinds = map[string]schema.GroupVersionKind{
+	"deployment":            appsv1.SchemeGroupVersion.WithKind("Deployment"),
+	"rollout":               metrics.RolloutKind,
+	"scalingrecommendation": hydrav1alpha1.GroupVersion.WithKind("ScalingRecommendation"),
+}
+
+// runVerifyDecision implements "hydra-route verify-decision": it checks the
+// signed decision record of a deployment, rollout or ScalingRecommendation
+// against a public key, and that the record names that object
+func runVerifyDecision(args []string) int {
+	flags := flag.NewFlagSet("verify-decision", flag.ExitOnError)
+	namespace := flags.String("namespace", "default", "Namespace of the object.")
+	publicKey := flags.String("public-key", "", "PEM public key of the signing key (required).")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: hydra-route verify-decision [flags] <deployment|rollout|scalingrecommendation>/<name>\n\nVerify the signed decision record HydraRoute wrote on an object.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || *publicKey == "" {
+		flags.Usage()
+		return 2
+	}
+	kind, name, ok := strings.Cut(flags.Arg(0), "/")
+	gvk, known := verifiableKinds[strings.ToLower(kind)]
+	if !ok || !known || name == "" {
+		flags.Usage()
+		return 2
+	}
+	key, err := os.ReadFile(*publicKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read public key: %v\n", err)
+		return 1
+	}
+
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create client: %v\n", err)
+		return 1
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: *namespace, Name: name}, obj); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", flags.Arg(0), err)
+		return 1
+	}
+
+	annotations := obj.GetAnnotations()
+	payload := annotations[hydracontroller.HydraRouteDecisionAnnotation]
+	signature := annotations[hydracontroller.HydraRouteDecisionSignatureAnnotation]
+	if payload == "" {
+		fmt.Fprintf(os.Stderr, "%s/%s carries no decision record\n", *namespace, flags.Arg(0))
+		return 1
+	}
+	if signature == "" {
+		fmt.Fprintf(os.Stderr, "The decision record of %s/%s is unsigned\n", *namespace, flags.Arg(0))
+		return 1
+	}
+	if err := signing.Verify(key, []byte(payload), signature); err != nil {
+		fmt.Fprintf(os.Stderr, "Verification failed: %v\n", err)
+		return 1
+	}
+
+	var record signing.Record
+	if err := json.Unmarshal([]byte(payload), &record); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid decision record: %v\n", err)
+		return 1
+	}
+	if record.Issuer != signing.Issuer || record.Kind != gvk.Kind || record.Namespace != *namespace || record.Name != name {
+		fmt.Fprintf(os.Stderr, "The signed record is about %s %s/%s, not this object\n", record.Kind, record.Namespace, record.Name)
+		return 1
+	}
+
+	action := "scaled"
+	if record.Kind == "ScalingRecommendation" {
+		action = "recommended"
+	}
+	fmt.Printf("Verified %s %s/%s: %s %s service %s from %d to %d replicas (decided %s", record.Kind, record.Namespace, record.Name,
+		record.Issuer, action, record.Service, record.FromReplicas, record.Replicas, record.DecidedAt.Format(time.RFC3339))
+	if record.KeyID != "" {
+		fmt.Printf(", key %s", record.KeyID)
+	}
+	fmt.Println(")")
+	return 0
+}
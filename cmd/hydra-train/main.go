@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/scaler"
+	hydraconfig "github.com/hydraai/hydra-route/pkg/config"
+)
+
+func main() {
+	var (
+		inputPath       = flag.String("input", "", "Path to archived data in JSON lines format (required).")
+		inputFormat     = flag.String("format", "training", "Input format: training (TrainingData records) or metrics (MetricsData records).")
+		configPath      = flag.String("config", "", "Optional controller configuration file to take model defaults from.")
+		modelType       = flag.String("model-type", "", "Model type to train (linear, neural_network, quantile, gru, littles_law, ensemble). Defaults to the configured type.")
+		outputPath      = flag.String("output", "model.json", "Path to write the trained model artifact.")
+		folds           = flag.Int("folds", 5, "Number of cross-validation folds used during hyperparameter search.")
+		holdoutFraction = flag.Float64("holdout", 0.2, "Fraction of the most recent samples held out for final evaluation.")
+		learningRates   = flag.String("learning-rates", "0.001,0.01,0.05", "Comma-separated learning rates to search (neural network).")
+		regularization  = flag.String("regularization", "0,0.01,0.1,1", "Comma-separated L2 penalties to search (linear model).")
+		hiddenUnits     = flag.String("hidden-units", "4,8,16", "Comma-separated hidden layer widths to search (neural network).")
+		epochs          = flag.Int("epochs", 0, "Training epochs for the neural network. Defaults to the configured value.")
+		logLevel        = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	)
+	flag.Parse()
+
+	logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	if level, err := logrus.ParseLevel(*logLevel); err == nil {
+		logrus.SetLevel(level)
+	}
+
+	if *inputPath == "" {
+		logrus.Fatal("--input is required")
+	}
+	if *holdoutFraction <= 0 || *holdoutFraction >= 1 {
+		logrus.Fatal("--holdout must be between 0 and 1")
+	}
+
+	cfg := hydraconfig.DefaultConfig()
+	if *configPath != "" {
+		loaded, err := hydraconfig.LoadConfig(*configPath)
+		if err != nil {
+			logrus.Fatalf("Failed to load config: %v", err)
+		}
+		cfg = loaded
+	}
+
+	modelCfg := cfg.Scaling.AIModel
+	if *modelType != "" {
+		modelCfg.ModelType = *modelType
+	}
+	if modelCfg.ModelType == "" {
+		modelCfg.ModelType = "linear"
+	}
+	if *epochs > 0 {
+		modelCfg.Hyperparameters.Epochs = *epochs
+	}
+
+	derived, err := scaler.CompileDerivedFeatures(modelCfg.DerivedFeatures)
+	if err != nil {
+		logrus.Fatalf("Invalid derived features: %v", err)
+	}
+	holidays, err := scaler.NewHolidays(modelCfg.Holidays)
+	if err != nil {
+		logrus.Fatalf("Invalid holidays: %v", err)
+	}
+	disabled, err := scaler.ParseFeatureSet(modelCfg.DisabledFeatures)
+	if err != nil {
+		logrus.Fatalf("Invalid disabled features: %v", err)
+	}
+
+	sequenceLength := 0
+	if modelCfg.ModelType == "gru" {
+		sequenceLength = modelCfg.Hyperparameters.SequenceLength
+	}
+
+	data, err := loadData(*inputPath, *inputFormat, derived, holidays, modelCfg.MaintenanceWindows, disabled, sequenceLength, modelCfg.TrainingQuality.IncidentErrorRate)
+	if err != nil {
+		logrus.Fatalf("Failed to load training data: %v", err)
+	}
+
+	if modelCfg.TrainingQuality.Enabled {
+		loaded := len(data)
+		var dropped map[string]int
+		data, dropped = scaler.FilterTrainingData(data, modelCfg.TrainingQuality)
+		logrus.WithFields(logrus.Fields{
+			"discarded": loaded - len(data),
+			"reasons":   dropped,
+		}).Info("Filtered flagged and outlying samples")
+		if len(data) == 0 {
+			logrus.Fatal("No samples left after training quality filtering")
+		}
+	}
+	if modelCfg.RevisionWeighting.Enabled {
+		loaded := len(data)
+		data = scaler.WeightByRevision(data, modelCfg.RevisionWeighting)
+		logrus.WithField("discarded", loaded-len(data)).Info("Weighted samples by deployment revision")
+		if len(data) == 0 {
+			logrus.Fatal("No samples left after revision weighting")
+		}
+	}
+
+	train, holdout := scaler.SplitHoldout(data, *holdoutFraction)
+	logrus.WithFields(logrus.Fields{
+		"samples":    len(data),
+		"train":      len(train),
+		"holdout":    len(holdout),
+		"model_type": modelCfg.ModelType,
+	}).Info("Loaded training data")
+
+	space := modelCfg.Hyperparameters.Search
+	if space.LearningRates, err = parseFloats(*learningRates); err != nil {
+		logrus.Fatalf("Invalid --learning-rates: %v", err)
+	}
+	if space.Regularization, err = parseFloats(*regularization); err != nil {
+		logrus.Fatalf("Invalid --regularization: %v", err)
+	}
+	if space.HiddenUnits, err = parseInts(*hiddenUnits); err != nil {
+		logrus.Fatalf("Invalid --hidden-units: %v", err)
+	}
+
+	// Hyperparameter search using k-fold cross-validation on the training split
+	best, err := scaler.Search(modelCfg, scaler.GridCandidates(modelCfg, space), func(newModel func() scaler.AIModel) (scaler.EvaluationResult, error) {
+		return scaler.CrossValidate(newModel, train, *folds, modelCfg.ClassBalance)
+	})
+	if err != nil {
+		logrus.Fatalf("Hyperparameter search failed: %v", err)
+	}
+	logrus.WithFields(logrus.Fields{
+		"learning_rate":  best.Candidate.LearningRate,
+		"regularization": best.Candidate.Regularization,
+		"hidden_units":   best.Candidate.HiddenUnits,
+		"cv_mse":         best.Evaluation.MSE,
+	}).Info("Selected hyperparameters")
+
+	// Train the final model on the full training split and score it on held-out data
+	finalCfg := best.Candidate.Apply(modelCfg)
+	model := scaler.NewModel(finalCfg)
+	balanced := train
+	if modelCfg.ClassBalance.Enabled {
+		balanced = scaler.BalanceTrainingData(train, modelCfg.ClassBalance)
+	}
+	if err := model.Train(balanced); err != nil {
+		logrus.Fatalf("Failed to train final model: %v", err)
+	}
+
+	evaluation, err := scaler.Evaluate(model, holdout)
+	if err != nil {
+		logrus.Fatalf("Failed to evaluate model: %v", err)
+	}
+
+	artifact, err := scaler.NewModelArtifact(model)
+	if err != nil {
+		logrus.Fatalf("Failed to build model artifact: %v", err)
+	}
+	artifact.TrainingSamples = len(train)
+	artifact.TrainingWindowStart = train[0].Timestamp
+	artifact.TrainingWindowEnd = train[len(train)-1].Timestamp
+	artifact.FeatureReference = scaler.BuildFeatureReference(train)
+	artifact.DerivedFeatures = derived.Names()
+	artifact.DisabledFeatures = disabled.Names()
+	if len(train[0].Features.Windows) > 0 {
+		artifact.FeatureWindows = scaler.WindowNames(cfg.Metrics.AggregationWindows)
+	}
+	artifact.Evaluation = &evaluation
+	artifact.FeatureImportance, err = scaler.ComputeImportance(model, holdout, artifact.DerivedFeatures, artifact.FeatureWindows, disabled)
+	if err != nil {
+		logrus.Fatalf("Failed to compute feature importance: %v", err)
+	}
+	for _, feature := range artifact.FeatureImportance.Features {
+		logrus.WithFields(logrus.Fields{
+			"feature":     feature.Feature,
+			"permutation": feature.Permutation,
+		}).Info("Feature importance")
+	}
+	artifact.Hyperparameters = map[string]float64{
+		"learning_rate":  finalCfg.LearningRate,
+		"regularization": finalCfg.Hyperparameters.Regularization,
+		"hidden_units":   float64(finalCfg.Hyperparameters.HiddenUnits),
+		"epochs":         float64(finalCfg.Hyperparameters.Epochs),
+	}
+
+	if err := scaler.SaveModelArtifact(*outputPath, artifact); err != nil {
+		logrus.Fatalf("Failed to save model artifact: %v", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"output":             *outputPath,
+		"holdout_mse":        evaluation.MSE,
+		"holdout_mae":        evaluation.MAE,
+		"direction_accuracy": evaluation.DirectionAccuracy,
+		"coverage":           evaluation.Coverage,
+	}).Info("Model artifact written")
+	for _, event := range []string{scaler.EventScaleUp, scaler.EventScaleDown, scaler.EventHold} {
+		if result, ok := evaluation.ByEvent[event]; ok {
+			logrus.WithFields(logrus.Fields{
+				"event":              event,
+				"samples":            result.Samples,
+				"holdout_mae":        result.MAE,
+				"direction_accuracy": result.DirectionAccuracy,
+			}).Info("Holdout evaluation by scale event")
+		}
+	}
+}
+
+// loadData reads JSON lines in either TrainingData or MetricsData form.
+// Holiday flags, derived features and sequences are computed for
+// MetricsData input; TrainingData records must already carry them. Disabled
+// features are zeroed and samples in maintenance windows dropped in either form.
+func loadData(path, format string, derived *scaler.DerivedFeatures, holidays scaler.Holidays, windows []hydraconfig.MaintenanceWindowConfig, disabled scaler.FeatureSet, sequenceLength int, incidentErrorRate float64) ([]scaler.TrainingData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		data    []scaler.TrainingData
+		history []*metrics.MetricsData
+	)
+
+	reader := bufio.NewScanner(file)
+	reader.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	line := 0
+	for reader.Scan() {
+		line++
+		text := strings.TrimSpace(reader.Text())
+		if text == "" {
+			continue
+		}
+
+		switch format {
+		case "training":
+			var sample scaler.TrainingData
+			if err := json.Unmarshal([]byte(text), &sample); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if scaler.MaintenanceWindowsFor(windows, sample.Service).Contains(sample.Timestamp) {
+				continue
+			}
+			disabled.Apply(&sample.Features)
+			for i := range sample.Features.Sequence {
+				disabled.Apply(&sample.Features.Sequence[i])
+			}
+			data = append(data, sample)
+		case "metrics":
+			sample := &metrics.MetricsData{}
+			if err := json.Unmarshal([]byte(text), sample); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			history = append(history, sample)
+		default:
+			return nil, fmt.Errorf("unknown input format %q", format)
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+
+	if format == "metrics" {
+		data = scaler.TrainingDataFromMetrics(history, derived, holidays, windows, disabled, sequenceLength, incidentErrorRate)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no usable samples in %s", path)
+	}
+
+	return data, nil
+}
+
+func parseFloats(value string) ([]float64, error) {
+	var values []float64
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no values given")
+	}
+	return values, nil
+}
+
+func parseInts(value string) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no values given")
+	}
+	return values, nil
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Scopes of admin API keys; each includes the ones before it
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeForce = "force"
+)
+
+var scopeRank = map[string]int{ScopeRead: 1, ScopeWrite: 2, ScopeForce: 3}
+
+var adminAPIRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "hydra_route_admin_api_requests_total",
+	Help: "Admin API requests made with API keys, by tenant and status code.",
+}, []string{"tenant", "code"})
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(adminAPIRequests)
+}
+
+// tenant is a holder of an admin API key
+type tenant struct {
+	name   string
+	digest [sha256.Size]byte
+	scope  string
+	quota  *quota
+}
+
+type tenantKey struct{}
+
+// loadTenants reads the API key of every configured tenant
+func loadTenants(keys []config.AdminAPIKeyConfig) ([]*tenant, error) {
+	tenants := make([]*tenant, 0, len(keys))
+	for _, key := range keys {
+		var secret string
+		if key.KeyFile != "" {
+			data, err := os.ReadFile(key.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read API key of %s: %w", key.Name, err)
+			}
+			secret = strings.TrimSpace(string(data))
+		} else {
+			secret = strings.TrimSpace(os.Getenv(key.KeyEnv))
+		}
+		if secret == "" {
+			return nil, fmt.Errorf("API key of %s is empty", key.Name)
+		}
+
+		t := &tenant{name: key.Name, digest: sha256.Sum256([]byte(secret)), scope: key.Scope}
+		if key.RequestsPerMinute > 0 {
+			t.quota = newQuota(key.RequestsPerMinute, key.Burst)
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, nil
+}
+
+// withAuth requires a known API key on every request but the OpenAPI
+// document when tenants are configured, applies the tenant's quota and
+// passes the tenant on to the handlers, which check the scope of writes
+func (s *Server) withAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.tenants) == 0 || r.URL.Path == "/api/v1/openapi.yaml" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		t := s.tenantFor(r)
+		if t == nil {
+			adminAPIRequests.WithLabelValues("", strconv.Itoa(http.StatusUnauthorized)).Inc()
+			w.Header().Set("WWW-Authenticate", `Bearer realm="hydra-route"`)
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or unknown API key"))
+			return
+		}
+		if t.quota != nil {
+			if wait := t.quota.take(time.Now()); wait > 0 {
+				adminAPIRequests.WithLabelValues(t.name, strconv.Itoa(http.StatusTooManyRequests)).Inc()
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				writeError(w, http.StatusTooManyRequests, fmt.Errorf("request quota of %s exceeded", t.name))
+				return
+			}
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), tenantKey{}, t)))
+		adminAPIRequests.WithLabelValues(t.name, strconv.Itoa(recorder.status)).Inc()
+		if r.Method != http.MethodGet {
+			logrus.WithFields(logrus.Fields{
+				"tenant": t.name,
+				"method": r.Method,
+				"path":   r.URL.Path,
+				"status": recorder.status,
+			}).Info("Admin API request")
+		}
+	})
+}
+
+// tenantFor returns the tenant whose key the request carries, as a bearer
+// token or in X-API-Key. Every key is compared in constant time, so the
+// time taken reveals nothing about which one nearly matched.
+func (s *Server) tenantFor(r *http.Request) *tenant {
+	secret := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		secret = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if secret == "" {
+		return nil
+	}
+	digest := sha256.Sum256([]byte(strings.TrimSpace(secret)))
+
+	var found *tenant
+	for _, t := range s.tenants {
+		if subtle.ConstantTimeCompare(digest[:], t.digest[:]) == 1 {
+			found = t
+		}
+	}
+	return found
+}
+
+// authorize checks that the request's tenant has scope, and answers 403
+// when it doesn't. Without configured tenants everything is allowed.
+func authorize(w http.ResponseWriter, r *http.Request, scope string) bool {
+	t, ok := r.Context().Value(tenantKey{}).(*tenant)
+	if !ok || scopeRank[t.scope] >= scopeRank[scope] {
+		return true
+	}
+	logrus.WithFields(logrus.Fields{
+		"tenant": t.name,
+		"scope":  t.scope,
+		"path":   r.URL.Path,
+	}).Warn("Admin API request denied")
+	writeError(w, http.StatusForbidden, fmt.Errorf("API key of %s has scope %s, %s is required", t.name, t.scope, scope))
+	return false
+}
+
+// statusRecorder keeps the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// quota is a token bucket refilled at the tenant's requests per minute
+type quota struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	updated  time.Time
+}
+
+func newQuota(perMinute, burst int) *quota {
+	if burst < 1 {
+		burst = 1
+	}
+	return &quota{interval: time.Minute / time.Duration(perMinute), burst: float64(burst), tokens: float64(burst)}
+}
+
+// take spends a token and returns 0, or returns how long until one is available
+func (q *quota) take(now time.Time) time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.updated.IsZero() {
+		q.tokens = math.Min(q.burst, q.tokens+float64(now.Sub(q.updated))/float64(q.interval))
+	}
+	q.updated = now
+	if q.tokens < 1 {
+		return time.Duration((1 - q.tokens) * float64(q.interval))
+	}
+	q.tokens--
+	return 0
+}
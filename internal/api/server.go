@@ -0,0 +1,344 @@
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/registry"
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// openAPISpec describes the admin API. Keep it in step with the handlers
+// below and with pkg/client.
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// Server exposes the HTTP admin API
+type Server struct {
+	config   config.AdminAPIConfig
+	registry *registry.Registry
+	scaler   *scaler.AIScaler
+	mux      *http.ServeMux
+
+	// Holders of API keys; empty leaves the API open
+	tenants []*tenant
+}
+
+// NewServer creates a new admin API server. The registry may be nil when
+// no model registry is configured.
+func NewServer(cfg config.AdminAPIConfig, modelRegistry *registry.Registry, aiScaler *scaler.AIScaler) *Server {
+	s := &Server{
+		config:   cfg,
+		registry: modelRegistry,
+		scaler:   aiScaler,
+		mux:      http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/api/v1/openapi.yaml", s.handleOpenAPI)
+	s.mux.HandleFunc("/api/v1/models", s.handleModels)
+	s.mux.HandleFunc("/api/v1/models/", s.handleModel)
+	s.mux.HandleFunc("/api/v1/drift", s.handleDrift)
+	s.mux.HandleFunc("/api/v1/rightsizing", s.handleRightSizing)
+	s.mux.HandleFunc("/api/v1/experiment", s.handleExperiment)
+	s.mux.HandleFunc("/api/v1/importance", s.handleImportance)
+	s.mux.HandleFunc("/api/v1/whatif", s.handleWhatIf)
+
+	return s
+}
+
+// Start serves the admin API until the context is cancelled, over TLS when
+// a certificate is configured
+func (s *Server) Start(ctx context.Context) error {
+	tlsConfig, err := newTLSConfig(s.config.TLS)
+	if err != nil {
+		return err
+	}
+	if s.tenants, err = loadTenants(s.config.APIKeys); err != nil {
+		return err
+	}
+	server := &http.Server{
+		Addr:              s.config.BindAddress,
+		Handler:           s.withAuth(s.mux),
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConfig,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		logrus.WithFields(logrus.Fields{
+			"address": s.config.BindAddress,
+			"tls":     tlsConfig != nil,
+			"tenants": len(s.tenants),
+		}).Info("Starting admin API")
+		if tlsConfig != nil {
+			// The certificate comes from TLSConfig.GetCertificate
+			errCh <- server.ListenAndServeTLS("", "")
+		} else {
+			errCh <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		logrus.Info("Stopping admin API")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// NeedLeaderElection makes every replica serve the admin API
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// handleOpenAPI serves GET /api/v1/openapi.yaml with the OpenAPI document of the admin API
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	if _, err := w.Write(openAPISpec); err != nil {
+		logrus.WithError(err).Debug("Failed to write admin API response")
+	}
+}
+
+// registerRequest is the body of POST /api/v1/models
+type registerRequest struct {
+	Version     string                `json:"version"`
+	Description string                `json:"description"`
+	Artifact    *scaler.ModelArtifact `json:"artifact"`
+}
+
+// promoteRequest is the body of POST /api/v1/models/{version}/promote
+type promoteRequest struct {
+	Stage registry.Stage `json:"stage"`
+	Force bool           `json:"force"`
+}
+
+// handleModels serves GET (list) and POST (register) on /api/v1/models
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRegistry(w) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		versions, err := s.registry.List(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		summaries := make([]*registry.ModelVersion, 0, len(versions))
+		for _, mv := range versions {
+			summaries = append(summaries, mv.Summary())
+		}
+		writeJSON(w, http.StatusOK, summaries)
+	case http.MethodPost:
+		if !authorize(w, r, ScopeWrite) {
+			return
+		}
+		var req registerRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+		mv, err := s.registry.Register(r.Context(), req.Version, req.Description, req.Artifact)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		s.syncModels(r.Context())
+		writeJSON(w, http.StatusCreated, mv.Summary())
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleModel serves /api/v1/models/{version}, /api/v1/models/{version}/promote
+// and /api/v1/models/rollback
+func (s *Server) handleModel(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRegistry(w) {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/models/"), "/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "rollback":
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		if !authorize(w, r, ScopeWrite) {
+			return
+		}
+		mv, err := s.registry.Rollback(r.Context())
+		if err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		s.syncModels(r.Context())
+		writeJSON(w, http.StatusOK, mv.Summary())
+	case len(parts) == 1:
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		mv, err := s.registry.Get(r.Context(), parts[0])
+		if err != nil {
+			writeError(w, statusForRegistryError(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, mv)
+	case len(parts) == 2 && parts[1] == "promote":
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		if !authorize(w, r, ScopeWrite) {
+			return
+		}
+		var req promoteRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+		if req.Force && !authorize(w, r, ScopeForce) {
+			return
+		}
+		mv, err := s.registry.Promote(r.Context(), parts[0], req.Stage, req.Force)
+		if err != nil {
+			writeError(w, statusForRegistryError(err), err)
+			return
+		}
+		s.syncModels(r.Context())
+		writeJSON(w, http.StatusOK, mv.Summary())
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("not found"))
+	}
+}
+
+// handleDrift serves GET /api/v1/drift with the latest feature drift evaluation
+func (s *Server) handleDrift(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.scaler.DriftStatus())
+}
+
+// handleRightSizing serves GET /api/v1/rightsizing with the latest right-sizing report
+func (s *Server) handleRightSizing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	report := s.scaler.RightSizingReport()
+	if report == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no right-sizing report yet"))
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleExperiment serves GET /api/v1/experiment with the latest experiment report
+func (s *Server) handleExperiment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	report := s.scaler.ExperimentReport()
+	if report == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no experiment report yet"))
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleImportance serves GET /api/v1/importance with the feature
+// importances of the active model
+func (s *Server) handleImportance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	report := s.scaler.FeatureImportance()
+	if report == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no feature importances yet"))
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleWhatIf serves POST /api/v1/whatif: the decision the scaler would
+// make for the metrics sample in the body, without acting on it
+func (s *Server) handleWhatIf(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	var sample metrics.MetricsData
+	if err := json.NewDecoder(r.Body).Decode(&sample); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	decision, err := s.scaler.WhatIf(&sample)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, decision)
+}
+
+// syncModels applies registry changes to the scaler immediately instead of
+// waiting for the next periodic sync
+func (s *Server) syncModels(ctx context.Context) {
+	if err := s.registry.Sync(ctx, s.scaler); err != nil {
+		logrus.WithError(err).Error("Failed to sync models after registry change")
+	}
+}
+
+func (s *Server) requireRegistry(w http.ResponseWriter) bool {
+	if s.registry == nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("model registry is not configured"))
+		return false
+	}
+	return true
+}
+
+func statusForRegistryError(err error) int {
+	if errors.Is(err, registry.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logrus.WithError(err).Debug("Failed to write admin API response")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// tlsCipherSuites are the TLS 1.2 suites the admin API offers: ECDHE key
+// exchange with AES-GCM only, all FIPS-approved, so the same configuration
+// holds whether or not the binary was built with boringcrypto
+var tlsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// tlsCurves are the FIPS-approved curves offered for key exchange
+var tlsCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384}
+
+// newTLSConfig returns the TLS configuration of the admin API, or nil when
+// no certificate is configured
+func newTLSConfig(cfg config.AdminAPITLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" {
+		return nil, nil
+	}
+	certificates := &certificateFiles{certFile: cfg.CertFile, keyFile: cfg.KeyFile}
+	if _, err := certificates.get(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CipherSuites:     tlsCipherSuites,
+		CurvePreferences: tlsCurves,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return certificates.get()
+		},
+	}
+	if cfg.MinVersion == "1.3" {
+		tlsConfig.MinVersion = tls.VersionTLS13
+	}
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in client CA file %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// certificateFiles loads a key pair and loads it again when either file
+// has been modified, for certificates rotated by cert-manager and the like
+type certificateFiles struct {
+	certFile, keyFile string
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	modified    time.Time
+}
+
+// get returns the current certificate. A rotation caught halfway, with a
+// key that doesn't match the certificate yet, keeps the previous one.
+func (c *certificateFiles) get() (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	modified, err := c.lastModified()
+	if err != nil {
+		if c.certificate != nil {
+			return c.certificate, nil
+		}
+		return nil, err
+	}
+	if c.certificate != nil && !modified.After(c.modified) {
+		return c.certificate, nil
+	}
+	certificate, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		if c.certificate != nil {
+			return c.certificate, nil
+		}
+		return nil, fmt.Errorf("failed to load admin API certificate: %w", err)
+	}
+	c.certificate = &certificate
+	c.modified = modified
+	return c.certificate, nil
+}
+
+// lastModified returns the later modification time of the two files
+func (c *certificateFiles) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
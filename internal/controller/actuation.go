@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// queuedActuation is a scaling action waiting for the actuation rate limiter
+type queuedActuation struct {
+	decision *scaler.ScalingDecision
+	owner    client.Object
+	risk     float64
+	queuedAt time.Time
+}
+
+// actuationQueue limits deployment updates across the cluster. Actions are
+// queued per service, a newer decision replacing a queued one, and applied
+// at the configured rate with the largest SLO risk first, so a cluster-wide
+// traffic event doesn't turn into hundreds of simultaneous writes and
+// rollouts.
+type actuationQueue struct {
+	interval time.Duration // between updates at the sustained rate
+	burst    float64
+
+	mu      sync.Mutex
+	pending map[string]*queuedActuation
+	wake    chan struct{}
+}
+
+func newActuationQueue(cfg config.ActuationRateLimitConfig) *actuationQueue {
+	if cfg.UpdatesPerMinute <= 0 {
+		return nil
+	}
+	return &actuationQueue{
+		interval: time.Minute / time.Duration(cfg.UpdatesPerMinute),
+		burst:    float64(cfg.Burst),
+		pending:  make(map[string]*queuedActuation),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// enqueue queues the action for a service, replacing any queued one
+func (q *actuationQueue) enqueue(key string, decision *scaler.ScalingDecision, owner client.Object) {
+	q.mu.Lock()
+	queuedAt := time.Now()
+	if previous, ok := q.pending[key]; ok {
+		queuedAt = previous.queuedAt
+	}
+	q.pending[key] = &queuedActuation{
+		decision: decision,
+		owner:    owner,
+		risk:     sloRisk(decision),
+		queuedAt: queuedAt,
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// remove drops the queued action of a service, once it is no longer wanted
+func (q *actuationQueue) remove(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.pending, key)
+}
+
+// pop removes and returns the queued action with the largest SLO risk,
+// oldest first among equals
+func (q *actuationQueue) pop() *queuedActuation {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var key string
+	var next *queuedActuation
+	for k, item := range q.pending {
+		if next == nil || item.risk > next.risk || (item.risk == next.risk && item.queuedAt.Before(next.queuedAt)) {
+			key, next = k, item
+		}
+	}
+	delete(q.pending, key)
+	return next
+}
+
+// len returns the number of queued actions
+func (q *actuationQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// run applies queued actions until the context is done. The rate is a token
+// bucket holding up to burst actions and refilling one per interval. An
+// action being applied when the context is cancelled is finished; those
+// still queued are dropped, as the next leader decides afresh.
+func (q *actuationQueue) run(ctx context.Context, apply func(context.Context, *scaler.ScalingDecision, client.Object) error) error {
+	tokens := q.burst
+	updated := time.Now()
+	defer func() {
+		if dropped := q.len(); dropped > 0 {
+			logrus.WithField("dropped", dropped).Info("Dropping queued scaling actions on shutdown")
+		}
+	}()
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if q.len() == 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-q.wake:
+			}
+			continue
+		}
+
+		now := time.Now()
+		tokens = math.Min(q.burst, tokens+float64(now.Sub(updated))/float64(q.interval))
+		updated = now
+		if tokens < 1 {
+			timer := time.NewTimer(time.Duration((1 - tokens) * float64(q.interval)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-timer.C:
+			}
+			continue
+		}
+
+		item := q.pop()
+		if item == nil {
+			continue
+		}
+		tokens--
+
+		log := logrus.WithFields(logrus.Fields{
+			"service":   item.decision.ServiceName,
+			"namespace": item.decision.Namespace,
+			"risk":      item.risk,
+			"queued":    time.Since(item.queuedAt).Round(time.Millisecond),
+			"remaining": q.len(),
+		})
+		log.Debug("Applying queued scaling action")
+		if err := apply(context.WithoutCancel(ctx), item.decision, item.owner); err != nil {
+			log.WithError(err).Error("Failed to process service")
+		}
+	}
+}
+
+// sloRisk ranks a queued action. Scale-ups come first, by how far the
+// service is under-provisioned relative to its current replicas, weighted up
+// by its error rate; scale-downs relieve no SLO risk and come last.
+func sloRisk(decision *scaler.ScalingDecision) float64 {
+	current := math.Max(float64(decision.CurrentReplicas), 1)
+	change := float64(decision.RecommendedReplicas-decision.CurrentReplicas) / current
+	if change <= 0 {
+		return change
+	}
+	if decision.Metrics != nil {
+		change *= 1 + decision.Metrics.ErrorRate/100
+	}
+	return change
+}
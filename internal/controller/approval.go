@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	"github.com/hydraai/hydra-route/internal/scaler"
+)
+
+// HydraRouteApproveAnnotation approves the pending replica change of a
+// ScalingRecommendation when set to "true"
+const HydraRouteApproveAnnotation = "hydra-route.ai/approve"
+
+// approvalNotifyTimeout bounds the request to the approval notification endpoint
+const approvalNotifyTimeout = 10 * time.Second
+
+// approvalNotification is the payload POSTed when approval is requested
+type approvalNotification struct {
+	Service             string    `json:"service"`
+	Namespace           string    `json:"namespace"`
+	CurrentReplicas     int32     `json:"current_replicas"`
+	RecommendedReplicas int32     `json:"recommended_replicas"`
+	Reasoning           string    `json:"reasoning"`
+	ExpiresAt           time.Time `json:"expires_at"`
+}
+
+// requiresApproval reports whether a decision changes replicas by more than
+// the share of the current replicas given by the threshold
+func requiresApproval(threshold float64, decision *scaler.ScalingDecision) bool {
+	if threshold <= 0 {
+		return false
+	}
+	current := math.Max(float64(decision.CurrentReplicas), 1)
+	return math.Abs(float64(decision.RecommendedReplicas-decision.CurrentReplicas))/current > threshold
+}
+
+// checkApproval holds large replica changes until an operator approves them
+// on the service's ScalingRecommendation, and reports whether the decision
+// may be applied. The first large decision opens a pending request; later
+// ones update its replicas. Once approved, the next decision is applied as
+// long as the request hasn't expired; an expired request is marked so and
+// the next large decision opens a new one. A decision that no longer needs
+// approval withdraws the pending request.
+func (r *HydraRouteReconciler) checkApproval(ctx context.Context, decision *scaler.ScalingDecision, recommendation *hydrav1alpha1.ScalingRecommendation, owner client.Object) (bool, error) {
+	cfg := r.Config.Scaling.Approval
+
+	if !requiresApproval(cfg.Threshold, decision) {
+		if recommendation == nil || !pendingApproval(recommendation) {
+			return true, nil
+		}
+		recommendation.Spec.Approval = nil
+		delete(recommendation.Annotations, HydraRouteApproveAnnotation)
+		return true, r.Update(ctx, recommendation)
+	}
+
+	if recommendation == nil {
+		return false, fmt.Errorf("no scaling recommendation to hold the approval request")
+	}
+
+	now := time.Now()
+	approval := recommendation.Spec.Approval
+	approved := recommendation.Annotations[HydraRouteApproveAnnotation] == "true"
+	open := pendingApproval(recommendation) && now.Before(approval.ExpiresAt.Time)
+
+	switch {
+	case open && approved:
+		approvedAt := metav1.NewTime(now)
+		approval.Phase = hydrav1alpha1.ApprovalApproved
+		approval.Replicas = decision.RecommendedReplicas
+		approval.ApprovedAt = &approvedAt
+		delete(recommendation.Annotations, HydraRouteApproveAnnotation)
+		if err := r.Update(ctx, recommendation); err != nil {
+			return false, err
+		}
+		r.approvalEvent(owner, v1.EventTypeNormal, "ScalingApproved",
+			"Scaling %s from %d to %d replicas approved",
+			decision.ServiceName, decision.CurrentReplicas, decision.RecommendedReplicas)
+		return true, nil
+
+	case open:
+		if approval.Replicas == decision.RecommendedReplicas {
+			return false, nil
+		}
+		approval.Replicas = decision.RecommendedReplicas
+		return false, r.Update(ctx, recommendation)
+	}
+
+	if pendingApproval(recommendation) {
+		approval.Phase = hydrav1alpha1.ApprovalExpired
+		delete(recommendation.Annotations, HydraRouteApproveAnnotation)
+		if err := r.Update(ctx, recommendation); err != nil {
+			return false, err
+		}
+		r.approvalEvent(owner, v1.EventTypeWarning, "ScalingApprovalExpired",
+			"Approval to scale %s to %d replicas expired", decision.ServiceName, approval.Replicas)
+		return false, nil
+	}
+
+	// Approvals given before the request opened don't carry over
+	if recommendation.Annotations != nil {
+		delete(recommendation.Annotations, HydraRouteApproveAnnotation)
+	}
+	recommendation.Spec.Approval = &hydrav1alpha1.ApprovalRequest{
+		Phase:       hydrav1alpha1.ApprovalPending,
+		Replicas:    decision.RecommendedReplicas,
+		RequestedAt: metav1.NewTime(now),
+		ExpiresAt:   metav1.NewTime(now.Add(cfg.Expiry)),
+	}
+	if err := r.Update(ctx, recommendation); err != nil {
+		return false, err
+	}
+
+	r.approvalEvent(owner, v1.EventTypeWarning, "ScalingApprovalRequired",
+		"Scaling %s from %d to %d replicas requires approval: kubectl annotate scalingrecommendation -n %s %s %s=true",
+		decision.ServiceName, decision.CurrentReplicas, decision.RecommendedReplicas,
+		decision.Namespace, recommendation.Name, HydraRouteApproveAnnotation)
+	if cfg.NotifyURL != "" {
+		if err := notifyApproval(ctx, cfg.NotifyURL, decision, now.Add(cfg.Expiry)); err != nil {
+			logrus.WithError(err).WithField("service", decision.ServiceName).Warn("Failed to send approval notification")
+		}
+	}
+	return false, nil
+}
+
+// pendingApproval reports whether a recommendation holds a pending approval request
+func pendingApproval(recommendation *hydrav1alpha1.ScalingRecommendation) bool {
+	approval := recommendation.Spec.Approval
+	return approval != nil && approval.Phase == hydrav1alpha1.ApprovalPending
+}
+
+func (r *HydraRouteReconciler) approvalEvent(owner client.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder != nil {
+		r.Recorder.Eventf(owner, eventType, reason, messageFmt, args...)
+	}
+}
+
+// notifyApproval POSTs an approval request to the notification endpoint
+func notifyApproval(ctx context.Context, url string, decision *scaler.ScalingDecision, expiresAt time.Time) error {
+	body, err := json.Marshal(approvalNotification{
+		Service:             decision.ServiceName,
+		Namespace:           decision.Namespace,
+		CurrentReplicas:     decision.CurrentReplicas,
+		RecommendedReplicas: decision.RecommendedReplicas,
+		Reasoning:           decision.Reasoning,
+		ExpiresAt:           expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := (&http.Client{Timeout: approvalNotifyTimeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// HydraRouteFinalizer is added to enabled ingresses so the controller can
+// clean up after them when they are deleted or HydraRoute is disabled
+const HydraRouteFinalizer = "hydra-route.ai/cleanup"
+
+// trackingAnnotations are the deployment annotations the controller adds when it scales
+var trackingAnnotations = []string{
+	"hydra-route.ai/last-scaled",
+	"hydra-route.ai/scale-reason",
+	"hydra-route.ai/confidence",
+	HydraRouteRecommendedReplicasAnnotation,
+	HydraRouteCostDeltaAnnotation,
+	HydraRouteCarbonDeltaAnnotation,
+	HydraRouteDecisionAnnotation,
+	HydraRouteDecisionSignatureAnnotation,
+	HydraRoutePlacementHintAnnotation,
+}
+
+// finalizeIngress cleans up after an ingress that is being deleted or no
+// longer has HydraRoute enabled, then removes the finalizer. Services still
+// routed to by another enabled ingress, or enabled directly, keep their
+// annotations and records.
+func (r *HydraRouteReconciler) finalizeIngress(ctx context.Context, ingress *networkingv1.Ingress) error {
+	log := logrus.WithFields(logrus.Fields{
+		"namespace": ingress.Namespace,
+		"name":      ingress.Name,
+	})
+
+	stillReferenced, err := r.servicesReferencedByOthers(ctx, ingress)
+	if err != nil {
+		return fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	for _, serviceName := range BackendServices(ingress) {
+		if stillReferenced[serviceName] {
+			log.WithField("service", serviceName).Debug("Service still managed through another ingress or directly, keeping its state")
+			if err := r.removeOwner(ctx, serviceName, ingress); err != nil {
+				return fmt.Errorf("failed to update scaling recommendation of service %s: %w", serviceName, err)
+			}
+			continue
+		}
+
+		if err := r.removeTrackingAnnotations(ctx, serviceName, ingress.Namespace); err != nil {
+			return fmt.Errorf("failed to clean up deployment of service %s: %w", serviceName, err)
+		}
+		if err := r.deleteRecommendation(ctx, serviceName, ingress.Namespace); err != nil {
+			return fmt.Errorf("failed to delete scaling recommendation of service %s: %w", serviceName, err)
+		}
+	}
+
+	if controllerutil.RemoveFinalizer(ingress, HydraRouteFinalizer) {
+		if err := r.Update(ctx, ingress); err != nil {
+			return fmt.Errorf("failed to remove finalizer: %w", err)
+		}
+	}
+
+	log.Info("Cleaned up HydraRoute state for ingress")
+	return nil
+}
+
+// servicesReferencedByOthers returns the services of an ingress that another
+// enabled, non-deleted ingress in the namespace also routes to, or that are
+// enabled directly
+func (r *HydraRouteReconciler) servicesReferencedByOthers(ctx context.Context, ingress *networkingv1.Ingress) (map[string]bool, error) {
+	ingressList := &networkingv1.IngressList{}
+	if err := r.List(ctx, ingressList, client.InNamespace(ingress.Namespace)); err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool)
+	for _, serviceName := range BackendServices(ingress) {
+		if r.targetIndex().isDirect(serviceKey(ingress.Namespace, serviceName)) {
+			referenced[serviceName] = true
+		}
+	}
+	for i := range ingressList.Items {
+		other := &ingressList.Items[i]
+		if other.Name == ingress.Name || !other.DeletionTimestamp.IsZero() || !r.isHydraRouteEnabled(ctx, other) {
+			continue
+		}
+		for _, serviceName := range BackendServices(other) {
+			referenced[serviceName] = true
+		}
+	}
+	return referenced, nil
+}
+
+// removeTrackingAnnotations strips the controller's annotations from the
+// deployments and rollouts backing a service
+func (r *HydraRouteReconciler) removeTrackingAnnotations(ctx context.Context, serviceName, namespace string) error {
+	rollouts, err := r.findServiceRollouts(ctx, serviceName, namespace)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if err := r.removeRolloutAnnotations(ctx, rollouts); err != nil {
+		return err
+	}
+
+	deployments, err := r.findServiceDeployments(ctx, serviceName, namespace)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	for _, deployment := range deployments {
+		updated := deployment.DeepCopy()
+		changed := false
+		for _, annotation := range trackingAnnotations {
+			if _, ok := updated.Annotations[annotation]; ok {
+				delete(updated.Annotations, annotation)
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		if err := r.Update(ctx, updated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package controller_test
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/internal/e2e"
+)
+
+// record re-records the cassettes in testdata against controller-runtime's
+// fake client: go test ./internal/controller -run ClusterState -record
+var record = flag.Bool("record", false, "Record the cluster-state cassettes in testdata instead of replaying them")
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		logrus.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+// runClusterState replays a cluster-state scenario from its cassette and
+// returns its result. The replay fails when the controller's writes differ
+// from the recorded ones.
+func runClusterState(t *testing.T, name string) e2e.Result {
+	t.Helper()
+
+	var scenario *e2e.Scenario
+	for _, s := range e2e.ClusterStateScenarios() {
+		if s.Name == name {
+			scenario = &s
+			break
+		}
+	}
+	if scenario == nil {
+		t.Fatalf("unknown cluster-state scenario %q", name)
+	}
+
+	results, err := e2e.RunCassette(context.Background(), e2e.CassetteOptions{
+		Directory: "testdata",
+		Replay:    !*record,
+		Fake:      *record,
+		Scenarios: []e2e.Scenario{*scenario},
+	})
+	if err != nil {
+		t.Fatalf("cassette run failed: %v", err)
+	}
+	result := results[0]
+	if !result.Passed {
+		t.Fatalf("%s: %s", name, result.Message)
+	}
+	return result
+}
+
+func TestClusterStateMissingDeployment(t *testing.T) {
+	// Reconciling and evaluating a service without a deployment must not create one
+	if result := runClusterState(t, "missing-deployment"); result.Replicas != 0 {
+		t.Errorf("expected no deployment, got %d replicas", result.Replicas)
+	}
+}
+
+func TestClusterStateConflictingHPA(t *testing.T) {
+	// The HPA reverts the first scale-up; replica parity then holds the
+	// service instead of fighting it
+	if result := runClusterState(t, "conflicting-hpa"); result.Replicas != 2 {
+		t.Errorf("expected the 2 replicas the HPA pins, got %d", result.Replicas)
+	}
+}
+
+func TestClusterStatePausedRollout(t *testing.T) {
+	// A paused rollout is scaled like any other deployment, from 2 up to the
+	// default max of 10 replicas
+	if result := runClusterState(t, "paused-rollout"); result.Replicas != 10 {
+		t.Errorf("expected 10 replicas, got %d", result.Replicas)
+	}
+}
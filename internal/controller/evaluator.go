@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// evaluator runs an evaluation loop per managed service, independently of
+// ingress reconciles. Each loop first fires at a stable offset within the
+// interval derived from the service key, so services are spread over the
+// interval instead of evaluating together after a restart, and later
+// evaluations are moved by up to the jitter share of the interval either
+// way. In deterministic mode the jitter of each loop is drawn from a source
+// seeded from the seed and the service key, so runs repeat. It runs as a
+// manager runnable, so only the leader evaluates. On shutdown no new
+// evaluations start, and Start returns once those in flight have finished,
+// so their actions are not cut off halfway.
+type evaluator struct {
+	interval time.Duration
+	jitter   float64
+	// Seed of the jitter sources; 0 seeds them from the clock
+	seed     int64
+	evaluate func(ctx context.Context, key string)
+
+	mu sync.Mutex
+	// Context of the runnable; nil until it starts
+	ctx context.Context
+	// Services that should have a loop, and the cancel func of each running loop
+	desired map[string]bool
+	loops   map[string]context.CancelFunc
+
+	// Running loops, waited for on shutdown
+	running sync.WaitGroup
+}
+
+func newEvaluator(interval time.Duration, jitter float64, seed int64, evaluate func(ctx context.Context, key string)) *evaluator {
+	return &evaluator{
+		interval: interval,
+		jitter:   jitter,
+		seed:     seed,
+		evaluate: evaluate,
+		desired:  make(map[string]bool),
+		loops:    make(map[string]context.CancelFunc),
+	}
+}
+
+// Start runs the loops of the managed services until the context is done
+func (e *evaluator) Start(ctx context.Context) error {
+	e.mu.Lock()
+	e.ctx = ctx
+	e.reconcileLoops()
+	e.mu.Unlock()
+
+	<-ctx.Done()
+
+	e.mu.Lock()
+	e.ctx = nil
+	e.loops = make(map[string]context.CancelFunc)
+	e.mu.Unlock()
+
+	e.running.Wait()
+	logrus.Info("Evaluation loops stopped")
+	return nil
+}
+
+// sync sets the services to evaluate, starting loops for new ones and
+// stopping those of services no ingress references anymore
+func (e *evaluator) sync(services []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.desired = make(map[string]bool, len(services))
+	for _, service := range services {
+		e.desired[service] = true
+	}
+	e.reconcileLoops()
+}
+
+// reconcileLoops brings the running loops in line with the desired services;
+// the caller holds the lock
+func (e *evaluator) reconcileLoops() {
+	if e.ctx == nil {
+		return
+	}
+	for service, cancel := range e.loops {
+		if !e.desired[service] {
+			cancel()
+			delete(e.loops, service)
+			logrus.WithField("service", service).Debug("Stopped evaluation loop")
+		}
+	}
+	for service := range e.desired {
+		if _, ok := e.loops[service]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(e.ctx)
+		e.loops[service] = cancel
+		e.running.Add(1)
+		go e.run(ctx, service)
+		logrus.WithField("service", service).Debug("Started evaluation loop")
+	}
+}
+
+// run evaluates a service every interval until its context is cancelled.
+// An evaluation that has started runs to completion even when the context
+// is cancelled meanwhile.
+func (e *evaluator) run(ctx context.Context, service string) {
+	defer e.running.Done()
+	random := e.random(service)
+	timer := time.NewTimer(e.offset(service))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		e.evaluate(context.WithoutCancel(ctx), service)
+		timer.Reset(e.next(random))
+	}
+}
+
+// offset is the stable delay before a service's first evaluation
+func (e *evaluator) offset(service string) time.Duration {
+	return time.Duration(float64(e.interval) * float64(serviceHash(service)%1000) / 1000)
+}
+
+// random returns the jitter source of a service's loop
+func (e *evaluator) random(service string) *rand.Rand {
+	if e.seed == 0 {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rand.New(rand.NewSource(e.seed ^ int64(serviceHash(service))))
+}
+
+func serviceHash(service string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(service))
+	return h.Sum32()
+}
+
+// next is the delay until the following evaluation, the interval moved by
+// a random share of up to the jitter either way
+func (e *evaluator) next(random *rand.Rand) time.Duration {
+	return time.Duration(float64(e.interval) * (1 + e.jitter*(2*random.Float64()-1)))
+}
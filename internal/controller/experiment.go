@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// ExperimentAnalyzer periodically compares the cohorts of the configured
+// experiment while it runs, and once more when it ends. The report is kept
+// on the scaler for the admin API; the final one is POSTed to the
+// notification endpoint. It runs as a manager runnable, so only the leader
+// compares.
+type ExperimentAnalyzer struct {
+	Collector *metrics.Collector
+	Scaler    *scaler.AIScaler
+	Config    config.ExperimentConfig
+
+	// Interval at which the collector is polled until its first cycle completes
+	CollectionInterval time.Duration
+}
+
+// Start compares the cohorts every interval from the start of the
+// experiment until it has ended or the context is done
+func (e *ExperimentAnalyzer) Start(ctx context.Context) error {
+	start, end, ok := scaler.ExperimentWindow(e.Config)
+	if !ok {
+		return nil
+	}
+	for !e.Collector.Ready() || time.Now().Before(start) {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(e.CollectionInterval):
+		}
+	}
+
+	// Only a leader that saw the experiment run sends the final report, so
+	// restarts after the end don't repeat the notification
+	running := time.Now().Before(end)
+
+	ticker := time.NewTicker(e.Config.Interval)
+	defer ticker.Stop()
+
+	for {
+		report := e.compare(ctx, start, end)
+		if report != nil && report.Completed {
+			if running && e.Config.NotifyURL != "" {
+				if err := notifyReport(ctx, e.Config.NotifyURL, report); err != nil {
+					logrus.WithError(err).Warn("Failed to send experiment notification")
+				}
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// compare builds and stores a report over the experiment so far
+func (e *ExperimentAnalyzer) compare(ctx context.Context, start, end time.Time) *scaler.ExperimentReport {
+	now := time.Now()
+	until := end
+	if now.Before(until) {
+		until = now
+	}
+
+	histories := make(map[string][]*metrics.MetricsData)
+	for _, key := range e.Collector.Services() {
+		namespace, name, _ := strings.Cut(key, "/")
+		history, err := e.Collector.History(ctx, name, namespace, start, until)
+		if err != nil {
+			logrus.WithError(err).WithField("service", key).Warn("Failed to read history for experiment")
+			continue
+		}
+		histories[key] = history
+	}
+
+	report := e.Scaler.CompareCohorts(histories, now)
+	if report == nil {
+		return nil
+	}
+	e.Scaler.SetExperimentReport(report)
+
+	fields := logrus.Fields{
+		"experiment":           report.Name,
+		"completed":            report.Completed,
+		"slo_attainment_delta": report.SLOAttainmentDelta,
+		"replicas_delta":       report.ReplicasDelta,
+	}
+	for _, cohort := range report.Cohorts {
+		fields[cohort.Cohort+"_slo_attainment"] = cohort.SLOAttainment
+		fields[cohort.Cohort+"_mean_replicas"] = cohort.MeanReplicas
+	}
+	logrus.WithFields(fields).Info("Experiment cohorts compared")
+	return report
+}
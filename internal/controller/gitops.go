@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hydraai/hydra-route/internal/scaler"
+)
+
+// HydraRouteRecommendedReplicasAnnotation carries the recommended replica
+// count of a deployment in the annotation GitOps mode
+const HydraRouteRecommendedReplicasAnnotation = "hydra-route.ai/recommended-replicas"
+
+// Estimated change in hourly cost and gCO2e of the service's latest scaling
+// action, set when scaling.cost.annotate_deployments is enabled
+const (
+	HydraRouteCostDeltaAnnotation   = "hydra-route.ai/cost-delta-per-hour"
+	HydraRouteCarbonDeltaAnnotation = "hydra-route.ai/carbon-delta-grams-per-hour"
+)
+
+// GitOps compatibility modes
+const (
+	// GitOpsModeAnnotation leaves spec.replicas alone and only records the
+	// recommendation for the GitOps tool or another actuator to apply
+	GitOpsModeAnnotation = "annotation"
+
+	// GitOpsModeServerSideApply sets spec.replicas through server-side apply
+	// under a dedicated field manager that GitOps tools can be told to ignore
+	GitOpsModeServerSideApply = "server-side-apply"
+)
+
+// writeReplicas sets the replicas of one deployment according to the
+// configured GitOps mode, together with the tracking annotations
+func (r *HydraRouteReconciler) writeReplicas(ctx context.Context, deployment *appsv1.Deployment, replicas int32, decision *scaler.ScalingDecision, owner client.Object) error {
+	annotations := r.scalingAnnotations(ctx, decision, "Deployment", deployment, replicas)
+
+	gitops := r.Config.General.GitOps
+	if gitops.Mode != GitOpsModeAnnotation {
+		r.warnForeignReplicaManagers(deployment, "deployment", gitops.FieldManager, owner)
+	}
+
+	switch gitops.Mode {
+	case GitOpsModeAnnotation:
+		annotations[HydraRouteRecommendedReplicasAnnotation] = fmt.Sprintf("%d", replicas)
+		updated := deployment.DeepCopy()
+		if updated.Annotations == nil {
+			updated.Annotations = make(map[string]string)
+		}
+		for key, value := range annotations {
+			updated.Annotations[key] = value
+		}
+		return r.Patch(ctx, updated, client.MergeFrom(deployment))
+
+	case GitOpsModeServerSideApply:
+		metadataAnnotations := make(map[string]interface{}, len(annotations))
+		for key, value := range annotations {
+			metadataAnnotations[key] = value
+		}
+		apply := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":        deployment.Name,
+				"namespace":   deployment.Namespace,
+				"annotations": metadataAnnotations,
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(replicas),
+			},
+		}}
+		return r.Patch(ctx, apply, client.Apply, client.FieldOwner(gitops.FieldManager), client.ForceOwnership)
+
+	default:
+		updated := deployment.DeepCopy()
+		updated.Spec.Replicas = &replicas
+		if updated.Annotations == nil {
+			updated.Annotations = make(map[string]string)
+		}
+		for key, value := range annotations {
+			updated.Annotations[key] = value
+		}
+		return r.Update(ctx, updated)
+	}
+}
+
+// scalingAnnotations returns the annotations written with a scaling action
+// that sets a workload to replicas
+func (r *HydraRouteReconciler) scalingAnnotations(ctx context.Context, decision *scaler.ScalingDecision, kind string, workload client.Object, replicas int32) map[string]string {
+	annotations := map[string]string{
+		trackingAnnotations[0]: time.Now().Format(time.RFC3339),
+		trackingAnnotations[1]: decision.Reasoning,
+		trackingAnnotations[2]: fmt.Sprintf("%.2f", decision.Confidence),
+	}
+	if r.Config.Scaling.Cost.AnnotateDeployments && decision.Cost != nil {
+		annotations[HydraRouteCostDeltaAnnotation] = fmt.Sprintf("%.4f", decision.Cost.CostPerHour)
+		annotations[HydraRouteCarbonDeltaAnnotation] = fmt.Sprintf("%.1f", decision.Cost.CarbonPerHour)
+	}
+	if r.Config.Scaling.ThermalPlacement.Enabled {
+		annotations[HydraRoutePlacementHintAnnotation] = r.placementHint(decision)
+	}
+	r.signDecision(ctx, annotations, kind, workload.GetNamespace(), workload.GetName(), replicas, decision)
+	return annotations
+}
+
+// warnForeignReplicaManagers reports field managers other than HydraRoute
+// that own spec.replicas of a workload, a deployment or rollout. A GitOps
+// tool owning the field will revert every scaling action unless it is told
+// to ignore replicas.
+func (r *HydraRouteReconciler) warnForeignReplicaManagers(workload client.Object, kind, fieldManager string, owner client.Object) {
+	managers := replicaManagers(workload)
+	var foreign []string
+	for _, manager := range managers {
+		if manager != fieldManager {
+			foreign = append(foreign, manager)
+		}
+	}
+	if len(foreign) == 0 {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		kind:        workload.GetName(),
+		"namespace": workload.GetNamespace(),
+		"managers":  foreign,
+	}).Warn("Other field managers own spec.replicas and may revert scaling; configure them to ignore replicas")
+	if r.Recorder != nil {
+		r.Recorder.Eventf(owner, v1.EventTypeWarning, "ReplicasManagedElsewhere",
+			"spec.replicas of %s %s is also managed by %v, which may revert scaling", kind, workload.GetName(), foreign)
+	}
+}
+
+// replicaManagers returns the field managers recorded as owning spec.replicas
+func replicaManagers(workload client.Object) []string {
+	var managers []string
+	for _, entry := range workload.GetManagedFields() {
+		if entry.FieldsV1 == nil || entry.Subresource != "" {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		spec, ok := fields["f:spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := spec["f:replicas"]; ok {
+			managers = append(managers, entry.Manager)
+		}
+	}
+	return managers
+}
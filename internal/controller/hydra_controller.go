@@ -2,7 +2,12 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -11,13 +16,21 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
 	"github.com/hydraai/hydra-route/internal/metrics"
 	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/internal/signing"
 	"github.com/hydraai/hydra-route/pkg/config"
 )
 
@@ -26,6 +39,7 @@ const (
 	HydraRouteMinReplicasAnnotation = "hydra-route.ai/min-replicas"
 	HydraRouteMaxReplicasAnnotation = "hydra-route.ai/max-replicas"
 	HydraRouteTargetAnnotation      = "hydra-route.ai/target"
+	HydraRouteNamespaceLabel        = "hydra-route.ai/enabled"
 	RequeueAfter                    = 30 * time.Second
 )
 
@@ -36,6 +50,27 @@ type HydraRouteReconciler struct {
 	MetricsCollector *metrics.Collector
 	AIScaler         *scaler.AIScaler
 	Config           *config.Config
+	Statuses         *StatusTracker
+	Recorder         record.EventRecorder
+
+	// Signer signs decision records; nil leaves them unsigned
+	Signer signing.Signer
+
+	// ModelReady reports whether the model has been loaded or fallen back;
+	// nil means the model is always ready
+	ModelReady func() bool
+
+	targets     *targetIndex
+	actuations  *actuationQueue
+	evaluations *evaluator
+
+	// Scale-ups whose new pods are followed until Ready; nil unless
+	// scaling.pod_readiness is enabled
+	readiness *readinessWatch
+
+	// Serializes syncTargets across concurrent reconciles, so an older
+	// snapshot of the target index never replaces a newer one
+	syncMu sync.Mutex
 }
 
 // NewController creates a new controller for HydraRoute
@@ -66,50 +101,156 @@ func (r *HydraRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	ingress := &networkingv1.Ingress{}
 	if err := r.Get(ctx, req.NamespacedName, ingress); err != nil {
 		log.WithError(err).Debug("Unable to fetch ingress")
+		if client.IgnoreNotFound(err) == nil {
+			r.targetIndex().remove(req.String())
+			r.syncTargets()
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// Check if HydraRoute is enabled for this ingress
-	if !r.isHydraRouteEnabled(ingress) {
+	// Clean up once the ingress is deleted or HydraRoute is disabled for it
+	if !ingress.DeletionTimestamp.IsZero() || !r.isHydraRouteEnabled(ctx, ingress) {
 		log.Debug("HydraRoute not enabled for this ingress")
+		r.targetIndex().remove(req.String())
+		r.syncTargets()
+		if controllerutil.ContainsFinalizer(ingress, HydraRouteFinalizer) {
+			if err := r.finalizeIngress(ctx, ingress); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 		return ctrl.Result{}, nil
 	}
 
-	// Process each service referenced by the ingress
-	for _, rule := range ingress.Spec.Rules {
-		if rule.HTTP == nil {
-			continue
+	if controllerutil.AddFinalizer(ingress, HydraRouteFinalizer) {
+		if err := r.Update(ctx, ingress); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
 		}
+	}
 
-		for _, path := range rule.HTTP.Paths {
-			serviceName := path.Backend.Service.Name
-			if serviceName == "" {
-				continue
-			}
+	// Services can be referenced by several paths and by several ingresses;
+	// each gets one evaluation loop, whichever ingresses reference it
+	r.targetIndex().update(req.String(), req.Namespace, BackendServices(ingress))
+	r.syncTargets()
 
-			if err := r.processService(ctx, serviceName, req.Namespace, ingress); err != nil {
-				log.WithError(err).WithField("service", serviceName).Error("Failed to process service")
-				continue
-			}
+	log.Debug("Reconciliation completed")
+	return ctrl.Result{}, nil
+}
+
+// syncTargets registers every referenced or directly enabled service with
+// the metrics collector and runs an evaluation loop for it
+func (r *HydraRouteReconciler) syncTargets() {
+	r.syncMu.Lock()
+	defer r.syncMu.Unlock()
+
+	services := r.targetIndex().services()
+	r.MetricsCollector.SetWithoutIngress(r.targetIndex().withoutIngress())
+	r.MetricsCollector.SetTargets(services)
+	r.forgetQuarantines(services)
+	if r.evaluations != nil {
+		r.evaluations.sync(services)
+	}
+}
+
+// EvaluateAll evaluates every referenced service once, in key order. Tests
+// use it to step evaluations without the manager's loops.
+func (r *HydraRouteReconciler) EvaluateAll(ctx context.Context) {
+	for _, key := range r.targetIndex().services() {
+		r.evaluateService(ctx, key)
+	}
+}
+
+// evaluateService makes and acts on a scaling decision for a service. The
+// first referencing ingress in key order supplies its scaling settings and
+// receives its events, or the service itself when it is enabled without an
+// ingress. The settings are resolved on every evaluation so policy and
+// annotation changes apply without a reconcile.
+func (r *HydraRouteReconciler) evaluateService(ctx context.Context, key string) {
+	log := logrus.WithField("service", key)
+
+	// Don't act on an empty metrics store or a model that is still loading right after startup
+	if !r.MetricsCollector.Ready() || (r.ModelReady != nil && !r.ModelReady()) {
+		log.Debug("Waiting for the first metrics collection cycle and model load")
+		return
+	}
+
+	// Quarantined services are left out until their probe
+	if r.checkQuarantine(key) {
+		log.Debug("Service is quarantined")
+		return
+	}
+
+	namespace, name, _ := strings.Cut(key, "/")
+
+	var owner client.Object
+	if ingresses := r.targetIndex().ingressesFor(key); len(ingresses) > 0 {
+		ingressNamespace, ingressName, _ := strings.Cut(ingresses[0], "/")
+		ingress := &networkingv1.Ingress{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ingressNamespace, Name: ingressName}, ingress); err != nil {
+			log.WithError(err).Debug("Unable to fetch ingress")
+			return
+		}
+		if !ingress.DeletionTimestamp.IsZero() || !r.isHydraRouteEnabled(ctx, ingress) {
+			return
+		}
+		owner = ingress
+	} else if r.targetIndex().isDirect(key) {
+		service := &v1.Service{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, service); err != nil {
+			log.WithError(err).Debug("Unable to fetch service")
+			return
+		}
+		if !service.DeletionTimestamp.IsZero() || !r.isServiceEnabled(ctx, service) {
+			return
 		}
+		owner = service
+	} else {
+		return
 	}
 
-	log.Debug("Reconciliation completed")
-	return ctrl.Result{RequeueAfter: RequeueAfter}, nil
+	settings, err := r.serviceSettings(ctx, owner)
+	if err != nil {
+		log.WithError(err).Warn("Failed to resolve scaling settings, using configuration defaults")
+	}
+	r.AIScaler.SetServiceSettings(key, settings)
+
+	err = r.processService(ctx, name, namespace, owner)
+	switch {
+	case errors.Is(err, errNoMetrics):
+		log.Debug("No metrics available for service")
+	case err != nil:
+		log.WithError(err).Error("Failed to process service")
+	}
+	r.recordEvaluation(key, owner, err)
+}
+
+// targetIndex returns the shared service index, creating it on first use
+func (r *HydraRouteReconciler) targetIndex() *targetIndex {
+	if r.targets == nil {
+		r.targets = newTargetIndex()
+	}
+	return r.targets
+}
+
+// evaluationInterval is the time between decisions for a service
+func (r *HydraRouteReconciler) evaluationInterval() time.Duration {
+	if r.Config != nil && r.Config.Scaling.EvaluationInterval > 0 {
+		return r.Config.Scaling.EvaluationInterval
+	}
+	return RequeueAfter
 }
 
 // processService handles scaling decisions for a specific service
-func (r *HydraRouteReconciler) processService(ctx context.Context, serviceName, namespace string, ingress *networkingv1.Ingress) error {
+func (r *HydraRouteReconciler) processService(ctx context.Context, serviceName, namespace string, owner client.Object) error {
 	log := logrus.WithFields(logrus.Fields{
 		"service":   serviceName,
 		"namespace": namespace,
+		"ingresses": r.targetIndex().ingressesFor(serviceKey(namespace, serviceName)),
 	})
 
 	// Get current metrics for the service
 	metricsData := r.MetricsCollector.GetLatestMetrics(serviceName, namespace)
 	if metricsData == nil {
-		log.Debug("No metrics available for service")
-		return nil
+		return errNoMetrics
 	}
 
 	// Make scaling decision using AI
@@ -123,74 +264,210 @@ func (r *HydraRouteReconciler) processService(ctx context.Context, serviceName,
 		return nil
 	}
 
+	if cp := decision.ChangePoint; cp != nil && r.Recorder != nil {
+		r.Recorder.Eventf(owner, v1.EventTypeWarning, "RegimeChange",
+			"Service %s: %s shifted %s from %.3g to %.3g (action: %s)",
+			serviceName, cp.Signal, cp.Direction, cp.Baseline, cp.Current, r.Config.Scaling.AIModel.ChangePoint.Action)
+	}
+
+	if step := decision.SLOEscalation; step != nil && r.Recorder != nil {
+		message := fmt.Sprintf("Service %s: SLO breached on %d consecutive evaluations despite scaling, replica floor raised from %d to %d",
+			serviceName, step.Breaches, step.FromReplicas, step.Replicas)
+		if step.Capped {
+			message += " (cap reached)"
+		}
+		r.Recorder.Event(owner, v1.EventTypeWarning, "SLOEscalation", message)
+	}
+
+	if limit := decision.CapacityLimit; limit != nil && r.Recorder != nil {
+		r.Recorder.Eventf(owner, v1.EventTypeWarning, "CapacityLimited",
+			"Service %s: recommendations clamped at max replicas %d on %d consecutive evaluations, latest recommendation was %d",
+			serviceName, limit.MaxReplicas, limit.Evaluations, limit.Unclamped)
+	}
+
+	if reason, ok := scaler.FindReason(decision.Reasons, scaler.ReasonZoneSpreadUnsatisfied); ok && r.Recorder != nil {
+		r.Recorder.Eventf(owner, v1.EventTypeWarning, "ZoneSpreadUnsatisfied",
+			"Service %s: %d replicas can't keep %s in each of %s zones, %s are needed",
+			serviceName, decision.RecommendedReplicas, reason.Parameters["per_zone"], reason.Parameters["zones"], reason.Parameters["replicas"])
+	}
+
+	if err := r.coordinateEjection(ctx, decision); err != nil {
+		log.WithError(err).Warn("Failed to coordinate outlier detection")
+	}
+
 	log.WithFields(logrus.Fields{
 		"current_replicas":     decision.CurrentReplicas,
 		"recommended_replicas": decision.RecommendedReplicas,
 		"confidence":           decision.Confidence,
 		"reasoning":            decision.Reasoning,
+		"reasons":              scaler.ReasonCodes(decision.Reasons),
 	}).Info("Scaling decision made")
 
+	var recommendation *hydrav1alpha1.ScalingRecommendation
+	if r.Config.General.RecordRecommendations {
+		recommendation, err = r.recordRecommendation(ctx, decision, owner, r.targetIndex().ingressesFor(serviceKey(namespace, serviceName)))
+		if err != nil {
+			log.WithError(err).Warn("Failed to record scaling recommendation")
+		}
+	}
+
+	// Make sure the previous change took effect before making another
+	if r.Config.General.ReplicaParity.Enabled {
+		hold, err := r.checkParity(ctx, decision, owner)
+		if err != nil {
+			return fmt.Errorf("failed to check replica parity: %w", err)
+		}
+		if hold != "" && decision.CurrentReplicas != decision.RecommendedReplicas {
+			log.WithField("reason", hold).Info("Holding scaling action")
+			return nil
+		}
+	}
+
+	// Leave Argo Rollouts canary analyses undisturbed
+	if r.Config.General.ArgoRollouts.Enabled && decision.CurrentReplicas != decision.RecommendedReplicas {
+		hold, err := r.checkRolloutAnalysis(ctx, decision, owner)
+		if err != nil {
+			return fmt.Errorf("failed to check rollouts: %w", err)
+		}
+		if hold != "" {
+			log.WithField("reason", hold).Info("Holding scaling action")
+			return nil
+		}
+	}
+
+	// Hold large changes until an operator approves them
+	if r.Config.Scaling.Approval.Threshold > 0 {
+		allowed, err := r.checkApproval(ctx, decision, recommendation, owner)
+		if err != nil {
+			return fmt.Errorf("failed to check approval: %w", err)
+		}
+		if !allowed {
+			log.Info("Scaling action awaiting approval")
+			return nil
+		}
+	}
+
 	// Skip if no scaling is needed
 	if decision.CurrentReplicas == decision.RecommendedReplicas {
 		log.Debug("No scaling needed")
+		if r.actuations != nil {
+			r.actuations.remove(serviceKey(namespace, serviceName))
+		}
+		return nil
+	}
+
+	// Give the veto webhook a chance to stop the action
+	if r.Config.General.VetoWebhook.URL != "" {
+		if allowed, reason := r.checkVeto(ctx, decision, owner); !allowed {
+			log.WithField("reason", reason).Warn("Scaling action vetoed")
+			if r.Recorder != nil {
+				r.Recorder.Eventf(owner, v1.EventTypeWarning, "ScalingVetoed",
+					"Scaling %s from %d to %d replicas vetoed: %s",
+					serviceName, decision.CurrentReplicas, decision.RecommendedReplicas, reason)
+			}
+			return nil
+		}
+	}
+
+	// Check the action against the organization's Rego policies
+	if r.Config.General.OPA.URL != "" {
+		if allowed, reason := r.checkOPA(ctx, decision, owner); !allowed {
+			log.WithField("reason", reason).Warn("Scaling action denied by policy")
+			if r.Recorder != nil {
+				r.Recorder.Eventf(owner, v1.EventTypeWarning, "ScalingDeniedByPolicy",
+					"Scaling %s from %d to %d replicas denied by policy: %s",
+					serviceName, decision.CurrentReplicas, decision.RecommendedReplicas, reason)
+			}
+			return nil
+		}
+	}
+
+	// Leave the action to the actuation rate limiter when one is configured
+	if r.actuations != nil {
+		r.actuations.enqueue(serviceKey(namespace, serviceName), decision, owner)
+		log.Debug("Scaling action queued")
 		return nil
 	}
 
-	// Apply scaling decision
-	if err := r.applyScalingDecision(ctx, decision, ingress); err != nil {
+	return r.actuate(ctx, decision, owner)
+}
+
+// actuate applies a scaling decision and records its outcome
+func (r *HydraRouteReconciler) actuate(ctx context.Context, decision *scaler.ScalingDecision, owner client.Object) error {
+	err := r.applyScalingDecision(ctx, decision, owner)
+	r.Statuses.RecordActuation(serviceKey(decision.Namespace, decision.ServiceName), err)
+	if err != nil {
 		return fmt.Errorf("failed to apply scaling decision: %w", err)
 	}
+	if !r.Config.General.DryRun && r.Config.General.GitOps.Mode != GitOpsModeAnnotation {
+		r.Statuses.RecordApplied(serviceKey(decision.Namespace, decision.ServiceName), decision.RecommendedReplicas)
+		r.Statuses.RecordAction(serviceKey(decision.Namespace, decision.ServiceName), decision.CurrentReplicas, decision.RecommendedReplicas)
+		if r.readiness != nil && decision.RecommendedReplicas > decision.CurrentReplicas {
+			r.awaitReady(ctx, decision)
+		}
+		if r.Config.General.RecordRecommendations {
+			if err := r.recordLastAction(ctx, decision); err != nil {
+				logrus.WithError(err).WithField("service", decision.ServiceName).Warn("Failed to record last scaling action")
+			}
+		}
+	}
 
 	// Record the scaling event
-	if err := r.recordScalingEvent(ctx, decision, ingress); err != nil {
-		log.WithError(err).Warn("Failed to record scaling event")
+	if err := r.recordScalingEvent(ctx, decision, owner); err != nil {
+		logrus.WithError(err).WithField("service", decision.ServiceName).Warn("Failed to record scaling event")
 	}
 
 	return nil
 }
 
-// applyScalingDecision applies the scaling decision to the deployment
-func (r *HydraRouteReconciler) applyScalingDecision(ctx context.Context, decision *scaler.ScalingDecision, ingress *networkingv1.Ingress) error {
-	// Find the deployment for the service
-	deployment, err := r.findServiceDeployment(ctx, decision.ServiceName, decision.Namespace)
+// applyScalingDecision applies the scaling decision to the deployments, or
+// Argo Rollouts, backing the service, splitting the recommended replicas
+// between them
+func (r *HydraRouteReconciler) applyScalingDecision(ctx context.Context, decision *scaler.ScalingDecision, owner client.Object) error {
+	rollouts, err := r.findServiceRollouts(ctx, decision.ServiceName, decision.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to find rollouts: %w", err)
+	}
+	if len(rollouts) > 0 {
+		return r.scaleRollouts(ctx, rollouts, decision, owner)
+	}
+
+	// Find the deployments for the service
+	deployments, err := r.findServiceDeployments(ctx, decision.ServiceName, decision.Namespace)
 	if err != nil {
 		return fmt.Errorf("failed to find deployment: %w", err)
 	}
 
-	if deployment == nil {
+	if len(deployments) == 0 {
 		return fmt.Errorf("no deployment found for service %s", decision.ServiceName)
 	}
 
+	replicas := splitReplicas(decision.RecommendedReplicas, deploymentWeights(r.Config.Scaling.DeploymentSplit, deployments))
+
 	// Check if we should perform dry run
 	if r.Config.General.DryRun {
-		logrus.WithFields(logrus.Fields{
-			"service":              decision.ServiceName,
-			"namespace":            decision.Namespace,
-			"current_replicas":     decision.CurrentReplicas,
-			"recommended_replicas": decision.RecommendedReplicas,
-		}).Info("DRY RUN: Would scale deployment")
+		for i, deployment := range deployments {
+			logrus.WithFields(logrus.Fields{
+				"service":              decision.ServiceName,
+				"namespace":            decision.Namespace,
+				"deployment":           deployment.Name,
+				"current_replicas":     decision.CurrentReplicas,
+				"recommended_replicas": replicas[i],
+			}).Info("DRY RUN: Would scale deployment")
+		}
 		return nil
 	}
 
-	// Update deployment replicas
-	updatedDeployment := deployment.DeepCopy()
-	updatedDeployment.Spec.Replicas = &decision.RecommendedReplicas
-
-	// Add annotations for tracking
-	if updatedDeployment.Annotations == nil {
-		updatedDeployment.Annotations = make(map[string]string)
-	}
-	updatedDeployment.Annotations["hydra-route.ai/last-scaled"] = time.Now().Format(time.RFC3339)
-	updatedDeployment.Annotations["hydra-route.ai/scale-reason"] = decision.Reasoning
-	updatedDeployment.Annotations["hydra-route.ai/confidence"] = fmt.Sprintf("%.2f", decision.Confidence)
-
-	if err := r.Update(ctx, updatedDeployment); err != nil {
-		return fmt.Errorf("failed to update deployment: %w", err)
+	for i, deployment := range deployments {
+		if err := r.writeReplicas(ctx, deployment, replicas[i], decision, owner); err != nil {
+			return fmt.Errorf("failed to update deployment %s: %w", deployment.Name, err)
+		}
 	}
 
 	logrus.WithFields(logrus.Fields{
 		"service":              decision.ServiceName,
 		"namespace":            decision.Namespace,
+		"deployments":          len(deployments),
 		"current_replicas":     decision.CurrentReplicas,
 		"recommended_replicas": decision.RecommendedReplicas,
 		"confidence":           decision.Confidence,
@@ -199,8 +476,8 @@ func (r *HydraRouteReconciler) applyScalingDecision(ctx context.Context, decisio
 	return nil
 }
 
-// findServiceDeployment finds the deployment that backs a service
-func (r *HydraRouteReconciler) findServiceDeployment(ctx context.Context, serviceName, namespace string) (*appsv1.Deployment, error) {
+// findServiceDeployments finds the deployments that back a service, by name
+func (r *HydraRouteReconciler) findServiceDeployments(ctx context.Context, serviceName, namespace string) ([]*appsv1.Deployment, error) {
 	// Get the service first
 	service := &v1.Service{}
 	if err := r.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: namespace}, service); err != nil {
@@ -213,14 +490,16 @@ func (r *HydraRouteReconciler) findServiceDeployment(ctx context.Context, servic
 		return nil, err
 	}
 
-	// Find deployment with matching labels
-	for _, deployment := range deploymentList.Items {
-		if r.deploymentMatchesService(&deployment, service) {
-			return &deployment, nil
+	// Find deployments with matching labels
+	var deployments []*appsv1.Deployment
+	for i := range deploymentList.Items {
+		if r.deploymentMatchesService(&deploymentList.Items[i], service) {
+			deployments = append(deployments, &deploymentList.Items[i])
 		}
 	}
+	sort.Slice(deployments, func(i, j int) bool { return deployments[i].Name < deployments[j].Name })
 
-	return nil, nil
+	return deployments, nil
 }
 
 // deploymentMatchesService checks if a deployment's pods would be selected by a service
@@ -240,42 +519,185 @@ func (r *HydraRouteReconciler) deploymentMatchesService(deployment *appsv1.Deplo
 }
 
 // recordScalingEvent creates an event to record the scaling decision
-func (r *HydraRouteReconciler) recordScalingEvent(ctx context.Context, decision *scaler.ScalingDecision, ingress *networkingv1.Ingress) error {
+func (r *HydraRouteReconciler) recordScalingEvent(ctx context.Context, decision *scaler.ScalingDecision, owner client.Object) error {
 	// In a real implementation, you would create a Kubernetes event
 	// For now, we'll just log it
-	logrus.WithFields(logrus.Fields{
+	log := logrus.WithFields(logrus.Fields{
 		"service":              decision.ServiceName,
 		"namespace":            decision.Namespace,
 		"current_replicas":     decision.CurrentReplicas,
 		"recommended_replicas": decision.RecommendedReplicas,
 		"confidence":           decision.Confidence,
 		"reasoning":            decision.Reasoning,
-	}).Info("Scaling event recorded")
+		"reasons":              scaler.ReasonCodes(decision.Reasons),
+	})
+	if decision.Cost != nil {
+		log = log.WithFields(logrus.Fields{
+			"cost_delta_per_hour":         decision.Cost.CostPerHour,
+			"carbon_delta_grams_per_hour": decision.Cost.CarbonPerHour,
+		})
+	}
+	log.Info("Scaling event recorded")
 
 	return nil
 }
 
 // isHydraRouteEnabled checks if HydraRoute is enabled for an ingress
-func (r *HydraRouteReconciler) isHydraRouteEnabled(ingress *networkingv1.Ingress) bool {
-	if ingress.Annotations == nil {
+func (r *HydraRouteReconciler) isHydraRouteEnabled(ctx context.Context, ingress *networkingv1.Ingress) bool {
+	return isHydraRouteEnabledOn(ctx, r.Client, r.Config.General.NamespaceOptIn, ingress)
+}
+
+// isHydraRouteEnabledOn checks the enable annotation of an ingress. Without
+// the annotation, and with namespace opt-in, the ingress is enabled when its
+// namespace carries the enable label; an annotation set to anything but
+// "true" opts the ingress out either way.
+func isHydraRouteEnabledOn(ctx context.Context, reader client.Reader, namespaceOptIn bool, ingress *networkingv1.Ingress) bool {
+	if enabled, exists := ingress.Annotations[HydraRouteAnnotation]; exists {
+		return enabled == "true"
+	}
+	if !namespaceOptIn {
 		return false
 	}
 
-	enabled, exists := ingress.Annotations[HydraRouteAnnotation]
-	if !exists {
+	namespace := &v1.Namespace{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: ingress.Namespace}, namespace); err != nil {
+		logrus.WithError(err).WithField("namespace", ingress.Namespace).Debug("Unable to fetch namespace")
 		return false
 	}
+	return namespace.Labels[HydraRouteNamespaceLabel] == "true"
+}
 
-	return enabled == "true"
+// ingressesInNamespace maps a namespace to the ingresses in it, so adding or
+// removing the enable label reconciles them
+func (r *HydraRouteReconciler) ingressesInNamespace(ctx context.Context, namespace client.Object) []reconcile.Request {
+	ingresses := &networkingv1.IngressList{}
+	if err := r.List(ctx, ingresses, client.InNamespace(namespace.GetName())); err != nil {
+		logrus.WithError(err).WithField("namespace", namespace.GetName()).Warn("Failed to list ingresses of namespace")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(ingresses.Items))
+	for _, ingress := range ingresses.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}})
+	}
+	return requests
+}
+
+// serviceSettings resolves the per-service scaling settings of an ingress's
+// backend services, or of a service enabled without an ingress: the min/max
+// replica annotations, overridden by any HydraRoutePolicy that references the
+// ingress or service
+func (r *HydraRouteReconciler) serviceSettings(ctx context.Context, owner client.Object) (scaler.ServiceSettings, error) {
+	var settings scaler.ServiceSettings
+
+	for annotation, target := range map[string]*int32{
+		HydraRouteMinReplicasAnnotation: &settings.MinReplicas,
+		HydraRouteMaxReplicasAnnotation: &settings.MaxReplicas,
+	} {
+		value := r.getAnnotationValue(owner, annotation, "")
+		if value == "" {
+			continue
+		}
+		replicas, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || replicas < 1 {
+			logrus.WithFields(logrus.Fields{
+				"object":     client.ObjectKeyFromObject(owner).String(),
+				"annotation": annotation,
+				"value":      value,
+			}).Warn("Ignoring invalid replica annotation")
+			continue
+		}
+		*target = int32(replicas)
+	}
+
+	if !r.Config.General.EnablePolicies {
+		return settings, nil
+	}
+
+	policies := &hydrav1alpha1.HydraRoutePolicyList{}
+	if err := r.List(ctx, policies, client.InNamespace(owner.GetNamespace())); err != nil {
+		return settings, err
+	}
+	for _, policy := range policies.Items {
+		if !policyAppliesTo(&policy, owner) {
+			continue
+		}
+		if policy.Spec.MinReplicas != nil {
+			settings.MinReplicas = *policy.Spec.MinReplicas
+		}
+		if policy.Spec.MaxReplicas != nil {
+			settings.MaxReplicas = *policy.Spec.MaxReplicas
+		}
+		if policy.Spec.TargetCPUUtilization != nil {
+			settings.TargetCPUUtilization = float64(*policy.Spec.TargetCPUUtilization)
+		}
+		if policy.Spec.TargetMemoryUtilization != nil {
+			settings.TargetMemoryUtilization = float64(*policy.Spec.TargetMemoryUtilization)
+		}
+		if policy.Spec.TargetRequestsPerReplica != nil {
+			settings.RequestsPerReplica = float64(*policy.Spec.TargetRequestsPerReplica)
+		}
+		if policy.Spec.TargetConcurrency != nil {
+			settings.TargetConcurrency = float64(*policy.Spec.TargetConcurrency)
+		}
+		if policy.Spec.TargetTokensPerSecond != nil {
+			settings.TargetTokensPerSecond = float64(*policy.Spec.TargetTokensPerSecond)
+		}
+		if policy.Spec.TimeToFirstTokenSLO != nil {
+			settings.TimeToFirstTokenSLO = policy.Spec.TimeToFirstTokenSLO.Duration
+		}
+		if bounds, err := scaler.ParseScaleFactorBounds(policy.Spec.MinScaleFactor, policy.Spec.MaxScaleFactor); err != nil {
+			logrus.WithError(err).WithField("policy", policy.Name).Warn("Ignoring invalid scale factor bounds")
+		} else {
+			settings.ScaleFactor = bounds
+		}
+		if len(policy.Spec.DisabledFeatures) > 0 {
+			disabled, err := scaler.ParseFeatureSet(policy.Spec.DisabledFeatures)
+			if err != nil {
+				logrus.WithError(err).WithField("policy", policy.Name).Warn("Ignoring unknown disabled features")
+			}
+			settings.DisabledFeatures = disabled
+		}
+		for _, window := range policy.Spec.MaintenanceWindows {
+			maintenance := config.MaintenanceWindowConfig{
+				Start:    window.Start,
+				Days:     window.Days,
+				Duration: window.Duration.Duration,
+				Timezone: window.Timezone,
+			}
+			if err := maintenance.Validate(); err != nil {
+				logrus.WithError(err).WithField("policy", policy.Name).Warn("Ignoring invalid maintenance window")
+				continue
+			}
+			settings.MaintenanceWindows = append(settings.MaintenanceWindows, maintenance)
+		}
+		if policy.Spec.Behavior != nil {
+			settings.Behavior = policy.Spec.Behavior
+		}
+		break
+	}
+
+	return settings, nil
+}
+
+// policyAppliesTo reports whether a policy references an ingress or a service
+func policyAppliesTo(policy *hydrav1alpha1.HydraRoutePolicy, owner client.Object) bool {
+	switch owner.(type) {
+	case *networkingv1.Ingress:
+		return policy.Spec.IngressName == owner.GetName()
+	case *v1.Service:
+		return policy.Spec.ServiceName == owner.GetName()
+	}
+	return false
 }
 
 // getAnnotationValue gets an annotation value with a default
-func (r *HydraRouteReconciler) getAnnotationValue(ingress *networkingv1.Ingress, key, defaultValue string) string {
-	if ingress.Annotations == nil {
+func (r *HydraRouteReconciler) getAnnotationValue(owner client.Object, key, defaultValue string) string {
+	if owner.GetAnnotations() == nil {
 		return defaultValue
 	}
 
-	if value, exists := ingress.Annotations[key]; exists {
+	if value, exists := owner.GetAnnotations()[key]; exists {
 		return value
 	}
 
@@ -284,8 +706,51 @@ func (r *HydraRouteReconciler) getAnnotationValue(ingress *networkingv1.Ingress,
 
 // SetupWithManager sets up the controller with the Manager
 func (r *HydraRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	r.targets = newTargetIndex()
+
+	var seed int64
+	if r.Config.Scaling.Deterministic {
+		seed = r.Config.Scaling.AIModel.Seed
+	}
+	r.evaluations = newEvaluator(r.evaluationInterval(), r.Config.Scaling.EvaluationJitter, seed, r.evaluateService)
+	if err := mgr.Add(r.evaluations); err != nil {
+		return err
+	}
+	if err := mgr.Add(manager.RunnableFunc(r.enumerateTargets)); err != nil {
+		return err
+	}
+	if r.Config.General.ServiceTargets {
+		if err := mgr.Add(manager.RunnableFunc(r.enumerateServices)); err != nil {
+			return err
+		}
+		if err := r.setupServiceTargets(mgr); err != nil {
+			return err
+		}
+	}
+
+	if r.actuations = newActuationQueue(r.Config.General.ActuationRateLimit); r.actuations != nil {
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			return r.actuations.run(ctx, r.actuate)
+		})); err != nil {
+			return err
+		}
+	}
+
+	if r.Config.Scaling.PodReadiness.Enabled {
+		if err := r.setupPodReadiness(mgr); err != nil {
+			return err
+		}
+	}
+
+	bldr := ctrl.NewControllerManagedBy(mgr).
 		For(&networkingv1.Ingress{}).
-		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.Deployment{})
+	if r.Config.General.NamespaceOptIn {
+		bldr = bldr.Watches(&v1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.ingressesInNamespace),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}))
+	}
+	return bldr.
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Config.General.Runtime.MaxConcurrentReconciles}).
 		Complete(r)
 }
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hydraai/hydra-route/internal/scaler"
+)
+
+// destinationRuleKind is the Istio resource carrying a service's outlier detection
+var destinationRuleKind = schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "DestinationRule"}
+
+// originalMaxEjectionAnnotation records the maxEjectionPercent of a
+// DestinationRule while it is relaxed, empty when it was unset, so it can
+// be restored
+const originalMaxEjectionAnnotation = "hydra-route.ai/original-max-ejection-percent"
+
+// maxEjectionPath is the field of a DestinationRule capping the share of
+// endpoints outlier detection may eject
+var maxEjectionPath = []string{"spec", "trafficPolicy", "outlierDetection", "maxEjectionPercent"}
+
+// coordinateEjection lowers maxEjectionPercent on the DestinationRules of a
+// service while endpoints are ejected and a scale-up is pending or under
+// way, so outlier detection doesn't take the overloaded endpoints out
+// before the new replicas can take their traffic, and restores it once the
+// scale-up has completed
+func (r *HydraRouteReconciler) coordinateEjection(ctx context.Context, decision *scaler.ScalingDecision) error {
+	relaxed := int64(r.Config.Scaling.MeshEjection.RelaxedMaxEjectionPercent)
+	if relaxed == 0 || r.Config.General.DryRun {
+		return nil
+	}
+
+	var ejected int
+	relax := false
+	if m := decision.Metrics; m != nil {
+		ejected = m.EjectedEndpoints
+		relax = ejected > 0 && (decision.RecommendedReplicas > decision.CurrentReplicas || m.CurrentReplicas < m.DesiredReplicas)
+	}
+
+	rules := &unstructured.UnstructuredList{}
+	rules.SetGroupVersionKind(destinationRuleKind.GroupVersion().WithKind(destinationRuleKind.Kind + "List"))
+	if err := r.List(ctx, rules, client.InNamespace(decision.Namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			logrus.Debug("Istio CRDs not installed, not coordinating outlier detection")
+			return nil
+		}
+		return err
+	}
+
+	for i := range rules.Items {
+		rule := &rules.Items[i]
+		host, _, _ := unstructured.NestedString(rule.Object, "spec", "host")
+		if !serviceHost(host, decision.ServiceName, decision.Namespace) {
+			continue
+		}
+		if _, ok, _ := unstructured.NestedMap(rule.Object, maxEjectionPath[:len(maxEjectionPath)-1]...); !ok {
+			continue // No outlier detection
+		}
+
+		annotations := rule.GetAnnotations()
+		original, isRelaxed := annotations[originalMaxEjectionAnnotation]
+		current, set, _ := unstructured.NestedInt64(rule.Object, maxEjectionPath...)
+
+		switch {
+		case relax && !isRelaxed:
+			if set && current <= relaxed {
+				continue
+			}
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[originalMaxEjectionAnnotation] = ""
+			if set {
+				annotations[originalMaxEjectionAnnotation] = strconv.FormatInt(current, 10)
+			}
+			rule.SetAnnotations(annotations)
+			if err := unstructured.SetNestedField(rule.Object, relaxed, maxEjectionPath...); err != nil {
+				return err
+			}
+		case !relax && isRelaxed:
+			if restored, err := strconv.ParseInt(original, 10, 64); err == nil {
+				if err := unstructured.SetNestedField(rule.Object, restored, maxEjectionPath...); err != nil {
+					return err
+				}
+			} else {
+				unstructured.RemoveNestedField(rule.Object, maxEjectionPath...)
+			}
+			delete(annotations, originalMaxEjectionAnnotation)
+			rule.SetAnnotations(annotations)
+		default:
+			continue
+		}
+
+		if err := r.Update(ctx, rule); err != nil {
+			return err
+		}
+		logrus.WithFields(logrus.Fields{
+			"service":          decision.ServiceName,
+			"namespace":        decision.Namespace,
+			"destination_rule": rule.GetName(),
+			"relaxed":          relax,
+			"ejected":          ejected,
+		}).Info("Coordinated outlier detection with scale-up")
+	}
+	return nil
+}
+
+// serviceHost reports whether a DestinationRule host names a service, in
+// short or fully qualified form
+func serviceHost(host, service, namespace string) bool {
+	return host == service || host == service+"."+namespace || strings.HasPrefix(host, service+"."+namespace+".")
+}
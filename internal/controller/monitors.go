@@ -0,0 +1,332 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Kinds of the prometheus-operator monitors the controller manages
+var (
+	serviceMonitorKind = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	podMonitorKind     = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+)
+
+const (
+	// monitorManagedByLabel marks the monitors the controller owns, so
+	// monitors of services that are no longer managed can be removed
+	monitorManagedByLabel = "app.kubernetes.io/managed-by"
+	monitorManagedBy      = "hydra-route"
+
+	// monitorResync is how often the monitors are brought up to date
+	monitorResync = 5 * time.Minute
+)
+
+// MonitorManager keeps prometheus-operator monitors in place for the
+// metrics HydraRoute exposes and depends on: a ServiceMonitor for the
+// controller's metrics service, one for the nginx ingress controller
+// service behind nginx_metrics_url, and a PodMonitor per LLM endpoint
+// behind an enabled ingress. It runs as a manager runnable, so only the
+// leader writes.
+type MonitorManager struct {
+	Client  client.Client
+	Config  config.ServiceMonitorsConfig
+	Metrics config.MetricsConfig
+
+	// Whether namespaces can enroll their ingresses with the enable label
+	NamespaceOptIn bool
+}
+
+// Start syncs the monitors until the context is done. It stops early when
+// the prometheus-operator CRDs are not installed.
+func (m *MonitorManager) Start(ctx context.Context) error {
+	ticker := time.NewTicker(monitorResync)
+	defer ticker.Stop()
+
+	for {
+		if err := m.sync(ctx); err != nil {
+			if meta.IsNoMatchError(err) {
+				logrus.Warn("prometheus-operator CRDs not installed, not managing monitors")
+				return nil
+			}
+			logrus.WithError(err).Warn("Failed to sync monitors")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync creates or updates the desired monitors and deletes the managed
+// ones that are no longer desired
+func (m *MonitorManager) sync(ctx context.Context) error {
+	desired, err := m.desiredMonitors(ctx)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool, len(desired))
+	for _, monitor := range desired {
+		keep[monitor.GetKind()+"/"+monitor.GetName()] = true
+		if err := m.apply(ctx, monitor); err != nil {
+			return fmt.Errorf("failed to apply %s %s: %w", monitor.GetKind(), monitor.GetName(), err)
+		}
+	}
+
+	for _, kind := range []schema.GroupVersionKind{serviceMonitorKind, podMonitorKind} {
+		existing := &unstructured.UnstructuredList{}
+		existing.SetGroupVersionKind(kind.GroupVersion().WithKind(kind.Kind + "List"))
+		if err := m.Client.List(ctx, existing, client.InNamespace(m.Config.Namespace),
+			client.MatchingLabels{monitorManagedByLabel: monitorManagedBy}); err != nil {
+			return err
+		}
+		for i := range existing.Items {
+			monitor := &existing.Items[i]
+			if keep[kind.Kind+"/"+monitor.GetName()] {
+				continue
+			}
+			if err := m.Client.Delete(ctx, monitor); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete %s %s: %w", kind.Kind, monitor.GetName(), err)
+			}
+			logrus.WithFields(logrus.Fields{"kind": kind.Kind, "name": monitor.GetName()}).Info("Deleted monitor")
+		}
+	}
+	return nil
+}
+
+// apply creates a monitor or replaces the spec and labels of an existing one
+func (m *MonitorManager) apply(ctx context.Context, desired *unstructured.Unstructured) error {
+	monitor := &unstructured.Unstructured{}
+	monitor.SetGroupVersionKind(desired.GroupVersionKind())
+	monitor.SetName(desired.GetName())
+	monitor.SetNamespace(desired.GetNamespace())
+
+	result, err := controllerutil.CreateOrUpdate(ctx, m.Client, monitor, func() error {
+		labels := monitor.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		for name, value := range desired.GetLabels() {
+			labels[name] = value
+		}
+		monitor.SetLabels(labels)
+		monitor.Object["spec"] = desired.Object["spec"]
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if result != controllerutil.OperationResultNone {
+		logrus.WithFields(logrus.Fields{
+			"kind":   desired.GetKind(),
+			"name":   desired.GetName(),
+			"result": result,
+		}).Info("Monitor synced")
+	}
+	return nil
+}
+
+// desiredMonitors returns the monitors the configuration calls for
+func (m *MonitorManager) desiredMonitors(ctx context.Context) ([]*unstructured.Unstructured, error) {
+	var monitors []*unstructured.Unstructured
+
+	if len(m.Config.ControllerSelector) > 0 {
+		monitors = append(monitors, m.monitor(serviceMonitorKind, "hydra-route", map[string]interface{}{
+			"selector":  map[string]interface{}{"matchLabels": stringMap(m.Config.ControllerSelector)},
+			"endpoints": []interface{}{m.endpoint("port", m.Config.ControllerPort)},
+		}))
+	}
+
+	if m.Config.Nginx && m.Metrics.NginxMetricsURL != "" {
+		monitor, err := m.nginxMonitor(ctx)
+		if err != nil {
+			logrus.WithError(err).WithField("url", m.Metrics.NginxMetricsURL).Warn("Not monitoring the nginx ingress controller")
+		} else {
+			monitors = append(monitors, monitor)
+		}
+	}
+
+	if m.Config.LLMPort != "" && m.Metrics.LLM.Enabled {
+		llm, err := m.llmMonitors(ctx)
+		if err != nil {
+			return nil, err
+		}
+		monitors = append(monitors, llm...)
+	}
+	return monitors, nil
+}
+
+// nginxMonitor monitors the in-cluster service nginx_metrics_url points at,
+// addressed as <service>.<namespace>[.svc...] or by one of its cluster IPs
+func (m *MonitorManager) nginxMonitor(ctx context.Context) (*unstructured.Unstructured, error) {
+	target, err := url.Parse(m.Metrics.NginxMetricsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var service *v1.Service
+	if ip := net.ParseIP(target.Hostname()); ip != nil {
+		if service, err = m.serviceByClusterIP(ctx, ip); err != nil {
+			return nil, err
+		}
+	} else {
+		parts := strings.Split(target.Hostname(), ".")
+		if len(parts) < 2 || (len(parts) > 2 && parts[2] != "svc") {
+			return nil, fmt.Errorf("host %s is not an in-cluster service address", target.Hostname())
+		}
+		service = &v1.Service{}
+		if err := m.Client.Get(ctx, client.ObjectKey{Namespace: parts[1], Name: parts[0]}, service); err != nil {
+			return nil, err
+		}
+	}
+	if len(service.Labels) == 0 {
+		return nil, fmt.Errorf("service %s/%s has no labels to select it by", service.Namespace, service.Name)
+	}
+
+	port := 80
+	if target.Scheme == "https" {
+		port = 443
+	}
+	if target.Port() != "" {
+		if port, err = strconv.Atoi(target.Port()); err != nil {
+			return nil, err
+		}
+	}
+	var endpoint map[string]interface{}
+	for _, servicePort := range service.Spec.Ports {
+		if int(servicePort.Port) != port {
+			continue
+		}
+		if servicePort.Name != "" {
+			endpoint = m.endpoint("port", servicePort.Name)
+		} else {
+			endpoint = m.endpoint("targetPort", servicePort.TargetPort.IntValue())
+		}
+	}
+	if endpoint == nil {
+		return nil, fmt.Errorf("service %s/%s has no port %d", service.Namespace, service.Name, port)
+	}
+
+	return m.monitor(serviceMonitorKind, "hydra-route-nginx", map[string]interface{}{
+		"namespaceSelector": map[string]interface{}{"matchNames": []interface{}{service.Namespace}},
+		"selector":          map[string]interface{}{"matchLabels": stringMap(service.Labels)},
+		"endpoints":         []interface{}{endpoint},
+	}), nil
+}
+
+// serviceByClusterIP finds the service owning ip, checking every family of a
+// dual-stack service rather than only its primary cluster IP
+func (m *MonitorManager) serviceByClusterIP(ctx context.Context, ip net.IP) (*v1.Service, error) {
+	services := &v1.ServiceList{}
+	if err := m.Client.List(ctx, services); err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	for i := range services.Items {
+		service := &services.Items[i]
+		clusterIPs := service.Spec.ClusterIPs
+		if len(clusterIPs) == 0 {
+			clusterIPs = []string{service.Spec.ClusterIP}
+		}
+		for _, clusterIP := range clusterIPs {
+			if parsed := net.ParseIP(clusterIP); parsed != nil && parsed.Equal(ip) {
+				return service, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no service has cluster IP %s", ip)
+}
+
+// llmMonitors returns a PodMonitor per service behind an enabled ingress,
+// selecting its pods by the service's selector since services often carry
+// no labels of their own
+func (m *MonitorManager) llmMonitors(ctx context.Context) ([]*unstructured.Unstructured, error) {
+	ingresses := &networkingv1.IngressList{}
+	if err := m.Client.List(ctx, ingresses); err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var monitors []*unstructured.Unstructured
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		if !isHydraRouteEnabledOn(ctx, m.Client, m.NamespaceOptIn, ingress) {
+			continue
+		}
+		for _, serviceName := range BackendServices(ingress) {
+			key := serviceKey(ingress.Namespace, serviceName)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			service := &v1.Service{}
+			if err := m.Client.Get(ctx, client.ObjectKey{Namespace: ingress.Namespace, Name: serviceName}, service); err != nil {
+				logrus.WithError(err).WithField("service", key).Debug("Not monitoring LLM endpoint")
+				continue
+			}
+			if len(service.Spec.Selector) == 0 {
+				continue
+			}
+			monitors = append(monitors, m.monitor(podMonitorKind, "hydra-route-llm-"+ingress.Namespace+"-"+serviceName, map[string]interface{}{
+				"namespaceSelector":   map[string]interface{}{"matchNames": []interface{}{ingress.Namespace}},
+				"selector":            map[string]interface{}{"matchLabels": stringMap(service.Spec.Selector)},
+				"podMetricsEndpoints": []interface{}{m.endpoint("port", m.Config.LLMPort)},
+			}))
+		}
+	}
+	return monitors, nil
+}
+
+// monitor builds a managed monitor with the configured labels
+func (m *MonitorManager) monitor(kind schema.GroupVersionKind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	monitor := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	monitor.SetGroupVersionKind(kind)
+	monitor.SetName(name)
+	monitor.SetNamespace(m.Config.Namespace)
+
+	labels := map[string]string{monitorManagedByLabel: monitorManagedBy}
+	for key, value := range m.Config.Labels {
+		labels[key] = value
+	}
+	monitor.SetLabels(labels)
+	return monitor
+}
+
+// endpoint is a scrape endpoint of /metrics at the configured interval
+func (m *MonitorManager) endpoint(portField string, port interface{}) map[string]interface{} {
+	if number, ok := port.(int); ok {
+		port = int64(number)
+	}
+	return map[string]interface{}{
+		portField:  port,
+		"path":     "/metrics",
+		"interval": m.Config.Interval.String(),
+	}
+}
+
+// stringMap converts labels to the untyped form unstructured objects hold
+func stringMap(labels map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(labels))
+	for key, value := range labels {
+		out[key] = value
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestServiceByClusterIP(t *testing.T) {
+	service := func(name, clusterIP string, clusterIPs ...string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ingress-nginx"},
+			Spec:       v1.ServiceSpec{ClusterIP: clusterIP, ClusterIPs: clusterIPs},
+		}
+	}
+	m := &MonitorManager{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			service("ipv4", "10.96.0.10"),
+			service("ipv6", "fd00:10:96::a", "fd00:10:96::a"),
+			service("dual-stack", "10.96.0.20", "10.96.0.20", "fd00:10:96::14"),
+		).Build(),
+	}
+
+	tests := []struct {
+		name    string
+		ip      string
+		want    string
+		wantErr bool
+	}{
+		{name: "ipv4 without cluster IPs", ip: "10.96.0.10", want: "ipv4"},
+		{name: "single-stack ipv6", ip: "fd00:10:96::a", want: "ipv6"},
+		{name: "ipv6 in another notation", ip: "fd00:10:96:0:0:0:0:a", want: "ipv6"},
+		{name: "dual-stack primary", ip: "10.96.0.20", want: "dual-stack"},
+		{name: "dual-stack secondary", ip: "fd00:10:96::14", want: "dual-stack"},
+		{name: "unknown", ip: "fd00:10:96::99", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.serviceByClusterIP(context.Background(), net.ParseIP(tt.ip))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got service %s", got.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("got service %s, want %s", got.Name, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hydraai/hydra-route/internal/scaler"
+)
+
+// opaInput is the input document of the OPA query for one pending action
+type opaInput struct {
+	Service             string   `json:"service"`
+	Namespace           string   `json:"namespace"`
+	Ingress             string   `json:"ingress,omitempty"`
+	CurrentReplicas     int32    `json:"current_replicas"`
+	RecommendedReplicas int32    `json:"recommended_replicas"`
+	Delta               int32    `json:"delta"`
+	DeltaRatio          float64  `json:"delta_ratio"`
+	Direction           string   `json:"direction"`
+	Confidence          float64  `json:"confidence"`
+	ModelVersion        string   `json:"model_version,omitempty"`
+	Reasons             []string `json:"reasons,omitempty"`
+	Time                string   `json:"time"`
+	Hour                int      `json:"hour"`
+	Minute              int      `json:"minute"`
+	Weekday             string   `json:"weekday"`
+	DryRun              bool     `json:"dry_run"`
+}
+
+// checkOPA evaluates the configured policy against a pending scaling
+// action. The rule may produce a boolean, an object with "allow" and
+// "reason" or "deny" messages, or a Gatekeeper-style set of violations
+// carrying "msg"; any violation or deny message blocks the action. When OPA
+// can't be reached or the rule is undefined the failure policy decides.
+func (r *HydraRouteReconciler) checkOPA(ctx context.Context, decision *scaler.ScalingDecision, owner client.Object) (bool, string) {
+	cfg := r.Config.General.OPA
+
+	allowed, reason, err := r.queryOPA(ctx, decision, owner)
+	if err != nil {
+		if cfg.FailurePolicy == "allow" {
+			return true, ""
+		}
+		return false, fmt.Sprintf("policy check failed: %v", err)
+	}
+	return allowed, reason
+}
+
+// queryOPA posts the input document to the Data API of the configured rule
+// and interprets its result
+func (r *HydraRouteReconciler) queryOPA(ctx context.Context, decision *scaler.ScalingDecision, owner client.Object) (bool, string, error) {
+	cfg := r.Config.General.OPA
+
+	body, err := json.Marshal(map[string]interface{}{"input": r.opaInput(decision, owner)})
+	if err != nil {
+		return false, "", err
+	}
+	endpoint := fmt.Sprintf("%s/v1/data/%s", strings.TrimSuffix(cfg.URL, "/"), strings.Trim(cfg.Path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range cfg.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := (&http.Client{Timeout: cfg.Timeout}).Do(req)
+	if err != nil {
+		return false, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 256*1024))
+	if err != nil {
+		return false, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, "", fmt.Errorf("OPA returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var answer struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &answer); err != nil {
+		return false, "", fmt.Errorf("invalid OPA response: %w", err)
+	}
+	if len(answer.Result) == 0 {
+		return false, "", fmt.Errorf("rule %s is undefined", cfg.Path)
+	}
+	return interpretOPAResult(answer.Result)
+}
+
+// opaInput describes a pending action, with the time of day in the
+// configured time zone
+func (r *HydraRouteReconciler) opaInput(decision *scaler.ScalingDecision, owner client.Object) opaInput {
+	location, err := time.LoadLocation(r.Config.General.OPA.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	now := time.Now().In(location)
+
+	input := opaInput{
+		Service:             decision.ServiceName,
+		Namespace:           decision.Namespace,
+		CurrentReplicas:     decision.CurrentReplicas,
+		RecommendedReplicas: decision.RecommendedReplicas,
+		Delta:               decision.RecommendedReplicas - decision.CurrentReplicas,
+		Direction:           "up",
+		Confidence:          decision.Confidence,
+		ModelVersion:        decision.ModelVersion,
+		Time:                now.Format(time.RFC3339),
+		Hour:                now.Hour(),
+		Minute:              now.Minute(),
+		Weekday:             now.Weekday().String(),
+		DryRun:              r.Config.General.DryRun,
+	}
+	if input.Delta < 0 {
+		input.Direction = "down"
+	}
+	if decision.CurrentReplicas > 0 {
+		input.DeltaRatio = float64(input.Delta) / float64(decision.CurrentReplicas)
+	}
+	for _, reason := range decision.Reasons {
+		input.Reasons = append(input.Reasons, string(reason.Code))
+	}
+	if ingress, ok := owner.(*networkingv1.Ingress); ok {
+		input.Ingress = ingress.Namespace + "/" + ingress.Name
+	}
+	return input
+}
+
+// interpretOPAResult reads the value of the queried rule
+func interpretOPAResult(result json.RawMessage) (bool, string, error) {
+	var allowed bool
+	if err := json.Unmarshal(result, &allowed); err == nil {
+		if !allowed {
+			return false, "denied by policy", nil
+		}
+		return true, "", nil
+	}
+
+	var violations []interface{}
+	if err := json.Unmarshal(result, &violations); err == nil {
+		if messages := opaMessages(violations); len(messages) > 0 {
+			return false, strings.Join(messages, "; "), nil
+		}
+		return true, "", nil
+	}
+
+	var object struct {
+		Allow  *bool         `json:"allow"`
+		Reason string        `json:"reason"`
+		Deny   []interface{} `json:"deny"`
+	}
+	if err := json.Unmarshal(result, &object); err != nil {
+		return false, "", fmt.Errorf("unexpected rule value %s", string(result))
+	}
+	if messages := opaMessages(object.Deny); len(messages) > 0 {
+		return false, strings.Join(messages, "; "), nil
+	}
+	if object.Allow == nil {
+		if object.Deny != nil {
+			return true, "", nil
+		}
+		return false, "", fmt.Errorf("rule value has neither allow nor deny: %s", string(result))
+	}
+	if !*object.Allow {
+		if object.Reason == "" {
+			return false, "denied by policy", nil
+		}
+		return false, object.Reason, nil
+	}
+	return true, "", nil
+}
+
+// opaMessages returns the messages of deny rules or violations, given as
+// strings or as objects with a "msg"
+func opaMessages(values []interface{}) []string {
+	messages := make([]string, 0, len(values))
+	for _, value := range values {
+		switch value := value.(type) {
+		case string:
+			messages = append(messages, value)
+		case map[string]interface{}:
+			if msg, ok := value["msg"].(string); ok {
+				messages = append(messages, msg)
+			} else {
+				messages = append(messages, "policy violation")
+			}
+		default:
+			messages = append(messages, fmt.Sprint(value))
+		}
+	}
+	return messages
+}
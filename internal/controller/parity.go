@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hydraai/hydra-route/internal/scaler"
+)
+
+// checkParity verifies that the replicas last applied to a service took
+// effect before it is scaled again, and reports why the controller should
+// hold off otherwise. A deployment spec that no longer matches means another
+// actor, such as a GitOps sync or an operator, reverted the change: the
+// conflict is recorded and surfaced, and the controller backs off
+// exponentially instead of re-applying every evaluation. A spec that matches
+// but whose pods are still starting or terminating is given time to converge.
+func (r *HydraRouteReconciler) checkParity(ctx context.Context, decision *scaler.ScalingDecision, owner client.Object) (string, error) {
+	cfg := r.Config.General.ReplicaParity
+	key := serviceKey(decision.Namespace, decision.ServiceName)
+
+	applied, ok := r.Statuses.Applied(key)
+	if !ok {
+		return "", nil
+	}
+
+	rollouts, err := r.findServiceRollouts(ctx, decision.ServiceName, decision.Namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to find rollouts: %w", err)
+	}
+	deployments, err := r.findServiceDeployments(ctx, decision.ServiceName, decision.Namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to find deployment: %w", err)
+	}
+	if len(rollouts) > 0 {
+		deployments = nil
+	}
+	var spec, ready, total int32
+	for _, rollout := range rollouts {
+		desired, current := rollout.Replicas()
+		spec += desired
+		ready += rollout.ReadyReplicas()
+		total += current
+	}
+	for _, deployment := range deployments {
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		spec += replicas
+		ready += deployment.Status.ReadyReplicas
+		total += deployment.Status.Replicas
+	}
+
+	now := time.Now()
+	if spec == applied.Replicas {
+		if _, ok := r.Statuses.Conflict(key); ok {
+			r.Statuses.ClearConflict(key)
+			logrus.WithField("service", decision.ServiceName).Info("Replica spec ownership conflict resolved")
+		}
+		converged := ready == spec && total == spec
+		if !converged && now.Sub(applied.Time) < cfg.ConvergenceTimeout {
+			return fmt.Sprintf("waiting for %d replicas to converge (%d ready, %d total)", spec, ready, total), nil
+		}
+		return "", nil
+	}
+
+	// The spec was changed after the controller's last write
+	conflict, ok := r.Statuses.Conflict(key)
+	if ok && conflict.appliedAt.Equal(applied.Time) {
+		if now.Before(conflict.RetryAt) {
+			return fmt.Sprintf("spec ownership conflict, retrying after %s", conflict.RetryAt.Format(time.RFC3339)), nil
+		}
+		return "", nil
+	}
+
+	// A new conflict, or the retry after the previous one was reverted too
+	count := 1
+	if ok {
+		count = conflict.Count + 1
+	}
+	backoff := cfg.InitialBackoff
+	for i := 1; i < count && backoff < cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > cfg.MaxBackoff {
+		backoff = cfg.MaxBackoff
+	}
+	conflict = SpecConflict{
+		Applied:   applied.Replicas,
+		Observed:  spec,
+		Count:     count,
+		Since:     now,
+		RetryAt:   now.Add(backoff),
+		appliedAt: applied.Time,
+	}
+	r.Statuses.SetConflict(key, conflict)
+
+	logrus.WithFields(logrus.Fields{
+		"service":   decision.ServiceName,
+		"namespace": decision.Namespace,
+		"applied":   applied.Replicas,
+		"observed":  spec,
+		"conflicts": count,
+		"backoff":   backoff,
+	}).Warn("Replicas changed by another actor, backing off")
+	if r.Recorder != nil {
+		r.Recorder.Eventf(owner, v1.EventTypeWarning, "SpecOwnershipConflict",
+			"Replicas of %s were set to %d after HydraRoute applied %d; not scaling it for %s",
+			decision.ServiceName, spec, applied.Replicas, backoff)
+	}
+	return fmt.Sprintf("spec ownership conflict, retrying after %s", conflict.RetryAt.Format(time.RFC3339)), nil
+}
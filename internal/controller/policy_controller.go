@@ -0,0 +1,259 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// HydraRoutePolicyReconciler keeps HydraRoutePolicy status conditions up to date
+type HydraRoutePolicyReconciler struct {
+	client.Client
+	Scheme           *runtime.Scheme
+	MetricsCollector *metrics.Collector
+	AIScaler         *scaler.AIScaler
+	Config           *config.Config
+	Statuses         *StatusTracker
+}
+
+// Reconcile recomputes the status conditions of a HydraRoutePolicy
+func (r *HydraRoutePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	policy := &hydrav1alpha1.HydraRoutePolicy{}
+	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	status := policy.Status.DeepCopy()
+	status.ObservedGeneration = policy.Generation
+	status.Services = nil
+	status.Quarantined = nil
+
+	services, reason, message, err := r.policyServices(ctx, policy)
+	switch {
+	case err != nil:
+		return ctrl.Result{}, err
+	case reason != "":
+		r.setUnavailable(status, policy.Generation, reason, message)
+	default:
+		status.Services = services
+		r.setConditions(status, policy.Namespace, policy.Generation)
+	}
+
+	policy.Status = *status
+	if err := r.Status().Update(ctx, policy); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update policy status: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"namespace": policy.Namespace,
+		"name":      policy.Name,
+		"ready":     meta.IsStatusConditionTrue(policy.Status.Conditions, hydrav1alpha1.ConditionReady),
+	}).Debug("Policy status updated")
+
+	return ctrl.Result{RequeueAfter: RequeueAfter}, nil
+}
+
+// policyServices returns the services a policy covers: the backend services
+// of its ingress, or the service it names. When they can't be scaled, the
+// reason and message of the unavailable condition are returned instead.
+func (r *HydraRoutePolicyReconciler) policyServices(ctx context.Context, policy *hydrav1alpha1.HydraRoutePolicy) ([]string, string, string, error) {
+	if name := policy.Spec.ServiceName; name != "" {
+		if !r.Config.General.ServiceTargets {
+			return nil, "ServiceTargetsDisabled", "general.service_targets is not enabled", nil
+		}
+		service := &v1.Service{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: policy.Namespace}, service)
+		switch {
+		case client.IgnoreNotFound(err) != nil:
+			return nil, "", "", err
+		case err != nil:
+			return nil, "ServiceNotFound", fmt.Sprintf("service %s not found", name), nil
+		case service.Annotations[HydraRouteAnnotation] != "" && service.Annotations[HydraRouteAnnotation] != "true":
+			return nil, "ServiceNotEnabled", fmt.Sprintf("service %s has %s set to %s", name, HydraRouteAnnotation, service.Annotations[HydraRouteAnnotation]), nil
+		}
+		return []string{name}, "", "", nil
+	}
+
+	ingress := &networkingv1.Ingress{}
+	err := r.Get(ctx, types.NamespacedName{Name: policy.Spec.IngressName, Namespace: policy.Namespace}, ingress)
+	switch {
+	case client.IgnoreNotFound(err) != nil:
+		return nil, "", "", err
+	case err != nil:
+		return nil, "IngressNotFound", fmt.Sprintf("ingress %s not found", policy.Spec.IngressName), nil
+	case !isHydraRouteEnabledOn(ctx, r.Client, r.Config.General.NamespaceOptIn, ingress):
+		return nil, "IngressNotEnabled", fmt.Sprintf("ingress %s does not have %s set to true, and is not enrolled by its namespace", ingress.Name, HydraRouteAnnotation), nil
+	}
+	return BackendServices(ingress), "", "", nil
+}
+
+// setUnavailable marks every condition unknown, and Ready false, when the policy's ingress cannot be used
+func (r *HydraRoutePolicyReconciler) setUnavailable(status *hydrav1alpha1.HydraRoutePolicyStatus, generation int64, reason, message string) {
+	for _, conditionType := range []string{
+		hydrav1alpha1.ConditionMetricsAvailable,
+		hydrav1alpha1.ConditionModelTrained,
+		hydrav1alpha1.ConditionActuationHealthy,
+		hydrav1alpha1.ConditionInCooldown,
+		hydrav1alpha1.ConditionSpecOwnershipConflict,
+		hydrav1alpha1.ConditionCrashLooping,
+		hydrav1alpha1.ConditionModelStale,
+		hydrav1alpha1.ConditionControllerDegraded,
+	} {
+		setCondition(status, generation, conditionType, metav1.ConditionUnknown, reason, message)
+	}
+	setCondition(status, generation, hydrav1alpha1.ConditionReady, metav1.ConditionFalse, reason, message)
+}
+
+// setConditions derives the conditions from the state of the policy's services
+func (r *HydraRoutePolicyReconciler) setConditions(status *hydrav1alpha1.HydraRoutePolicyStatus, namespace string, generation int64) {
+	if len(status.Services) == 0 {
+		r.setUnavailable(status, generation, "NoBackendServices", "ingress has no backend services")
+		return
+	}
+
+	// Metrics are stale when they are older than two collection intervals,
+	// stretched while the controller throttles itself
+	maxAge := 2 * r.MetricsCollector.Interval()
+	var missing, failing, cooling, conflicting, crashing []string
+	for _, service := range status.Services {
+		key := serviceKey(namespace, service)
+
+		latest := r.MetricsCollector.GetLatestMetrics(service, namespace)
+		if latest == nil || (maxAge > 0 && time.Since(latest.Timestamp) > maxAge) {
+			missing = append(missing, service)
+		} else if latest.CrashLoop {
+			crashing = append(crashing, fmt.Sprintf("%s: %d pods", service, latest.CrashLoopPods))
+		}
+		if result, ok := r.Statuses.Actuation(key); ok && result.Error != "" {
+			failing = append(failing, fmt.Sprintf("%s: %s", service, result.Error))
+		}
+		if r.AIScaler.InCooldown(key) {
+			cooling = append(cooling, service)
+		}
+		if conflict, ok := r.Statuses.Conflict(key); ok {
+			conflicting = append(conflicting, fmt.Sprintf("%s: applied %d, found %d, retrying after %s",
+				service, conflict.Applied, conflict.Observed, conflict.RetryAt.Format(time.RFC3339)))
+		}
+		if quarantine, ok := r.Statuses.Quarantined(key); ok {
+			status.Quarantined = append(status.Quarantined, hydrav1alpha1.QuarantinedService{
+				Name:      service,
+				Failures:  int32(quarantine.Failures),
+				LastError: quarantine.LastError,
+				Since:     metav1.NewTime(quarantine.Since),
+				Until:     metav1.NewTime(quarantine.Until),
+			})
+		}
+	}
+
+	metricsReady := len(missing) == 0
+	if metricsReady {
+		setCondition(status, generation, hydrav1alpha1.ConditionMetricsAvailable, metav1.ConditionTrue, "MetricsFresh", "fresh metrics are available for all services")
+	} else {
+		setCondition(status, generation, hydrav1alpha1.ConditionMetricsAvailable, metav1.ConditionFalse, "MetricsMissing", "no fresh metrics for "+strings.Join(missing, ", "))
+	}
+
+	if r.AIScaler.ModelTrained() {
+		setCondition(status, generation, hydrav1alpha1.ConditionModelTrained, metav1.ConditionTrue, "ModelTrained", "the active model is trained")
+	} else {
+		setCondition(status, generation, hydrav1alpha1.ConditionModelTrained, metav1.ConditionFalse, "HeuristicFallback", "the active model is untrained; decisions use the heuristic fallback")
+	}
+
+	switch freshness := r.AIScaler.ModelFreshness(); {
+	case freshness.SLA == 0:
+		setCondition(status, generation, hydrav1alpha1.ConditionModelStale, metav1.ConditionUnknown, "FreshnessNotChecked", "no freshness SLA is configured")
+	case freshness.Stale:
+		message := fmt.Sprintf("no training succeeded for %s (SLA %s)", freshness.Age.Round(time.Second), freshness.SLA)
+		if freshness.LastError != "" {
+			message += ": " + freshness.LastError
+		}
+		setCondition(status, generation, hydrav1alpha1.ConditionModelStale, metav1.ConditionTrue, "ModelStale", message)
+	default:
+		setCondition(status, generation, hydrav1alpha1.ConditionModelStale, metav1.ConditionFalse, "ModelFresh", "the model was trained within its freshness SLA")
+	}
+
+	if reason, throttled := r.AIScaler.Throttled(); throttled {
+		setCondition(status, generation, hydrav1alpha1.ConditionControllerDegraded, metav1.ConditionTrue, "ResourceLimitsNear",
+			reason+"; collection is slowed down and retraining is paused")
+	} else {
+		setCondition(status, generation, hydrav1alpha1.ConditionControllerDegraded, metav1.ConditionFalse, "ResourcesAvailable", "the controller runs within its resource limits")
+	}
+
+	actuationHealthy := len(failing) == 0
+	if actuationHealthy {
+		setCondition(status, generation, hydrav1alpha1.ConditionActuationHealthy, metav1.ConditionTrue, "ActuationSucceeded", "no failed scaling actions")
+	} else {
+		setCondition(status, generation, hydrav1alpha1.ConditionActuationHealthy, metav1.ConditionFalse, "ActuationFailed", strings.Join(failing, "; "))
+	}
+
+	if len(cooling) > 0 {
+		setCondition(status, generation, hydrav1alpha1.ConditionInCooldown, metav1.ConditionTrue, "RecentlyScaled", "in cooldown: "+strings.Join(cooling, ", "))
+	} else {
+		setCondition(status, generation, hydrav1alpha1.ConditionInCooldown, metav1.ConditionFalse, "NotInCooldown", "no service is in cooldown")
+	}
+
+	if len(conflicting) > 0 {
+		setCondition(status, generation, hydrav1alpha1.ConditionSpecOwnershipConflict, metav1.ConditionTrue, "ReplicasChangedExternally", strings.Join(conflicting, "; "))
+	} else {
+		setCondition(status, generation, hydrav1alpha1.ConditionSpecOwnershipConflict, metav1.ConditionFalse, "NoConflict", "applied replicas are in place")
+	}
+
+	if len(crashing) > 0 {
+		setCondition(status, generation, hydrav1alpha1.ConditionCrashLooping, metav1.ConditionTrue, "PodsCrashLooping", "scale-down blocked while pods crash-loop: "+strings.Join(crashing, "; "))
+	} else {
+		setCondition(status, generation, hydrav1alpha1.ConditionCrashLooping, metav1.ConditionFalse, "NoCrashLoop", "no pods are crash-looping")
+	}
+
+	// Quarantined services have no fresh metrics either, so they are
+	// reported first
+	switch {
+	case len(status.Quarantined) > 0:
+		names := make([]string, 0, len(status.Quarantined))
+		for _, quarantined := range status.Quarantined {
+			names = append(names, quarantined.Name)
+		}
+		setCondition(status, generation, hydrav1alpha1.ConditionReady, metav1.ConditionFalse, "ServicesQuarantined", "evaluations keep failing for "+strings.Join(names, ", "))
+	case !metricsReady:
+		setCondition(status, generation, hydrav1alpha1.ConditionReady, metav1.ConditionFalse, "MetricsMissing", "waiting for metrics")
+	case !actuationHealthy:
+		setCondition(status, generation, hydrav1alpha1.ConditionReady, metav1.ConditionFalse, "ActuationFailed", "recent scaling actions failed")
+	case len(conflicting) > 0:
+		setCondition(status, generation, hydrav1alpha1.ConditionReady, metav1.ConditionFalse, "SpecOwnershipConflict", "another actor is changing replicas")
+	default:
+		setCondition(status, generation, hydrav1alpha1.ConditionReady, metav1.ConditionTrue, "Scaling", "services are being scaled")
+	}
+}
+
+func setCondition(status *hydrav1alpha1.HydraRoutePolicyStatus, generation int64, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// SetupWithManager sets up the policy controller with the Manager
+func (r *HydraRoutePolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hydrav1alpha1.HydraRoutePolicy{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Config.General.Runtime.MaxConcurrentReconciles}).
+		Complete(r)
+}
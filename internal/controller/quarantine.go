@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// errNoMetrics fails an evaluation of a service no metrics were collected for
+var errNoMetrics = errors.New("no metrics collected")
+
+var serviceQuarantined = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "hydra_route_service_quarantined",
+	Help: "Whether a service is quarantined after exhausting its evaluation error budget.",
+}, []string{"service", "namespace"})
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(serviceQuarantined)
+}
+
+// Quarantine records that a service exhausted its error budget and is left
+// out of collection and evaluation until a probe succeeds
+type Quarantine struct {
+	Failures  int       // failed evaluations in the window that exhausted the budget
+	LastError string    // error of the latest failed evaluation
+	Count     int       // consecutive quarantines, for the backoff
+	Since     time.Time // start of the latest quarantine
+	Until     time.Time // when the service is probed again
+
+	probing bool // released for one probe evaluation
+}
+
+// evaluation is the outcome of one evaluation of a service
+type evaluation struct {
+	at  time.Time
+	err string
+}
+
+// CountEvaluations starts counting the failed evaluations of every service
+// against the error budget of cfg
+func (t *StatusTracker) CountEvaluations(cfg config.QuarantineConfig) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.quarantine = cfg
+}
+
+// RecordEvaluation counts the outcome of an evaluation against the service's
+// error budget. It returns the quarantine the service enters when this
+// evaluation exhausts the budget or fails its probe, and whether a probe
+// succeeded and released the service.
+func (t *StatusTracker) RecordEvaluation(key string, err error) (*Quarantine, bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cfg := t.quarantine
+	if !cfg.Enabled {
+		return nil, false
+	}
+
+	now := time.Now()
+	result := evaluation{at: now}
+	if err != nil {
+		result.err = err.Error()
+	}
+
+	// A probe decides on its own outcome
+	if quarantine, ok := t.quarantines[key]; ok && quarantine.probing {
+		if err == nil {
+			delete(t.quarantines, key)
+			t.evaluations[key] = []evaluation{result}
+			return nil, true
+		}
+		backoff := cfg.Duration
+		for i := 0; i < quarantine.Count && backoff < cfg.MaxDuration; i++ {
+			backoff *= 2
+		}
+		if backoff > cfg.MaxDuration {
+			backoff = cfg.MaxDuration
+		}
+		quarantine = Quarantine{
+			Failures:  quarantine.Failures,
+			LastError: result.err,
+			Count:     quarantine.Count + 1,
+			Since:     now,
+			Until:     now.Add(backoff),
+		}
+		t.quarantines[key] = quarantine
+		return &quarantine, false
+	}
+
+	evaluations := t.evaluations[key]
+	for len(evaluations) > 0 && now.Sub(evaluations[0].at) > cfg.Window {
+		evaluations = evaluations[1:]
+	}
+	evaluations = append(evaluations, result)
+	t.evaluations[key] = evaluations
+
+	failures := 0
+	for _, e := range evaluations {
+		if e.err != "" {
+			failures++
+		}
+	}
+	if err == nil || len(evaluations) < cfg.MinEvaluations || float64(failures)/float64(len(evaluations)) < cfg.MaxErrorRatio {
+		return nil, false
+	}
+
+	quarantine := Quarantine{
+		Failures:  failures,
+		LastError: result.err,
+		Count:     1,
+		Since:     now,
+		Until:     now.Add(cfg.Duration),
+	}
+	t.quarantines[key] = quarantine
+	delete(t.evaluations, key)
+	return &quarantine, false
+}
+
+// Quarantined returns the quarantine of a service, if it is quarantined
+func (t *StatusTracker) Quarantined(key string) (Quarantine, bool) {
+	if t == nil {
+		return Quarantine{}, false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	quarantine, ok := t.quarantines[key]
+	return quarantine, ok
+}
+
+// startProbe releases a quarantined service whose quarantine has run out
+// for one probe evaluation, and reports whether it did
+func (t *StatusTracker) startProbe(key string, now time.Time) bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	quarantine, ok := t.quarantines[key]
+	if !ok || quarantine.probing || now.Before(quarantine.Until) {
+		return false
+	}
+	quarantine.probing = true
+	t.quarantines[key] = quarantine
+	return true
+}
+
+// quarantinedKeys returns the services held in quarantine, without those
+// released for a probe, in sorted order
+func (t *StatusTracker) quarantinedKeys() []string {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var keys []string
+	for key, quarantine := range t.quarantines {
+		if !quarantine.probing {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// keepEvaluations drops the error budget and quarantine of every service
+// not in keys, and returns the services released from quarantine that way
+func (t *StatusTracker) keepEvaluations(keys []string) []string {
+	if t == nil {
+		return nil
+	}
+
+	keep := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		keep[key] = true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.evaluations {
+		if !keep[key] {
+			delete(t.evaluations, key)
+		}
+	}
+	var released []string
+	for key := range t.quarantines {
+		if !keep[key] {
+			delete(t.quarantines, key)
+			released = append(released, key)
+		}
+	}
+	return released
+}
+
+// checkQuarantine reports whether a quarantined service is skipped in this
+// evaluation. Once its quarantine has run out the service is released for
+// one probe: its metrics are collected again, and the next evaluation
+// decides whether it stays out.
+func (r *HydraRouteReconciler) checkQuarantine(key string) bool {
+	quarantine, ok := r.Statuses.Quarantined(key)
+	if !ok || quarantine.probing {
+		return false
+	}
+	if r.Statuses.startProbe(key, time.Now()) {
+		logrus.WithField("service", key).Info("Quarantine of service ran out, probing it")
+		r.syncQuarantine()
+		r.MetricsCollector.Trigger()
+	}
+	return true
+}
+
+// recordEvaluation counts an evaluation against the service's error budget,
+// and reports the service entering or leaving quarantine
+func (r *HydraRouteReconciler) recordEvaluation(key string, owner client.Object, err error) {
+	quarantine, released := r.Statuses.RecordEvaluation(key, err)
+	namespace, name, _ := strings.Cut(key, "/")
+	switch {
+	case released:
+		serviceQuarantined.DeleteLabelValues(name, namespace)
+		r.syncQuarantine()
+		logrus.WithField("service", key).Info("Probe of quarantined service succeeded, service released")
+	case quarantine != nil:
+		serviceQuarantined.WithLabelValues(name, namespace).Set(1)
+		r.syncQuarantine()
+		logrus.WithFields(logrus.Fields{
+			"service":  key,
+			"failures": quarantine.Failures,
+			"until":    quarantine.Until.Format(time.RFC3339),
+			"error":    quarantine.LastError,
+		}).Warn("Service exhausted its error budget, quarantined")
+		if r.Recorder != nil && owner != nil {
+			r.Recorder.Eventf(owner, v1.EventTypeWarning, "ServiceQuarantined",
+				"Service %s is left out of collection and evaluation until %s after %d failed evaluations: %s",
+				name, quarantine.Until.Format(time.RFC3339), quarantine.Failures, quarantine.LastError)
+		}
+	}
+}
+
+// forgetQuarantines releases the services no longer managed from quarantine
+func (r *HydraRouteReconciler) forgetQuarantines(services []string) {
+	for _, key := range r.Statuses.keepEvaluations(services) {
+		namespace, name, _ := strings.Cut(key, "/")
+		serviceQuarantined.DeleteLabelValues(name, namespace)
+	}
+	r.syncQuarantine()
+}
+
+// syncQuarantine stops collecting metrics for the quarantined services
+func (r *HydraRouteReconciler) syncQuarantine() {
+	r.MetricsCollector.SetQuarantined(r.Statuses.quarantinedKeys())
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/hydraai/hydra-route/internal/scaler"
+)
+
+// awaitedScaleUp is a scale-up whose new pods are followed until Ready
+type awaitedScaleUp struct {
+	namespace string
+	selector  labels.Selector
+	since     time.Time
+	want      int
+	// Ready transitions of the new pods, by pod name
+	ready map[string]time.Time
+}
+
+// readinessWatch follows the pods added by the controller's scale-ups
+// through their lifecycle events. The kubelet reports a pod Ready as soon as
+// its readiness probe passes, so the transition time is precise to the
+// second, where metrics samples only show it an interval later.
+type readinessWatch struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	awaited map[string]*awaitedScaleUp
+}
+
+func newReadinessWatch(timeout time.Duration) *readinessWatch {
+	return &readinessWatch{timeout: timeout, awaited: make(map[string]*awaitedScaleUp)}
+}
+
+// setupPodReadiness watches pods turning Ready in the namespaces of awaited
+// scale-ups
+func (r *HydraRouteReconciler) setupPodReadiness(mgr ctrl.Manager) error {
+	r.readiness = newReadinessWatch(r.Config.Scaling.PodReadiness.Timeout)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("hydra-route-pod-readiness").
+		For(&v1.Pod{}, builder.WithPredicates(predicate.Funcs{
+			CreateFunc:  func(event.CreateEvent) bool { return false },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldPod, okOld := e.ObjectOld.(*v1.Pod)
+				newPod, okNew := e.ObjectNew.(*v1.Pod)
+				return okOld && okNew && !podReady(oldPod) && podReady(newPod) && r.readiness.awaiting(newPod.Namespace)
+			},
+		})).
+		Complete(reconcile.Func(r.observePodReady))
+}
+
+// awaitReady starts following the new pods of a scale-up just written, and
+// holds the scaler's outcome of the decision until they are Ready
+func (r *HydraRouteReconciler) awaitReady(ctx context.Context, decision *scaler.ScalingDecision) {
+	service := &v1.Service{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: decision.Namespace, Name: decision.ServiceName}, service); err != nil {
+		logrus.WithError(err).WithField("service", decision.ServiceName).Debug("Failed to read service for readiness tracking")
+		return
+	}
+	if len(service.Spec.Selector) == 0 {
+		return
+	}
+
+	key := serviceKey(decision.Namespace, decision.ServiceName)
+	since := time.Now()
+	r.readiness.mu.Lock()
+	r.readiness.awaited[key] = &awaitedScaleUp{
+		namespace: decision.Namespace,
+		selector:  labels.SelectorFromSet(service.Spec.Selector),
+		since:     since,
+		want:      int(decision.RecommendedReplicas - decision.CurrentReplicas),
+		ready:     make(map[string]time.Time),
+	}
+	r.readiness.mu.Unlock()
+	r.AIScaler.ScaledUp(key, since)
+}
+
+// observePodReady counts a pod that turned Ready towards the awaited
+// scale-ups whose services select it, and reports those it completes
+func (r *HydraRouteReconciler) observePodReady(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	pod := &v1.Pod{}
+	if err := r.Get(ctx, req.NamespacedName, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	condition := podReadyCondition(pod)
+	if condition == nil || condition.Status != v1.ConditionTrue {
+		return reconcile.Result{}, nil
+	}
+	readyAt := condition.LastTransitionTime.Time
+	if readyAt.IsZero() {
+		readyAt = time.Now()
+	}
+
+	for key, at := range r.readiness.observe(pod, readyAt) {
+		namespace, name, _ := strings.Cut(key, "/")
+		logrus.WithFields(logrus.Fields{
+			"service":   name,
+			"namespace": namespace,
+		}).Info("New replicas of the scale-up are ready")
+		r.AIScaler.ReplicasReady(key, at)
+	}
+	return reconcile.Result{}, nil
+}
+
+// observe records the Ready transition of a pod created by an awaited
+// scale-up and returns the scale-ups it completes, with the time the last
+// of their pods became Ready. Scale-ups past the timeout are dropped.
+func (w *readinessWatch) observe(pod *v1.Pod, readyAt time.Time) map[string]time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	completed := make(map[string]time.Time)
+	for key, awaited := range w.awaited {
+		if time.Since(awaited.since) > w.timeout {
+			delete(w.awaited, key)
+			continue
+		}
+		// Creation times are truncated to the second
+		if awaited.namespace != pod.Namespace || pod.CreationTimestamp.Time.Before(awaited.since.Truncate(time.Second)) ||
+			!awaited.selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+
+		awaited.ready[pod.Name] = readyAt
+		if len(awaited.ready) < awaited.want {
+			continue
+		}
+		var last time.Time
+		for _, at := range awaited.ready {
+			if at.After(last) {
+				last = at
+			}
+		}
+		completed[key] = last
+		delete(w.awaited, key)
+	}
+	return completed
+}
+
+// awaiting reports whether a scale-up in the namespace is awaited
+func (w *readinessWatch) awaiting(namespace string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, awaited := range w.awaited {
+		if awaited.namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func podReady(pod *v1.Pod) bool {
+	condition := podReadyCondition(pod)
+	return condition != nil && condition.Status == v1.ConditionTrue
+}
+
+func podReadyCondition(pod *v1.Pod) *v1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == v1.PodReady {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
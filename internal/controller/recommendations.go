@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	"github.com/hydraai/hydra-route/internal/scaler"
+)
+
+const (
+	// RecommendationServiceLabel names the service a ScalingRecommendation belongs to
+	RecommendationServiceLabel = "hydra-route.ai/service"
+
+	// ManagedByLabel marks objects created by the controller
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+
+	// ManagedByValue is the ManagedByLabel value of objects created by the controller
+	ManagedByValue = "hydra-route"
+)
+
+// recordRecommendation creates or updates the ScalingRecommendation holding
+// the latest decision for a service. There is one per service, named after
+// it, owned by every enabled ingress routing to the service, or by the
+// service itself when it is enabled without one, so it is garbage collected
+// once the last of them is deleted. A pending approval is kept
+// across decisions, and so is the last action. The stored recommendation is
+// returned.
+func (r *HydraRouteReconciler) recordRecommendation(ctx context.Context, decision *scaler.ScalingDecision, owner client.Object, ingresses []string) (*hydrav1alpha1.ScalingRecommendation, error) {
+	spec := hydrav1alpha1.ScalingRecommendationSpec{
+		ServiceName:         decision.ServiceName,
+		Ingresses:           ingresses,
+		CurrentReplicas:     decision.CurrentReplicas,
+		RecommendedReplicas: decision.RecommendedReplicas,
+		Confidence:          fmt.Sprintf("%.2f", decision.Confidence),
+		Reasoning:           decision.Reasoning,
+		Reasons:             decisionReasons(decision.Reasons),
+		ModelVersion:        decision.ModelVersion,
+		DecidedAt:           metav1.NewTime(decision.Timestamp),
+	}
+	if decision.Cost != nil {
+		spec.EstimatedCostPerHour = fmt.Sprintf("%.4f", decision.Cost.CostPerHour)
+		spec.EstimatedCarbonGramsPerHour = fmt.Sprintf("%.1f", decision.Cost.CarbonPerHour)
+	}
+	signed := make(map[string]string)
+	r.signDecision(ctx, signed, "ScalingRecommendation", decision.Namespace, decision.ServiceName, decision.RecommendedReplicas, decision)
+
+	recommendation := &hydrav1alpha1.ScalingRecommendation{}
+	err := r.Get(ctx, types.NamespacedName{Name: decision.ServiceName, Namespace: decision.Namespace}, recommendation)
+	if apierrors.IsNotFound(err) {
+		recommendation = &hydrav1alpha1.ScalingRecommendation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      decision.ServiceName,
+				Namespace: decision.Namespace,
+				Labels: map[string]string{
+					RecommendationServiceLabel: decision.ServiceName,
+					ManagedByLabel:             ManagedByValue,
+				},
+				Annotations: signed,
+			},
+			Spec: spec,
+		}
+		if err := r.setOwner(owner, recommendation); err != nil {
+			return nil, err
+		}
+		if err := r.Create(ctx, recommendation); err != nil {
+			return nil, err
+		}
+		return recommendation, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	spec.Approval = recommendation.Spec.Approval
+	spec.LastAction = recommendation.Spec.LastAction
+	recommendation.Spec = spec
+	if len(signed) > 0 && recommendation.Annotations == nil {
+		recommendation.Annotations = make(map[string]string)
+	}
+	for key, value := range signed {
+		recommendation.Annotations[key] = value
+	}
+	if err := r.setOwner(owner, recommendation); err != nil {
+		return nil, err
+	}
+	if err := r.Update(ctx, recommendation); err != nil {
+		return nil, err
+	}
+	return recommendation, nil
+}
+
+// recordLastAction stores a replica change applied to a service on its
+// ScalingRecommendation
+func (r *HydraRouteReconciler) recordLastAction(ctx context.Context, decision *scaler.ScalingDecision) error {
+	recommendation := &hydrav1alpha1.ScalingRecommendation{}
+	if err := r.Get(ctx, types.NamespacedName{Name: decision.ServiceName, Namespace: decision.Namespace}, recommendation); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	recommendation.Spec.LastAction = &hydrav1alpha1.ReplicaChange{
+		FromReplicas: decision.CurrentReplicas,
+		ToReplicas:   decision.RecommendedReplicas,
+		AppliedAt:    metav1.Now(),
+	}
+	return r.Update(ctx, recommendation)
+}
+
+// decisionReasons converts the scaler's reasons to their API type
+func decisionReasons(reasons []scaler.Reason) []hydrav1alpha1.DecisionReason {
+	converted := make([]hydrav1alpha1.DecisionReason, 0, len(reasons))
+	for _, reason := range reasons {
+		converted = append(converted, hydrav1alpha1.DecisionReason{
+			Code:       string(reason.Code),
+			Parameters: reason.Parameters,
+		})
+	}
+	return converted
+}
+
+// setOwner adds an owner reference to the enabled ingress or service on an
+// object the controller generated. Several ingresses can share a service, so
+// none of them is the controller; references are removed when an ingress or
+// service is finalized.
+func (r *HydraRouteReconciler) setOwner(owner, object client.Object) error {
+	return controllerutil.SetOwnerReference(owner, object, r.Scheme)
+}
+
+// removeOwner drops the owner reference to an ingress or service from a service's ScalingRecommendation
+func (r *HydraRouteReconciler) removeOwner(ctx context.Context, serviceName string, owner client.Object) error {
+	recommendation := &hydrav1alpha1.ScalingRecommendation{}
+	if err := r.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: owner.GetNamespace()}, recommendation); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	var refs []metav1.OwnerReference
+	for _, ref := range recommendation.OwnerReferences {
+		if ref.UID == owner.GetUID() {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	if len(refs) == len(recommendation.OwnerReferences) {
+		return nil
+	}
+
+	recommendation.OwnerReferences = refs
+	return r.Update(ctx, recommendation)
+}
+
+// deleteRecommendation removes the ScalingRecommendation of a service
+func (r *HydraRouteReconciler) deleteRecommendation(ctx context.Context, serviceName, namespace string) error {
+	recommendation := &hydrav1alpha1.ScalingRecommendation{
+		ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: namespace},
+	}
+	if err := r.Delete(ctx, recommendation); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// reportNotifyTimeout bounds the request to a report notification endpoint
+const reportNotifyTimeout = 10 * time.Second
+
+// RightSizer periodically analyzes every service with collected metrics for
+// over-provisioning against its observed peak. The report is kept on the
+// scaler for the admin API and POSTed to the notification endpoint when it
+// has findings. It runs as a manager runnable, so only the leader analyzes.
+type RightSizer struct {
+	Collector *metrics.Collector
+	Scaler    *scaler.AIScaler
+	Config    config.RightSizingConfig
+
+	// Interval at which the collector is polled until its first cycle completes
+	CollectionInterval time.Duration
+}
+
+// Start runs the first analysis once metrics have been collected, then
+// every interval until the context is done
+func (r *RightSizer) Start(ctx context.Context) error {
+	for !r.Collector.Ready() {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.CollectionInterval):
+		}
+	}
+
+	ticker := time.NewTicker(r.Config.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.analyze(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// analyze builds and publishes a report over the configured window
+func (r *RightSizer) analyze(ctx context.Context) {
+	end := time.Now()
+	report := &scaler.RightSizingReport{
+		GeneratedAt: end,
+		Window:      r.Config.Window.String(),
+		Services:    []scaler.ServiceRightSizing{},
+	}
+
+	for _, key := range r.Collector.Services() {
+		namespace, name, _ := strings.Cut(key, "/")
+		history, err := r.Collector.History(ctx, name, namespace, end.Add(-r.Config.Window), end)
+		if err != nil {
+			logrus.WithError(err).WithField("service", key).Warn("Failed to read history for right-sizing")
+			continue
+		}
+		if len(history) > 0 {
+			report.Analyzed++
+		}
+		result := r.Scaler.AnalyzeRightSizing(key, history, r.Collector.GetLatestMetrics(name, namespace))
+		if result == nil {
+			continue
+		}
+		report.Services = append(report.Services, *result)
+		logrus.WithFields(logrus.Fields{
+			"service":  key,
+			"findings": result.Findings,
+		}).Info("Service over-provisioned")
+	}
+
+	r.Scaler.SetRightSizingReport(report)
+	logrus.WithFields(logrus.Fields{
+		"analyzed": report.Analyzed,
+		"flagged":  len(report.Services),
+	}).Info("Right-sizing analysis completed")
+
+	if r.Config.NotifyURL != "" && len(report.Services) > 0 {
+		if err := notifyReport(ctx, r.Config.NotifyURL, report); err != nil {
+			logrus.WithError(err).Warn("Failed to send right-sizing notification")
+		}
+	}
+}
+
+// notifyReport POSTs a report to a notification endpoint as JSON
+func notifyReport(ctx context.Context, url string, report interface{}) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := (&http.Client{Timeout: reportNotifyTimeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
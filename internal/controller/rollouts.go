@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/scaler"
+)
+
+// findServiceRollouts returns the Argo Rollouts backing a service when
+// general.argo_rollouts is enabled. They are scaled in place of the
+// service's deployments.
+func (r *HydraRouteReconciler) findServiceRollouts(ctx context.Context, serviceName, namespace string) ([]metrics.Rollout, error) {
+	if !r.Config.General.ArgoRollouts.Enabled {
+		return nil, nil
+	}
+
+	service := &v1.Service{}
+	if err := r.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: namespace}, service); err != nil {
+		return nil, err
+	}
+	return metrics.ServiceRollouts(ctx, r, service)
+}
+
+// checkRolloutAnalysis holds scaling actions while a canary analysis of a
+// rollout backing the service is pending or running, unless
+// general.argo_rollouts.scale_during_analysis allows them. Replica changes
+// during an analysis shift the load its metrics are judged on. Rollouts
+// merely paused at a canary step are scaled.
+func (r *HydraRouteReconciler) checkRolloutAnalysis(ctx context.Context, decision *scaler.ScalingDecision, owner client.Object) (string, error) {
+	if r.Config.General.ArgoRollouts.ScaleDuringAnalysis {
+		return "", nil
+	}
+
+	rollouts, err := r.findServiceRollouts(ctx, decision.ServiceName, decision.Namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to find rollouts: %w", err)
+	}
+	var analyzing []string
+	for _, rollout := range rollouts {
+		if rollout.AnalysisRunning() {
+			analyzing = append(analyzing, rollout.GetName())
+		}
+	}
+	if len(analyzing) == 0 {
+		return "", nil
+	}
+
+	if r.Recorder != nil {
+		r.Recorder.Eventf(owner, v1.EventTypeNormal, "ScalingHeldForAnalysis",
+			"Scaling %s from %d to %d replicas held while rollout %s runs a canary analysis",
+			decision.ServiceName, decision.CurrentReplicas, decision.RecommendedReplicas, strings.Join(analyzing, ", "))
+	}
+	return "canary analysis running on rollout " + strings.Join(analyzing, ", "), nil
+}
+
+// scaleRollouts splits the recommended replicas between the rollouts
+// backing a service in proportion to their current replicas
+func (r *HydraRouteReconciler) scaleRollouts(ctx context.Context, rollouts []metrics.Rollout, decision *scaler.ScalingDecision, owner client.Object) error {
+	weights := make([]float64, len(rollouts))
+	var total float64
+	for i, rollout := range rollouts {
+		desired, _ := rollout.Replicas()
+		weights[i] = float64(desired)
+		total += weights[i]
+	}
+	if total == 0 {
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	replicas := splitReplicas(decision.RecommendedReplicas, weights)
+
+	for i, rollout := range rollouts {
+		if r.Config.General.DryRun {
+			logrus.WithFields(logrus.Fields{
+				"service":              decision.ServiceName,
+				"namespace":            decision.Namespace,
+				"rollout":              rollout.GetName(),
+				"current_replicas":     decision.CurrentReplicas,
+				"recommended_replicas": replicas[i],
+			}).Info("DRY RUN: Would scale rollout")
+			continue
+		}
+		if err := r.writeRolloutReplicas(ctx, rollout, replicas[i], decision, owner); err != nil {
+			return fmt.Errorf("failed to update rollout %s: %w", rollout.GetName(), err)
+		}
+	}
+
+	if !r.Config.General.DryRun {
+		logrus.WithFields(logrus.Fields{
+			"service":              decision.ServiceName,
+			"namespace":            decision.Namespace,
+			"rollouts":             len(rollouts),
+			"current_replicas":     decision.CurrentReplicas,
+			"recommended_replicas": decision.RecommendedReplicas,
+			"confidence":           decision.Confidence,
+		}).Info("Successfully scaled rollout")
+	}
+	return nil
+}
+
+// writeRolloutReplicas sets the replicas of one rollout according to the
+// configured GitOps mode, together with the tracking annotations. Only
+// spec.replicas and the annotations are patched: Argo Rollouts scales the
+// stable and canary ReplicaSets to keep the current step's weight, and the
+// rollout's pause, abort and step state are never written, so a paused
+// canary stays paused.
+func (r *HydraRouteReconciler) writeRolloutReplicas(ctx context.Context, rollout metrics.Rollout, replicas int32, decision *scaler.ScalingDecision, owner client.Object) error {
+	metadataAnnotations := make(map[string]interface{})
+	for key, value := range r.scalingAnnotations(ctx, decision, metrics.RolloutKind.Kind, rollout, replicas) {
+		metadataAnnotations[key] = value
+	}
+
+	gitops := r.Config.General.GitOps
+	if gitops.Mode != GitOpsModeAnnotation {
+		r.warnForeignReplicaManagers(rollout, "rollout", gitops.FieldManager, owner)
+	}
+
+	patch := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": metrics.RolloutKind.GroupVersion().String(),
+		"kind":       metrics.RolloutKind.Kind,
+		"metadata": map[string]interface{}{
+			"name":        rollout.GetName(),
+			"namespace":   rollout.GetNamespace(),
+			"annotations": metadataAnnotations,
+		},
+	}}
+
+	switch gitops.Mode {
+	case GitOpsModeAnnotation:
+		metadataAnnotations[HydraRouteRecommendedReplicasAnnotation] = fmt.Sprintf("%d", replicas)
+		return r.Patch(ctx, patch, client.Merge)
+
+	case GitOpsModeServerSideApply:
+		patch.Object["spec"] = map[string]interface{}{"replicas": int64(replicas)}
+		return r.Patch(ctx, patch, client.Apply, client.FieldOwner(gitops.FieldManager), client.ForceOwnership)
+
+	default:
+		patch.Object["spec"] = map[string]interface{}{"replicas": int64(replicas)}
+		return r.Patch(ctx, patch, client.Merge)
+	}
+}
+
+// removeRolloutAnnotations strips the controller's annotations from the
+// rollouts backing a service
+func (r *HydraRouteReconciler) removeRolloutAnnotations(ctx context.Context, rollouts []metrics.Rollout) error {
+	for _, rollout := range rollouts {
+		removed := make(map[string]interface{})
+		for _, annotation := range trackingAnnotations {
+			if _, ok := rollout.GetAnnotations()[annotation]; ok {
+				removed[annotation] = nil
+			}
+		}
+		if len(removed) == 0 {
+			continue
+		}
+
+		patch := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": metrics.RolloutKind.GroupVersion().String(),
+			"kind":       metrics.RolloutKind.Kind,
+			"metadata": map[string]interface{}{
+				"name":        rollout.GetName(),
+				"namespace":   rollout.GetNamespace(),
+				"annotations": removed,
+			},
+		}}
+		if err := r.Patch(ctx, patch, client.Merge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
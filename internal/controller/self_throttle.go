@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// cgroupRoot is where the controller's cgroup is mounted in its container
+const cgroupRoot = "/sys/fs/cgroup"
+
+var (
+	selfThrottled = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "hydra_route_self_throttled",
+		Help: "Whether the controller is shedding work because it nears its own resource limits.",
+	})
+	selfResourceUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hydra_route_self_resource_usage_ratio",
+		Help: "Memory and CPU use of the controller as a share of its limits.",
+	}, []string{"resource"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(selfThrottled, selfResourceUsage)
+}
+
+// SelfThrottler watches the controller's own memory and CPU use against the
+// limits of its container. From the configured share of either limit it
+// stretches the collection interval and puts the scaler in its reduced
+// mode, which a Degraded condition on every policy reports, until use has
+// dropped back by the recovery margin. It runs on every replica, since
+// each one holds its own caches.
+type SelfThrottler struct {
+	Collector *metrics.Collector
+	Scaler    *scaler.AIScaler
+	Config    config.SelfThrottlingConfig
+
+	// Root of the cgroup file system; empty for /sys/fs/cgroup
+	CgroupRoot string
+
+	throttled bool
+	cpu       cpuSample
+}
+
+// cpuSample is the CPU time the controller had used at a point in time
+type cpuSample struct {
+	usage time.Duration
+	at    time.Time
+}
+
+// NeedLeaderElection makes every replica watch its own resource use
+func (t *SelfThrottler) NeedLeaderElection() bool {
+	return false
+}
+
+// Start checks resource use every check interval until the context is done
+func (t *SelfThrottler) Start(ctx context.Context) error {
+	if t.CgroupRoot == "" {
+		t.CgroupRoot = cgroupRoot
+	}
+	ticker := time.NewTicker(t.Config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			t.check()
+		}
+	}
+}
+
+// check compares resource use with the limits and enters or leaves
+// throttling, with the recovery margin between the two
+func (t *SelfThrottler) check() {
+	memory, memoryKnown := t.memoryUsage()
+	cpu, cpuKnown := t.cpuUsage(time.Now())
+	if memoryKnown {
+		selfResourceUsage.WithLabelValues("memory").Set(memory)
+	}
+	if cpuKnown {
+		selfResourceUsage.WithLabelValues("cpu").Set(cpu)
+	}
+
+	var reasons []string
+	if memoryKnown && memory >= t.Config.MemoryThreshold {
+		reasons = append(reasons, fmt.Sprintf("memory at %.0f%% of its limit", memory*100))
+	}
+	if cpuKnown && cpu >= t.Config.CPUThreshold {
+		reasons = append(reasons, fmt.Sprintf("CPU at %.0f%% of its limit", cpu*100))
+	}
+
+	switch {
+	case len(reasons) > 0:
+		reason := strings.Join(reasons, ", ")
+		if !t.throttled {
+			logrus.WithFields(logrus.Fields{
+				"reason":   reason,
+				"slowdown": t.Config.CollectionSlowdown,
+			}).Warn("Controller nears its resource limits, throttling itself")
+			if memoryKnown && memory >= t.Config.MemoryThreshold {
+				debug.FreeOSMemory()
+			}
+		}
+		t.throttle(reason)
+	case t.throttled && memory < t.Config.MemoryThreshold-t.Config.RecoveryMargin && cpu < t.Config.CPUThreshold-t.Config.RecoveryMargin:
+		logrus.Info("Controller resource use recovered, throttling ended")
+		t.throttle("")
+	case t.throttled:
+		// Between the recovery level and the thresholds, keep the reason current
+		t.throttle(fmt.Sprintf("recovering: memory at %.0f%%, CPU at %.0f%% of their limits", memory*100, cpu*100))
+	}
+}
+
+// throttle applies the throttled state for reason, or ends it with ""
+func (t *SelfThrottler) throttle(reason string) {
+	t.throttled = reason != ""
+	if t.throttled {
+		t.Collector.SetSlowdown(t.Config.CollectionSlowdown)
+		selfThrottled.Set(1)
+	} else {
+		t.Collector.SetSlowdown(1)
+		selfThrottled.Set(0)
+	}
+	t.Scaler.SetThrottled(reason)
+}
+
+// memoryUsage returns the working set of the controller's cgroup, without
+// reclaimable page cache, as a share of the smaller of its memory limit and
+// the Go soft memory limit. The Go runtime's own total stands in where no
+// cgroup reports it.
+func (t *SelfThrottler) memoryUsage() (float64, bool) {
+	limit := math.MaxFloat64
+	if goLimit := debug.SetMemoryLimit(-1); goLimit != math.MaxInt64 {
+		limit = float64(goLimit)
+	}
+
+	var usage float64
+	if current, ok := t.readCgroupValue("memory.current", "memory/memory.usage_in_bytes"); ok {
+		usage = current
+		inactive := t.readCgroupStat("memory.stat", "inactive_file")
+		if inactive == 0 {
+			inactive = t.readCgroupStat("memory/memory.stat", "total_inactive_file")
+		}
+		usage = math.Max(0, usage-inactive)
+		if cgroupLimit, ok := t.readCgroupValue("memory.max", "memory/memory.limit_in_bytes"); ok {
+			limit = math.Min(limit, cgroupLimit)
+		}
+	} else {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		usage = float64(stats.Sys - stats.HeapReleased)
+	}
+
+	// cgroup v1 reports no limit as a value near the largest int64
+	if limit >= float64(math.MaxInt64/2) || limit <= 0 {
+		return 0, false
+	}
+	return usage / limit, true
+}
+
+// cpuUsage returns the CPU the controller used since the previous check as
+// a share of its cgroup's CPU quota. There is none without a CPU limit.
+func (t *SelfThrottler) cpuUsage(now time.Time) (float64, bool) {
+	var quota float64
+	if cpuMax, err := os.ReadFile(filepath.Join(t.CgroupRoot, "cpu.max")); err == nil {
+		fields := strings.Fields(string(cpuMax))
+		if len(fields) == 2 && fields[0] != "max" {
+			q, _ := strconv.ParseFloat(fields[0], 64)
+			period, _ := strconv.ParseFloat(fields[1], 64)
+			if period > 0 {
+				quota = q / period
+			}
+		}
+	} else if q, ok := t.readCgroupValue("", "cpu/cpu.cfs_quota_us"); ok && q > 0 {
+		if period, ok := t.readCgroupValue("", "cpu/cpu.cfs_period_us"); ok && period > 0 {
+			quota = q / period
+		}
+	}
+	if quota <= 0 {
+		return 0, false
+	}
+
+	var used time.Duration
+	if usec := t.readCgroupStat("cpu.stat", "usage_usec"); usec > 0 {
+		used = time.Duration(usec) * time.Microsecond
+	} else if nsec, ok := t.readCgroupValue("", "cpuacct/cpuacct.usage"); ok {
+		used = time.Duration(nsec)
+	} else {
+		return 0, false
+	}
+
+	previous := t.cpu
+	t.cpu = cpuSample{usage: used, at: now}
+	if previous.at.IsZero() || !now.After(previous.at) {
+		return 0, false
+	}
+	return (used - previous.usage).Seconds() / now.Sub(previous.at).Seconds() / quota, true
+}
+
+// readCgroupValue reads a single number from the cgroup v2 file, or else
+// the cgroup v1 file; empty names are skipped. "max" means no limit.
+func (t *SelfThrottler) readCgroupValue(v2, v1 string) (float64, bool) {
+	for _, name := range []string{v2, v1} {
+		if name == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(t.CgroupRoot, name))
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "max" {
+			return 0, false
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+		return parsed, true
+	}
+	return 0, false
+}
+
+// readCgroupStat reads one key of a flat keyed cgroup file, 0 when missing
+func (t *SelfThrottler) readCgroupStat(name, key string) float64 {
+	data, err := os.ReadFile(filepath.Join(t.CgroupRoot, name))
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == key {
+			value, _ := strconv.ParseFloat(fields[1], 64)
+			return value
+		}
+	}
+	return 0
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+)
+
+// reconcileService tracks a service enabled without an ingress, through the
+// enable annotation or a HydraRoutePolicy naming it. Such services are
+// evaluated like the backends of an enabled ingress, with the service
+// supplying their settings and receiving their events.
+func (r *HydraRouteReconciler) reconcileService(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"namespace": req.Namespace,
+		"service":   req.Name,
+	})
+
+	service := &v1.Service{}
+	if err := r.Get(ctx, req.NamespacedName, service); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			r.targetIndex().setDirect(req.String(), false)
+			r.syncTargets()
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !service.DeletionTimestamp.IsZero() || !r.isServiceEnabled(ctx, service) {
+		r.targetIndex().setDirect(req.String(), false)
+		r.syncTargets()
+		if controllerutil.ContainsFinalizer(service, HydraRouteFinalizer) {
+			if err := r.finalizeService(ctx, service); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if controllerutil.AddFinalizer(service, HydraRouteFinalizer) {
+		if err := r.Update(ctx, service); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+		}
+	}
+
+	r.targetIndex().setDirect(req.String(), true)
+	r.syncTargets()
+
+	log.Debug("Service enabled without an ingress")
+	return ctrl.Result{}, nil
+}
+
+// isServiceEnabled checks whether a service is enabled without an ingress:
+// its enable annotation is "true", or, without the annotation, a
+// HydraRoutePolicy in its namespace names it
+func (r *HydraRouteReconciler) isServiceEnabled(ctx context.Context, service *v1.Service) bool {
+	if enabled, exists := service.Annotations[HydraRouteAnnotation]; exists {
+		return enabled == "true"
+	}
+	if !r.Config.General.EnablePolicies {
+		return false
+	}
+
+	policies := &hydrav1alpha1.HydraRoutePolicyList{}
+	if err := r.List(ctx, policies, client.InNamespace(service.Namespace)); err != nil {
+		logrus.WithError(err).WithField("namespace", service.Namespace).Debug("Unable to list policies")
+		return false
+	}
+	for i := range policies.Items {
+		if policies.Items[i].Spec.ServiceName == service.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// finalizeService cleans up after a service that is being deleted or no
+// longer enabled, then removes the finalizer. A service an enabled ingress
+// still routes to keeps its annotations and records.
+func (r *HydraRouteReconciler) finalizeService(ctx context.Context, service *v1.Service) error {
+	key := serviceKey(service.Namespace, service.Name)
+	if len(r.targetIndex().ingressesFor(key)) > 0 {
+		if err := r.removeOwner(ctx, service.Name, service); err != nil {
+			return fmt.Errorf("failed to update scaling recommendation of service %s: %w", service.Name, err)
+		}
+	} else {
+		if err := r.removeTrackingAnnotations(ctx, service.Name, service.Namespace); err != nil {
+			return fmt.Errorf("failed to clean up deployment of service %s: %w", service.Name, err)
+		}
+		if err := r.deleteRecommendation(ctx, service.Name, service.Namespace); err != nil {
+			return fmt.Errorf("failed to delete scaling recommendation of service %s: %w", service.Name, err)
+		}
+	}
+
+	if controllerutil.RemoveFinalizer(service, HydraRouteFinalizer) {
+		if err := r.Update(ctx, service); err != nil {
+			return fmt.Errorf("failed to remove finalizer: %w", err)
+		}
+	}
+
+	logrus.WithField("service", key).Info("Cleaned up HydraRoute state for service")
+	return nil
+}
+
+// serviceForPolicy maps a HydraRoutePolicy to the service it names, so
+// creating, retargeting or deleting the policy reconciles the service
+func (r *HydraRouteReconciler) serviceForPolicy(ctx context.Context, object client.Object) []reconcile.Request {
+	policy, ok := object.(*hydrav1alpha1.HydraRoutePolicy)
+	if !ok || policy.Spec.ServiceName == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: policy.Namespace, Name: policy.Spec.ServiceName}}}
+}
+
+// setupServiceTargets adds the controller tracking the services enabled
+// without an ingress, watching the policies that name them when policies are
+// enabled
+func (r *HydraRouteReconciler) setupServiceTargets(mgr ctrl.Manager) error {
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		Named("hydra-route-service").
+		For(&v1.Service{})
+	if r.Config.General.EnablePolicies {
+		bldr = bldr.Watches(&hydrav1alpha1.HydraRoutePolicy{}, handler.EnqueueRequestsFromMapFunc(r.serviceForPolicy))
+	}
+	return bldr.
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Config.General.Runtime.MaxConcurrentReconciles}).
+		Complete(reconcile.Func(r.reconcileService))
+}
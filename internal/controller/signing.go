@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/internal/signing"
+)
+
+// Annotations carrying the signed record of the latest decision about an
+// object, and its signature over the record's exact bytes
+const (
+	HydraRouteDecisionAnnotation          = "hydra-route.ai/decision"
+	HydraRouteDecisionSignatureAnnotation = "hydra-route.ai/decision-signature"
+)
+
+// VetoSignatureHeader carries the signature of a veto webhook request body
+const VetoSignatureHeader = "X-Hydra-Route-Signature"
+
+// signDecision adds the signed record of decision about an object to
+// annotations when signing is configured. A record that can't be signed is
+// still written, with an empty signature, rather than keeping the previous
+// signed one, which would no longer match the object.
+func (r *HydraRouteReconciler) signDecision(ctx context.Context, annotations map[string]string, kind, namespace, name string, replicas int32, decision *scaler.ScalingDecision) {
+	if r.Signer == nil {
+		return
+	}
+	record, signature, err := signing.SignRecord(ctx, r.Signer, signing.Record{
+		Kind:         kind,
+		Namespace:    namespace,
+		Name:         name,
+		Service:      decision.ServiceName,
+		FromReplicas: decision.CurrentReplicas,
+		Replicas:     replicas,
+		Confidence:   fmt.Sprintf("%.2f", decision.Confidence),
+		ModelVersion: decision.ModelVersion,
+		DecidedAt:    decision.Timestamp.UTC(),
+	})
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"kind":      kind,
+			"namespace": namespace,
+			"name":      name,
+		}).Warn("Failed to sign decision record; writing it unsigned")
+	}
+	annotations[HydraRouteDecisionAnnotation] = record
+	annotations[HydraRouteDecisionSignatureAnnotation] = signature
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// HydraRouteReplicaWeightAnnotation sets a deployment's share of its
+// service's replicas under the weighted deployment split
+const HydraRouteReplicaWeightAnnotation = "hydra-route.ai/replica-weight"
+
+// deploymentWeights returns the share of the service's replicas each
+// deployment should receive. "proportional" keeps the current ratio of
+// desired replicas and "weighted" uses the replica weight annotation (1 when
+// unset).
+func deploymentWeights(split string, deployments []*appsv1.Deployment) []float64 {
+	weights := make([]float64, len(deployments))
+	switch split {
+	case "weighted":
+		for i, deployment := range deployments {
+			weights[i] = 1
+			value, ok := deployment.Annotations[HydraRouteReplicaWeightAnnotation]
+			if !ok {
+				continue
+			}
+			weight, err := strconv.ParseFloat(value, 64)
+			if err != nil || weight < 0 {
+				logrus.WithFields(logrus.Fields{
+					"deployment": deployment.Name,
+					"namespace":  deployment.Namespace,
+					"value":      value,
+				}).Warn("Ignoring invalid replica weight annotation")
+				continue
+			}
+			weights[i] = weight
+		}
+	default:
+		for i, deployment := range deployments {
+			if deployment.Spec.Replicas != nil {
+				weights[i] = float64(*deployment.Spec.Replicas)
+			}
+		}
+	}
+
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		// Nothing to go by, for example every deployment scaled to zero
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	return weights
+}
+
+// splitReplicas divides total replicas in proportion to weights, handing the
+// rounding remainder to the largest fractional shares so the parts add up.
+// A deployment with a positive weight gets at least one replica when another
+// can spare it.
+func splitReplicas(total int32, weights []float64) []int32 {
+	var sum float64
+	for _, weight := range weights {
+		sum += weight
+	}
+
+	parts := make([]int32, len(weights))
+	remainders := make([]float64, len(weights))
+	assigned := int32(0)
+	for i, weight := range weights {
+		share := float64(total) * weight / sum
+		parts[i] = int32(math.Floor(share))
+		remainders[i] = share - math.Floor(share)
+		assigned += parts[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for _, i := range order {
+		if assigned >= total {
+			break
+		}
+		parts[i]++
+		assigned++
+	}
+
+	// Keep every weighted deployment running while there are replicas to spare
+	for i, weight := range weights {
+		if weight == 0 || parts[i] > 0 {
+			continue
+		}
+		largest := 0
+		for j := range parts {
+			if parts[j] > parts[largest] {
+				largest = j
+			}
+		}
+		if parts[largest] <= 1 {
+			break
+		}
+		parts[largest]--
+		parts[i]++
+	}
+	return parts
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// enumerateTargets records the backend services of every enabled ingress
+// when the controller starts, so evaluation loops and metrics collection
+// cover them without waiting for each ingress to be reconciled. Registering
+// them with the collector starts a collection cycle ahead of its next tick.
+// Policies only apply to enabled ingresses, so enumerating the ingresses
+// covers them too. It runs as a manager runnable, after the caches have synced; a failure
+// leaves the services to their ingresses' reconciles.
+func (r *HydraRouteReconciler) enumerateTargets(ctx context.Context) error {
+	ingresses := &networkingv1.IngressList{}
+	if err := r.List(ctx, ingresses); err != nil {
+		logrus.WithError(err).Warn("Failed to enumerate ingresses at startup")
+		return nil
+	}
+
+	enabled := 0
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		if !ingress.DeletionTimestamp.IsZero() || !r.isHydraRouteEnabled(ctx, ingress) {
+			continue
+		}
+		key := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}.String()
+		r.targetIndex().update(key, ingress.Namespace, BackendServices(ingress))
+		enabled++
+	}
+	r.syncTargets()
+
+	logrus.WithFields(logrus.Fields{
+		"ingresses": enabled,
+		"services":  len(r.targetIndex().services()),
+	}).Info("Enumerated managed services at startup")
+	return nil
+}
+
+// enumerateServices records the services enabled without an ingress at
+// startup, like enumerateTargets does for ingresses
+func (r *HydraRouteReconciler) enumerateServices(ctx context.Context) error {
+	services := &v1.ServiceList{}
+	if err := r.List(ctx, services); err != nil {
+		logrus.WithError(err).Warn("Failed to enumerate services at startup")
+		return nil
+	}
+
+	enabled := 0
+	for i := range services.Items {
+		service := &services.Items[i]
+		if !service.DeletionTimestamp.IsZero() || !r.isServiceEnabled(ctx, service) {
+			continue
+		}
+		r.targetIndex().setDirect(serviceKey(service.Namespace, service.Name), true)
+		enabled++
+	}
+	r.syncTargets()
+
+	logrus.WithField("services", enabled).Info("Enumerated services enabled without an ingress at startup")
+	return nil
+}
@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// ActuationResult is the outcome of the latest attempt to scale a service
+type ActuationResult struct {
+	Time  time.Time
+	Error string // empty when the scaling action succeeded
+}
+
+// AppliedReplicas is the replica count the controller last wrote for a service
+type AppliedReplicas struct {
+	Replicas int32
+	Time     time.Time
+}
+
+// ScalingAction is a replica change the controller applied to a service
+type ScalingAction struct {
+	From int32
+	To   int32
+	Time time.Time
+}
+
+// SpecConflict records that another actor changed a service's replicas
+// after the controller applied them
+type SpecConflict struct {
+	Applied  int32     // replicas the controller applied
+	Observed int32     // replicas found in the deployment spec
+	Count    int       // consecutive conflicts, for the backoff
+	Since    time.Time // detection of the latest conflict
+	RetryAt  time.Time // when the controller may apply replicas again
+
+	appliedAt time.Time // time of the overridden write
+}
+
+// StatusTracker shares per-service actuation outcomes between the ingress
+// reconciler, which scales services, and the policy reconciler, which
+// reports their health, keeps the applied actions for summary reports and
+// counts failed evaluations against each service's error budget
+type StatusTracker struct {
+	mu         sync.RWMutex
+	actuations map[string]ActuationResult
+	applied    map[string]AppliedReplicas
+	conflicts  map[string]SpecConflict
+
+	// Applied actions per service, kept for actionRetention; none are
+	// kept while it is zero
+	actions         map[string][]ScalingAction
+	actionRetention time.Duration
+
+	// Recent evaluations and quarantines per service, counted against the
+	// error budget of quarantine; nothing is counted while it is disabled
+	evaluations map[string][]evaluation
+	quarantines map[string]Quarantine
+	quarantine  config.QuarantineConfig
+}
+
+// NewStatusTracker creates an empty status tracker
+func NewStatusTracker() *StatusTracker {
+	return &StatusTracker{
+		actuations:  make(map[string]ActuationResult),
+		applied:     make(map[string]AppliedReplicas),
+		conflicts:   make(map[string]SpecConflict),
+		actions:     make(map[string][]ScalingAction),
+		evaluations: make(map[string][]evaluation),
+		quarantines: make(map[string]Quarantine),
+	}
+}
+
+// RecordActuation stores the outcome of scaling a service (namespace/name)
+func (t *StatusTracker) RecordActuation(key string, err error) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := ActuationResult{Time: time.Now()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	t.actuations[key] = result
+}
+
+// Actuation returns the latest actuation outcome of a service
+func (t *StatusTracker) Actuation(key string) (ActuationResult, bool) {
+	if t == nil {
+		return ActuationResult{}, false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result, ok := t.actuations[key]
+	return result, ok
+}
+
+// RecordApplied stores the replica count written for a service
+func (t *StatusTracker) RecordApplied(key string, replicas int32) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.applied[key] = AppliedReplicas{Replicas: replicas, Time: time.Now()}
+}
+
+// Applied returns the replica count last written for a service
+func (t *StatusTracker) Applied(key string) (AppliedReplicas, bool) {
+	if t == nil {
+		return AppliedReplicas{}, false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	applied, ok := t.applied[key]
+	return applied, ok
+}
+
+// SetConflict stores the spec ownership conflict of a service
+func (t *StatusTracker) SetConflict(key string, conflict SpecConflict) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.conflicts[key] = conflict
+}
+
+// ClearConflict forgets the spec ownership conflict of a service
+func (t *StatusTracker) ClearConflict(key string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.conflicts, key)
+}
+
+// Conflict returns the spec ownership conflict of a service, if any
+func (t *StatusTracker) Conflict(key string) (SpecConflict, bool) {
+	if t == nil {
+		return SpecConflict{}, false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	conflict, ok := t.conflicts[key]
+	return conflict, ok
+}
+
+// KeepActions starts keeping the applied actions of every service for the
+// retention period
+func (t *StatusTracker) KeepActions(retention time.Duration) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.actionRetention = retention
+}
+
+// RecordAction stores a replica change applied to a service and forgets
+// the actions past the retention period
+func (t *StatusTracker) RecordAction(key string, from, to int32) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.actionRetention == 0 {
+		return
+	}
+
+	now := time.Now()
+	actions := t.actions[key]
+	for len(actions) > 0 && now.Sub(actions[0].Time) > t.actionRetention {
+		actions = actions[1:]
+	}
+	t.actions[key] = append(actions, ScalingAction{From: from, To: to, Time: now})
+}
+
+// Actions returns the actions applied to every service from a time on
+func (t *StatusTracker) Actions(since time.Time) map[string][]ScalingAction {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	result := make(map[string][]ScalingAction)
+	for key, actions := range t.actions {
+		for _, action := range actions {
+			if !action.Time.Before(since) {
+				result[key] = append(result[key], action)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	"github.com/hydraai/hydra-route/internal/egress"
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// SummaryReporter writes a ScalingReport summarizing every interval: the
+// actions applied, SLO breaches avoided, estimated savings and model
+// accuracy. Each summary is also POSTed to the notification endpoint and
+// mailed when configured. It runs as a manager runnable, so only the leader
+// reports, on the actions it applied itself.
+type SummaryReporter struct {
+	Client    client.Client
+	Collector *metrics.Collector
+	Scaler    *scaler.AIScaler
+	Statuses  *StatusTracker
+	Config    config.SummaryReportConfig
+
+	// Interval at which the collector is polled until its first cycle completes
+	CollectionInterval time.Duration
+}
+
+// Start writes a summary at the end of every interval until the context is done
+func (s *SummaryReporter) Start(ctx context.Context) error {
+	s.Statuses.KeepActions(s.Config.Interval)
+	s.Scaler.KeepClamping(s.Config.Interval)
+
+	for !s.Collector.Ready() {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.CollectionInterval):
+		}
+	}
+
+	ticker := time.NewTicker(s.Config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		end := time.Now()
+		report := s.summarize(ctx, end.Add(-s.Config.Interval), end)
+		if err := s.write(ctx, report); err != nil {
+			logrus.WithError(err).Warn("Failed to write scaling report")
+		}
+		if s.Config.NotifyURL != "" {
+			if err := notifyReport(ctx, s.Config.NotifyURL, report); err != nil {
+				logrus.WithError(err).Warn("Failed to send scaling report notification")
+			}
+		}
+		if s.Config.Email.SMTPAddress != "" {
+			if err := mailDigest(s.Config.Email, report); err != nil {
+				logrus.WithError(err).Warn("Failed to mail scaling report")
+			}
+		}
+	}
+}
+
+// summarize builds the report of a period
+func (s *SummaryReporter) summarize(ctx context.Context, start, end time.Time) *hydrav1alpha1.ScalingReport {
+	spec := hydrav1alpha1.ScalingReportSpec{
+		PeriodStart: metav1.NewTime(start),
+		PeriodEnd:   metav1.NewTime(end),
+	}
+
+	actions := s.Statuses.Actions(start)
+	var samples, attained int
+	var replicaHours, savings float64
+	var activity []hydrav1alpha1.ServiceActivity
+
+	for _, key := range s.Collector.Services() {
+		namespace, name, _ := strings.Cut(key, "/")
+		history, err := s.Collector.History(ctx, name, namespace, start, end)
+		if err != nil {
+			logrus.WithError(err).WithField("service", key).Warn("Failed to read history for scaling report")
+			continue
+		}
+		if len(history) == 0 {
+			continue
+		}
+		spec.Services++
+
+		service := hydrav1alpha1.ServiceActivity{Service: key}
+		var scaleUps []scaler.ScaleUp
+		for _, action := range actions[key] {
+			if action.To > action.From {
+				service.ScaleUps++
+				scaleUps = append(scaleUps, scaler.ScaleUp{Time: action.Time, From: action.From, To: action.To})
+			} else {
+				service.ScaleDowns++
+			}
+		}
+
+		summary := s.Scaler.SummarizeService(history, scaleUps)
+		samples += summary.Samples
+		attained += summary.Attained
+		replicaHours += summary.ReplicaHoursSaved
+		savings += summary.Savings
+		spec.ScaleUps += service.ScaleUps
+		spec.ScaleDowns += service.ScaleDowns
+		spec.SLOBreachesAvoided += int32(summary.BreachesAvoided)
+
+		if summary.Samples > 0 {
+			service.SLOAttainment = fmt.Sprintf("%.2f", float64(summary.Attained)/float64(summary.Samples)*100)
+		}
+		if service.ScaleUps+service.ScaleDowns > 0 {
+			activity = append(activity, service)
+		}
+	}
+
+	if samples > 0 {
+		spec.SLOAttainment = fmt.Sprintf("%.2f", float64(attained)/float64(samples)*100)
+	}
+	spec.ReplicaHoursSaved = fmt.Sprintf("%.1f", replicaHours)
+	if savings != 0 {
+		spec.EstimatedSavings = fmt.Sprintf("%.2f", savings)
+	}
+
+	spec.ModelVersion = s.Scaler.ModelVersion()
+	if accuracy := s.Scaler.PredictionError(spec.ModelVersion); accuracy.Samples > 0 {
+		spec.ModelMAE = fmt.Sprintf("%.4f", accuracy.MAE)
+		spec.ModelPredictions = int32(accuracy.Samples)
+	}
+
+	sort.Slice(activity, func(i, j int) bool {
+		a, b := activity[i].ScaleUps+activity[i].ScaleDowns, activity[j].ScaleUps+activity[j].ScaleDowns
+		if a != b {
+			return a > b
+		}
+		return activity[i].Service < activity[j].Service
+	})
+	if len(activity) > s.Config.TopServices {
+		activity = activity[:s.Config.TopServices]
+	}
+	spec.TopServices = activity
+	spec.Clamped = s.clamped(start)
+
+	return &hydrav1alpha1.ScalingReport{
+		TypeMeta: metav1.TypeMeta{APIVersion: hydrav1alpha1.GroupVersion.String(), Kind: "ScalingReport"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scaling-report-" + end.UTC().Format("20060102-1504"),
+			Namespace: s.Config.Namespace,
+			Labels:    map[string]string{monitorManagedByLabel: monitorManagedBy},
+		},
+		Spec: spec,
+	}
+}
+
+// clamped lists the services whose recommendations their limits clamped
+// since start, those most often clamped at max replicas first
+func (s *SummaryReporter) clamped(start time.Time) []hydrav1alpha1.ServiceClamping {
+	var clamped []hydrav1alpha1.ServiceClamping
+	for key, counts := range s.Scaler.Clamping(start) {
+		if counts.Clamped() == 0 {
+			continue
+		}
+		clamped = append(clamped, hydrav1alpha1.ServiceClamping{
+			Service:        key,
+			Evaluations:    int32(counts.Evaluations),
+			AtMin:          int32(counts.Min),
+			AtMax:          int32(counts.Max),
+			Step:           int32(counts.Step),
+			CapacityAlerts: int32(counts.Alerts),
+		})
+	}
+	sort.Slice(clamped, func(i, j int) bool {
+		if clamped[i].AtMax != clamped[j].AtMax {
+			return clamped[i].AtMax > clamped[j].AtMax
+		}
+		a := clamped[i].AtMin + clamped[i].Step
+		b := clamped[j].AtMin + clamped[j].Step
+		if a != b {
+			return a > b
+		}
+		return clamped[i].Service < clamped[j].Service
+	})
+	if len(clamped) > s.Config.TopServices {
+		clamped = clamped[:s.Config.TopServices]
+	}
+	return clamped
+}
+
+// write creates a report and deletes the oldest ones beyond the retained number
+func (s *SummaryReporter) write(ctx context.Context, report *hydrav1alpha1.ScalingReport) error {
+	if err := s.Client.Create(ctx, report); err != nil {
+		return err
+	}
+	logrus.WithFields(logrus.Fields{
+		"report":               report.Name,
+		"scale_ups":            report.Spec.ScaleUps,
+		"scale_downs":          report.Spec.ScaleDowns,
+		"slo_breaches_avoided": report.Spec.SLOBreachesAvoided,
+		"estimated_savings":    report.Spec.EstimatedSavings,
+	}).Info("Scaling report written")
+
+	reports := &hydrav1alpha1.ScalingReportList{}
+	if err := s.Client.List(ctx, reports, client.InNamespace(s.Config.Namespace),
+		client.MatchingLabels{monitorManagedByLabel: monitorManagedBy}); err != nil {
+		return err
+	}
+	sort.Slice(reports.Items, func(i, j int) bool {
+		return reports.Items[i].Spec.PeriodEnd.After(reports.Items[j].Spec.PeriodEnd.Time)
+	})
+	for i := s.Config.Retain; i < len(reports.Items); i++ {
+		if err := s.Client.Delete(ctx, &reports.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mailDigest sends a plain-text digest of a report to the configured recipients
+func mailDigest(cfg config.EmailDigestConfig, report *hydrav1alpha1.ScalingReport) error {
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		host, _, err := net.SplitHostPort(cfg.SMTPAddress)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", cfg.Username, os.Getenv(cfg.PasswordEnv), host)
+	}
+
+	spec := report.Spec
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\nSubject: HydraRoute scaling report %s to %s\r\n\r\n",
+		cfg.From, strings.Join(cfg.To, ", "), spec.PeriodStart.Format("2006-01-02"), spec.PeriodEnd.Format("2006-01-02"))
+	fmt.Fprintf(&body, "Services: %d\r\n", spec.Services)
+	fmt.Fprintf(&body, "Scaling actions: %d up, %d down\r\n", spec.ScaleUps, spec.ScaleDowns)
+	fmt.Fprintf(&body, "SLO breaches avoided: %d\r\n", spec.SLOBreachesAvoided)
+	if spec.SLOAttainment != "" {
+		fmt.Fprintf(&body, "SLO attainment: %s%%\r\n", spec.SLOAttainment)
+	}
+	fmt.Fprintf(&body, "Replica-hours saved against peak provisioning: %s\r\n", spec.ReplicaHoursSaved)
+	if spec.EstimatedSavings != "" {
+		fmt.Fprintf(&body, "Estimated savings: %s\r\n", spec.EstimatedSavings)
+	}
+	if spec.ModelMAE != "" {
+		fmt.Fprintf(&body, "Model %s: mean absolute error %s over %d predictions\r\n", spec.ModelVersion, spec.ModelMAE, spec.ModelPredictions)
+	}
+	if len(spec.TopServices) > 0 {
+		body.WriteString("\r\nMost active services:\r\n")
+		for _, service := range spec.TopServices {
+			fmt.Fprintf(&body, "  %s: %d up, %d down", service.Service, service.ScaleUps, service.ScaleDowns)
+			if service.SLOAttainment != "" {
+				fmt.Fprintf(&body, ", SLO attainment %s%%", service.SLOAttainment)
+			}
+			body.WriteString("\r\n")
+		}
+	}
+	if len(spec.Clamped) > 0 {
+		body.WriteString("\r\nRecommendations clamped by limits:\r\n")
+		for _, service := range spec.Clamped {
+			fmt.Fprintf(&body, "  %s: %d of %d evaluations at max, %d at min, %d by step limits",
+				service.Service, service.AtMax, service.Evaluations, service.AtMin, service.Step)
+			if service.CapacityAlerts > 0 {
+				fmt.Fprintf(&body, ", %d capacity alerts", service.CapacityAlerts)
+			}
+			body.WriteString("\r\n")
+		}
+	}
+	fmt.Fprintf(&body, "\r\nkubectl get scalingreport -n %s %s -o yaml\r\n", report.Namespace, report.Name)
+
+	// SendMail dials on its own, bypassing the guarded HTTP transport
+	if err := egress.Check(cfg.SMTPAddress); err != nil {
+		return err
+	}
+	return smtp.SendMail(cfg.SMTPAddress, auth, cfg.From, cfg.To, []byte(body.String()))
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// targetIndex tracks which ingresses reference each backend service so a
+// service shared by several ingresses gets one evaluation loop rather than
+// one per referencing ingress. It also tracks the services enabled directly,
+// without an ingress.
+type targetIndex struct {
+	mu sync.Mutex
+
+	// ingress key -> service keys it references
+	byIngress map[string]map[string]bool
+
+	// keys of the services enabled directly
+	direct map[string]bool
+}
+
+func newTargetIndex() *targetIndex {
+	return &targetIndex{
+		byIngress: make(map[string]map[string]bool),
+		direct:    make(map[string]bool),
+	}
+}
+
+// BackendServices returns the distinct backend service names of an ingress,
+// including the default backend, in sorted order
+func BackendServices(ingress *networkingv1.Ingress) []string {
+	seen := make(map[string]bool)
+	if backend := ingress.Spec.DefaultBackend; backend != nil && backend.Service != nil && backend.Service.Name != "" {
+		seen[backend.Service.Name] = true
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil || path.Backend.Service.Name == "" {
+				continue
+			}
+			seen[path.Backend.Service.Name] = true
+		}
+	}
+
+	services := make([]string, 0, len(seen))
+	for name := range seen {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// update records the services referenced by an ingress, replacing any
+// previously recorded set
+func (t *targetIndex) update(ingressKey, namespace string, services []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	keys := make(map[string]bool, len(services))
+	for _, service := range services {
+		keys[serviceKey(namespace, service)] = true
+	}
+	t.byIngress[ingressKey] = keys
+}
+
+// remove forgets an ingress that was deleted or no longer has HydraRoute enabled
+func (t *targetIndex) remove(ingressKey string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.byIngress, ingressKey)
+}
+
+// setDirect records whether a service is enabled directly
+func (t *targetIndex) setDirect(service string, enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if enabled {
+		t.direct[service] = true
+	} else {
+		delete(t.direct, service)
+	}
+}
+
+// isDirect reports whether a service is enabled directly
+func (t *targetIndex) isDirect(service string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.direct[service]
+}
+
+// ingressesFor returns the sorted keys of the enabled ingresses referencing a service
+func (t *targetIndex) ingressesFor(service string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var ingresses []string
+	for ingress, services := range t.byIngress {
+		if services[service] {
+			ingresses = append(ingresses, ingress)
+		}
+	}
+	sort.Strings(ingresses)
+	return ingresses
+}
+
+// services returns the sorted keys of the services any enabled ingress
+// references, and of those enabled directly
+func (t *targetIndex) services() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for _, services := range t.byIngress {
+		for service := range services {
+			seen[service] = true
+		}
+	}
+	for service := range t.direct {
+		seen[service] = true
+	}
+	services := make([]string, 0, len(seen))
+	for service := range seen {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// withoutIngress returns the sorted keys of the services enabled directly
+// that no enabled ingress references
+func (t *targetIndex) withoutIngress() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var services []string
+	for service := range t.direct {
+		referenced := false
+		for _, ingressServices := range t.byIngress {
+			if ingressServices[service] {
+				referenced = true
+				break
+			}
+		}
+		if !referenced {
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+	return services
+}
+
+func serviceKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
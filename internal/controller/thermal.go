@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/hydraai/hydra-route/internal/scaler"
+)
+
+// HydraRoutePlacementHintAnnotation carries the node affinity, as JSON, that
+// steers the replicas of a scale-up away from thermally constrained nodes.
+// HydraRoute doesn't touch the pod template, which would roll every pod;
+// an admission policy or scheduler plugin merges the hint into new pods.
+const HydraRoutePlacementHintAnnotation = "hydra-route.ai/node-affinity-hint"
+
+// placementHint returns the placement hint annotation value for a scaling
+// action: on scale-ups, a preferred anti-affinity for the nodes that were
+// thermally constrained in the last collection cycle. It is empty for
+// scale-downs and while no node is constrained, so a stale hint is cleared.
+func (r *HydraRouteReconciler) placementHint(decision *scaler.ScalingDecision) string {
+	if decision.RecommendedReplicas <= decision.CurrentReplicas {
+		return ""
+	}
+	nodes := r.MetricsCollector.ThermallyConstrainedNodes()
+	if len(nodes) == 0 {
+		return ""
+	}
+
+	affinity := v1.NodeAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{{
+			Weight: r.Config.Scaling.ThermalPlacement.Weight,
+			Preference: v1.NodeSelectorTerm{
+				MatchFields: []v1.NodeSelectorRequirement{{
+					Key:      "metadata.name",
+					Operator: v1.NodeSelectorOpNotIn,
+					Values:   nodes,
+				}},
+			},
+		}},
+	}
+	hint, err := json.Marshal(affinity)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to encode placement hint")
+		return ""
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"service":   decision.ServiceName,
+		"namespace": decision.Namespace,
+		"nodes":     nodes,
+	}).Info("Steering new replicas away from thermally constrained nodes")
+	return string(hint)
+}
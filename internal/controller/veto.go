@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hydraai/hydra-route/internal/scaler"
+)
+
+// vetoRequest is the payload POSTed to the veto webhook
+type vetoRequest struct {
+	Decision *scaler.ScalingDecision `json:"decision"`
+	Ingress  string                  `json:"ingress,omitempty"`
+	DryRun   bool                    `json:"dry_run"`
+}
+
+// vetoResponse is the optional answer of the veto webhook. An empty body
+// with status 200 allows the action.
+type vetoResponse struct {
+	Allowed *bool  `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+// checkVeto asks the veto webhook whether a scaling action may go ahead.
+// Any status other than 200, or an explicit "allowed": false, vetoes the
+// action; when the hook can't be reached the failure policy decides.
+func (r *HydraRouteReconciler) checkVeto(ctx context.Context, decision *scaler.ScalingDecision, owner client.Object) (bool, string) {
+	hook := r.Config.General.VetoWebhook
+
+	allowed, reason, err := r.callVetoWebhook(ctx, decision, owner)
+	if err != nil {
+		if hook.FailurePolicy == "allow" {
+			return true, ""
+		}
+		return false, fmt.Sprintf("veto webhook failed: %v", err)
+	}
+	return allowed, reason
+}
+
+// callVetoWebhook sends the decision to the webhook and interprets its
+// answer; errors are only returned when no answer was received
+func (r *HydraRouteReconciler) callVetoWebhook(ctx context.Context, decision *scaler.ScalingDecision, owner client.Object) (bool, string, error) {
+	hook := r.Config.General.VetoWebhook
+
+	request := vetoRequest{
+		Decision: decision,
+		DryRun:   r.Config.General.DryRun,
+	}
+	if ingress, ok := owner.(*networkingv1.Ingress); ok {
+		request.Ingress = ingress.Namespace + "/" + ingress.Name
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return false, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.Signer != nil {
+		if signature, err := r.Signer.Sign(ctx, body); err != nil {
+			logrus.WithError(err).Warn("Failed to sign veto webhook request; sending it unsigned")
+		} else {
+			req.Header.Set(VetoSignatureHeader, signature)
+		}
+	}
+	for name, value := range hook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := (&http.Client{Timeout: hook.Timeout}).Do(req)
+	if err != nil {
+		return false, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return false, "", err
+	}
+
+	var answer vetoResponse
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &answer); err != nil && resp.StatusCode == http.StatusOK {
+			return false, "", fmt.Errorf("invalid response: %w", err)
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if answer.Reason != "" {
+			return false, answer.Reason, nil
+		}
+		return false, fmt.Sprintf("veto webhook returned status %d", resp.StatusCode), nil
+	}
+	if answer.Allowed != nil && !*answer.Allowed {
+		if answer.Reason != "" {
+			return false, answer.Reason, nil
+		}
+		return false, "denied by veto webhook", nil
+	}
+	return true, "", nil
+}
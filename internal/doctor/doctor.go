@@ -0,0 +1,333 @@
+// Package doctor checks the cluster prerequisites of the controller and
+// reports actionable failures
+package doctor
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/rbac"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Check outcomes
+const (
+	StatusOK   = "ok"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// certificateWarning is how close to expiry a webhook CA triggers a warning
+const certificateWarning = 30 * 24 * time.Hour
+
+// Result is the outcome of one check
+type Result struct {
+	Check   string
+	Status  string
+	Message string
+
+	// What to do about a warning or failure
+	Hint string
+}
+
+// Options configures a doctor run
+type Options struct {
+	Client    client.Client
+	Discovery discovery.DiscoveryInterface
+	HTTP      *http.Client
+	Config    *config.Config
+
+	// Namespace and name of the controller's service account
+	ServiceAccountNamespace string
+	ServiceAccountName      string
+}
+
+// Run performs every check, in order
+func Run(ctx context.Context, opts Options) []Result {
+	var results []Result
+	results = append(results, checkCRDs(opts)...)
+	results = append(results, checkRBAC(ctx, opts)...)
+	results = append(results, checkMetricsServer(opts))
+	results = append(results, checkNginx(ctx, opts))
+	results = append(results, checkPrometheus(ctx, opts))
+	results = append(results, checkWebhooks(ctx, opts)...)
+	return results
+}
+
+// Failed reports whether any check failed
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if result.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCRDs verifies the custom resources the enabled features depend on
+func checkCRDs(opts Options) []Result {
+	crds := []struct {
+		resource string
+		enabled  bool
+		setting  string
+	}{
+		{"scalingrecommendations", opts.Config.General.RecordRecommendations, "general.record_recommendations"},
+		{"hydraroutepolicies", opts.Config.General.EnablePolicies, "general.enable_policies"},
+		{"scalingreports", opts.Config.Scaling.SummaryReport.Enabled, "scaling.summary_report.enabled"},
+	}
+
+	served := make(map[string]bool)
+	resources, err := opts.Discovery.ServerResourcesForGroupVersion(hydrav1alpha1.GroupVersion.String())
+	if err == nil {
+		for _, resource := range resources.APIResources {
+			served[resource.Name] = true
+		}
+	}
+
+	var results []Result
+	for _, crd := range crds {
+		check := "crd/" + crd.resource
+		switch {
+		case served[crd.resource]:
+			results = append(results, Result{Check: check, Status: StatusOK, Message: "installed"})
+		case !crd.enabled:
+			results = append(results, Result{Check: check, Status: StatusSkip, Message: fmt.Sprintf("not installed, %s is disabled", crd.setting)})
+		default:
+			results = append(results, Result{
+				Check:   check,
+				Status:  StatusFail,
+				Message: fmt.Sprintf("%s.%s is not served by the API server", crd.resource, hydrav1alpha1.GroupVersion.Group),
+				Hint:    fmt.Sprintf("kubectl apply -f deploy/kubernetes/crds/, or disable %s", crd.setting),
+			})
+		}
+	}
+	return results
+}
+
+// checkRBAC asks the API server whether the controller's service account
+// holds every permission it needs with the configuration
+func checkRBAC(ctx context.Context, opts Options) []Result {
+	user := fmt.Sprintf("system:serviceaccount:%s:%s", opts.ServiceAccountNamespace, opts.ServiceAccountName)
+	grants := rbac.Required(opts.Config, rbac.Options{
+		Namespace:          opts.ServiceAccountNamespace,
+		ServiceAccountName: opts.ServiceAccountName,
+	})
+
+	var results []Result
+	for _, grant := range grants {
+		resource := grant.Resource
+		if grant.Subresource != "" {
+			resource += "/" + grant.Subresource
+		}
+		check := "rbac/" + resource
+		if grant.Group != "" {
+			check = "rbac/" + grant.Group + "/" + resource
+		}
+		scope := "cluster-wide"
+		if grant.Namespace != "" {
+			check += " (" + grant.Namespace + ")"
+			scope = "in " + grant.Namespace
+		}
+
+		var missing []string
+		for _, verb := range grant.Verbs {
+			review := &authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					User:   user,
+					Groups: []string{"system:serviceaccounts", "system:serviceaccounts:" + opts.ServiceAccountNamespace},
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace:   grant.Namespace,
+						Group:       grant.Group,
+						Resource:    grant.Resource,
+						Subresource: grant.Subresource,
+						Verb:        verb,
+					},
+				},
+			}
+			if err := opts.Client.Create(ctx, review); err != nil {
+				return append(results, Result{
+					Check:   "rbac",
+					Status:  StatusWarn,
+					Message: fmt.Sprintf("cannot review permissions: %v", err),
+					Hint:    "run the doctor as a user allowed to create subjectaccessreviews",
+				})
+			}
+			if !review.Status.Allowed {
+				missing = append(missing, verb)
+			}
+		}
+
+		if len(missing) == 0 {
+			results = append(results, Result{Check: check, Status: StatusOK, Message: strings.Join(grant.Verbs, ", ")})
+			continue
+		}
+		results = append(results, Result{
+			Check:   check,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("%s cannot %s %s (needed to %s)", user, strings.Join(missing, ", "), scope, grant.Reason),
+			Hint:    "hydra-route rbac --config <file> | kubectl apply -f -, or grant the verbs to the service account",
+		})
+	}
+	return results
+}
+
+// checkMetricsServer verifies the resource metrics API is served
+func checkMetricsServer(opts Options) Result {
+	const check = "metrics-server"
+	resources, err := opts.Discovery.ServerResourcesForGroupVersion("metrics.k8s.io/v1beta1")
+	if err != nil {
+		return Result{
+			Check:   check,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("metrics.k8s.io/v1beta1 is not available: %v", err),
+			Hint:    "install metrics-server; without it CPU and memory utilization stay at 0",
+		}
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "pods" {
+			return Result{Check: check, Status: StatusOK, Message: "metrics.k8s.io/v1beta1 serves pods"}
+		}
+	}
+	return Result{
+		Check:   check,
+		Status:  StatusFail,
+		Message: "metrics.k8s.io/v1beta1 does not serve pod metrics",
+		Hint:    "check the metrics-server deployment and its APIService",
+	}
+}
+
+// checkNginx verifies the ingress controller stats endpoint answers
+func checkNginx(ctx context.Context, opts Options) Result {
+	const check = "nginx-metrics"
+	url := opts.Config.Metrics.NginxMetricsURL
+	if url == "" {
+		return Result{Check: check, Status: StatusSkip, Message: "metrics.nginx_metrics_url is not set"}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/api/v1/nginx/stats", nil)
+	if err != nil {
+		return Result{Check: check, Status: StatusFail, Message: err.Error(), Hint: "fix metrics.nginx_metrics_url"}
+	}
+	for name, value := range opts.Config.Metrics.Sources["nginx"].Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := opts.HTTP.Do(req)
+	if err != nil {
+		return Result{
+			Check:   check,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("%s is unreachable: %v", url, err),
+			Hint:    "cluster-internal URLs only resolve in the cluster; run the doctor from a pod or port-forward the endpoint",
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{
+			Check:   check,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("%s returned status %d", url, resp.StatusCode),
+			Hint:    "enable the stats endpoint on the ingress controller, or configure Prometheus recording rules instead",
+		}
+	}
+	return Result{Check: check, Status: StatusOK, Message: url + " answers"}
+}
+
+// checkPrometheus verifies Prometheus answers and has the recorded series
+func checkPrometheus(ctx context.Context, opts Options) Result {
+	const check = "prometheus"
+	if opts.Config.Metrics.PrometheusURL == "" {
+		return Result{Check: check, Status: StatusSkip, Message: "metrics.prometheus_url is not set"}
+	}
+
+	prometheus := metrics.NewPrometheusClient(opts.Config.Metrics, opts.HTTP)
+	vector, err := prometheus.Query(ctx, "count("+metrics.RecordRequestRate+")", time.Now())
+	if err != nil {
+		return Result{
+			Check:   check,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("query failed: %v", err),
+			Hint:    "check metrics.prometheus_url and metrics.sources.prometheus; cluster-internal URLs only resolve in the cluster",
+		}
+	}
+	if len(vector) == 0 {
+		return Result{
+			Check:   check,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("no %s series", metrics.RecordRequestRate),
+			Hint:    "apply the output of \"hydra-route recording-rules\"; until then request metrics come from nginx only",
+		}
+	}
+	return Result{Check: check, Status: StatusOK, Message: fmt.Sprintf("%d services with recorded request rates", int(vector[0].Value))}
+}
+
+// checkWebhooks verifies the CA bundles of any admission webhooks registered
+// for HydraRoute are valid and not about to expire
+func checkWebhooks(ctx context.Context, opts Options) []Result {
+	bundles := make(map[string][]byte)
+
+	validating := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+	if err := opts.Client.List(ctx, validating); err == nil {
+		for _, configuration := range validating.Items {
+			for _, webhook := range configuration.Webhooks {
+				if strings.Contains(configuration.Name, "hydra-route") {
+					bundles[configuration.Name+"/"+webhook.Name] = webhook.ClientConfig.CABundle
+				}
+			}
+		}
+	}
+	mutating := &admissionregistrationv1.MutatingWebhookConfigurationList{}
+	if err := opts.Client.List(ctx, mutating); err == nil {
+		for _, configuration := range mutating.Items {
+			for _, webhook := range configuration.Webhooks {
+				if strings.Contains(configuration.Name, "hydra-route") {
+					bundles[configuration.Name+"/"+webhook.Name] = webhook.ClientConfig.CABundle
+				}
+			}
+		}
+	}
+
+	if len(bundles) == 0 {
+		return []Result{{Check: "webhooks", Status: StatusSkip, Message: "no HydraRoute admission webhooks registered"}}
+	}
+
+	var results []Result
+	for name, bundle := range bundles {
+		results = append(results, checkCABundle("webhook/"+name, bundle))
+	}
+	return results
+}
+
+// checkCABundle parses a PEM CA bundle and checks its validity period
+func checkCABundle(check string, bundle []byte) Result {
+	block, _ := pem.Decode(bundle)
+	if block == nil {
+		return Result{Check: check, Status: StatusFail, Message: "caBundle is empty or not PEM", Hint: "reinstall the webhook certificate or let cert-manager inject the CA"}
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return Result{Check: check, Status: StatusFail, Message: fmt.Sprintf("caBundle is not a certificate: %v", err), Hint: "reinstall the webhook certificate"}
+	}
+
+	remaining := time.Until(cert.NotAfter)
+	switch {
+	case remaining <= 0:
+		return Result{Check: check, Status: StatusFail, Message: fmt.Sprintf("CA expired on %s", cert.NotAfter.Format(time.RFC3339)), Hint: "rotate the webhook certificate"}
+	case remaining < certificateWarning:
+		return Result{Check: check, Status: StatusWarn, Message: fmt.Sprintf("CA expires on %s", cert.NotAfter.Format(time.RFC3339)), Hint: "rotate the webhook certificate soon"}
+	}
+	return Result{Check: check, Status: StatusOK, Message: fmt.Sprintf("CA valid until %s", cert.NotAfter.Format(time.RFC3339))}
+}
@@ -0,0 +1,339 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// Interaction is one call the controller made to the API server and the
+// answer it got
+type Interaction struct {
+	Verb     string          `json:"verb"` // get, list, create, update, patch or delete, with /<subresource>
+	Kind     string          `json:"kind"`
+	Key      string          `json:"key"` // namespace/name, or the namespace and selectors of a list
+	Request  json.RawMessage `json:"request,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    *CallError      `json:"error,omitempty"`
+}
+
+// CallError is the error a recorded call failed with, kept so that the
+// replayed call fails the same way
+type CallError struct {
+	// API errors, such as not found or conflict
+	Status *metav1.Status `json:"status,omitempty"`
+
+	// The kind isn't served, such as an Argo Rollout without its CRD
+	NoMatch bool `json:"no_match,omitempty"`
+
+	Message string `json:"message,omitempty"`
+}
+
+// Cassette holds the API interactions of a recorded run, in the order they
+// were made
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// CassettePath is the cassette of a scenario in a cassette directory
+func CassettePath(directory string, scenario Scenario) string {
+	return filepath.Join(directory, scenario.Name+".json")
+}
+
+// LoadCassette reads a cassette written by a recording run
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette as indented JSON so diffs between recordings stay readable
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// interactionKey identifies the calls a recorded interaction answers
+func interactionKey(verb, kind, key string) string {
+	return verb + " " + kind + " " + key
+}
+
+// isRead reports whether a verb only reads
+func isRead(verb string) bool {
+	return verb == "get" || verb == "list"
+}
+
+// objectKey is the key of a call on a single object
+func objectKey(obj client.Object) string {
+	return client.ObjectKeyFromObject(obj).String()
+}
+
+// listKey is the key of a list call: its namespace and selectors
+func listKey(opts []client.ListOption) string {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	key := listOpts.Namespace
+	if listOpts.LabelSelector != nil && !listOpts.LabelSelector.Empty() {
+		key += "?labels=" + listOpts.LabelSelector.String()
+	}
+	if listOpts.FieldSelector != nil && !listOpts.FieldSelector.Empty() {
+		key += "?fields=" + listOpts.FieldSelector.String()
+	}
+	return key
+}
+
+// kindOf names the kind of a typed or unstructured object or list
+func kindOf(scheme *runtime.Scheme, obj runtime.Object) string {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return fmt.Sprintf("%T", obj)
+	}
+	return gvk.String()
+}
+
+// newCallError keeps what a replay needs of a call's error
+func newCallError(err error) *CallError {
+	if err == nil {
+		return nil
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		s := status.Status()
+		return &CallError{Status: &s}
+	}
+	return &CallError{NoMatch: meta.IsNoMatchError(err), Message: err.Error()}
+}
+
+// err rebuilds the recorded error of a call on obj
+func (e *CallError) err(scheme *runtime.Scheme, obj runtime.Object) error {
+	switch {
+	case e.Status != nil:
+		return &apierrors.StatusError{ErrStatus: *e.Status}
+	case e.NoMatch:
+		gvk, _ := apiutil.GVKForObject(obj, scheme)
+		return &meta.NoKindMatchError{GroupKind: gvk.GroupKind(), SearchedVersions: []string{gvk.Version}}
+	default:
+		return errors.New(e.Message)
+	}
+}
+
+// recorder wraps a client, passing every call through and appending it with
+// its answer to a cassette
+type recorder struct {
+	scheme *runtime.Scheme
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+func (r *recorder) funcs() interceptor.Funcs {
+	return interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			err := c.Get(ctx, key, obj, opts...)
+			r.record("get", obj, key.String(), nil, obj, err)
+			return err
+		},
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			err := c.List(ctx, list, opts...)
+			r.record("list", list, listKey(opts), nil, list, err)
+			return err
+		},
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			key, request := objectKey(obj), r.encode(obj)
+			err := c.Create(ctx, obj, opts...)
+			r.record("create", obj, key, request, obj, err)
+			return err
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			request := r.encode(obj)
+			err := c.Update(ctx, obj, opts...)
+			r.record("update", obj, objectKey(obj), request, obj, err)
+			return err
+		},
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			request, _ := patch.Data(obj)
+			err := c.Patch(ctx, obj, patch, opts...)
+			r.record("patch", obj, objectKey(obj), request, obj, err)
+			return err
+		},
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			err := c.Delete(ctx, obj, opts...)
+			r.record("delete", obj, objectKey(obj), nil, nil, err)
+			return err
+		},
+		SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			request := r.encode(obj)
+			err := c.SubResource(subResource).Update(ctx, obj, opts...)
+			r.record("update/"+subResource, obj, objectKey(obj), request, obj, err)
+			return err
+		},
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			request, _ := patch.Data(obj)
+			err := c.SubResource(subResource).Patch(ctx, obj, patch, opts...)
+			r.record("patch/"+subResource, obj, objectKey(obj), request, obj, err)
+			return err
+		},
+	}
+}
+
+// encode returns an object as JSON, or nothing if it can't be encoded
+func (r *recorder) encode(obj runtime.Object) json.RawMessage {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func (r *recorder) record(verb string, obj runtime.Object, key string, request json.RawMessage, response runtime.Object, err error) {
+	interaction := Interaction{
+		Verb:    verb,
+		Kind:    kindOf(r.scheme, obj),
+		Key:     key,
+		Request: request,
+		Error:   newCallError(err),
+	}
+	if err == nil && response != nil {
+		interaction.Response = r.encode(response)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+}
+
+// player answers calls from a cassette instead of an API server. Calls are
+// matched by verb, kind and key, each recorded answer used once in order.
+// Reads past the recorded ones get the last answer again, since how often
+// the collector lists objects doesn't change the outcome; a write that
+// wasn't recorded fails, and so does the run.
+type player struct {
+	scheme *runtime.Scheme
+
+	mu         sync.Mutex
+	queues     map[string][]Interaction
+	last       map[string]Interaction
+	unexpected []string
+}
+
+func newPlayer(scheme *runtime.Scheme, cassette *Cassette) *player {
+	p := &player{
+		scheme: scheme,
+		queues: make(map[string][]Interaction),
+		last:   make(map[string]Interaction),
+	}
+	for _, interaction := range cassette.Interactions {
+		key := interactionKey(interaction.Verb, interaction.Kind, interaction.Key)
+		p.queues[key] = append(p.queues[key], interaction)
+	}
+	return p
+}
+
+func (p *player) funcs() interceptor.Funcs {
+	return interceptor.Funcs{
+		Get: func(_ context.Context, _ client.WithWatch, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+			return p.answer("get", obj, key.String(), obj)
+		},
+		List: func(_ context.Context, _ client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			return p.answer("list", list, listKey(opts), list)
+		},
+		Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+			return p.answer("create", obj, objectKey(obj), obj)
+		},
+		Update: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.UpdateOption) error {
+			return p.answer("update", obj, objectKey(obj), obj)
+		},
+		Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+			return p.answer("patch", obj, objectKey(obj), obj)
+		},
+		Delete: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.DeleteOption) error {
+			return p.answer("delete", obj, objectKey(obj), nil)
+		},
+		SubResourceUpdate: func(_ context.Context, _ client.Client, subResource string, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+			return p.answer("update/"+subResource, obj, objectKey(obj), obj)
+		},
+		SubResourcePatch: func(_ context.Context, _ client.Client, subResource string, obj client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+			return p.answer("patch/"+subResource, obj, objectKey(obj), obj)
+		},
+	}
+}
+
+// answer replays the next recorded answer to a call, decoding its response
+// into the given object
+func (p *player) answer(verb string, obj runtime.Object, key string, into runtime.Object) error {
+	key = interactionKey(verb, kindOf(p.scheme, obj), key)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	interaction, ok := p.last[key]
+	if queue := p.queues[key]; len(queue) > 0 {
+		interaction, ok = queue[0], true
+		p.queues[key] = queue[1:]
+		p.last[key] = interaction
+	} else if !isRead(verb) {
+		ok = false
+	}
+	if !ok {
+		p.unexpected = append(p.unexpected, key)
+		return fmt.Errorf("no recorded interaction for %s", key)
+	}
+
+	if interaction.Error != nil {
+		return interaction.Error.err(p.scheme, obj)
+	}
+	if into == nil || len(interaction.Response) == 0 {
+		return nil
+	}
+	// Decode into a cleared object, as a client would
+	value := reflect.ValueOf(into).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	return json.Unmarshal(interaction.Response, into)
+}
+
+// divergence returns how the replayed run differed from the recorded one:
+// calls that weren't recorded and recorded writes that weren't made
+func (p *player) divergence() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	problems := make([]string, 0, len(p.unexpected))
+	for _, key := range p.unexpected {
+		problems = append(problems, "unexpected "+key)
+	}
+	for key, queue := range p.queues {
+		for _, interaction := range queue {
+			if !isRead(interaction.Verb) {
+				problems = append(problems, "missing "+key)
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
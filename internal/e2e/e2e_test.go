@@ -0,0 +1,42 @@
+package e2e
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestScenarios runs the default scenarios against envtest. It needs the
+// control plane binaries, so it is skipped unless KUBEBUILDER_ASSETS is set.
+func TestScenarios(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set; install the control plane binaries with setup-envtest")
+	}
+	if testing.Short() {
+		t.Skip("end-to-end scenarios take about a minute")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	results, err := Run(ctx, Options{
+		CRDDirectory: filepath.Join("..", "..", "deploy", "kubernetes", "crds"),
+		Timeout:      3 * time.Minute,
+		Hold:         45 * time.Second,
+		Scenarios:    DefaultScenarios(),
+	})
+	if err != nil {
+		t.Fatalf("end-to-end run failed: %v", err)
+	}
+
+	for _, result := range results {
+		result := result
+		t.Run(result.Scenario, func(t *testing.T) {
+			if !result.Passed {
+				t.Errorf("%s (%d replicas after %s)", result.Message, result.Replicas, result.Duration.Round(time.Second))
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	hydracontroller "github.com/hydraai/hydra-route/internal/controller"
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// CassetteOptions configures a stepped run that records or replays the
+// controller's API interactions
+type CassetteOptions struct {
+	// Directory of the cassettes, one per scenario, written by a recording
+	// run and read by a replay
+	Directory string
+
+	// Answer the controller's calls from the cassettes instead of an API server
+	Replay bool
+
+	// Record against controller-runtime's fake client instead of a control plane
+	Fake bool
+
+	// Record against the cluster of the current kubeconfig instead of envtest
+	UseExistingCluster bool
+
+	// Directory holding the CRD manifests to install when recording
+	CRDDirectory string
+
+	// Scenarios to run
+	Scenarios []Scenario
+}
+
+const (
+	// cassetteSteps is how many collection and reconcile rounds a run makes
+	cassetteSteps = 5
+
+	// cassetteStepInterval separates the rounds. Evaluation intervals and
+	// cooldowns are shorter, so every round may act.
+	cassetteStepInterval = 50 * time.Millisecond
+)
+
+// RunCassette runs each scenario in lockstep: each round collects metrics
+// once, reconciles the scenario's ingress and evaluates its services
+// directly, without a manager, watches or evaluation loops, so the
+// controller makes the same calls on every run. When recording, the
+// scenario is created on envtest, an existing cluster or the fake client
+// and the controller's calls are saved to the scenario's cassette; the
+// harness stands in for the deployment and HPA controllers between rounds.
+// When replaying, no control plane is needed: the controller is answered
+// from the cassette, and the scenario fails if the controller's writes
+// differ from the recorded ones.
+func RunCassette(ctx context.Context, opts CassetteOptions) ([]Result, error) {
+	scheme := newScheme()
+
+	// Client of the control plane recordings run against; nil on replay
+	// and with the fake client
+	var live client.WithWatch
+	if !opts.Replay && !opts.Fake {
+		env := &envtest.Environment{
+			CRDDirectoryPaths:     []string{opts.CRDDirectory},
+			ErrorIfCRDPathMissing: true,
+			UseExistingCluster:    &opts.UseExistingCluster,
+		}
+		envConfig, err := env.Start()
+		if err != nil {
+			return nil, fmt.Errorf("failed to start test environment: %w", err)
+		}
+		defer env.Stop()
+
+		if live, err = client.NewWithWatch(envConfig, client.Options{Scheme: scheme}); err != nil {
+			return nil, fmt.Errorf("failed to create client: %w", err)
+		}
+		defer deleteNamespaces(live, opts.Scenarios)
+	}
+
+	results := make([]Result, 0, len(opts.Scenarios))
+	for _, scenario := range opts.Scenarios {
+		result, err := runCassetteScenario(ctx, scheme, live, scenario, opts)
+		if err != nil {
+			return nil, fmt.Errorf("scenario %s: %w", scenario.Name, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// runCassetteScenario records or replays one scenario's cassette
+func runCassetteScenario(ctx context.Context, scheme *runtime.Scheme, live client.WithWatch, scenario Scenario, opts CassetteOptions) (Result, error) {
+	start := time.Now()
+	path := CassettePath(opts.Directory, scenario)
+
+	traffic := NewTrafficSource()
+	defer traffic.Close()
+	traffic.SetRequestRate(scenario.Namespace(), workloadName, workloadPort, scenario.RequestRate)
+
+	// The controller's client, and the one for setup and stand-ins outside
+	// the cassette, which is nil on replay
+	var c, setup client.Client
+	var rec *recorder
+	var play *player
+
+	if opts.Replay {
+		cassette, err := LoadCassette(path)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to load cassette: %w", err)
+		}
+		play = newPlayer(scheme, cassette)
+		c = interceptor.NewClient(fake.NewClientBuilder().WithScheme(scheme).Build(), play.funcs())
+	} else {
+		if live == nil {
+			live = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&appsv1.Deployment{}, &hydrav1alpha1.HydraRoutePolicy{}).
+				Build()
+		}
+		if err := scenario.apply(ctx, live); err != nil {
+			return Result{}, err
+		}
+		setup = live
+		rec = &recorder{scheme: scheme}
+		c = interceptor.NewClient(live, rec.funcs())
+	}
+
+	cfg := cassetteConfig(traffic.URL(), scenario)
+	collector := metrics.NewCollector(c, cfg.Metrics)
+	reconciler := &hydracontroller.HydraRouteReconciler{
+		Client:           c,
+		Scheme:           scheme,
+		MetricsCollector: collector,
+		AIScaler:         scaler.NewAIScaler(cfg.Scaling),
+		Config:           cfg,
+		Statuses:         hydracontroller.NewStatusTracker(),
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: scenario.Namespace(), Name: workloadName}}
+
+	for step := 0; step < cassetteSteps; step++ {
+		if setup != nil {
+			standIn(ctx, setup, scenario, !opts.UseExistingCluster)
+		}
+		// The reconcile registers the services the collection cycle covers
+		if _, err := reconciler.Reconcile(ctx, request); err != nil {
+			logrus.WithError(err).WithField("scenario", scenario.Name).Debug("Reconcile failed")
+		}
+		if err := collector.CollectOnce(ctx); err != nil {
+			logrus.WithError(err).WithField("step", step).Debug("Metrics collection failed")
+		}
+		reconciler.EvaluateAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return Result{}, ctx.Err()
+		case <-time.After(cassetteStepInterval):
+		}
+	}
+	if setup != nil {
+		standIn(ctx, setup, scenario, !opts.UseExistingCluster)
+	}
+
+	result := Result{Scenario: scenario.Name}
+	replicas, err := scenario.replicas(ctx, c)
+	switch {
+	case err != nil:
+		result.Message = fmt.Sprintf("failed to read deployment: %v", err)
+	case scenario.Expect.Check(replicas):
+		result.Passed = true
+		result.Replicas = replicas
+		result.Message = fmt.Sprintf("ended with %s", scenario.Expect.Description)
+	default:
+		result.Replicas = replicas
+		result.Message = fmt.Sprintf("expected %s, got %d", scenario.Expect.Description, replicas)
+	}
+	result.Duration = time.Since(start)
+
+	if play != nil {
+		if problems := play.divergence(); len(problems) > 0 {
+			result.Passed = false
+			result.Message = "replay diverged: " + strings.Join(problems, "; ")
+		}
+	}
+
+	if rec != nil {
+		if err := rec.cassette.Save(path); err != nil {
+			return Result{}, fmt.Errorf("failed to save cassette: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// cassetteConfig is the end-to-end configuration with intervals short
+// enough for the stepped rounds, and replica parity enabled with a long
+// back-off so a reverted change is held for the rest of the run
+func cassetteConfig(trafficURL string, scenario Scenario) *config.Config {
+	cfg := controllerConfig(trafficURL, []Scenario{scenario})
+	cfg.Scaling.EvaluationInterval = cassetteStepInterval / 5
+	cfg.Scaling.Cooldown.ScaleUpCooldown = cassetteStepInterval / 5
+	cfg.Scaling.Cooldown.ScaleDownCooldown = cassetteStepInterval / 5
+	cfg.General.ReplicaParity.Enabled = true
+	cfg.General.ReplicaParity.InitialBackoff = time.Hour
+	cfg.General.ReplicaParity.MaxBackoff = time.Hour
+	return cfg
+}
+
+// standIn plays the controllers the recorded scenarios rely on: an HPA
+// resets spec.replicas to the replicas it pins, and without a deployment
+// controller its status update is mirrored from the spec
+func standIn(ctx context.Context, c client.Client, scenario Scenario, mirrorStatus bool) {
+	if scenario.NoDeployment {
+		return
+	}
+	log := logrus.WithField("scenario", scenario.Name)
+	deployment := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: scenario.Namespace(), Name: workloadName}, deployment); err != nil {
+		log.WithError(err).Debug("Failed to read deployment")
+		return
+	}
+
+	if scenario.HPAReplicas > 0 && (deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != scenario.HPAReplicas) {
+		replicas := scenario.HPAReplicas
+		deployment.Spec.Replicas = &replicas
+		if err := c.Update(ctx, deployment); err != nil {
+			log.WithError(err).Debug("Failed to revert deployment replicas")
+			return
+		}
+	}
+
+	if !mirrorStatus || deployment.Spec.Replicas == nil || deployment.Status.Replicas == *deployment.Spec.Replicas {
+		return
+	}
+	replicas := *deployment.Spec.Replicas
+	deployment.Status.Replicas = replicas
+	deployment.Status.ReadyReplicas = replicas
+	deployment.Status.AvailableReplicas = replicas
+	deployment.Status.UpdatedReplicas = replicas
+	if err := c.Status().Update(ctx, deployment); err != nil {
+		log.WithError(err).Debug("Failed to update deployment status")
+	}
+}
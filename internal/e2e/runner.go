@@ -0,0 +1,291 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	hydracontroller "github.com/hydraai/hydra-route/internal/controller"
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Options configures an end-to-end run
+type Options struct {
+	// Run against the cluster of the current kubeconfig (e.g. kind) instead
+	// of a local envtest control plane
+	UseExistingCluster bool
+
+	// Directory holding the CRD manifests to install
+	CRDDirectory string
+
+	// How long a scenario may take to reach its expected replicas
+	Timeout time.Duration
+
+	// How long hold expectations must stay true
+	Hold time.Duration
+
+	// Leave the scenario namespaces in place after the run
+	KeepNamespaces bool
+
+	// Scenarios to run
+	Scenarios []Scenario
+}
+
+// Result is the outcome of a scenario
+type Result struct {
+	Scenario string
+	Passed   bool
+	Replicas int32
+	Message  string
+	Duration time.Duration
+}
+
+// pollInterval is how often scenarios check their deployment
+const pollInterval = time.Second
+
+// Run starts a control plane (or connects to an existing cluster), runs the
+// controller in-process against the fake metrics source and waits for every
+// scenario's expectation. Scenarios run concurrently, each in its own
+// namespace.
+func Run(ctx context.Context, opts Options) ([]Result, error) {
+	scheme := newScheme()
+
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     []string{opts.CRDDirectory},
+		ErrorIfCRDPathMissing: true,
+		UseExistingCluster:    &opts.UseExistingCluster,
+	}
+	restConfig, err := env.Start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start test environment: %w", err)
+	}
+	defer env.Stop()
+
+	traffic := NewTrafficSource()
+	defer traffic.Close()
+
+	cfg := controllerConfig(traffic.URL(), opts.Scenarios)
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:  scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manager: %w", err)
+	}
+
+	collector := metrics.NewCollector(mgr.GetClient(), cfg.Metrics)
+	aiScaler := scaler.NewAIScaler(cfg.Scaling)
+	reconciler := &hydracontroller.HydraRouteReconciler{
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		MetricsCollector: collector,
+		AIScaler:         aiScaler,
+		Config:           cfg,
+		Statuses:         hydracontroller.NewStatusTracker(),
+		Recorder:         mgr.GetEventRecorderFor("hydra-route"),
+	}
+	if err := reconciler.SetupWithManager(mgr); err != nil {
+		return nil, fmt.Errorf("failed to set up controller: %w", err)
+	}
+	if err := mgr.Add(collector); err != nil {
+		return nil, fmt.Errorf("failed to set up metrics collector: %w", err)
+	}
+
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	for _, scenario := range opts.Scenarios {
+		traffic.SetRequestRate(scenario.Namespace(), workloadName, workloadPort, scenario.RequestRate)
+		if err := scenario.apply(ctx, c); err != nil {
+			return nil, fmt.Errorf("scenario %s: %w", scenario.Name, err)
+		}
+	}
+	if !opts.KeepNamespaces {
+		defer deleteNamespaces(c, opts.Scenarios)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		if err := mgr.Start(runCtx); err != nil {
+			logrus.WithError(err).Error("Controller manager stopped")
+		}
+	}()
+
+	// envtest runs an API server only; stand in for the deployment controller
+	if !opts.UseExistingCluster {
+		go syncDeploymentStatus(runCtx, c, opts.Scenarios)
+	}
+
+	results := make([]Result, len(opts.Scenarios))
+	var wg sync.WaitGroup
+	for i, scenario := range opts.Scenarios {
+		wg.Add(1)
+		go func(i int, scenario Scenario) {
+			defer wg.Done()
+			results[i] = wait(runCtx, c, scenario, opts)
+		}(i, scenario)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// newScheme returns the scheme with the built-in and HydraRoute types
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(hydrav1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+// Failed reports whether any scenario failed
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if !result.Passed {
+			return true
+		}
+	}
+	return false
+}
+
+// controllerConfig is the default configuration tightened for fast feedback:
+// one second collection and cooldowns, the fake nginx endpoint as the only
+// request source and the scenarios' policies
+func controllerConfig(trafficURL string, scenarios []Scenario) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Metrics.NginxMetricsURL = trafficURL
+	cfg.Metrics.PrometheusURL = ""
+	cfg.Metrics.CollectionInterval = time.Second
+	cfg.Scaling.EvaluationInterval = time.Second
+	cfg.Scaling.Cooldown.ScaleUpCooldown = time.Second
+	cfg.Scaling.Cooldown.ScaleDownCooldown = time.Second
+	cfg.Scaling.AIModel.Registry.Backend = ""
+	cfg.General.DryRun = false
+	cfg.General.EnablePolicies = false
+
+	for _, scenario := range scenarios {
+		cfg.Scaling.Policies = append(cfg.Scaling.Policies, scenario.policyConfigs()...)
+	}
+	return cfg
+}
+
+// wait polls a scenario's deployment until its expectation is met, broken
+// (for hold expectations) or the timeout expires
+func wait(ctx context.Context, c client.Client, scenario Scenario, opts Options) Result {
+	start := time.Now()
+	result := Result{Scenario: scenario.Name}
+
+	deadline := opts.Timeout
+	if scenario.Expect.Hold {
+		deadline = opts.Hold
+	}
+	timer := time.NewTimer(deadline)
+	defer timer.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		replicas, err := scenario.replicas(ctx, c)
+		if err == nil {
+			result.Replicas = replicas
+			met := scenario.Expect.Check(replicas)
+			switch {
+			case met && !scenario.Expect.Hold:
+				result.Passed = true
+				result.Message = fmt.Sprintf("reached %s", scenario.Expect.Description)
+			case !met && scenario.Expect.Hold:
+				result.Message = fmt.Sprintf("expected %s, got %d", scenario.Expect.Description, replicas)
+			}
+			if result.Message != "" {
+				result.Duration = time.Since(start)
+				return result
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			result.Message = ctx.Err().Error()
+			result.Duration = time.Since(start)
+			return result
+		case <-timer.C:
+			result.Duration = time.Since(start)
+			if scenario.Expect.Hold {
+				result.Passed = true
+				result.Message = fmt.Sprintf("held %s for %s", scenario.Expect.Description, deadline)
+			} else if err != nil {
+				result.Message = fmt.Sprintf("failed to read deployment: %v", err)
+			} else {
+				result.Message = fmt.Sprintf("expected %s within %s, got %d", scenario.Expect.Description, deadline, result.Replicas)
+			}
+			return result
+		case <-ticker.C:
+		}
+	}
+}
+
+// syncDeploymentStatus mirrors spec.replicas into status.replicas of the
+// scenario deployments, which the collector reads as current replicas
+func syncDeploymentStatus(ctx context.Context, c client.Client, scenarios []Scenario) {
+	ticker := time.NewTicker(pollInterval / 2)
+	defer ticker.Stop()
+
+	for {
+		for _, scenario := range scenarios {
+			deployment := &appsv1.Deployment{}
+			if err := c.Get(ctx, client.ObjectKey{Namespace: scenario.Namespace(), Name: workloadName}, deployment); err != nil {
+				continue
+			}
+			if deployment.Spec.Replicas == nil || deployment.Status.Replicas == *deployment.Spec.Replicas {
+				continue
+			}
+			replicas := *deployment.Spec.Replicas
+			deployment.Status.Replicas = replicas
+			deployment.Status.ReadyReplicas = replicas
+			deployment.Status.AvailableReplicas = replicas
+			deployment.Status.UpdatedReplicas = replicas
+			if err := c.Status().Update(ctx, deployment); err != nil {
+				logrus.WithError(err).WithField("scenario", scenario.Name).Debug("Failed to update deployment status")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deleteNamespaces removes the scenario namespaces. envtest has no namespace
+// controller, so there they stay terminating until the control plane stops.
+func deleteNamespaces(c client.Client, scenarios []Scenario) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, scenario := range scenarios {
+		namespace := &v1.Namespace{}
+		namespace.Name = scenario.Namespace()
+		if err := c.Delete(ctx, namespace); client.IgnoreNotFound(err) != nil {
+			logrus.WithError(err).WithField("namespace", namespace.Name).Warn("Failed to delete scenario namespace")
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydracontroller "github.com/hydraai/hydra-route/internal/controller"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Names of the workload every scenario creates in its own namespace
+const (
+	workloadName = "web"
+	workloadPort = int32(80)
+
+	// workloadImage only has to start on kind; envtest runs no pods
+	workloadImage = "registry.k8s.io/pause:3.9"
+)
+
+// Scenario is a single end-to-end case: a workload behind an ingress, the
+// traffic the fake metrics source reports for it and the replica count the
+// controller is expected to reach
+type Scenario struct {
+	// Name, also used for the scenario's namespace
+	Name string
+
+	// Initial replicas of the deployment
+	Replicas int32
+
+	// Requests per second reported for the service
+	RequestRate float64
+
+	// Whether the ingress carries hydra-route.ai/enabled: "true"
+	Enabled bool
+
+	// Additional ingress annotations, such as min/max replicas
+	Annotations map[string]string
+
+	// Policy rules scoped to the scenario's service. They pin the scale
+	// factor so outcomes don't depend on the untrained model.
+	Policies []string
+
+	// Cluster state the scenario starts from: no deployment behind the
+	// service, a paused rollout, or an HPA pinning the deployment at
+	// HPAReplicas
+	NoDeployment bool
+	Paused       bool
+	HPAReplicas  int32
+
+	// Replica count the scenario is waiting for
+	Expect Expectation
+}
+
+// Expectation is a condition on the deployment's spec.replicas
+type Expectation struct {
+	// Description printed with the result
+	Description string
+
+	// Check reports whether the replica count satisfies the expectation
+	Check func(replicas int32) bool
+
+	// Hold requires Check to stay true for the whole hold period instead of
+	// becoming true before the timeout
+	Hold bool
+}
+
+// Namespace is the namespace the scenario's objects are created in
+func (s Scenario) Namespace() string {
+	return "hydra-e2e-" + s.Name
+}
+
+// serviceKey is the namespace/service key policies are scoped to
+func (s Scenario) serviceKey() string {
+	return s.Namespace() + "/" + workloadName
+}
+
+// DefaultScenarios are the scenarios run when none are selected
+func DefaultScenarios() []Scenario {
+	return []Scenario{
+		{
+			Name:        "scale-up",
+			Replicas:    2,
+			RequestRate: 1000,
+			Enabled:     true,
+			Policies:    []string{"if request_rate > 500 then scale_factor = 2"},
+			Expect: Expectation{
+				Description: "at least 4 replicas",
+				Check:       func(replicas int32) bool { return replicas >= 4 },
+			},
+		},
+		{
+			Name:        "scale-down",
+			Replicas:    4,
+			RequestRate: 2,
+			Enabled:     true,
+			Policies:    []string{"if request_rate < 10 then scale_factor = 0.5"},
+			Expect: Expectation{
+				Description: "at most 2 replicas",
+				Check:       func(replicas int32) bool { return replicas <= 2 },
+			},
+		},
+		{
+			Name:        "max-replicas",
+			Replicas:    1,
+			RequestRate: 1000,
+			Enabled:     true,
+			Annotations: map[string]string{hydracontroller.HydraRouteMaxReplicasAnnotation: "3"},
+			Policies:    []string{"if request_rate > 500 then scale_factor = 4"},
+			Expect: Expectation{
+				Description: "exactly 3 replicas (max-replicas annotation)",
+				Check:       func(replicas int32) bool { return replicas == 3 },
+			},
+		},
+		{
+			Name:        "disabled",
+			Replicas:    2,
+			RequestRate: 1000,
+			Enabled:     false,
+			Policies:    []string{"if request_rate > 500 then scale_factor = 2"},
+			Expect: Expectation{
+				Description: "unchanged at 2 replicas (ingress not enabled)",
+				Check:       func(replicas int32) bool { return replicas == 2 },
+				Hold:        true,
+			},
+		},
+	}
+}
+
+// ClusterStateScenarios cover cluster states the controller has to cope
+// with rather than traffic patterns. They run in steps against recorded API
+// interactions; see RunCassette.
+func ClusterStateScenarios() []Scenario {
+	return []Scenario{
+		{
+			Name:         "missing-deployment",
+			Replicas:     2,
+			RequestRate:  1000,
+			Enabled:      true,
+			NoDeployment: true,
+			Policies:     []string{"if request_rate > 500 then scale_factor = 2"},
+			Expect: Expectation{
+				Description: "no deployment created",
+				Check:       func(replicas int32) bool { return replicas == 0 },
+			},
+		},
+		{
+			Name:        "conflicting-hpa",
+			Replicas:    2,
+			RequestRate: 1000,
+			Enabled:     true,
+			HPAReplicas: 2,
+			Policies:    []string{"if request_rate > 500 then scale_factor = 2"},
+			Expect: Expectation{
+				Description: "left at the 2 replicas the HPA reverts to",
+				Check:       func(replicas int32) bool { return replicas == 2 },
+			},
+		},
+		{
+			Name:        "paused-rollout",
+			Replicas:    2,
+			RequestRate: 1000,
+			Enabled:     true,
+			Paused:      true,
+			Policies:    []string{"if request_rate > 500 then scale_factor = 2"},
+			Expect: Expectation{
+				Description: "at least 4 replicas (scaling a paused deployment is allowed)",
+				Check:       func(replicas int32) bool { return replicas >= 4 },
+			},
+		},
+	}
+}
+
+// policyConfigs returns the scenario's policies as configuration entries
+func (s Scenario) policyConfigs() []config.PolicyConfig {
+	policies := make([]config.PolicyConfig, 0, len(s.Policies))
+	for i, rule := range s.Policies {
+		policies = append(policies, config.PolicyConfig{
+			Name:     fmt.Sprintf("e2e-%s-%d", s.Name, i),
+			Rule:     rule,
+			Services: []string{s.serviceKey()},
+		})
+	}
+	return policies
+}
+
+// apply creates the scenario's namespace, deployment, service and ingress
+func (s Scenario) apply(ctx context.Context, c client.Client) error {
+	namespace := s.Namespace()
+	labels := map[string]string{"app": workloadName}
+	replicas := s.Replicas
+
+	annotations := make(map[string]string, len(s.Annotations)+1)
+	for name, value := range s.Annotations {
+		annotations[name] = value
+	}
+	if s.Enabled {
+		annotations[hydracontroller.HydraRouteAnnotation] = "true"
+	}
+
+	ingressClass := "nginx"
+	pathType := networkingv1.PathTypePrefix
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: workloadName, Namespace: namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Paused:   s.Paused,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: workloadName, Image: workloadImage}},
+				},
+			},
+		},
+	}
+
+	objects := []client.Object{
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: workloadName, Namespace: namespace},
+			Spec: v1.ServiceSpec{
+				Selector: labels,
+				Ports: []v1.ServicePort{{
+					Name:       "http",
+					Port:       workloadPort,
+					TargetPort: intstr.FromInt(int(workloadPort)),
+				}},
+			},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: workloadName, Namespace: namespace, Annotations: annotations},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: &ingressClass,
+				Rules: []networkingv1.IngressRule{{
+					Host: s.Name + ".e2e.local",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     "/",
+								PathType: &pathType,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: workloadName,
+										Port: networkingv1.ServiceBackendPort{Number: workloadPort},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		},
+	}
+
+	if !s.NoDeployment {
+		objects = append(objects, deployment)
+	}
+	if s.HPAReplicas > 0 {
+		hpaReplicas := s.HPAReplicas
+		objects = append(objects, &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: workloadName, Namespace: namespace},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       workloadName,
+				},
+				MinReplicas: &hpaReplicas,
+				MaxReplicas: hpaReplicas,
+			},
+		})
+	}
+
+	for _, object := range objects {
+		if err := c.Create(ctx, object); err != nil {
+			return fmt.Errorf("failed to create %T %s: %w", object, object.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// replicas returns the scenario deployment's spec.replicas, or 0 for a
+// scenario without a deployment that still has none
+func (s Scenario) replicas(ctx context.Context, c client.Client) (int32, error) {
+	deployment := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: s.Namespace(), Name: workloadName}, deployment); err != nil {
+		if s.NoDeployment && apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if deployment.Spec.Replicas == nil {
+		return 1, nil
+	}
+	return *deployment.Spec.Replicas, nil
+}
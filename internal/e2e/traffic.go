@@ -0,0 +1,62 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+)
+
+// TrafficSource is a fake nginx ingress controller stats endpoint. It serves
+// /api/v1/nginx/stats with a per-upstream request rate for every service a
+// scenario sets, so the collector attributes traffic to the right service.
+type TrafficSource struct {
+	mu        sync.RWMutex
+	upstreams map[string]float64
+	server    *httptest.Server
+}
+
+// NewTrafficSource starts the fake stats endpoint on a local port
+func NewTrafficSource() *TrafficSource {
+	t := &TrafficSource{upstreams: make(map[string]float64)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/nginx/stats", t.serveStats)
+	t.server = httptest.NewServer(mux)
+	return t
+}
+
+// URL is the base URL to configure as metrics.nginx_metrics_url
+func (t *TrafficSource) URL() string {
+	return t.server.URL
+}
+
+// SetRequestRate sets the requests per second reported for a service port
+func (t *TrafficSource) SetRequestRate(namespace, service string, port int32, rate float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.upstreams[fmt.Sprintf("%s-%s-%d", namespace, service, port)] = rate
+}
+
+// Close stops the endpoint
+func (t *TrafficSource) Close() {
+	t.server.Close()
+}
+
+func (t *TrafficSource) serveStats(w http.ResponseWriter, r *http.Request) {
+	t.mu.RLock()
+	stats := metrics.NginxMetrics{
+		ResponseTime:    50,
+		UpstreamMetrics: make(map[string]float64, len(t.upstreams)),
+	}
+	for name, rate := range t.upstreams {
+		stats.UpstreamMetrics[name] = rate
+		stats.RequestsPerSecond += rate
+	}
+	t.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
@@ -0,0 +1,66 @@
+// Package egress enforces air-gapped mode on the outbound connections of the
+// controller. Clients of the Kubernetes API server build their own transport
+// and are not affected.
+package egress
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// DialFunc dials a network address, like net.Dialer.DialContext
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+var blockedConnections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "hydra_route_egress_blocked_total",
+	Help: "Outbound connections refused in air-gapped mode, by address.",
+}, []string{"address"})
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(blockedConnections)
+}
+
+// policy is the air-gapped configuration in effect; nil allows everything
+var policy atomic.Pointer[config.AirGappedConfig]
+
+// Restrict puts cfg in effect and guards http.DefaultTransport, which the
+// notification and webhook clients use. It does nothing unless cfg is enabled.
+func Restrict(cfg config.AirGappedConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	policy.Store(&cfg)
+	transport := http.DefaultTransport.(*http.Transport)
+	transport.DialContext = Guard(transport.DialContext)
+}
+
+// Check returns an error when the policy in effect doesn't allow connecting
+// to address (host:port)
+func Check(address string) error {
+	cfg := policy.Load()
+	if cfg == nil || cfg.Allows(address) {
+		return nil
+	}
+	blockedConnections.WithLabelValues(address).Inc()
+	return fmt.Errorf("air-gapped mode refuses the connection to %s, which is not in allowed_endpoints", address)
+}
+
+// Guard wraps dial to refuse the addresses the policy in effect doesn't
+// allow. Addresses are checked as given, before name resolution, and so
+// are proxies from the environment.
+func Guard(dial DialFunc) DialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if err := Check(address); err != nil {
+			return nil, err
+		}
+		return dial(ctx, network, address)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build boringcrypto
+
+package fips
+
+import (
+	"crypto/boring"
+	_ "crypto/tls/fipsonly"
+)
+
+func enabled() bool {
+	return boring.Enabled()
+}
@@ -0,0 +1,10 @@
+// Package fips reports whether the binary uses FIPS-validated crypto.
+// Building with GOEXPERIMENT=boringcrypto (make build-fips) links the
+// BoringCrypto module and, through crypto/tls/fipsonly, restricts every TLS
+// connection of the binary to FIPS-approved versions, suites and curves.
+package fips
+
+// Enabled reports whether crypto operations go through the FIPS module
+func Enabled() bool {
+	return enabled()
+}
@@ -0,0 +1,325 @@
+// Package lint checks scaling configuration and HydraRoute policies for
+// contradictions, on their own and against the metrics history of the
+// services they cover
+package lint
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Finding severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Finding is one problem found
+type Finding struct {
+	Severity string
+
+	// What the finding is about: a configuration key, scaling policy or
+	// HydraRoutePolicy, and the service when history was involved
+	Subject string
+	Message string
+}
+
+// Options configures a lint run
+type Options struct {
+	Config *config.Config
+
+	// HydraRoutePolicies to check, with the backend services (namespace/name)
+	// each covers by the key of the policy (namespace/name)
+	Policies []hydrav1alpha1.HydraRoutePolicy
+	Services map[string][]string
+
+	// Metrics history by service (namespace/name), oldest sample first; may be empty
+	History map[string][]*metrics.MetricsData
+}
+
+// modelVariables are the policy variables that depend on the model's output
+// or on trends the archived samples don't carry, so rules reading them
+// can't be replayed against the history
+var modelVariables = map[string]bool{
+	"model":                        true,
+	scaler.PolicyTargetScaleFactor: true,
+	scaler.PolicyTargetConfidence:  true,
+	"trend_cpu":                    true,
+	"trend_memory":                 true,
+	"trend_requests":               true,
+}
+
+// Run performs every check, configuration first
+func Run(opts Options) []Finding {
+	var findings []Finding
+	findings = append(findings, checkScalingConfig(opts.Config.Scaling)...)
+	findings = append(findings, checkScalingPolicies(opts.Config.Scaling, opts.History)...)
+	findings = append(findings, checkPolicies(opts)...)
+	findings = append(findings, checkUncovered(opts)...)
+	return findings
+}
+
+// Failed reports whether any finding is an error
+func Failed(findings []Finding) bool {
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// checkScalingConfig checks the global replica bounds and thresholds
+func checkScalingConfig(cfg config.ScalingConfig) []Finding {
+	var findings []Finding
+	if cfg.MinReplicas == cfg.MaxReplicas {
+		findings = append(findings, Finding{SeverityWarning, "scaling.min_replicas",
+			fmt.Sprintf("min_replicas equals max_replicas (%d): services without their own bounds are never scaled", cfg.MinReplicas)})
+	}
+
+	for _, threshold := range []struct {
+		name       string
+		up, down   float64
+		upperBound float64
+	}{
+		{"cpu_utilization", cfg.ScaleUpThresholds.CPUUtilization, cfg.ScaleDownThresholds.CPUUtilization, 0},
+		{"memory_utilization", cfg.ScaleUpThresholds.MemoryUtilization, cfg.ScaleDownThresholds.MemoryUtilization, 0},
+		{"request_rate", cfg.ScaleUpThresholds.RequestRate, cfg.ScaleDownThresholds.RequestRate, 0},
+		{"response_time", cfg.ScaleUpThresholds.ResponseTime, cfg.ScaleDownThresholds.ResponseTime, 0},
+		{"error_rate", cfg.ScaleUpThresholds.ErrorRate, cfg.ScaleDownThresholds.ErrorRate, 100},
+	} {
+		if threshold.up > 0 && threshold.down >= threshold.up {
+			findings = append(findings, Finding{SeverityError, "scaling.scale_down_thresholds." + threshold.name,
+				fmt.Sprintf("scale-down threshold %g is not below the scale-up threshold %g: decisions would flap", threshold.down, threshold.up)})
+		}
+		if threshold.upperBound > 0 && threshold.up > threshold.upperBound {
+			findings = append(findings, Finding{SeverityError, "scaling.scale_up_thresholds." + threshold.name,
+				fmt.Sprintf("scale-up threshold %g can never be reached: the value is at most %g", threshold.up, threshold.upperBound)})
+		}
+	}
+	return findings
+}
+
+// checkScalingPolicies compiles the scaling policy rules and replays their
+// conditions against the history of the services they apply to
+func checkScalingPolicies(cfg config.ScalingConfig, history map[string][]*metrics.MetricsData) []Finding {
+	var findings []Finding
+	policies := make([]*scaler.Policy, 0, len(cfg.Policies))
+	for i, policyConfig := range cfg.Policies {
+		if policyConfig.Name == "" {
+			policyConfig.Name = fmt.Sprintf("policy-%d", i)
+		}
+		compiled, err := scaler.CompilePolicies([]config.PolicyConfig{policyConfig})
+		if err != nil {
+			findings = append(findings, Finding{SeverityError, "scaling.policies", err.Error()})
+			continue
+		}
+		policies = append(policies, compiled...)
+	}
+	if len(history) == 0 {
+		return findings
+	}
+
+	for _, policy := range policies {
+		subject := "scaling policy " + policy.Name
+		if policy.Rule.Condition == nil {
+			continue
+		}
+		if readsAny(policy.Rule.Condition.Variables(), modelVariables) {
+			findings = append(findings, Finding{SeverityInfo, subject, "condition reads the model output or trends, not replayed against history"})
+			continue
+		}
+		for service := range policy.Services {
+			if _, ok := history[service]; !ok {
+				findings = append(findings, Finding{SeverityWarning, subject,
+					fmt.Sprintf("service %s has no history; check the name is namespace/service", service)})
+			}
+		}
+
+		samples, fired := 0, 0
+		for _, key := range sortedKeys(history) {
+			if len(policy.Services) > 0 && !policy.Services[key] {
+				continue
+			}
+			for _, sample := range history[key] {
+				holds, err := policy.Rule.Condition.Eval(sampleVariables(sample))
+				if err != nil {
+					continue
+				}
+				samples++
+				if holds != 0 {
+					fired++
+				}
+			}
+		}
+		switch {
+		case samples == 0:
+		case fired == 0:
+			findings = append(findings, Finding{SeverityWarning, subject,
+				fmt.Sprintf("condition never held in %d samples: the rule has no effect", samples)})
+		case fired == samples && policy.Rule.Target == scaler.PolicyTargetScaleFactor && !readsAny(policy.Rule.Value.Variables(), modelVariables):
+			findings = append(findings, Finding{SeverityWarning, subject,
+				fmt.Sprintf("condition held in all %d samples and the rule ignores the model: the model never decides", samples)})
+		}
+	}
+	return findings
+}
+
+// checkPolicies checks each HydraRoutePolicy's bounds and targets, and
+// whether its services' history could ever reach the targets
+func checkPolicies(opts Options) []Finding {
+	var findings []Finding
+	cfg := opts.Config.Scaling
+
+	byIngress := make(map[string]string)
+	for _, policy := range opts.Policies {
+		key := policy.Namespace + "/" + policy.Name
+		subject := "HydraRoutePolicy " + key
+
+		ingress := policy.Namespace + "/" + policy.Spec.IngressName
+		if first, ok := byIngress[ingress]; ok {
+			findings = append(findings, Finding{SeverityWarning, subject,
+				fmt.Sprintf("ingress %s is also referenced by %s; only one of them takes effect", ingress, first)})
+		} else {
+			byIngress[ingress] = key
+		}
+
+		minReplicas, maxReplicas := cfg.MinReplicas, cfg.MaxReplicas
+		if policy.Spec.MinReplicas != nil {
+			minReplicas = *policy.Spec.MinReplicas
+		}
+		if policy.Spec.MaxReplicas != nil {
+			maxReplicas = *policy.Spec.MaxReplicas
+		}
+		switch {
+		case minReplicas > maxReplicas:
+			findings = append(findings, Finding{SeverityError, subject,
+				fmt.Sprintf("minReplicas %d is above maxReplicas %d", minReplicas, maxReplicas)})
+		case minReplicas == maxReplicas:
+			findings = append(findings, Finding{SeverityWarning, subject,
+				fmt.Sprintf("minReplicas equals maxReplicas (%d): the model can never change replicas", minReplicas)})
+		}
+
+		if _, err := scaler.ParseScaleFactorBounds(policy.Spec.MinScaleFactor, policy.Spec.MaxScaleFactor); err != nil {
+			findings = append(findings, Finding{SeverityError, subject, err.Error() + "; the configured bounds apply"})
+		}
+
+		targetCPU := cfg.ScaleUpThresholds.CPUUtilization
+		if policy.Spec.TargetCPUUtilization != nil {
+			targetCPU = float64(*policy.Spec.TargetCPUUtilization)
+		}
+		for _, service := range opts.Services[key] {
+			findings = append(findings, checkReachable(subject+" ("+service+")", opts.History[service], targetCPU, maxReplicas)...)
+		}
+	}
+	return findings
+}
+
+// checkUncovered checks the services in the history that no policy covers
+// against the global CPU threshold and maximum replicas
+func checkUncovered(opts Options) []Finding {
+	covered := make(map[string]bool)
+	for _, services := range opts.Services {
+		for _, service := range services {
+			covered[service] = true
+		}
+	}
+
+	var findings []Finding
+	cfg := opts.Config.Scaling
+	for _, service := range sortedKeys(opts.History) {
+		if !covered[service] {
+			findings = append(findings, checkReachable("service "+service, opts.History[service],
+				cfg.ScaleUpThresholds.CPUUtilization, cfg.MaxReplicas)...)
+		}
+	}
+	return findings
+}
+
+// checkReachable compares a service's history with its CPU target and
+// maximum replicas
+func checkReachable(subject string, history []*metrics.MetricsData, targetCPU float64, maxReplicas int32) []Finding {
+	if len(history) == 0 {
+		return nil
+	}
+
+	var findings []Finding
+	var peak, peakThrottling float64
+	atMax := 0
+	for _, sample := range history {
+		if sample.CPUUtilization > peak {
+			peak, peakThrottling = sample.CPUUtilization, sample.CPUThrottling
+		}
+		if sample.CurrentReplicas >= maxReplicas {
+			atMax++
+		}
+	}
+
+	if targetCPU > 0 && peak > 0 && peak < targetCPU {
+		if peakThrottling > 0 {
+			findings = append(findings, Finding{SeverityError, subject,
+				fmt.Sprintf("CPU target %g%% is unreachable: CPU peaked at %.0f%% of requests while throttled %.0f%% of periods, so limits cap it below the target",
+					targetCPU, peak, peakThrottling)})
+		} else {
+			findings = append(findings, Finding{SeverityInfo, subject,
+				fmt.Sprintf("CPU target %g%% was never reached in %d samples (peak %.0f%%)", targetCPU, len(history), peak)})
+		}
+	}
+	if share := float64(atMax) / float64(len(history)); share >= 0.5 {
+		findings = append(findings, Finding{SeverityWarning, subject,
+			fmt.Sprintf("at maxReplicas %d in %.0f%% of samples: the bound, not the model, sizes the service", maxReplicas, math.Round(share*100))})
+	}
+	return findings
+}
+
+// sampleVariables are the policy variables of an archived sample. Trends and
+// model outputs are unknown and left zero.
+func sampleVariables(sample *metrics.MetricsData) map[string]float64 {
+	features := scaler.FeaturesFromMetrics(sample)
+	return map[string]float64{
+		"cpu_utilization":          features.CPUUtilization,
+		"memory_utilization":       features.MemoryUtilization,
+		"request_rate":             features.RequestRate,
+		"network_bandwidth":        features.NetworkBandwidth,
+		"io_bandwidth":             features.IOBandwidth,
+		"response_time":            features.ResponseTime,
+		"error_rate":               features.ErrorRate,
+		"concurrency":              sample.Concurrency,
+		"cpu_throttling":           sample.CPUThrottling,
+		"retry_amplification":      sample.RetryAmplification,
+		"oom_kills":                float64(sample.OOMKills),
+		"memory_limit_utilization": sample.MemoryLimitUtilization,
+		"token_rate":               sample.TokenRate,
+		"time_to_first_token":      sample.TimeToFirstToken,
+		"latency_burn_rate_5m":     sample.LatencyBurnRate5m,
+		"latency_burn_rate_1h":     sample.LatencyBurnRate1h,
+		"time_of_day":              float64(sample.Timestamp.Hour()),
+		"day_of_week":              float64(sample.Timestamp.Weekday()),
+		"current_replicas":         float64(sample.CurrentReplicas),
+	}
+}
+
+func readsAny(variables []string, names map[string]bool) bool {
+	for _, name := range variables {
+		if names[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(history map[string][]*metrics.MetricsData) []string {
+	keys := make([]string, 0, len(history))
+	for key := range history {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,308 @@
+// Package loadtest drives the scaler's decision pipeline with synthetic
+// metrics for many services at once and measures its throughput, lock
+// contention and memory
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"runtime/metrics"
+	"sort"
+	"sync"
+	"time"
+
+	hydrametrics "github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Namespace of the synthetic services
+const Namespace = "loadtest"
+
+// mutexWaitMetric is the cumulative time goroutines spent blocked on
+// sync.Mutex and sync.RWMutex
+const mutexWaitMetric = "/sync/mutex/wait/total:seconds"
+
+// shapes the synthetic services cycle through
+var shapes = []string{"sine", "ramp", "step", "spike", "constant"}
+
+// Options configures a load test
+type Options struct {
+	// Scaling configuration of the scaler under test
+	Config config.ScalingConfig
+
+	// Synthetic services, and the samples decided on per service
+	Services int
+	Rounds   int
+
+	// Goroutines making decisions concurrently; each owns a share of the services
+	Workers int
+
+	// Time between a service's samples, advanced on a simulated clock
+	Interval time.Duration
+
+	// Seed of the traffic curves and their noise
+	Seed int64
+}
+
+// Result holds the measurements of a load test
+type Result struct {
+	Services int
+	Workers  int
+
+	// Calls of MakeScalingDecision, and how they ended
+	Calls     int
+	Decisions int
+	Changes   int
+	Skipped   int
+	Errors    int
+
+	// First error returned, and whether the run was cut short
+	FirstError  error
+	Interrupted bool
+
+	Duration       time.Duration
+	CallsPerSecond float64
+	LatencyP50     time.Duration
+	LatencyP99     time.Duration
+	LatencyMax     time.Duration
+
+	// Time goroutines spent blocked on mutexes during the run, in total and
+	// as a share of the workers' time
+	MutexWait      time.Duration
+	MutexWaitShare float64
+
+	// Allocations per call and garbage collections during the run
+	BytesPerCall  float64
+	AllocsPerCall float64
+	GCCycles      uint32
+	GCPauseTotal  time.Duration
+	GCPauseMax    time.Duration
+
+	// Live heap the run added, in total and per service
+	HeapGrowth     int64
+	HeapPerService float64
+}
+
+// service is the state of one synthetic service, owned by one worker
+type service struct {
+	key      string
+	name     string
+	replicas int32
+}
+
+// worker makes the decisions of its share of the services
+type worker struct {
+	services  []*service
+	simulator *hydrametrics.Simulator
+	latencies []time.Duration
+
+	calls, decisions, changes, skipped, errors int
+	firstError                                 error
+}
+
+// Run makes Rounds decisions for every service. Each round advances the
+// simulated clock by the interval and the replicas of a service follow its
+// previous recommendation, as if every action had been applied. Rounds of
+// different services interleave across the workers, as the per-service
+// evaluation loops of the controller do.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Services < 1 || opts.Rounds < 1 || opts.Workers < 1 {
+		return nil, fmt.Errorf("services, rounds and workers must be at least 1")
+	}
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive")
+	}
+
+	ai := scaler.NewAIScaler(opts.Config)
+	traffic := curves(opts)
+	workers := make([]*worker, opts.Workers)
+	for i := range workers {
+		cfg := traffic
+		cfg.Seed = opts.Seed + int64(i) + 1
+		workers[i] = &worker{
+			simulator: hydrametrics.NewSimulator(cfg),
+			latencies: make([]time.Duration, 0, (opts.Services/opts.Workers+1)*opts.Rounds),
+		}
+	}
+	for i := 0; i < opts.Services; i++ {
+		name := serviceName(i)
+		w := workers[i%len(workers)]
+		w.services = append(w.services, &service{
+			key:      Namespace + "/" + name,
+			name:     name,
+			replicas: opts.Config.MinReplicas,
+		})
+	}
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	waitBefore := mutexWait()
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			w.run(ctx, ai, opts, start)
+		}(w)
+	}
+	wg.Wait()
+
+	duration := time.Since(start)
+	waitAfter := mutexWait()
+	runtime.ReadMemStats(&after)
+	allocated, mallocs, gcCycles := after.TotalAlloc-before.TotalAlloc, after.Mallocs-before.Mallocs, after.NumGC-before.NumGC
+	pauses := after.PauseTotalNs - before.PauseTotalNs
+	maxPause := maxGCPause(&before, &after)
+
+	// The live heap the services' state added, without the garbage
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(ai)
+
+	result := &Result{
+		Services:     opts.Services,
+		Workers:      opts.Workers,
+		Interrupted:  ctx.Err() != nil,
+		Duration:     duration,
+		MutexWait:    waitAfter - waitBefore,
+		GCCycles:     gcCycles,
+		GCPauseTotal: time.Duration(pauses),
+		GCPauseMax:   maxPause,
+		HeapGrowth:   int64(after.HeapAlloc) - int64(before.HeapAlloc),
+	}
+
+	var latencies []time.Duration
+	for _, w := range workers {
+		result.Calls += w.calls
+		result.Decisions += w.decisions
+		result.Changes += w.changes
+		result.Skipped += w.skipped
+		result.Errors += w.errors
+		if result.FirstError == nil {
+			result.FirstError = w.firstError
+		}
+		latencies = append(latencies, w.latencies...)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	if n := len(latencies); n > 0 {
+		result.LatencyP50 = latencies[n/2]
+		result.LatencyP99 = latencies[n*99/100]
+		result.LatencyMax = latencies[n-1]
+	}
+	if result.Calls > 0 {
+		result.CallsPerSecond = float64(result.Calls) / duration.Seconds()
+		result.BytesPerCall = float64(allocated) / float64(result.Calls)
+		result.AllocsPerCall = float64(mallocs) / float64(result.Calls)
+	}
+	result.MutexWaitShare = result.MutexWait.Seconds() / (duration.Seconds() * float64(opts.Workers))
+	result.HeapPerService = float64(result.HeapGrowth) / float64(opts.Services)
+	return result, nil
+}
+
+// run decides on the worker's services round by round until done or the
+// context is cancelled
+func (w *worker) run(ctx context.Context, ai *scaler.AIScaler, opts Options, start time.Time) {
+	for round := 0; round < opts.Rounds; round++ {
+		now := start.Add(time.Duration(round) * opts.Interval)
+		for _, svc := range w.services {
+			if ctx.Err() != nil {
+				return
+			}
+			sample := &hydrametrics.MetricsData{
+				Timestamp:       now,
+				ServiceName:     svc.name,
+				Namespace:       Namespace,
+				CurrentReplicas: svc.replicas,
+				DesiredReplicas: svc.replicas,
+			}
+			w.simulator.Fill(svc.key, sample)
+
+			began := time.Now()
+			decision, err := ai.MakeScalingDecision(sample)
+			w.latencies = append(w.latencies, time.Since(began))
+			w.calls++
+
+			switch {
+			case err != nil:
+				w.errors++
+				if w.firstError == nil {
+					w.firstError = fmt.Errorf("%s: %w", svc.key, err)
+				}
+			case decision == nil:
+				w.skipped++
+			default:
+				w.decisions++
+				if decision.RecommendedReplicas != svc.replicas {
+					w.changes++
+					svc.replicas = decision.RecommendedReplicas
+				}
+			}
+		}
+	}
+}
+
+// curves gives every service a traffic curve drawn from the seed: a shape,
+// a base rate between 10 and 500 requests per second, an amplitude of up
+// to three times the base and a period between 10 minutes and 2 hours.
+// CPU is simulated at 20 requests per second per replica.
+func curves(opts Options) config.SimulatedConfig {
+	random := rand.New(rand.NewSource(opts.Seed))
+	cfg := config.SimulatedConfig{
+		Default: config.TrafficCurveConfig{
+			Noise:              0.1,
+			ResponseTime:       50,
+			ErrorRate:          0.5,
+			RequestsPerReplica: 20,
+			MemoryUtilization:  40,
+		},
+		Services: make(map[string]config.TrafficCurveConfig, opts.Services),
+	}
+	for i := 0; i < opts.Services; i++ {
+		base := 10 + random.Float64()*490
+		cfg.Services[Namespace+"/"+serviceName(i)] = config.TrafficCurveConfig{
+			Shape:     shapes[i%len(shapes)],
+			Base:      base,
+			Amplitude: base * 3 * random.Float64(),
+			Period:    10*time.Minute + time.Duration(random.Int63n(int64(110*time.Minute))),
+		}
+	}
+	return cfg
+}
+
+func serviceName(i int) string {
+	return fmt.Sprintf("service-%05d", i)
+}
+
+// mutexWait reads the cumulative time goroutines spent blocked on mutexes
+func mutexWait() time.Duration {
+	sample := []metrics.Sample{{Name: mutexWaitMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindFloat64 {
+		return 0
+	}
+	return time.Duration(sample[0].Value.Float64() * float64(time.Second))
+}
+
+// maxGCPause returns the longest pause of the collections between two
+// MemStats readings, from the ring buffer of the last 256 pauses
+func maxGCPause(before, after *runtime.MemStats) time.Duration {
+	size := uint32(len(after.PauseNs))
+	first := before.NumGC
+	if after.NumGC-first > size {
+		first = after.NumGC - size
+	}
+
+	var longest uint64
+	for cycle := first; cycle < after.NumGC; cycle++ {
+		if pause := after.PauseNs[cycle%size]; pause > longest {
+			longest = pause
+		}
+	}
+	return time.Duration(longest)
+}
@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Endpoints whose failures are backed off. The LLM, latency SLO and
+// throttling sources query the Prometheus endpoint too, so they share its
+// circuit.
+const (
+	endpointNginx      = "nginx"
+	endpointPrometheus = "prometheus"
+)
+
+// sourceEndpoint returns the external endpoint a source scrapes, or "" for
+// sources served by the Kubernetes API
+func sourceEndpoint(source string) string {
+	switch source {
+	case SourceNginx:
+		return endpointNginx
+	case SourcePrometheus, SourceLLM, SourceLatencySLO, SourceThrottling, SourceMesh, SourceRetries:
+		return endpointPrometheus
+	}
+	return ""
+}
+
+// circuit tracks the failures of one endpoint. It is closed while scrapes
+// go through and open while they are skipped; once the backoff has passed,
+// the next scrape is let through as a recovery probe that closes the circuit
+// on success and doubles the backoff on failure.
+type circuit struct {
+	failures int
+	open     bool
+	backoff  time.Duration
+	retryAt  time.Time
+}
+
+// breakers holds the circuits of the external endpoints
+type breakers struct {
+	config config.SourceBackoffConfig
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	// Source of the jitter, seeded from the clock unless set with seed
+	random *rand.Rand
+}
+
+func newBreakers(cfg config.SourceBackoffConfig) *breakers {
+	return &breakers{
+		config:   cfg,
+		circuits: make(map[string]*circuit),
+		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// seed reseeds the jitter source, so retry timing repeats between runs
+func (b *breakers) seed(seed int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.random = rand.New(rand.NewSource(seed))
+}
+
+// allow reports whether a scrape of the source should be made now
+func (b *breakers) allow(source string, now time.Time) bool {
+	endpoint := sourceEndpoint(source)
+	if !b.config.Enabled || endpoint == "" {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[endpoint]
+	if c == nil || !c.open {
+		return true
+	}
+	if now.Before(c.retryAt) {
+		sourceSkipped.WithLabelValues(endpoint).Inc()
+		return false
+	}
+	logrus.WithField("endpoint", endpoint).Debug("Probing metrics endpoint")
+	return true
+}
+
+// backingOff reports whether the circuit of the source's endpoint is open
+// and no recovery probe is due yet
+func (b *breakers) backingOff(source string, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[sourceEndpoint(source)]
+	return c != nil && c.open && now.Before(c.retryAt)
+}
+
+// record updates the circuit of the source's endpoint with a scrape outcome
+func (b *breakers) record(source string, err error, now time.Time) {
+	endpoint := sourceEndpoint(source)
+	if !b.config.Enabled || endpoint == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[endpoint]
+	if c == nil {
+		c = &circuit{}
+		b.circuits[endpoint] = c
+	}
+	log := logrus.WithField("endpoint", endpoint)
+
+	switch {
+	case err == nil:
+		if c.open {
+			log.Info("Metrics endpoint recovered, closing circuit")
+		}
+		*c = circuit{}
+	case c.open:
+		// A failed recovery probe
+		c.backoff *= 2
+		if c.backoff > b.config.MaxBackoff {
+			c.backoff = b.config.MaxBackoff
+		}
+		c.retryAt = now.Add(b.jittered(c.backoff))
+		log.WithError(err).WithField("retry_at", c.retryAt).Debug("Recovery probe failed")
+	default:
+		c.failures++
+		if c.failures >= b.config.FailureThreshold {
+			c.open = true
+			c.backoff = b.config.InitialBackoff
+			c.retryAt = now.Add(b.jittered(c.backoff))
+			log.WithError(err).WithFields(logrus.Fields{
+				"failures": c.failures,
+				"retry_at": c.retryAt,
+			}).Warn("Metrics endpoint failing, opening circuit")
+		}
+	}
+
+	circuitOpen.WithLabelValues(endpoint).Set(boolToFloat(c.open))
+	consecutiveFailures.WithLabelValues(endpoint).Set(float64(c.failures))
+	backoffSeconds.WithLabelValues(endpoint).Set(c.backoff.Seconds())
+}
+
+// jittered moves a wait by a random share of up to the jitter either way.
+// It is called with mu held.
+func (b *breakers) jittered(wait time.Duration) time.Duration {
+	return time.Duration(float64(wait) * (1 + b.config.Jitter*(2*b.random.Float64()-1)))
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
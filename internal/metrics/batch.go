@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// batchLoadByNode returns the CPU cores requested by the active Job pods on
+// each node, CronJob runs included
+func (c *Collector) batchLoadByNode(ctx context.Context) (map[string]float64, error) {
+	podList := &v1.PodList{}
+	if err := c.client.List(ctx, podList); err != nil {
+		return nil, err
+	}
+
+	load := make(map[string]float64)
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == "" || !isJobPod(pod) {
+			continue
+		}
+		if pod.Status.Phase != v1.PodPending && pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			if cpu, ok := container.Resources.Requests[v1.ResourceCPU]; ok {
+				load[pod.Spec.NodeName] += float64(cpu.MilliValue()) / 1000.0
+			}
+		}
+	}
+	return load, nil
+}
+
+// isJobPod reports whether a pod is run by a Job
+func isJobPod(pod v1.Pod) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "Job" {
+			return true
+		}
+	}
+	return false
+}
+
+// detectBatchContention flags a sample taken while pods of the service share
+// nodes with Job pods requesting at least the configured CPU
+func (c *Collector) detectBatchContention(ctx context.Context, service v1.Service, sample *MetricsData, load map[string]float64) error {
+	pods, err := c.getServicePods(ctx, service)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" && load[pod.Spec.NodeName] >= c.config.BatchContention.MinCPU {
+			sample.BatchContentionPods++
+		}
+	}
+	sample.BatchContention = sample.BatchContentionPods > 0
+	return nil
+}
@@ -0,0 +1,189 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// bootstrap fills the history of a service seen for the first time from
+// Prometheus range queries over the bootstrap window, so window aggregates,
+// trends and training data do not have to accumulate in memory first. It
+// runs at most once per service, whatever the outcome.
+func (c *Collector) bootstrap(ctx context.Context, service v1.Service) {
+	key := fmt.Sprintf("%s/%s", service.Namespace, service.Name)
+
+	// Try again once Prometheus has recovered
+	if c.breakers.backingOff(SourcePrometheus, time.Now()) {
+		return
+	}
+
+	c.mu.Lock()
+	if c.bootstrapped[key] || len(c.metricsStore[key]) > 0 {
+		c.mu.Unlock()
+		return
+	}
+	c.bootstrapped[key] = true
+	c.mu.Unlock()
+
+	end := time.Now()
+	start := end.Add(-c.config.Bootstrap.Window)
+	history, err := c.queryHistory(ctx, service, start, end, c.config.CollectionInterval)
+	if err != nil {
+		logrus.WithError(err).WithField("service", key).Warn("Failed to bootstrap metrics history")
+		return
+	}
+	if len(history) == 0 {
+		logrus.WithField("service", key).Debug("No metrics history to bootstrap")
+		return
+	}
+
+	cutoff := end.Add(-c.config.RetentionPeriod)
+	var retained []*MetricsData
+	for i, sample := range history {
+		if len(c.config.AggregationWindows) > 0 {
+			sample.Windows = windowAggregates(c.config.AggregationWindows, history[:i], sample)
+		}
+		if sample.Timestamp.After(cutoff) {
+			retained = append(retained, sample)
+		}
+	}
+
+	c.mu.Lock()
+	if len(c.metricsStore[key]) == 0 {
+		c.metricsStore[key] = retained
+	}
+	c.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"service": key,
+		"samples": len(history),
+		"from":    history[0].Timestamp,
+	}).Info("Bootstrapped metrics history")
+
+	if c.OnBootstrap != nil {
+		c.OnBootstrap(key, history)
+	}
+}
+
+// queryHistory rebuilds the samples of a service between start and end, one
+// per step, from the recording rules generated by
+// "hydra-route recording-rules". Replica counts are the number of pods of the
+// service's deployments with recorded CPU usage; utilization is usage
+// over the resource requests of the current pod templates. Revisions and
+// images are not known for past samples and are left empty.
+func (c *Collector) queryHistory(ctx context.Context, service v1.Service, start, end time.Time, step time.Duration) ([]*MetricsData, error) {
+	samples := make(map[model.Time]*MetricsData)
+	at := func(t model.Time) *MetricsData {
+		sample, ok := samples[t]
+		if !ok {
+			sample = &MetricsData{
+				Timestamp:   t.Time(),
+				ServiceName: service.Name,
+				Namespace:   service.Namespace,
+			}
+			samples[t] = sample
+		}
+		return sample
+	}
+	rangeValues := func(query string, apply func(t model.Time, value float64)) error {
+		matrix, err := c.prometheus.QueryRange(ctx, query, start, end, step)
+		if err != nil {
+			return err
+		}
+		for _, stream := range matrix {
+			for _, pair := range stream.Values {
+				apply(pair.Timestamp, float64(pair.Value))
+			}
+		}
+		return nil
+	}
+
+	selector := serviceSelector(service.Name, service.Namespace)
+	queries := []struct {
+		query string
+		apply func(t model.Time, value float64)
+	}{
+		{RecordRequestRate + selector, func(t model.Time, v float64) { at(t).RequestRate += v }},
+		{RecordErrorRatio + selector, func(t model.Time, v float64) { at(t).ErrorRate += v * 100 }},
+		{fmt.Sprintf("%s{namespace=%q, service=%q, quantile=%q}", RecordLatencyQuantile, service.Namespace, service.Name, responseTimeQuantile),
+			func(t model.Time, v float64) { at(t).ResponseTime += v * 1000 }},
+	}
+	for _, q := range queries {
+		if err := rangeValues(q.query, q.apply); err != nil {
+			return nil, err
+		}
+	}
+
+	deployments, err := c.getServiceDeployments(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+
+	cpuUsage := make(map[model.Time]float64)
+	cpuRequests := make(map[model.Time]float64)
+	memoryUsage := make(map[model.Time]float64)
+	memoryRequests := make(map[model.Time]float64)
+	for _, deployment := range deployments {
+		pods := fmt.Sprintf("{namespace=%q, pod=~%q}", service.Namespace, deploymentPodPattern(deployment))
+		cpuRequest, memoryRequest := podRequests(deployment)
+
+		if err := rangeValues("count("+RecordCPUUsage+pods+")", func(t model.Time, v float64) {
+			cpuRequests[t] += v * cpuRequest
+			memoryRequests[t] += v * memoryRequest
+			at(t).CurrentReplicas += int32(v)
+			at(t).DesiredReplicas += int32(v)
+		}); err != nil {
+			return nil, err
+		}
+		if err := rangeValues("sum("+RecordCPUUsage+pods+")", func(t model.Time, v float64) { cpuUsage[t] += v }); err != nil {
+			return nil, err
+		}
+		if err := rangeValues("sum("+RecordMemoryUsage+pods+")", func(t model.Time, v float64) { memoryUsage[t] += v }); err != nil {
+			return nil, err
+		}
+	}
+	for t, requests := range cpuRequests {
+		if requests > 0 {
+			at(t).CPUUtilization = cpuUsage[t] / requests * 100
+		}
+	}
+	for t, requests := range memoryRequests {
+		if requests > 0 {
+			at(t).MemoryUtilization = memoryUsage[t] / requests * 100
+		}
+	}
+
+	history := make([]*MetricsData, 0, len(samples))
+	for _, sample := range samples {
+		history = append(history, sample)
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Timestamp.Before(history[j].Timestamp) })
+	return history, nil
+}
+
+// deploymentPodPattern matches the names of the pods a deployment creates,
+// <deployment>-<pod-template-hash>-<suffix>
+func deploymentPodPattern(deployment *appsv1.Deployment) string {
+	return regexp.QuoteMeta(deployment.Name) + "-[a-z0-9]+-[a-z0-9]+"
+}
+
+// podRequests returns the CPU (cores) and memory (bytes) requested by one pod
+// of the deployment's current template
+func podRequests(deployment *appsv1.Deployment) (float64, float64) {
+	var cpu, memory float64
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if requests := container.Resources.Requests; requests != nil {
+			cpu += float64(requests.Cpu().MilliValue()) / 1000.0
+			memory += float64(requests.Memory().Value())
+		}
+	}
+	return cpu, memory
+}
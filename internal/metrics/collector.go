@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -29,11 +34,30 @@ type MetricsData struct {
 	CPUUtilization    float64 `json:"cpu_utilization"`
 	MemoryUtilization float64 `json:"memory_utilization"`
 
+	// Memory working set as a percentage of the memory limits
+	MemoryLimitUtilization float64 `json:"memory_limit_utilization,omitempty"`
+
+	// OOM kills of the service's containers within metrics.oom_window
+	OOMKills int `json:"oom_kills,omitempty"`
+
 	// Request metrics
 	RequestRate  float64 `json:"request_rate"`
 	ResponseTime float64 `json:"response_time"`
 	ErrorRate    float64 `json:"error_rate"`
 
+	// Requests in flight across all replicas
+	Concurrency float64 `json:"concurrency,omitempty"`
+
+	// LLM serving metrics: generated tokens per second across all replicas
+	// and the 95th percentile time to first token in milliseconds
+	TokenRate        float64 `json:"token_rate,omitempty"`
+	TimeToFirstToken float64 `json:"time_to_first_token,omitempty"`
+
+	// Rates at which the latency SLO error budget burns over the last 5
+	// minutes and hour; 1 spends exactly the budget over the SLO period
+	LatencyBurnRate5m float64 `json:"latency_burn_rate_5m,omitempty"`
+	LatencyBurnRate1h float64 `json:"latency_burn_rate_1h,omitempty"`
+
 	// Bandwidth metrics
 	NetworkBandwidth float64 `json:"network_bandwidth"`
 	IOBandwidth      float64 `json:"io_bandwidth"`
@@ -42,9 +66,77 @@ type MetricsData struct {
 	CurrentReplicas int32 `json:"current_replicas"`
 	DesiredReplicas int32 `json:"desired_replicas"`
 
+	// Deployment revision and container images serving the sample
+	Revision string `json:"revision,omitempty"`
+	Image    string `json:"image,omitempty"`
+
+	// CPU cores and memory MB requested per replica, and the node type most
+	// of the service's pods run on, for cost estimates
+	CPURequests    float64 `json:"cpu_requests,omitempty"`
+	MemoryRequests float64 `json:"memory_requests,omitempty"`
+	NodeType       string  `json:"node_type,omitempty"`
+
+	// Set while pods of the service run on cordoned or draining nodes or are being evicted
+	Maintenance     bool `json:"maintenance,omitempty"`
+	MaintenancePods int  `json:"maintenance_pods,omitempty"`
+
+	// Set while pods of the service are crash-looping
+	CrashLoop     bool `json:"crash_loop,omitempty"`
+	CrashLoopPods int  `json:"crash_loop_pods,omitempty"`
+
+	// Percentage of CFS periods in which the service's containers were
+	// throttled, and the CPU utilization measured before correcting for it
+	CPUThrottling          float64 `json:"cpu_throttling,omitempty"`
+	MeasuredCPUUtilization float64 `json:"measured_cpu_utilization,omitempty"`
+
+	// Set while pods of the service share nodes with large batch jobs
+	BatchContention     bool `json:"batch_contention,omitempty"`
+	BatchContentionPods int  `json:"batch_contention_pods,omitempty"`
+
+	// Zones the pods of the service spread over by their topology spread
+	// constraint; 0 when the service isn't spread
+	Zones int `json:"zones,omitempty"`
+
+	// Pods of the service the scheduler is evicting for higher-priority
+	// pods, and pending pods it can't place; both count towards the
+	// replicas without serving
+	PreemptedPods     int `json:"preempted_pods,omitempty"`
+	UnschedulablePods int `json:"unschedulable_pods,omitempty"`
+
+	// Retried requests per second, the ratio of requests to first attempts
+	// when the sample counts as a retry storm, and the request rate measured
+	// before discounting the retries
+	RetryRate           float64 `json:"retry_rate,omitempty"`
+	RetryAmplification  float64 `json:"retry_amplification,omitempty"`
+	MeasuredRequestRate float64 `json:"measured_request_rate,omitempty"`
+
+	// Endpoints of the service that service mesh outlier detection has
+	// ejected from load balancing
+	EjectedEndpoints int `json:"ejected_endpoints,omitempty"`
+
+	// Temperature in degrees Celsius of the hottest node and largest power
+	// draw in watts among the nodes running the service's pods, and the pods
+	// on nodes at or above the thermal limits
+	NodeTemperature        float64 `json:"node_temperature,omitempty"`
+	NodePower              float64 `json:"node_power,omitempty"`
+	ThermalConstrainedPods int     `json:"thermal_constrained_pods,omitempty"`
+
+	// Pods of the service running on Windows nodes, for which CPU
+	// throttling and I/O bandwidth aren't measured
+	WindowsPods int `json:"windows_pods,omitempty"`
+
 	// Additional context
 	IngressClass   string `json:"ingress_class"`
 	LoadBalancerIP string `json:"load_balancer_ip"`
+
+	// Seconds since the oldest failing source last succeeded for this service
+	StalenessSeconds float64 `json:"staleness_seconds,omitempty"`
+
+	// Metrics backfilled from earlier samples because their source failed
+	Imputed []string `json:"imputed,omitempty"`
+
+	// Aggregates over the configured windows, shortest first
+	Windows []WindowAggregate `json:"windows,omitempty"`
 }
 
 // NginxMetrics represents nginx ingress controller metrics
@@ -55,6 +147,9 @@ type NginxMetrics struct {
 	ActiveConnections int64              `json:"active_connections"`
 	BytesPerSecond    float64            `json:"bytes_per_second"`
 	UpstreamMetrics   map[string]float64 `json:"upstream_metrics"`
+
+	// Active connections per upstream, the requests in flight to its backends
+	UpstreamActiveConnections map[string]float64 `json:"upstream_active_connections"`
 }
 
 // SystemMetrics represents system-level metrics
@@ -79,66 +174,201 @@ type Collector struct {
 	mu           sync.RWMutex
 	metricsStore map[string][]*MetricsData
 
-	// HTTP client for external metrics
-	httpClient *http.Client
+	// Services (namespace/name) registered for collection, guarded by mu
+	targets map[string]bool
+
+	// Registered services no ingress routes to, guarded by mu
+	withoutIngress map[string]bool
+
+	// Registered services quarantined by the controller, left out of
+	// collection, guarded by mu
+	quarantined map[string]bool
+
+	// HTTP client of the nginx source
+	nginxClient *http.Client
+
+	// Client for the recording rules in Prometheus, nil when not configured
+	prometheus *PrometheusClient
+
+	// Synthetic traffic generator, nil unless the simulated source is enabled
+	simulator *Simulator
 
-	// Collection state
-	isRunning bool
-	stopCh    chan struct{}
+	// Circuits of the nginx and Prometheus endpoints
+	breakers *breakers
+
+	// Requests for a collection cycle ahead of the next tick
+	triggerCh chan struct{}
+
+	// Set once a collection cycle has completed successfully
+	ready atomic.Bool
+
+	// Factor the collection interval is stretched by, in thousandths; 0
+	// and 1000 collect at the configured interval
+	slowdown atomic.Int64
+
+	// Last successful scrape per service and source, used for staleness
+	startedAt     time.Time
+	sourceSuccess map[string]map[string]time.Time
+
+	// Services whose history bootstrap has been attempted
+	bootstrapped map[string]bool
+
+	// CPU requested by Job pods per node in the current cycle, nil when unknown
+	batchLoad map[string]float64
+
+	// Temperature and power draw per node in the last cycle, guarded by mu
+	nodeThermal map[string]NodeThermal
+
+	// Operating system per node name, and the measurements noted as
+	// unavailable on Windows nodes per service
+	osMu        sync.Mutex
+	nodeOS      map[string]string
+	unsupported map[string]map[string]bool
+
+	// Finish times of the OOM kills seen per service within the window,
+	// keyed by pod, container and finish time
+	oomKills map[string]map[string]time.Time
+
+	// OnBootstrap, when set, receives the history backfilled for a service
+	// seen for the first time, oldest sample first
+	OnBootstrap func(key string, history []*MetricsData)
+
+	// ArgoRollouts counts the replicas of the Argo Rollouts a service
+	// selects, in place of the deployments they reference
+	ArgoRollouts bool
 }
 
 // NewCollector creates a new metrics collector
 func NewCollector(client client.Client, cfg config.MetricsConfig) *Collector {
-	return &Collector{
-		client:       client,
-		config:       cfg,
-		metricsStore: make(map[string][]*MetricsData),
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		stopCh: make(chan struct{}),
+	c := &Collector{
+		client:        client,
+		config:        cfg,
+		metricsStore:  make(map[string][]*MetricsData),
+		targets:       make(map[string]bool),
+		startedAt:     time.Now(),
+		sourceSuccess: make(map[string]map[string]time.Time),
+		bootstrapped:  make(map[string]bool),
+		oomKills:      make(map[string]map[string]time.Time),
+		nodeOS:        make(map[string]string),
+		unsupported:   make(map[string]map[string]bool),
+		nginxClient:   newSourceClient(cfg, SourceNginx),
+		triggerCh:     make(chan struct{}, 1),
+		breakers:      newBreakers(cfg.SourceBackoff),
+	}
+	if cfg.PrometheusURL != "" {
+		c.prometheus = NewPrometheusClient(cfg, newSourceClient(cfg, SourcePrometheus))
 	}
+	if cfg.Simulated.Enabled {
+		c.simulator = NewSimulator(cfg.Simulated)
+	}
+	return c
 }
 
-// Start begins metrics collection
+// Start collects metrics every collection interval until the context is
+// done. It runs as a manager runnable.
 func (c *Collector) Start(ctx context.Context) error {
-	if c.isRunning {
-		return fmt.Errorf("collector is already running")
-	}
-
-	c.isRunning = true
 	logrus.Info("Starting metrics collector")
 
 	// Start collection ticker
-	ticker := time.NewTicker(c.config.CollectionInterval)
+	interval := c.Interval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	// Initial collection
 	if err := c.collectMetrics(ctx); err != nil {
 		logrus.WithError(err).Error("Initial metrics collection failed")
+	} else {
+		c.markReady()
 	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			logrus.Info("Stopping metrics collector due to context cancellation")
-			return ctx.Err()
-		case <-c.stopCh:
 			logrus.Info("Stopping metrics collector")
 			return nil
 		case <-ticker.C:
-			if err := c.collectMetrics(ctx); err != nil {
-				logrus.WithError(err).Error("Metrics collection failed")
-			}
+		case <-c.triggerCh:
+			logrus.Debug("Collection cycle triggered")
+		}
+
+		if err := c.collectMetrics(ctx); err != nil {
+			logrus.WithError(err).Error("Metrics collection failed")
+		} else {
+			c.markReady()
+		}
+
+		if next := c.Interval(); next != interval {
+			interval = next
+			ticker.Reset(interval)
+			logrus.WithField("interval", interval).Info("Collection interval changed")
 		}
 	}
 }
 
-// Stop stops the metrics collector
-func (c *Collector) Stop() {
-	if c.isRunning {
-		close(c.stopCh)
-		c.isRunning = false
+// SetSeed seeds the jitter of the source back-off. In deterministic mode
+// retry timing, and so the samples that reach a decision, repeat between runs.
+func (c *Collector) SetSeed(seed int64) {
+	c.breakers.seed(seed)
+}
+
+// SetSlowdown stretches the collection interval by factor from the next
+// cycle on, such as while the controller throttles itself; 1 restores it
+func (c *Collector) SetSlowdown(factor float64) {
+	c.slowdown.Store(int64(factor * 1000))
+}
+
+// Interval returns the collection interval, stretched by any slowdown
+func (c *Collector) Interval() time.Duration {
+	interval := c.config.CollectionInterval
+	if slowdown := c.slowdown.Load(); slowdown > 1000 {
+		interval = interval * time.Duration(slowdown) / 1000
+	}
+	return interval
+}
+
+// Trigger asks the running collector for a collection cycle now rather than
+// at the next tick. Requests made while one is pending are merged.
+func (c *Collector) Trigger() {
+	select {
+	case c.triggerCh <- struct{}{}:
+	default:
+	}
+}
+
+// CollectOnce runs a single collection cycle, for callers that drive
+// collection themselves instead of calling Start
+func (c *Collector) CollectOnce(ctx context.Context) error {
+	if err := c.collectMetrics(ctx); err != nil {
+		return err
+	}
+	c.markReady()
+	return nil
+}
+
+// NeedLeaderElection makes every replica collect, so each passes its
+// readiness check and a new leader finds the metrics store warm. Replicas
+// that don't lead have no registered services and collect nothing.
+func (c *Collector) NeedLeaderElection() bool {
+	return false
+}
+
+// Ready reports whether at least one collection cycle has completed successfully
+func (c *Collector) Ready() bool {
+	return c.ready.Load()
+}
+
+// ReadyCheck is a readiness probe check that fails until the first
+// successful collection cycle
+func (c *Collector) ReadyCheck(_ *http.Request) error {
+	if !c.Ready() {
+		return fmt.Errorf("no metrics collection cycle has completed yet")
+	}
+	return nil
+}
+
+func (c *Collector) markReady() {
+	if !c.ready.Swap(true) {
+		logrus.Info("First metrics collection cycle completed")
 	}
 }
 
@@ -164,15 +394,43 @@ func (c *Collector) GetLatestMetrics(serviceName, namespace string) *MetricsData
 func (c *Collector) collectMetrics(ctx context.Context) error {
 	logrus.Debug("Starting metrics collection cycle")
 
-	// Get all services with ingress annotations
-	services, err := c.getIngressServices(ctx)
+	// Get the registered services
+	start := time.Now()
+	services, err := c.getTargetServices(ctx)
+	observeScrape(SourceKubernetes, start, err)
 	if err != nil {
-		return fmt.Errorf("failed to get ingress services: %w", err)
+		return fmt.Errorf("failed to get target services: %w", err)
+	}
+
+	// Batch load is per node, so it is read once for all services
+	c.batchLoad = nil
+	if c.config.BatchContention.Enabled {
+		if c.batchLoad, err = c.batchLoadByNode(ctx); err != nil {
+			logrus.WithError(err).Debug("Failed to read batch job load")
+		}
+	}
+
+	// Node temperatures are also read once, and kept for placement hints
+	var nodeThermal map[string]NodeThermal
+	if c.prometheus != nil && c.config.Thermal.Enabled {
+		start := time.Now()
+		nodeThermal, err = c.readNodeThermal(ctx)
+		observeScrape(SourceThermal, start, err)
+		if err != nil {
+			logrus.WithError(err).Debug("Failed to read node temperatures")
+		}
+		c.mu.Lock()
+		c.nodeThermal = nodeThermal
+		c.mu.Unlock()
 	}
 
 	// Collect metrics for each service
 	for _, service := range services {
-		metrics, err := c.collectServiceMetrics(ctx, service)
+		if c.prometheus != nil && c.config.Bootstrap.Enabled {
+			c.bootstrap(ctx, service)
+		}
+
+		metrics, err := c.collectServiceMetrics(ctx, service, nodeThermal)
 		if err != nil {
 			logrus.WithError(err).WithFields(logrus.Fields{
 				"service":   service.Name,
@@ -191,73 +449,363 @@ func (c *Collector) collectMetrics(ctx context.Context) error {
 	return nil
 }
 
-// getIngressServices finds services that are exposed via ingress
-func (c *Collector) getIngressServices(ctx context.Context) ([]v1.Service, error) {
-	var services []v1.Service
-
-	// Get all services
-	serviceList := &v1.ServiceList{}
-	if err := c.client.List(ctx, serviceList); err != nil {
-		return nil, err
+// SetTargets replaces the services (namespace/name) metrics are collected
+// for. The controller registers the backend services of the enabled
+// ingresses and the services enabled directly; no other service is
+// collected. Services new to the set are collected right away rather than
+// at the next tick.
+func (c *Collector) SetTargets(keys []string) {
+	targets := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		targets[key] = true
 	}
 
-	// Filter services that have ingress
-	for _, service := range serviceList.Items {
-		// Check if service has ingress annotation or is referenced by ingress
-		if c.isServiceExposed(ctx, service) {
-			services = append(services, service)
+	c.mu.Lock()
+	added := false
+	for key := range targets {
+		if !c.targets[key] {
+			added = true
+			break
+		}
+	}
+	for key := range c.targets {
+		if !targets[key] {
+			namespace, name, _ := strings.Cut(key, "/")
+			sampleStaleness.DeleteLabelValues(name, namespace)
 		}
 	}
+	c.targets = targets
+	c.mu.Unlock()
+	collectionTargets.Set(float64(len(targets)))
 
-	return services, nil
+	if added {
+		c.Trigger()
+	}
 }
 
-// isServiceExposed checks if a service is exposed via ingress
-func (c *Collector) isServiceExposed(ctx context.Context, service v1.Service) bool {
-	// For now, we'll consider all services as potentially exposed
-	// In a real implementation, you'd check ingress resources
-	return true
+// SetWithoutIngress replaces the registered services (namespace/name) no
+// ingress routes to. The nginx totals don't include their traffic, so their
+// request metrics only come from Prometheus.
+func (c *Collector) SetWithoutIngress(keys []string) {
+	withoutIngress := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		withoutIngress[key] = true
+	}
+
+	c.mu.Lock()
+	c.withoutIngress = withoutIngress
+	c.mu.Unlock()
+}
+
+// SetQuarantined replaces the registered services (namespace/name) left
+// out of collection while the controller has them quarantined
+func (c *Collector) SetQuarantined(keys []string) {
+	quarantined := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		quarantined[key] = true
+	}
+
+	c.mu.Lock()
+	c.quarantined = quarantined
+	c.mu.Unlock()
+}
+
+// Targets returns the registered services in sorted order
+func (c *Collector) Targets() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.targets))
+	for key := range c.targets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getTargetServices fetches the registered services, skipping those that
+// don't exist (yet) and those quarantined
+func (c *Collector) getTargetServices(ctx context.Context) ([]v1.Service, error) {
+	c.mu.RLock()
+	quarantined := c.quarantined
+	c.mu.RUnlock()
+
+	var services []v1.Service
+	for _, key := range c.Targets() {
+		if quarantined[key] {
+			continue
+		}
+		namespace, name, _ := strings.Cut(key, "/")
+		service := v1.Service{}
+		if err := c.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &service); err != nil {
+			if apierrors.IsNotFound(err) {
+				logrus.WithField("service", key).Debug("Registered service not found")
+				continue
+			}
+			return nil, err
+		}
+		services = append(services, service)
+	}
+
+	return services, nil
 }
 
 // collectServiceMetrics collects all metrics for a specific service
-func (c *Collector) collectServiceMetrics(ctx context.Context, service v1.Service) (*MetricsData, error) {
+func (c *Collector) collectServiceMetrics(ctx context.Context, service v1.Service, nodeThermal map[string]NodeThermal) (*MetricsData, error) {
 	metrics := &MetricsData{
 		Timestamp:   time.Now(),
 		ServiceName: service.Name,
 		Namespace:   service.Namespace,
 	}
 
+	key := fmt.Sprintf("%s/%s", service.Namespace, service.Name)
+	var failed []string
+
 	// Collect resource utilization metrics
-	if err := c.collectResourceMetrics(ctx, service, metrics); err != nil {
-		logrus.WithError(err).Debug("Failed to collect resource metrics")
+	if c.simulator == nil || !c.simulator.simulatesResources(key) {
+		if !c.scrape(key, SourceResource, func() error {
+			return c.collectResourceMetrics(ctx, service, metrics)
+		}) {
+			failed = append(failed, SourceResource)
+		}
+	}
+
+	// Correct CPU utilization capped by throttling
+	if c.prometheus != nil && c.config.CPUThrottling.Enabled {
+		if !c.scrape(key, SourceThrottling, func() error {
+			return c.collectThrottling(ctx, service, metrics)
+		}) {
+			failed = append(failed, SourceThrottling)
+		}
+	}
+
+	// Collect request metrics
+	if c.simulator == nil {
+		failed = append(failed, c.collectRequestMetrics(ctx, key, service, metrics)...)
+	}
+
+	// Collect LLM serving metrics
+	if c.prometheus != nil && c.config.LLM.Enabled {
+		if !c.scrape(key, SourceLLM, func() error {
+			return c.collectLLMMetrics(ctx, service, metrics)
+		}) {
+			failed = append(failed, SourceLLM)
+		}
+	}
+
+	// Collect latency SLO burn rates
+	if c.prometheus != nil && c.config.LatencySLO.Enabled {
+		if !c.scrape(key, SourceLatencySLO, func() error {
+			return c.collectLatencySLO(ctx, service, metrics)
+		}) {
+			failed = append(failed, SourceLatencySLO)
+		}
+	}
+
+	// Collect endpoints ejected by service mesh outlier detection
+	if c.prometheus != nil && c.config.MeshEjection.Enabled {
+		if !c.scrape(key, SourceMesh, func() error {
+			return c.collectMeshEjection(ctx, service, metrics)
+		}) {
+			failed = append(failed, SourceMesh)
+		}
+	}
+
+	// Collect the temperature of the nodes running the service
+	if nodeThermal != nil {
+		if !c.scrape(key, SourceThermal, func() error {
+			return c.collectThermal(ctx, service, metrics, nodeThermal)
+		}) {
+			failed = append(failed, SourceThermal)
+		}
 	}
 
-	// Collect nginx metrics
-	if c.config.NginxMetricsURL != "" {
-		if err := c.collectNginxMetrics(ctx, service, metrics); err != nil {
-			logrus.WithError(err).Debug("Failed to collect nginx metrics")
+	// Collect retried requests
+	if c.prometheus != nil && c.config.RetryStorm.Enabled && c.config.RetryStorm.RetryMetric != "" {
+		if !c.scrape(key, SourceRetries, func() error {
+			return c.collectRetries(ctx, service, metrics)
+		}) {
+			failed = append(failed, SourceRetries)
 		}
 	}
 
 	// Collect system metrics
 	if c.config.BandwidthMonitoring.EnableNetworkBandwidth || c.config.BandwidthMonitoring.EnableIOBandwidth {
-		if err := c.collectSystemMetrics(ctx, service, metrics); err != nil {
-			logrus.WithError(err).Debug("Failed to collect system metrics")
+		if !c.scrape(key, SourceSystem, func() error {
+			return c.collectSystemMetrics(ctx, service, metrics)
+		}) {
+			failed = append(failed, SourceSystem)
 		}
 	}
 
 	// Collect deployment information
-	if err := c.collectDeploymentInfo(ctx, service, metrics); err != nil {
-		logrus.WithError(err).Debug("Failed to collect deployment info")
+	if !c.scrape(key, SourceDeployment, func() error {
+		return c.collectDeploymentInfo(ctx, service, metrics)
+	}) {
+		failed = append(failed, SourceDeployment)
+	}
+
+	// Generate synthetic traffic once the replica counts are known
+	if c.simulator != nil {
+		c.scrape(key, SourceSimulated, func() error {
+			c.simulator.Fill(key, metrics)
+			return nil
+		})
+	}
+
+	// Flag samples taken during node maintenance
+	if c.config.MaintenanceBlackout.Enabled {
+		if err := c.detectMaintenance(ctx, service, metrics); err != nil {
+			logrus.WithError(err).WithField("service", key).Debug("Failed to check node maintenance")
+		}
+	}
+
+	// Flag samples taken while pods crash-loop
+	if c.config.CrashLoop.Enabled {
+		if err := c.detectCrashLoop(ctx, service, metrics); err != nil {
+			logrus.WithError(err).WithField("service", key).Debug("Failed to check crash loops")
+		}
+	}
+
+	// Count recent OOM kills
+	if c.config.OOMWindow > 0 {
+		if err := c.detectOOMKills(ctx, key, service, metrics); err != nil {
+			logrus.WithError(err).WithField("service", key).Debug("Failed to check OOM kills")
+		}
+	}
+
+	// Flag samples taken while batch jobs contend for the same nodes
+	if c.batchLoad != nil {
+		if err := c.detectBatchContention(ctx, service, metrics, c.batchLoad); err != nil {
+			logrus.WithError(err).WithField("service", key).Debug("Failed to check batch contention")
+		}
+	}
+
+	// Count pods preempted by the scheduler or waiting for it
+	if c.config.Preemption.Enabled {
+		if err := c.detectPreemption(ctx, service, metrics); err != nil {
+			logrus.WithError(err).WithField("service", key).Debug("Failed to check preemption")
+		}
 	}
 
+	// Count the zones a spread service's replicas must cover
+	if c.config.ZoneSpread.Enabled {
+		if err := c.detectZones(ctx, service, metrics); err != nil {
+			logrus.WithError(err).WithField("service", key).Debug("Failed to count zones")
+		}
+	}
+
+	metrics.StalenessSeconds = c.staleness(key, failed, metrics.Timestamp)
+	c.impute(key, metrics, failed)
+	c.correctMaintenance(key, metrics)
+	c.correctRetryStorm(key, metrics)
+	c.aggregateWindows(key, metrics)
+	sampleStaleness.WithLabelValues(service.Name, service.Namespace).Set(metrics.StalenessSeconds)
+
 	return metrics, nil
 }
 
+// scrape runs a single source collection for a service, records its
+// self-metrics and reports whether it succeeded. Scrapes of an endpoint
+// whose circuit is open are skipped and count as failed.
+func (c *Collector) scrape(key, source string, collect func() error) bool {
+	start := time.Now()
+	if !c.breakers.allow(source, start) {
+		return false
+	}
+	err := collect()
+	observeScrape(source, start, err)
+	c.breakers.record(source, err, time.Now())
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"service": key,
+			"source":  source,
+		}).Debug("Failed to collect metrics")
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sourceSuccess[key] == nil {
+		c.sourceSuccess[key] = make(map[string]time.Time)
+	}
+	c.sourceSuccess[key][source] = time.Now()
+	return true
+}
+
+// staleness returns how long the oldest failing source has been without a
+// successful scrape for a service. Sources that never succeeded count from
+// collector start.
+func (c *Collector) staleness(key string, failed []string, now time.Time) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var worst time.Duration
+	for _, source := range failed {
+		since := c.startedAt
+		if last, ok := c.sourceSuccess[key][source]; ok {
+			since = last
+		}
+		if age := now.Sub(since); age > worst {
+			worst = age
+		}
+	}
+	return worst.Seconds()
+}
+
+// collectRequestMetrics fills the request rate, response time and error rate
+// from the request sources: the Prometheus recording rules when configured,
+// then the nginx ingress controller for services an ingress routes to.
+// Without merge policies the first source with data for the service is used;
+// with them every source is queried and the readings are merged field by
+// field. It returns the sources that
+// failed, or nil once one has succeeded.
+func (c *Collector) collectRequestMetrics(ctx context.Context, key string, service v1.Service, metrics *MetricsData) []string {
+	var failed []string
+	readings := make(map[string]*MetricsData)
+
+	if c.prometheus != nil {
+		reading := &MetricsData{Timestamp: metrics.Timestamp}
+		found := false
+		if c.scrape(key, SourcePrometheus, func() (err error) {
+			found, err = c.collectPrometheusMetrics(ctx, service, reading)
+			return err
+		}) {
+			if found {
+				readings[SourcePrometheus] = reading
+			}
+		} else {
+			failed = append(failed, SourcePrometheus)
+		}
+	}
+
+	c.mu.RLock()
+	withoutIngress := c.withoutIngress[key]
+	c.mu.RUnlock()
+
+	if c.config.NginxMetricsURL != "" && !withoutIngress && (len(readings) == 0 || c.mergesSources()) {
+		reading := &MetricsData{}
+		if c.scrape(key, SourceNginx, func() error {
+			return c.collectNginxMetrics(ctx, service, reading)
+		}) {
+			reading.Timestamp = time.Now()
+			readings[SourceNginx] = reading
+		} else {
+			failed = append(failed, SourceNginx)
+		}
+	}
+
+	if len(readings) == 0 {
+		return failed
+	}
+	c.mergeReadings(key, metrics, readings)
+	return nil
+}
+
 // collectResourceMetrics collects CPU and memory utilization
 func (c *Collector) collectResourceMetrics(ctx context.Context, service v1.Service, metrics *MetricsData) error {
-	// Get pods for the service
-	pods, err := c.getServicePods(ctx, service)
+	// Get the pods serving the service
+	pods, err := c.getBackendPods(ctx, service)
 	if err != nil {
 		return err
 	}
@@ -266,7 +814,23 @@ func (c *Collector) collectResourceMetrics(ctx context.Context, service v1.Servi
 		return nil
 	}
 
-	var totalCPU, totalMemory, totalCPURequests, totalMemoryRequests float64
+	var totalCPU, totalMemory, totalCPURequests, totalMemoryRequests, totalMemoryLimits float64
+
+	// Requests per replica cover every pod, including those without usage metrics
+	var podCPURequests, podMemoryRequests float64
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			podCPURequests += float64(container.Resources.Requests.Cpu().MilliValue()) / 1000.0
+			podMemoryRequests += float64(container.Resources.Requests.Memory().Value()) / (1024 * 1024)
+		}
+	}
+	metrics.CPURequests = podCPURequests / float64(len(pods))
+	metrics.MemoryRequests = podMemoryRequests / float64(len(pods))
+	_, windows := c.splitWindowsPods(ctx, pods)
+	metrics.WindowsPods = len(windows)
+	if c.config.NodeTypeLabel != "" {
+		metrics.NodeType = c.nodeType(ctx, pods)
+	}
 
 	// Aggregate metrics from all pods
 	for _, pod := range pods {
@@ -296,6 +860,9 @@ func (c *Collector) collectResourceMetrics(ctx context.Context, service v1.Servi
 					totalMemoryRequests += float64(memory.Value()) / (1024 * 1024)
 				}
 			}
+			if limit, ok := container.Resources.Limits[v1.ResourceMemory]; ok {
+				totalMemoryLimits += float64(limit.Value()) / (1024 * 1024)
+			}
 		}
 	}
 
@@ -306,16 +873,56 @@ func (c *Collector) collectResourceMetrics(ctx context.Context, service v1.Servi
 	if totalMemoryRequests > 0 {
 		metrics.MemoryUtilization = (totalMemory / totalMemoryRequests) * 100
 	}
+	if totalMemoryLimits > 0 {
+		metrics.MemoryLimitUtilization = (totalMemory / totalMemoryLimits) * 100
+	}
 
 	return nil
 }
 
+// nodeType returns the most common value of the node type label across the
+// nodes the pods run on, or "" when none carry it
+func (c *Collector) nodeType(ctx context.Context, pods []v1.Pod) string {
+	counts := make(map[string]int)
+	types := make(map[string]string)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		nodeType, seen := types[pod.Spec.NodeName]
+		if !seen {
+			node := &v1.Node{}
+			if err := c.client.Get(ctx, client.ObjectKey{Name: pod.Spec.NodeName}, node); err != nil {
+				logrus.WithError(err).WithField("node", pod.Spec.NodeName).Debug("Failed to read node type")
+			}
+			nodeType = node.Labels[c.config.NodeTypeLabel]
+			types[pod.Spec.NodeName] = nodeType
+		}
+		if nodeType != "" {
+			counts[nodeType]++
+		}
+	}
+
+	var common string
+	for nodeType, count := range counts {
+		if count > counts[common] || (count == counts[common] && nodeType < common) {
+			common = nodeType
+		}
+	}
+	return common
+}
+
 // collectNginxMetrics collects metrics from nginx ingress controller
 func (c *Collector) collectNginxMetrics(ctx context.Context, service v1.Service, metrics *MetricsData) error {
 	// Build metrics URL
-	url := fmt.Sprintf("%s/api/v1/nginx/stats", c.config.NginxMetricsURL)
+	url := fmt.Sprintf("%s/api/v1/nginx/stats", strings.TrimSuffix(c.config.NginxMetricsURL, "/"))
+
+	req, err := newSourceRequest(ctx, url, sourceHeaders(c.config, SourceNginx))
+	if err != nil {
+		return err
+	}
 
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.nginxClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -335,8 +942,16 @@ func (c *Collector) collectNginxMetrics(ctx context.Context, service v1.Service,
 		return err
 	}
 
-	// Map nginx metrics to our metrics structure
+	// Map nginx metrics to our metrics structure. Traffic reaching the service
+	// through any ingress is summed over its upstreams when reported.
 	metrics.RequestRate = nginxMetrics.RequestsPerSecond
+	if rate, ok := sumUpstreams(nginxMetrics.UpstreamMetrics, service); ok {
+		metrics.RequestRate = rate
+	}
+	// Active connections are only attributable to the service per upstream
+	if connections, ok := sumUpstreams(nginxMetrics.UpstreamActiveConnections, service); ok {
+		metrics.Concurrency = connections
+	}
 	metrics.ResponseTime = nginxMetrics.ResponseTime
 	metrics.ErrorRate = nginxMetrics.ErrorRate
 	metrics.NetworkBandwidth = nginxMetrics.BytesPerSecond / (1024 * 1024) // Convert to MB/s
@@ -344,6 +959,35 @@ func (c *Collector) collectNginxMetrics(ctx context.Context, service v1.Service,
 	return nil
 }
 
+// sumUpstreams sums a per-upstream value, such as the request rate, over the
+// nginx upstreams backed by a service. Upstreams are named <namespace>-<service>-<port> and each port a
+// service exposes through an ingress gets its own upstream, shared by every
+// ingress that routes to it.
+func sumUpstreams(upstreams map[string]float64, service v1.Service) (float64, bool) {
+	if len(upstreams) == 0 {
+		return 0, false
+	}
+
+	var total float64
+	found := false
+	counted := make(map[string]bool)
+	for _, port := range service.Spec.Ports {
+		for _, portRef := range []string{fmt.Sprintf("%d", port.Port), port.Name} {
+			if portRef == "" {
+				continue
+			}
+			name := fmt.Sprintf("%s-%s-%s", service.Namespace, service.Name, portRef)
+			if rate, ok := upstreams[name]; ok && !counted[name] {
+				total += rate
+				counted[name] = true
+				found = true
+			}
+		}
+	}
+
+	return total, found
+}
+
 // collectSystemMetrics collects system-level bandwidth metrics
 func (c *Collector) collectSystemMetrics(ctx context.Context, service v1.Service, metrics *MetricsData) error {
 	// This is a simplified implementation
@@ -354,9 +998,14 @@ func (c *Collector) collectSystemMetrics(ctx context.Context, service v1.Service
 		metrics.NetworkBandwidth = c.estimateNetworkBandwidth(service)
 	}
 
+	// Windows nodes don't report per-container disk I/O
 	if c.config.BandwidthMonitoring.EnableIOBandwidth {
-		// Simulate I/O bandwidth measurement
-		metrics.IOBandwidth = c.estimateIOBandwidth(service)
+		if metrics.WindowsPods > 0 {
+			c.noteUnsupported(fmt.Sprintf("%s/%s", service.Namespace, service.Name), "io_bandwidth")
+		} else {
+			// Simulate I/O bandwidth measurement
+			metrics.IOBandwidth = c.estimateIOBandwidth(service)
+		}
 	}
 
 	return nil
@@ -364,17 +1013,43 @@ func (c *Collector) collectSystemMetrics(ctx context.Context, service v1.Service
 
 // collectDeploymentInfo collects deployment replica information
 func (c *Collector) collectDeploymentInfo(ctx context.Context, service v1.Service, metrics *MetricsData) error {
+	// Rollouts stand in for the deployments of the service, whose pods they
+	// take over through spec.workloadRef
+	if c.ArgoRollouts {
+		rollouts, err := ServiceRollouts(ctx, c.client, &service)
+		if err != nil {
+			return err
+		}
+		if len(rollouts) > 0 {
+			for i, rollout := range rollouts {
+				desired, current := rollout.Replicas()
+				metrics.CurrentReplicas += current
+				metrics.DesiredReplicas += desired
+				if i == 0 {
+					metrics.Revision = rollout.GetAnnotations()[RolloutRevisionAnnotation]
+					metrics.Image = rollout.Images
+				}
+			}
+			return nil
+		}
+	}
+
 	// Get deployment for the service
 	deployments, err := c.getServiceDeployments(ctx, service)
 	if err != nil {
 		return err
 	}
 
-	if len(deployments) > 0 {
-		deployment := deployments[0] // Use first deployment
-		metrics.CurrentReplicas = deployment.Status.Replicas
+	// Replica counts cover every deployment the service selects, which the
+	// controller scales together; revision and images are the first's
+	for i, deployment := range deployments {
+		metrics.CurrentReplicas += deployment.Status.Replicas
 		if deployment.Spec.Replicas != nil {
-			metrics.DesiredReplicas = *deployment.Spec.Replicas
+			metrics.DesiredReplicas += *deployment.Spec.Replicas
+		}
+		if i == 0 {
+			metrics.Revision = deployment.Annotations[DeploymentRevisionAnnotation]
+			metrics.Image = deploymentImages(deployment)
 		}
 	}
 
@@ -383,9 +1058,17 @@ func (c *Collector) collectDeploymentInfo(ctx context.Context, service v1.Servic
 
 // Helper methods (simplified implementations)
 
+// getServicePods returns the pods selected by the service
 func (c *Collector) getServicePods(ctx context.Context, service v1.Service) ([]v1.Pod, error) {
-	// Implementation would get pods using service selector
-	return []v1.Pod{}, nil
+	if len(service.Spec.Selector) == 0 {
+		return nil, nil
+	}
+
+	podList := &v1.PodList{}
+	if err := c.client.List(ctx, podList, client.InNamespace(service.Namespace), client.MatchingLabels(service.Spec.Selector)); err != nil {
+		return nil, err
+	}
+	return podList.Items, nil
 }
 
 func (c *Collector) getPodMetrics(ctx context.Context, pod v1.Pod) (*metricsv1beta1.PodMetrics, error) {
@@ -393,9 +1076,38 @@ func (c *Collector) getPodMetrics(ctx context.Context, pod v1.Pod) (*metricsv1be
 	return &metricsv1beta1.PodMetrics{}, nil
 }
 
+// getServiceDeployments returns the deployments whose pods the service selects
 func (c *Collector) getServiceDeployments(ctx context.Context, service v1.Service) ([]*appsv1.Deployment, error) {
-	// Implementation would find deployments for the service
-	return []*appsv1.Deployment{}, nil
+	if len(service.Spec.Selector) == 0 {
+		return nil, nil
+	}
+
+	deploymentList := &appsv1.DeploymentList{}
+	if err := c.client.List(ctx, deploymentList, client.InNamespace(service.Namespace)); err != nil {
+		return nil, err
+	}
+
+	selector := labels.SelectorFromSet(service.Spec.Selector)
+	var deployments []*appsv1.Deployment
+	for i := range deploymentList.Items {
+		deployment := &deploymentList.Items[i]
+		if selector.Matches(labels.Set(deployment.Spec.Template.Labels)) {
+			deployments = append(deployments, deployment)
+		}
+	}
+	return deployments, nil
+}
+
+// DeploymentRevisionAnnotation is set by the deployment controller on every rollout
+const DeploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// deploymentImages returns the container images of a deployment, comma-separated
+func deploymentImages(deployment *appsv1.Deployment) string {
+	images := make([]string, 0, len(deployment.Spec.Template.Spec.Containers))
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	return strings.Join(images, ",")
 }
 
 func (c *Collector) estimateNetworkBandwidth(service v1.Service) float64 {
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// crashLoopBackOffReason is the waiting reason of containers restarted with back-off
+const crashLoopBackOffReason = "CrashLoopBackOff"
+
+// detectCrashLoop marks a sample taken while pods of the service crash-loop.
+// A pod counts when one of its containers waits in CrashLoopBackOff, or has
+// restarted at least min_restarts times and last terminated within the
+// window, which covers the short runs between back-offs.
+func (c *Collector) detectCrashLoop(ctx context.Context, service v1.Service, sample *MetricsData) error {
+	pods, err := c.getServicePods(ctx, service)
+	if err != nil {
+		return err
+	}
+
+	cutoff := sample.Timestamp.Add(-c.config.CrashLoop.Window)
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if crashLooping(status, c.config.CrashLoop.MinRestarts, cutoff.Unix()) {
+				sample.CrashLoopPods++
+				break
+			}
+		}
+	}
+
+	sample.CrashLoop = sample.CrashLoopPods > 0
+	return nil
+}
+
+// crashLooping reports whether a container is crash-looping
+func crashLooping(status v1.ContainerStatus, minRestarts int32, cutoff int64) bool {
+	if waiting := status.State.Waiting; waiting != nil && waiting.Reason == crashLoopBackOffReason {
+		return true
+	}
+	terminated := status.LastTerminationState.Terminated
+	return terminated != nil && status.RestartCount >= minRestarts && terminated.FinishedAt.Unix() > cutoff
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getBackendPods returns the pods that receive traffic for the service: the
+// ready pod endpoints of its EndpointSlices. This covers services selecting
+// pods of several deployments and leaves out pods that are starting or
+// terminating. Services without EndpointSlices, and the selector discovery
+// mode, fall back to the pods matching the service selector.
+func (c *Collector) getBackendPods(ctx context.Context, service v1.Service) ([]v1.Pod, error) {
+	if c.config.BackendDiscovery == "selector" {
+		return c.getServicePods(ctx, service)
+	}
+
+	sliceList := &discoveryv1.EndpointSliceList{}
+	if err := c.client.List(ctx, sliceList, client.InNamespace(service.Namespace),
+		client.MatchingLabels{discoveryv1.LabelServiceName: service.Name}); err != nil {
+		return nil, err
+	}
+	if len(sliceList.Items) == 0 {
+		return c.getServicePods(ctx, service)
+	}
+
+	// A dual-stack service has a slice per IP family listing the same pods
+	seen := make(map[string]bool)
+	var pods []v1.Pod
+	for _, slice := range sliceList.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" || seen[endpoint.TargetRef.Name] {
+				continue
+			}
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			seen[endpoint.TargetRef.Name] = true
+
+			pod := &v1.Pod{}
+			key := types.NamespacedName{Namespace: service.Namespace, Name: endpoint.TargetRef.Name}
+			if err := c.client.Get(ctx, key, pod); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue // Deleted since the slice was updated
+				}
+				return nil, err
+			}
+			pods = append(pods, *pod)
+		}
+	}
+	return pods, nil
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+func TestGetBackendPodsDualStack(t *testing.T) {
+	ready, notReady := true, false
+	endpoint := func(pod, address string, isReady *bool) discoveryv1.Endpoint {
+		return discoveryv1.Endpoint{
+			Addresses:  []string{address},
+			Conditions: discoveryv1.EndpointConditions{Ready: isReady},
+			TargetRef:  &v1.ObjectReference{Kind: "Pod", Name: pod},
+		}
+	}
+	slice := func(name string, family discoveryv1.AddressType, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "shop",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "web"},
+			},
+			AddressType: family,
+			Endpoints:   endpoints,
+		}
+	}
+	pod := func(name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"}}
+	}
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		want    []string
+	}{
+		{
+			name: "single-stack ipv6",
+			objects: []client.Object{
+				slice("web-v6", discoveryv1.AddressTypeIPv6,
+					endpoint("web-a", "fd00:10:244::a", &ready),
+					endpoint("web-b", "fd00:10:244::b", &ready)),
+				pod("web-a"), pod("web-b"),
+			},
+			want: []string{"web-a", "web-b"},
+		},
+		{
+			name: "dual-stack slices list each pod once",
+			objects: []client.Object{
+				slice("web-v4", discoveryv1.AddressTypeIPv4,
+					endpoint("web-a", "10.244.0.10", &ready),
+					endpoint("web-b", "10.244.0.11", &ready)),
+				slice("web-v6", discoveryv1.AddressTypeIPv6,
+					endpoint("web-a", "fd00:10:244::a", &ready),
+					endpoint("web-b", "fd00:10:244::b", &ready)),
+				pod("web-a"), pod("web-b"),
+			},
+			want: []string{"web-a", "web-b"},
+		},
+		{
+			name: "not ready pods left out",
+			objects: []client.Object{
+				slice("web-v4", discoveryv1.AddressTypeIPv4,
+					endpoint("web-a", "10.244.0.10", &ready),
+					endpoint("web-b", "10.244.0.11", &notReady)),
+				slice("web-v6", discoveryv1.AddressTypeIPv6,
+					endpoint("web-a", "fd00:10:244::a", &ready),
+					endpoint("web-b", "fd00:10:244::b", &notReady)),
+				pod("web-a"), pod("web-b"),
+			},
+			want: []string{"web-a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCollector(fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tt.objects...).Build(), config.DefaultConfig().Metrics)
+			service := v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}}
+
+			pods, err := c.getBackendPods(context.Background(), service)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, pod := range pods {
+				got = append(got, pod.Name)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got pods %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got pods %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxHistoryPoints bounds the samples per series of a history range query,
+// below the 11,000 points Prometheus accepts
+const maxHistoryPoints = 10000
+
+// Services returns the keys (namespace/name) of the services with collected metrics
+func (c *Collector) Services() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.metricsStore))
+	for key := range c.metricsStore {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// History returns the samples of a service between start and end, oldest
+// first. With Prometheus configured they are rebuilt from the recording
+// rules, one per collection interval or coarser so a long range stays
+// within the query limit; otherwise they come from the in-memory history,
+// which only reaches back retention_period.
+func (c *Collector) History(ctx context.Context, serviceName, namespace string, start, end time.Time) ([]*MetricsData, error) {
+	if c.prometheus == nil {
+		var history []*MetricsData
+		for _, sample := range c.GetMetrics(serviceName, namespace) {
+			if !sample.Timestamp.Before(start) && !sample.Timestamp.After(end) {
+				history = append(history, sample)
+			}
+		}
+		return history, nil
+	}
+
+	service := &v1.Service{}
+	if err := c.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: serviceName}, service); err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	step := c.config.CollectionInterval
+	if minStep := end.Sub(start) / maxHistoryPoints; step < minStep {
+		step = minStep
+	}
+	return c.queryHistory(ctx, *service, start, end, step)
+}
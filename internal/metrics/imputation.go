@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Imputed metric names, as used in MetricsData.Imputed
+const (
+	MetricCPUUtilization    = "cpu_utilization"
+	MetricMemoryUtilization = "memory_utilization"
+	MetricRequestRate       = "request_rate"
+	MetricResponseTime      = "response_time"
+	MetricErrorRate         = "error_rate"
+	MetricConcurrency       = "concurrency"
+	MetricTokenRate         = "token_rate"
+	MetricTimeToFirstToken  = "time_to_first_token"
+	MetricNetworkBandwidth  = "network_bandwidth"
+	MetricIOBandwidth       = "io_bandwidth"
+)
+
+// ImputableMetrics lists every metric that can be backfilled
+var ImputableMetrics = []string{
+	MetricCPUUtilization,
+	MetricMemoryUtilization,
+	MetricRequestRate,
+	MetricResponseTime,
+	MetricErrorRate,
+	MetricConcurrency,
+	MetricTokenRate,
+	MetricTimeToFirstToken,
+	MetricNetworkBandwidth,
+	MetricIOBandwidth,
+}
+
+// sourceMetrics maps each source to the metrics it provides
+var sourceMetrics = map[string][]string{
+	SourceResource:   {MetricCPUUtilization, MetricMemoryUtilization},
+	SourceNginx:      {MetricRequestRate, MetricResponseTime, MetricErrorRate, MetricConcurrency},
+	SourcePrometheus: {MetricRequestRate, MetricResponseTime, MetricErrorRate, MetricConcurrency},
+	SourceLLM:        {MetricTokenRate, MetricTimeToFirstToken},
+	SourceSystem:     {MetricNetworkBandwidth, MetricIOBandwidth},
+	SourceSimulated:  {MetricRequestRate, MetricResponseTime, MetricErrorRate, MetricConcurrency},
+}
+
+// metricField returns a pointer to the named metric on a sample
+func metricField(m *MetricsData, name string) *float64 {
+	switch name {
+	case MetricCPUUtilization:
+		return &m.CPUUtilization
+	case MetricMemoryUtilization:
+		return &m.MemoryUtilization
+	case MetricRequestRate:
+		return &m.RequestRate
+	case MetricResponseTime:
+		return &m.ResponseTime
+	case MetricErrorRate:
+		return &m.ErrorRate
+	case MetricConcurrency:
+		return &m.Concurrency
+	case MetricTokenRate:
+		return &m.TokenRate
+	case MetricTimeToFirstToken:
+		return &m.TimeToFirstToken
+	case MetricNetworkBandwidth:
+		return &m.NetworkBandwidth
+	case MetricIOBandwidth:
+		return &m.IOBandwidth
+	}
+	return nil
+}
+
+// IsImputed reports whether a metric on the sample was backfilled
+func (m *MetricsData) IsImputed(name string) bool {
+	for _, imputed := range m.Imputed {
+		if imputed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// impute backfills the metrics of failed sources from the service history.
+// "locf" carries the previous value forward, moving it a (1 - decay) step
+// towards the mean of the observed values each cycle; "linear" extrapolates
+// the last change, shrinking it by decay each cycle. Metrics whose source has
+// not succeeded within max_age are left as collected. Replica counts are
+// carried forward unchanged and not flagged.
+func (c *Collector) impute(key string, sample *MetricsData, failed []string) {
+	cfg := c.config.Imputation
+	if cfg.Method == "" || cfg.Method == "none" || len(failed) == 0 {
+		return
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	history := c.metricsStore[key]
+	if len(history) == 0 {
+		return
+	}
+	previous := history[len(history)-1]
+
+	for _, source := range failed {
+		last, ok := c.sourceSuccess[key][source]
+		if !ok || sample.Timestamp.Sub(last) > cfg.MaxAge {
+			continue
+		}
+
+		if source == SourceDeployment {
+			sample.CurrentReplicas = previous.CurrentReplicas
+			sample.DesiredReplicas = previous.DesiredReplicas
+			continue
+		}
+
+		for _, name := range sourceMetrics[source] {
+			if sample.IsImputed(name) {
+				continue // Also provided by another failed source
+			}
+			value := imputeValue(cfg, history, previous, name)
+			if value < 0 {
+				value = 0
+			}
+			*metricField(sample, name) = value
+			sample.Imputed = append(sample.Imputed, name)
+		}
+	}
+}
+
+// imputeValue estimates a metric from the previous samples
+func imputeValue(cfg config.ImputationConfig, history []*MetricsData, previous *MetricsData, name string) float64 {
+	prev := *metricField(previous, name)
+
+	if cfg.Method == "linear" {
+		if len(history) < 2 {
+			return prev
+		}
+		before := *metricField(history[len(history)-2], name)
+		return prev + (prev-before)*cfg.Decay
+	}
+
+	var sum float64
+	var count int
+	for _, m := range history {
+		if m.IsImputed(name) {
+			continue
+		}
+		sum += *metricField(m, name)
+		count++
+	}
+	if count == 0 {
+		return prev
+	}
+	mean := sum / float64(count)
+	return mean + (prev-mean)*cfg.Decay
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// collectLatencySLO reads the share of requests slower than the latency SLO
+// threshold from the recording rules and converts it to burn rates: the
+// share divided by the error budget, the share the objective allows.
+// Services without traffic keep both at zero.
+func (c *Collector) collectLatencySLO(ctx context.Context, service v1.Service, metrics *MetricsData) error {
+	now := time.Now()
+	budget := 1 - c.config.LatencySLO.Objective/100
+
+	rates := make([]float64, len(LatencySLOWindows))
+	for i, window := range LatencySLOWindows {
+		ratio, err := c.prometheus.Query(ctx, fmt.Sprintf("%s{namespace=%q, service=%q, window=%q}",
+			RecordLatencySLOErrors, service.Namespace, service.Name, window), now)
+		if err != nil {
+			return err
+		}
+		rates[i] = sumVector(ratio) / budget
+	}
+
+	metrics.LatencyBurnRate5m, metrics.LatencyBurnRate1h = rates[0], rates[1]
+	return nil
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// timeToFirstTokenQuantile is the recorded time to first token quantile
+const timeToFirstTokenQuantile = "0.95"
+
+// collectLLMMetrics reads the token throughput and time to first token of a
+// service from the recording rules. Services without LLM series, which are
+// not model servers, keep both at zero.
+func (c *Collector) collectLLMMetrics(ctx context.Context, service v1.Service, metrics *MetricsData) error {
+	now := time.Now()
+
+	tokens, err := c.prometheus.Query(ctx, RecordTokenRate+serviceSelector(service.Name, service.Namespace), now)
+	if err != nil {
+		return err
+	}
+	ttft, err := c.prometheus.Query(ctx, fmt.Sprintf("%s{namespace=%q, service=%q, quantile=%q}",
+		RecordTimeToFirstToken, service.Namespace, service.Name, timeToFirstTokenQuantile), now)
+	if err != nil {
+		return err
+	}
+
+	metrics.TokenRate = sumVector(tokens)
+	metrics.TimeToFirstToken = sumVector(ttft) * 1000 // Milliseconds
+	return nil
+}
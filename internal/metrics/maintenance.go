@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// unschedulableTaint is added to cordoned nodes
+const unschedulableTaint = "node.kubernetes.io/unschedulable"
+
+// detectMaintenance marks a sample taken while pods of the service run on
+// cordoned or draining nodes, or are being evicted
+func (c *Collector) detectMaintenance(ctx context.Context, service v1.Service, sample *MetricsData) error {
+	pods, err := c.getServicePods(ctx, service)
+	if err != nil {
+		return err
+	}
+
+	cordoned := make(map[string]bool)
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			sample.MaintenancePods++
+			continue
+		}
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+
+		unschedulable, checked := cordoned[pod.Spec.NodeName]
+		if !checked {
+			node := &v1.Node{}
+			if err := c.client.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+				return err
+			}
+			unschedulable = isCordoned(node)
+			cordoned[pod.Spec.NodeName] = unschedulable
+		}
+		if unschedulable {
+			sample.MaintenancePods++
+		}
+	}
+
+	sample.Maintenance = sample.MaintenancePods > 0
+	return nil
+}
+
+// isCordoned reports whether a node is marked unschedulable
+func isCordoned(node *v1.Node) bool {
+	if node.Spec.Unschedulable {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == unschedulableTaint {
+			return true
+		}
+	}
+	return false
+}
+
+// correctMaintenance replaces the latency and error rate of a sample taken
+// during node maintenance with the last values observed outside of it, so
+// evictions don't read as load
+func (c *Collector) correctMaintenance(key string, sample *MetricsData) {
+	if !sample.Maintenance || !c.config.MaintenanceBlackout.CorrectLatency {
+		return
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	history := c.metricsStore[key]
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Maintenance {
+			continue
+		}
+		sample.ResponseTime = history[i].ResponseTime
+		sample.ErrorRate = history[i].ErrorRate
+		for _, name := range []string{MetricResponseTime, MetricErrorRate} {
+			if !sample.IsImputed(name) {
+				sample.Imputed = append(sample.Imputed, name)
+			}
+		}
+		return
+	}
+}
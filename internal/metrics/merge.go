@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Merge policies for a metric reported by several sources
+const (
+	MergePreferSource = "prefer-source"
+	MergeMax          = "max"
+	MergeAverage      = "average"
+	MergeFreshest     = "freshest"
+)
+
+// requestSources are the request metric sources in order of preference
+var requestSources = []string{SourcePrometheus, SourceNginx}
+
+// mergedMetrics are the metrics merge policies apply to
+var mergedMetrics = []string{MetricRequestRate, MetricResponseTime, MetricErrorRate}
+
+// conflictTolerance is the relative difference below which source values
+// are not reported as conflicting
+const conflictTolerance = 0.01
+
+// mergesSources reports whether every request source is queried each cycle
+// and combined through the merge policies. Without policies the first
+// source with data is used, as before merging existed.
+func (c *Collector) mergesSources() bool {
+	return len(c.config.SourceMerge) > 0
+}
+
+// mergePolicyUsed reports whether any field is merged with the policy
+func (c *Collector) mergePolicyUsed(policy string) bool {
+	for _, merge := range c.config.SourceMerge {
+		if merge.Policy == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeReadings combines the request metrics reported by several sources
+// into the sample, field by field. Each reading's Timestamp is the time of
+// its source data. Fields without a policy prefer Prometheus.
+func (c *Collector) mergeReadings(key string, metrics *MetricsData, readings map[string]*MetricsData) {
+	for _, name := range mergedMetrics {
+		merge := c.config.SourceMerge[name]
+		if merge.Policy == "" {
+			merge.Policy = MergePreferSource
+		}
+		if merge.Source == "" {
+			merge.Source = SourcePrometheus
+		}
+
+		values := make(map[string]float64, len(readings))
+		var sources []string
+		for _, source := range requestSources {
+			if reading, ok := readings[source]; ok {
+				values[source] = *metricField(reading, name)
+				sources = append(sources, source)
+			}
+		}
+
+		var value float64
+		switch merge.Policy {
+		case MergeMax:
+			value = math.Inf(-1)
+			for _, source := range sources {
+				value = math.Max(value, values[source])
+			}
+		case MergeAverage:
+			for _, source := range sources {
+				value += values[source] / float64(len(sources))
+			}
+		case MergeFreshest:
+			var newest time.Time
+			for _, source := range sources {
+				if at := readings[source].Timestamp; at.After(newest) {
+					newest, value = at, values[source]
+				}
+			}
+		default:
+			chosen, ok := values[merge.Source]
+			if !ok {
+				chosen = values[sources[0]]
+			}
+			value = chosen
+		}
+		*metricField(metrics, name) = value
+
+		if conflicting(values) {
+			logrus.WithFields(logrus.Fields{
+				"service": key,
+				"metric":  name,
+				"values":  values,
+				"policy":  merge.Policy,
+				"merged":  value,
+			}).Debug("Metrics sources disagree")
+		}
+	}
+
+	// Bandwidth is only reported by nginx
+	if reading, ok := readings[SourceNginx]; ok {
+		metrics.NetworkBandwidth = reading.NetworkBandwidth
+	}
+
+	// In-flight requests come from the first source that reports them; the
+	// application gauge is more precise than nginx connections
+	for _, source := range requestSources {
+		if reading, ok := readings[source]; ok && reading.Concurrency > 0 {
+			metrics.Concurrency = reading.Concurrency
+			break
+		}
+	}
+}
+
+// conflicting reports whether source values differ by more than the tolerance
+func conflicting(values map[string]float64) bool {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, value := range values {
+		low, high = math.Min(low, value), math.Max(high, value)
+	}
+	if len(values) < 2 || high == low {
+		return false
+	}
+	return (high-low)/math.Max(math.Abs(high), math.Abs(low)) > conflictTolerance
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// istioOutboundCluster matches the outbound Envoy clusters of Istio sidecars,
+// outbound|<port>|<subset>|<service>.<namespace>.svc.<domain>, capturing the
+// service and namespace
+const istioOutboundCluster = `outbound\\|[0-9]+\\|[^|]*\\|([^.]+)\\.([^.]+)\\..*`
+
+// meshEjectionExpr returns the expression recording the ejected endpoints of
+// each service. Every client proxy ejects on its own view of the endpoints,
+// so the largest count any client reports is recorded.
+func meshEjectionExpr(mesh string) string {
+	if mesh == "linkerd" {
+		// Linkerd's failure accrual moves endpoints out of the ready state
+		return `max by (namespace, service) (label_replace(label_replace(outbound_http_balancer_endpoints{endpoint_state="pending", backend_kind="Service"}, "service", "$1", "backend_name", "(.*)"), "namespace", "$1", "backend_namespace", "(.*)"))`
+	}
+	ejections := fmt.Sprintf(`envoy_cluster_outlier_detection_ejections_active{cluster_name=~"%s"}`, istioOutboundCluster)
+	return fmt.Sprintf(`max by (namespace, service) (label_replace(label_replace(%s, "service", "$1", "cluster_name", "%s"), "namespace", "$2", "cluster_name", "%s"))`,
+		ejections, istioOutboundCluster, istioOutboundCluster)
+}
+
+// collectMeshEjection reads the endpoints of a service that service mesh
+// outlier detection has ejected from load balancing
+func (c *Collector) collectMeshEjection(ctx context.Context, service v1.Service, metrics *MetricsData) error {
+	ejected, err := c.prometheus.Query(ctx, fmt.Sprintf("%s{namespace=%q, service=%q}",
+		RecordMeshEjections, service.Namespace, service.Name), time.Now())
+	if err != nil {
+		return err
+	}
+
+	metrics.EjectedEndpoints = int(sumVector(ejected))
+	return nil
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Operating systems of nodes, as in the kubernetes.io/os label
+const (
+	OSLinux   = "linux"
+	OSWindows = "windows"
+)
+
+// podOS returns the operating system a pod runs on: its spec.os when set,
+// otherwise the kubernetes.io/os label of its node, cached per node, and
+// Linux when neither is known
+func (c *Collector) podOS(ctx context.Context, pod *v1.Pod) string {
+	if pod.Spec.OS != nil && pod.Spec.OS.Name != "" {
+		return string(pod.Spec.OS.Name)
+	}
+	if pod.Spec.NodeName == "" {
+		return OSLinux
+	}
+
+	c.osMu.Lock()
+	osName, cached := c.nodeOS[pod.Spec.NodeName]
+	c.osMu.Unlock()
+	if cached {
+		return osName
+	}
+
+	node := &v1.Node{}
+	if err := c.client.Get(ctx, client.ObjectKey{Name: pod.Spec.NodeName}, node); err != nil {
+		logrus.WithError(err).WithField("node", pod.Spec.NodeName).Debug("Failed to read node operating system")
+		return OSLinux
+	}
+	osName = node.Labels[v1.LabelOSStable]
+	if osName == "" {
+		osName = OSLinux
+	}
+
+	c.osMu.Lock()
+	c.nodeOS[pod.Spec.NodeName] = osName
+	c.osMu.Unlock()
+	return osName
+}
+
+// splitWindowsPods separates the pods running on Windows nodes from the others
+func (c *Collector) splitWindowsPods(ctx context.Context, pods []v1.Pod) (linux, windows []v1.Pod) {
+	for i := range pods {
+		if c.podOS(ctx, &pods[i]) == OSWindows {
+			windows = append(windows, pods[i])
+		} else {
+			linux = append(linux, pods[i])
+		}
+	}
+	return linux, windows
+}
+
+// noteUnsupported logs once per service that a measurement isn't available
+// on Windows nodes, rather than on every cycle
+func (c *Collector) noteUnsupported(key, measurement string) {
+	c.osMu.Lock()
+	defer c.osMu.Unlock()
+
+	if c.unsupported[key] == nil {
+		c.unsupported[key] = make(map[string]bool)
+	}
+	if c.unsupported[key][measurement] {
+		return
+	}
+	c.unsupported[key][measurement] = true
+	logrus.WithFields(logrus.Fields{
+		"service":     key,
+		"measurement": measurement,
+	}).Info("Measurement not available for pods on Windows nodes, skipping it for them")
+}
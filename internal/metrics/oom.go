@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// oomKilledReason is the termination reason of containers killed for
+// exceeding their memory limit
+const oomKilledReason = "OOMKilled"
+
+// detectOOMKills counts the OOM kills of the service's containers within the
+// configured window. Kubernetes only keeps the last termination of each
+// container, so kills are remembered by container and finish time as they
+// are seen.
+func (c *Collector) detectOOMKills(ctx context.Context, key string, service v1.Service, sample *MetricsData) error {
+	pods, err := c.getServicePods(ctx, service)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	kills := c.oomKills[key]
+	if kills == nil {
+		kills = make(map[string]time.Time)
+		c.oomKills[key] = kills
+	}
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.LastTerminationState.Terminated
+			if terminated == nil || terminated.Reason != oomKilledReason {
+				continue
+			}
+			id := fmt.Sprintf("%s/%s/%d", pod.Name, status.Name, terminated.FinishedAt.Unix())
+			kills[id] = terminated.FinishedAt.Time
+		}
+	}
+
+	cutoff := sample.Timestamp.Add(-c.config.OOMWindow)
+	for id, at := range kills {
+		if !at.After(cutoff) {
+			delete(kills, id)
+		}
+	}
+	sample.OOMKills = len(kills)
+	return nil
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// detectPreemption counts the pods of a service that the scheduler is
+// evicting for higher-priority pods, and the pending pods it has failed to
+// place, e.g. replacements of preempted pods on a full cluster
+func (c *Collector) detectPreemption(ctx context.Context, service v1.Service, sample *MetricsData) error {
+	pods, err := c.getServicePods(ctx, service)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		switch {
+		case podCondition(pod, v1.DisruptionTarget, v1.ConditionTrue, v1.PodReasonPreemptionByScheduler):
+			sample.PreemptedPods++
+		case pod.Status.Phase == v1.PodPending && podCondition(pod, v1.PodScheduled, v1.ConditionFalse, v1.PodReasonUnschedulable):
+			sample.UnschedulablePods++
+		}
+	}
+	return nil
+}
+
+// podCondition reports whether a pod's condition of a type has a status for a reason
+func podCondition(pod v1.Pod, conditionType v1.PodConditionType, status v1.ConditionStatus, reason string) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == status && condition.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
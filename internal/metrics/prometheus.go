@@ -0,0 +1,282 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/hydraai/hydra-route/internal/egress"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// tenantHeader carries the tenant to Cortex, Mimir and Thanos receivers
+const tenantHeader = "X-Scope-OrgID"
+
+// responseTimeQuantile is the recorded latency quantile used as response time
+const responseTimeQuantile = "0.95"
+
+// PrometheusClient queries a Prometheus-compatible HTTP API, including
+// multi-tenant backends such as Thanos, Cortex, Mimir and VictoriaMetrics
+type PrometheusClient struct {
+	url           string
+	headers       map[string]string
+	parameters    map[string]string
+	splitInterval time.Duration
+	httpClient    *http.Client
+}
+
+// prometheusResponse is the envelope of the Prometheus query API
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// NewPrometheusClient creates a client for the configured Prometheus URL
+func NewPrometheusClient(cfg config.MetricsConfig, httpClient *http.Client) *PrometheusClient {
+	return &PrometheusClient{
+		url:           strings.TrimSuffix(cfg.PrometheusURL, "/"),
+		headers:       sourceHeaders(cfg, SourcePrometheus),
+		parameters:    cfg.PrometheusQuery.Parameters,
+		splitInterval: cfg.PrometheusQuery.SplitInterval,
+		httpClient:    httpClient,
+	}
+}
+
+// sourceHeaders returns the HTTP headers configured for a source
+func sourceHeaders(cfg config.MetricsConfig, source string) map[string]string {
+	sourceCfg := cfg.Sources[source]
+	headers := make(map[string]string, len(sourceCfg.Headers)+1)
+	for name, value := range sourceCfg.Headers {
+		headers[name] = value
+	}
+	if sourceCfg.TenantID != "" {
+		headers[tenantHeader] = sourceCfg.TenantID
+	}
+	return headers
+}
+
+// newSourceClient returns an HTTP client with the timeout and connection
+// pool configured for a source. Each source gets its own pool, and a slow
+// endpoint holds up the collection cycle for at most its timeout per request.
+func newSourceClient(cfg config.MetricsConfig, source string) *http.Client {
+	sourceCfg := cfg.Sources[source]
+	timeout := sourceCfg.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	maxIdle := sourceCfg.MaxIdleConnections
+	if maxIdle == 0 {
+		maxIdle = 10
+	}
+	idleTimeout := sourceCfg.IdleConnectionTimeout
+	if idleTimeout == 0 {
+		idleTimeout = 90 * time.Second
+	}
+	keepAlive := sourceCfg.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = 30 * time.Second
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = egress.Guard((&net.Dialer{Timeout: timeout, KeepAlive: keepAlive}).DialContext)
+	transport.MaxIdleConns = maxIdle
+	transport.MaxIdleConnsPerHost = maxIdle
+	transport.IdleConnTimeout = idleTimeout
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// newSourceRequest builds a GET request carrying the source's headers
+func newSourceRequest(ctx context.Context, url string, headers map[string]string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// Query evaluates an instant query
+func (p *PrometheusClient) Query(ctx context.Context, query string, ts time.Time) (model.Vector, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("time", formatTime(ts))
+
+	var vector model.Vector
+	if err := p.get(ctx, "/api/v1/query", params, model.ValVector, &vector); err != nil {
+		return nil, err
+	}
+	return vector, nil
+}
+
+// QueryRange evaluates a range query. Ranges longer than the split interval
+// are fetched as consecutive queries and merged, so historical pulls stay
+// within the query limits of the backend.
+func (p *PrometheusClient) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) (model.Matrix, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("range query step must be positive")
+	}
+
+	series := make(map[model.Fingerprint]*model.SampleStream)
+	for chunkStart := start; !chunkStart.After(end); {
+		chunkEnd := end
+		if p.splitInterval > 0 && chunkEnd.Sub(chunkStart) > p.splitInterval {
+			chunkEnd = chunkStart.Add(p.splitInterval)
+		}
+
+		params := url.Values{}
+		params.Set("query", query)
+		params.Set("start", formatTime(chunkStart))
+		params.Set("end", formatTime(chunkEnd))
+		params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+
+		var matrix model.Matrix
+		if err := p.get(ctx, "/api/v1/query_range", params, model.ValMatrix, &matrix); err != nil {
+			return nil, err
+		}
+		for _, stream := range matrix {
+			fingerprint := stream.Metric.Fingerprint()
+			if existing, ok := series[fingerprint]; ok {
+				existing.Values = append(existing.Values, stream.Values...)
+			} else {
+				series[fingerprint] = stream
+			}
+		}
+
+		// Chunks share no sample: the next one starts a step after this one ends
+		chunkStart = chunkEnd.Add(step)
+	}
+
+	matrix := make(model.Matrix, 0, len(series))
+	for _, stream := range series {
+		matrix = append(matrix, stream)
+	}
+	sort.Sort(matrix)
+	return matrix, nil
+}
+
+// get calls a query endpoint and decodes a result of the expected type
+func (p *PrometheusClient) get(ctx context.Context, path string, params url.Values, resultType model.ValueType, result interface{}) error {
+	for name, value := range p.parameters {
+		params.Set(name, value)
+	}
+
+	req, err := newSourceRequest(ctx, p.url+path+"?"+params.Encode(), p.headers)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var response prometheusResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("prometheus returned status %d: %w", resp.StatusCode, err)
+	}
+	if response.Status != "success" {
+		return fmt.Errorf("prometheus query failed (%s): %s", response.ErrorType, response.Error)
+	}
+	if response.Data.ResultType != resultType.String() {
+		return fmt.Errorf("prometheus returned %s result, expected %s", response.Data.ResultType, resultType)
+	}
+	return json.Unmarshal(response.Data.Result, result)
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', -1, 64)
+}
+
+// serviceSelector returns the label matchers selecting a service's recorded series
+func serviceSelector(service, namespace string) string {
+	return fmt.Sprintf("{namespace=%q, service=%q}", namespace, service)
+}
+
+// collectPrometheusMetrics reads the request rate, error rate, response time
+// and, with a concurrency metric, the in-flight requests of a service from the recording rules generated by "hydra-route
+// recording-rules". It reports false when the rules have no series for the
+// service, so the caller can fall back to another source.
+func (c *Collector) collectPrometheusMetrics(ctx context.Context, service v1.Service, metrics *MetricsData) (bool, error) {
+	selector := serviceSelector(service.Name, service.Namespace)
+	now := time.Now()
+
+	rate, err := c.prometheus.Query(ctx, RecordRequestRate+selector, now)
+	if err != nil {
+		return false, err
+	}
+	if len(rate) == 0 {
+		return false, nil
+	}
+
+	errorRatio, err := c.prometheus.Query(ctx, RecordErrorRatio+selector, now)
+	if err != nil {
+		return false, err
+	}
+	latency, err := c.prometheus.Query(ctx, fmt.Sprintf("%s{namespace=%q, service=%q, quantile=%q}",
+		RecordLatencyQuantile, service.Namespace, service.Name, responseTimeQuantile), now)
+	if err != nil {
+		return false, err
+	}
+
+	metrics.RequestRate = sumVector(rate)
+	metrics.ErrorRate = sumVector(errorRatio) * 100  // Percentage
+	metrics.ResponseTime = sumVector(latency) * 1000 // Milliseconds
+
+	if c.config.ConcurrencyMetric != "" {
+		inFlight, err := c.prometheus.Query(ctx, RecordConcurrency+selector, now)
+		if err != nil {
+			return false, err
+		}
+		metrics.Concurrency = sumVector(inFlight)
+	}
+
+	// The freshest merge policy compares the age of the recorded samples,
+	// which lag the live nginx stats by up to a rule evaluation interval
+	if c.mergePolicyUsed(MergeFreshest) {
+		recorded, err := c.prometheus.Query(ctx, "max(timestamp("+RecordRequestRate+selector+"))", now)
+		if err != nil {
+			return false, err
+		}
+		if len(recorded) > 0 {
+			seconds := float64(recorded[0].Value)
+			metrics.Timestamp = time.Unix(0, int64(seconds*1e9))
+		}
+	}
+	return true, nil
+}
+
+// sumVector sums the sample values of a vector, skipping NaN from empty ratios
+func sumVector(vector model.Vector) float64 {
+	var sum float64
+	for _, sample := range vector {
+		if value := float64(sample.Value); !math.IsNaN(value) {
+			sum += value
+		}
+	}
+	return sum
+}
@@ -0,0 +1,269 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Recording rule names for the per-service series HydraRoute reads from
+// Prometheus. They follow the level:metric:operations convention and carry
+// the namespace and service (or pod) labels.
+const (
+	RecordRequestRate      = "namespace_service:hydra_route_requests:rate"
+	RecordErrorRatio       = "namespace_service:hydra_route_errors:ratio"
+	RecordLatencyQuantile  = "namespace_service:hydra_route_request_duration_seconds:quantile"
+	RecordCPUUsage         = "namespace_pod:hydra_route_cpu_usage_cores:rate"
+	RecordMemoryUsage      = "namespace_pod:hydra_route_memory_working_set_bytes:sum"
+	RecordCPUThrottling    = "namespace_pod:hydra_route_cpu_throttled_periods:ratio"
+	RecordConcurrency      = "namespace_service:hydra_route_requests_in_flight:sum"
+	RecordTokenRate        = "namespace_service:hydra_route_generation_tokens:rate"
+	RecordTimeToFirstToken = "namespace_service:hydra_route_time_to_first_token_seconds:quantile"
+	RecordLatencySLOErrors = "namespace_service:hydra_route_latency_slo_errors:ratio"
+	RecordMeshEjections    = "namespace_service:hydra_route_mesh_ejected_endpoints:max"
+	RecordRetryRate        = "namespace_service:hydra_route_retries:rate"
+	RecordNodeTemperature  = "node:hydra_route_temperature_celsius:max"
+	RecordNodePower        = "node:hydra_route_power_watts:sum"
+)
+
+// LatencySLOWindows are the windows the latency SLO error ratio is recorded
+// over, short first, as the window label of RecordLatencySLOErrors
+var LatencySLOWindows = []string{"5m", "1h"}
+
+// LatencyQuantiles are the response time percentiles recorded per service
+var LatencyQuantiles = []float64{0.5, 0.95, 0.99}
+
+// RecordingRule is a single Prometheus recording rule
+type RecordingRule struct {
+	Record string            `json:"record"`
+	Expr   string            `json:"expr"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// RuleGroup is a Prometheus rule group
+type RuleGroup struct {
+	Name     string          `json:"name"`
+	Interval string          `json:"interval,omitempty"`
+	Rules    []RecordingRule `json:"rules"`
+}
+
+// RecordingRules returns the recording rules for the queries HydraRoute runs
+// against Prometheus. Request rate, error ratio and latency come from the
+// nginx ingress controller, or the applications' request metrics for
+// services without nginx series, and are mapped onto the namespace and
+// service labels through the configured label mapping.
+func RecordingRules(cfg config.MetricsConfig) RuleGroup {
+	window := model.Duration(cfg.RequestRateWindow).String()
+	mapping := cfg.LabelMapping
+
+	requests := fmt.Sprintf("rate(nginx_ingress_controller_requests[%s])", window)
+	errors := fmt.Sprintf("rate(nginx_ingress_controller_requests{status=~\"5..\"}[%s])", window)
+	buckets := fmt.Sprintf("rate(nginx_ingress_controller_request_duration_seconds_bucket[%s])", window)
+
+	requestRate := mapServiceLabels(mapping, requests)
+	errorRatio := mapServiceLabels(mapping, errors) + " / " + mapServiceLabels(mapping, requests)
+
+	// Services no ingress routes to fall back to the applications' own
+	// request metrics, for namespaces and services nginx has no series for
+	app := cfg.ApplicationRequests
+	if app.Counter != "" {
+		appRequests := fmt.Sprintf("rate(%s[%s])", app.Counter, window)
+		appErrors := fmt.Sprintf("rate(%s{%s}[%s])", app.Counter, app.ErrorSelector, window)
+		requestRate = fmt.Sprintf("(%s) or (%s)", requestRate, mapServiceLabels(mapping, appRequests))
+		errorRatio = fmt.Sprintf("(%s) or (%s / %s)", errorRatio, mapServiceLabels(mapping, appErrors), mapServiceLabels(mapping, appRequests))
+	}
+
+	rules := []RecordingRule{
+		{
+			Record: RecordRequestRate,
+			Expr:   requestRate,
+		},
+		{
+			Record: RecordErrorRatio,
+			Expr:   errorRatio,
+		},
+	}
+
+	for _, quantile := range LatencyQuantiles {
+		latency := fmt.Sprintf("histogram_quantile(%g, %s)", quantile, mapServiceLabels(mapping, buckets, "le"))
+		if app.DurationHistogram != "" {
+			appBuckets := fmt.Sprintf("rate(%s_bucket[%s])", app.DurationHistogram, window)
+			latency = fmt.Sprintf("(%s) or (histogram_quantile(%g, %s))", latency, quantile, mapServiceLabels(mapping, appBuckets, "le"))
+		}
+		rules = append(rules, RecordingRule{
+			Record: RecordLatencyQuantile,
+			Expr:   latency,
+			Labels: map[string]string{"quantile": fmt.Sprintf("%g", quantile)},
+		})
+	}
+
+	// Resource usage is recorded per pod; the collector maps pods to services
+	// through the service selector. Pods cAdvisor has no series for, such as
+	// those on Windows nodes, fall back to the kubelet resource endpoint.
+	cpuUsage := fmt.Sprintf("sum by (namespace, pod) (rate(container_cpu_usage_seconds_total{container!=\"\", container!=\"POD\"}[%s]))", window)
+	memoryUsage := "sum by (namespace, pod) (container_memory_working_set_bytes{container!=\"\", container!=\"POD\"})"
+	if selector := cfg.WindowsNodes.ResourceMetricsSelector; selector != "" {
+		cpuUsage = fmt.Sprintf("(%s) or (sum by (namespace, pod) (rate(container_cpu_usage_seconds_total{container!=\"\", %s}[%s])))", cpuUsage, selector, window)
+		memoryUsage = fmt.Sprintf("(%s) or (sum by (namespace, pod) (container_memory_working_set_bytes{container!=\"\", %s}))", memoryUsage, selector)
+	}
+	rules = append(rules,
+		RecordingRule{
+			Record: RecordCPUUsage,
+			Expr:   cpuUsage,
+		},
+		RecordingRule{
+			Record: RecordMemoryUsage,
+			Expr:   memoryUsage,
+		},
+		RecordingRule{
+			Record: RecordCPUThrottling,
+			Expr: fmt.Sprintf("sum by (namespace, pod) (rate(container_cpu_cfs_throttled_periods_total{container!=\"\"}[%s])) / sum by (namespace, pod) (rate(container_cpu_cfs_periods_total{container!=\"\"}[%s]))",
+				window, window),
+		},
+	)
+
+	// In-flight requests come from an application gauge when one is configured
+	if cfg.ConcurrencyMetric != "" {
+		rules = append(rules, RecordingRule{
+			Record: RecordConcurrency,
+			Expr:   mapServiceLabels(mapping, cfg.ConcurrencyMetric),
+		})
+	}
+
+	// LLM serving metrics, when collected
+	if cfg.LLM.Enabled {
+		tokens := fmt.Sprintf("rate(%s[%s])", cfg.LLM.TokenCounter, window)
+		ttft := fmt.Sprintf("rate(%s_bucket[%s])", cfg.LLM.TimeToFirstTokenHistogram, window)
+		rules = append(rules,
+			RecordingRule{
+				Record: RecordTokenRate,
+				Expr:   mapServiceLabels(mapping, tokens),
+			},
+			RecordingRule{
+				Record: RecordTimeToFirstToken,
+				Expr:   fmt.Sprintf("histogram_quantile(%s, %s)", timeToFirstTokenQuantile, mapServiceLabels(mapping, ttft, "le")),
+				Labels: map[string]string{"quantile": timeToFirstTokenQuantile},
+			},
+		)
+	}
+
+	// Share of requests slower than the latency SLO threshold, per burn-rate window
+	if cfg.LatencySLO.Enabled {
+		le := fmt.Sprintf("%g", cfg.LatencySLO.Threshold.Seconds())
+		for _, window := range LatencySLOWindows {
+			good := fmt.Sprintf("rate(%s_bucket{le=%q}[%s])", cfg.LatencySLO.Histogram, le, window)
+			total := fmt.Sprintf("rate(%s_count[%s])", cfg.LatencySLO.Histogram, window)
+			rules = append(rules, RecordingRule{
+				Record: RecordLatencySLOErrors,
+				Expr:   fmt.Sprintf("1 - %s / %s", mapServiceLabels(mapping, good), mapServiceLabels(mapping, total)),
+				Labels: map[string]string{"window": window},
+			})
+		}
+	}
+
+	// Retried requests, when counted
+	if cfg.RetryStorm.Enabled && cfg.RetryStorm.RetryMetric != "" {
+		rules = append(rules, RecordingRule{
+			Record: RecordRetryRate,
+			Expr:   mapServiceLabels(mapping, fmt.Sprintf("rate(%s[%s])", cfg.RetryStorm.RetryMetric, window)),
+		})
+	}
+
+	// Endpoints ejected by service mesh outlier detection, when read
+	if cfg.MeshEjection.Enabled {
+		rules = append(rules, RecordingRule{
+			Record: RecordMeshEjections,
+			Expr:   meshEjectionExpr(cfg.MeshEjection.Mesh),
+		})
+	}
+
+	// Temperature and power draw of nodes, when read. They carry the node
+	// label rather than namespace and service.
+	if cfg.Thermal.Enabled {
+		rules = append(rules, RecordingRule{
+			Record: RecordNodeTemperature,
+			Expr:   nodeThermalExpr("max", cfg.Thermal.TemperatureMetric, cfg.Thermal.NodeLabel),
+		})
+		if cfg.Thermal.PowerMetric != "" {
+			rules = append(rules, RecordingRule{
+				Record: RecordNodePower,
+				Expr:   nodeThermalExpr("sum", cfg.Thermal.PowerMetric, cfg.Thermal.NodeLabel),
+			})
+		}
+	}
+
+	interval := cfg.CollectionInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	return RuleGroup{
+		Name:     "hydra-route",
+		Interval: model.Duration(interval).String(),
+		Rules:    rules,
+	}
+}
+
+// mapServiceLabels aggregates a source expression by namespace and service
+// (and any extra labels). The configured service and namespace labels are
+// copied onto service and namespace and the mapping rules are applied in
+// order before the final aggregation, so every label they read is kept
+// until then.
+func mapServiceLabels(mapping config.LabelMappingConfig, expr string, extra ...string) string {
+	serviceLabel, namespaceLabel := mapping.ServiceLabel, mapping.NamespaceLabel
+	if serviceLabel == "" {
+		serviceLabel = "service"
+	}
+	if namespaceLabel == "" {
+		namespaceLabel = "namespace"
+	}
+
+	target := append([]string{"namespace", "service"}, extra...)
+	if serviceLabel == "service" && namespaceLabel == "namespace" && len(mapping.Rules) == 0 {
+		return fmt.Sprintf("sum by (%s) (%s)", strings.Join(target, ", "), expr)
+	}
+
+	source := append([]string{namespaceLabel, serviceLabel}, extra...)
+	for _, rule := range mapping.Rules {
+		if !containsLabel(source, rule.SourceLabel) && rule.SourceLabel != "namespace" && rule.SourceLabel != "service" {
+			source = append(source, rule.SourceLabel)
+		}
+	}
+
+	mapped := fmt.Sprintf("sum by (%s) (%s)", strings.Join(source, ", "), expr)
+	if serviceLabel != "service" {
+		mapped = labelReplace(mapped, "service", "$1", serviceLabel, "(.*)")
+	}
+	if namespaceLabel != "namespace" {
+		mapped = labelReplace(mapped, "namespace", "$1", namespaceLabel, "(.*)")
+	}
+	for _, rule := range mapping.Rules {
+		regex, replacement := rule.Regex, rule.Replacement
+		if regex == "" {
+			regex = "(.*)"
+		}
+		if replacement == "" {
+			replacement = "$1"
+		}
+		mapped = labelReplace(mapped, rule.TargetLabel, replacement, rule.SourceLabel, regex)
+	}
+
+	return fmt.Sprintf("sum by (%s) (%s)", strings.Join(target, ", "), mapped)
+}
+
+func labelReplace(expr, target, replacement, source, regex string) string {
+	return fmt.Sprintf("label_replace(%s, %q, %q, %q, %q)", expr, target, replacement, source, regex)
+}
+
+func containsLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// collectRetries reads the retried requests of a service from the recording rules
+func (c *Collector) collectRetries(ctx context.Context, service v1.Service, metrics *MetricsData) error {
+	retries, err := c.prometheus.Query(ctx, fmt.Sprintf("%s{namespace=%q, service=%q}",
+		RecordRetryRate, service.Namespace, service.Name), time.Now())
+	if err != nil {
+		return err
+	}
+
+	metrics.RetryRate = sumVector(retries)
+	return nil
+}
+
+// correctRetryStorm discounts the retries from the request rate of a sample
+// once requests outnumber first attempts by min_amplification, so the model
+// sizes the service for its demand rather than for the retries its
+// failures cause
+func (c *Collector) correctRetryStorm(key string, sample *MetricsData) {
+	cfg := c.config.RetryStorm
+	if !cfg.Enabled || sample.RequestRate <= 0 || sample.IsImputed(MetricRequestRate) {
+		return
+	}
+
+	firstAttempts := sample.RequestRate - sample.RetryRate
+	if cfg.RetryMetric == "" {
+		var ok bool
+		if firstAttempts, ok = c.inferFirstAttempts(key, sample); !ok {
+			return
+		}
+	}
+	if firstAttempts <= 0 || sample.RequestRate/firstAttempts < cfg.MinAmplification {
+		return
+	}
+
+	sample.RetryAmplification = sample.RequestRate / firstAttempts
+	sample.MeasuredRequestRate = sample.RequestRate
+	sample.RequestRate = firstAttempts
+}
+
+// inferFirstAttempts estimates the first attempts of a failing service
+// without a retry counter. Clients retry failed requests, so a burst of
+// requests that brings no more successful ones is retries: the first
+// attempts are the successful requests at the error rate of the last
+// healthy sample, and never fewer than that sample's requests.
+func (c *Collector) inferFirstAttempts(key string, sample *MetricsData) (float64, bool) {
+	cfg := c.config.RetryStorm
+	if sample.ErrorRate < cfg.MinErrorRate {
+		return 0, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	history := c.metricsStore[key]
+	for i := len(history) - 1; i >= 0; i-- {
+		baseline := history[i]
+		if baseline.MeasuredRequestRate > 0 || baseline.ErrorRate >= cfg.MinErrorRate {
+			continue
+		}
+		if sample.RequestRate <= baseline.RequestRate {
+			return 0, false
+		}
+		successful := sample.RequestRate * (1 - math.Min(sample.ErrorRate, 100)/100)
+		return math.Max(successful/(1-baseline.ErrorRate/100), baseline.RequestRate), true
+	}
+	return 0, false
+}
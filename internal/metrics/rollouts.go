@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RolloutKind is the Argo Rollouts resource, read without its API module
+var RolloutKind = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+
+// RolloutRevisionAnnotation is set by the Argo Rollouts controller on every rollout
+const RolloutRevisionAnnotation = "rollout.argoproj.io/revision"
+
+// Rollout is an Argo Rollout whose pods a service selects
+type Rollout struct {
+	*unstructured.Unstructured
+
+	// Deployment referenced through spec.workloadRef, whose pod template the
+	// rollout runs and whose pods Argo Rollouts takes over; empty for a
+	// rollout with its own template
+	WorkloadRef string
+
+	// Container images of the pod template, comma-separated
+	Images string
+}
+
+// Replicas returns the desired replicas of the rollout and those it runs
+func (r Rollout) Replicas() (desired, current int32) {
+	spec, found, _ := unstructured.NestedInt64(r.Object, "spec", "replicas")
+	if !found {
+		spec = 1
+	}
+	status, _, _ := unstructured.NestedInt64(r.Object, "status", "replicas")
+	return int32(spec), int32(status)
+}
+
+// ReadyReplicas returns the ready replicas of the rollout
+func (r Rollout) ReadyReplicas() int32 {
+	ready, _, _ := unstructured.NestedInt64(r.Object, "status", "readyReplicas")
+	return int32(ready)
+}
+
+// Paused reports whether the rollout is paused, at a canary step or by hand
+func (r Rollout) Paused() bool {
+	paused, _, _ := unstructured.NestedBool(r.Object, "spec", "paused")
+	conditions, _, _ := unstructured.NestedSlice(r.Object, "status", "pauseConditions")
+	return paused || len(conditions) > 0
+}
+
+// AnalysisRunning reports whether a canary step or background analysis of
+// the rollout is pending or running
+func (r Rollout) AnalysisRunning() bool {
+	for _, field := range []string{"currentStepAnalysisRunStatus", "currentBackgroundAnalysisRunStatus"} {
+		run, found, _ := unstructured.NestedMap(r.Object, "status", "canary", field)
+		if !found {
+			continue
+		}
+		switch status, _ := run["status"].(string); status {
+		case "", "Pending", "Running":
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceRollouts returns the Argo Rollouts whose pods a service selects.
+// A rollout referencing a deployment's template through spec.workloadRef
+// is matched on that template. No rollouts are returned when the Argo
+// Rollouts CRDs aren't installed.
+func ServiceRollouts(ctx context.Context, reader client.Reader, service *v1.Service) ([]Rollout, error) {
+	if len(service.Spec.Selector) == 0 {
+		return nil, nil
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(RolloutKind.GroupVersion().WithKind(RolloutKind.Kind + "List"))
+	if err := reader.List(ctx, list, client.InNamespace(service.Namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	selector := labels.SelectorFromSet(service.Spec.Selector)
+	var rollouts []Rollout
+	for i := range list.Items {
+		rollout := Rollout{Unstructured: &list.Items[i]}
+
+		template, _, _ := unstructured.NestedMap(rollout.Object, "spec", "template")
+		if kind, _, _ := unstructured.NestedString(rollout.Object, "spec", "workloadRef", "kind"); kind == "Deployment" {
+			rollout.WorkloadRef, _, _ = unstructured.NestedString(rollout.Object, "spec", "workloadRef", "name")
+			deployment := &appsv1.Deployment{}
+			if err := reader.Get(ctx, types.NamespacedName{Namespace: service.Namespace, Name: rollout.WorkloadRef}, deployment); err != nil {
+				if client.IgnoreNotFound(err) == nil {
+					continue
+				}
+				return nil, err
+			}
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deployment.Spec.Template)
+			if err != nil {
+				return nil, err
+			}
+			template = content
+		}
+
+		podLabels, _, _ := unstructured.NestedStringMap(template, "metadata", "labels")
+		if !selector.Matches(labels.Set(podLabels)) {
+			continue
+		}
+		containers, _, _ := unstructured.NestedSlice(template, "spec", "containers")
+		images := make([]string, 0, len(containers))
+		for _, container := range containers {
+			if image, ok := container.(map[string]interface{})["image"].(string); ok {
+				images = append(images, image)
+			}
+		}
+		rollout.Images = strings.Join(images, ",")
+		rollouts = append(rollouts, rollout)
+	}
+	return rollouts, nil
+}
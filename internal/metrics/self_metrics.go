@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Metric sources the collector scrapes
+const (
+	SourceKubernetes = "kubernetes"  // registered services
+	SourceResource   = "resource"    // pod CPU and memory
+	SourceNginx      = "nginx"       // ingress controller request metrics
+	SourcePrometheus = "prometheus"  // request metrics from recording rules
+	SourceLLM        = "llm"         // LLM serving metrics from recording rules
+	SourceLatencySLO = "latency_slo" // latency SLO burn rates from recording rules
+	SourceThrottling = "throttling"  // CPU throttling from recording rules
+	SourceMesh       = "mesh"        // mesh-ejected endpoints from recording rules
+	SourceRetries    = "retries"     // retried requests from recording rules
+	SourceThermal    = "thermal"     // node temperature and power from recording rules
+	SourceSystem     = "system"      // network and I/O bandwidth
+	SourceDeployment = "deployment"  // replica counts
+	SourceSimulated  = "simulated"   // synthetic traffic for local development
+)
+
+var (
+	scrapeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "hydra_route_metrics_collection_duration_seconds",
+		Help:    "Duration of metrics scrapes by source.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"source"})
+
+	scrapeErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hydra_route_metrics_collection_errors_total",
+		Help: "Failed metrics scrapes by source.",
+	}, []string{"source"})
+
+	lastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hydra_route_metrics_source_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful scrape by source.",
+	}, []string{"source"})
+
+	sampleStaleness = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hydra_route_metrics_staleness_seconds",
+		Help: "Age of the oldest source data in the latest sample of a service.",
+	}, []string{"service", "namespace"})
+
+	collectionTargets = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "hydra_route_metrics_collection_targets",
+		Help: "Services registered for metrics collection.",
+	})
+
+	circuitOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hydra_route_metrics_endpoint_circuit_open",
+		Help: "Whether scrapes of a metrics endpoint are skipped after repeated failures (1) or not (0).",
+	}, []string{"endpoint"})
+
+	consecutiveFailures = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hydra_route_metrics_endpoint_consecutive_failures",
+		Help: "Failed scrapes of a metrics endpoint since its last success.",
+	}, []string{"endpoint"})
+
+	backoffSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hydra_route_metrics_endpoint_backoff_seconds",
+		Help: "Current wait between recovery probes of a metrics endpoint; 0 while its circuit is closed.",
+	}, []string{"endpoint"})
+
+	sourceSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hydra_route_metrics_endpoint_skipped_scrapes_total",
+		Help: "Scrapes skipped while the circuit of a metrics endpoint was open.",
+	}, []string{"endpoint"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(scrapeDuration, scrapeErrors, lastSuccess, sampleStaleness, collectionTargets,
+		circuitOpen, consecutiveFailures, backoffSeconds, sourceSkipped)
+}
+
+// observeScrape records the outcome of a single scrape
+func observeScrape(source string, start time.Time, err error) {
+	now := time.Now()
+	scrapeDuration.WithLabelValues(source).Observe(now.Sub(start).Seconds())
+	if err != nil {
+		scrapeErrors.WithLabelValues(source).Inc()
+		return
+	}
+	lastSuccess.WithLabelValues(source).Set(float64(now.Unix()))
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Simulator generates synthetic traffic for services from configured curves,
+// so the controller can run against a local cluster without nginx or
+// Prometheus, and load tests can drive the scaler without a cluster
+type Simulator struct {
+	cfg config.SimulatedConfig
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewSimulator creates a simulator for the curves of the configuration
+func NewSimulator(cfg config.SimulatedConfig) *Simulator {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Simulator{cfg: cfg, rand: rand.New(rand.NewSource(seed))}
+}
+
+// curve returns the curve of a service: its own entry with unset fields
+// taken from the default curve
+func (s *Simulator) curve(key string) config.TrafficCurveConfig {
+	curve := s.cfg.Default
+	override, ok := s.cfg.Services[key]
+	if !ok {
+		return curve
+	}
+
+	if override.Shape != "" {
+		curve.Shape = override.Shape
+	}
+	for _, field := range []struct{ value, target *float64 }{
+		{&override.Base, &curve.Base},
+		{&override.Amplitude, &curve.Amplitude},
+		{&override.Noise, &curve.Noise},
+		{&override.ResponseTime, &curve.ResponseTime},
+		{&override.ErrorRate, &curve.ErrorRate},
+		{&override.RequestsPerReplica, &curve.RequestsPerReplica},
+		{&override.MemoryUtilization, &curve.MemoryUtilization},
+	} {
+		if *field.value != 0 {
+			*field.target = *field.value
+		}
+	}
+	if override.Period != 0 {
+		curve.Period = override.Period
+	}
+	return curve
+}
+
+// simulatesResources reports whether CPU and memory of a service are
+// simulated rather than read from the metrics API
+func (s *Simulator) simulatesResources(key string) bool {
+	return s.curve(key).RequestsPerReplica > 0
+}
+
+// Fill sets the request metrics of a sample, and its resource utilization
+// when the curve has a per-replica capacity. It runs after the replica
+// counts are known: load beyond capacity raises response time in proportion
+// and fails the share of requests the replicas can't serve.
+func (s *Simulator) Fill(key string, metrics *MetricsData) {
+	curve := s.curve(key)
+
+	rate := requestRate(curve, metrics.Timestamp)
+	if curve.Noise > 0 {
+		s.mu.Lock()
+		rate *= 1 + curve.Noise*s.rand.NormFloat64()
+		s.mu.Unlock()
+	}
+	rate = math.Max(rate, 0)
+
+	metrics.RequestRate = rate
+	metrics.ResponseTime = curve.ResponseTime
+	metrics.ErrorRate = curve.ErrorRate
+
+	if curve.RequestsPerReplica <= 0 {
+		return
+	}
+
+	replicas := metrics.CurrentReplicas
+	if replicas < 1 {
+		replicas = 1
+	}
+	load := rate / (float64(replicas) * curve.RequestsPerReplica)
+	metrics.CPUUtilization = math.Min(load, 1) * 100
+	metrics.MemoryUtilization = curve.MemoryUtilization
+	if load > 1 {
+		metrics.ResponseTime *= load
+		metrics.ErrorRate = math.Min(100, metrics.ErrorRate+(1-1/load)*100)
+	}
+	metrics.Concurrency = rate * metrics.ResponseTime / 1000
+}
+
+// requestRate evaluates a curve at a point in time. Cycles are aligned to the
+// Unix epoch, so every replica of the controller sees the same traffic.
+func requestRate(curve config.TrafficCurveConfig, t time.Time) float64 {
+	if curve.Period <= 0 {
+		return curve.Base
+	}
+	phase := math.Mod(float64(t.UnixNano()), float64(curve.Period)) / float64(curve.Period)
+
+	switch curve.Shape {
+	case "sine":
+		// Halfway between base and peak at the start of a cycle
+		return curve.Base + curve.Amplitude*(1+math.Sin(2*math.Pi*phase))/2
+	case "ramp":
+		return curve.Base + curve.Amplitude*phase
+	case "step":
+		if phase >= 0.5 {
+			return curve.Base + curve.Amplitude
+		}
+		return curve.Base
+	case "spike":
+		if phase < 0.1 {
+			return curve.Base + curve.Amplitude
+		}
+		return curve.Base
+	default:
+		return curve.Base
+	}
+}
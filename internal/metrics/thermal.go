@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// NodeThermal is the latest temperature and power draw read for a node
+type NodeThermal struct {
+	Temperature float64
+	Power       float64
+}
+
+// nodeThermalExpr returns the expression recording a node metric by node
+// name, taken from the configured label without any :port suffix
+func nodeThermalExpr(aggregation, metric, label string) string {
+	return fmt.Sprintf("%s by (node) (%s)", aggregation, labelReplace(metric, "node", "$1", label, "([^:]+)(:[0-9]+)?"))
+}
+
+// readNodeThermal reads the temperature and power draw of every node. It
+// runs once per cycle, since new replicas may land on any node.
+func (c *Collector) readNodeThermal(ctx context.Context) (map[string]NodeThermal, error) {
+	now := time.Now()
+	temperatures, err := c.prometheus.Query(ctx, RecordNodeTemperature, now)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]NodeThermal, len(temperatures))
+	for _, sample := range temperatures {
+		if node := string(sample.Metric["node"]); node != "" && !math.IsNaN(float64(sample.Value)) {
+			nodes[node] = NodeThermal{Temperature: float64(sample.Value)}
+		}
+	}
+
+	if c.config.Thermal.PowerMetric != "" {
+		power, err := c.prometheus.Query(ctx, RecordNodePower, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, sample := range power {
+			if node := string(sample.Metric["node"]); node != "" && !math.IsNaN(float64(sample.Value)) {
+				reading := nodes[node]
+				reading.Power = float64(sample.Value)
+				nodes[node] = reading
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// constrained reports whether a node runs at or above the configured
+// temperature or power draw
+func (c *Collector) constrained(reading NodeThermal) bool {
+	thermal := c.config.Thermal
+	return reading.Temperature >= thermal.MaxTemperature || (thermal.MaxPower > 0 && reading.Power >= thermal.MaxPower)
+}
+
+// collectThermal notes the hottest node and largest power draw under the
+// pods of a service, and how many of its pods run on constrained nodes
+func (c *Collector) collectThermal(ctx context.Context, service v1.Service, metrics *MetricsData, nodes map[string]NodeThermal) error {
+	pods, err := c.getServicePods(ctx, service)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		reading, ok := nodes[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		metrics.NodeTemperature = math.Max(metrics.NodeTemperature, reading.Temperature)
+		metrics.NodePower = math.Max(metrics.NodePower, reading.Power)
+		if c.constrained(reading) {
+			metrics.ThermalConstrainedPods++
+		}
+	}
+	return nil
+}
+
+// ThermallyConstrainedNodes returns the nodes that ran at or above the
+// configured temperature or power draw in the last collection cycle, in
+// sorted order
+func (c *Collector) ThermallyConstrainedNodes() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var nodes []string
+	for node, reading := range c.nodeThermal {
+		if c.constrained(reading) {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
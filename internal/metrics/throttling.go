@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// collectThrottling reads the throttled share of CFS periods of the pods
+// behind a service and corrects its CPU utilization with it. Pods on
+// Windows nodes, which have no CFS quota, are left out.
+func (c *Collector) collectThrottling(ctx context.Context, service v1.Service, metrics *MetricsData) error {
+	pods, err := c.getBackendPods(ctx, service)
+	if err != nil || len(pods) == 0 {
+		return err
+	}
+	pods, windows := c.splitWindowsPods(ctx, pods)
+	if len(windows) > 0 {
+		c.noteUnsupported(fmt.Sprintf("%s/%s", service.Namespace, service.Name), "cpu_throttling")
+	}
+	if len(pods) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, regexp.QuoteMeta(pod.Name))
+	}
+	ratio, err := c.prometheus.Query(ctx, fmt.Sprintf("avg(%s{namespace=%q, pod=~%q})",
+		RecordCPUThrottling, service.Namespace, strings.Join(names, "|")), time.Now())
+	if err != nil {
+		return err
+	}
+
+	metrics.CPUThrottling = sumVector(ratio) * 100 // Percentage
+	c.correctThrottling(metrics)
+	return nil
+}
+
+// correctThrottling raises the CPU utilization of a heavily throttled
+// service. A container throttled in a share of its CFS periods was denied
+// CPU for about that share of the time, so its demand is estimated as the
+// measured usage divided by the unthrottled share, up to max_correction.
+func (c *Collector) correctThrottling(metrics *MetricsData) {
+	cfg := c.config.CPUThrottling
+	if metrics.CPUThrottling < cfg.Threshold || metrics.CPUUtilization <= 0 {
+		return
+	}
+
+	correction := math.Min(1/(1-math.Min(metrics.CPUThrottling, 99)/100), cfg.MaxCorrection)
+	metrics.MeasuredCPUUtilization = metrics.CPUUtilization
+	metrics.CPUUtilization *= correction
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// WindowAggregate summarizes key metrics of a service over a trailing window
+type WindowAggregate struct {
+	Window          time.Duration `json:"window"`
+	CPUMean         float64       `json:"cpu_mean"`
+	CPUMax          float64       `json:"cpu_max"`
+	MemoryMean      float64       `json:"memory_mean"`
+	MemoryMax       float64       `json:"memory_max"`
+	RequestRateMean float64       `json:"request_rate_mean"`
+	RequestRateMax  float64       `json:"request_rate_max"`
+}
+
+// aggregateWindows computes the configured window aggregates for a new
+// sample from the stored history of its service, shortest window first
+func (c *Collector) aggregateWindows(key string, sample *MetricsData) {
+	if len(c.config.AggregationWindows) == 0 {
+		return
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	sample.Windows = windowAggregates(c.config.AggregationWindows, c.metricsStore[key], sample)
+}
+
+// windowAggregates computes the aggregates of a sample over each window from
+// the samples preceding it, which must be in collection order
+func windowAggregates(configured []time.Duration, history []*MetricsData, sample *MetricsData) []WindowAggregate {
+	windows := append([]time.Duration(nil), configured...)
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+
+	aggregates := make([]WindowAggregate, 0, len(windows))
+	for _, window := range windows {
+		cutoff := sample.Timestamp.Add(-window)
+		agg := WindowAggregate{
+			Window:          window,
+			CPUMax:          sample.CPUUtilization,
+			MemoryMax:       sample.MemoryUtilization,
+			RequestRateMax:  sample.RequestRate,
+			CPUMean:         sample.CPUUtilization,
+			MemoryMean:      sample.MemoryUtilization,
+			RequestRateMean: sample.RequestRate,
+		}
+		count := 1.0
+
+		// History is stored in collection order, so walk back until the cutoff
+		for i := len(history) - 1; i >= 0 && history[i].Timestamp.After(cutoff); i-- {
+			m := history[i]
+			agg.CPUMean += m.CPUUtilization
+			agg.MemoryMean += m.MemoryUtilization
+			agg.RequestRateMean += m.RequestRate
+			agg.CPUMax = math.Max(agg.CPUMax, m.CPUUtilization)
+			agg.MemoryMax = math.Max(agg.MemoryMax, m.MemoryUtilization)
+			agg.RequestRateMax = math.Max(agg.RequestRateMax, m.RequestRate)
+			count++
+		}
+
+		agg.CPUMean /= count
+		agg.MemoryMean /= count
+		agg.RequestRateMean /= count
+		aggregates = append(aggregates, agg)
+	}
+	return aggregates
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// detectZones records the zones the pods of a service can spread over. Only
+// services whose pod template spreads them by the topology key are counted;
+// the zones are those of schedulable nodes that match the template's node
+// selector.
+func (c *Collector) detectZones(ctx context.Context, service v1.Service, sample *MetricsData) error {
+	deployments, err := c.getServiceDeployments(ctx, service)
+	if err != nil {
+		return err
+	}
+
+	topologyKey := c.config.ZoneSpread.TopologyKey
+	var selectors []labels.Selector
+	for _, deployment := range deployments {
+		if spreadsBy(deployment.Spec.Template.Spec, topologyKey) {
+			selectors = append(selectors, labels.SelectorFromSet(deployment.Spec.Template.Spec.NodeSelector))
+		}
+	}
+	if len(selectors) == 0 {
+		return nil
+	}
+
+	nodeList := &v1.NodeList{}
+	if err := c.client.List(ctx, nodeList); err != nil {
+		return err
+	}
+
+	zones := make(map[string]bool)
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		zone, ok := node.Labels[topologyKey]
+		if !ok || isCordoned(node) {
+			continue
+		}
+		for _, selector := range selectors {
+			if selector.Matches(labels.Set(node.Labels)) {
+				zones[zone] = true
+				break
+			}
+		}
+	}
+	sample.Zones = len(zones)
+	return nil
+}
+
+// spreadsBy reports whether a pod spec has a topology spread constraint on a key
+func spreadsBy(spec v1.PodSpec, topologyKey string) bool {
+	for _, constraint := range spec.TopologySpreadConstraints {
+		if constraint.TopologyKey == topologyKey {
+			return true
+		}
+	}
+	return false
+}
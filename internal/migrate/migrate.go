@@ -0,0 +1,295 @@
+// Package migrate converts existing HorizontalPodAutoscaler and KEDA
+// ScaledObject resources into equivalent HydraRoutePolicy resources
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydrav1alpha1 "github.com/hydraai/hydra-route/api/v1alpha1"
+	"github.com/hydraai/hydra-route/internal/controller"
+)
+
+// ScaledObjectGVK identifies KEDA ScaledObjects
+var ScaledObjectGVK = schema.GroupVersionKind{Group: "keda.sh", Version: "v1alpha1", Kind: "ScaledObject"}
+
+// Source is the autoscaling configuration read from an HPA or ScaledObject
+type Source struct {
+	Kind                    string
+	Namespace               string
+	Name                    string
+	TargetKind              string
+	TargetName              string
+	MinReplicas             *int32
+	MaxReplicas             int32
+	TargetCPUUtilization    *int32
+	TargetMemoryUtilization *int32
+	Behavior                *autoscalingv2.HorizontalPodAutoscalerBehavior
+
+	// Settings that have no HydraRoutePolicy equivalent
+	Unsupported []string
+}
+
+// Result is a generated policy with the sources it was derived from
+type Result struct {
+	Policy   *hydrav1alpha1.HydraRoutePolicy
+	Sources  []Source
+	Warnings []string
+}
+
+// FromHPA reads the scaling settings of a HorizontalPodAutoscaler
+func FromHPA(hpa *autoscalingv2.HorizontalPodAutoscaler) Source {
+	source := Source{
+		Kind:        "HorizontalPodAutoscaler",
+		Namespace:   hpa.Namespace,
+		Name:        hpa.Name,
+		TargetKind:  hpa.Spec.ScaleTargetRef.Kind,
+		TargetName:  hpa.Spec.ScaleTargetRef.Name,
+		MinReplicas: hpa.Spec.MinReplicas,
+		MaxReplicas: hpa.Spec.MaxReplicas,
+	}
+
+	for _, metric := range hpa.Spec.Metrics {
+		if metric.Type != autoscalingv2.ResourceMetricSourceType || metric.Resource == nil ||
+			metric.Resource.Target.Type != autoscalingv2.UtilizationMetricType || metric.Resource.Target.AverageUtilization == nil {
+			source.Unsupported = append(source.Unsupported, fmt.Sprintf("metric of type %s", metric.Type))
+			continue
+		}
+
+		utilization := *metric.Resource.Target.AverageUtilization
+		switch metric.Resource.Name {
+		case v1.ResourceCPU:
+			source.TargetCPUUtilization = &utilization
+		case v1.ResourceMemory:
+			source.TargetMemoryUtilization = &utilization
+		default:
+			source.Unsupported = append(source.Unsupported, fmt.Sprintf("resource metric %s", metric.Resource.Name))
+		}
+	}
+	source.Behavior = hpa.Spec.Behavior
+
+	return source
+}
+
+// FromScaledObject reads the scaling settings of a KEDA ScaledObject
+func FromScaledObject(obj *unstructured.Unstructured) (Source, error) {
+	source := Source{
+		Kind:        "ScaledObject",
+		Namespace:   obj.GetNamespace(),
+		Name:        obj.GetName(),
+		TargetKind:  "Deployment",
+		MaxReplicas: 100, // KEDA default
+	}
+
+	if kind, found, _ := unstructured.NestedString(obj.Object, "spec", "scaleTargetRef", "kind"); found && kind != "" {
+		source.TargetKind = kind
+	}
+	name, found, err := unstructured.NestedString(obj.Object, "spec", "scaleTargetRef", "name")
+	if err != nil || !found {
+		return source, fmt.Errorf("scaledobject %s/%s has no scale target", source.Namespace, source.Name)
+	}
+	source.TargetName = name
+
+	if min, found, _ := unstructured.NestedInt64(obj.Object, "spec", "minReplicaCount"); found {
+		value := int32(min)
+		source.MinReplicas = &value
+	}
+	if max, found, _ := unstructured.NestedInt64(obj.Object, "spec", "maxReplicaCount"); found {
+		source.MaxReplicas = int32(max)
+	}
+
+	triggers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "triggers")
+	for _, raw := range triggers {
+		trigger, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		triggerType, _, _ := unstructured.NestedString(trigger, "type")
+		metricType, _, _ := unstructured.NestedString(trigger, "metricType")
+		if metricType == "" {
+			// Older KEDA versions put the metric type in the trigger metadata
+			metricType, _, _ = unstructured.NestedString(trigger, "metadata", "type")
+		}
+		value, _, _ := unstructured.NestedString(trigger, "metadata", "value")
+
+		if (triggerType != "cpu" && triggerType != "memory") || metricType != "Utilization" {
+			source.Unsupported = append(source.Unsupported, fmt.Sprintf("trigger of type %s", triggerType))
+			continue
+		}
+		utilization, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			source.Unsupported = append(source.Unsupported, fmt.Sprintf("%s trigger with value %q", triggerType, value))
+			continue
+		}
+
+		target := int32(utilization)
+		if triggerType == "cpu" {
+			source.TargetCPUUtilization = &target
+		} else {
+			source.TargetMemoryUtilization = &target
+		}
+	}
+
+	return source, nil
+}
+
+// Generate scans a namespace (all namespaces when empty) for HPAs and KEDA
+// ScaledObjects and returns a HydraRoutePolicy for every ingress that routes
+// to a scaled Deployment. The cluster is only read.
+func Generate(ctx context.Context, c client.Reader, namespace string) ([]Result, []string, error) {
+	var sources []Source
+	var warnings []string
+
+	hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := c.List(ctx, hpas, client.InNamespace(namespace)); err != nil {
+		return nil, nil, fmt.Errorf("failed to list horizontal pod autoscalers: %w", err)
+	}
+	for i := range hpas.Items {
+		sources = append(sources, FromHPA(&hpas.Items[i]))
+	}
+
+	scaledObjects := &unstructured.UnstructuredList{}
+	scaledObjects.SetGroupVersionKind(ScaledObjectGVK.GroupVersion().WithKind(ScaledObjectGVK.Kind + "List"))
+	if err := c.List(ctx, scaledObjects, client.InNamespace(namespace)); err != nil {
+		if !meta.IsNoMatchError(err) {
+			return nil, nil, fmt.Errorf("failed to list scaled objects: %w", err)
+		}
+		warnings = append(warnings, "KEDA is not installed; only HorizontalPodAutoscalers were scanned")
+	}
+	for i := range scaledObjects.Items {
+		source, err := FromScaledObject(&scaledObjects.Items[i])
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			continue
+		}
+		sources = append(sources, source)
+	}
+
+	byIngress := make(map[string]*Result)
+	for _, source := range sources {
+		ref := fmt.Sprintf("%s %s/%s", source.Kind, source.Namespace, source.Name)
+		if source.TargetKind != "Deployment" {
+			warnings = append(warnings, fmt.Sprintf("%s scales a %s; only Deployments are supported", ref, source.TargetKind))
+			continue
+		}
+
+		ingresses, err := ingressesForDeployment(ctx, c, source.Namespace, source.TargetName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(ingresses) == 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: no ingress routes to deployment %s", ref, source.TargetName))
+			continue
+		}
+
+		for _, ingress := range ingresses {
+			key := fmt.Sprintf("%s/%s", source.Namespace, ingress)
+			if existing, ok := byIngress[key]; ok {
+				existing.Sources = append(existing.Sources, source)
+				existing.Warnings = append(existing.Warnings, fmt.Sprintf("%s also scales a service of this ingress; its settings were not merged", ref))
+				continue
+			}
+			byIngress[key] = &Result{
+				Policy:   policyFor(source, ingress),
+				Sources:  []Source{source},
+				Warnings: sourceWarnings(source),
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(byIngress))
+	for key := range byIngress {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	results := make([]Result, 0, len(keys))
+	for _, key := range keys {
+		results = append(results, *byIngress[key])
+	}
+	return results, warnings, nil
+}
+
+// policyFor maps a source's settings onto a policy for an ingress
+func policyFor(source Source, ingress string) *hydrav1alpha1.HydraRoutePolicy {
+	policy := &hydrav1alpha1.HydraRoutePolicy{}
+	policy.APIVersion = hydrav1alpha1.GroupVersion.String()
+	policy.Kind = "HydraRoutePolicy"
+	policy.Name = ingress
+	policy.Namespace = source.Namespace
+	policy.Spec.IngressName = ingress
+
+	// HydraRoute keeps at least one replica; HPA defaults minReplicas to 1 and KEDA to 0
+	minReplicas := int32(1)
+	if source.MinReplicas != nil && *source.MinReplicas > 1 {
+		minReplicas = *source.MinReplicas
+	}
+	maxReplicas := source.MaxReplicas
+	policy.Spec.MinReplicas = &minReplicas
+	policy.Spec.MaxReplicas = &maxReplicas
+	policy.Spec.TargetCPUUtilization = source.TargetCPUUtilization
+	policy.Spec.TargetMemoryUtilization = source.TargetMemoryUtilization
+	policy.Spec.Behavior = source.Behavior
+
+	return policy
+}
+
+func sourceWarnings(source Source) []string {
+	var warnings []string
+	if source.MinReplicas != nil && *source.MinReplicas < 1 {
+		warnings = append(warnings, fmt.Sprintf("%s %s scales to zero; HydraRoute keeps at least one replica", source.Kind, source.Name))
+	}
+	for _, unsupported := range source.Unsupported {
+		warnings = append(warnings, fmt.Sprintf("%s %s: %s is not converted", source.Kind, source.Name, unsupported))
+	}
+	return warnings
+}
+
+// ingressesForDeployment returns the ingresses routing to a service that selects the deployment's pods
+func ingressesForDeployment(ctx context.Context, c client.Reader, namespace, name string) ([]string, error) {
+	deployment := &appsv1.Deployment{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, deployment); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	podLabels := labels.Set(deployment.Spec.Template.Labels)
+
+	services := &v1.ServiceList{}
+	if err := c.List(ctx, services, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	selected := make(map[string]bool)
+	for _, service := range services.Items {
+		if len(service.Spec.Selector) > 0 && labels.SelectorFromSet(service.Spec.Selector).Matches(podLabels) {
+			selected[service.Name] = true
+		}
+	}
+
+	ingresses := &networkingv1.IngressList{}
+	if err := c.List(ctx, ingresses, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	var names []string
+	for i := range ingresses.Items {
+		for _, service := range controller.BackendServices(&ingresses.Items[i]) {
+			if selected[service] {
+				names = append(names, ingresses.Items[i].Name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
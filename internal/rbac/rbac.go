@@ -0,0 +1,198 @@
+// Package rbac derives the permissions the controller needs from its
+// configuration and renders them as least-privilege RBAC manifests
+package rbac
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Verb sets
+var (
+	read      = []string{"get", "list", "watch"}
+	readWrite = []string{"get", "list", "watch", "update", "patch"}
+)
+
+// Grant is an access the controller's service account needs
+type Grant struct {
+	// Namespace the access is needed in; empty for every namespace and for
+	// cluster-scoped resources
+	Namespace string
+
+	Group       string
+	Resource    string
+	Subresource string
+	Verbs       []string
+
+	// What the access is for
+	Reason string
+}
+
+// Options identifies the controller the permissions are for
+type Options struct {
+	// Name of the generated roles and bindings
+	Name string
+
+	// Namespace the controller runs in, holding its service account and
+	// leader election lease
+	Namespace string
+
+	// Service account of the controller
+	ServiceAccountName string
+}
+
+// Required returns the grants the controller needs with a configuration.
+// Access to the services being scaled is limited to
+// general.watch_namespaces when set, and cluster-wide otherwise; features
+// that are disabled get nothing.
+func Required(cfg *config.Config, opts Options) []Grant {
+	watched := cfg.General.WatchNamespaces
+	if len(watched) == 0 {
+		watched = []string{""}
+	}
+
+	var grants []Grant
+	add := func(namespaces []string, group, resource, subresource string, verbs []string, reason string) {
+		for _, namespace := range namespaces {
+			grants = append(grants, Grant{
+				Namespace:   namespace,
+				Group:       group,
+				Resource:    resource,
+				Subresource: subresource,
+				Verbs:       verbs,
+				Reason:      reason,
+			})
+		}
+	}
+	cluster := []string{""}
+
+	add(watched, "networking.k8s.io", "ingresses", "", readWrite, "watch and annotate ingresses")
+	add(watched, "apps", "deployments", "", readWrite, "scale deployments")
+	if cfg.General.ServiceTargets {
+		add(watched, "", "services", "", readWrite, "discover backend services and finalize services enabled without an ingress")
+	} else {
+		add(watched, "", "services", "", read, "discover backend services")
+	}
+	add(watched, "discovery.k8s.io", "endpointslices", "", read, "find backend pods")
+	add(watched, "metrics.k8s.io", "pods", "", []string{"get", "list"}, "read pod usage from metrics-server")
+	add(watched, "", "events", "", []string{"create", "patch"}, "record scaling events")
+	if cfg.Metrics.BatchContention.Enabled {
+		add(cluster, "", "pods", "", read, "collect resource metrics and find Job pods on shared nodes")
+	} else {
+		add(watched, "", "pods", "", read, "collect resource metrics")
+	}
+	add(cluster, "", "nodes", "", read, "read node types, cordons and zones")
+	if cfg.General.NamespaceOptIn {
+		add(cluster, "", "namespaces", "", read, "find namespaces that enroll their ingresses")
+	}
+
+	if cfg.General.RecordRecommendations {
+		add(watched, "hydra-route.ai", "scalingrecommendations", "",
+			[]string{"get", "list", "watch", "create", "update", "patch", "delete"}, "record recommendations")
+	}
+	if cfg.General.EnablePolicies {
+		add(watched, "hydra-route.ai", "hydraroutepolicies", "", read, "read policies")
+		add(watched, "hydra-route.ai", "hydraroutepolicies", "status", []string{"get", "update", "patch"}, "report policy status")
+	}
+	if cfg.General.ArgoRollouts.Enabled {
+		add(watched, "argoproj.io", "rollouts", "", []string{"get", "list", "patch"}, "scale Argo Rollouts")
+	}
+	if cfg.Scaling.MeshEjection.RelaxedMaxEjectionPercent > 0 {
+		add(watched, "networking.istio.io", "destinationrules", "", []string{"get", "list", "update"}, "relax outlier detection during scale-ups")
+	}
+
+	own := []string{opts.Namespace}
+	add(own, "coordination.k8s.io", "leases", "", []string{"get", "list", "watch", "create", "update", "patch", "delete"}, "leader election")
+	if watched[0] != "" && !contains(watched, opts.Namespace) {
+		add(own, "", "events", "", []string{"create", "patch"}, "record leader election events")
+	}
+	if registry := cfg.Scaling.AIModel.Registry; registry.Backend == "configmap" {
+		add([]string{registry.Namespace}, "", "configmaps", "", []string{"get", "list", "watch", "create", "update"}, "store model versions")
+	}
+	if report := cfg.Scaling.SummaryReport; report.Enabled {
+		add([]string{report.Namespace}, "hydra-route.ai", "scalingreports", "", []string{"get", "list", "watch", "create", "delete"}, "write summary reports")
+	}
+	if monitors := cfg.General.ServiceMonitors; monitors.Enabled {
+		for _, resource := range []string{"servicemonitors", "podmonitors"} {
+			add([]string{monitors.Namespace}, "monitoring.coreos.com", resource, "", []string{"get", "list", "create", "update", "delete"}, "manage Prometheus monitors")
+		}
+	}
+	return grants
+}
+
+// Manifests renders grants as the controller's ServiceAccount, a
+// ClusterRole and ClusterRoleBinding for the cluster-wide grants, and a Role
+// and RoleBinding for each namespace with grants of its own
+func Manifests(grants []Grant, opts Options) []runtime.Object {
+	objects := []runtime.Object{&corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+		ObjectMeta: metav1.ObjectMeta{Name: opts.ServiceAccountName, Namespace: opts.Namespace},
+	}}
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: opts.ServiceAccountName, Namespace: opts.Namespace}}
+
+	rules := make(map[string][]rbacv1.PolicyRule)
+	for _, grant := range grants {
+		resource := grant.Resource
+		if grant.Subresource != "" {
+			resource += "/" + grant.Subresource
+		}
+		rules[grant.Namespace] = append(rules[grant.Namespace], rbacv1.PolicyRule{
+			APIGroups: []string{grant.Group},
+			Resources: []string{resource},
+			Verbs:     grant.Verbs,
+		})
+	}
+
+	if clusterRules, ok := rules[""]; ok {
+		objects = append(objects,
+			&rbacv1.ClusterRole{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.Name},
+				Rules:      clusterRules,
+			},
+			&rbacv1.ClusterRoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.Name},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: opts.Name},
+				Subjects:   subjects,
+			})
+	}
+
+	namespaces := make([]string, 0, len(rules))
+	for namespace := range rules {
+		if namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		objects = append(objects,
+			&rbacv1.Role{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.Name, Namespace: namespace},
+				Rules:      rules[namespace],
+			},
+			&rbacv1.RoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.Name, Namespace: namespace},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: opts.Name},
+				Subjects:   subjects,
+			})
+	}
+	return objects
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
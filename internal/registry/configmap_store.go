@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RegistryLabel marks ConfigMaps that hold model versions for a registry
+	RegistryLabel = "hydra-route.ai/model-registry"
+
+	// VersionAnnotation records the model version held by a ConfigMap
+	VersionAnnotation = "hydra-route.ai/model-version"
+
+	// StageAnnotation mirrors the stage of the model version for kubectl users
+	StageAnnotation = "hydra-route.ai/model-stage"
+
+	configMapDataKey = "version.json"
+)
+
+// ConfigMapStore keeps one ConfigMap per model version in a namespace.
+// Reads go through an uncached reader so the controller does not need to
+// watch every ConfigMap in the cluster.
+type ConfigMapStore struct {
+	reader    client.Reader
+	writer    client.Writer
+	namespace string
+	name      string
+}
+
+// NewConfigMapStore creates a ConfigMap-backed store
+func NewConfigMapStore(reader client.Reader, writer client.Writer, namespace, name string) *ConfigMapStore {
+	return &ConfigMapStore{
+		reader:    reader,
+		writer:    writer,
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+// List returns every model version in the registry
+func (c *ConfigMapStore) List(ctx context.Context) ([]*ModelVersion, error) {
+	list := &v1.ConfigMapList{}
+	if err := c.reader.List(ctx, list, client.InNamespace(c.namespace), client.MatchingLabels{RegistryLabel: c.name}); err != nil {
+		return nil, fmt.Errorf("failed to list registry configmaps: %w", err)
+	}
+
+	var versions []*ModelVersion
+	for i := range list.Items {
+		mv, err := decodeConfigMap(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, mv)
+	}
+
+	return versions, nil
+}
+
+// Get returns a single model version
+func (c *ConfigMapStore) Get(ctx context.Context, version string) (*ModelVersion, error) {
+	cm := &v1.ConfigMap{}
+	key := types.NamespacedName{Namespace: c.namespace, Name: c.configMapName(version)}
+	if err := c.reader.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return decodeConfigMap(cm)
+}
+
+// Put creates or updates the ConfigMap for a model version
+func (c *ConfigMapStore) Put(ctx context.Context, mv *ModelVersion) error {
+	data, err := json.Marshal(mv)
+	if err != nil {
+		return fmt.Errorf("failed to marshal model version: %w", err)
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.configMapName(mv.Version),
+			Namespace: c.namespace,
+			Labels:    map[string]string{RegistryLabel: c.name},
+			Annotations: map[string]string{
+				VersionAnnotation: mv.Version,
+				StageAnnotation:   string(mv.Stage),
+			},
+		},
+		Data: map[string]string{configMapDataKey: string(data)},
+	}
+
+	existing := &v1.ConfigMap{}
+	err = c.reader.Get(ctx, types.NamespacedName{Namespace: c.namespace, Name: cm.Name}, existing)
+	if apierrors.IsNotFound(err) {
+		return c.writer.Create(ctx, cm)
+	}
+	if err != nil {
+		return err
+	}
+
+	cm.ResourceVersion = existing.ResourceVersion
+	return c.writer.Update(ctx, cm)
+}
+
+// configMapName derives a DNS-compatible ConfigMap name from a version
+func (c *ConfigMapStore) configMapName(version string) string {
+	sanitized := strings.ToLower(strings.NewReplacer(".", "-", "+", "-", "_", "-").Replace(version))
+	return fmt.Sprintf("%s-%s", c.name, sanitized)
+}
+
+func decodeConfigMap(cm *v1.ConfigMap) (*ModelVersion, error) {
+	raw, ok := cm.Data[configMapDataKey]
+	if !ok {
+		return nil, fmt.Errorf("configmap %s/%s has no %s key", cm.Namespace, cm.Name, configMapDataKey)
+	}
+
+	mv := &ModelVersion{}
+	if err := json.Unmarshal([]byte(raw), mv); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal configmap %s/%s: %w", cm.Namespace, cm.Name, err)
+	}
+	return mv, nil
+}
@@ -0,0 +1,499 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/hydraai/hydra-route/internal/scaler"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Stage is the rollout stage of a model version
+type Stage string
+
+const (
+	// StageShadow versions predict alongside the active model without affecting decisions
+	StageShadow Stage = "shadow"
+
+	// StageCanary versions drive decisions for a subset of services
+	StageCanary Stage = "canary"
+
+	// StageActive is the version driving decisions for all other services
+	StageActive Stage = "active"
+
+	// StageRetired versions are kept for auditing and rollback
+	StageRetired Stage = "retired"
+)
+
+// ModelVersion is a versioned model artifact together with its rollout state
+type ModelVersion struct {
+	Version     string                `json:"version"`
+	Stage       Stage                 `json:"stage"`
+	Description string                `json:"description,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+	ActivatedAt time.Time             `json:"activated_at,omitempty"`
+	Artifact    *scaler.ModelArtifact `json:"artifact"`
+}
+
+// Summary returns a copy of the version without the (potentially large) artifact parameters
+func (mv *ModelVersion) Summary() *ModelVersion {
+	summary := *mv
+	if mv.Artifact != nil {
+		summary.Artifact = &scaler.ModelArtifact{
+			FormatVersion:       mv.Artifact.FormatVersion,
+			ModelType:           mv.Artifact.ModelType,
+			CreatedAt:           mv.Artifact.CreatedAt,
+			TrainingSamples:     mv.Artifact.TrainingSamples,
+			TrainingWindowStart: mv.Artifact.TrainingWindowStart,
+			TrainingWindowEnd:   mv.Artifact.TrainingWindowEnd,
+			Hyperparameters:     mv.Artifact.Hyperparameters,
+			Evaluation:          mv.Artifact.Evaluation,
+		}
+	}
+	return &summary
+}
+
+// ModelTarget receives the models selected by the registry and reports how
+// well each version has predicted
+type ModelTarget interface {
+	SetModel(model scaler.AIModel, version string)
+	SetTrainedAt(trainedAt time.Time)
+	SetCanaryModel(model scaler.AIModel, version string)
+	SetShadowModel(model scaler.AIModel, version string)
+	SetFeatureReference(reference scaler.FeatureReference)
+	SetFeatureImportance(report *scaler.ImportanceReport)
+	PredictionError(version string) scaler.PredictionError
+	ResetPredictionError(version string)
+}
+
+// Registry manages versioned models and their staged rollout
+type Registry struct {
+	store    Store
+	modelCfg config.AIModelConfig
+	interval time.Duration
+
+	// mu serializes mutations so stage transitions are not interleaved
+	mu sync.Mutex
+
+	// syncMu serializes syncs, which the ticker and the admin API both run.
+	// It is separate from mu, since a sync promotes canaries through Promote.
+	syncMu sync.Mutex
+
+	// last applied versions, to avoid reloading unchanged models on every
+	// sync, guarded by syncMu
+	appliedActive string
+	appliedCanary string
+	appliedShadow string
+
+	// set once the initial sync has run
+	synced atomic.Bool
+}
+
+// New creates a registry on top of a store
+func New(store Store, modelCfg config.AIModelConfig, syncInterval time.Duration) *Registry {
+	return &Registry{
+		store:    store,
+		modelCfg: modelCfg,
+		interval: syncInterval,
+	}
+}
+
+// List returns all versions ordered from newest to oldest
+func (r *Registry) List(ctx context.Context) ([]*ModelVersion, error) {
+	versions, err := r.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareVersions(versions[i].Version, versions[j].Version) > 0
+	})
+	return versions, nil
+}
+
+// Get returns a single version
+func (r *Registry) Get(ctx context.Context, version string) (*ModelVersion, error) {
+	return r.store.Get(ctx, canonicalVersion(version))
+}
+
+// Register adds a new version in the shadow stage. The version must be a
+// valid semantic version higher than every version already registered.
+func (r *Registry) Register(ctx context.Context, version, description string, artifact *scaler.ModelArtifact) (*ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parsed, err := ParseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	if artifact == nil {
+		return nil, fmt.Errorf("artifact is required")
+	}
+	if _, err := artifact.Model(r.modelCfg); err != nil {
+		return nil, fmt.Errorf("artifact cannot be loaded: %w", err)
+	}
+
+	versions, err := r.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range versions {
+		if compareVersions(existing.Version, parsed.String()) >= 0 {
+			return nil, fmt.Errorf("version %s must be higher than existing version %s", parsed, existing.Version)
+		}
+	}
+
+	now := time.Now()
+	mv := &ModelVersion{
+		Version:     parsed.String(),
+		Description: description,
+		CreatedAt:   now,
+		Artifact:    artifact,
+	}
+	if err := r.setStage(ctx, versions, mv, StageShadow, now); err != nil {
+		return nil, err
+	}
+
+	logrus.WithField("version", mv.Version).Info("Registered model version in shadow stage")
+	return mv, nil
+}
+
+// Promote moves a version to the given stage. Versions advance one stage at
+// a time (shadow → canary → active) unless force is set. Any version already
+// holding the target stage is retired.
+func (r *Registry) Promote(ctx context.Context, version string, stage Stage, force bool) (*ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mv, err := r.store.Get(ctx, canonicalVersion(version))
+	if err != nil {
+		return nil, err
+	}
+	if !force && !validTransition(mv.Stage, stage) {
+		return nil, fmt.Errorf("cannot move %s from %s to %s without force", version, mv.Stage, stage)
+	}
+
+	versions, err := r.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.setStage(ctx, versions, mv, stage, time.Now()); err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"version": mv.Version,
+		"stage":   stage,
+	}).Info("Promoted model version")
+	return mv, nil
+}
+
+// Rollback retires the active version and reactivates the version that was
+// active before it
+func (r *Registry) Rollback(ctx context.Context) (*ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, err := r.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current := findStage(versions, StageActive)
+	if current == nil {
+		return nil, fmt.Errorf("no active model version to roll back from")
+	}
+
+	var previous *ModelVersion
+	for _, mv := range versions {
+		if mv.Version == current.Version || mv.ActivatedAt.IsZero() || !mv.ActivatedAt.Before(current.ActivatedAt) {
+			continue
+		}
+		if previous == nil || mv.ActivatedAt.After(previous.ActivatedAt) {
+			previous = mv
+		}
+	}
+	if previous == nil {
+		return nil, fmt.Errorf("no previously active model version to roll back to")
+	}
+
+	// Clear the current activation time so a second rollback goes further back
+	now := time.Now()
+	current.ActivatedAt = time.Time{}
+	if err := r.setStage(ctx, versions, previous, StageActive, now); err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"from": current.Version,
+		"to":   previous.Version,
+	}).Warn("Rolled back active model version")
+	return previous, nil
+}
+
+// Active returns the active version, or nil if none is active
+func (r *Registry) Active(ctx context.Context) (*ModelVersion, error) {
+	versions, err := r.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return findStage(versions, StageActive), nil
+}
+
+// Sync loads the active, canary and shadow versions into the target when
+// they changed, then evaluates the canary for automatic promotion. A
+// promoted or retired canary is applied in the same sync.
+func (r *Registry) Sync(ctx context.Context, target ModelTarget) error {
+	r.syncMu.Lock()
+	defer r.syncMu.Unlock()
+
+	for {
+		resync, err := r.syncOnce(ctx, target)
+		if err != nil || !resync {
+			return err
+		}
+	}
+}
+
+// syncOnce runs one pass of Sync, and reports whether the canary changed
+// stage so the versions need to be applied again
+func (r *Registry) syncOnce(ctx context.Context, target ModelTarget) (bool, error) {
+	versions, err := r.store.List(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if active := findStage(versions, StageActive); active != nil && active.Version != r.appliedActive {
+		model, err := active.Artifact.Model(r.modelCfg)
+		if err != nil {
+			return false, fmt.Errorf("failed to load active version %s: %w", active.Version, err)
+		}
+		target.SetModel(model, active.Version)
+		target.SetTrainedAt(active.Artifact.CreatedAt)
+		target.SetFeatureReference(active.Artifact.FeatureReference)
+		target.SetFeatureImportance(active.Artifact.FeatureImportance)
+		r.appliedActive = active.Version
+		logrus.WithField("version", active.Version).Info("Loaded active model version")
+	}
+
+	if err := r.syncStage(versions, StageCanary, &r.appliedCanary, target.SetCanaryModel); err != nil {
+		return false, err
+	}
+	if err := r.syncStage(versions, StageShadow, &r.appliedShadow, target.SetShadowModel); err != nil {
+		return false, err
+	}
+
+	if r.modelCfg.Registry.Canary.AutoPromote {
+		return r.evaluateCanary(ctx, target)
+	}
+	return false, nil
+}
+
+// syncStage loads or clears the optional model for a stage
+func (r *Registry) syncStage(versions []*ModelVersion, stage Stage, applied *string, set func(scaler.AIModel, string)) error {
+	mv := findStage(versions, stage)
+	switch {
+	case mv == nil && *applied != "":
+		set(nil, "")
+		*applied = ""
+		logrus.WithField("stage", stage).Info("Cleared model version")
+	case mv != nil && mv.Version != *applied:
+		model, err := mv.Artifact.Model(r.modelCfg)
+		if err != nil {
+			return fmt.Errorf("failed to load %s version %s: %w", stage, mv.Version, err)
+		}
+		set(model, mv.Version)
+		*applied = mv.Version
+		logrus.WithFields(logrus.Fields{
+			"version": mv.Version,
+			"stage":   stage,
+		}).Info("Loaded model version")
+	}
+	return nil
+}
+
+// evaluateCanary compares the canary's prediction error with the active
+// version's once both have enough scored predictions, promoting the canary
+// when it is at least as accurate and retiring it otherwise. It reports
+// whether the canary changed stage.
+func (r *Registry) evaluateCanary(ctx context.Context, target ModelTarget) (bool, error) {
+	if r.appliedCanary == "" {
+		return false, nil
+	}
+
+	canaryCfg := r.modelCfg.Registry.Canary
+	stableLabel := r.appliedActive
+	if stableLabel == "" {
+		stableLabel = scaler.UnversionedModel
+	}
+	canaryErr := target.PredictionError(r.appliedCanary)
+	stableErr := target.PredictionError(stableLabel)
+	if canaryErr.Samples < canaryCfg.MinSamples || stableErr.Samples < canaryCfg.MinSamples {
+		return false, nil
+	}
+
+	fields := logrus.Fields{
+		"canary_version": r.appliedCanary,
+		"stable_version": stableLabel,
+		"canary_mae":     canaryErr.MAE,
+		"stable_mae":     stableErr.MAE,
+	}
+
+	version := r.appliedCanary
+	stage := StageRetired
+	if canaryErr.MAE <= stableErr.MAE*canaryCfg.MaxErrorRatio {
+		stage = StageActive
+	}
+
+	if _, err := r.Promote(ctx, version, stage, false); err != nil {
+		return false, fmt.Errorf("failed to move canary %s to %s: %w", version, stage, err)
+	}
+	target.ResetPredictionError(version)
+	target.ResetPredictionError(stableLabel)
+
+	if stage == StageActive {
+		logrus.WithFields(fields).Info("Canary model version promoted to active")
+	} else {
+		logrus.WithFields(fields).Warn("Canary model version retired due to higher prediction error")
+	}
+
+	// Apply the outcome right away rather than on the next tick
+	return true, nil
+}
+
+// Start periodically syncs the registry into the target until the context is cancelled
+func (r *Registry) Start(ctx context.Context, target ModelTarget) error {
+	logrus.Info("Starting model registry sync")
+
+	if err := r.Sync(ctx, target); err != nil {
+		logrus.WithError(err).Error("Initial model registry sync failed, keeping the configured model")
+	}
+	r.synced.Store(true)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logrus.Info("Stopping model registry sync")
+			return nil
+		case <-ticker.C:
+			if err := r.Sync(ctx, target); err != nil {
+				logrus.WithError(err).Error("Model registry sync failed")
+			}
+		}
+	}
+}
+
+// Runnable returns the sync loop as a manager runnable. It runs on every
+// replica, so each serves the active model and passes its readiness check.
+func (r *Registry) Runnable(target ModelTarget) manager.Runnable {
+	return &syncRunnable{registry: r, target: target}
+}
+
+type syncRunnable struct {
+	registry *Registry
+	target   ModelTarget
+}
+
+func (s *syncRunnable) Start(ctx context.Context) error {
+	return s.registry.Start(ctx, s.target)
+}
+
+func (s *syncRunnable) NeedLeaderElection() bool {
+	return false
+}
+
+// Synced reports whether the initial sync has run
+func (r *Registry) Synced() bool {
+	return r.synced.Load()
+}
+
+// ReadyCheck is a readiness probe check that fails until the initial sync
+// has loaded the active model or failed and left the configured model in place
+func (r *Registry) ReadyCheck(_ *http.Request) error {
+	if !r.Synced() {
+		return fmt.Errorf("initial model registry sync has not completed")
+	}
+	return nil
+}
+
+// setStage persists mv in the given stage, retiring any other version
+// currently holding that stage
+func (r *Registry) setStage(ctx context.Context, versions []*ModelVersion, mv *ModelVersion, stage Stage, now time.Time) error {
+	for _, other := range versions {
+		if other.Version == mv.Version || other.Stage != stage || stage == StageRetired {
+			continue
+		}
+		other.Stage = StageRetired
+		other.UpdatedAt = now
+		if err := r.store.Put(ctx, other); err != nil {
+			return fmt.Errorf("failed to retire version %s: %w", other.Version, err)
+		}
+	}
+
+	mv.Stage = stage
+	mv.UpdatedAt = now
+	if stage == StageActive {
+		mv.ActivatedAt = now
+	}
+	return r.store.Put(ctx, mv)
+}
+
+// validTransition reports whether a stage change follows the rollout order
+func validTransition(from, to Stage) bool {
+	switch to {
+	case StageCanary:
+		return from == StageShadow
+	case StageActive:
+		return from == StageCanary
+	case StageShadow:
+		return from == StageRetired
+	case StageRetired:
+		return from != StageRetired
+	}
+	return false
+}
+
+func findStage(versions []*ModelVersion, stage Stage) *ModelVersion {
+	for _, mv := range versions {
+		if mv.Stage == stage {
+			return mv
+		}
+	}
+	return nil
+}
+
+// canonicalVersion normalizes a version so "1.2.0" and "v1.2.0" refer to the same entry
+func canonicalVersion(version string) string {
+	parsed, err := ParseVersion(version)
+	if err != nil {
+		return version
+	}
+	return parsed.String()
+}
+
+// compareVersions compares version strings, ordering unparsable versions first
+func compareVersions(a, b string) int {
+	va, errA := ParseVersion(a)
+	vb, errB := ParseVersion(b)
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return va.Compare(vb)
+}
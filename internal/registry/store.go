@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when a model version does not exist in the store
+var ErrNotFound = errors.New("model version not found")
+
+// Store persists model versions for a registry backend
+type Store interface {
+	// List returns every stored model version
+	List(ctx context.Context) ([]*ModelVersion, error)
+
+	// Get returns a single model version or ErrNotFound
+	Get(ctx context.Context, version string) (*ModelVersion, error)
+
+	// Put creates or replaces a model version
+	Put(ctx context.Context, mv *ModelVersion) error
+}
+
+// DirectoryStore keeps one JSON file per model version in a local directory
+type DirectoryStore struct {
+	dir string
+}
+
+// NewDirectoryStore creates a directory-backed store, creating the directory if needed
+func NewDirectoryStore(dir string) (*DirectoryStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create registry directory: %w", err)
+	}
+	return &DirectoryStore{dir: dir}, nil
+}
+
+// List returns every model version stored in the directory
+func (d *DirectoryStore) List(ctx context.Context) ([]*ModelVersion, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []*ModelVersion
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		mv, err := d.read(filepath.Join(d.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, mv)
+	}
+
+	return versions, nil
+}
+
+// Get returns a single model version
+func (d *DirectoryStore) Get(ctx context.Context, version string) (*ModelVersion, error) {
+	mv, err := d.read(d.path(version))
+	if os.IsNotExist(errors.Unwrap(err)) {
+		return nil, ErrNotFound
+	}
+	return mv, err
+}
+
+// Put writes a model version, replacing any previous content atomically
+func (d *DirectoryStore) Put(ctx context.Context, mv *ModelVersion) error {
+	data, err := json.MarshalIndent(mv, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal model version: %w", err)
+	}
+
+	tmp := d.path(mv.Version) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write model version: %w", err)
+	}
+	return os.Rename(tmp, d.path(mv.Version))
+}
+
+func (d *DirectoryStore) path(version string) string {
+	return filepath.Join(d.dir, version+".json")
+}
+
+func (d *DirectoryStore) read(path string) (*ModelVersion, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model version: %w", err)
+	}
+
+	mv := &ModelVersion{}
+	if err := json.Unmarshal(data, mv); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", filepath.Base(path), err)
+	}
+	return mv, nil
+}
@@ -2,7 +2,11 @@ package scaler
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math"
+	"math/rand"
+	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -13,6 +17,24 @@ import (
 	"github.com/hydraai/hydra-route/pkg/config"
 )
 
+const (
+	// defaultHiddenUnits is the hidden layer width of the neural network
+	defaultHiddenUnits = 8
+
+	// defaultEpochs is the number of passes over the data when training the neural network
+	defaultEpochs = 200
+
+	// defaultSequenceLength is the number of samples read by the GRU model
+	defaultSequenceLength = 6
+
+	// maxTrainingData is the number of training samples kept for retraining
+	// when max_training_samples is unset
+	maxTrainingData = 10000
+
+	// minRidgePenalty is the smallest L2 penalty applied when training the linear model
+	minRidgePenalty = 1e-6
+)
+
 // ScalingDecision represents a scaling decision made by the AI
 type ScalingDecision struct {
 	ServiceName         string               `json:"service_name"`
@@ -22,23 +44,70 @@ type ScalingDecision struct {
 	RecommendedReplicas int32                `json:"recommended_replicas"`
 	Confidence          float64              `json:"confidence"`
 	Reasoning           string               `json:"reasoning"`
+	Reasons             []Reason             `json:"reasons"`
+	ModelVersion        string               `json:"model_version,omitempty"`
 	Metrics             *metrics.MetricsData `json:"metrics"`
+
+	// Regime shift detected on this sample, if any
+	ChangePoint *ChangePoint `json:"change_point,omitempty"`
+
+	// Step of the SLO escalation floor taken on this sample, if any
+	SLOEscalation *SLOEscalation `json:"slo_escalation,omitempty"`
+
+	// Set on the evaluation whose recommendation was clamped at the max
+	// replicas long enough to raise the capacity alert
+	CapacityLimit *CapacityLimit `json:"capacity_limit,omitempty"`
+
+	// Scale factor the recommendation was computed from, and the envelope
+	// the prediction was mapped onto
+	ScaleFactor       float64            `json:"scale_factor,omitempty"`
+	ScaleFactorBounds *ScaleFactorBounds `json:"scale_factor_bounds,omitempty"`
+
+	// Estimated change in hourly cost and emissions, when enabled
+	Cost *CostEstimate `json:"cost,omitempty"`
+
+	// Experiment cohort of the service while an experiment runs
+	Cohort string `json:"cohort,omitempty"`
 }
 
 // FeatureVector represents input features for the AI model
 type FeatureVector struct {
-	CPUUtilization    float64
-	MemoryUtilization float64
-	RequestRate       float64
-	NetworkBandwidth  float64
-	IOBandwidth       float64
-	ResponseTime      float64
-	ErrorRate         float64
-	TimeOfDay         float64 // 0-23
-	DayOfWeek         float64 // 0-6
-	TrendCPU          float64 // CPU trend over time
-	TrendMemory       float64 // Memory trend over time
-	TrendRequests     float64 // Request rate trend
+	CPUUtilization    float64 `json:"cpu_utilization"`
+	MemoryUtilization float64 `json:"memory_utilization"`
+	RequestRate       float64 `json:"request_rate"`
+	NetworkBandwidth  float64 `json:"network_bandwidth"`
+	IOBandwidth       float64 `json:"io_bandwidth"`
+	ResponseTime      float64 `json:"response_time"`
+	ErrorRate         float64 `json:"error_rate"`
+
+	// Time of day and day of week as points on the unit circle, so 23:00
+	// is as close to 00:00 as to 22:00, and Saturday to Sunday
+	HourSin float64 `json:"hour_sin"`
+	HourCos float64 `json:"hour_cos"`
+	DaySin  float64 `json:"day_sin"`
+	DayCos  float64 `json:"day_cos"`
+
+	// 1 for samples taken on a configured holiday, 0 otherwise
+	Holiday float64 `json:"holiday"`
+
+	TrendCPU      float64 `json:"trend_cpu"`      // CPU trend over time
+	TrendMemory   float64 `json:"trend_memory"`   // Memory trend over time
+	TrendRequests float64 `json:"trend_requests"` // Request rate trend
+
+	// Replica count of the sample; read by models that reason about
+	// per-replica load rather than used as a regression input
+	CurrentReplicas float64 `json:"current_replicas,omitempty"`
+
+	// Derived holds the config-defined derived features, in configuration order
+	Derived []float64 `json:"derived,omitempty"`
+
+	// Windows holds the CPU, memory and request rate mean and max of every
+	// collector aggregation window, shortest window first
+	Windows []float64 `json:"windows,omitempty"`
+
+	// Sequence holds the preceding samples of the service, oldest first, for
+	// sequence models. Entries carry no sequence of their own.
+	Sequence []FeatureVector `json:"sequence,omitempty"`
 }
 
 // AIModel interface for different scaling models
@@ -50,18 +119,40 @@ type AIModel interface {
 
 // TrainingData represents historical data for training
 type TrainingData struct {
-	Features    FeatureVector
-	ActualScale float64
-	Performance float64 // performance metric (0-1)
-	Timestamp   time.Time
+	Features    FeatureVector `json:"features"`
+	ActualScale float64       `json:"actual_scale"`
+	Performance float64       `json:"performance"` // performance metric (0-1)
+	Timestamp   time.Time     `json:"timestamp"`
+
+	// Service (namespace/name) and the deployment revision and images the
+	// sample was observed under
+	Service  string `json:"service,omitempty"`
+	Revision string `json:"revision,omitempty"`
+	Image    string `json:"image,omitempty"`
+
+	// Relative importance of the sample during training; 0 counts as 1
+	Weight float64 `json:"weight,omitempty"`
+
+	// Conditions the sample was collected under (incident, rollout,
+	// collector_outage) that training_quality can exclude
+	Flags []string `json:"flags,omitempty"`
+}
+
+// sampleWeight returns the training weight of a sample
+func sampleWeight(sample TrainingData) float64 {
+	if sample.Weight <= 0 {
+		return 1
+	}
+	return sample.Weight
 }
 
 // LinearModel implements a linear regression model
 type LinearModel struct {
-	Weights   []float64
-	Bias      float64
-	IsTrained bool
-	Config    config.AIModelConfig
+	Weights        []float64
+	Bias           float64
+	Regularization float64 // L2 (ridge) penalty applied during training
+	IsTrained      bool
+	Config         config.AIModelConfig
 }
 
 // NeuralNetwork implements a simple neural network
@@ -74,6 +165,8 @@ type NeuralNetwork struct {
 	Bias1        []float64
 	Bias2        []float64
 	LearningRate float64
+	HiddenUnits  int
+	Epochs       int
 	IsTrained    bool
 	Config       config.AIModelConfig
 }
@@ -87,60 +180,299 @@ type EnsembleModel struct {
 
 // AIScaler manages AI-based scaling decisions
 type AIScaler struct {
-	config          config.ScalingConfig
-	model           AIModel
-	trainingData    []TrainingData
-	mu              sync.RWMutex
-	lastDecisions   map[string]*ScalingDecision
-	cooldownTracker map[string]time.Time
+	config        config.ScalingConfig
+	model         AIModel
+	modelVersion  string
+	shadowModel   AIModel
+	shadowVersion string
+	canaryModel   AIModel
+	canaryVersion string
+	outcomes      *OutcomeTracker
+	drift         *DriftDetector
+	changePoints  *ChangePointDetector
+	policies      []*Policy
+	derived       *DerivedFeatures
+	holidays      Holidays
+	disabled      FeatureSet
+	behavior      *behaviorHistory
+	budget        *actionBudget
+	concurrency   *concurrencyTracker
+	contention    *contentionFloors
+	oom           *oomFloors
+	sloFloors     *sloFloors
+	clamping      *clampAudit // nil on what-if probes
+
+	// Decisions, cooldowns, settings, sequences and training samples per
+	// service, sharded by service key
+	states *serviceStates
+
+	// Guards the models and the reports
+	mu          sync.RWMutex
+	rightSizing *RightSizingReport
+	importance  *ImportanceReport
+	experiment  *ExperimentReport
+
+	// Last successful training of the active model and the error of any
+	// retrain failed since, guarded by mu; staleness of a model never
+	// trained counts from startedAt
+	trainedAt     time.Time
+	trainingError string
+	startedAt     time.Time
+
+	// Retrains in progress, waited for before a checkpoint. One runs at a
+	// time; retrains asked for meanwhile run once after it.
+	retraining    sync.WaitGroup
+	retrainMu     sync.Mutex
+	retrainActive bool
+	retrainQueued bool
+
+	// Why the controller is throttling itself, empty when it isn't, and
+	// whether a retrain was postponed meanwhile; guarded by retrainMu
+	throttled        string
+	retrainPostponed bool
 }
 
 // NewAIScaler creates a new AI-based scaler
 func NewAIScaler(config config.ScalingConfig) *AIScaler {
 	scaler := &AIScaler{
-		config:          config,
-		trainingData:    make([]TrainingData, 0),
-		lastDecisions:   make(map[string]*ScalingDecision),
-		cooldownTracker: make(map[string]time.Time),
+		config:     config,
+		behavior:   newBehaviorHistory(),
+		contention: newContentionFloors(),
+		oom:        newOOMFloors(),
+		clamping:   newClampAudit(),
+		budget:     newActionBudget(config.ActionBudget),
+		states:     newServiceStates(config.AIModel.MaxTrainingSamples),
+		outcomes:   NewOutcomeTracker(config.AIModel.TrainingQuality.IncidentErrorRate),
+		startedAt:  time.Now(),
+	}
+
+	if config.TargetMode == TargetModeConcurrency {
+		scaler.concurrency = newConcurrencyTracker(config.Concurrency)
+	}
+	if config.SLOEscalation.Enabled {
+		scaler.sloFloors = newSLOFloors()
+	}
+	if config.PodReadiness.Timeout > 0 {
+		scaler.outcomes.readyTimeout = config.PodReadiness.Timeout
+	}
+	if config.AIModel.DriftDetection.Enabled {
+		scaler.drift = NewDriftDetector(config.AIModel.DriftDetection)
+	}
+	if config.AIModel.ChangePoint.Enabled {
+		scaler.changePoints = NewChangePointDetector(config.AIModel.ChangePoint)
+	}
+
+	policies, err := CompilePolicies(config.Policies)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid scaling policies, ignoring them")
+	}
+	scaler.policies = policies
+
+	derived, err := CompileDerivedFeatures(config.AIModel.DerivedFeatures)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid derived features, ignoring them")
+	}
+	scaler.derived = derived
+
+	holidays, err := NewHolidays(config.AIModel.Holidays)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid holidays, ignoring them")
+	}
+	scaler.holidays = holidays
+
+	disabled, err := ParseFeatureSet(config.AIModel.DisabledFeatures)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid disabled features, ignoring the unknown ones")
 	}
+	scaler.disabled = disabled
 
-	// Initialize the AI model based on configuration
-	scaler.model = scaler.createModel()
+	// Initialize the AI model, preferring a pre-trained artifact when configured
+	scaler.model = scaler.loadOrCreateModel()
 
 	return scaler
 }
 
+// loadOrCreateModel loads the configured model artifact, falling back to an
+// untrained model when no artifact is configured or it cannot be loaded
+func (s *AIScaler) loadOrCreateModel() AIModel {
+	path := s.config.AIModel.ModelArtifactPath
+	if checkpoint := s.config.AIModel.CheckpointPath; checkpoint != "" {
+		if _, err := os.Stat(checkpoint); err == nil {
+			path = checkpoint
+		}
+	}
+	if path == "" {
+		return s.createModel()
+	}
+
+	artifact, err := LoadModelArtifact(path)
+	if err == nil {
+		var model AIModel
+		model, err = artifact.Model(s.config.AIModel)
+		if err == nil {
+			s.SetFeatureReference(artifact.FeatureReference)
+			s.SetFeatureImportance(artifact.FeatureImportance)
+			if !equalNames(artifact.DerivedFeatures, s.derived.Names()) {
+				logrus.WithFields(logrus.Fields{
+					"artifact_features":   artifact.DerivedFeatures,
+					"configured_features": s.derived.Names(),
+				}).Warn("Model artifact was trained with different derived features")
+			}
+			if !equalNames(artifact.DisabledFeatures, s.disabled.Names()) {
+				logrus.WithFields(logrus.Fields{
+					"artifact_disabled":   artifact.DisabledFeatures,
+					"configured_disabled": s.disabled.Names(),
+				}).Warn("Model artifact was trained with different disabled features")
+			}
+			if isTrained(model) {
+				s.trainedAt = artifact.CreatedAt
+			}
+			logrus.WithFields(logrus.Fields{
+				"path":       path,
+				"model_type": artifact.ModelType,
+				"created_at": artifact.CreatedAt,
+			}).Info("Loaded pre-trained model artifact")
+			return model
+		}
+	}
+
+	logrus.WithError(err).WithField("path", path).Warn("Failed to load model artifact, using untrained model")
+	return s.createModel()
+}
+
 // createModel creates the appropriate AI model based on configuration
 func (s *AIScaler) createModel() AIModel {
-	switch s.config.AIModel.ModelType {
+	return NewModel(s.config.AIModel)
+}
+
+// NewModel creates an untrained AI model of the type named in the
+// configuration, with the configured hyperparameters applied
+func NewModel(cfg config.AIModelConfig) AIModel {
+	switch cfg.ModelType {
 	case "neural_network":
-		return &NeuralNetwork{
-			LearningRate: s.config.AIModel.LearningRate,
-			Config:       s.config.AIModel,
-		}
+		return newNeuralNetwork(cfg)
+	case "quantile":
+		return newQuantileModel(cfg)
+	case "gru":
+		return newGRUModel(cfg)
+	case "littles_law":
+		return newLittlesLawModel(cfg)
 	case "ensemble":
 		return &EnsembleModel{
 			Models: []AIModel{
-				&LinearModel{Config: s.config.AIModel},
-				&NeuralNetwork{LearningRate: s.config.AIModel.LearningRate, Config: s.config.AIModel},
+				newLinearModel(cfg),
+				newNeuralNetwork(cfg),
+				newLittlesLawModel(cfg),
 			},
-			Weights: []float64{0.6, 0.4}, // Linear model gets more weight initially
-			Config:  s.config.AIModel,
+			Weights: []float64{0.5, 0.3, 0.2}, // Linear model gets more weight initially
+			Config:  cfg,
 		}
 	default: // "linear" or default
-		return &LinearModel{Config: s.config.AIModel}
+		return newLinearModel(cfg)
+	}
+}
+
+func newLinearModel(cfg config.AIModelConfig) *LinearModel {
+	return &LinearModel{
+		Regularization: cfg.Hyperparameters.Regularization,
+		Config:         cfg,
+	}
+}
+
+func newNeuralNetwork(cfg config.AIModelConfig) *NeuralNetwork {
+	return &NeuralNetwork{
+		LearningRate: cfg.LearningRate,
+		HiddenUnits:  cfg.Hyperparameters.HiddenUnits,
+		Epochs:       cfg.Hyperparameters.Epochs,
+		Config:       cfg,
+	}
+}
+
+// SetModel replaces the model driving scaling decisions
+func (s *AIScaler) SetModel(model AIModel, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.model = model
+	s.modelVersion = version
+}
+
+// SetShadowModel sets a model that predicts alongside the active model
+// without affecting decisions; pass nil to clear it
+func (s *AIScaler) SetShadowModel(model AIModel, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.shadowModel = model
+	s.shadowVersion = version
+}
+
+// SetCanaryModel sets a model that drives decisions for the canary cohort
+// of services; pass nil to clear it
+func (s *AIScaler) SetCanaryModel(model AIModel, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.canaryModel = model
+	s.canaryVersion = version
+}
+
+// SetFeatureReference sets the training feature distribution drift is measured against
+func (s *AIScaler) SetFeatureReference(reference FeatureReference) {
+	if s.drift != nil {
+		s.drift.SetReference(reference)
 	}
 }
 
+// DriftStatus returns the latest feature drift evaluation
+func (s *AIScaler) DriftStatus() DriftStatus {
+	if s.drift == nil {
+		return DriftStatus{}
+	}
+	return s.drift.Status()
+}
+
+// PredictionError returns the observed prediction error of a model version
+func (s *AIScaler) PredictionError(version string) PredictionError {
+	return s.outcomes.Error(version)
+}
+
+// ResetPredictionError clears the observed prediction error of a model version
+func (s *AIScaler) ResetPredictionError(version string) {
+	s.outcomes.Reset(version)
+}
+
+// currentModel returns the active model and its registry version
+func (s *AIScaler) currentModel() (AIModel, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.model, s.modelVersion
+}
+
+// modelFor returns the model that drives decisions for a service: the canary
+// version for services in the canary cohort, the active version otherwise
+func (s *AIScaler) modelFor(key string) (AIModel, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.canaryModel != nil && inCanaryCohort(key, s.config.AIModel.Registry.Canary) {
+		return s.canaryModel, s.canaryVersion
+	}
+	return s.model, s.modelVersion
+}
+
 // MakeScalingDecision analyzes metrics and returns a scaling decision
 func (s *AIScaler) MakeScalingDecision(metricsData *metrics.MetricsData) (*ScalingDecision, error) {
 	if metricsData == nil {
 		return nil, fmt.Errorf("metrics data is nil")
 	}
 
-	// Check if we're in cooldown period
+	// Check if we're in cooldown period. Breaches of the SLO count on every
+	// evaluation, and a floor raised by them overrides the cooldown.
 	key := fmt.Sprintf("%s/%s", metricsData.Namespace, metricsData.ServiceName)
-	if s.isInCooldown(key) {
+	escalationFloor, escalation := s.observeSLO(key, metricsData)
+	if s.isInCooldown(key) && escalationFloor <= metricsData.CurrentReplicas {
 		logrus.WithFields(logrus.Fields{
 			"service":   metricsData.ServiceName,
 			"namespace": metricsData.Namespace,
@@ -148,28 +480,287 @@ func (s *AIScaler) MakeScalingDecision(metricsData *metrics.MetricsData) (*Scali
 		return nil, nil
 	}
 
+	// Hold replicas rather than act on metrics from before a collection outage
+	age := time.Since(metricsData.Timestamp)
+	stale := s.config.StaleMetrics.MaxAge > 0 && age > s.config.StaleMetrics.MaxAge
+	if stale && s.config.StaleMetrics.Action == "skip" {
+		return s.holdDecision(key, metricsData, age), nil
+	}
+
 	// Convert metrics to feature vector
 	features := s.extractFeatures(metricsData)
 
-	// Get prediction from AI model
-	scaleFactor, confidence, err := s.model.Predict(features)
-	if err != nil {
-		return nil, fmt.Errorf("model prediction failed: %w", err)
+	// Score the previous predictions for this service now that a newer sample
+	// exists, and keep the labelled sample for online learning. Outcomes
+	// observed in a maintenance window are dropped unscored.
+	inWindow := s.inMaintenanceWindow(key, metricsData.Timestamp)
+	if inWindow {
+		s.outcomes.Discard(key)
+	} else if sample, ok := s.outcomes.Resolve(key, metricsData, s.realizedScale); ok {
+		s.AddTrainingData(sample)
 	}
 
-	// Calculate recommended replicas
+	// Compare the live feature distribution with the training distribution
+	// Samples taken during node maintenance, crash loops or maintenance
+	// windows don't reflect the workload
+	disrupted := metricsData.Maintenance || metricsData.CrashLoop || inWindow
+	if s.drift != nil && !disrupted && s.drift.Observe(features, time.Now()) {
+		s.handleDrift()
+	}
+
+	// Watch for regime shifts such as a release that changes per-request cost
+	var changePoint *ChangePoint
+	if s.changePoints != nil && !disrupted {
+		if changePoint = s.changePoints.Observe(key, metricsData, time.Now()); changePoint != nil {
+			s.handleChangePoint(key, changePoint)
+		}
+	}
+
+	// Current replica count the recommendation starts from
 	currentReplicas := metricsData.CurrentReplicas
 	if currentReplicas == 0 {
 		currentReplicas = 1 // Default to 1 if not set
 	}
 
+	// Get prediction from AI model
+	model, modelVersion := s.modelFor(key)
+	settings := s.settingsFor(key)
+	scaleFactor, confidence, absolute, err := s.predict(model, features, currentReplicas, settings)
+	if err != nil {
+		return nil, fmt.Errorf("model prediction failed: %w", err)
+	}
+
+	s.trackPredictions(key, metricsData, features, scaleFactor)
+
+	// Map the prediction onto the service's scale factor envelope; factors
+	// derived from absolute targets are exact and left alone
+	if !absolute {
+		scaleFactor = settings.ScaleFactor.apply(scaleFactor)
+	}
+
+	// Services in the threshold cohort of a running experiment are scaled by
+	// the thresholds instead of the model
+	cohort := activeCohort(key, s.config.Experiment, time.Now())
+	if cohort == CohortThreshold {
+		scaleFactor, confidence, absolute = thresholdFactor(metricsData, settings, s.config.ScaleDownThresholds), 1.0, false
+	}
+
+	// In the concurrency target mode, measured in-flight requests size the
+	// service; the model only decides while none are reported
+	var inFlight *concurrencyTarget
+	if s.concurrency != nil {
+		s.concurrency.observe(key, metricsData.Timestamp, metricsData.Concurrency)
+		if target, ok := s.concurrency.target(key, currentReplicas, settings.TargetConcurrency, time.Now()); ok {
+			scaleFactor, confidence, inFlight = targetFactor(target.Replicas, currentReplicas), 1.0, &target
+		}
+	}
+
+	// LLM endpoints are sized by token throughput and time to first token
+	// when targets are set, or by in-flight requests if those call for more
+	var llm *llmTarget
+	if target, ok := llmReplicas(metricsData, settings, currentReplicas); ok {
+		llm = &target
+		if inFlight == nil || target.Replicas > inFlight.Replicas {
+			scaleFactor, confidence = targetFactor(target.Replicas, currentReplicas), 1.0
+		}
+	}
+
+	// Trust predictions less when some metrics sources have gone quiet
+	confidence = discountStaleness(confidence, metricsData.StalenessSeconds, s.config.Prediction.StalenessHalfLife)
+
+	// Apply operator-defined guardrails around the model output
+	modelFactor := scaleFactor
+	scaleFactor, confidence, applied := applyPolicies(s.policies, key, metricsData, features, scaleFactor, confidence)
+
+	// Keep only part of the change when acting on stale metrics
+	if stale {
+		scaleFactor = 1 + (scaleFactor-1)*s.config.StaleMetrics.Dampening
+	}
+
+	// Calculate recommended replicas
 	recommendedReplicas := s.calculateRecommendedReplicas(currentReplicas, scaleFactor)
 
+	// The utilization comes from the serving pods only, so size those and
+	// keep replacements for the pods being preempted or not yet scheduled,
+	// and for those the service mesh has ejected
+	unavailable := unavailablePods(metricsData)
+	compensated := s.config.Preemption.Action == PreemptionCompensate && unavailable > 0
+	ejectionCompensated := s.config.MeshEjection.Action == MeshEjectionCompensate && metricsData.EjectedEndpoints > 0
+	if lost := s.lostReplicas(metricsData); lost > 0 {
+		serving := currentReplicas - lost
+		if serving < 1 {
+			serving = 1
+		}
+		recommendedReplicas = s.calculateRecommendedReplicas(serving, scaleFactor) + lost
+	}
+
+	// Add replicas up front while batch jobs contend for the service's nodes
+	var contentionFloor int32
+	if s.config.BatchContention.Action == BatchContentionPrescale {
+		contentionFloor = s.contention.floor(key, metricsData.BatchContention, currentReplicas, s.config.BatchContention.PrescaleFactor)
+		if recommendedReplicas < contentionFloor {
+			recommendedReplicas = contentionFloor
+		}
+	}
+
+	// Recurring OOM kills call for capacity whatever the request metrics say
+	var oomFloor int32
+	if s.config.OOM.Action == OOMScaleUp {
+		oomFloor = s.oom.floor(key, metricsData.OOMKills, s.config.OOM.MinKills, currentReplicas, s.config.OOM.ScaleFactor)
+		if recommendedReplicas < oomFloor {
+			recommendedReplicas = oomFloor
+		}
+	}
+
+	// A fast burn of the latency SLO error budget calls for capacity, as it
+	// would page an SRE
+	burnFloor := latencyBurnFloor(s.config.LatencySLO, metricsData, currentReplicas)
+	if recommendedReplicas < burnFloor {
+		recommendedReplicas = burnFloor
+	}
+
+	// So does an SLO breached on evaluation after evaluation despite scaling
+	if recommendedReplicas < escalationFloor {
+		recommendedReplicas = escalationFloor
+	}
+
+	// Keep enough replicas that a scale-down doesn't empty a zone
+	zoneFloor := zoneSpreadFloor(s.config.ZoneSpread, metricsData)
+	raisedForZones := s.config.ZoneSpread.Action == ZoneSpreadFloor && recommendedReplicas < zoneFloor
+	if raisedForZones {
+		recommendedReplicas = zoneFloor
+	}
+
 	// Apply constraints
-	recommendedReplicas = s.applyConstraints(recommendedReplicas)
+	unclamped := recommendedReplicas
+	recommendedReplicas = s.applyConstraints(key, recommendedReplicas)
+	constrained := recommendedReplicas
+
+	// Stabilize and rate-limit like an HPA when the service has a behavior
+	limitedByBehavior := false
+	if settings.Behavior != nil {
+		limited := s.behavior.apply(key, settings.Behavior, currentReplicas, recommendedReplicas,
+			settings.MinReplicas, settings.MaxReplicas, time.Now())
+		limitedByBehavior = limited != recommendedReplicas
+		recommendedReplicas = limited
+	}
+	// What-if probes have no audit, so they leave the clamping counts alone
+	var capacityLimit *CapacityLimit
+	if s.clamping != nil {
+		capacityLimit = s.auditClamping(key, metricsData, clampLimits(unclamped, constrained, limitedByBehavior), unclamped, settings.MaxReplicas)
+	}
+
+	// Evictions and rescheduling inflate load during node maintenance
+	heldForMaintenance := metricsData.Maintenance && recommendedReplicas > currentReplicas
+	if heldForMaintenance {
+		recommendedReplicas = currentReplicas
+	}
+
+	// Requests drop while pods crash, which would otherwise drive replicas down further
+	heldForCrashLoop := metricsData.CrashLoop && recommendedReplicas < currentReplicas
+	if heldForCrashLoop {
+		recommendedReplicas = currentReplicas
+	}
 
-	// Generate reasoning
-	reasoning := s.generateReasoning(features, scaleFactor, confidence)
+	// Wait for the scheduler to settle when the current replicas are about to change
+	heldForPreemption := s.config.Preemption.Action == PreemptionWait && unavailable > 0 && recommendedReplicas != currentReplicas
+	if heldForPreemption {
+		recommendedReplicas = currentReplicas
+	}
+
+	// Hold once the service has used up its scaling actions
+	overBudget := recommendedReplicas != currentReplicas && s.budget != nil && !s.budget.spend(key, currentReplicas, recommendedReplicas, time.Now())
+	if overBudget {
+		recommendedReplicas = currentReplicas
+	}
+
+	// Record the reasons behind the decision
+	reasons := signalReasons(features, scaleFactor, confidence)
+	if metricsData.MeasuredCPUUtilization > 0 {
+		reasons = append(reasons, newReason(ReasonCPUThrottled, "throttling", metricsData.CPUThrottling,
+			"measured", metricsData.MeasuredCPUUtilization, "corrected", metricsData.CPUUtilization))
+	}
+	if metricsData.MeasuredRequestRate > 0 {
+		reasons = append(reasons, newReason(ReasonRetryStorm, "amplification", metricsData.RetryAmplification,
+			"measured", metricsData.MeasuredRequestRate, "corrected", metricsData.RequestRate))
+	}
+	if burnFloor > 0 {
+		reasons = append(reasons, newReason(ReasonLatencySLOBurn, "burn_rate_5m", metricsData.LatencyBurnRate5m,
+			"burn_rate_1h", metricsData.LatencyBurnRate1h, "replicas", burnFloor))
+	}
+	if escalationFloor > 0 {
+		reasons = append(reasons, newReason(ReasonSLOEscalation, "response_time", metricsData.ResponseTime,
+			"error_rate", metricsData.ErrorRate, "replicas", escalationFloor))
+	}
+	if absolute {
+		reasons = append(reasons, newReason(ReasonAbsoluteTarget, "replicas", math.Ceil(scaleFactor*float64(currentReplicas))))
+	}
+	if inFlight != nil {
+		reasons = append(reasons, newReason(ReasonConcurrencyTarget, "concurrency", inFlight.Concurrency,
+			"target", settings.TargetConcurrency, "replicas", math.Ceil(inFlight.Replicas), "panic", inFlight.Panic))
+	}
+	if llm != nil {
+		reasons = append(reasons, newReason(ReasonLLMTarget, "token_rate", llm.TokenRate,
+			"ttft_ms", llm.TimeToFirstToken, "replicas", math.Ceil(llm.Replicas)))
+	}
+	for _, result := range applied {
+		reasons = append(reasons, newReason(ReasonPolicyApplied,
+			"policy", result.Name, "target", result.Target, "value", result.Value, "model", modelFactor))
+	}
+	if stale {
+		reasons = append(reasons, newReason(ReasonStaleMetricsDampened,
+			"age_seconds", age.Seconds(), "dampening", s.config.StaleMetrics.Dampening))
+	}
+	if limitedByBehavior {
+		reasons = append(reasons, newReason(ReasonBehaviorLimited, "replicas", recommendedReplicas))
+	}
+	if metricsData.OOMKills >= s.config.OOM.MinKills {
+		switch s.config.OOM.Action {
+		case OOMScaleUp:
+			reasons = append(reasons, newReason(ReasonOOMKilled, "kills", metricsData.OOMKills,
+				"memory_limit_utilization", metricsData.MemoryLimitUtilization, "replicas", oomFloor))
+		case OOMVertical:
+			reasons = append(reasons, newReason(ReasonOOMKilled, "kills", metricsData.OOMKills,
+				"memory_limit_utilization", metricsData.MemoryLimitUtilization, "recommendation", OOMVertical))
+		}
+	}
+	if metricsData.BatchContention {
+		switch s.config.BatchContention.Action {
+		case BatchContentionRelax:
+			reasons = append(reasons, newReason(ReasonBatchContention,
+				"pods", metricsData.BatchContentionPods, "relax", s.config.BatchContention.Relax))
+		case BatchContentionPrescale:
+			reasons = append(reasons, newReason(ReasonBatchContention,
+				"pods", metricsData.BatchContentionPods, "replicas", contentionFloor))
+		}
+	}
+	if heldForMaintenance {
+		reasons = append(reasons, newReason(ReasonMaintenanceHold, "pods", metricsData.MaintenancePods))
+	}
+	if heldForCrashLoop {
+		reasons = append(reasons, newReason(ReasonCrashLoopHold, "pods", metricsData.CrashLoopPods))
+	}
+	if compensated || heldForPreemption {
+		reasons = append(reasons, newReason(ReasonPreemption, "preempted", metricsData.PreemptedPods,
+			"unschedulable", metricsData.UnschedulablePods, "action", s.config.Preemption.Action))
+	}
+	if ejectionCompensated {
+		reasons = append(reasons, newReason(ReasonMeshEjection, "endpoints", metricsData.EjectedEndpoints))
+	}
+	if cohort != "" {
+		reasons = append(reasons, newReason(ReasonExperiment, "experiment", s.config.Experiment.Name, "cohort", cohort))
+	}
+	if overBudget {
+		reasons = append(reasons, newReason(ReasonActionBudgetExhausted,
+			"per_hour", s.config.ActionBudget.PerHour, "per_day", s.config.ActionBudget.PerDay))
+	}
+	if recommendedReplicas < zoneFloor {
+		reasons = append(reasons, newReason(ReasonZoneSpreadUnsatisfied,
+			"zones", metricsData.Zones, "per_zone", s.config.ZoneSpread.MinReplicasPerZone, "replicas", zoneFloor))
+	} else if raisedForZones {
+		reasons = append(reasons, newReason(ReasonZoneSpread,
+			"zones", metricsData.Zones, "per_zone", s.config.ZoneSpread.MinReplicasPerZone, "replicas", zoneFloor))
+	}
 
 	decision := &ScalingDecision{
 		ServiceName:         metricsData.ServiceName,
@@ -178,21 +769,182 @@ func (s *AIScaler) MakeScalingDecision(metricsData *metrics.MetricsData) (*Scali
 		CurrentReplicas:     currentReplicas,
 		RecommendedReplicas: recommendedReplicas,
 		Confidence:          confidence,
-		Reasoning:           reasoning,
+		Reasoning:           RenderReasons(reasons),
+		Reasons:             reasons,
+		ModelVersion:        modelVersion,
 		Metrics:             metricsData,
+		ChangePoint:         changePoint,
+		SLOEscalation:       escalation,
+		CapacityLimit:       capacityLimit,
+		ScaleFactor:         scaleFactor,
+		ScaleFactorBounds:   &settings.ScaleFactor,
+		Cost:                estimateCost(s.config.Cost, metricsData, currentReplicas, recommendedReplicas),
+		Cohort:              cohort,
 	}
 
 	// Store decision and update cooldown
 	s.storeDecision(key, decision)
+	if settings.Behavior != nil {
+		s.behavior.record(key, settings.Behavior, currentReplicas, recommendedReplicas, decision.Timestamp)
+	}
 
 	return decision, nil
 }
 
+// holdDecision keeps the current replica count for a service whose latest
+// metrics are older than the configured maximum age
+func (s *AIScaler) holdDecision(key string, metricsData *metrics.MetricsData, age time.Duration) *ScalingDecision {
+	currentReplicas := metricsData.CurrentReplicas
+	if currentReplicas == 0 {
+		currentReplicas = 1
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"service":   metricsData.ServiceName,
+		"namespace": metricsData.Namespace,
+		"age":       age.Round(time.Second),
+	}).Warn("Metrics are stale, holding current replicas")
+
+	decision := &ScalingDecision{
+		ServiceName:         metricsData.ServiceName,
+		Namespace:           metricsData.Namespace,
+		Timestamp:           time.Now(),
+		CurrentReplicas:     currentReplicas,
+		RecommendedReplicas: currentReplicas,
+		Metrics:             metricsData,
+	}
+	decision.Reasons = []Reason{newReason(ReasonStaleMetricsHold,
+		"age_seconds", age.Seconds(), "max_age_seconds", s.config.StaleMetrics.MaxAge.Seconds())}
+	decision.Reasoning = RenderReasons(decision.Reasons)
+	s.storeDecision(key, decision)
+	return decision
+}
+
+// trackPredictions records what every loaded model version predicts for this
+// sample so versions can be compared once the outcome is known. Shadow
+// predictions are also logged next to the decision-driving prediction.
+func (s *AIScaler) trackPredictions(key string, metricsData *metrics.MetricsData, features FeatureVector, decisionFactor float64) {
+	throttled := s.isThrottled()
+	s.mu.RLock()
+	candidates := map[string]AIModel{versionLabel(s.modelVersion): s.model}
+	if s.canaryModel != nil {
+		candidates[versionLabel(s.canaryVersion)] = s.canaryModel
+	}
+	// Shadow predictions are skipped while the controller throttles itself
+	hasShadow := s.shadowModel != nil && !throttled
+	if hasShadow {
+		candidates[versionLabel(s.shadowVersion)] = s.shadowModel
+	}
+	shadowVersion := s.shadowVersion
+	s.mu.RUnlock()
+
+	predictions := make(map[string]float64, len(candidates))
+	for version, model := range candidates {
+		factor, _, err := model.Predict(features)
+		if err != nil {
+			logrus.WithError(err).WithField("model_version", version).Debug("Model prediction failed")
+			continue
+		}
+		predictions[version] = factor
+	}
+	if !s.inMaintenanceWindow(key, metricsData.Timestamp) {
+		s.outcomes.Record(key, metricsData, features, predictions)
+	}
+
+	if hasShadow {
+		logrus.WithFields(logrus.Fields{
+			"service":         metricsData.ServiceName,
+			"namespace":       metricsData.Namespace,
+			"shadow_version":  shadowVersion,
+			"decision_factor": decisionFactor,
+			"shadow_factor":   predictions[versionLabel(shadowVersion)],
+		}).Debug("Shadow model prediction")
+	}
+}
+
+// versionLabel returns the label used to track a model version's predictions
+func versionLabel(version string) string {
+	if version == "" {
+		return UnversionedModel
+	}
+	return version
+}
+
+// inCanaryCohort reports whether a service key is served by the canary model.
+// An explicit allowlist takes precedence over percentage-based selection,
+// which hashes the key so membership is stable across restarts.
+func inCanaryCohort(key string, cfg config.CanaryConfig) bool {
+	if len(cfg.Services) > 0 {
+		for _, service := range cfg.Services {
+			if service == key {
+				return true
+			}
+		}
+		return false
+	}
+
+	if cfg.Percentage <= 0 {
+		return false
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000)/100.0 < cfg.Percentage
+}
+
 // extractFeatures converts metrics data to feature vector
 func (s *AIScaler) extractFeatures(metricsData *metrics.MetricsData) FeatureVector {
-	now := time.Now()
+	features := FeaturesFromMetrics(metricsData)
+
+	// Calculate trends (simplified implementation)
+	features.TrendCPU = s.calculateTrend(metricsData.ServiceName, metricsData.Namespace, "cpu")
+	features.TrendMemory = s.calculateTrend(metricsData.ServiceName, metricsData.Namespace, "memory")
+	features.TrendRequests = s.calculateTrend(metricsData.ServiceName, metricsData.Namespace, "requests")
+
+	// Node-level contention from batch jobs inflates per-pod CPU
+	if metricsData.BatchContention && s.config.BatchContention.Action == BatchContentionRelax {
+		features.CPUUtilization = relaxContention(features.CPUUtilization, s.config.BatchContention.Relax)
+	}
+
+	s.holidays.Apply(metricsData, &features)
+	s.derived.Apply(metricsData, &features)
 
-	features := FeatureVector{
+	// Zero the features disabled globally or by the service's policy
+	key := fmt.Sprintf("%s/%s", metricsData.Namespace, metricsData.ServiceName)
+	s.settingsFor(key).DisabledFeatures.Apply(&features)
+
+	if s.config.AIModel.ModelType == "gru" {
+		features.Sequence = s.recordSequence(key, features)
+	}
+
+	return features
+}
+
+// recordSequence appends a sample to the service's sequence buffer and
+// returns the samples that preceded it, oldest first
+func (s *AIScaler) recordSequence(key string, features FeatureVector) []FeatureVector {
+	length := s.config.AIModel.Hyperparameters.SequenceLength
+	if length <= 0 {
+		length = defaultSequenceLength
+	}
+
+	return s.states.recordSequence(key, features, length)
+}
+
+// FeaturesFromMetrics converts a single metrics sample to a feature vector.
+// Temporal features are derived from the sample timestamp so archived data
+// produces the same features it would have produced live; trends and the
+// holiday flag are left zero.
+func FeaturesFromMetrics(metricsData *metrics.MetricsData) FeatureVector {
+	ts := sampleTime(metricsData)
+	hourSin, hourCos := cyclical(float64(ts.Hour())+float64(ts.Minute())/60, 24)
+	daySin, dayCos := cyclical(float64(ts.Weekday()), 7)
+
+	var windows []float64
+	for _, w := range metricsData.Windows {
+		windows = append(windows, w.CPUMean, w.CPUMax, w.MemoryMean, w.MemoryMax, w.RequestRateMean, w.RequestRateMax)
+	}
+
+	return FeatureVector{
 		CPUUtilization:    metricsData.CPUUtilization,
 		MemoryUtilization: metricsData.MemoryUtilization,
 		RequestRate:       metricsData.RequestRate,
@@ -200,16 +952,29 @@ func (s *AIScaler) extractFeatures(metricsData *metrics.MetricsData) FeatureVect
 		IOBandwidth:       metricsData.IOBandwidth,
 		ResponseTime:      metricsData.ResponseTime,
 		ErrorRate:         metricsData.ErrorRate,
-		TimeOfDay:         float64(now.Hour()),
-		DayOfWeek:         float64(now.Weekday()),
+		HourSin:           hourSin,
+		HourCos:           hourCos,
+		DaySin:            daySin,
+		DayCos:            dayCos,
+		CurrentReplicas:   float64(metricsData.CurrentReplicas),
+		Windows:           windows,
 	}
+}
 
-	// Calculate trends (simplified implementation)
-	features.TrendCPU = s.calculateTrend(metricsData.ServiceName, metricsData.Namespace, "cpu")
-	features.TrendMemory = s.calculateTrend(metricsData.ServiceName, metricsData.Namespace, "memory")
-	features.TrendRequests = s.calculateTrend(metricsData.ServiceName, metricsData.Namespace, "requests")
+// sampleTime returns the timestamp of a metrics sample, or the current time
+// for samples without one
+func sampleTime(metricsData *metrics.MetricsData) time.Time {
+	if metricsData.Timestamp.IsZero() {
+		return time.Now()
+	}
+	return metricsData.Timestamp
+}
 
-	return features
+// cyclical encodes a position within a period as the sine and cosine of
+// its angle
+func cyclical(value, period float64) (float64, float64) {
+	angle := 2 * math.Pi * value / period
+	return math.Sin(angle), math.Cos(angle)
 }
 
 // calculateTrend calculates the trend for a specific metric (simplified)
@@ -219,70 +984,68 @@ func (s *AIScaler) calculateTrend(serviceName, namespace, metricType string) flo
 	return 0.0
 }
 
+// predict asks the model for a scale factor and confidence. In the absolute
+// target mode, a model implementing TargetModel is asked for a target
+// instead, which is reported as the equivalent factor of the current
+// replicas; other models, and targets that can't be used, fall back to the
+// factor.
+func (s *AIScaler) predict(model AIModel, features FeatureVector, currentReplicas int32, settings ServiceSettings) (float64, float64, bool, error) {
+	if s.config.TargetMode == TargetModeAbsolute {
+		if targetModel, ok := model.(TargetModel); ok {
+			target, err := targetModel.PredictTarget(features)
+			if err != nil {
+				return 0, 0, false, err
+			}
+			if replicas, ok := targetReplicas(target, settings.RequestsPerReplica); ok {
+				return targetFactor(replicas, currentReplicas), target.Confidence, true, nil
+			}
+		}
+	}
+
+	scaleFactor, confidence, err := model.Predict(features)
+	return scaleFactor, confidence, false, err
+}
+
 // calculateRecommendedReplicas calculates the number of replicas based on scale factor
 func (s *AIScaler) calculateRecommendedReplicas(currentReplicas int32, scaleFactor float64) int32 {
+	// The tolerance keeps factors derived from absolute targets exact
+	const tolerance = 1e-9
 	if scaleFactor > 1.1 { // Scale up threshold
-		return int32(math.Ceil(float64(currentReplicas) * scaleFactor))
+		return int32(math.Ceil(float64(currentReplicas)*scaleFactor - tolerance))
 	} else if scaleFactor < 0.9 { // Scale down threshold
-		return int32(math.Floor(float64(currentReplicas) * scaleFactor))
+		return int32(math.Floor(float64(currentReplicas)*scaleFactor + tolerance))
 	}
 	return currentReplicas // No scaling needed
 }
 
-// applyConstraints applies min/max replica constraints
-func (s *AIScaler) applyConstraints(replicas int32) int32 {
-	if replicas < s.config.MinReplicas {
-		return s.config.MinReplicas
+// applyConstraints applies the service's min/max replica constraints
+func (s *AIScaler) applyConstraints(key string, replicas int32) int32 {
+	settings := s.settingsFor(key)
+	if replicas < settings.MinReplicas {
+		return settings.MinReplicas
 	}
-	if replicas > s.config.MaxReplicas {
-		return s.config.MaxReplicas
+	if replicas > settings.MaxReplicas {
+		return settings.MaxReplicas
 	}
 	return replicas
 }
 
-// generateReasoning creates a human-readable explanation for the scaling decision
-func (s *AIScaler) generateReasoning(features FeatureVector, scaleFactor float64, confidence float64) string {
-	var reasons []string
-
-	if features.CPUUtilization > 80 {
-		reasons = append(reasons, "high CPU utilization")
+// discountStaleness halves confidence for every half-life of metrics staleness
+func discountStaleness(confidence, stalenessSeconds float64, halfLife time.Duration) float64 {
+	if stalenessSeconds <= 0 || halfLife <= 0 {
+		return confidence
 	}
-	if features.MemoryUtilization > 80 {
-		reasons = append(reasons, "high memory utilization")
-	}
-	if features.RequestRate > 100 {
-		reasons = append(reasons, "high request rate")
-	}
-	if features.ErrorRate > 5 {
-		reasons = append(reasons, "elevated error rate")
-	}
-	if features.ResponseTime > 1000 {
-		reasons = append(reasons, "slow response times")
-	}
-
-	if len(reasons) == 0 {
-		if scaleFactor > 1.1 {
-			return fmt.Sprintf("AI model recommends scaling up (factor: %.2f, confidence: %.2f)", scaleFactor, confidence)
-		} else if scaleFactor < 0.9 {
-			return fmt.Sprintf("AI model recommends scaling down (factor: %.2f, confidence: %.2f)", scaleFactor, confidence)
-		}
-		return "No scaling needed based on current metrics"
-	}
-
-	action := "up"
-	if scaleFactor < 1.0 {
-		action = "down"
-	}
-
-	return fmt.Sprintf("Scaling %s due to: %v (factor: %.2f, confidence: %.2f)", action, reasons, scaleFactor, confidence)
+	return confidence * math.Pow(0.5, stalenessSeconds/halfLife.Seconds())
 }
 
 // isInCooldown checks if a service is in cooldown period
 func (s *AIScaler) isInCooldown(key string) bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	// The stabilization windows of an HPA behavior replace the cooldowns
+	if s.settingsFor(key).Behavior != nil {
+		return false
+	}
 
-	lastTime, exists := s.cooldownTracker[key]
+	lastTime, exists := s.states.lastScaled(key)
 	if !exists {
 		return false
 	}
@@ -295,52 +1058,166 @@ func (s *AIScaler) isInCooldown(key string) bool {
 	return scaleUpCooldown || scaleDownCooldown
 }
 
-// storeDecision stores a scaling decision and updates cooldown
-func (s *AIScaler) storeDecision(key string, decision *ScalingDecision) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// InCooldown reports whether a service (namespace/name) is in its post-scaling cooldown
+func (s *AIScaler) InCooldown(key string) bool {
+	return s.isInCooldown(key)
+}
 
-	s.lastDecisions[key] = decision
+// ModelTrained reports whether the active model has been trained. An
+// untrained model falls back to heuristic predictions.
+func (s *AIScaler) ModelTrained() bool {
+	model, _ := s.currentModel()
+	return isTrained(model)
+}
 
-	// Update cooldown only if scaling is recommended
-	if decision.CurrentReplicas != decision.RecommendedReplicas {
-		s.cooldownTracker[key] = decision.Timestamp
+// isTrained reports whether a model has learned parameters; an ensemble
+// counts as trained when any member is
+func isTrained(model AIModel) bool {
+	switch m := model.(type) {
+	case *LinearModel:
+		return m.IsTrained
+	case *NeuralNetwork:
+		return m.IsTrained
+	case *QuantileModel:
+		return m.IsTrained
+	case *GRUModel:
+		return m.IsTrained
+	case *LittlesLawModel:
+		return m.IsTrained
+	case *EnsembleModel:
+		for _, member := range m.Models {
+			if isTrained(member) {
+				return true
+			}
+		}
 	}
+	return false
+}
+
+// storeDecision stores a scaling decision and updates cooldown
+func (s *AIScaler) storeDecision(key string, decision *ScalingDecision) {
+	s.states.storeDecision(key, decision)
 }
 
 // AddTrainingData adds new training data for model improvement
 func (s *AIScaler) AddTrainingData(data TrainingData) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	added := s.states.addTraining(data)
 
-	s.trainingData = append(s.trainingData, data)
+	// Retrain model periodically
+	if s.config.AIModel.EnableOnlineLearning && added%100 == 0 {
+		s.startRetrain()
+	}
+}
 
-	// Limit training data size
-	maxSize := 10000
-	if len(s.trainingData) > maxSize {
-		s.trainingData = s.trainingData[len(s.trainingData)-maxSize:]
+// Bootstrap adds the training data derived from a service's backfilled
+// metrics history and, with online learning, retrains on it right away
+func (s *AIScaler) Bootstrap(key string, history []*metrics.MetricsData) {
+	sequenceLength := 0
+	if s.config.AIModel.ModelType == "gru" {
+		sequenceLength = s.config.AIModel.Hyperparameters.SequenceLength
+	}
+	settings := s.settingsFor(key)
+	data := TrainingDataFromMetrics(history, s.derived, s.holidays, settings.MaintenanceWindows, settings.DisabledFeatures, sequenceLength, s.config.AIModel.TrainingQuality.IncidentErrorRate)
+	if len(data) == 0 {
+		return
 	}
 
-	// Retrain model periodically
-	if s.config.AIModel.EnableOnlineLearning && len(s.trainingData)%100 == 0 {
-		go s.retrainModel()
+	s.states.prependTraining(key, data)
+
+	logrus.WithFields(logrus.Fields{
+		"service": key,
+		"samples": len(data),
+	}).Info("Added bootstrapped training data")
+
+	if s.config.AIModel.EnableOnlineLearning {
+		s.startRetrain()
 	}
 }
 
-// retrainModel retrains the AI model with collected data
+// startRetrain retrains the model in the background, or once more after
+// the retrain in progress
+func (s *AIScaler) startRetrain() {
+	s.retrainMu.Lock()
+	defer s.retrainMu.Unlock()
+
+	if s.throttled != "" {
+		s.retrainPostponed = true
+		return
+	}
+	if s.retrainActive {
+		s.retrainQueued = true
+		return
+	}
+	s.retrainActive = true
+	s.retraining.Add(1)
+	go func() {
+		defer s.retraining.Done()
+		for {
+			s.retrainModel()
+
+			s.retrainMu.Lock()
+			queued := s.retrainQueued
+			s.retrainQueued, s.retrainActive = false, queued
+			s.retrainMu.Unlock()
+			if !queued {
+				return
+			}
+		}
+	}()
+}
+
+// retrainModel retrains the AI model with collected data. A copy of the
+// model is trained while the original keeps serving decisions, and replaces
+// it unless another model was set meanwhile.
 func (s *AIScaler) retrainModel() {
-	s.mu.RLock()
-	trainingData := make([]TrainingData, len(s.trainingData))
-	copy(trainingData, s.trainingData)
-	s.mu.RUnlock()
+	trainingData := s.states.trainingData()
 
-	logrus.Info("Retraining AI model with %d data points", len(trainingData))
+	if s.config.AIModel.TrainingQuality.Enabled {
+		trainingData = s.filterTrainingData(trainingData)
+	}
+	if s.config.AIModel.RevisionWeighting.Enabled {
+		trainingData = WeightByRevision(trainingData, s.config.AIModel.RevisionWeighting)
+	}
+
+	logrus.Infof("Retraining AI model with %d data points", len(trainingData))
 
-	if err := s.model.Train(trainingData); err != nil {
+	current, _ := s.currentModel()
+	model := trainableCopy(current, s.config.AIModel)
+	if err := model.Train(balanceIf(trainingData, s.config.AIModel.ClassBalance)); err != nil {
 		logrus.WithError(err).Error("Failed to retrain AI model")
-	} else {
-		logrus.Info("AI model retrained successfully")
+		s.recordTraining(err)
+		return
+	}
+
+	s.mu.Lock()
+	replaced := s.model == current
+	if replaced {
+		s.model = model
 	}
+	s.mu.Unlock()
+	if !replaced {
+		logrus.Info("Model changed while retraining, discarding the retrained copy")
+		return
+	}
+
+	s.recordTraining(nil)
+	s.SetFeatureReference(BuildFeatureReference(trainingData))
+	logrus.Info("AI model retrained successfully")
+	s.updateImportance(model, trainingData)
+}
+
+// trainableCopy returns a copy of a model to train without disturbing the
+// original. Trained state is carried over through an artifact, so models
+// that train incrementally continue from it; models without artifact
+// support or not yet trained are replaced by a new model of the same type.
+func trainableCopy(model AIModel, cfg config.AIModelConfig) AIModel {
+	if artifact, err := NewModelArtifact(model); err == nil {
+		if copied, err := artifact.Model(cfg); err == nil {
+			return copied
+		}
+	}
+	cfg.ModelType = model.GetModelType()
+	return NewModel(cfg)
 }
 
 // Linear Model Implementation
@@ -374,28 +1251,38 @@ func (lm *LinearModel) Train(data []TrainingData) error {
 		return fmt.Errorf("insufficient training data")
 	}
 
-	// Prepare training data
-	numFeatures := 12 // Number of features in FeatureVector
-	X := mat.NewDense(len(data), numFeatures, nil)
+	// Prepare training data; the last column is the intercept term
+	numFeatures := len(lm.featuresToSlice(data[0].Features))
+	X := mat.NewDense(len(data), numFeatures+1, nil)
 	y := mat.NewVecDense(len(data), nil)
 
 	for i, sample := range data {
+		// Scaling a row by the root of its weight gives weighted least squares
+		rowScale := math.Sqrt(sampleWeight(sample))
 		features := lm.featuresToSlice(sample.Features)
 		for j, feature := range features {
 			if j < numFeatures {
-				X.Set(i, j, feature)
+				X.Set(i, j, feature*rowScale)
 			}
 		}
-		y.SetVec(i, sample.ActualScale)
+		X.Set(i, numFeatures, rowScale)
+		// Fit in the pre-sigmoid space so Predict maps back to the observed scale
+		y.SetVec(i, scaleToLogit(sample.ActualScale)*rowScale)
 	}
 
-	// Simple linear regression using normal equation
+	// Ridge regression using the regularized normal equation
 	var xT mat.Dense
 	xT.CloneFrom(X.T())
 
 	var xTx mat.Dense
 	xTx.Mul(&xT, X)
 
+	// A minimal penalty keeps X'X invertible when a feature is constant
+	penalty := math.Max(lm.Regularization, minRidgePenalty)
+	for i := 0; i < numFeatures; i++ {
+		xTx.Set(i, i, xTx.At(i, i)+penalty)
+	}
+
 	var xTxInv mat.Dense
 	if err := xTxInv.Inverse(&xTx); err != nil {
 		return fmt.Errorf("failed to compute matrix inverse: %w", err)
@@ -412,6 +1299,7 @@ func (lm *LinearModel) Train(data []TrainingData) error {
 	for i := 0; i < numFeatures; i++ {
 		lm.Weights[i] = weights.AtVec(i)
 	}
+	lm.Bias = weights.AtVec(numFeatures)
 
 	lm.IsTrained = true
 	return nil
@@ -422,7 +1310,7 @@ func (lm *LinearModel) GetModelType() string {
 }
 
 func (lm *LinearModel) featuresToSlice(features FeatureVector) []float64 {
-	return []float64{
+	input := []float64{
 		features.CPUUtilization / 100.0,
 		features.MemoryUtilization / 100.0,
 		features.RequestRate / 1000.0,
@@ -430,14 +1318,42 @@ func (lm *LinearModel) featuresToSlice(features FeatureVector) []float64 {
 		features.IOBandwidth / 100.0,
 		features.ResponseTime / 1000.0,
 		features.ErrorRate / 100.0,
-		features.TimeOfDay / 24.0,
-		features.DayOfWeek / 7.0,
+		features.HourSin,
+		features.HourCos,
+		features.DaySin,
+		features.DayCos,
+		features.Holiday,
 		features.TrendCPU,
 		features.TrendMemory,
 		features.TrendRequests,
 	}
+
+	// Derived features are used as defined; expressions should keep them roughly in [0, 1]
+	input = append(input, features.Derived...)
+
+	// Window aggregates come in CPU, memory and request rate pairs per window
+	for i, value := range features.Windows {
+		input = append(input, value/windowScales[(i/2)%len(windowScales)])
+	}
+	return input
+}
+
+// WindowNames lists aggregation windows in the order their aggregates
+// appear in FeatureVector.Windows
+func WindowNames(windows []time.Duration) []string {
+	sorted := append([]time.Duration(nil), windows...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	names := make([]string, len(sorted))
+	for i, window := range sorted {
+		names[i] = window.String()
+	}
+	return names
 }
 
+// windowScales normalizes the CPU, memory and request rate window aggregates
+var windowScales = []float64{100.0, 100.0, 1000.0}
+
 func (lm *LinearModel) heuristicPredict(features FeatureVector) float64 {
 	// Simple heuristic-based scaling
 	scaleFactor := 1.0
@@ -468,10 +1384,29 @@ func (lm *LinearModel) heuristicPredict(features FeatureVector) float64 {
 
 // Utility functions
 
+// fitLength truncates or zero-pads a feature slice to n values so samples
+// extracted under different derived feature configurations line up
+func fitLength(input []float64, n int) []float64 {
+	if len(input) == n {
+		return input
+	}
+	fitted := make([]float64, n)
+	copy(fitted, input)
+	return fitted
+}
+
 func sigmoid(x float64) float64 {
 	return 1.0 / (1.0 + math.Exp(-x))
 }
 
+// scaleToLogit inverts the 0.5 + 1.5*sigmoid(x) output mapping used by the
+// trained models, clamping to keep the logit finite
+func scaleToLogit(scale float64) float64 {
+	p := (scale - 0.5) / 1.5
+	p = math.Max(0.001, math.Min(0.999, p))
+	return math.Log(p / (1 - p))
+}
+
 // Neural Network Implementation (simplified)
 
 func (nn *NeuralNetwork) Predict(features FeatureVector) (float64, float64, error) {
@@ -511,12 +1446,103 @@ func (nn *NeuralNetwork) Predict(features FeatureVector) (float64, float64, erro
 }
 
 func (nn *NeuralNetwork) Train(data []TrainingData) error {
-	// Simplified training implementation
-	// In production, you'd implement proper backpropagation
+	if len(data) < 10 {
+		return fmt.Errorf("insufficient training data")
+	}
+
+	numInputs := len(nn.featuresToSlice(data[0].Features))
+	inputs := make([][]float64, len(data))
+	for i, sample := range data {
+		inputs[i] = fitLength(nn.featuresToSlice(sample.Features), numInputs)
+	}
+
+	numHidden := nn.HiddenUnits
+	if numHidden <= 0 {
+		numHidden = defaultHiddenUnits
+	}
+	if !nn.IsTrained || nn.Weights1 == nil {
+		nn.initWeights(numInputs, numHidden)
+	} else if _, cols := nn.Weights1.Dims(); cols != numInputs {
+		// The feature set changed, e.g. derived features were added
+		nn.initWeights(numInputs, numHidden)
+	}
+	numHidden = len(nn.HiddenLayer)
+
+	learningRate := nn.LearningRate
+	if learningRate <= 0 {
+		learningRate = 0.01
+	}
+	epochs := nn.Epochs
+	if epochs <= 0 {
+		epochs = defaultEpochs
+	}
+
+	hidden := make([]float64, numHidden)
+	for epoch := 0; epoch < epochs; epoch++ {
+		for i, input := range inputs {
+			// Forward pass
+			for h := 0; h < numHidden; h++ {
+				sum := nn.Bias1[h]
+				for j, inp := range input {
+					sum += nn.Weights1.At(h, j) * inp
+				}
+				hidden[h] = sigmoid(sum)
+			}
+			output := nn.Bias2[0]
+			for h := 0; h < numHidden; h++ {
+				output += nn.Weights2.At(h, 0) * hidden[h]
+			}
+			outSig := sigmoid(output)
+			prediction := 0.5 + 1.5*outSig
+
+			// Backward pass on squared error of the scale factor
+			gradOut := (prediction - data[i].ActualScale) * 1.5 * outSig * (1 - outSig) * sampleWeight(data[i])
+			for h := 0; h < numHidden; h++ {
+				w2 := nn.Weights2.At(h, 0)
+				gradHidden := gradOut * w2 * hidden[h] * (1 - hidden[h])
+				nn.Weights2.Set(h, 0, w2-learningRate*gradOut*hidden[h])
+				for j, inp := range input {
+					nn.Weights1.Set(h, j, nn.Weights1.At(h, j)-learningRate*gradHidden*inp)
+				}
+				nn.Bias1[h] -= learningRate * gradHidden
+			}
+			nn.Bias2[0] -= learningRate * gradOut
+		}
+	}
+
 	nn.IsTrained = true
 	return nil
 }
 
+// initWeights initializes the network with small random weights
+func (nn *NeuralNetwork) initWeights(numInputs, numHidden int) {
+	rng := newRand(nn.Config.Seed)
+	scale := 1.0 / math.Sqrt(float64(numInputs))
+
+	nn.InputLayer = make([]float64, numInputs)
+	nn.HiddenLayer = make([]float64, numHidden)
+	nn.OutputLayer = make([]float64, 1)
+	nn.Weights1 = mat.NewDense(numHidden, numInputs, nil)
+	nn.Weights2 = mat.NewDense(numHidden, 1, nil)
+	for h := 0; h < numHidden; h++ {
+		for j := 0; j < numInputs; j++ {
+			nn.Weights1.Set(h, j, rng.NormFloat64()*scale)
+		}
+		nn.Weights2.Set(h, 0, rng.NormFloat64()*scale)
+	}
+	nn.Bias1 = make([]float64, numHidden)
+	nn.Bias2 = make([]float64, 1)
+}
+
+// newRand returns a random source seeded from a configured seed, or from
+// the clock when the seed is 0
+func newRand(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
 func (nn *NeuralNetwork) GetModelType() string {
 	return "neural_network"
 }
@@ -0,0 +1,359 @@
+package scaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// ArtifactFormatVersion is the current model artifact format version.
+// Version 2 replaced the raw time of day and day of week inputs with their
+// cyclical encodings and the holiday flag.
+const ArtifactFormatVersion = 2
+
+// ModelArtifact is the serialized form of a trained model that can be
+// produced offline by hydra-train and loaded by the controller
+type ModelArtifact struct {
+	FormatVersion   int                `json:"format_version"`
+	ModelType       string             `json:"model_type"`
+	CreatedAt       time.Time          `json:"created_at"`
+	TrainingSamples int                `json:"training_samples"`
+	Hyperparameters map[string]float64 `json:"hyperparameters,omitempty"`
+	Evaluation      *EvaluationResult  `json:"evaluation,omitempty"`
+
+	// Time range covered by the training samples
+	TrainingWindowStart time.Time `json:"training_window_start,omitempty"`
+	TrainingWindowEnd   time.Time `json:"training_window_end,omitempty"`
+
+	// Feature distributions of the training data, used for drift detection
+	FeatureReference FeatureReference `json:"feature_reference,omitempty"`
+
+	// Names of the derived features the model was trained with, in order
+	DerivedFeatures []string `json:"derived_features,omitempty"`
+
+	// Names of the base features zeroed during training, in FeatureNames order
+	DisabledFeatures []string `json:"disabled_features,omitempty"`
+
+	// Collector aggregation windows the model was trained with, shortest first
+	FeatureWindows []string `json:"feature_windows,omitempty"`
+
+	// Feature importances on the holdout data
+	FeatureImportance *ImportanceReport `json:"feature_importance,omitempty"`
+
+	Linear        *LinearModelState   `json:"linear,omitempty"`
+	NeuralNetwork *NeuralNetworkState `json:"neural_network,omitempty"`
+	Quantile      *QuantileModelState `json:"quantile,omitempty"`
+	LittlesLaw    *LittlesLawState    `json:"littles_law,omitempty"`
+	GRU           *GRUModelState      `json:"gru,omitempty"`
+	Ensemble      []EnsembleMember    `json:"ensemble,omitempty"`
+}
+
+// LinearModelState holds the learned parameters of a LinearModel
+type LinearModelState struct {
+	Weights        []float64 `json:"weights"`
+	Bias           float64   `json:"bias"`
+	Regularization float64   `json:"regularization"`
+}
+
+// NeuralNetworkState holds the learned parameters of a NeuralNetwork
+type NeuralNetworkState struct {
+	Weights1     [][]float64 `json:"weights1"`
+	Weights2     []float64   `json:"weights2"`
+	Bias1        []float64   `json:"bias1"`
+	Bias2        float64     `json:"bias2"`
+	LearningRate float64     `json:"learning_rate"`
+	Epochs       int         `json:"epochs"`
+}
+
+// LittlesLawState holds the calibration of a LittlesLawModel
+type LittlesLawState struct {
+	TargetConcurrency float64 `json:"target_concurrency"`
+	Efficiency        float64 `json:"efficiency"`
+	Confidence        float64 `json:"confidence"`
+	Trained           bool    `json:"trained"`
+}
+
+// QuantileModelState holds the learned parameters of a QuantileModel
+type QuantileModelState struct {
+	Weights  []float64 `json:"weights"`
+	Bias     float64   `json:"bias"`
+	Quantile float64   `json:"quantile"`
+}
+
+// GRUModelState holds the learned parameters of a GRUModel
+type GRUModelState struct {
+	Wz             [][]float64 `json:"wz"`
+	Wr             [][]float64 `json:"wr"`
+	Wh             [][]float64 `json:"wh"`
+	Bz             []float64   `json:"bz"`
+	Br             []float64   `json:"br"`
+	Bh             []float64   `json:"bh"`
+	Wo             []float64   `json:"wo"`
+	Bo             float64     `json:"bo"`
+	SequenceLength int         `json:"sequence_length"`
+}
+
+// EnsembleMember is a weighted member of a serialized ensemble
+type EnsembleMember struct {
+	Weight float64        `json:"weight"`
+	Model  *ModelArtifact `json:"model"`
+}
+
+// NewModelArtifact captures the learned state of a model
+func NewModelArtifact(model AIModel) (*ModelArtifact, error) {
+	artifact := &ModelArtifact{
+		FormatVersion: ArtifactFormatVersion,
+		ModelType:     model.GetModelType(),
+		CreatedAt:     time.Now(),
+	}
+
+	switch m := model.(type) {
+	case *LinearModel:
+		if !m.IsTrained {
+			return nil, fmt.Errorf("linear model is not trained")
+		}
+		artifact.Linear = &LinearModelState{
+			Weights:        append([]float64(nil), m.Weights...),
+			Bias:           m.Bias,
+			Regularization: m.Regularization,
+		}
+	case *NeuralNetwork:
+		if !m.IsTrained || m.Weights1 == nil {
+			return nil, fmt.Errorf("neural network is not trained")
+		}
+		rows, cols := m.Weights1.Dims()
+		state := &NeuralNetworkState{
+			Weights1:     make([][]float64, rows),
+			Weights2:     make([]float64, rows),
+			Bias1:        append([]float64(nil), m.Bias1...),
+			Bias2:        m.Bias2[0],
+			LearningRate: m.LearningRate,
+			Epochs:       m.Epochs,
+		}
+		for i := 0; i < rows; i++ {
+			state.Weights1[i] = make([]float64, cols)
+			for j := 0; j < cols; j++ {
+				state.Weights1[i][j] = m.Weights1.At(i, j)
+			}
+			state.Weights2[i] = m.Weights2.At(i, 0)
+		}
+		artifact.NeuralNetwork = state
+	case *QuantileModel:
+		if !m.IsTrained {
+			return nil, fmt.Errorf("quantile model is not trained")
+		}
+		artifact.Quantile = &QuantileModelState{
+			Weights:  append([]float64(nil), m.Weights...),
+			Bias:     m.Bias,
+			Quantile: m.Quantile,
+		}
+	case *GRUModel:
+		if !m.IsTrained {
+			return nil, fmt.Errorf("gru model is not trained")
+		}
+		artifact.GRU = &GRUModelState{
+			Wz:             copyMatrix(m.Wz),
+			Wr:             copyMatrix(m.Wr),
+			Wh:             copyMatrix(m.Wh),
+			Bz:             append([]float64(nil), m.Bz...),
+			Br:             append([]float64(nil), m.Br...),
+			Bh:             append([]float64(nil), m.Bh...),
+			Wo:             append([]float64(nil), m.Wo...),
+			Bo:             m.Bo,
+			SequenceLength: m.SequenceLength,
+		}
+	case *LittlesLawModel:
+		// The formula works uncalibrated, so untrained models are saved too
+		artifact.LittlesLaw = &LittlesLawState{
+			TargetConcurrency: m.TargetConcurrency,
+			Efficiency:        m.Efficiency,
+			Confidence:        m.Confidence,
+			Trained:           m.IsTrained,
+		}
+	case *EnsembleModel:
+		for i, member := range m.Models {
+			memberArtifact, err := NewModelArtifact(member)
+			if err != nil {
+				return nil, fmt.Errorf("ensemble member %d: %w", i, err)
+			}
+			artifact.Ensemble = append(artifact.Ensemble, EnsembleMember{
+				Weight: m.Weights[i],
+				Model:  memberArtifact,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("model type %q does not support artifacts", model.GetModelType())
+	}
+
+	return artifact, nil
+}
+
+// Model reconstructs a trained model from the artifact
+func (a *ModelArtifact) Model(cfg config.AIModelConfig) (AIModel, error) {
+	if a.FormatVersion > ArtifactFormatVersion {
+		return nil, fmt.Errorf("unsupported artifact format version %d", a.FormatVersion)
+	}
+	if a.FormatVersion < 2 {
+		return nil, fmt.Errorf("artifact format version %d predates the cyclical time features; retrain the model", a.FormatVersion)
+	}
+
+	switch a.ModelType {
+	case "linear":
+		if a.Linear == nil {
+			return nil, fmt.Errorf("artifact is missing linear model state")
+		}
+		return &LinearModel{
+			Weights:        append([]float64(nil), a.Linear.Weights...),
+			Bias:           a.Linear.Bias,
+			Regularization: a.Linear.Regularization,
+			IsTrained:      true,
+			Config:         cfg,
+		}, nil
+	case "neural_network":
+		state := a.NeuralNetwork
+		if state == nil || len(state.Weights1) == 0 {
+			return nil, fmt.Errorf("artifact is missing neural network state")
+		}
+		rows, cols := len(state.Weights1), len(state.Weights1[0])
+		if len(state.Weights2) != rows || len(state.Bias1) != rows {
+			return nil, fmt.Errorf("neural network state has inconsistent dimensions")
+		}
+		nn := &NeuralNetwork{
+			InputLayer:   make([]float64, cols),
+			HiddenLayer:  make([]float64, rows),
+			OutputLayer:  make([]float64, 1),
+			Weights1:     mat.NewDense(rows, cols, nil),
+			Weights2:     mat.NewDense(rows, 1, nil),
+			Bias1:        append([]float64(nil), state.Bias1...),
+			Bias2:        []float64{state.Bias2},
+			LearningRate: state.LearningRate,
+			HiddenUnits:  rows,
+			Epochs:       state.Epochs,
+			IsTrained:    true,
+			Config:       cfg,
+		}
+		for i := 0; i < rows; i++ {
+			if len(state.Weights1[i]) != cols {
+				return nil, fmt.Errorf("neural network state has inconsistent dimensions")
+			}
+			for j := 0; j < cols; j++ {
+				nn.Weights1.Set(i, j, state.Weights1[i][j])
+			}
+			nn.Weights2.Set(i, 0, state.Weights2[i])
+		}
+		return nn, nil
+	case "quantile":
+		if a.Quantile == nil {
+			return nil, fmt.Errorf("artifact is missing quantile model state")
+		}
+		model := newQuantileModel(cfg)
+		model.Weights = append([]float64(nil), a.Quantile.Weights...)
+		model.Bias = a.Quantile.Bias
+		model.Quantile = a.Quantile.Quantile
+		model.IsTrained = true
+		return model, nil
+	case "gru":
+		state := a.GRU
+		if state == nil {
+			return nil, fmt.Errorf("artifact is missing gru model state")
+		}
+		hidden := len(state.Bz)
+		if hidden == 0 || len(state.Wz) != hidden || len(state.Wr) != hidden || len(state.Wh) != hidden ||
+			len(state.Br) != hidden || len(state.Bh) != hidden || len(state.Wo) != hidden {
+			return nil, fmt.Errorf("gru state has inconsistent dimensions")
+		}
+		width := len(state.Wz[0])
+		for k := 0; k < hidden; k++ {
+			if len(state.Wz[k]) != width || len(state.Wr[k]) != width || len(state.Wh[k]) != width {
+				return nil, fmt.Errorf("gru state has inconsistent dimensions")
+			}
+		}
+		model := newGRUModel(cfg)
+		model.Wz = copyMatrix(state.Wz)
+		model.Wr = copyMatrix(state.Wr)
+		model.Wh = copyMatrix(state.Wh)
+		model.Bz = append([]float64(nil), state.Bz...)
+		model.Br = append([]float64(nil), state.Br...)
+		model.Bh = append([]float64(nil), state.Bh...)
+		model.Wo = append([]float64(nil), state.Wo...)
+		model.Bo = state.Bo
+		model.HiddenUnits = hidden
+		if state.SequenceLength > 0 {
+			model.SequenceLength = state.SequenceLength
+		}
+		model.IsTrained = true
+		return model, nil
+	case "littles_law":
+		if a.LittlesLaw == nil {
+			return nil, fmt.Errorf("artifact is missing littles_law model state")
+		}
+		model := newLittlesLawModel(cfg)
+		model.TargetConcurrency = a.LittlesLaw.TargetConcurrency
+		model.Efficiency = a.LittlesLaw.Efficiency
+		model.Confidence = a.LittlesLaw.Confidence
+		model.IsTrained = a.LittlesLaw.Trained
+		return model, nil
+	case "ensemble":
+		if len(a.Ensemble) == 0 {
+			return nil, fmt.Errorf("artifact has no ensemble members")
+		}
+		ensemble := &EnsembleModel{Config: cfg}
+		for i, member := range a.Ensemble {
+			if member.Model == nil {
+				return nil, fmt.Errorf("ensemble member %d is empty", i)
+			}
+			model, err := member.Model.Model(cfg)
+			if err != nil {
+				return nil, fmt.Errorf("ensemble member %d: %w", i, err)
+			}
+			ensemble.Models = append(ensemble.Models, model)
+			ensemble.Weights = append(ensemble.Weights, member.Weight)
+		}
+		return ensemble, nil
+	default:
+		return nil, fmt.Errorf("unknown model type %q", a.ModelType)
+	}
+}
+
+// copyMatrix deep-copies a row-major matrix
+func copyMatrix(m [][]float64) [][]float64 {
+	out := make([][]float64, len(m))
+	for i, row := range m {
+		out[i] = append([]float64(nil), row...)
+	}
+	return out
+}
+
+// SaveModelArtifact writes an artifact to disk as JSON
+func SaveModelArtifact(path string, artifact *ModelArtifact) error {
+	data, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal model artifact: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write model artifact: %w", err)
+	}
+
+	return nil
+}
+
+// LoadModelArtifact reads an artifact from disk
+func LoadModelArtifact(path string) (*ModelArtifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model artifact: %w", err)
+	}
+
+	artifact := &ModelArtifact{}
+	if err := json.Unmarshal(data, artifact); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal model artifact: %w", err)
+	}
+
+	return artifact, nil
+}
@@ -0,0 +1,72 @@
+package scaler
+
+import (
+	"math"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Scale event types of training samples, by the scale factor they were
+// labelled with
+const (
+	EventScaleUp   = "scale_up"
+	EventScaleDown = "scale_down"
+	EventHold      = "hold"
+)
+
+// EventType classifies a scale factor with the thresholds of scaleDirection
+func EventType(scaleFactor float64) string {
+	switch scaleDirection(scaleFactor) {
+	case 1:
+		return EventScaleUp
+	case -1:
+		return EventScaleDown
+	}
+	return EventHold
+}
+
+// BalanceTrainingData counters the scarcity of scale events among training
+// samples, most of which need no scaling. Each event type is boosted by the
+// ratio of the most common type's count to its own, capped at
+// cfg.MaxWeight: the "weight" method multiplies sample weights by it, and
+// "oversample" repeats samples that many times, rounded. The most common
+// type is left as is.
+func BalanceTrainingData(data []TrainingData, cfg config.ClassBalanceConfig) []TrainingData {
+	counts := make(map[string]int, 3)
+	for _, sample := range data {
+		counts[EventType(sample.ActualScale)]++
+	}
+	largest := 0
+	for _, count := range counts {
+		if count > largest {
+			largest = count
+		}
+	}
+
+	boost := make(map[string]float64, len(counts))
+	for event, count := range counts {
+		boost[event] = math.Min(float64(largest)/float64(count), cfg.MaxWeight)
+	}
+
+	balanced := make([]TrainingData, 0, len(data))
+	for _, sample := range data {
+		factor := boost[EventType(sample.ActualScale)]
+		if cfg.Method == "oversample" {
+			for copies := int(math.Round(factor)); copies > 0; copies-- {
+				balanced = append(balanced, sample)
+			}
+			continue
+		}
+		sample.Weight = sampleWeight(sample) * factor
+		balanced = append(balanced, sample)
+	}
+	return balanced
+}
+
+// balanceIf balances training data when cfg is enabled
+func balanceIf(data []TrainingData, cfg config.ClassBalanceConfig) []TrainingData {
+	if !cfg.Enabled {
+		return data
+	}
+	return BalanceTrainingData(data, cfg)
+}
@@ -0,0 +1,270 @@
+package scaler
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+)
+
+// Defaults the API server applies to HPA behavior fields left unset
+var (
+	defaultScaleUpRules = autoscalingv2.HPAScalingRules{
+		StabilizationWindowSeconds: int32Ptr(0),
+		SelectPolicy:               selectPolicyPtr(autoscalingv2.MaxChangePolicySelect),
+		Policies: []autoscalingv2.HPAScalingPolicy{
+			{Type: autoscalingv2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+			{Type: autoscalingv2.PodsScalingPolicy, Value: 4, PeriodSeconds: 15},
+		},
+	}
+	defaultScaleDownRules = autoscalingv2.HPAScalingRules{
+		StabilizationWindowSeconds: int32Ptr(300),
+		SelectPolicy:               selectPolicyPtr(autoscalingv2.MaxChangePolicySelect),
+		Policies: []autoscalingv2.HPAScalingPolicy{
+			{Type: autoscalingv2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+		},
+	}
+)
+
+// behaviorRecommendation is a replica recommendation made before behavior
+// limits, kept for the stabilization windows
+type behaviorRecommendation struct {
+	replicas int32
+	at       time.Time
+}
+
+// behaviorEvent is a replica change, kept for the rate policies
+type behaviorEvent struct {
+	delta int32 // positive when scaling up
+	at    time.Time
+}
+
+// behaviorHistory tracks per service what the HPA v2 behavior algorithm
+// needs: past recommendations and past scaling events
+type behaviorHistory struct {
+	mu              sync.Mutex
+	recommendations map[string][]behaviorRecommendation
+	events          map[string][]behaviorEvent
+}
+
+func newBehaviorHistory() *behaviorHistory {
+	return &behaviorHistory{
+		recommendations: make(map[string][]behaviorRecommendation),
+		events:          make(map[string][]behaviorEvent),
+	}
+}
+
+// apply limits a recommendation the way the HPA controller limits its
+// desired replicas: the recommendation is first stabilized (the lowest
+// recommendation within the scale-up window, the highest within the
+// scale-down window), then capped by the rate policies of the direction it
+// moves in, selected with selectPolicy, and finally kept within min/max.
+func (h *behaviorHistory) apply(key string, behavior *autoscalingv2.HorizontalPodAutoscalerBehavior,
+	current, desired, minReplicas, maxReplicas int32, now time.Time) int32 {
+	scaleUp := behaviorRules(behavior.ScaleUp, defaultScaleUpRules)
+	scaleDown := behaviorRules(behavior.ScaleDown, defaultScaleDownRules)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Stabilize
+	upCutoff := now.Add(-time.Duration(*scaleUp.StabilizationWindowSeconds) * time.Second)
+	downCutoff := now.Add(-time.Duration(*scaleDown.StabilizationWindowSeconds) * time.Second)
+	upRecommendation, downRecommendation := desired, desired
+	for _, rec := range h.recommendations[key] {
+		if rec.at.After(upCutoff) && rec.replicas < upRecommendation {
+			upRecommendation = rec.replicas
+		}
+		if rec.at.After(downCutoff) && rec.replicas > downRecommendation {
+			downRecommendation = rec.replicas
+		}
+	}
+	stabilized := current
+	if stabilized < upRecommendation {
+		stabilized = upRecommendation
+	}
+	if stabilized > downRecommendation {
+		stabilized = downRecommendation
+	}
+	h.recommendations[key] = append(pruneRecommendations(h.recommendations[key], now, scaleUp, scaleDown),
+		behaviorRecommendation{replicas: desired, at: now})
+
+	// Limit the rate of change
+	events := h.events[key]
+	replicas := stabilized
+	switch {
+	case replicas > current:
+		limit := scaleUpLimit(current, events, scaleUp, now)
+		if limit < current {
+			limit = current
+		}
+		if limit < maxReplicas {
+			replicas = minInt32(replicas, limit)
+		} else {
+			replicas = minInt32(replicas, maxReplicas)
+		}
+	case replicas < current:
+		limit := scaleDownLimit(current, events, scaleDown, now)
+		if limit > current {
+			limit = current
+		}
+		if limit > minReplicas {
+			replicas = maxInt32(replicas, limit)
+		} else {
+			replicas = maxInt32(replicas, minReplicas)
+		}
+	}
+	return replicas
+}
+
+// record keeps a replica change for the rate policies. Changes are recorded
+// when decided, whether or not the deployment update then succeeds.
+func (h *behaviorHistory) record(key string, behavior *autoscalingv2.HorizontalPodAutoscalerBehavior, from, to int32, now time.Time) {
+	if from == to {
+		return
+	}
+	longest := int32(0)
+	for _, rules := range []autoscalingv2.HPAScalingRules{
+		behaviorRules(behavior.ScaleUp, defaultScaleUpRules),
+		behaviorRules(behavior.ScaleDown, defaultScaleDownRules),
+	} {
+		for _, policy := range rules.Policies {
+			longest = maxInt32(longest, policy.PeriodSeconds)
+		}
+	}
+	cutoff := now.Add(-time.Duration(longest) * time.Second)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var kept []behaviorEvent
+	for _, event := range h.events[key] {
+		if event.at.After(cutoff) {
+			kept = append(kept, event)
+		}
+	}
+	h.events[key] = append(kept, behaviorEvent{delta: to - from, at: now})
+}
+
+// scaleUpLimit is the highest replica count the scale-up policies allow
+func scaleUpLimit(current int32, events []behaviorEvent, rules autoscalingv2.HPAScalingRules, now time.Time) int32 {
+	selectPolicy := *rules.SelectPolicy
+	if selectPolicy == autoscalingv2.DisabledPolicySelect {
+		return current
+	}
+
+	var result int32 = math.MinInt32
+	if selectPolicy == autoscalingv2.MinChangePolicySelect {
+		result = math.MaxInt32
+	}
+	for _, policy := range rules.Policies {
+		periodStart := current - replicasChanged(events, policy.PeriodSeconds, now)
+		var proposed int32
+		if policy.Type == autoscalingv2.PodsScalingPolicy {
+			proposed = periodStart + policy.Value
+		} else {
+			proposed = int32(math.Ceil(float64(periodStart) * (1 + float64(policy.Value)/100)))
+		}
+		if selectPolicy == autoscalingv2.MinChangePolicySelect {
+			result = minInt32(result, proposed)
+		} else {
+			result = maxInt32(result, proposed)
+		}
+	}
+	return result
+}
+
+// scaleDownLimit is the lowest replica count the scale-down policies allow
+func scaleDownLimit(current int32, events []behaviorEvent, rules autoscalingv2.HPAScalingRules, now time.Time) int32 {
+	selectPolicy := *rules.SelectPolicy
+	if selectPolicy == autoscalingv2.DisabledPolicySelect {
+		return current
+	}
+
+	var result int32 = math.MaxInt32
+	if selectPolicy == autoscalingv2.MinChangePolicySelect {
+		result = math.MinInt32
+	}
+	for _, policy := range rules.Policies {
+		periodStart := current - replicasChanged(events, policy.PeriodSeconds, now)
+		var proposed int32
+		if policy.Type == autoscalingv2.PodsScalingPolicy {
+			proposed = periodStart - policy.Value
+		} else {
+			proposed = int32(float64(periodStart) * (1 - float64(policy.Value)/100))
+		}
+		if selectPolicy == autoscalingv2.MinChangePolicySelect {
+			result = maxInt32(result, proposed)
+		} else {
+			result = minInt32(result, proposed)
+		}
+	}
+	return result
+}
+
+// replicasChanged is the net replica change within the last period
+func replicasChanged(events []behaviorEvent, periodSeconds int32, now time.Time) int32 {
+	cutoff := now.Add(-time.Duration(periodSeconds) * time.Second)
+	var changed int32
+	for _, event := range events {
+		if event.at.After(cutoff) {
+			changed += event.delta
+		}
+	}
+	return changed
+}
+
+// pruneRecommendations drops recommendations older than both stabilization windows
+func pruneRecommendations(recommendations []behaviorRecommendation, now time.Time, scaleUp, scaleDown autoscalingv2.HPAScalingRules) []behaviorRecommendation {
+	window := maxInt32(*scaleUp.StabilizationWindowSeconds, *scaleDown.StabilizationWindowSeconds)
+	cutoff := now.Add(-time.Duration(window) * time.Second)
+
+	var kept []behaviorRecommendation
+	for _, rec := range recommendations {
+		if rec.at.After(cutoff) {
+			kept = append(kept, rec)
+		}
+	}
+	return kept
+}
+
+// behaviorRules fills the unset fields of a direction's rules with the HPA defaults
+func behaviorRules(rules *autoscalingv2.HPAScalingRules, defaults autoscalingv2.HPAScalingRules) autoscalingv2.HPAScalingRules {
+	if rules == nil {
+		return defaults
+	}
+	filled := *rules
+	if filled.StabilizationWindowSeconds == nil {
+		filled.StabilizationWindowSeconds = defaults.StabilizationWindowSeconds
+	}
+	if filled.SelectPolicy == nil {
+		filled.SelectPolicy = defaults.SelectPolicy
+	}
+	if len(filled.Policies) == 0 {
+		filled.Policies = defaults.Policies
+	}
+	return filled
+}
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+func selectPolicyPtr(policy autoscalingv2.ScalingPolicySelect) *autoscalingv2.ScalingPolicySelect {
+	return &policy
+}
+
+func minInt32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,95 @@
+package scaler
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// tokenBucket holds the scaling actions a service may still take in one
+// window. It starts full and refills continuously at capacity per window.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// refill adds the tokens accrued since the last update
+func (b *tokenBucket) refill(capacity int, window time.Duration, now time.Time) {
+	elapsed := now.Sub(b.updated)
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(capacity), b.tokens+float64(capacity)*elapsed.Seconds()/window.Seconds())
+		b.updated = now
+	}
+}
+
+// actionBudget limits how many scaling actions each service receives per
+// hour and per day, independently of the cooldowns. It guards against a
+// model that keeps changing its mind from churning a deployment.
+type actionBudget struct {
+	perHour int
+	perDay  int
+
+	mu      sync.Mutex
+	buckets map[string]*[2]tokenBucket // hourly, daily
+	last    map[string][2]int32        // from and to replicas of the last action
+}
+
+func newActionBudget(cfg config.ActionBudgetConfig) *actionBudget {
+	if cfg.PerHour <= 0 && cfg.PerDay <= 0 {
+		return nil
+	}
+	return &actionBudget{
+		perHour: cfg.PerHour,
+		perDay:  cfg.PerDay,
+		buckets: make(map[string]*[2]tokenBucket),
+		last:    make(map[string][2]int32),
+	}
+}
+
+// spend takes one action from the service's budget, and reports false
+// without taking anything when either window is exhausted. Repeating the
+// previous action is free: a change that was not applied, because it is
+// awaiting approval, was vetoed or failed, is decided again until it is.
+func (b *actionBudget) spend(key string, from, to int32, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if last, ok := b.last[key]; ok && last == [2]int32{from, to} {
+		return true
+	}
+
+	buckets, ok := b.buckets[key]
+	if !ok {
+		buckets = &[2]tokenBucket{
+			{tokens: float64(b.perHour), updated: now},
+			{tokens: float64(b.perDay), updated: now},
+		}
+		b.buckets[key] = buckets
+	}
+
+	limits := []struct {
+		capacity int
+		window   time.Duration
+	}{
+		{b.perHour, time.Hour},
+		{b.perDay, 24 * time.Hour},
+	}
+	for i, limit := range limits {
+		if limit.capacity <= 0 {
+			continue
+		}
+		buckets[i].refill(limit.capacity, limit.window, now)
+		if buckets[i].tokens < 1 {
+			return false
+		}
+	}
+	for i, limit := range limits {
+		if limit.capacity > 0 {
+			buckets[i].tokens--
+		}
+	}
+	b.last[key] = [2]int32{from, to}
+	return true
+}
@@ -0,0 +1,158 @@
+package scaler
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Signals watched for regime shifts
+const (
+	// SignalCostPerRequest is CPU spent per request across all replicas
+	SignalCostPerRequest = "cost_per_request"
+
+	// SignalRequestRate is the traffic baseline
+	SignalRequestRate = "request_rate"
+)
+
+// minRequestRate is the request rate below which cost per request is not tracked
+const minRequestRate = 1.0
+
+// ChangePoint describes a detected regime shift of a service
+type ChangePoint struct {
+	Signal     string    `json:"signal"`
+	Direction  string    `json:"direction"` // up, down
+	Baseline   float64   `json:"baseline"`
+	Current    float64   `json:"current"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// pageHinkley is a two-sided Page-Hinkley test on relative deviations from
+// the running mean of a signal
+type pageHinkley struct {
+	count         int
+	mean          float64
+	up, minUp     float64
+	down, maxDown float64
+}
+
+// observe adds a value and returns "up" or "down" when a shift is detected
+func (ph *pageHinkley) observe(value float64, cfg config.ChangePointConfig) string {
+	ph.count++
+	if ph.count == 1 {
+		ph.mean = value
+		return ""
+	}
+	ph.mean += (value - ph.mean) / float64(ph.count)
+
+	deviation := (value - ph.mean) / math.Max(math.Abs(ph.mean), 1e-9)
+	ph.up += deviation - cfg.Tolerance
+	ph.down += deviation + cfg.Tolerance
+	ph.minUp = math.Min(ph.minUp, ph.up)
+	ph.maxDown = math.Max(ph.maxDown, ph.down)
+
+	if ph.count < cfg.MinSamples {
+		return ""
+	}
+	if ph.up-ph.minUp > cfg.Threshold {
+		return "up"
+	}
+	if ph.maxDown-ph.down > cfg.Threshold {
+		return "down"
+	}
+	return ""
+}
+
+// ChangePointDetector runs online change-point detection per service
+type ChangePointDetector struct {
+	config config.ChangePointConfig
+
+	mu       sync.Mutex
+	services map[string]map[string]*pageHinkley
+}
+
+// NewChangePointDetector creates a change-point detector
+func NewChangePointDetector(cfg config.ChangePointConfig) *ChangePointDetector {
+	return &ChangePointDetector{
+		config:   cfg,
+		services: make(map[string]map[string]*pageHinkley),
+	}
+}
+
+// Observe feeds a metrics sample to the service's detectors and returns the
+// change point when a shift is detected. Detection restarts the service's
+// detectors so the new regime becomes the baseline.
+func (d *ChangePointDetector) Observe(key string, metricsData *metrics.MetricsData, now time.Time) *ChangePoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	signals := map[string]float64{SignalRequestRate: metricsData.RequestRate}
+	if metricsData.RequestRate >= minRequestRate && metricsData.CurrentReplicas > 0 {
+		signals[SignalCostPerRequest] = metricsData.CPUUtilization * float64(metricsData.CurrentReplicas) / metricsData.RequestRate
+	}
+
+	detectors := d.services[key]
+	if detectors == nil {
+		detectors = make(map[string]*pageHinkley)
+		d.services[key] = detectors
+	}
+
+	for _, signal := range []string{SignalCostPerRequest, SignalRequestRate} {
+		value, ok := signals[signal]
+		if !ok {
+			continue
+		}
+		ph := detectors[signal]
+		if ph == nil {
+			ph = &pageHinkley{}
+			detectors[signal] = ph
+		}
+
+		if direction := ph.observe(value, d.config); direction != "" {
+			delete(d.services, key)
+			return &ChangePoint{
+				Signal:     signal,
+				Direction:  direction,
+				Baseline:   ph.mean,
+				Current:    value,
+				DetectedAt: now,
+			}
+		}
+	}
+	return nil
+}
+
+// handleChangePoint applies the configured response to a regime shift
+func (s *AIScaler) handleChangePoint(key string, cp *ChangePoint) {
+	fields := logrus.Fields{
+		"service":   key,
+		"signal":    cp.Signal,
+		"direction": cp.Direction,
+		"baseline":  cp.Baseline,
+		"current":   cp.Current,
+		"action":    s.config.AIModel.ChangePoint.Action,
+	}
+
+	switch s.config.AIModel.ChangePoint.Action {
+	case "retrain":
+		logrus.WithFields(fields).Warn("Regime change detected, retraining on samples from the new regime")
+		s.discardTrainingData(cp.DetectedAt)
+	case "reset":
+		logrus.WithFields(fields).Warn("Regime change detected, resetting model")
+		s.discardTrainingData(cp.DetectedAt)
+		s.SetModel(NewModel(s.config.AIModel), "")
+	default:
+		logrus.WithFields(fields).Warn("Regime change detected")
+	}
+}
+
+// discardTrainingData drops training samples from before a regime change so
+// the model relearns from the new regime once enough samples arrive
+func (s *AIScaler) discardTrainingData(before time.Time) {
+	s.states.discardTraining(before)
+}
@@ -0,0 +1,53 @@
+package scaler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Checkpoint writes the active model to path as an artifact, so the next
+// start picks up what online learning has learned since. Retrains in
+// progress are waited for until the context is done. An untrained model is
+// not written, leaving any earlier checkpoint in place.
+func (s *AIScaler) Checkpoint(ctx context.Context, path string) error {
+	done := make(chan struct{})
+	go func() {
+		s.retraining.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("retraining still in progress: %w", ctx.Err())
+	}
+
+	model, _ := s.currentModel()
+	data := s.states.trainingData()
+
+	artifact, err := NewModelArtifact(model)
+	if err != nil {
+		logrus.WithError(err).Info("Skipping model checkpoint")
+		return nil
+	}
+	artifact.TrainingSamples = len(data)
+	if len(data) > 0 {
+		artifact.TrainingWindowStart = data[0].Timestamp
+		artifact.TrainingWindowEnd = data[len(data)-1].Timestamp
+		artifact.FeatureReference = BuildFeatureReference(data)
+	}
+	artifact.DerivedFeatures = s.derived.Names()
+	artifact.DisabledFeatures = s.disabled.Names()
+	artifact.FeatureImportance = s.FeatureImportance()
+
+	if err := SaveModelArtifact(path, artifact); err != nil {
+		return err
+	}
+	logrus.WithFields(logrus.Fields{
+		"path":             path,
+		"model_type":       artifact.ModelType,
+		"training_samples": len(data),
+	}).Info("Wrote model checkpoint")
+	return nil
+}
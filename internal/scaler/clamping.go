@@ -0,0 +1,238 @@
+package scaler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+)
+
+// Limits that clamp recommendations: the service's min and max replicas,
+// and the rate limits of its HPA behavior
+const (
+	ClampMin  = "min"
+	ClampMax  = "max"
+	ClampStep = "step"
+)
+
+var (
+	recommendationsClamped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hydra_route_recommendations_clamped_total",
+		Help: "Recommendations clamped by the min replicas, max replicas or behavior step limits of a service.",
+	}, []string{"service", "namespace", "limit"})
+
+	capacityLimited = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hydra_route_capacity_limited",
+		Help: "Whether the recommendations of a service keep being clamped at its max replicas.",
+	}, []string{"service", "namespace"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(recommendationsClamped, capacityLimited)
+}
+
+// CapacityLimit reports a service whose recommendations were clamped at its
+// max replicas on consecutive evaluations: it needs more than its limit
+// allows
+type CapacityLimit struct {
+	Evaluations int   `json:"evaluations"` // consecutive evaluations clamped at max
+	MaxReplicas int32 `json:"max_replicas"`
+	Unclamped   int32 `json:"unclamped_replicas"` // recommendation before the clamp
+}
+
+// ClampCounts counts the evaluations of a service, those whose
+// recommendation each limit clamped and the capacity alerts raised
+type ClampCounts struct {
+	Evaluations int
+	Min         int
+	Max         int
+	Step        int
+	Alerts      int
+}
+
+// Clamped returns the evaluations clamped by any limit
+func (c ClampCounts) Clamped() int {
+	return c.Min + c.Max + c.Step
+}
+
+// clampAudit counts clamped recommendations per service in hourly buckets
+// kept for retention, and the current streak of evaluations clamped at max
+type clampAudit struct {
+	mu        sync.Mutex
+	retention time.Duration
+	hours     map[string]map[int64]*ClampCounts
+	streaks   map[string]int
+}
+
+func newClampAudit() *clampAudit {
+	return &clampAudit{
+		hours:   make(map[string]map[int64]*ClampCounts),
+		streaks: make(map[string]int),
+	}
+}
+
+// observe counts an evaluation clamped by limits, possibly none. It returns
+// the service's streak of evaluations clamped at max, and the length of a
+// streak this evaluation ended. A streak reaching alertAfter counts as an
+// alert; 0 counts none.
+func (a *clampAudit) observe(key string, limits []string, alertAfter int, now time.Time) (int, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	streak, ended := 0, a.streaks[key]
+	for _, limit := range limits {
+		if limit == ClampMax {
+			streak, ended = a.streaks[key]+1, 0
+		}
+	}
+	if streak > 0 {
+		a.streaks[key] = streak
+	} else {
+		delete(a.streaks, key)
+	}
+
+	if a.retention > 0 {
+		hour := now.Unix() / 3600
+		buckets := a.hours[key]
+		if buckets == nil {
+			buckets = make(map[int64]*ClampCounts)
+			a.hours[key] = buckets
+		}
+		counts := buckets[hour]
+		if counts == nil {
+			counts = &ClampCounts{}
+			buckets[hour] = counts
+			oldest := now.Add(-a.retention).Unix() / 3600
+			for bucket := range buckets {
+				if bucket < oldest {
+					delete(buckets, bucket)
+				}
+			}
+		}
+		counts.Evaluations++
+		for _, limit := range limits {
+			switch limit {
+			case ClampMin:
+				counts.Min++
+			case ClampMax:
+				counts.Max++
+			case ClampStep:
+				counts.Step++
+			}
+		}
+		if alertAfter > 0 && streak == alertAfter {
+			counts.Alerts++
+		}
+	}
+	return streak, ended
+}
+
+// since sums the counts of every service from the hour of since on, and
+// drops those past the retention, such as of services no longer evaluated
+func (a *clampAudit) since(since time.Time) map[string]ClampCounts {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	first := since.Unix() / 3600
+	oldest := time.Now().Add(-a.retention).Unix() / 3600
+	totals := make(map[string]ClampCounts, len(a.hours))
+	for key, buckets := range a.hours {
+		var total ClampCounts
+		for hour, counts := range buckets {
+			if hour < oldest {
+				delete(buckets, hour)
+				continue
+			}
+			if hour < first {
+				continue
+			}
+			total.Evaluations += counts.Evaluations
+			total.Min += counts.Min
+			total.Max += counts.Max
+			total.Step += counts.Step
+			total.Alerts += counts.Alerts
+		}
+		if len(buckets) == 0 {
+			delete(a.hours, key)
+		}
+		if total.Evaluations > 0 {
+			totals[key] = total
+		}
+	}
+	return totals
+}
+
+// KeepClamping starts keeping the clamping counts of every service for the
+// retention, such as the period of summary reports
+func (s *AIScaler) KeepClamping(retention time.Duration) {
+	s.clamping.mu.Lock()
+	defer s.clamping.mu.Unlock()
+	if retention > s.clamping.retention {
+		s.clamping.retention = retention
+	}
+}
+
+// Clamping returns the evaluations of every service since a time, and how
+// many each limit clamped. Counts are kept in hourly buckets, so the hour
+// of since is included in full.
+func (s *AIScaler) Clamping(since time.Time) map[string]ClampCounts {
+	return s.clamping.since(since)
+}
+
+// auditClamping counts which limits clamped a recommendation. Once a
+// service's recommendations are clamped at its max replicas on
+// capacity_alert.evaluations consecutive evaluations, the capacity limit is
+// returned for the evaluation that reached it.
+func (s *AIScaler) auditClamping(key string, metricsData *metrics.MetricsData, limits []string, unclamped, maxReplicas int32) *CapacityLimit {
+	for _, limit := range limits {
+		recommendationsClamped.WithLabelValues(metricsData.ServiceName, metricsData.Namespace, limit).Inc()
+	}
+
+	cfg := s.config.CapacityAlert
+	if !cfg.Enabled {
+		s.clamping.observe(key, limits, 0, time.Now())
+		return nil
+	}
+	streak, ended := s.clamping.observe(key, limits, cfg.Evaluations, time.Now())
+	if ended >= cfg.Evaluations {
+		capacityLimited.DeleteLabelValues(metricsData.ServiceName, metricsData.Namespace)
+		logrus.WithFields(logrus.Fields{
+			"service":   metricsData.ServiceName,
+			"namespace": metricsData.Namespace,
+		}).Info("Recommendations no longer clamped at max replicas")
+	}
+	if streak != cfg.Evaluations {
+		return nil
+	}
+
+	capacityLimited.WithLabelValues(metricsData.ServiceName, metricsData.Namespace).Set(1)
+	logrus.WithFields(logrus.Fields{
+		"service":      metricsData.ServiceName,
+		"namespace":    metricsData.Namespace,
+		"evaluations":  streak,
+		"max_replicas": maxReplicas,
+		"recommended":  unclamped,
+	}).Warn("Recommendations keep being clamped at max replicas, the service needs more capacity")
+	return &CapacityLimit{Evaluations: streak, MaxReplicas: maxReplicas, Unclamped: unclamped}
+}
+
+// clampLimits returns the limits that clamped a recommendation, given the
+// recommendation before and after the min and max replicas and whether
+// the behavior rate-limited it
+func clampLimits(unclamped, constrained int32, limitedByBehavior bool) []string {
+	var limits []string
+	switch {
+	case constrained > unclamped:
+		limits = append(limits, ClampMin)
+	case constrained < unclamped:
+		limits = append(limits, ClampMax)
+	}
+	if limitedByBehavior {
+		limits = append(limits, ClampStep)
+	}
+	return limits
+}
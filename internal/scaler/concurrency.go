@@ -0,0 +1,132 @@
+package scaler
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// TargetModeConcurrency sizes services by their requests in flight instead
+// of the model, like the Knative pod autoscaler
+const TargetModeConcurrency = "concurrency"
+
+// concurrencySample is the in-flight request count of one metrics sample
+type concurrencySample struct {
+	at          time.Time
+	concurrency float64
+}
+
+// concurrencyWindow holds a service's recent in-flight request counts and
+// whether it is in panic mode
+type concurrencyWindow struct {
+	samples     []concurrencySample
+	panicSince  time.Time
+	panicTarget float64
+}
+
+// concurrencyTarget is the replica count a service's in-flight requests call for
+type concurrencyTarget struct {
+	Replicas    float64
+	Concurrency float64 // averaged over the window that set the replicas
+	Panic       bool
+}
+
+// concurrencyTracker sizes services from their in-flight requests. The
+// stable window average over the target per replica sets the replicas. When
+// the panic window asks for panic_threshold times the current replicas, the
+// service enters panic mode for a stable window: the panic window drives
+// scale-ups right away and the replicas don't go down until it ends.
+type concurrencyTracker struct {
+	cfg config.ConcurrencyConfig
+
+	mu      sync.Mutex
+	windows map[string]*concurrencyWindow
+}
+
+func newConcurrencyTracker(cfg config.ConcurrencyConfig) *concurrencyTracker {
+	return &concurrencyTracker{
+		cfg:     cfg,
+		windows: make(map[string]*concurrencyWindow),
+	}
+}
+
+// observe records the in-flight requests of a sample, once per sample
+func (t *concurrencyTracker) observe(key string, at time.Time, concurrency float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	window, ok := t.windows[key]
+	if !ok {
+		window = &concurrencyWindow{}
+		t.windows[key] = window
+	}
+	if n := len(window.samples); n > 0 && !at.After(window.samples[n-1].at) {
+		return
+	}
+	window.samples = append(window.samples, concurrencySample{at: at, concurrency: concurrency})
+
+	cutoff := at.Add(-t.cfg.StableWindow)
+	for len(window.samples) > 1 && !window.samples[0].at.After(cutoff) {
+		window.samples = window.samples[1:]
+	}
+}
+
+// target returns the replicas the in-flight requests of a service call for
+// at the given target per replica. It reports false when no sample in the
+// stable window reported requests in flight, so the model decides instead.
+func (t *concurrencyTracker) target(key string, currentReplicas int32, perReplica float64, now time.Time) (concurrencyTarget, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	window, ok := t.windows[key]
+	if !ok || len(window.samples) == 0 || perReplica <= 0 {
+		return concurrencyTarget{}, false
+	}
+
+	latest := window.samples[len(window.samples)-1].at
+	stable, reported := averageConcurrency(window.samples, latest.Add(-t.cfg.StableWindow))
+	if !reported {
+		return concurrencyTarget{}, false
+	}
+	burst, _ := averageConcurrency(window.samples, latest.Add(-t.cfg.PanicWindow))
+
+	stableReplicas := stable / perReplica
+	panicReplicas := burst / perReplica
+
+	if panicReplicas/math.Max(float64(currentReplicas), 1) >= t.cfg.PanicThreshold {
+		if window.panicSince.IsZero() {
+			window.panicTarget = 0
+		}
+		window.panicSince = now
+	} else if !window.panicSince.IsZero() && now.Sub(window.panicSince) >= t.cfg.StableWindow {
+		window.panicSince = time.Time{}
+	}
+
+	if window.panicSince.IsZero() {
+		return concurrencyTarget{Replicas: stableReplicas, Concurrency: stable}, true
+	}
+
+	// Panic mode only moves up, and never below the replicas already running
+	window.panicTarget = math.Max(window.panicTarget, math.Max(panicReplicas, stableReplicas))
+	replicas := math.Max(window.panicTarget, float64(currentReplicas))
+	return concurrencyTarget{Replicas: replicas, Concurrency: burst, Panic: true}, true
+}
+
+// averageConcurrency averages the samples after the cutoff, always counting
+// the latest, and reports whether any of them had requests in flight
+func averageConcurrency(samples []concurrencySample, cutoff time.Time) (float64, bool) {
+	var sum float64
+	count := 0
+	reported := false
+	for i := len(samples) - 1; i >= 0; i-- {
+		if count > 0 && !samples[i].at.After(cutoff) {
+			break
+		}
+		sum += samples[i].concurrency
+		count++
+		reported = reported || samples[i].concurrency > 0
+	}
+	return sum / float64(count), reported
+}
@@ -0,0 +1,48 @@
+package scaler
+
+import (
+	"math"
+	"sync"
+)
+
+// Batch contention actions
+const (
+	BatchContentionRelax    = "relax"
+	BatchContentionPrescale = "prescale"
+)
+
+// contentionFloors holds the replica floor of each service pre-scaled for
+// batch contention. The floor is set once per episode from the replicas
+// when it began, so it doesn't compound from cycle to cycle.
+type contentionFloors struct {
+	mu     sync.Mutex
+	floors map[string]int32
+}
+
+func newContentionFloors() *contentionFloors {
+	return &contentionFloors{floors: make(map[string]int32)}
+}
+
+// floor returns the replica floor of a service while it is contended, and 0
+// once the contention has ended
+func (f *contentionFloors) floor(key string, contended bool, currentReplicas int32, factor float64) int32 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !contended {
+		delete(f.floors, key)
+		return 0
+	}
+	floor, ok := f.floors[key]
+	if !ok {
+		floor = int32(math.Ceil(float64(currentReplicas) * factor))
+		f.floors[key] = floor
+	}
+	return floor
+}
+
+// relaxContention discounts CPU utilization measured while batch jobs share
+// the service's nodes, which raises the effective CPU target by the relax share
+func relaxContention(cpuUtilization, relax float64) float64 {
+	return cpuUtilization / (1 + relax)
+}
@@ -0,0 +1,43 @@
+package scaler
+
+import (
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// defaultNodeType holds the rates of node types without an entry of their own
+const defaultNodeType = "default"
+
+// CostEstimate is the estimated change in hourly cost and emissions of a
+// decision, from the resources requested by the replicas it adds or removes
+type CostEstimate struct {
+	NodeType      string  `json:"node_type,omitempty"`
+	CostPerHour   float64 `json:"cost_per_hour"`
+	CarbonPerHour float64 `json:"carbon_grams_per_hour"` // gCO2e
+}
+
+// estimateCost prices the replica change of a decision with the rates of the
+// node type the service runs on. It returns nil when estimates are disabled,
+// the pods request nothing or no rates apply.
+func estimateCost(cfg config.CostConfig, sample *metrics.MetricsData, currentReplicas, recommendedReplicas int32) *CostEstimate {
+	if !cfg.Enabled || (sample.CPURequests == 0 && sample.MemoryRequests == 0) {
+		return nil
+	}
+	rates, ok := cfg.NodeTypes[sample.NodeType]
+	if !ok {
+		if rates, ok = cfg.NodeTypes[defaultNodeType]; !ok {
+			return nil
+		}
+	}
+
+	delta := float64(recommendedReplicas - currentReplicas)
+	cores := sample.CPURequests * delta
+	gib := sample.MemoryRequests / 1024 * delta
+	kilowatts := (cores*rates.WattsPerCore + gib*rates.WattsPerGiB) / 1000
+
+	return &CostEstimate{
+		NodeType:      sample.NodeType,
+		CostPerHour:   cores*rates.CPUCoreHour + gib*rates.MemoryGiBHour,
+		CarbonPerHour: kilowatts * rates.CarbonIntensity,
+	}
+}
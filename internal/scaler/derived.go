@@ -0,0 +1,160 @@
+package scaler
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+	"github.com/hydraai/hydra-route/pkg/expr"
+)
+
+// derivedVariables are the MetricsData values available to derived feature expressions
+var derivedVariables = map[string]bool{
+	"cpu_utilization":          true,
+	"memory_utilization":       true,
+	"request_rate":             true,
+	"response_time":            true,
+	"error_rate":               true,
+	"concurrency":              true,
+	"cpu_throttling":           true,
+	"retry_amplification":      true,
+	"oom_kills":                true,
+	"memory_limit_utilization": true,
+	"token_rate":               true,
+	"time_to_first_token":      true,
+	"latency_burn_rate_5m":     true,
+	"latency_burn_rate_1h":     true,
+	"network_bandwidth":        true,
+	"io_bandwidth":             true,
+	"current_replicas":         true,
+	"desired_replicas":         true,
+	"time_of_day":              true,
+	"day_of_week":              true,
+}
+
+// imputedPrefix names the 0/1 variable that flags a backfilled metric,
+// e.g. imputed_cpu_utilization
+const imputedPrefix = "imputed_"
+
+func init() {
+	for _, name := range metrics.ImputableMetrics {
+		derivedVariables[imputedPrefix+name] = true
+		policyVariables[imputedPrefix+name] = true
+	}
+}
+
+// setImputedVariables sets the imputed flag variables for a metrics sample
+func setImputedVariables(vars map[string]float64, metricsData *metrics.MetricsData) {
+	for _, name := range metrics.ImputableMetrics {
+		vars[imputedPrefix+name] = 0
+	}
+	for _, name := range metricsData.Imputed {
+		vars[imputedPrefix+name] = 1
+	}
+}
+
+// DerivedFeatures computes the config-defined features appended to every
+// FeatureVector, in configuration order
+type DerivedFeatures struct {
+	names       []string
+	expressions []*expr.Expr
+}
+
+// CompileDerivedFeatures parses the configured derived feature expressions
+// and checks that they only read known MetricsData values
+func CompileDerivedFeatures(cfgs []config.DerivedFeatureConfig) (*DerivedFeatures, error) {
+	derived := &DerivedFeatures{}
+	seen := make(map[string]bool)
+	for _, cfg := range cfgs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("derived feature name is required")
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("duplicate derived feature %q", cfg.Name)
+		}
+		seen[cfg.Name] = true
+
+		e, err := expr.Parse(cfg.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("derived feature %s: %w", cfg.Name, err)
+		}
+		if err := e.Check(derivedVariables); err != nil {
+			return nil, fmt.Errorf("derived feature %s: %w", cfg.Name, err)
+		}
+
+		derived.names = append(derived.names, cfg.Name)
+		derived.expressions = append(derived.expressions, e)
+	}
+	return derived, nil
+}
+
+// Names returns the derived feature names in the order they appear in FeatureVector.Derived
+func (d *DerivedFeatures) Names() []string {
+	if d == nil {
+		return nil
+	}
+	return append([]string(nil), d.names...)
+}
+
+// Apply evaluates the derived features for a metrics sample and stores them
+// on the feature vector. An expression that fails to evaluate, for example
+// by dividing by zero, contributes 0.
+func (d *DerivedFeatures) Apply(metricsData *metrics.MetricsData, features *FeatureVector) {
+	if d == nil || len(d.expressions) == 0 {
+		return
+	}
+
+	ts := sampleTime(metricsData)
+	vars := map[string]float64{
+		"cpu_utilization":          metricsData.CPUUtilization,
+		"memory_utilization":       metricsData.MemoryUtilization,
+		"request_rate":             metricsData.RequestRate,
+		"response_time":            metricsData.ResponseTime,
+		"error_rate":               metricsData.ErrorRate,
+		"concurrency":              metricsData.Concurrency,
+		"cpu_throttling":           metricsData.CPUThrottling,
+		"retry_amplification":      metricsData.RetryAmplification,
+		"oom_kills":                float64(metricsData.OOMKills),
+		"memory_limit_utilization": metricsData.MemoryLimitUtilization,
+		"token_rate":               metricsData.TokenRate,
+		"time_to_first_token":      metricsData.TimeToFirstToken,
+		"latency_burn_rate_5m":     metricsData.LatencyBurnRate5m,
+		"latency_burn_rate_1h":     metricsData.LatencyBurnRate1h,
+		"network_bandwidth":        metricsData.NetworkBandwidth,
+		"io_bandwidth":             metricsData.IOBandwidth,
+		"current_replicas":         float64(metricsData.CurrentReplicas),
+		"desired_replicas":         float64(metricsData.DesiredReplicas),
+		"time_of_day":              float64(ts.Hour()),
+		"day_of_week":              float64(ts.Weekday()),
+	}
+	setImputedVariables(vars, metricsData)
+
+	features.Derived = make([]float64, len(d.expressions))
+	for i, e := range d.expressions {
+		value, err := e.Eval(vars)
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"feature":   d.names[i],
+				"service":   metricsData.ServiceName,
+				"namespace": metricsData.Namespace,
+			}).Debug("Failed to evaluate derived feature")
+			continue
+		}
+		features.Derived[i] = value
+	}
+}
+
+// equalNames reports whether two feature name lists are identical
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
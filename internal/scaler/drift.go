@@ -0,0 +1,319 @@
+package scaler
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+const (
+	// referenceBins is the number of quantile bins kept per feature
+	referenceBins = 10
+
+	// psiEpsilon floors bin proportions so empty bins don't produce infinite PSI
+	psiEpsilon = 1e-4
+)
+
+// featureNames lists the FeatureVector fields in featureValues order
+var featureNames = []string{
+	"cpu_utilization",
+	"memory_utilization",
+	"request_rate",
+	"network_bandwidth",
+	"io_bandwidth",
+	"response_time",
+	"error_rate",
+	"hour_sin",
+	"hour_cos",
+	"day_sin",
+	"day_cos",
+	"holiday",
+	"trend_cpu",
+	"trend_memory",
+	"trend_requests",
+}
+
+// featureValues returns the raw feature values in featureNames order
+func featureValues(f FeatureVector) []float64 {
+	return []float64{
+		f.CPUUtilization,
+		f.MemoryUtilization,
+		f.RequestRate,
+		f.NetworkBandwidth,
+		f.IOBandwidth,
+		f.ResponseTime,
+		f.ErrorRate,
+		f.HourSin,
+		f.HourCos,
+		f.DaySin,
+		f.DayCos,
+		f.Holiday,
+		f.TrendCPU,
+		f.TrendMemory,
+		f.TrendRequests,
+	}
+}
+
+// FeatureDistribution describes the training distribution of one feature as
+// quantile bin edges and the share of training samples in each bin
+type FeatureDistribution struct {
+	Edges       []float64 `json:"edges"`
+	Proportions []float64 `json:"proportions"`
+}
+
+// FeatureReference maps feature names to their training distributions
+type FeatureReference map[string]*FeatureDistribution
+
+// BuildFeatureReference summarizes the feature distributions of training samples
+func BuildFeatureReference(data []TrainingData) FeatureReference {
+	if len(data) == 0 {
+		return nil
+	}
+
+	columns := make([][]float64, len(featureNames))
+	for _, sample := range data {
+		for i, v := range featureValues(sample.Features) {
+			columns[i] = append(columns[i], v)
+		}
+	}
+
+	reference := make(FeatureReference, len(featureNames))
+	for i, name := range featureNames {
+		values := columns[i]
+		sort.Float64s(values)
+
+		edges := make([]float64, referenceBins-1)
+		for b := 1; b < referenceBins; b++ {
+			edges[b-1] = values[(b*len(values))/referenceBins]
+		}
+
+		dist := &FeatureDistribution{Edges: edges}
+		dist.Proportions = binProportions(edges, values)
+		reference[name] = dist
+	}
+
+	return reference
+}
+
+// bin returns the index of the bin holding v: the number of edges below v
+func bin(edges []float64, v float64) int {
+	return sort.Search(len(edges), func(i int) bool { return edges[i] >= v })
+}
+
+func binProportions(edges []float64, values []float64) []float64 {
+	counts := make([]float64, len(edges)+1)
+	for _, v := range values {
+		counts[bin(edges, v)]++
+	}
+	for i := range counts {
+		counts[i] /= float64(len(values))
+	}
+	return counts
+}
+
+// FeatureDrift holds the drift statistics for one feature
+type FeatureDrift struct {
+	PSI float64 `json:"psi"`
+	KS  float64 `json:"ks"`
+}
+
+// DriftStatus is the latest drift evaluation result
+type DriftStatus struct {
+	Drifted      bool                    `json:"drifted"`
+	Method       string                  `json:"method"`
+	Threshold    float64                 `json:"threshold"`
+	Score        float64                 `json:"score"`
+	Feature      string                  `json:"feature,omitempty"`
+	Samples      int                     `json:"samples"`
+	CheckedAt    time.Time               `json:"checked_at"`
+	Features     map[string]FeatureDrift `json:"features,omitempty"`
+	ActionTaken  string                  `json:"action_taken,omitempty"`
+	HasReference bool                    `json:"has_reference"`
+}
+
+// DriftDetector compares a sliding window of recent feature vectors with the
+// training distribution of the active model
+type DriftDetector struct {
+	config config.DriftDetectionConfig
+
+	mu        sync.Mutex
+	reference FeatureReference
+	window    []FeatureVector
+	next      int
+	filled    bool
+	lastCheck time.Time
+	status    DriftStatus
+}
+
+// NewDriftDetector creates a drift detector
+func NewDriftDetector(cfg config.DriftDetectionConfig) *DriftDetector {
+	return &DriftDetector{
+		config: cfg,
+		window: make([]FeatureVector, cfg.WindowSize),
+		status: DriftStatus{Method: cfg.Method, Threshold: cfg.Threshold},
+	}
+}
+
+// SetReference replaces the training distribution and clears the window
+func (d *DriftDetector) SetReference(reference FeatureReference) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.reference = reference
+	d.next = 0
+	d.filled = false
+	d.status = DriftStatus{Method: d.config.Method, Threshold: d.config.Threshold, HasReference: reference != nil}
+}
+
+// Observe adds a feature vector to the window and, when the check interval
+// has elapsed, evaluates drift. It returns true when drift was newly detected.
+func (d *DriftDetector) Observe(features FeatureVector, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.window) == 0 {
+		return false
+	}
+
+	d.window[d.next] = features
+	d.next = (d.next + 1) % len(d.window)
+	if d.next == 0 {
+		d.filled = true
+	}
+
+	if d.reference == nil || now.Sub(d.lastCheck) < d.config.CheckInterval {
+		return false
+	}
+
+	samples := d.samples()
+	if len(samples) < d.config.MinSamples {
+		return false
+	}
+
+	d.lastCheck = now
+	wasDrifted := d.status.Drifted
+	d.status = d.evaluate(samples, now)
+	return d.status.Drifted && !wasDrifted
+}
+
+// Status returns the latest drift evaluation
+func (d *DriftDetector) Status() DriftStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.status
+}
+
+// setAction records the action taken in response to drift
+func (d *DriftDetector) setAction(action string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.status.ActionTaken = action
+}
+
+func (d *DriftDetector) samples() []FeatureVector {
+	if d.filled {
+		return d.window
+	}
+	return d.window[:d.next]
+}
+
+func (d *DriftDetector) evaluate(samples []FeatureVector, now time.Time) DriftStatus {
+	status := DriftStatus{
+		Method:       d.config.Method,
+		Threshold:    d.config.Threshold,
+		Samples:      len(samples),
+		CheckedAt:    now,
+		Features:     make(map[string]FeatureDrift, len(featureNames)),
+		HasReference: true,
+	}
+
+	columns := make([][]float64, len(featureNames))
+	for _, f := range samples {
+		for i, v := range featureValues(f) {
+			columns[i] = append(columns[i], v)
+		}
+	}
+
+	for i, name := range featureNames {
+		ref, exists := d.reference[name]
+		if !exists {
+			continue
+		}
+
+		actual := binProportions(ref.Edges, columns[i])
+		drift := FeatureDrift{
+			PSI: populationStabilityIndex(ref.Proportions, actual),
+			KS:  kolmogorovSmirnov(ref.Proportions, actual),
+		}
+		status.Features[name] = drift
+
+		score := drift.PSI
+		if d.config.Method == "ks" {
+			score = drift.KS
+		}
+		if score > status.Score {
+			status.Score = score
+			status.Feature = name
+		}
+	}
+
+	status.Drifted = status.Score > d.config.Threshold
+	return status
+}
+
+// populationStabilityIndex computes PSI between expected and actual bin proportions
+func populationStabilityIndex(expected, actual []float64) float64 {
+	var psi float64
+	for i := range expected {
+		e := math.Max(expected[i], psiEpsilon)
+		a := math.Max(actual[i], psiEpsilon)
+		psi += (a - e) * math.Log(a/e)
+	}
+	return psi
+}
+
+// kolmogorovSmirnov computes the KS statistic over the bin edges: the largest
+// gap between the cumulative expected and actual distributions
+func kolmogorovSmirnov(expected, actual []float64) float64 {
+	var cumExpected, cumActual, ks float64
+	for i := range expected {
+		cumExpected += expected[i]
+		cumActual += actual[i]
+		ks = math.Max(ks, math.Abs(cumExpected-cumActual))
+	}
+	return ks
+}
+
+// handleDrift applies the configured response to newly detected drift
+func (s *AIScaler) handleDrift() {
+	status := s.drift.Status()
+	fields := logrus.Fields{
+		"method":    status.Method,
+		"score":     status.Score,
+		"threshold": status.Threshold,
+		"feature":   status.Feature,
+		"action":    s.config.AIModel.DriftDetection.Action,
+	}
+
+	switch s.config.AIModel.DriftDetection.Action {
+	case "retrain":
+		logrus.WithFields(fields).Warn("Model drift detected, triggering retrain")
+		s.drift.setAction("retrain")
+		s.startRetrain()
+	case "fallback":
+		logrus.WithFields(fields).Warn("Model drift detected, falling back to heuristic model")
+		s.drift.setAction("fallback")
+		fallbackCfg := s.config.AIModel
+		fallbackCfg.ModelType = "linear"
+		s.SetModel(NewModel(fallbackCfg), "")
+	default:
+		logrus.WithFields(fields).Warn("Model drift detected")
+	}
+}
@@ -0,0 +1,169 @@
+package scaler
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+var sloEscalationFloor = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "hydra_route_slo_escalation_floor",
+	Help: "Replica floor raised for a service that keeps breaching its SLO.",
+}, []string{"service", "namespace"})
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(sloEscalationFloor)
+}
+
+// SLOEscalation is a step of the replica floor of a service breaching its SLO
+type SLOEscalation struct {
+	Breaches     int       `json:"breaches"`      // consecutive breaching evaluations
+	FromReplicas int32     `json:"from_replicas"` // floor before the step, 0 when there was none
+	Replicas     int32     `json:"replicas"`
+	Capped       bool      `json:"capped"` // the floor reached its cap
+	EscalatedAt  time.Time `json:"escalated_at"`
+}
+
+// sloFloor is the escalation state of one service
+type sloFloor struct {
+	replicas   int32
+	breaches   int // since the last step
+	streak     int // since the SLO was last met
+	recoveries int
+	seen       time.Time
+}
+
+// sloFloors raise a replica floor for services breaching their SLO on
+// consecutive evaluations. Every breach_evaluations breaches multiply the
+// replicas by the step factor, up to the cap; the floor holds until the
+// SLO is met on recovery_evaluations consecutive evaluations.
+type sloFloors struct {
+	mu     sync.Mutex
+	floors map[string]*sloFloor
+}
+
+func newSLOFloors() *sloFloors {
+	return &sloFloors{floors: make(map[string]*sloFloor)}
+}
+
+// observe counts an evaluation of a service's sample taken at seen, and
+// returns the service's floor, with the step taken if the floor rose. A
+// sample evaluated before counts once.
+func (f *sloFloors) observe(key string, seen time.Time, breached bool, currentReplicas, maxReplicas int32, cfg config.SLOEscalationConfig) (int32, *SLOEscalation) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	floor, ok := f.floors[key]
+	if !ok {
+		if !breached {
+			return 0, nil
+		}
+		floor = &sloFloor{}
+		f.floors[key] = floor
+	}
+	if !seen.After(floor.seen) {
+		return floor.replicas, nil
+	}
+	floor.seen = seen
+
+	if !breached {
+		floor.breaches, floor.streak = 0, 0
+		floor.recoveries++
+		if floor.replicas == 0 || floor.recoveries >= cfg.RecoveryEvaluations {
+			delete(f.floors, key)
+			return 0, nil
+		}
+		return floor.replicas, nil
+	}
+
+	floor.recoveries = 0
+	floor.breaches++
+	floor.streak++
+	if floor.breaches < cfg.BreachEvaluations {
+		return floor.replicas, nil
+	}
+	floor.breaches = 0
+
+	base := currentReplicas
+	if floor.replicas > base {
+		base = floor.replicas
+	}
+	next := int32(math.Ceil(float64(base) * cfg.StepFactor))
+	if next <= base {
+		next = base + 1
+	}
+	limit := cfg.MaxReplicas
+	if limit == 0 || limit > maxReplicas {
+		limit = maxReplicas
+	}
+	if next > limit {
+		next = limit
+	}
+	if next <= floor.replicas {
+		return floor.replicas, nil
+	}
+
+	step := &SLOEscalation{
+		Breaches:     floor.streak,
+		FromReplicas: floor.replicas,
+		Replicas:     next,
+		Capped:       next == limit,
+		EscalatedAt:  time.Now(),
+	}
+	floor.replicas = next
+	return next, step
+}
+
+// current returns the floor of a service, 0 when none is raised
+func (f *sloFloors) current(key string) int32 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if floor, ok := f.floors[key]; ok {
+		return floor.replicas
+	}
+	return 0
+}
+
+// observeSLO counts an evaluation of a service against the SLO of
+// slo_escalation, and returns its replica floor with the step taken, if
+// any. Samples taken during node maintenance or a maintenance window leave
+// the count as it is.
+func (s *AIScaler) observeSLO(key string, metricsData *metrics.MetricsData) (int32, *SLOEscalation) {
+	cfg := s.config.SLOEscalation
+	if s.sloFloors == nil {
+		return 0, nil
+	}
+
+	if metricsData.Maintenance || s.inMaintenanceWindow(key, metricsData.Timestamp) {
+		return s.sloFloors.current(key), nil
+	}
+
+	breached := metricsData.ResponseTime > cfg.SLOResponseTime || metricsData.ErrorRate > cfg.SLOErrorRate
+	floor, step := s.sloFloors.observe(key, metricsData.Timestamp, breached, metricsData.CurrentReplicas, s.settingsFor(key).MaxReplicas, cfg)
+
+	if floor == 0 {
+		sloEscalationFloor.DeleteLabelValues(metricsData.ServiceName, metricsData.Namespace)
+	} else {
+		sloEscalationFloor.WithLabelValues(metricsData.ServiceName, metricsData.Namespace).Set(float64(floor))
+	}
+	if step != nil {
+		logrus.WithFields(logrus.Fields{
+			"service":       metricsData.ServiceName,
+			"namespace":     metricsData.Namespace,
+			"breaches":      step.Breaches,
+			"response_time": metricsData.ResponseTime,
+			"error_rate":    metricsData.ErrorRate,
+			"floor":         step.Replicas,
+			"capped":        step.Capped,
+		}).Warn("SLO breached despite scaling, raised the replica floor")
+	}
+	return floor, step
+}
@@ -0,0 +1,250 @@
+package scaler
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// EvaluationResult summarizes model accuracy on a set of samples
+type EvaluationResult struct {
+	Samples           int     `json:"samples"`
+	MSE               float64 `json:"mse"`
+	MAE               float64 `json:"mae"`
+	DirectionAccuracy float64 `json:"direction_accuracy"` // fraction of samples with the correct up/down/hold call
+	Coverage          float64 `json:"coverage"`           // fraction of samples where the prediction was at least the actual scale
+
+	// The same scores restricted to the samples of each scale event type
+	// (scale_up, scale_down, hold), so rare scale events aren't hidden by
+	// the many samples that need no scaling
+	ByEvent map[string]EvaluationResult `json:"by_event,omitempty"`
+}
+
+// evaluationSums accumulates the scores of an EvaluationResult
+type evaluationSums struct {
+	samples                             int
+	squared, absolute, correct, covered float64
+}
+
+// add scores one prediction
+func (e *evaluationSums) add(prediction, actual float64) {
+	diff := prediction - actual
+	e.squared += diff * diff
+	e.absolute += math.Abs(diff)
+	if scaleDirection(prediction) == scaleDirection(actual) {
+		e.correct++
+	}
+	if prediction >= actual {
+		e.covered++
+	}
+	e.samples++
+}
+
+// merge adds the scores of a result over several samples
+func (e *evaluationSums) merge(result EvaluationResult) {
+	n := float64(result.Samples)
+	e.squared += result.MSE * n
+	e.absolute += result.MAE * n
+	e.correct += result.DirectionAccuracy * n
+	e.covered += result.Coverage * n
+	e.samples += result.Samples
+}
+
+// result averages the accumulated scores
+func (e *evaluationSums) result() EvaluationResult {
+	n := float64(e.samples)
+	return EvaluationResult{
+		Samples:           e.samples,
+		MSE:               e.squared / n,
+		MAE:               e.absolute / n,
+		DirectionAccuracy: e.correct / n,
+		Coverage:          e.covered / n,
+	}
+}
+
+// stratifiedSums accumulates scores overall and per scale event type
+type stratifiedSums struct {
+	total   evaluationSums
+	byEvent map[string]*evaluationSums
+}
+
+func (s *stratifiedSums) event(event string) *evaluationSums {
+	if s.byEvent == nil {
+		s.byEvent = make(map[string]*evaluationSums, 3)
+	}
+	sums, exists := s.byEvent[event]
+	if !exists {
+		sums = &evaluationSums{}
+		s.byEvent[event] = sums
+	}
+	return sums
+}
+
+func (s *stratifiedSums) result() EvaluationResult {
+	result := s.total.result()
+	result.ByEvent = make(map[string]EvaluationResult, len(s.byEvent))
+	for event, sums := range s.byEvent {
+		result.ByEvent[event] = sums.result()
+	}
+	return result
+}
+
+// Evaluate scores a trained model against labelled samples, overall and per
+// scale event type
+func Evaluate(model AIModel, data []TrainingData) (EvaluationResult, error) {
+	var sums stratifiedSums
+	for _, sample := range data {
+		prediction, _, err := model.Predict(sample.Features)
+		if err != nil {
+			return EvaluationResult{}, fmt.Errorf("prediction failed: %w", err)
+		}
+		sums.total.add(prediction, sample.ActualScale)
+		sums.event(EventType(sample.ActualScale)).add(prediction, sample.ActualScale)
+	}
+
+	if sums.total.samples == 0 {
+		return EvaluationResult{}, fmt.Errorf("no samples to evaluate")
+	}
+	return sums.result(), nil
+}
+
+// CrossValidate trains a fresh model on k-1 folds and evaluates it on the
+// remaining fold, returning the sample-weighted average across folds. The
+// training folds are balanced by scale event type when balance is enabled.
+func CrossValidate(newModel func() AIModel, data []TrainingData, folds int, balance config.ClassBalanceConfig) (EvaluationResult, error) {
+	if folds < 2 {
+		return EvaluationResult{}, fmt.Errorf("cross-validation requires at least 2 folds")
+	}
+	if len(data) < folds {
+		return EvaluationResult{}, fmt.Errorf("not enough samples (%d) for %d folds", len(data), folds)
+	}
+
+	var sums stratifiedSums
+	foldSize := len(data) / folds
+
+	for k := 0; k < folds; k++ {
+		start := k * foldSize
+		end := start + foldSize
+		if k == folds-1 {
+			end = len(data)
+		}
+
+		train := make([]TrainingData, 0, len(data)-(end-start))
+		train = append(train, data[:start]...)
+		train = append(train, data[end:]...)
+
+		model := newModel()
+		if err := model.Train(balanceIf(train, balance)); err != nil {
+			return EvaluationResult{}, fmt.Errorf("fold %d: training failed: %w", k, err)
+		}
+
+		result, err := Evaluate(model, data[start:end])
+		if err != nil {
+			return EvaluationResult{}, fmt.Errorf("fold %d: %w", k, err)
+		}
+
+		sums.total.merge(result)
+		for event, eventResult := range result.ByEvent {
+			sums.event(event).merge(eventResult)
+		}
+	}
+
+	return sums.result(), nil
+}
+
+// SplitHoldout orders samples by time and reserves the most recent fraction
+// for evaluation, so held-out data is never older than training data
+func SplitHoldout(data []TrainingData, fraction float64) ([]TrainingData, []TrainingData) {
+	sorted := make([]TrainingData, len(data))
+	copy(sorted, data)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	holdout := int(float64(len(sorted)) * fraction)
+	split := len(sorted) - holdout
+	return sorted[:split], sorted[split:]
+}
+
+// scaleDirection classifies a scale factor using the same thresholds as
+// calculateRecommendedReplicas
+func scaleDirection(scaleFactor float64) int {
+	if scaleFactor > 1.1 {
+		return 1
+	} else if scaleFactor < 0.9 {
+		return -1
+	}
+	return 0
+}
+
+// TrainingDataFromMetrics derives labelled samples from an archived metrics
+// history. Each sample is labelled with the replica change that followed it,
+// i.e. the next observed replica count divided by the current one. Derived
+// features are computed when derived is non-nil, samples taken on holidays
+// are flagged, and the disabled features are zeroed. With a sequenceLength above
+// 1, each sample also carries the samples of its service that preceded it.
+// Samples taken during node maintenance, and pairs of samples touching a
+// maintenance window of their service, are skipped.
+func TrainingDataFromMetrics(history []*metrics.MetricsData, derived *DerivedFeatures, holidays Holidays, windows []config.MaintenanceWindowConfig, disabled FeatureSet, sequenceLength int, incidentErrorRate float64) []TrainingData {
+	byService := make(map[string][]*metrics.MetricsData)
+	for _, m := range history {
+		if m == nil {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s", m.Namespace, m.ServiceName)
+		byService[key] = append(byService[key], m)
+	}
+
+	var data []TrainingData
+	for key, samples := range byService {
+		sort.SliceStable(samples, func(i, j int) bool {
+			return samples[i].Timestamp.Before(samples[j].Timestamp)
+		})
+
+		serviceWindows := MaintenanceWindowsFor(windows, key)
+		vectors := make([]FeatureVector, len(samples))
+		for i, sample := range samples {
+			vectors[i] = FeaturesFromMetrics(sample)
+			holidays.Apply(sample, &vectors[i])
+			derived.Apply(sample, &vectors[i])
+			disabled.Apply(&vectors[i])
+		}
+
+		for i := 0; i+1 < len(samples); i++ {
+			current, next := samples[i], samples[i+1]
+			if current.CurrentReplicas <= 0 || next.CurrentReplicas <= 0 {
+				continue
+			}
+			if current.Maintenance || next.Maintenance || current.CrashLoop || next.CrashLoop {
+				continue
+			}
+			if serviceWindows.Contains(current.Timestamp) || serviceWindows.Contains(next.Timestamp) {
+				continue
+			}
+
+			features := vectors[i]
+			if sequenceLength > 1 {
+				start := i - (sequenceLength - 1)
+				if start < 0 {
+					start = 0
+				}
+				features.Sequence = vectors[start:i]
+			}
+
+			data = append(data, TrainingData{
+				Features:    features,
+				ActualScale: float64(next.CurrentReplicas) / float64(current.CurrentReplicas),
+				Timestamp:   current.Timestamp,
+				Service:     key,
+				Revision:    current.Revision,
+				Image:       current.Image,
+				Flags:       QualityFlags(current, next, incidentErrorRate),
+			})
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,203 @@
+package scaler
+
+import (
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Experiment cohorts
+const (
+	CohortAI        = "ai"
+	CohortThreshold = "threshold"
+)
+
+// ExperimentWindow returns when the configured experiment starts and ends.
+// ok is false when no experiment is configured.
+func ExperimentWindow(cfg config.ExperimentConfig) (start, end time.Time, ok bool) {
+	if cfg.Name == "" {
+		return time.Time{}, time.Time{}, false
+	}
+	start, err := time.Parse(time.RFC3339, cfg.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, start.Add(cfg.Duration), true
+}
+
+// ExperimentCohort returns the cohort a service is assigned to. An explicit
+// threshold cohort takes precedence over percentage-based selection, which
+// hashes the key salted with the experiment name, so membership is stable
+// across restarts and differs between experiments.
+func ExperimentCohort(key string, cfg config.ExperimentConfig) string {
+	if len(cfg.ThresholdServices) > 0 {
+		for _, service := range cfg.ThresholdServices {
+			if service == key {
+				return CohortThreshold
+			}
+		}
+		return CohortAI
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(cfg.Name + "/" + key))
+	if float64(h.Sum32()%10000)/100.0 < cfg.ThresholdPercentage {
+		return CohortThreshold
+	}
+	return CohortAI
+}
+
+// activeCohort returns the cohort of a service while the experiment runs,
+// or "" outside of it
+func activeCohort(key string, cfg config.ExperimentConfig, now time.Time) string {
+	start, end, ok := ExperimentWindow(cfg)
+	if !ok || now.Before(start) || !now.Before(end) {
+		return ""
+	}
+	return ExperimentCohort(key, cfg)
+}
+
+// thresholdFactor is the scale factor of the threshold cohort. A service
+// above a scale-up threshold, or below all scale-down thresholds, is scaled
+// to bring its CPU and memory utilization to the scale-up thresholds, as a
+// utilization-target autoscaler would; between the two it is left alone.
+func thresholdFactor(metricsData *metrics.MetricsData, settings ServiceSettings, down config.ThresholdConfig) float64 {
+	var ratio float64
+	above, below := false, true
+	for _, signal := range []struct{ value, up, down float64 }{
+		{metricsData.CPUUtilization, settings.TargetCPUUtilization, down.CPUUtilization},
+		{metricsData.MemoryUtilization, settings.TargetMemoryUtilization, down.MemoryUtilization},
+	} {
+		if signal.up <= 0 {
+			continue
+		}
+		ratio = math.Max(ratio, signal.value/signal.up)
+		above = above || signal.value > signal.up
+		below = below && signal.down > 0 && signal.value < signal.down
+	}
+	if ratio == 0 || (!above && !below) {
+		return 1.0
+	}
+	return math.Max(0.5, math.Min(2.0, ratio))
+}
+
+// ExperimentReport compares the cohorts of an experiment over the time it
+// has run so far
+type ExperimentReport struct {
+	Name        string    `json:"name"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Completed   bool      `json:"completed"`
+
+	Cohorts []CohortResult `json:"cohorts"`
+
+	// AI cohort minus threshold cohort: SLO attainment in percentage
+	// points, mean replicas and cost per service-hour
+	SLOAttainmentDelta float64 `json:"slo_attainment_delta"`
+	ReplicasDelta      float64 `json:"replicas_delta"`
+	CostPerHourDelta   float64 `json:"cost_per_hour_delta,omitempty"`
+}
+
+// CohortResult is the outcome of one cohort. Samples taken during node
+// maintenance or crash loops are left out.
+type CohortResult struct {
+	Cohort   string `json:"cohort"`
+	Services int    `json:"services"`
+	Samples  int    `json:"samples"`
+
+	// Percentage of samples within the SLO response time and error rate
+	SLOAttainment float64 `json:"slo_attainment"`
+
+	// Mean replicas of a service, and the mean hourly cost of its replicas'
+	// requests when cost estimates are enabled
+	MeanReplicas float64 `json:"mean_replicas"`
+	CostPerHour  float64 `json:"cost_per_hour,omitempty"`
+}
+
+// CompareCohorts builds the report of the configured experiment from the
+// history of every service over it, by service key. It returns nil when no
+// experiment is configured.
+func (s *AIScaler) CompareCohorts(histories map[string][]*metrics.MetricsData, now time.Time) *ExperimentReport {
+	cfg := s.config.Experiment
+	start, end, ok := ExperimentWindow(cfg)
+	if !ok {
+		return nil
+	}
+
+	type totals struct {
+		services, samples, attained int
+		replicas, cost              float64
+	}
+	byCohort := map[string]*totals{CohortAI: {}, CohortThreshold: {}}
+
+	for key, history := range histories {
+		t := byCohort[ExperimentCohort(key, cfg)]
+		counted := false
+		for _, sample := range history {
+			if sample.Timestamp.Before(start) || !sample.Timestamp.Before(end) || sample.Maintenance || sample.CrashLoop {
+				continue
+			}
+			counted = true
+			t.samples++
+			if sample.ResponseTime <= cfg.SLOResponseTime && sample.ErrorRate <= cfg.SLOErrorRate {
+				t.attained++
+			}
+			t.replicas += float64(sample.CurrentReplicas)
+			if cost := estimateCost(s.config.Cost, sample, 0, sample.CurrentReplicas); cost != nil {
+				t.cost += cost.CostPerHour
+			}
+		}
+		if counted {
+			t.services++
+		}
+	}
+
+	report := &ExperimentReport{
+		Name:        cfg.Name,
+		GeneratedAt: now,
+		Start:       start,
+		End:         end,
+		Completed:   !now.Before(end),
+	}
+	results := make(map[string]CohortResult, len(byCohort))
+	for _, cohort := range []string{CohortAI, CohortThreshold} {
+		t := byCohort[cohort]
+		result := CohortResult{Cohort: cohort, Services: t.services, Samples: t.samples}
+		if t.samples > 0 {
+			result.SLOAttainment = float64(t.attained) / float64(t.samples) * 100
+			result.MeanReplicas = t.replicas / float64(t.samples)
+			result.CostPerHour = t.cost / float64(t.samples)
+		}
+		results[cohort] = result
+		report.Cohorts = append(report.Cohorts, result)
+	}
+
+	ai, threshold := results[CohortAI], results[CohortThreshold]
+	if ai.Samples > 0 && threshold.Samples > 0 {
+		report.SLOAttainmentDelta = ai.SLOAttainment - threshold.SLOAttainment
+		report.ReplicasDelta = ai.MeanReplicas - threshold.MeanReplicas
+		report.CostPerHourDelta = ai.CostPerHour - threshold.CostPerHour
+	}
+	return report
+}
+
+// SetExperimentReport stores the latest experiment report
+func (s *AIScaler) SetExperimentReport(report *ExperimentReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.experiment = report
+}
+
+// ExperimentReport returns the latest experiment report, or nil before the
+// first comparison
+func (s *AIScaler) ExperimentReport() *ExperimentReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.experiment
+}
@@ -0,0 +1,122 @@
+package scaler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FeatureNames are the base features of a FeatureVector in model input
+// order, named as in its JSON form. Derived features and aggregation
+// windows are configured separately.
+var FeatureNames = []string{
+	"cpu_utilization",
+	"memory_utilization",
+	"request_rate",
+	"network_bandwidth",
+	"io_bandwidth",
+	"response_time",
+	"error_rate",
+	"hour_sin",
+	"hour_cos",
+	"day_sin",
+	"day_cos",
+	"holiday",
+	"trend_cpu",
+	"trend_memory",
+	"trend_requests",
+}
+
+// featureAliases maps the raw time_of_day and day_of_week features, which
+// the cyclical encodings replaced, to those encodings, so configurations
+// disabling them keep working
+var featureAliases = map[string][]string{
+	"time_of_day": {"hour_sin", "hour_cos"},
+	"day_of_week": {"day_sin", "day_cos"},
+}
+
+// FeatureSet is a set of base features, one bit per entry of FeatureNames.
+// It is comparable, so it can be part of ServiceSettings.
+type FeatureSet uint32
+
+// ParseFeatureSet returns the set of the named base features. Unknown
+// names are reported in the error; the known ones are still returned.
+func ParseFeatureSet(names []string) (FeatureSet, error) {
+	var set FeatureSet
+	var unknown []string
+	for _, name := range names {
+		if aliased, ok := featureAliases[name]; ok {
+			for _, feature := range aliased {
+				set |= 1 << uint(featureIndex(feature))
+			}
+			continue
+		}
+		bit := featureIndex(name)
+		if bit < 0 {
+			unknown = append(unknown, name)
+			continue
+		}
+		set |= 1 << uint(bit)
+	}
+	if len(unknown) > 0 {
+		return set, fmt.Errorf("unknown features %s; valid features are %s",
+			strings.Join(unknown, ", "), strings.Join(FeatureNames, ", "))
+	}
+	return set, nil
+}
+
+func featureIndex(name string) int {
+	for i, feature := range FeatureNames {
+		if feature == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Names returns the features in the set in FeatureNames order
+func (f FeatureSet) Names() []string {
+	var names []string
+	for i, name := range FeatureNames {
+		if f&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Apply zeroes the features in the set on a feature vector, so a disabled
+// feature contributes nothing to training or prediction whatever its value.
+// The sequence is left alone; its samples were zeroed when they were extracted.
+func (f FeatureSet) Apply(features *FeatureVector) {
+	if f == 0 {
+		return
+	}
+	for _, field := range baseFeatures(features) {
+		if f&1 != 0 {
+			*field = 0
+		}
+		f >>= 1
+	}
+}
+
+// baseFeatures returns pointers to the base features of a feature vector in
+// FeatureNames order
+func baseFeatures(features *FeatureVector) []*float64 {
+	return []*float64{
+		&features.CPUUtilization,
+		&features.MemoryUtilization,
+		&features.RequestRate,
+		&features.NetworkBandwidth,
+		&features.IOBandwidth,
+		&features.ResponseTime,
+		&features.ErrorRate,
+		&features.HourSin,
+		&features.HourCos,
+		&features.DaySin,
+		&features.DayCos,
+		&features.Holiday,
+		&features.TrendCPU,
+		&features.TrendMemory,
+		&features.TrendRequests,
+	}
+}
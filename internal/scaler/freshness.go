@@ -0,0 +1,151 @@
+package scaler
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// freshnessCheckInterval is how often the scheduled retrain is checked for
+// and the freshness metrics are refreshed
+const freshnessCheckInterval = time.Minute
+
+var (
+	modelAge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "hydra_route_model_age_seconds",
+		Help: "Time since the active model was last trained successfully, or since startup when it never was.",
+	})
+	modelStale = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "hydra_route_model_stale",
+		Help: "1 when no training succeeded within scaling.ai_model.freshness_sla, 0 otherwise.",
+	})
+	trainingFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hydra_route_model_training_failures_total",
+		Help: "Retrains of the model that failed.",
+	})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(modelAge, modelStale, trainingFailures)
+}
+
+// ModelFreshness reports how long ago the active model was last trained
+type ModelFreshness struct {
+	// Time of the last successful training, of the loaded artifact or
+	// registry version, or zero when the model was never trained
+	TrainedAt time.Time `json:"trained_at,omitempty"`
+
+	// Time since TrainedAt, or since startup when it is zero
+	Age time.Duration `json:"age"`
+
+	// Configured freshness SLA; zero when not checked
+	SLA time.Duration `json:"sla,omitempty"`
+
+	// Whether Age exceeds the SLA
+	Stale bool `json:"stale"`
+
+	// Error of the latest retrain, when it failed after the last success
+	LastError string `json:"last_error,omitempty"`
+}
+
+// ModelFreshness returns the age of the active model and whether it is stale
+func (s *AIScaler) ModelFreshness() ModelFreshness {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	freshness := ModelFreshness{
+		TrainedAt: s.trainedAt,
+		SLA:       s.config.AIModel.FreshnessSLA,
+		LastError: s.trainingError,
+	}
+	since := s.trainedAt
+	if since.IsZero() {
+		since = s.startedAt
+	}
+	freshness.Age = time.Since(since)
+	freshness.Stale = freshness.SLA > 0 && freshness.Age > freshness.SLA
+	return freshness
+}
+
+// SetTrainedAt records when the active model was trained, for a model
+// loaded from an artifact or the registry
+func (s *AIScaler) SetTrainedAt(trainedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.trainedAt = trainedAt
+	s.trainingError = ""
+}
+
+// recordTraining records the outcome of a retrain of the active model
+func (s *AIScaler) recordTraining(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.trainingError = err.Error()
+		trainingFailures.Inc()
+		return
+	}
+	s.trainedAt = time.Now()
+	s.trainingError = ""
+}
+
+// StartModelFreshness retrains the model every retrain_interval with online
+// learning, whatever the amount of new training data, and keeps the model
+// age and staleness metrics current. It runs as a manager runnable on the
+// leader, which collects the training data.
+func (s *AIScaler) StartModelFreshness(ctx context.Context) error {
+	cfg := s.config.AIModel
+	scheduled := cfg.EnableOnlineLearning && cfg.RetrainInterval > 0
+	logrus.WithFields(logrus.Fields{
+		"retrain_interval": cfg.RetrainInterval,
+		"scheduled":        scheduled,
+		"freshness_sla":    cfg.FreshnessSLA,
+	}).Info("Starting model freshness checks")
+
+	ticker := time.NewTicker(freshnessCheckInterval)
+	defer ticker.Stop()
+
+	lastRetrain := time.Now()
+	stale := false
+	for {
+		stale = s.reportFreshness(stale)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			if scheduled && now.Sub(lastRetrain) >= cfg.RetrainInterval {
+				logrus.Info("Scheduled model retrain")
+				s.startRetrain()
+				lastRetrain = now
+			}
+		}
+	}
+}
+
+// reportFreshness exports the model age and staleness, warning when the
+// model turns stale, and returns whether it is
+func (s *AIScaler) reportFreshness(wasStale bool) bool {
+	freshness := s.ModelFreshness()
+	modelAge.Set(freshness.Age.Seconds())
+
+	if !freshness.Stale {
+		modelStale.Set(0)
+		return false
+	}
+	modelStale.Set(1)
+	if !wasStale {
+		logrus.WithFields(logrus.Fields{
+			"trained_at": freshness.TrainedAt,
+			"age":        freshness.Age.Round(time.Second),
+			"sla":        freshness.SLA,
+			"last_error": freshness.LastError,
+		}).Warn("Model is stale: no training succeeded within the freshness SLA")
+	}
+	return true
+}
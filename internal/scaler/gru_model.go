@@ -0,0 +1,303 @@
+package scaler
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// gruGradientClip bounds each gradient component during backpropagation
+// through time so a long sequence cannot blow up the weights
+const gruGradientClip = 5.0
+
+// GRUModel is a single-layer gated recurrent unit network. It reads the last
+// SequenceLength samples of a service (FeatureVector.Sequence followed by the
+// current sample) rather than only the instantaneous values, so it can learn
+// ramps, bursts and other temporal dynamics. Like the quantile model it is
+// trained on the pre-sigmoid scale factor.
+type GRUModel struct {
+	// Gate and candidate weights over the concatenated [input; hidden] vector
+	Wz, Wr, Wh [][]float64
+	Bz, Br, Bh []float64
+
+	// Readout from the final hidden state
+	Wo []float64
+	Bo float64
+
+	HiddenUnits    int
+	SequenceLength int
+	LearningRate   float64
+	Epochs         int
+	IsTrained      bool
+	Config         config.AIModelConfig
+}
+
+// gruStep holds the activations of one time step for backpropagation
+type gruStep struct {
+	x, hPrev, z, r, rh, hHat, h []float64
+}
+
+func newGRUModel(cfg config.AIModelConfig) *GRUModel {
+	return &GRUModel{
+		HiddenUnits:    cfg.Hyperparameters.HiddenUnits,
+		SequenceLength: cfg.Hyperparameters.SequenceLength,
+		LearningRate:   cfg.LearningRate,
+		Epochs:         cfg.Hyperparameters.Epochs,
+		Config:         cfg,
+	}
+}
+
+func (gm *GRUModel) Predict(features FeatureVector) (float64, float64, error) {
+	if !gm.IsTrained {
+		lm := &LinearModel{}
+		return lm.heuristicPredict(features), 0.3, nil
+	}
+
+	steps := gm.forward(gm.sequenceInputs(features, gm.numInputs()))
+	output := gm.readout(steps[len(steps)-1].h)
+
+	scaleFactor := 0.5 + 1.5*sigmoid(output)
+	confidence := 0.9 // As for the neural network
+
+	return scaleFactor, confidence, nil
+}
+
+// Train fits the network by backpropagation through time on the squared
+// error of the pre-sigmoid scale factor
+func (gm *GRUModel) Train(data []TrainingData) error {
+	if len(data) < 10 {
+		return fmt.Errorf("insufficient training data")
+	}
+
+	numInputs := len(gm.featuresToSlice(data[0].Features))
+	numHidden := gm.HiddenUnits
+	if numHidden <= 0 {
+		numHidden = defaultHiddenUnits
+	}
+	if !gm.IsTrained || gm.numInputs() != numInputs || len(gm.Bz) != numHidden {
+		gm.initWeights(numInputs, numHidden)
+	}
+
+	sequences := make([][][]float64, len(data))
+	targets := make([]float64, len(data))
+	for i, sample := range data {
+		sequences[i] = gm.sequenceInputs(sample.Features, numInputs)
+		targets[i] = scaleToLogit(sample.ActualScale)
+	}
+
+	learningRate := gm.LearningRate
+	if learningRate <= 0 {
+		learningRate = 0.01
+	}
+	epochs := gm.Epochs
+	if epochs <= 0 {
+		epochs = defaultEpochs
+	}
+
+	for epoch := 0; epoch < epochs; epoch++ {
+		for i, sequence := range sequences {
+			gm.backpropagate(sequence, targets[i], learningRate*sampleWeight(data[i]))
+		}
+	}
+
+	gm.IsTrained = true
+	return nil
+}
+
+func (gm *GRUModel) GetModelType() string {
+	return "gru"
+}
+
+func (gm *GRUModel) featuresToSlice(features FeatureVector) []float64 {
+	lm := &LinearModel{}
+	return lm.featuresToSlice(features)
+}
+
+// numInputs returns the input width the weights were initialized for
+func (gm *GRUModel) numInputs() int {
+	if len(gm.Wz) == 0 {
+		return 0
+	}
+	return len(gm.Wz[0]) - len(gm.Wz)
+}
+
+// sequenceInputs returns the model inputs for the last SequenceLength
+// samples ending with the current one, oldest first
+func (gm *GRUModel) sequenceInputs(features FeatureVector, numInputs int) [][]float64 {
+	length := gm.SequenceLength
+	if length <= 0 {
+		length = defaultSequenceLength
+	}
+
+	history := features.Sequence
+	if len(history) > length-1 {
+		history = history[len(history)-(length-1):]
+	}
+
+	inputs := make([][]float64, 0, len(history)+1)
+	for _, step := range history {
+		inputs = append(inputs, fitLength(gm.featuresToSlice(step), numInputs))
+	}
+	return append(inputs, fitLength(gm.featuresToSlice(features), numInputs))
+}
+
+// forward runs the sequence through the network from a zero hidden state
+func (gm *GRUModel) forward(inputs [][]float64) []gruStep {
+	numHidden := len(gm.Bz)
+	h := make([]float64, numHidden)
+
+	steps := make([]gruStep, len(inputs))
+	for t, x := range inputs {
+		step := gruStep{
+			x:     x,
+			hPrev: h,
+			z:     make([]float64, numHidden),
+			r:     make([]float64, numHidden),
+			rh:    make([]float64, numHidden),
+			hHat:  make([]float64, numHidden),
+			h:     make([]float64, numHidden),
+		}
+
+		xh := concat(x, h)
+		for k := 0; k < numHidden; k++ {
+			step.z[k] = sigmoid(gm.Bz[k] + dot(gm.Wz[k], xh))
+			step.r[k] = sigmoid(gm.Br[k] + dot(gm.Wr[k], xh))
+			step.rh[k] = step.r[k] * h[k]
+		}
+
+		xrh := concat(x, step.rh)
+		for k := 0; k < numHidden; k++ {
+			step.hHat[k] = math.Tanh(gm.Bh[k] + dot(gm.Wh[k], xrh))
+			step.h[k] = (1-step.z[k])*h[k] + step.z[k]*step.hHat[k]
+		}
+
+		steps[t] = step
+		h = step.h
+	}
+	return steps
+}
+
+func (gm *GRUModel) readout(h []float64) float64 {
+	return gm.Bo + dot(gm.Wo, h)
+}
+
+// backpropagate applies one gradient descent step for a single sequence
+func (gm *GRUModel) backpropagate(inputs [][]float64, target, learningRate float64) {
+	steps := gm.forward(inputs)
+	numInputs := len(inputs[0])
+	numHidden := len(gm.Bz)
+
+	last := steps[len(steps)-1]
+	errOut := clipGradient(gm.readout(last.h) - target)
+
+	dWz, dWr, dWh := zeroMatrix(numHidden, numInputs+numHidden), zeroMatrix(numHidden, numInputs+numHidden), zeroMatrix(numHidden, numInputs+numHidden)
+	dBz, dBr, dBh := make([]float64, numHidden), make([]float64, numHidden), make([]float64, numHidden)
+
+	dh := make([]float64, numHidden)
+	for k := range dh {
+		dh[k] = errOut * gm.Wo[k]
+	}
+
+	for t := len(steps) - 1; t >= 0; t-- {
+		step := steps[t]
+		xh := concat(step.x, step.hPrev)
+		xrh := concat(step.x, step.rh)
+		dhPrev := make([]float64, numHidden)
+		dRh := make([]float64, numHidden)
+
+		// Candidate state
+		for k := 0; k < numHidden; k++ {
+			dhPrev[k] += dh[k] * (1 - step.z[k])
+			da := clipGradient(dh[k] * step.z[k] * (1 - step.hHat[k]*step.hHat[k]))
+			dBh[k] += da
+			for j, v := range xrh {
+				dWh[k][j] += da * v
+			}
+			for j := 0; j < numHidden; j++ {
+				dRh[j] += gm.Wh[k][numInputs+j] * da
+			}
+		}
+
+		// Update and reset gates
+		for k := 0; k < numHidden; k++ {
+			dhPrev[k] += dRh[k] * step.r[k]
+
+			daz := clipGradient(dh[k] * (step.hHat[k] - step.hPrev[k]) * step.z[k] * (1 - step.z[k]))
+			dar := clipGradient(dRh[k] * step.hPrev[k] * step.r[k] * (1 - step.r[k]))
+			dBz[k] += daz
+			dBr[k] += dar
+			for j, v := range xh {
+				dWz[k][j] += daz * v
+				dWr[k][j] += dar * v
+			}
+			for j := 0; j < numHidden; j++ {
+				dhPrev[j] += gm.Wz[k][numInputs+j]*daz + gm.Wr[k][numInputs+j]*dar
+			}
+		}
+
+		dh = dhPrev
+	}
+
+	for k := 0; k < numHidden; k++ {
+		gm.Wo[k] -= learningRate * errOut * last.h[k]
+		gm.Bz[k] -= learningRate * clipGradient(dBz[k])
+		gm.Br[k] -= learningRate * clipGradient(dBr[k])
+		gm.Bh[k] -= learningRate * clipGradient(dBh[k])
+		for j := range gm.Wz[k] {
+			gm.Wz[k][j] -= learningRate * clipGradient(dWz[k][j])
+			gm.Wr[k][j] -= learningRate * clipGradient(dWr[k][j])
+			gm.Wh[k][j] -= learningRate * clipGradient(dWh[k][j])
+		}
+	}
+	gm.Bo -= learningRate * errOut
+}
+
+// initWeights initializes the network with small random weights
+func (gm *GRUModel) initWeights(numInputs, numHidden int) {
+	rng := newRand(gm.Config.Seed)
+	scale := 1.0 / math.Sqrt(float64(numInputs+numHidden))
+
+	randomMatrix := func() [][]float64 {
+		m := zeroMatrix(numHidden, numInputs+numHidden)
+		for k := range m {
+			for j := range m[k] {
+				m[k][j] = rng.NormFloat64() * scale
+			}
+		}
+		return m
+	}
+
+	gm.Wz, gm.Wr, gm.Wh = randomMatrix(), randomMatrix(), randomMatrix()
+	gm.Bz, gm.Br, gm.Bh = make([]float64, numHidden), make([]float64, numHidden), make([]float64, numHidden)
+	gm.Wo = make([]float64, numHidden)
+	for k := range gm.Wo {
+		gm.Wo[k] = rng.NormFloat64() * scale
+	}
+	gm.Bo = 0
+}
+
+func zeroMatrix(rows, cols int) [][]float64 {
+	m := make([][]float64, rows)
+	for i := range m {
+		m[i] = make([]float64, cols)
+	}
+	return m
+}
+
+func concat(a, b []float64) []float64 {
+	out := make([]float64, 0, len(a)+len(b))
+	return append(append(out, a...), b...)
+}
+
+func dot(weights, values []float64) float64 {
+	var sum float64
+	for i, v := range values {
+		sum += weights[i] * v
+	}
+	return sum
+}
+
+func clipGradient(g float64) float64 {
+	return math.Max(-gruGradientClip, math.Min(gruGradientClip, g))
+}
@@ -0,0 +1,45 @@
+package scaler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+)
+
+// holidayLayout is the date format of configured holidays
+const holidayLayout = "2006-01-02"
+
+// Holidays is the calendar of days, such as public holidays or sales
+// events, whose traffic follows its own pattern. Samples taken on them
+// carry the holiday feature, so models can learn the difference rather
+// than mistake it for an ordinary weekday. The nil calendar has no holidays.
+type Holidays map[string]bool
+
+// NewHolidays returns the calendar of the given dates (YYYY-MM-DD). Invalid
+// dates are reported in the error; the valid ones are still returned.
+func NewHolidays(dates []string) (Holidays, error) {
+	holidays := make(Holidays, len(dates))
+	var invalid []string
+	for _, date := range dates {
+		day, err := time.Parse(holidayLayout, date)
+		if err != nil {
+			invalid = append(invalid, date)
+			continue
+		}
+		holidays[day.Format(holidayLayout)] = true
+	}
+	if len(invalid) > 0 {
+		return holidays, fmt.Errorf("invalid holiday dates %v, expected YYYY-MM-DD", invalid)
+	}
+	return holidays, nil
+}
+
+// Apply sets the holiday feature of a feature vector extracted from a
+// metrics sample taken on a holiday. The date is that of the sample
+// timestamp, as are the time of day and day of week.
+func (h Holidays) Apply(metricsData *metrics.MetricsData, features *FeatureVector) {
+	if h[sampleTime(metricsData).Format(holidayLayout)] {
+		features.Holiday = 1
+	}
+}
@@ -0,0 +1,232 @@
+package scaler
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// maxImportanceSamples caps the samples permutation importance is computed
+// on; each feature costs a pass of predictions over them
+const maxImportanceSamples = 2000
+
+// windowAggregateNames name the aggregates of one window in the order they
+// appear in FeatureVector.Windows
+var windowAggregateNames = []string{"cpu_mean", "cpu_max", "memory_mean", "memory_max", "request_rate_mean", "request_rate_max"}
+
+var featureImportance = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "hydra_route_feature_importance",
+	Help: "Importance of each model feature after the latest training, by method (permutation or coefficient).",
+}, []string{"feature", "method"})
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(featureImportance)
+}
+
+// FeatureImportance is how much one feature drives the model's predictions
+type FeatureImportance struct {
+	Feature string `json:"feature"`
+
+	// Increase of the mean squared error when the feature's values are
+	// shuffled across samples; near zero for features the model ignores
+	Permutation float64 `json:"permutation"`
+
+	// Weight of the normalized feature in the pre-sigmoid output of the
+	// linear and quantile models
+	Coefficient *float64 `json:"coefficient,omitempty"`
+}
+
+// ImportanceReport holds the feature importances of a trained model,
+// most important first
+type ImportanceReport struct {
+	ModelType  string              `json:"model_type"`
+	ComputedAt time.Time           `json:"computed_at"`
+	Samples    int                 `json:"samples"`
+	BaseMSE    float64             `json:"base_mse"`
+	Features   []FeatureImportance `json:"features"`
+}
+
+// importanceFeature is a model input that can be read and set on a feature vector
+type importanceFeature struct {
+	name  string
+	input int // position in the model input
+	get   func(*FeatureVector) float64
+	set   func(*FeatureVector, float64)
+}
+
+// ComputeImportance scores the features of a trained model on labelled
+// samples. The derived feature and window names label the extra inputs;
+// unnamed ones are numbered. Disabled features are left out, since they
+// are always zero.
+func ComputeImportance(model AIModel, data []TrainingData, derived, windows []string, disabled FeatureSet) (*ImportanceReport, error) {
+	if len(data) > maxImportanceSamples {
+		data = data[len(data)-maxImportanceSamples:]
+	}
+	base, err := Evaluate(model, data)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportanceReport{
+		ModelType:  model.GetModelType(),
+		ComputedAt: time.Now(),
+		Samples:    len(data),
+		BaseMSE:    base.MSE,
+	}
+	coefficients := modelCoefficients(model)
+
+	// A fixed seed makes reports of the same model and data comparable
+	random := rand.New(rand.NewSource(1))
+	permuted := make([]TrainingData, len(data))
+	for _, feature := range importanceFeatures(data[0].Features, derived, windows, disabled) {
+		values := make([]float64, len(data))
+		for i := range data {
+			values[i] = feature.get(&data[i].Features)
+		}
+		random.Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+
+		for i, sample := range data {
+			sample.Features.Derived = append([]float64(nil), sample.Features.Derived...)
+			sample.Features.Windows = append([]float64(nil), sample.Features.Windows...)
+			feature.set(&sample.Features, values[i])
+			permuted[i] = sample
+		}
+		result, err := Evaluate(model, permuted)
+		if err != nil {
+			return nil, fmt.Errorf("feature %s: %w", feature.name, err)
+		}
+
+		importance := FeatureImportance{Feature: feature.name, Permutation: result.MSE - base.MSE}
+		if feature.input >= 0 && feature.input < len(coefficients) {
+			coefficient := coefficients[feature.input]
+			importance.Coefficient = &coefficient
+		}
+		report.Features = append(report.Features, importance)
+	}
+
+	sort.SliceStable(report.Features, func(i, j int) bool {
+		return report.Features[i].Permutation > report.Features[j].Permutation
+	})
+	return report, nil
+}
+
+// importanceFeatures lists the inputs of a feature vector laid out like the sample's
+func importanceFeatures(sample FeatureVector, derived, windows []string, disabled FeatureSet) []importanceFeature {
+	var features []importanceFeature
+	for i, name := range FeatureNames {
+		if disabled&(1<<uint(i)) != 0 {
+			continue
+		}
+		index := i
+		features = append(features, importanceFeature{
+			name:  name,
+			input: i,
+			get:   func(f *FeatureVector) float64 { return *baseFeatures(f)[index] },
+			set:   func(f *FeatureVector, v float64) { *baseFeatures(f)[index] = v },
+		})
+	}
+
+	input := len(FeatureNames)
+	for i := range sample.Derived {
+		name := fmt.Sprintf("derived_%d", i)
+		if i < len(derived) {
+			name = derived[i]
+		}
+		index := i
+		features = append(features, importanceFeature{
+			name:  name,
+			input: input + i,
+			get:   func(f *FeatureVector) float64 { return valueAt(f.Derived, index) },
+			set:   func(f *FeatureVector, v float64) { setAt(f.Derived, index, v) },
+		})
+	}
+
+	input += len(sample.Derived)
+	for i := range sample.Windows {
+		window := fmt.Sprintf("window_%d", i/len(windowAggregateNames))
+		if i/len(windowAggregateNames) < len(windows) {
+			window = windows[i/len(windowAggregateNames)]
+		}
+		index := i
+		features = append(features, importanceFeature{
+			name:  fmt.Sprintf("%s_%s", windowAggregateNames[i%len(windowAggregateNames)], window),
+			input: input + i,
+			get:   func(f *FeatureVector) float64 { return valueAt(f.Windows, index) },
+			set:   func(f *FeatureVector, v float64) { setAt(f.Windows, index, v) },
+		})
+	}
+	return features
+}
+
+// modelCoefficients returns the input weights of the models that are linear
+// in their inputs, or nil
+func modelCoefficients(model AIModel) []float64 {
+	switch m := model.(type) {
+	case *LinearModel:
+		if m.IsTrained {
+			return m.Weights
+		}
+	case *QuantileModel:
+		if m.IsTrained {
+			return m.Weights
+		}
+	}
+	return nil
+}
+
+func valueAt(values []float64, i int) float64 {
+	if i < len(values) {
+		return values[i]
+	}
+	return 0
+}
+
+func setAt(values []float64, i int, value float64) {
+	if i < len(values) {
+		values[i] = value
+	}
+}
+
+// updateImportance computes the importances of a freshly trained model,
+// keeps them for the admin API and exports them as metrics
+func (s *AIScaler) updateImportance(model AIModel, data []TrainingData) {
+	report, err := ComputeImportance(model, data, s.derived.Names(), nil, s.disabled)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to compute feature importance")
+		return
+	}
+	s.SetFeatureImportance(report)
+}
+
+// SetFeatureImportance replaces the importances of the active model, for
+// example with those recorded in its artifact. Nil clears them.
+func (s *AIScaler) SetFeatureImportance(report *ImportanceReport) {
+	s.mu.Lock()
+	s.importance = report
+	s.mu.Unlock()
+
+	featureImportance.Reset()
+	if report == nil {
+		return
+	}
+	for _, feature := range report.Features {
+		featureImportance.WithLabelValues(feature.Feature, "permutation").Set(feature.Permutation)
+		if feature.Coefficient != nil {
+			featureImportance.WithLabelValues(feature.Feature, "coefficient").Set(*feature.Coefficient)
+		}
+	}
+}
+
+// FeatureImportance returns the importances of the active model, or nil
+// before it has been trained or loaded with them
+func (s *AIScaler) FeatureImportance() *ImportanceReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.importance
+}
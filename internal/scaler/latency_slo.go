@@ -0,0 +1,20 @@
+package scaler
+
+import (
+	"math"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// latencyBurnFloor returns the replica floor of a service whose latency SLO
+// error budget burns at the threshold or faster in both windows, or 0
+func latencyBurnFloor(cfg config.LatencySLOScalingConfig, metricsData *metrics.MetricsData, currentReplicas int32) int32 {
+	if cfg.BurnRateThreshold <= 0 {
+		return 0
+	}
+	if metricsData.LatencyBurnRate5m < cfg.BurnRateThreshold || metricsData.LatencyBurnRate1h < cfg.BurnRateThreshold {
+		return 0
+	}
+	return int32(math.Ceil(float64(currentReplicas) * cfg.ScaleFactor))
+}
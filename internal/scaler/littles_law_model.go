@@ -0,0 +1,112 @@
+package scaler
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// minLittlesLawSamples is the number of usable samples needed to calibrate
+// the Little's Law model
+const minLittlesLawSamples = 10
+
+// LittlesLawModel is a queueing-theory baseline. By Little's Law the average
+// number of requests in flight is the arrival rate times the time each
+// request spends in the service, so a service needs
+//
+//	replicas = request_rate × response_time / target_concurrency
+//
+// to keep each replica at its target number of concurrent requests. Training
+// fits a single efficiency multiplier to the realized scale factors, which
+// absorbs whatever the formula doesn't see, such as per-request cost that
+// isn't spent in the measured response time.
+type LittlesLawModel struct {
+	TargetConcurrency float64
+	Efficiency        float64
+	Confidence        float64
+	IsTrained         bool
+	Config            config.AIModelConfig
+}
+
+func newLittlesLawModel(cfg config.AIModelConfig) *LittlesLawModel {
+	return &LittlesLawModel{
+		TargetConcurrency: cfg.Hyperparameters.TargetConcurrency,
+		Efficiency:        1,
+		Confidence:        0.6,
+		Config:            cfg,
+	}
+}
+
+// requiredReplicas is the replica count the formula asks for, or false when
+// the sample lacks traffic or latency
+func (ll *LittlesLawModel) requiredReplicas(features FeatureVector) (float64, bool) {
+	if features.RequestRate <= 0 || features.ResponseTime <= 0 || ll.TargetConcurrency <= 0 {
+		return 0, false
+	}
+	inFlight := features.RequestRate * features.ResponseTime / 1000.0
+	return ll.Efficiency * inFlight / ll.TargetConcurrency, true
+}
+
+func (ll *LittlesLawModel) Predict(features FeatureVector) (float64, float64, error) {
+	replicas, ok := ll.requiredReplicas(features)
+	if !ok || features.CurrentReplicas <= 0 {
+		lm := &LinearModel{}
+		return lm.heuristicPredict(features), 0.3, nil
+	}
+
+	scaleFactor := math.Max(0.5, math.Min(2.0, replicas/features.CurrentReplicas))
+	return scaleFactor, ll.Confidence, nil
+}
+
+// PredictTarget returns the required replicas; samples without traffic or
+// latency give an empty target, which falls back to the scale factor
+func (ll *LittlesLawModel) PredictTarget(features FeatureVector) (Target, error) {
+	replicas, ok := ll.requiredReplicas(features)
+	if !ok {
+		return Target{}, nil
+	}
+	return Target{Replicas: replicas, Confidence: ll.Confidence}, nil
+}
+
+// Train calibrates the efficiency multiplier by least squares against the
+// realized scale factors of samples that carry traffic, latency and their
+// replica count. Confidence follows the relative error of the fit.
+func (ll *LittlesLawModel) Train(data []TrainingData) error {
+	calibration := &LittlesLawModel{TargetConcurrency: ll.TargetConcurrency, Efficiency: 1}
+
+	var predicted, realized []float64
+	for _, sample := range data {
+		replicas, ok := calibration.requiredReplicas(sample.Features)
+		if !ok || sample.Features.CurrentReplicas <= 0 || sample.ActualScale <= 0 {
+			continue
+		}
+		predicted = append(predicted, replicas/sample.Features.CurrentReplicas)
+		realized = append(realized, sample.ActualScale)
+	}
+	if len(predicted) < minLittlesLawSamples {
+		return fmt.Errorf("insufficient training data: %d samples with traffic, latency and replicas", len(predicted))
+	}
+
+	var cross, squares float64
+	for i := range predicted {
+		cross += predicted[i] * realized[i]
+		squares += predicted[i] * predicted[i]
+	}
+	efficiency := math.Max(0.1, math.Min(10, cross/squares))
+
+	var relativeError float64
+	for i := range predicted {
+		relativeError += math.Abs(efficiency*predicted[i]-realized[i]) / realized[i]
+	}
+	relativeError /= float64(len(predicted))
+
+	ll.Efficiency = efficiency
+	ll.Confidence = math.Max(0.3, math.Min(0.95, 1-relativeError))
+	ll.IsTrained = true
+	return nil
+}
+
+func (ll *LittlesLawModel) GetModelType() string {
+	return "littles_law"
+}
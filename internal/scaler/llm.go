@@ -0,0 +1,41 @@
+package scaler
+
+import (
+	"math"
+	"time"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+)
+
+// llmTarget is the replica count the serving metrics of an LLM endpoint call for
+type llmTarget struct {
+	Replicas         float64
+	TokenRate        float64 // generated tokens per second
+	TimeToFirstToken float64 // milliseconds
+}
+
+// llmReplicas sizes an LLM endpoint from its serving metrics. Token
+// throughput divided by the tokens one replica serves gives one count; a
+// time to first token above or below the SLO scales the current replicas in
+// proportion, like an HPA on a latency metric. The larger count wins. It
+// reports false when the service has neither target or no LLM metrics.
+func llmReplicas(metricsData *metrics.MetricsData, settings ServiceSettings, currentReplicas int32) (llmTarget, bool) {
+	target := llmTarget{
+		TokenRate:        metricsData.TokenRate,
+		TimeToFirstToken: metricsData.TimeToFirstToken,
+	}
+	sized := false
+
+	if settings.TargetTokensPerSecond > 0 && metricsData.TokenRate > 0 {
+		target.Replicas = metricsData.TokenRate / settings.TargetTokensPerSecond
+		sized = true
+	}
+
+	slo := float64(settings.TimeToFirstTokenSLO) / float64(time.Millisecond)
+	if slo > 0 && metricsData.TimeToFirstToken > 0 {
+		target.Replicas = math.Max(target.Replicas, float64(currentReplicas)*metricsData.TimeToFirstToken/slo)
+		sized = true
+	}
+
+	return target, sized
+}
@@ -0,0 +1,39 @@
+package scaler
+
+import (
+	"time"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// MaintenanceWindows are planned downtimes of a service. Samples taken and
+// outcomes observed inside them show the service being worked on rather
+// than its workload, so they are neither learned from nor scored.
+type MaintenanceWindows []config.MaintenanceWindowConfig
+
+// MaintenanceWindowsFor returns the windows that apply to a service (namespace/name)
+func MaintenanceWindowsFor(windows []config.MaintenanceWindowConfig, key string) MaintenanceWindows {
+	var applying MaintenanceWindows
+	for _, window := range windows {
+		if window.AppliesTo(key) {
+			applying = append(applying, window)
+		}
+	}
+	return applying
+}
+
+// Contains reports whether t falls inside any of the windows
+func (w MaintenanceWindows) Contains(t time.Time) bool {
+	for _, window := range w {
+		if window.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// inMaintenanceWindow reports whether t falls inside a maintenance window
+// of the service, configured or declared by its policy
+func (s *AIScaler) inMaintenanceWindow(key string, t time.Time) bool {
+	return s.settingsFor(key).MaintenanceWindows.Contains(t)
+}
@@ -0,0 +1,24 @@
+package scaler
+
+import (
+	"github.com/hydraai/hydra-route/internal/metrics"
+)
+
+// Mesh ejection actions
+const (
+	MeshEjectionCompensate = "compensate"
+)
+
+// lostReplicas returns the replicas of a service that add no capacity under
+// the compensate actions: pods being preempted or not yet scheduled, and
+// endpoints the service mesh has ejected from load balancing
+func (s *AIScaler) lostReplicas(metricsData *metrics.MetricsData) int32 {
+	var lost int32
+	if s.config.Preemption.Action == PreemptionCompensate {
+		lost += unavailablePods(metricsData)
+	}
+	if s.config.MeshEjection.Action == MeshEjectionCompensate {
+		lost += int32(metricsData.EjectedEndpoints)
+	}
+	return lost
+}
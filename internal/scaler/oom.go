@@ -0,0 +1,58 @@
+package scaler
+
+import (
+	"math"
+	"sync"
+)
+
+// OOM actions
+const (
+	OOMScaleUp  = "scale_up"
+	OOMVertical = "vertical"
+)
+
+// oomFloor is the replica floor of a service with recurring OOM kills
+type oomFloor struct {
+	replicas int32
+	kills    int
+}
+
+// oomFloors raises a replica floor for services whose containers keep
+// getting OOM killed. Each kill seen while the kills recur multiplies the
+// current replicas by the scale factor; the floor holds until fewer than
+// the minimum kills remain in the window.
+type oomFloors struct {
+	mu     sync.Mutex
+	floors map[string]*oomFloor
+}
+
+func newOOMFloors() *oomFloors {
+	return &oomFloors{floors: make(map[string]*oomFloor)}
+}
+
+// floor returns the replica floor of a service, or 0 when its OOM kills
+// don't recur
+func (f *oomFloors) floor(key string, kills, minKills int, currentReplicas int32, factor float64) int32 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if kills < minKills {
+		delete(f.floors, key)
+		return 0
+	}
+
+	floor, ok := f.floors[key]
+	if !ok {
+		floor = &oomFloor{}
+		f.floors[key] = floor
+	}
+	if kills > floor.kills {
+		escalated := int32(math.Ceil(float64(currentReplicas) * factor))
+		if escalated > floor.replicas {
+			floor.replicas = escalated
+		}
+	}
+	// Kills leaving the window lower the count, so the next one escalates again
+	floor.kills = kills
+	return floor.replicas
+}
@@ -0,0 +1,219 @@
+package scaler
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+)
+
+// UnversionedModel labels predictions from a model that did not come from the registry
+const UnversionedModel = "unversioned"
+
+// PredictionError summarizes how far a model version's predictions were from
+// the scale factor later observed to be required
+type PredictionError struct {
+	Samples int     `json:"samples"`
+	MAE     float64 `json:"mae"`
+}
+
+// pendingPrediction holds predictions awaiting the next metrics sample
+type pendingPrediction struct {
+	sample      metrics.MetricsData
+	disrupted   bool // taken during node maintenance or a crash loop
+	features    FeatureVector
+	predictions map[string]float64 // model version -> predicted scale factor
+}
+
+// OutcomeTracker scores predictions against what the following metrics
+// sample shows was actually required. Pending predictions are sharded by
+// service key like the scaler's per-service state.
+type OutcomeTracker struct {
+	shards [stateShards]pendingShard
+
+	// Error rate from which resolved samples are flagged as incidents
+	incidentErrorRate float64
+
+	// How long predictions are held for the pods of a scale-up to become
+	// Ready; see AwaitReady
+	readyTimeout time.Duration
+
+	mu     sync.Mutex
+	errors map[string]*errorAccumulator
+}
+
+// pendingShard holds the pending predictions of the services whose keys hash to it
+type pendingShard struct {
+	mu      sync.Mutex
+	pending map[string]*pendingPrediction
+
+	// Scale-ups whose new pods are awaited, and the smoothed time the new
+	// pods of each service took to become Ready
+	rampUps     map[string]*rampUp
+	timeToReady map[string]time.Duration
+}
+
+type errorAccumulator struct {
+	samples int
+	sumAbs  float64
+}
+
+// NewOutcomeTracker creates an empty outcome tracker flagging samples with
+// an error rate from incidentErrorRate as incidents
+func NewOutcomeTracker(incidentErrorRate float64) *OutcomeTracker {
+	tracker := &OutcomeTracker{
+		incidentErrorRate: incidentErrorRate,
+		readyTimeout:      10 * time.Minute,
+		errors:            make(map[string]*errorAccumulator),
+	}
+	for i := range tracker.shards {
+		tracker.shards[i].pending = make(map[string]*pendingPrediction)
+		tracker.shards[i].rampUps = make(map[string]*rampUp)
+		tracker.shards[i].timeToReady = make(map[string]time.Duration)
+	}
+	return tracker
+}
+
+// Record stores the predictions made for a service from a metrics sample
+func (t *OutcomeTracker) Record(key string, sample *metrics.MetricsData, features FeatureVector, predictions map[string]float64) {
+	shard := &t.shards[shardIndex(key)]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	// The prediction behind an awaited scale-up is kept until it is scored
+	if _, held := shard.pending[key]; held && shard.rampUps[key] != nil {
+		return
+	}
+	shard.pending[key] = &pendingPrediction{
+		sample:      *sample,
+		disrupted:   sample.Maintenance || sample.CrashLoop,
+		features:    features,
+		predictions: predictions,
+	}
+}
+
+// Resolve scores the pending predictions for a service against the realized
+// scale factor derived from a newer metrics sample. The resolved sample is
+// returned as labelled training data, flagged with the conditions it was
+// collected under.
+func (t *OutcomeTracker) Resolve(key string, next *metrics.MetricsData, realize func(*metrics.MetricsData, int32) (float64, bool)) (TrainingData, bool) {
+	shard := &t.shards[shardIndex(key)]
+	shard.mu.Lock()
+	pending, exists := shard.pending[key]
+	if !exists || !next.Timestamp.After(pending.sample.Timestamp) {
+		shard.mu.Unlock()
+		return TrainingData{}, false
+	}
+	if ramp, awaited := shard.rampUps[key]; awaited {
+		switch {
+		case ramp.readyAt.IsZero() && next.Timestamp.Sub(ramp.since) > t.readyTimeout:
+			// The new pods never all became Ready; the outcome says little
+			delete(shard.rampUps, key)
+			delete(shard.pending, key)
+			shard.mu.Unlock()
+			return TrainingData{}, false
+		case ramp.readyAt.IsZero() || !next.Timestamp.After(ramp.readyAt):
+			shard.mu.Unlock()
+			return TrainingData{}, false
+		}
+		delete(shard.rampUps, key)
+	}
+	delete(shard.pending, key)
+	shard.mu.Unlock()
+
+	// Outcomes observed across node maintenance or crash loops say little about the models
+	if pending.disrupted || next.Maintenance || next.CrashLoop {
+		return TrainingData{}, false
+	}
+
+	realized, ok := realize(next, pending.sample.CurrentReplicas)
+	if !ok {
+		return TrainingData{}, false
+	}
+
+	t.mu.Lock()
+	for version, predicted := range pending.predictions {
+		acc, exists := t.errors[version]
+		if !exists {
+			acc = &errorAccumulator{}
+			t.errors[version] = acc
+		}
+		acc.samples++
+		acc.sumAbs += math.Abs(predicted - realized)
+	}
+	t.mu.Unlock()
+
+	return TrainingData{
+		Features:    pending.features,
+		ActualScale: realized,
+		Timestamp:   pending.sample.Timestamp,
+		Service:     key,
+		Revision:    pending.sample.Revision,
+		Image:       pending.sample.Image,
+		Flags:       QualityFlags(&pending.sample, next, t.incidentErrorRate),
+	}, true
+}
+
+// Discard drops the pending predictions for a service without scoring them
+func (t *OutcomeTracker) Discard(key string) {
+	shard := &t.shards[shardIndex(key)]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	delete(shard.pending, key)
+}
+
+// Error returns the accumulated prediction error for a model version
+func (t *OutcomeTracker) Error(version string) PredictionError {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	acc, exists := t.errors[version]
+	if !exists || acc.samples == 0 {
+		return PredictionError{}
+	}
+	return PredictionError{Samples: acc.samples, MAE: acc.sumAbs / float64(acc.samples)}
+}
+
+// Reset clears the accumulated error for a model version
+func (t *OutcomeTracker) Reset(version string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.errors, version)
+}
+
+// realizedScale estimates the scale factor that would have been right for
+// the interval that just ended: the load observed in the next sample relative
+// to the service's target utilization, expressed against the replica count at
+// the time of the prediction. Returns false when no thresholded signal is available.
+func (s *AIScaler) realizedScale(next *metrics.MetricsData, previousReplicas int32) (float64, bool) {
+	settings := s.settingsFor(fmt.Sprintf("%s/%s", next.Namespace, next.ServiceName))
+	var ratio float64
+	var found bool
+
+	if settings.TargetCPUUtilization > 0 && next.CPUUtilization > 0 {
+		cpu := next.CPUUtilization
+		if next.BatchContention && s.config.BatchContention.Action == BatchContentionRelax {
+			cpu = relaxContention(cpu, s.config.BatchContention.Relax)
+		}
+		ratio = math.Max(ratio, cpu/settings.TargetCPUUtilization)
+		found = true
+	}
+	if settings.TargetMemoryUtilization > 0 && next.MemoryUtilization > 0 {
+		ratio = math.Max(ratio, next.MemoryUtilization/settings.TargetMemoryUtilization)
+		found = true
+	}
+	if !found {
+		return 0, false
+	}
+
+	// Utilization is per replica; correct for replica changes since the prediction
+	if previousReplicas > 0 && next.CurrentReplicas > 0 {
+		ratio *= float64(next.CurrentReplicas) / float64(previousReplicas)
+	}
+
+	return math.Max(0.5, math.Min(2.0, ratio)), true
+}
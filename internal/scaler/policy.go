@@ -0,0 +1,154 @@
+package scaler
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+	"github.com/hydraai/hydra-route/pkg/expr"
+)
+
+// Policy targets a rule may assign
+const (
+	PolicyTargetScaleFactor = "scale_factor"
+	PolicyTargetConfidence  = "confidence"
+)
+
+// policyVariables are the variables available to policy rules. "model" is the
+// model's raw scale factor; "scale_factor" reflects earlier rules.
+var policyVariables = map[string]bool{
+	"cpu_utilization":          true,
+	"memory_utilization":       true,
+	"request_rate":             true,
+	"network_bandwidth":        true,
+	"io_bandwidth":             true,
+	"response_time":            true,
+	"error_rate":               true,
+	"concurrency":              true,
+	"cpu_throttling":           true,
+	"retry_amplification":      true,
+	"oom_kills":                true,
+	"memory_limit_utilization": true,
+	"token_rate":               true,
+	"time_to_first_token":      true,
+	"latency_burn_rate_5m":     true,
+	"latency_burn_rate_1h":     true,
+	"time_of_day":              true,
+	"day_of_week":              true,
+	"trend_cpu":                true,
+	"trend_memory":             true,
+	"trend_requests":           true,
+	"current_replicas":         true,
+	"model":                    true,
+	PolicyTargetScaleFactor:    true,
+	PolicyTargetConfidence:     true,
+}
+
+// Policy is a compiled scaling policy rule
+type Policy struct {
+	Name     string
+	Rule     *expr.Rule
+	Services map[string]bool // empty applies to all services
+}
+
+// PolicyResult records a policy that changed a decision
+type PolicyResult struct {
+	Name   string
+	Target string
+	Value  float64
+}
+
+// CompilePolicies parses the configured policy rules and checks that they
+// only read known variables and assign supported targets
+func CompilePolicies(cfgs []config.PolicyConfig) ([]*Policy, error) {
+	policies := make([]*Policy, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		name := cfg.Name
+		if name == "" {
+			name = fmt.Sprintf("policy-%d", i)
+		}
+
+		rule, err := expr.ParseRule(cfg.Rule)
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: %w", name, err)
+		}
+		if rule.Target != PolicyTargetScaleFactor && rule.Target != PolicyTargetConfidence {
+			return nil, fmt.Errorf("policy %s: cannot assign %q", name, rule.Target)
+		}
+		if err := rule.Check(policyVariables); err != nil {
+			return nil, fmt.Errorf("policy %s: %w", name, err)
+		}
+
+		policy := &Policy{Name: name, Rule: rule, Services: make(map[string]bool)}
+		for _, service := range cfg.Services {
+			policy.Services[service] = true
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// applyPolicies runs the policy rules in order against a model prediction and
+// returns the adjusted scale factor and confidence with the rules that fired.
+// A rule that fails to evaluate is skipped.
+func applyPolicies(policies []*Policy, key string, metricsData *metrics.MetricsData, features FeatureVector, scaleFactor, confidence float64) (float64, float64, []PolicyResult) {
+	if len(policies) == 0 {
+		return scaleFactor, confidence, nil
+	}
+
+	ts := sampleTime(metricsData)
+	vars := map[string]float64{
+		"cpu_utilization":          features.CPUUtilization,
+		"memory_utilization":       features.MemoryUtilization,
+		"request_rate":             features.RequestRate,
+		"network_bandwidth":        features.NetworkBandwidth,
+		"io_bandwidth":             features.IOBandwidth,
+		"response_time":            features.ResponseTime,
+		"error_rate":               features.ErrorRate,
+		"concurrency":              metricsData.Concurrency,
+		"cpu_throttling":           metricsData.CPUThrottling,
+		"retry_amplification":      metricsData.RetryAmplification,
+		"oom_kills":                float64(metricsData.OOMKills),
+		"memory_limit_utilization": metricsData.MemoryLimitUtilization,
+		"token_rate":               metricsData.TokenRate,
+		"time_to_first_token":      metricsData.TimeToFirstToken,
+		"latency_burn_rate_5m":     metricsData.LatencyBurnRate5m,
+		"latency_burn_rate_1h":     metricsData.LatencyBurnRate1h,
+		"time_of_day":              float64(ts.Hour()),
+		"day_of_week":              float64(ts.Weekday()),
+		"trend_cpu":                features.TrendCPU,
+		"trend_memory":             features.TrendMemory,
+		"trend_requests":           features.TrendRequests,
+		"current_replicas":         float64(metricsData.CurrentReplicas),
+		"model":                    scaleFactor,
+		PolicyTargetScaleFactor:    scaleFactor,
+		PolicyTargetConfidence:     confidence,
+	}
+	setImputedVariables(vars, metricsData)
+
+	var results []PolicyResult
+	for _, policy := range policies {
+		if len(policy.Services) > 0 && !policy.Services[key] {
+			continue
+		}
+
+		value, applied, err := policy.Rule.Apply(vars)
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"policy":  policy.Name,
+				"service": key,
+			}).Warn("Failed to evaluate scaling policy")
+			continue
+		}
+		if !applied {
+			continue
+		}
+
+		vars[policy.Rule.Target] = value
+		results = append(results, PolicyResult{Name: policy.Name, Target: policy.Rule.Target, Value: value})
+	}
+
+	return vars[PolicyTargetScaleFactor], vars[PolicyTargetConfidence], results
+}
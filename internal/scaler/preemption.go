@@ -0,0 +1,18 @@
+package scaler
+
+import (
+	"github.com/hydraai/hydra-route/internal/metrics"
+)
+
+// Preemption actions
+const (
+	PreemptionCompensate = "compensate"
+	PreemptionWait       = "wait"
+)
+
+// unavailablePods returns the pods that count towards a service's replicas
+// without serving: those being preempted, which are about to go, and those
+// the scheduler can't place, which have yet to start
+func unavailablePods(metricsData *metrics.MetricsData) int32 {
+	return int32(metricsData.PreemptedPods + metricsData.UnschedulablePods)
+}
@@ -0,0 +1,158 @@
+package scaler
+
+import (
+	"math"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Flags of training samples collected under conditions that make them poor
+// examples of the workload
+const (
+	// FlagIncident marks samples whose error rate reached
+	// training_quality.incident_error_rate
+	FlagIncident = "incident"
+
+	// FlagRollout marks samples whose deployment revision or images changed
+	// before the outcome was observed
+	FlagRollout = "rollout"
+
+	// FlagCollectorOutage marks samples with stale or imputed metrics
+	FlagCollectorOutage = "collector_outage"
+)
+
+// reasonOutlier labels samples trimmed as outliers
+const reasonOutlier = "outlier"
+
+// madScale makes the median absolute deviation consistent with the standard
+// deviation of normally distributed data
+const madScale = 1.4826
+
+var trainingSamplesFiltered = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "hydra_route_training_samples_filtered_total",
+	Help: "Training samples dropped before training, by flag or as outliers.",
+}, []string{"reason"})
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(trainingSamplesFiltered)
+}
+
+// QualityFlags returns the flags of the sample taken from current, whose
+// outcome next shows
+func QualityFlags(current, next *metrics.MetricsData, incidentErrorRate float64) []string {
+	var flags []string
+	if incidentErrorRate > 0 && (current.ErrorRate >= incidentErrorRate || next.ErrorRate >= incidentErrorRate) {
+		flags = append(flags, FlagIncident)
+	}
+	if current.Revision != next.Revision || current.Image != next.Image {
+		flags = append(flags, FlagRollout)
+	}
+	if collectorOutage(current) || collectorOutage(next) {
+		flags = append(flags, FlagCollectorOutage)
+	}
+	return flags
+}
+
+// collectorOutage reports whether a metrics sample misses fresh data from a source
+func collectorOutage(sample *metrics.MetricsData) bool {
+	return sample.StalenessSeconds > 0 || len(sample.Imputed) > 0
+}
+
+// FilterTrainingData drops the samples carrying a flag in cfg.Exclude, then
+// the samples whose scale factor lies more than cfg.OutlierThreshold scaled
+// median absolute deviations from the median of their service. Medians are
+// robust to the samples they trim, so a single bad day cannot move them.
+// It returns the kept samples and the number dropped per reason.
+func FilterTrainingData(data []TrainingData, cfg config.TrainingQualityConfig) ([]TrainingData, map[string]int) {
+	dropped := make(map[string]int)
+
+	excluded := make(map[string]bool, len(cfg.Exclude))
+	for _, flag := range cfg.Exclude {
+		excluded[flag] = true
+	}
+
+	kept := make([]TrainingData, 0, len(data))
+	byService := make(map[string][]float64)
+samples:
+	for _, sample := range data {
+		for _, flag := range sample.Flags {
+			if excluded[flag] {
+				dropped[flag]++
+				continue samples
+			}
+		}
+		kept = append(kept, sample)
+		byService[sample.Service] = append(byService[sample.Service], sample.ActualScale)
+	}
+
+	if cfg.OutlierThreshold <= 0 {
+		recordFiltered(dropped)
+		return kept, dropped
+	}
+
+	type bounds struct{ median, spread float64 }
+	limits := make(map[string]bounds, len(byService))
+	for service, scales := range byService {
+		median := medianOf(scales)
+		deviations := make([]float64, len(scales))
+		for i, scale := range scales {
+			deviations[i] = math.Abs(scale - median)
+		}
+		limits[service] = bounds{median: median, spread: madScale * medianOf(deviations)}
+	}
+
+	trimmed := kept[:0]
+	for _, sample := range kept {
+		limit := limits[sample.Service]
+		// A zero spread means most samples agree exactly; only trim around a spread
+		if limit.spread > 0 && math.Abs(sample.ActualScale-limit.median) > cfg.OutlierThreshold*limit.spread {
+			dropped[reasonOutlier]++
+			continue
+		}
+		trimmed = append(trimmed, sample)
+	}
+
+	recordFiltered(dropped)
+	return trimmed, dropped
+}
+
+// recordFiltered counts the dropped samples in the filter metric
+func recordFiltered(dropped map[string]int) {
+	for reason, count := range dropped {
+		trainingSamplesFiltered.WithLabelValues(reason).Add(float64(count))
+	}
+}
+
+// medianOf returns the median of values, sorting a copy
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// filterTrainingData applies training_quality to collected training data,
+// logging what it dropped
+func (s *AIScaler) filterTrainingData(data []TrainingData) []TrainingData {
+	kept, dropped := FilterTrainingData(data, s.config.AIModel.TrainingQuality)
+	if len(kept) < len(data) {
+		fields := logrus.Fields{"kept": len(kept)}
+		for reason, count := range dropped {
+			fields[reason] = count
+		}
+		logrus.WithFields(fields).Info("Filtered training data")
+	}
+	return kept
+}
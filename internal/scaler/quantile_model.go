@@ -0,0 +1,122 @@
+package scaler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// QuantileModel is a linear quantile regression model. It predicts a high
+// quantile (the 90th percentile by default) of the required scale factor
+// instead of its mean, giving latency-sensitive services a principled safety
+// margin. Because the output mapping is monotonic, fitting the quantile in
+// the pre-sigmoid space yields the same quantile of the scale factor.
+type QuantileModel struct {
+	Weights        []float64
+	Bias           float64
+	Quantile       float64
+	LearningRate   float64
+	Epochs         int
+	Regularization float64
+	IsTrained      bool
+	Config         config.AIModelConfig
+}
+
+func newQuantileModel(cfg config.AIModelConfig) *QuantileModel {
+	return &QuantileModel{
+		Quantile:       cfg.Hyperparameters.Quantile,
+		LearningRate:   cfg.LearningRate,
+		Epochs:         cfg.Hyperparameters.Epochs,
+		Regularization: cfg.Hyperparameters.Regularization,
+		Config:         cfg,
+	}
+}
+
+func (qm *QuantileModel) Predict(features FeatureVector) (float64, float64, error) {
+	if !qm.IsTrained {
+		lm := &LinearModel{}
+		return lm.heuristicPredict(features), 0.5, nil
+	}
+
+	input := qm.featuresToSlice(features)
+	prediction := qm.Bias
+	for i, feature := range input {
+		if i < len(qm.Weights) {
+			prediction += qm.Weights[i] * feature
+		}
+	}
+
+	scaleFactor := 0.5 + 1.5*sigmoid(prediction)
+	confidence := 0.8 // Static confidence, as for the linear model
+
+	return scaleFactor, confidence, nil
+}
+
+// Train fits the model by subgradient descent on the pinball loss
+func (qm *QuantileModel) Train(data []TrainingData) error {
+	if len(data) < 10 {
+		return fmt.Errorf("insufficient training data")
+	}
+
+	tau := qm.Quantile
+	if tau <= 0 || tau >= 1 {
+		tau = 0.9
+	}
+	learningRate := qm.LearningRate
+	if learningRate <= 0 {
+		learningRate = 0.01
+	}
+	epochs := qm.Epochs
+	if epochs <= 0 {
+		epochs = defaultEpochs
+	}
+
+	numInputs := len(qm.featuresToSlice(data[0].Features))
+	inputs := make([][]float64, len(data))
+	targets := make([]float64, len(data))
+	for i, sample := range data {
+		inputs[i] = fitLength(qm.featuresToSlice(sample.Features), numInputs)
+		targets[i] = scaleToLogit(sample.ActualScale)
+	}
+
+	// Start from the empirical quantile so training only has to learn the feature effects
+	sorted := append([]float64(nil), targets...)
+	sort.Float64s(sorted)
+	qm.Bias = sorted[int(tau*float64(len(sorted)-1))]
+	qm.Weights = make([]float64, numInputs)
+
+	for epoch := 0; epoch < epochs; epoch++ {
+		for i, input := range inputs {
+			prediction := qm.Bias
+			for j, x := range input {
+				prediction += qm.Weights[j] * x
+			}
+
+			// Subgradient of the pinball loss with respect to the prediction
+			grad := 1 - tau
+			if targets[i] > prediction {
+				grad = -tau
+			}
+			grad *= sampleWeight(data[i])
+
+			for j, x := range input {
+				qm.Weights[j] -= learningRate * (grad*x + qm.Regularization*qm.Weights[j])
+			}
+			qm.Bias -= learningRate * grad
+		}
+	}
+
+	qm.Quantile = tau
+	qm.IsTrained = true
+	return nil
+}
+
+func (qm *QuantileModel) GetModelType() string {
+	return "quantile"
+}
+
+func (qm *QuantileModel) featuresToSlice(features FeatureVector) []float64 {
+	lm := &LinearModel{}
+	return lm.featuresToSlice(features)
+}
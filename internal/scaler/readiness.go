@@ -0,0 +1,117 @@
+package scaler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// timeToReadySmoothing is the weight of the latest scale-up in the smoothed
+// time to ready of a service
+const timeToReadySmoothing = 0.3
+
+var (
+	scaleUpReadySeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "hydra_route_scale_up_ready_seconds",
+		Help:    "Time from a scale-up to the last of its new pods becoming Ready.",
+		Buckets: []float64{5, 10, 20, 30, 60, 120, 300, 600, 1200},
+	})
+	timeToReadySeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hydra_route_time_to_ready_seconds",
+		Help: "Smoothed time the new pods of a service's scale-ups take to become Ready.",
+	}, []string{"service", "namespace"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(scaleUpReadySeconds, timeToReadySeconds)
+}
+
+// rampUp is a scale-up whose new pods are awaited
+type rampUp struct {
+	since   time.Time // when the replicas were written
+	readyAt time.Time // when the last new pod became Ready; zero until then
+}
+
+// AwaitReady holds the pending prediction of a service, the one behind a
+// scale-up written at since, until the new pods are Ready. Samples taken
+// while they start count replicas that don't serve yet, so the outcome is
+// scored against the first sample after MarkReady instead, or dropped when
+// the pods aren't Ready within the timeout.
+func (t *OutcomeTracker) AwaitReady(key string, since time.Time) {
+	shard := &t.shards[shardIndex(key)]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	shard.rampUps[key] = &rampUp{since: since}
+}
+
+// MarkReady notes that the new pods of a service's awaited scale-up were
+// all Ready at readyAt, and returns how long they took
+func (t *OutcomeTracker) MarkReady(key string, readyAt time.Time) (time.Duration, bool) {
+	shard := &t.shards[shardIndex(key)]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	ramp, awaited := shard.rampUps[key]
+	if !awaited || !ramp.readyAt.IsZero() {
+		return 0, false
+	}
+	ramp.readyAt = readyAt
+	took := readyAt.Sub(ramp.since)
+	if took < 0 {
+		took = 0
+	}
+
+	if smoothed, ok := shard.timeToReady[key]; ok {
+		shard.timeToReady[key] = smoothed + time.Duration(timeToReadySmoothing*float64(took-smoothed))
+	} else {
+		shard.timeToReady[key] = took
+	}
+	return took, true
+}
+
+// TimeToReady returns the smoothed time the new pods of a service's
+// scale-ups took to become Ready
+func (t *OutcomeTracker) TimeToReady(key string) (time.Duration, bool) {
+	shard := &t.shards[shardIndex(key)]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	smoothed, ok := shard.timeToReady[key]
+	return smoothed, ok
+}
+
+// ScaledUp notes a scale-up of a service written at since, whose outcome
+// waits for its new pods to become Ready
+func (s *AIScaler) ScaledUp(key string, since time.Time) {
+	s.outcomes.AwaitReady(key, since)
+}
+
+// ReplicasReady notes that the new pods of a service's latest scale-up were
+// all Ready at readyAt
+func (s *AIScaler) ReplicasReady(key string, readyAt time.Time) {
+	took, ok := s.outcomes.MarkReady(key, readyAt)
+	if !ok {
+		return
+	}
+	smoothed, _ := s.outcomes.TimeToReady(key)
+
+	namespace, service, _ := strings.Cut(key, "/")
+	scaleUpReadySeconds.Observe(took.Seconds())
+	timeToReadySeconds.WithLabelValues(service, namespace).Set(smoothed.Seconds())
+	logrus.WithFields(logrus.Fields{
+		"service":       service,
+		"namespace":     namespace,
+		"time_to_ready": took,
+		"smoothed":      smoothed,
+	}).Debug("New replicas ready")
+}
+
+// TimeToReady returns the smoothed time the new pods of a service's
+// scale-ups took to become Ready, once one has been observed
+func (s *AIScaler) TimeToReady(key string) (time.Duration, bool) {
+	return s.outcomes.TimeToReady(key)
+}
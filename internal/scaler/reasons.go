@@ -0,0 +1,318 @@
+package scaler
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReasonCode is a machine-readable reason behind a scaling decision
+type ReasonCode string
+
+// Reason codes, with the parameters each carries
+const (
+	// Signals above their threshold: value, threshold
+	ReasonCPUHigh          ReasonCode = "CPU_HIGH"
+	ReasonMemoryHigh       ReasonCode = "MEMORY_HIGH"
+	ReasonRequestRateHigh  ReasonCode = "REQUEST_RATE_HIGH"
+	ReasonErrorRateHigh    ReasonCode = "ERROR_RATE_HIGH"
+	ReasonResponseTimeHigh ReasonCode = "RESPONSE_TIME_HIGH"
+
+	// Model outcome, one per decision that reached the model: factor, confidence
+	ReasonModelScaleUp   ReasonCode = "MODEL_SCALE_UP"
+	ReasonModelScaleDown ReasonCode = "MODEL_SCALE_DOWN"
+	ReasonNoChange       ReasonCode = "NO_CHANGE"
+
+	// Recurring OOM kills: kills, memory_limit_utilization, and replicas for
+	// the scale-up floor or recommendation for a vertical recommendation
+	ReasonOOMKilled ReasonCode = "OOM_KILLED"
+
+	// CPU utilization corrected for CFS throttling: throttling, measured, corrected
+	ReasonCPUThrottled ReasonCode = "CPU_THROTTLED"
+
+	// Request rate discounted for retries: amplification, measured, corrected
+	ReasonRetryStorm ReasonCode = "RETRY_STORM"
+
+	// Latency SLO error budget burning in both windows: burn_rate_5m,
+	// burn_rate_1h, replicas
+	ReasonLatencySLOBurn ReasonCode = "LATENCY_SLO_BURN"
+
+	// Replica floor raised by an SLO breached on consecutive evaluations,
+	// held until the SLO recovers: response_time, error_rate, replicas
+	ReasonSLOEscalation ReasonCode = "SLO_ESCALATION"
+
+	// Absolute target the factor was derived from: replicas
+	ReasonAbsoluteTarget ReasonCode = "ABSOLUTE_TARGET"
+
+	// In-flight requests that sized the service instead of the model:
+	// concurrency, target, replicas, panic
+	ReasonConcurrencyTarget ReasonCode = "CONCURRENCY_TARGET"
+
+	// Serving metrics that sized an LLM endpoint: token_rate, ttft_ms, replicas
+	ReasonLLMTarget ReasonCode = "LLM_TARGET"
+
+	// Adjustments made after the model
+	ReasonPolicyApplied         ReasonCode = "POLICY_APPLIED"          // policy, target, value, model
+	ReasonStaleMetricsDampened  ReasonCode = "STALE_METRICS_DAMPENED"  // age_seconds, dampening
+	ReasonBehaviorLimited       ReasonCode = "HPA_BEHAVIOR_LIMITED"    // replicas
+	ReasonMaintenanceHold       ReasonCode = "MAINTENANCE_HOLD"        // pods
+	ReasonCrashLoopHold         ReasonCode = "CRASH_LOOP_HOLD"         // pods
+	ReasonBatchContention       ReasonCode = "BATCH_CONTENTION"        // pods, relax or replicas
+	ReasonActionBudgetExhausted ReasonCode = "ACTION_BUDGET_EXHAUSTED" // per_hour, per_day
+	ReasonZoneSpread            ReasonCode = "ZONE_SPREAD"             // zones, per_zone, replicas
+	ReasonPreemption            ReasonCode = "PREEMPTION"              // preempted, unschedulable, action
+	ReasonMeshEjection          ReasonCode = "MESH_EJECTION"           // endpoints
+	ReasonExperiment            ReasonCode = "EXPERIMENT"              // experiment, cohort
+
+	// Replicas too few for every zone the service spreads over, because of
+	// the warn action, max replicas or a hold: zones, per_zone, replicas
+	ReasonZoneSpreadUnsatisfied ReasonCode = "ZONE_SPREAD_UNSATISFIED"
+
+	// Replicas held without consulting the model: age_seconds, max_age_seconds
+	ReasonStaleMetricsHold ReasonCode = "STALE_METRICS_HOLD"
+)
+
+// Thresholds above which a signal is reported as a reason
+const (
+	reasonCPUThreshold          = 80   // percent
+	reasonMemoryThreshold       = 80   // percent
+	reasonRequestRateThreshold  = 100  // requests per second
+	reasonErrorRateThreshold    = 5    // percent
+	reasonResponseTimeThreshold = 1000 // milliseconds
+)
+
+// Reason is a reason code with its parameters. Parameters are strings so
+// they round-trip through Kubernetes resources unchanged; numeric values are
+// formatted with strconv and parse back with strconv.ParseFloat.
+type Reason struct {
+	Code       ReasonCode        `json:"code"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// newReason builds a reason from name/value pairs; float64 values are
+// rounded to two decimals
+func newReason(code ReasonCode, params ...interface{}) Reason {
+	reason := Reason{Code: code}
+	if len(params) == 0 {
+		return reason
+	}
+	reason.Parameters = make(map[string]string, len(params)/2)
+	for i := 0; i+1 < len(params); i += 2 {
+		name := params[i].(string)
+		switch value := params[i+1].(type) {
+		case float64:
+			reason.Parameters[name] = strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+		default:
+			reason.Parameters[name] = fmt.Sprint(value)
+		}
+	}
+	return reason
+}
+
+// Float returns a numeric parameter, or 0 when missing
+func (r Reason) Float(name string) float64 {
+	value, _ := strconv.ParseFloat(r.Parameters[name], 64)
+	return value
+}
+
+// String renders a reason as "CODE{name:value,...}" with sorted parameter names
+func (r Reason) String() string {
+	if len(r.Parameters) == 0 {
+		return string(r.Code)
+	}
+	names := make([]string, 0, len(r.Parameters))
+	for name := range r.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	params := make([]string, 0, len(names))
+	for _, name := range names {
+		params = append(params, name+":"+r.Parameters[name])
+	}
+	return fmt.Sprintf("%s{%s}", r.Code, strings.Join(params, ","))
+}
+
+// ReasonCodes returns the codes of a list of reasons
+func ReasonCodes(reasons []Reason) []string {
+	codes := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		codes = append(codes, string(reason.Code))
+	}
+	return codes
+}
+
+// FindReason returns the first reason with a code
+func FindReason(reasons []Reason, code ReasonCode) (Reason, bool) {
+	for _, reason := range reasons {
+		if reason.Code == code {
+			return reason, true
+		}
+	}
+	return Reason{}, false
+}
+
+// signalReasons returns the reasons for the signals above their thresholds
+// and the model outcome
+func signalReasons(features FeatureVector, scaleFactor, confidence float64) []Reason {
+	var reasons []Reason
+	for _, signal := range []struct {
+		code      ReasonCode
+		value     float64
+		threshold float64
+	}{
+		{ReasonCPUHigh, features.CPUUtilization, reasonCPUThreshold},
+		{ReasonMemoryHigh, features.MemoryUtilization, reasonMemoryThreshold},
+		{ReasonRequestRateHigh, features.RequestRate, reasonRequestRateThreshold},
+		{ReasonErrorRateHigh, features.ErrorRate, reasonErrorRateThreshold},
+		{ReasonResponseTimeHigh, features.ResponseTime, reasonResponseTimeThreshold},
+	} {
+		if signal.value > signal.threshold {
+			reasons = append(reasons, newReason(signal.code, "value", signal.value, "threshold", signal.threshold))
+		}
+	}
+
+	outcome := ReasonNoChange
+	if scaleFactor > 1.1 {
+		outcome = ReasonModelScaleUp
+	} else if scaleFactor < 0.9 {
+		outcome = ReasonModelScaleDown
+	}
+	return append(reasons, newReason(outcome, "factor", scaleFactor, "confidence", confidence))
+}
+
+// signalDescriptions are the human-readable names of the signal codes
+var signalDescriptions = map[ReasonCode]string{
+	ReasonCPUHigh:          "high CPU utilization",
+	ReasonMemoryHigh:       "high memory utilization",
+	ReasonRequestRateHigh:  "high request rate",
+	ReasonErrorRateHigh:    "elevated error rate",
+	ReasonResponseTimeHigh: "slow response times",
+}
+
+// RenderReasons renders the human-readable explanation of a decision from
+// its reasons: the signals and model outcome first, then each adjustment
+func RenderReasons(reasons []Reason) string {
+	var signals []string
+	var outcome *Reason
+	var adjustments []string
+
+	for i, reason := range reasons {
+		if description, ok := signalDescriptions[reason.Code]; ok {
+			signals = append(signals, description)
+			continue
+		}
+		switch reason.Code {
+		case ReasonModelScaleUp, ReasonModelScaleDown, ReasonNoChange:
+			outcome = &reasons[i]
+		case ReasonOOMKilled:
+			if reason.Parameters["recommendation"] == OOMVertical {
+				adjustments = append(adjustments, fmt.Sprintf("%s OOM kills (memory at %.0f%% of limits), raise the memory limit",
+					reason.Parameters["kills"], reason.Float("memory_limit_utilization")))
+			} else {
+				adjustments = append(adjustments, fmt.Sprintf("%s OOM kills (memory at %.0f%% of limits), scaled up to at least %s replicas",
+					reason.Parameters["kills"], reason.Float("memory_limit_utilization"), reason.Parameters["replicas"]))
+			}
+		case ReasonCPUThrottled:
+			adjustments = append(adjustments, fmt.Sprintf("CPU throttled in %.0f%% of periods, utilization corrected from %.0f%% to %.0f%%",
+				reason.Float("throttling"), reason.Float("measured"), reason.Float("corrected")))
+		case ReasonRetryStorm:
+			adjustments = append(adjustments, fmt.Sprintf("retries amplified requests %.1fx, request rate discounted from %.1f to %.1f/s",
+				reason.Float("amplification"), reason.Float("measured"), reason.Float("corrected")))
+		case ReasonAbsoluteTarget:
+			adjustments = append(adjustments, fmt.Sprintf("model target of %s replicas", reason.Parameters["replicas"]))
+		case ReasonConcurrencyTarget:
+			adjustment := fmt.Sprintf("%.2f requests in flight at %s per replica call for %s replicas",
+				reason.Float("concurrency"), reason.Parameters["target"], reason.Parameters["replicas"])
+			if reason.Parameters["panic"] == "true" {
+				adjustment += " (panic mode)"
+			}
+			adjustments = append(adjustments, adjustment)
+		case ReasonLLMTarget:
+			adjustments = append(adjustments, fmt.Sprintf("%.0f tokens/s with %.0fms to first token call for %s replicas",
+				reason.Float("token_rate"), reason.Float("ttft_ms"), reason.Parameters["replicas"]))
+		case ReasonPolicyApplied:
+			adjustments = append(adjustments, fmt.Sprintf("policy %s set %s to %.2f (model: %.2f)",
+				reason.Parameters["policy"], reason.Parameters["target"], reason.Float("value"), reason.Float("model")))
+		case ReasonStaleMetricsDampened:
+			adjustments = append(adjustments, fmt.Sprintf("stale metrics (%s old), change dampened by %.2f",
+				seconds(reason.Float("age_seconds")), reason.Float("dampening")))
+		case ReasonBehaviorLimited:
+			adjustments = append(adjustments, fmt.Sprintf("limited to %s replicas by the HPA behavior", reason.Parameters["replicas"]))
+		case ReasonMaintenanceHold:
+			adjustments = append(adjustments, fmt.Sprintf("scale-up held during node maintenance (%s pods affected)", reason.Parameters["pods"]))
+		case ReasonCrashLoopHold:
+			adjustments = append(adjustments, fmt.Sprintf("scale-down held while %s pods crash-loop", reason.Parameters["pods"]))
+		case ReasonBatchContention:
+			if _, ok := reason.Parameters["relax"]; ok {
+				adjustments = append(adjustments, fmt.Sprintf("batch jobs share nodes with %s pods, CPU target relaxed by %.0f%%",
+					reason.Parameters["pods"], reason.Float("relax")*100))
+			} else {
+				adjustments = append(adjustments, fmt.Sprintf("batch jobs share nodes with %s pods, pre-scaled to at least %s replicas",
+					reason.Parameters["pods"], reason.Parameters["replicas"]))
+			}
+		case ReasonActionBudgetExhausted:
+			adjustments = append(adjustments, fmt.Sprintf("held, action budget exhausted (%s per hour, %s per day)",
+				reason.Parameters["per_hour"], reason.Parameters["per_day"]))
+		case ReasonPreemption:
+			if reason.Parameters["action"] == PreemptionWait {
+				adjustments = append(adjustments, fmt.Sprintf("held while %s pods are preempted and %s unschedulable",
+					reason.Parameters["preempted"], reason.Parameters["unschedulable"]))
+			} else {
+				adjustments = append(adjustments, fmt.Sprintf("sized from the serving replicas, %s pods preempted and %s unschedulable",
+					reason.Parameters["preempted"], reason.Parameters["unschedulable"]))
+			}
+		case ReasonExperiment:
+			if reason.Parameters["cohort"] == CohortThreshold {
+				adjustments = append(adjustments, fmt.Sprintf("scaled by thresholds in the threshold cohort of experiment %s",
+					reason.Parameters["experiment"]))
+			} else {
+				adjustments = append(adjustments, fmt.Sprintf("AI cohort of experiment %s", reason.Parameters["experiment"]))
+			}
+		case ReasonMeshEjection:
+			adjustments = append(adjustments, fmt.Sprintf("sized from the endpoints receiving traffic, %s ejected by the service mesh",
+				reason.Parameters["endpoints"]))
+		case ReasonZoneSpread:
+			adjustments = append(adjustments, fmt.Sprintf("kept at least %s replicas, %s in each of %s zones",
+				reason.Parameters["replicas"], reason.Parameters["per_zone"], reason.Parameters["zones"]))
+		case ReasonZoneSpreadUnsatisfied:
+			adjustments = append(adjustments, fmt.Sprintf("too few replicas for %s in each of %s zones (%s needed)",
+				reason.Parameters["per_zone"], reason.Parameters["zones"], reason.Parameters["replicas"]))
+		case ReasonSLOEscalation:
+			adjustments = append(adjustments, fmt.Sprintf("kept at least %s replicas after a sustained SLO breach (%.0fms, %.2f%% errors)",
+				reason.Parameters["replicas"], reason.Float("response_time"), reason.Float("error_rate")))
+		case ReasonStaleMetricsHold:
+			adjustments = append(adjustments, fmt.Sprintf("stale metrics: latest sample is %s old (max age %s)",
+				seconds(reason.Float("age_seconds")), seconds(reason.Float("max_age_seconds"))))
+		default:
+			adjustments = append(adjustments, reason.String())
+		}
+	}
+
+	var parts []string
+	if outcome != nil {
+		factor, confidence := outcome.Float("factor"), outcome.Float("confidence")
+		switch {
+		case len(signals) > 0:
+			action := "up"
+			if factor < 1.0 {
+				action = "down"
+			}
+			parts = append(parts, fmt.Sprintf("Scaling %s due to: %v (factor: %.2f, confidence: %.2f)", action, signals, factor, confidence))
+		case outcome.Code == ReasonModelScaleUp:
+			parts = append(parts, fmt.Sprintf("AI model recommends scaling up (factor: %.2f, confidence: %.2f)", factor, confidence))
+		case outcome.Code == ReasonModelScaleDown:
+			parts = append(parts, fmt.Sprintf("AI model recommends scaling down (factor: %.2f, confidence: %.2f)", factor, confidence))
+		default:
+			parts = append(parts, "No scaling needed based on current metrics")
+		}
+	}
+	return strings.Join(append(parts, adjustments...), "; ")
+}
+
+// seconds formats a number of seconds as a rounded duration
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second)).Round(time.Second)
+}
@@ -0,0 +1,114 @@
+package scaler
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// WeightByRevision weights training samples by how recent the deployment
+// revision they were observed under is. Per service, samples from the
+// current revision keep their weight and each older revision is multiplied
+// by cfg.Decay once more. Samples from revisions beyond cfg.KeepRevisions,
+// or whose images come from a different repository than the current
+// revision's, are discarded as belonging to a materially different
+// application. Samples without revision information are left unchanged.
+func WeightByRevision(data []TrainingData, cfg config.RevisionWeightingConfig) []TrainingData {
+	// Rank revisions per service by when they were last observed
+	lastSeen := make(map[string]map[string]time.Time)
+	images := make(map[string]map[string]string)
+	for _, sample := range data {
+		revision := revisionOf(sample)
+		if revision == "" {
+			continue
+		}
+		if lastSeen[sample.Service] == nil {
+			lastSeen[sample.Service] = make(map[string]time.Time)
+			images[sample.Service] = make(map[string]string)
+		}
+		if sample.Timestamp.After(lastSeen[sample.Service][revision]) {
+			lastSeen[sample.Service][revision] = sample.Timestamp
+			images[sample.Service][revision] = sample.Image
+		}
+	}
+
+	ranks := make(map[string]map[string]int)
+	currentImages := make(map[string]string)
+	for service, revisions := range lastSeen {
+		ordered := make([]string, 0, len(revisions))
+		for revision := range revisions {
+			ordered = append(ordered, revision)
+		}
+		sort.Slice(ordered, func(i, j int) bool {
+			return revisions[ordered[i]].After(revisions[ordered[j]])
+		})
+
+		ranks[service] = make(map[string]int, len(ordered))
+		for rank, revision := range ordered {
+			ranks[service][revision] = rank
+		}
+		currentImages[service] = images[service][ordered[0]]
+	}
+
+	weighted := make([]TrainingData, 0, len(data))
+	for _, sample := range data {
+		revision := revisionOf(sample)
+		if revision == "" {
+			weighted = append(weighted, sample)
+			continue
+		}
+
+		rank := ranks[sample.Service][revision]
+		if cfg.KeepRevisions > 0 && rank >= cfg.KeepRevisions {
+			continue
+		}
+		if !sameRepositories(sample.Image, currentImages[sample.Service]) {
+			continue
+		}
+
+		sample.Weight = sampleWeight(sample) * math.Pow(cfg.Decay, float64(rank))
+		weighted = append(weighted, sample)
+	}
+	return weighted
+}
+
+// revisionOf identifies the revision of a sample, falling back to its images
+func revisionOf(sample TrainingData) string {
+	if sample.Revision != "" {
+		return sample.Revision
+	}
+	return sample.Image
+}
+
+// sameRepositories reports whether two comma-separated image lists use the
+// same repositories, ignoring tags and digests
+func sameRepositories(a, b string) bool {
+	if a == "" || b == "" {
+		return true
+	}
+	left, right := strings.Split(a, ","), strings.Split(b, ",")
+	if len(left) != len(right) {
+		return false
+	}
+	for i := range left {
+		if imageRepository(left[i]) != imageRepository(right[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// imageRepository strips the tag and digest from an image reference
+func imageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	// A colon after the last slash separates the tag; earlier ones belong to a registry port
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
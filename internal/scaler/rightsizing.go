@@ -0,0 +1,149 @@
+package scaler
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+)
+
+// RightSizingReport lists the services found over-provisioned or idle by
+// the latest right-sizing analysis
+type RightSizingReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Window      string    `json:"window"`
+
+	// Services with enough history to be analyzed
+	Analyzed int `json:"analyzed"`
+
+	// Services with findings, by key
+	Services []ServiceRightSizing `json:"services"`
+}
+
+// ServiceRightSizing is the analysis of one service over the window.
+// Requests are per replica, summed over its containers: CPU in cores,
+// memory in MB.
+type ServiceRightSizing struct {
+	Service string `json:"service"`
+	Samples int    `json:"samples"`
+	Idle    bool   `json:"idle,omitempty"`
+
+	// Configured minimum, the replicas the observed peak needed at the
+	// utilization targets and the minimum recommended instead
+	MinReplicas            int32 `json:"min_replicas"`
+	PeakReplicas           int32 `json:"peak_replicas"`
+	RecommendedMinReplicas int32 `json:"recommended_min_replicas,omitempty"`
+
+	PeakCPUUtilization     float64 `json:"peak_cpu_utilization"`
+	CPURequests            float64 `json:"cpu_requests,omitempty"`
+	RecommendedCPURequests float64 `json:"recommended_cpu_requests,omitempty"`
+
+	PeakMemoryUtilization     float64 `json:"peak_memory_utilization"`
+	MemoryRequests            float64 `json:"memory_requests,omitempty"`
+	RecommendedMemoryRequests float64 `json:"recommended_memory_requests,omitempty"`
+
+	// Human-readable findings
+	Findings []string `json:"findings"`
+}
+
+// AnalyzeRightSizing compares a service's provisioning with its peak over
+// the history: a minimum above the replicas the peak needed, requests the
+// peak left mostly unused, or no traffic at all. Samples taken during node
+// maintenance or crash loops are left out. The latest sample supplies the
+// current requests. It returns nil when the history spans too little of
+// the window or nothing is over-provisioned.
+func (s *AIScaler) AnalyzeRightSizing(key string, history []*metrics.MetricsData, latest *metrics.MetricsData) *ServiceRightSizing {
+	cfg := s.config.RightSizing
+	settings := s.settingsFor(key)
+
+	var samples []*metrics.MetricsData
+	for _, sample := range history {
+		if sample.CurrentReplicas > 0 && !sample.Maintenance && !sample.CrashLoop {
+			samples = append(samples, sample)
+		}
+	}
+	if len(samples) < 2 {
+		return nil
+	}
+	span := samples[len(samples)-1].Timestamp.Sub(samples[0].Timestamp)
+	if span.Seconds() < cfg.Window.Seconds()*cfg.MinCoverage {
+		return nil
+	}
+
+	var peakCPU, peakMemory, peakRate, peakNeeded float64
+	for _, sample := range samples {
+		peakCPU = math.Max(peakCPU, sample.CPUUtilization)
+		peakMemory = math.Max(peakMemory, sample.MemoryUtilization)
+		peakRate = math.Max(peakRate, sample.RequestRate)
+
+		var load float64
+		if settings.TargetCPUUtilization > 0 {
+			load = sample.CPUUtilization / settings.TargetCPUUtilization
+		}
+		if settings.TargetMemoryUtilization > 0 {
+			load = math.Max(load, sample.MemoryUtilization/settings.TargetMemoryUtilization)
+		}
+		peakNeeded = math.Max(peakNeeded, float64(sample.CurrentReplicas)*load)
+	}
+
+	result := &ServiceRightSizing{
+		Service:               key,
+		Samples:               len(samples),
+		MinReplicas:           settings.MinReplicas,
+		PeakReplicas:          int32(math.Ceil(peakNeeded)),
+		PeakCPUUtilization:    math.Round(peakCPU*10) / 10,
+		PeakMemoryUtilization: math.Round(peakMemory*10) / 10,
+	}
+	headroom := 1 + cfg.Headroom
+
+	if peakRate == 0 && peakCPU < cfg.IdleCPUUtilization {
+		result.Idle = true
+		result.Findings = append(result.Findings, fmt.Sprintf("idle: served no requests and peaked at %.1f%% CPU in %s", peakCPU, span.Round(time.Hour)))
+	}
+
+	recommendedMin := int32(math.Max(1, math.Ceil(peakNeeded*headroom)))
+	if result.Idle {
+		recommendedMin = 1
+	}
+	if settings.MinReplicas > recommendedMin {
+		result.RecommendedMinReplicas = recommendedMin
+		result.Findings = append(result.Findings, fmt.Sprintf("min_replicas of %d is above the %d replicas the peak needed, %d would do",
+			settings.MinReplicas, result.PeakReplicas, recommendedMin))
+	}
+
+	if latest != nil && latest.CPURequests > 0 && peakCPU > 0 && peakCPU < cfg.RequestUtilizationThreshold {
+		result.CPURequests = latest.CPURequests
+		result.RecommendedCPURequests = math.Ceil(latest.CPURequests*peakCPU/100*headroom*100) / 100
+		result.Findings = append(result.Findings, fmt.Sprintf("CPU requests of %.2f cores peaked at %.0f%% use, %.2f cores would do",
+			result.CPURequests, peakCPU, result.RecommendedCPURequests))
+	}
+	if latest != nil && latest.MemoryRequests > 0 && peakMemory > 0 && peakMemory < cfg.RequestUtilizationThreshold {
+		result.MemoryRequests = math.Round(latest.MemoryRequests)
+		result.RecommendedMemoryRequests = math.Ceil(latest.MemoryRequests * peakMemory / 100 * headroom)
+		result.Findings = append(result.Findings, fmt.Sprintf("memory requests of %.0fMB peaked at %.0f%% use, %.0fMB would do",
+			result.MemoryRequests, peakMemory, result.RecommendedMemoryRequests))
+	}
+
+	if len(result.Findings) == 0 {
+		return nil
+	}
+	return result
+}
+
+// SetRightSizingReport stores the latest right-sizing report
+func (s *AIScaler) SetRightSizingReport(report *RightSizingReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rightSizing = report
+}
+
+// RightSizingReport returns the latest right-sizing report, or nil before
+// the first analysis
+func (s *AIScaler) RightSizingReport() *RightSizingReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.rightSizing
+}
@@ -0,0 +1,65 @@
+package scaler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Range of the scale factor the models predict: their output is mapped
+// through 0.5 + 1.5*sigmoid(x)
+const (
+	ModelMinScaleFactor = 0.5
+	ModelMaxScaleFactor = 2.0
+)
+
+// ScaleFactorBounds is the envelope a service's predicted scale factors are
+// mapped onto: Min below 1 for the strongest scale-down, Max above 1 for
+// the strongest scale-up
+type ScaleFactorBounds struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// apply maps a predicted scale factor onto the bounds. Each side of 1 is
+// stretched or compressed linearly, so 1 stays neutral and the models'
+// extremes reach the bounds. Bounds on the wrong side of 1, or unset, keep
+// the models' range, which leaves every factor as it is, including the
+// heuristic fallback's beyond that range; other bounds hold it within them.
+func (b ScaleFactorBounds) apply(factor float64) float64 {
+	if b.Min <= 0 || b.Min >= 1 {
+		b.Min = ModelMinScaleFactor
+	}
+	if b.Max <= 1 {
+		b.Max = ModelMaxScaleFactor
+	}
+	if b.Min == ModelMinScaleFactor && b.Max == ModelMaxScaleFactor {
+		return factor
+	}
+	factor = math.Max(ModelMinScaleFactor, math.Min(ModelMaxScaleFactor, factor))
+	switch {
+	case factor > 1:
+		return 1 + (factor-1)*(b.Max-1)/(ModelMaxScaleFactor-1)
+	case factor < 1:
+		return 1 - (1-factor)*(1-b.Min)/(1-ModelMinScaleFactor)
+	}
+	return factor
+}
+
+// ParseScaleFactorBounds reads the decimal bounds of a HydraRoutePolicy.
+// An empty bound is left 0, so it falls back to the configuration.
+func ParseScaleFactorBounds(minFactor, maxFactor string) (ScaleFactorBounds, error) {
+	var bounds ScaleFactorBounds
+	var err error
+	if minFactor != "" {
+		if bounds.Min, err = strconv.ParseFloat(minFactor, 64); err != nil || bounds.Min <= 0 || bounds.Min >= 1 {
+			return ScaleFactorBounds{}, fmt.Errorf("minScaleFactor %q must be a decimal between 0 and 1", minFactor)
+		}
+	}
+	if maxFactor != "" {
+		if bounds.Max, err = strconv.ParseFloat(maxFactor, 64); err != nil || bounds.Max <= 1 || math.IsInf(bounds.Max, 1) {
+			return ScaleFactorBounds{}, fmt.Errorf("maxScaleFactor %q must be a decimal above 1", maxFactor)
+		}
+	}
+	return bounds, nil
+}
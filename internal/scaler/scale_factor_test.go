@@ -0,0 +1,34 @@
+package scaler
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScaleFactorBoundsApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		bounds ScaleFactorBounds
+		factor float64
+		want   float64
+	}{
+		{name: "unset bounds leave a model factor", factor: 1.7, want: 1.7},
+		{name: "unset bounds leave a heuristic factor beyond the model range", factor: 2.34, want: 2.34},
+		{name: "default bounds leave a heuristic factor beyond the model range", bounds: ScaleFactorBounds{Min: 0.5, Max: 2}, factor: 2.34, want: 2.34},
+		{name: "default bounds leave a scale-down", bounds: ScaleFactorBounds{Min: 0.5, Max: 2}, factor: 0.4, want: 0.4},
+		{name: "invalid bounds keep the model range", bounds: ScaleFactorBounds{Min: 1.5, Max: 0.9}, factor: 2.34, want: 2.34},
+		{name: "neutral factor stays neutral", bounds: ScaleFactorBounds{Min: 0.8, Max: 3}, factor: 1, want: 1},
+		{name: "scale-up stretched", bounds: ScaleFactorBounds{Min: 0.8, Max: 3}, factor: 1.5, want: 2},
+		{name: "model maximum reaches the bound", bounds: ScaleFactorBounds{Min: 0.8, Max: 3}, factor: 2, want: 3},
+		{name: "heuristic factor held within custom bounds", bounds: ScaleFactorBounds{Min: 0.8, Max: 3}, factor: 2.34, want: 3},
+		{name: "scale-down compressed", bounds: ScaleFactorBounds{Min: 0.8, Max: 3}, factor: 0.75, want: 0.9},
+		{name: "model minimum reaches the bound", bounds: ScaleFactorBounds{Min: 0.8, Max: 3}, factor: 0.5, want: 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.bounds.apply(tt.factor); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("apply(%v) with %+v = %v, want %v", tt.factor, tt.bounds, got, tt.want)
+			}
+		})
+	}
+}
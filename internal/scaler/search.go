@@ -0,0 +1,220 @@
+package scaler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Candidate is one point in the hyperparameter search space. Zero values
+// keep the base configuration's setting.
+type Candidate struct {
+	LearningRate   float64 `json:"learning_rate,omitempty"`
+	Regularization float64 `json:"regularization"`
+	HiddenUnits    int     `json:"hidden_units,omitempty"`
+}
+
+// Apply returns a copy of the model configuration with the candidate's values set
+func (c Candidate) Apply(cfg config.AIModelConfig) config.AIModelConfig {
+	if c.LearningRate > 0 {
+		cfg.LearningRate = c.LearningRate
+	}
+	if c.HiddenUnits > 0 {
+		cfg.Hyperparameters.HiddenUnits = c.HiddenUnits
+	}
+	cfg.Hyperparameters.Regularization = c.Regularization
+	return cfg
+}
+
+// SearchResult is the best candidate found by a search
+type SearchResult struct {
+	Candidate  Candidate        `json:"candidate"`
+	Evaluation EvaluationResult `json:"evaluation"`
+}
+
+// GridCandidates enumerates every combination of the configured values.
+// Dimensions irrelevant to the model type are collapsed to the base value.
+func GridCandidates(base config.AIModelConfig, space config.HyperparameterSearchConfig) []Candidate {
+	learningRates, regularization, hiddenUnits := searchDimensions(base, space)
+
+	var candidates []Candidate
+	for _, lr := range learningRates {
+		for _, reg := range regularization {
+			for _, units := range hiddenUnits {
+				candidates = append(candidates, Candidate{LearningRate: lr, Regularization: reg, HiddenUnits: units})
+			}
+		}
+	}
+	return candidates
+}
+
+// RandomCandidates samples up to trials distinct combinations of the configured values
+func RandomCandidates(base config.AIModelConfig, space config.HyperparameterSearchConfig, trials int, rng *rand.Rand) []Candidate {
+	grid := GridCandidates(base, space)
+	if trials >= len(grid) {
+		return grid
+	}
+
+	rng.Shuffle(len(grid), func(i, j int) { grid[i], grid[j] = grid[j], grid[i] })
+	return grid[:trials]
+}
+
+func searchDimensions(base config.AIModelConfig, space config.HyperparameterSearchConfig) ([]float64, []float64, []int) {
+	learningRates := []float64{base.LearningRate}
+	regularization := []float64{base.Hyperparameters.Regularization}
+	hiddenUnits := []int{base.Hyperparameters.HiddenUnits}
+
+	usesRegularization := base.ModelType != "neural_network" && base.ModelType != "gru"
+	usesLearningRate := base.ModelType != "linear"
+	usesHiddenUnits := base.ModelType == "neural_network" || base.ModelType == "gru" || base.ModelType == "ensemble"
+
+	if usesLearningRate && len(space.LearningRates) > 0 {
+		learningRates = space.LearningRates
+	}
+	if usesHiddenUnits && len(space.HiddenUnits) > 0 {
+		hiddenUnits = space.HiddenUnits
+	}
+	if usesRegularization && len(space.Regularization) > 0 {
+		regularization = space.Regularization
+	}
+
+	return learningRates, regularization, hiddenUnits
+}
+
+// Search scores every candidate and returns the one with the lowest MSE.
+// score receives a factory for untrained models configured with the candidate.
+func Search(base config.AIModelConfig, candidates []Candidate, score func(newModel func() AIModel) (EvaluationResult, error)) (SearchResult, error) {
+	best := SearchResult{}
+	bestMSE := math.Inf(1)
+	var lastErr error
+
+	for _, c := range candidates {
+		cfg := c.Apply(base)
+		result, err := score(func() AIModel { return NewModel(cfg) })
+		if err != nil {
+			lastErr = err
+			logrus.WithError(err).WithField("candidate", c).Debug("Hyperparameter candidate failed")
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"candidate": c,
+			"mse":       result.MSE,
+		}).Debug("Evaluated hyperparameter candidate")
+
+		if result.MSE < bestMSE {
+			best = SearchResult{Candidate: c, Evaluation: result}
+			bestMSE = result.MSE
+		}
+	}
+
+	if math.IsInf(bestMSE, 1) {
+		return best, fmt.Errorf("no candidate trained successfully: %v", lastErr)
+	}
+	return best, nil
+}
+
+// HoldoutScorer returns a score function that trains on the older samples,
+// balanced by scale event type when balance is enabled, and evaluates on the
+// most recent fraction
+func HoldoutScorer(data []TrainingData, fraction float64, balance config.ClassBalanceConfig) func(newModel func() AIModel) (EvaluationResult, error) {
+	train, holdout := SplitHoldout(data, fraction)
+	train = balanceIf(train, balance)
+	return func(newModel func() AIModel) (EvaluationResult, error) {
+		model := newModel()
+		if err := model.Train(train); err != nil {
+			return EvaluationResult{}, err
+		}
+		return Evaluate(model, holdout)
+	}
+}
+
+// StartHyperparameterSearch periodically searches the configured space on the
+// collected training data and replaces the model when a candidate beats the
+// current configuration on held-out data. It runs as a manager runnable on
+// the leader, which collects the training data.
+func (s *AIScaler) StartHyperparameterSearch(ctx context.Context) error {
+	searchCfg := s.config.AIModel.Hyperparameters.Search
+	logrus.WithFields(logrus.Fields{
+		"interval": searchCfg.Interval,
+		"strategy": searchCfg.Strategy,
+	}).Info("Starting periodic hyperparameter search")
+
+	ticker := time.NewTicker(searchCfg.Interval)
+	defer ticker.Stop()
+
+	rng := newRand(s.config.AIModel.Seed)
+	current := Candidate{
+		LearningRate:   s.config.AIModel.LearningRate,
+		Regularization: s.config.AIModel.Hyperparameters.Regularization,
+		HiddenUnits:    s.config.AIModel.Hyperparameters.HiddenUnits,
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if selected, err := s.runHyperparameterSearch(current, rng); err != nil {
+				logrus.WithError(err).Warn("Hyperparameter search failed")
+			} else {
+				current = selected
+			}
+		}
+	}
+}
+
+// runHyperparameterSearch performs one search and returns the candidate in effect afterwards
+func (s *AIScaler) runHyperparameterSearch(current Candidate, rng *rand.Rand) (Candidate, error) {
+	searchCfg := s.config.AIModel.Hyperparameters.Search
+
+	data := s.states.trainingData()
+	if s.config.AIModel.TrainingQuality.Enabled {
+		data = s.filterTrainingData(data)
+	}
+
+	if len(data) < searchCfg.MinSamples {
+		logrus.WithField("samples", len(data)).Debug("Not enough training data for hyperparameter search")
+		return current, nil
+	}
+
+	base := current.Apply(s.config.AIModel)
+	candidates := GridCandidates(base, searchCfg)
+	if searchCfg.Strategy == "random" {
+		candidates = RandomCandidates(base, searchCfg, searchCfg.Trials, rng)
+	}
+	candidates = append(candidates, current)
+
+	score := HoldoutScorer(data, searchCfg.HoldoutFraction, s.config.AIModel.ClassBalance)
+	result, err := Search(s.config.AIModel, candidates, score)
+	if err != nil {
+		return current, err
+	}
+	if result.Candidate == current {
+		logrus.WithField("mse", result.Evaluation.MSE).Info("Hyperparameter search kept the current configuration")
+		return current, nil
+	}
+
+	// Retrain on all collected data with the winning configuration
+	model := NewModel(result.Candidate.Apply(s.config.AIModel))
+	if err := model.Train(balanceIf(data, s.config.AIModel.ClassBalance)); err != nil {
+		return current, fmt.Errorf("failed to train selected candidate: %w", err)
+	}
+
+	s.SetModel(model, "")
+	s.recordTraining(nil)
+	s.SetFeatureReference(BuildFeatureReference(data))
+	s.updateImportance(model, data)
+	logrus.WithFields(logrus.Fields{
+		"candidate": result.Candidate,
+		"mse":       result.Evaluation.MSE,
+	}).Info("Hyperparameter search selected a new configuration")
+
+	return result.Candidate, nil
+}
@@ -0,0 +1,78 @@
+package scaler
+
+import (
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+)
+
+// ServiceSettings override the global scaling configuration for one service.
+// Zero values fall back to the configuration.
+type ServiceSettings struct {
+	MinReplicas             int32
+	MaxReplicas             int32
+	TargetCPUUtilization    float64       // percent; replaces the scale-up CPU threshold
+	TargetMemoryUtilization float64       // percent; replaces the scale-up memory threshold
+	RequestsPerReplica      float64       // requests per second; converts capacity targets to replicas
+	TargetConcurrency       float64       // requests in flight per replica in the concurrency target mode
+	TargetTokensPerSecond   float64       // generated tokens per second per replica of LLM endpoints
+	TimeToFirstTokenSLO     time.Duration // 95th percentile time to first token of LLM endpoints
+	DisabledFeatures        FeatureSet    // zeroed for this service, in addition to the globally disabled ones
+
+	// Envelope the predicted scale factor is mapped onto; each zero bound
+	// falls back to the configuration
+	ScaleFactor ScaleFactorBounds
+
+	// Planned downtime of the service, in addition to the configured windows
+	MaintenanceWindows MaintenanceWindows
+
+	// HPA v2 scale-up and scale-down behavior; replaces the cooldowns when set
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior
+}
+
+// SetServiceSettings sets the overrides for a service (namespace/name).
+// Passing the zero value removes them.
+func (s *AIScaler) SetServiceSettings(key string, settings ServiceSettings) {
+	s.states.setSettings(key, settings)
+}
+
+// settingsFor returns the overrides of a service with the global
+// configuration filled in for unset fields
+func (s *AIScaler) settingsFor(key string) ServiceSettings {
+	settings := s.states.settings(key)
+
+	if settings.MinReplicas == 0 {
+		settings.MinReplicas = s.config.MinReplicas
+	}
+	if settings.MaxReplicas == 0 {
+		settings.MaxReplicas = s.config.MaxReplicas
+	}
+	if settings.TargetCPUUtilization == 0 {
+		settings.TargetCPUUtilization = s.config.ScaleUpThresholds.CPUUtilization
+	}
+	if settings.TargetMemoryUtilization == 0 {
+		settings.TargetMemoryUtilization = s.config.ScaleUpThresholds.MemoryUtilization
+	}
+	if settings.RequestsPerReplica == 0 {
+		settings.RequestsPerReplica = s.config.RequestsPerReplica
+	}
+	if settings.TargetConcurrency == 0 {
+		settings.TargetConcurrency = s.config.Concurrency.Target
+	}
+	if settings.TargetTokensPerSecond == 0 {
+		settings.TargetTokensPerSecond = s.config.LLM.TargetTokensPerReplica
+	}
+	if settings.TimeToFirstTokenSLO == 0 {
+		settings.TimeToFirstTokenSLO = s.config.LLM.TimeToFirstTokenSLO
+	}
+	if settings.ScaleFactor.Min == 0 {
+		settings.ScaleFactor.Min = s.config.Prediction.MinScaleFactor
+	}
+	if settings.ScaleFactor.Max == 0 {
+		settings.ScaleFactor.Max = s.config.Prediction.MaxScaleFactor
+	}
+	settings.DisabledFeatures |= s.disabled
+	settings.MaintenanceWindows = append(settings.MaintenanceWindows[:len(settings.MaintenanceWindows):len(settings.MaintenanceWindows)],
+		MaintenanceWindowsFor(s.config.AIModel.MaintenanceWindows, key)...)
+	return settings
+}
@@ -0,0 +1,302 @@
+package scaler
+
+import (
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// stateShards is the number of shards the per-service state is spread
+	// over, so decisions for different services rarely wait on each other
+	stateShards = 64
+
+	// minTrainingSamplesPerService is the fewest training samples kept per
+	// service, however many services share the training budget
+	minTrainingSamplesPerService = 20
+)
+
+// serviceState is what the scaler keeps about one service between decisions
+type serviceState struct {
+	lastDecision *ScalingDecision
+
+	// Time of the last decision that changed replicas; zero if none
+	lastScaled time.Time
+
+	settings ServiceSettings
+	sequence []FeatureVector
+	training trainingRing
+}
+
+// stateShard holds the state of the services whose keys hash to it
+type stateShard struct {
+	mu       sync.RWMutex
+	services map[string]*serviceState
+}
+
+// serviceStates spreads the per-service state over shards by service key.
+// Training samples are kept in a ring buffer per service, each holding its
+// share of maxTraining, so one service's samples never displace
+// another's and adding a sample never copies the others.
+type serviceStates struct {
+	shards [stateShards]stateShard
+
+	// Training samples kept across all services
+	maxTraining int
+
+	// Services holding training samples, and samples added since the start
+	trainingServices atomic.Int64
+	samplesAdded     atomic.Int64
+}
+
+func newServiceStates(maxTraining int) *serviceStates {
+	if maxTraining <= 0 {
+		maxTraining = maxTrainingData
+	}
+	states := &serviceStates{maxTraining: maxTraining}
+	for i := range states.shards {
+		states.shards[i].services = make(map[string]*serviceState)
+	}
+	return states
+}
+
+func (st *serviceStates) shard(key string) *stateShard {
+	return &st.shards[shardIndex(key)]
+}
+
+// shardIndex returns the shard of a service key
+func shardIndex(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32() % stateShards
+}
+
+// state returns the state of a service, creating it; the caller holds the
+// shard's write lock
+func (sh *stateShard) state(key string) *serviceState {
+	state, ok := sh.services[key]
+	if !ok {
+		state = &serviceState{}
+		sh.services[key] = state
+	}
+	return state
+}
+
+// settings returns the overrides of a service
+func (st *serviceStates) settings(key string) ServiceSettings {
+	sh := st.shard(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	if state, ok := sh.services[key]; ok {
+		return state.settings
+	}
+	return ServiceSettings{}
+}
+
+func (st *serviceStates) setSettings(key string, settings ServiceSettings) {
+	sh := st.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if _, ok := sh.services[key]; !ok && reflect.ValueOf(settings).IsZero() {
+		return
+	}
+	sh.state(key).settings = settings
+}
+
+// lastScaled returns when a decision last changed the replicas of a service
+func (st *serviceStates) lastScaled(key string) (time.Time, bool) {
+	sh := st.shard(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	state, ok := sh.services[key]
+	if !ok || state.lastScaled.IsZero() {
+		return time.Time{}, false
+	}
+	return state.lastScaled, true
+}
+
+// storeDecision records the latest decision of a service and starts its
+// cooldown when the decision changes replicas
+func (st *serviceStates) storeDecision(key string, decision *ScalingDecision) {
+	sh := st.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	state := sh.state(key)
+	state.lastDecision = decision
+	if decision.CurrentReplicas != decision.RecommendedReplicas {
+		state.lastScaled = decision.Timestamp
+	}
+}
+
+// recordSequence appends a sample to the sequence of a service, keeping at
+// most length-1 samples, and returns the samples that preceded it
+func (st *serviceStates) recordSequence(key string, features FeatureVector, length int) []FeatureVector {
+	sh := st.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	state := sh.state(key)
+	previous := state.sequence
+	buffer := append(append([]FeatureVector(nil), previous...), features)
+	if len(buffer) > length-1 {
+		buffer = buffer[len(buffer)-(length-1):]
+	}
+	state.sequence = buffer
+	return previous
+}
+
+// trainingCapacity is the number of training samples each service keeps:
+// an equal share of maxTraining, and at least the minimum
+func (st *serviceStates) trainingCapacity() int {
+	services := int(st.trainingServices.Load())
+	if services < 1 {
+		services = 1
+	}
+	capacity := st.maxTraining / services
+	if capacity < minTrainingSamplesPerService {
+		capacity = minTrainingSamplesPerService
+	}
+	return capacity
+}
+
+// addTraining adds a training sample of a service and returns the number of
+// samples added since the start
+func (st *serviceStates) addTraining(sample TrainingData) int64 {
+	sh := st.shard(sample.Service)
+	sh.mu.Lock()
+	state := sh.state(sample.Service)
+	if state.training.len() == 0 {
+		st.trainingServices.Add(1)
+	}
+	state.training.add(sample, st.trainingCapacity())
+	sh.mu.Unlock()
+
+	return st.samplesAdded.Add(1)
+}
+
+// prependTraining adds samples older than those a service already holds,
+// keeping the newest that fit
+func (st *serviceStates) prependTraining(key string, samples []TrainingData) {
+	sh := st.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	state := sh.state(key)
+	if state.training.len() == 0 {
+		st.trainingServices.Add(1)
+	}
+	state.training.prepend(samples, st.trainingCapacity())
+}
+
+// trainingData returns a copy of the training samples of every service,
+// oldest first
+func (st *serviceStates) trainingData() []TrainingData {
+	var data []TrainingData
+	for i := range st.shards {
+		sh := &st.shards[i]
+		sh.mu.RLock()
+		for _, state := range sh.services {
+			data = state.training.appendTo(data)
+		}
+		sh.mu.RUnlock()
+	}
+	sort.SliceStable(data, func(i, j int) bool { return data[i].Timestamp.Before(data[j].Timestamp) })
+	return data
+}
+
+// discardTraining drops the training samples of every service from before a time
+func (st *serviceStates) discardTraining(before time.Time) {
+	for i := range st.shards {
+		sh := &st.shards[i]
+		sh.mu.Lock()
+		for _, state := range sh.services {
+			if state.training.len() > 0 && state.training.discardBefore(before) == 0 {
+				st.trainingServices.Add(-1)
+			}
+		}
+		sh.mu.Unlock()
+	}
+}
+
+// probe returns states holding only the settings and sequences of these
+func (st *serviceStates) probe() *serviceStates {
+	probe := newServiceStates(st.maxTraining)
+	for i := range st.shards {
+		sh := &st.shards[i]
+		sh.mu.RLock()
+		for key, state := range sh.services {
+			probe.shards[i].services[key] = &serviceState{
+				settings: state.settings,
+				sequence: state.sequence,
+			}
+		}
+		sh.mu.RUnlock()
+	}
+	return probe
+}
+
+// trainingRing holds the newest training samples of a service. Once full,
+// each sample overwrites the oldest one. The capacity is given on every
+// change, so the ring grows or shrinks as services join or leave the
+// training budget.
+type trainingRing struct {
+	// Samples, oldest at start; full whenever start is not 0
+	samples []TrainingData
+	start   int
+}
+
+func (r *trainingRing) len() int {
+	return len(r.samples)
+}
+
+func (r *trainingRing) add(sample TrainingData, capacity int) {
+	switch {
+	case len(r.samples) < capacity && r.start == 0:
+		r.samples = append(r.samples, sample)
+	case len(r.samples) == capacity:
+		r.samples[r.start] = sample
+		r.start = (r.start + 1) % len(r.samples)
+	default:
+		// The capacity changed since the ring filled up
+		r.samples, r.start = newest(append(r.appendTo(nil), sample), capacity), 0
+	}
+}
+
+func (r *trainingRing) prepend(samples []TrainingData, capacity int) {
+	merged := append(append([]TrainingData(nil), samples...), r.appendTo(nil)...)
+	r.samples, r.start = newest(merged, capacity), 0
+}
+
+// discardBefore drops the samples from before a time and returns how many remain
+func (r *trainingRing) discardBefore(before time.Time) int {
+	var kept []TrainingData
+	for _, sample := range r.appendTo(nil) {
+		if !sample.Timestamp.Before(before) {
+			kept = append(kept, sample)
+		}
+	}
+	r.samples, r.start = kept, 0
+	return len(kept)
+}
+
+// appendTo appends the samples to a slice, oldest first
+func (r *trainingRing) appendTo(data []TrainingData) []TrainingData {
+	data = append(data, r.samples[r.start:]...)
+	return append(data, r.samples[:r.start]...)
+}
+
+// newest returns the last samples that fit the capacity, in a slice of
+// their own so the dropped ones can be collected
+func newest(samples []TrainingData, capacity int) []TrainingData {
+	if len(samples) <= capacity {
+		return samples
+	}
+	return append([]TrainingData(nil), samples[len(samples)-capacity:]...)
+}
@@ -0,0 +1,140 @@
+package scaler
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+)
+
+// ScaleUp is a scale-up the controller applied to a service
+type ScaleUp struct {
+	Time time.Time
+	From int32
+	To   int32
+}
+
+// ServiceSummary is what a service's history over a summary period shows
+type ServiceSummary struct {
+	// Samples taken outside node maintenance, crash loops and maintenance
+	// windows, and those within the SLO response time and error rate
+	Samples  int
+	Attained int
+
+	// Scale-ups taken while the service met the SLO, after which its load
+	// grew into the added replicas within the prediction horizon while it
+	// kept meeting the SLO
+	BreachesAvoided int
+
+	// Replica-hours below the period's peak replicas, and their cost when
+	// cost estimates are enabled
+	ReplicaHoursSaved float64
+	Savings           float64
+}
+
+// SummarizeService evaluates a service's history over a summary period,
+// oldest sample first, together with the scale-ups applied to it
+func (s *AIScaler) SummarizeService(history []*metrics.MetricsData, scaleUps []ScaleUp) ServiceSummary {
+	var summary ServiceSummary
+	if len(history) == 0 {
+		return summary
+	}
+
+	var peak int32
+	for _, sample := range history {
+		if sample.CurrentReplicas > peak {
+			peak = sample.CurrentReplicas
+		}
+		if sample.Maintenance || sample.CrashLoop {
+			continue
+		}
+		if s.inMaintenanceWindow(sample.Namespace+"/"+sample.ServiceName, sample.Timestamp) {
+			continue
+		}
+		summary.Samples++
+		if s.meetsSLO(sample) {
+			summary.Attained++
+		}
+	}
+
+	// Samples stand for the time until the next one, except across gaps
+	// in the history
+	maxGap := 2 * medianGap(history)
+	for i := 0; i+1 < len(history); i++ {
+		sample := history[i]
+		gap := history[i+1].Timestamp.Sub(sample.Timestamp)
+		if gap > maxGap || sample.CurrentReplicas == 0 {
+			continue
+		}
+		hours := gap.Hours()
+		summary.ReplicaHoursSaved += float64(peak-sample.CurrentReplicas) * hours
+		if cost := estimateCost(s.config.Cost, sample, sample.CurrentReplicas, peak); cost != nil {
+			summary.Savings += cost.CostPerHour * hours
+		}
+	}
+
+	for _, scaleUp := range scaleUps {
+		if s.avoidedBreach(history, scaleUp) {
+			summary.BreachesAvoided++
+		}
+	}
+	return summary
+}
+
+// avoidedBreach reports whether a scale-up was taken while the service met
+// the SLO, and its request rate then grew by at least the share of replicas
+// added within the prediction horizon without the SLO being missed: the
+// previous replicas would have had to carry more load than before.
+func (s *AIScaler) avoidedBreach(history []*metrics.MetricsData, scaleUp ScaleUp) bool {
+	if scaleUp.From <= 0 || scaleUp.To <= scaleUp.From {
+		return false
+	}
+
+	var before *metrics.MetricsData
+	var peakRate float64
+	after := 0
+	horizon := scaleUp.Time.Add(s.config.Prediction.PredictionHorizon)
+	for _, sample := range history {
+		switch {
+		case !sample.Timestamp.After(scaleUp.Time):
+			before = sample
+		case !sample.Timestamp.After(horizon):
+			if !s.meetsSLO(sample) {
+				return false
+			}
+			peakRate = math.Max(peakRate, sample.RequestRate)
+			after++
+		}
+	}
+	if before == nil || after == 0 || before.RequestRate <= 0 || !s.meetsSLO(before) {
+		return false
+	}
+	return peakRate >= before.RequestRate*float64(scaleUp.To)/float64(scaleUp.From)
+}
+
+// meetsSLO reports whether a sample is within the summary SLO
+func (s *AIScaler) meetsSLO(sample *metrics.MetricsData) bool {
+	cfg := s.config.SummaryReport
+	return sample.ResponseTime <= cfg.SLOResponseTime && sample.ErrorRate <= cfg.SLOErrorRate
+}
+
+// medianGap returns the median time between consecutive samples
+func medianGap(history []*metrics.MetricsData) time.Duration {
+	if len(history) < 2 {
+		return 0
+	}
+	gaps := make([]time.Duration, 0, len(history)-1)
+	for i := 1; i < len(history); i++ {
+		gaps = append(gaps, history[i].Timestamp.Sub(history[i-1].Timestamp))
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	return gaps[len(gaps)/2]
+}
+
+// ModelVersion returns the label of the active model version, as used for
+// its prediction error
+func (s *AIScaler) ModelVersion() string {
+	_, version := s.currentModel()
+	return versionLabel(version)
+}
@@ -0,0 +1,52 @@
+package scaler
+
+import (
+	"math"
+)
+
+// Target modes: how a model's output becomes a replica count
+const (
+	// TargetModeFactor multiplies the current replicas by the predicted scale factor
+	TargetModeFactor = "factor"
+
+	// TargetModeAbsolute takes the replica count, or the capacity in requests
+	// per second, that a TargetModel predicts the service needs
+	TargetModeAbsolute = "absolute"
+)
+
+// Target is what a service needs according to a TargetModel: a replica
+// count, or a capacity in requests per second that the service's requests
+// per replica turn into one. Replicas takes precedence when both are set.
+type Target struct {
+	Replicas   float64
+	Capacity   float64
+	Confidence float64
+}
+
+// TargetModel is implemented by models that predict what a service needs in
+// absolute terms rather than as a multiple of its current replicas. Absolute
+// targets break the feedback loop in which the current replica count, which
+// the previous recommendation set, scales the next recommendation.
+type TargetModel interface {
+	PredictTarget(features FeatureVector) (Target, error)
+}
+
+// targetReplicas converts a target into replicas, and reports false when
+// the target can't be used: a capacity without requests per replica, or
+// nothing predicted at all
+func targetReplicas(target Target, requestsPerReplica float64) (float64, bool) {
+	switch {
+	case target.Replicas > 0:
+		return target.Replicas, true
+	case target.Capacity > 0 && requestsPerReplica > 0:
+		return target.Capacity / requestsPerReplica, true
+	default:
+		return 0, false
+	}
+}
+
+// targetFactor expresses a target replica count as a factor of the current
+// replicas, so policies, reasons and the dead band treat both modes alike
+func targetFactor(replicas float64, currentReplicas int32) float64 {
+	return math.Ceil(replicas) / math.Max(float64(currentReplicas), 1)
+}
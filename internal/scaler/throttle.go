@@ -0,0 +1,46 @@
+package scaler
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// SetThrottled switches the scaler to its reduced mode while the controller
+// nears its own resource limits, for reason, or back with an empty reason.
+// In the reduced mode retrains are postponed, since training holds a copy
+// of the model and every training sample, and shadow models make no
+// predictions. A retrain postponed meanwhile starts once it ends.
+func (s *AIScaler) SetThrottled(reason string) {
+	s.retrainMu.Lock()
+	was := s.throttled
+	s.throttled = reason
+	postponed := s.retrainPostponed && reason == ""
+	if postponed {
+		s.retrainPostponed = false
+	}
+	s.retrainMu.Unlock()
+
+	if (was == "") == (reason == "") {
+		return
+	}
+	if reason != "" {
+		logrus.WithField("reason", reason).Warn("Model retraining and shadow predictions paused")
+		return
+	}
+	logrus.Info("Model retraining and shadow predictions resumed")
+	if postponed {
+		s.startRetrain()
+	}
+}
+
+// Throttled returns why the scaler is in its reduced mode, if it is
+func (s *AIScaler) Throttled() (string, bool) {
+	s.retrainMu.Lock()
+	defer s.retrainMu.Unlock()
+
+	return s.throttled, s.throttled != ""
+}
+
+func (s *AIScaler) isThrottled() bool {
+	_, throttled := s.Throttled()
+	return throttled
+}
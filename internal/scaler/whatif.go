@@ -0,0 +1,58 @@
+package scaler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+)
+
+// WhatIf returns the decision the current models, policies and service
+// settings would make for a hypothetical sample, without changing any
+// state: no cooldown starts, no prediction is tracked for scoring or
+// training, drift and change-point detection don't see the sample, no
+// action budget is spent and clamped recommendations aren't audited. The sample is judged on its own, as if the
+// service hadn't scaled recently, so cooldowns, action budgets and the HPA
+// behavior's stabilization don't apply. A sample without a timestamp is
+// taken as current.
+func (s *AIScaler) WhatIf(sample *metrics.MetricsData) (*ScalingDecision, error) {
+	if sample == nil {
+		return nil, fmt.Errorf("metrics data is nil")
+	}
+	probe := *sample
+	if probe.Timestamp.IsZero() {
+		probe.Timestamp = time.Now()
+	}
+	return s.probe().MakeScalingDecision(&probe)
+}
+
+// probe returns a scaler sharing this one's configuration, models, policies,
+// derived features and service settings, with empty decision state
+func (s *AIScaler) probe() *AIScaler {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	probe := &AIScaler{
+		config:        s.config,
+		model:         s.model,
+		modelVersion:  s.modelVersion,
+		canaryModel:   s.canaryModel,
+		canaryVersion: s.canaryVersion,
+		policies:      s.policies,
+		derived:       s.derived,
+		holidays:      s.holidays,
+		disabled:      s.disabled,
+		behavior:      newBehaviorHistory(),
+		contention:    newContentionFloors(),
+		oom:           newOOMFloors(),
+		states:        s.states.probe(),
+		outcomes:      NewOutcomeTracker(s.config.AIModel.TrainingQuality.IncidentErrorRate),
+	}
+	if s.config.TargetMode == TargetModeConcurrency {
+		probe.concurrency = newConcurrencyTracker(s.config.Concurrency)
+	}
+	if s.config.SLOEscalation.Enabled {
+		probe.sloFloors = newSLOFloors()
+	}
+	return probe
+}
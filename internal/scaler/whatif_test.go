@@ -0,0 +1,53 @@
+package scaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+func TestWhatIfLeavesClampingAlone(t *testing.T) {
+	s := NewAIScaler(config.DefaultConfig().Scaling)
+	s.SetServiceSettings("shop/web", ServiceSettings{MinReplicas: 1, MaxReplicas: 2})
+
+	// Saturated at its max replicas, so every recommendation is clamped
+	sample := func() *metrics.MetricsData {
+		return &metrics.MetricsData{
+			ServiceName:       "web",
+			Namespace:         "shop",
+			Timestamp:         time.Now(),
+			CPUUtilization:    95,
+			MemoryUtilization: 90,
+			RequestRate:       1000,
+			CurrentReplicas:   2,
+		}
+	}
+	clampedAtMax := recommendationsClamped.WithLabelValues("web", "shop", ClampMax)
+	before := testutil.ToFloat64(clampedAtMax)
+
+	decision, err := s.WhatIf(sample())
+	if err != nil {
+		t.Fatalf("WhatIf failed: %v", err)
+	}
+	if decision.RecommendedReplicas != 2 {
+		t.Errorf("what-if recommended %d replicas, want the max of 2", decision.RecommendedReplicas)
+	}
+	if got := testutil.ToFloat64(clampedAtMax); got != before {
+		t.Errorf("what-if counted %v clamped recommendations", got-before)
+	}
+	if counts := s.Clamping(time.Time{}); len(counts) != 0 {
+		t.Errorf("what-if entered the clamping audit: %+v", counts)
+	}
+
+	// A live decision on the same sample is audited
+	if _, err := s.MakeScalingDecision(sample()); err != nil {
+		t.Fatalf("MakeScalingDecision failed: %v", err)
+	}
+	if got := testutil.ToFloat64(clampedAtMax); got != before+1 {
+		t.Errorf("live decision counted %v clamped recommendations, want 1", got-before)
+	}
+}
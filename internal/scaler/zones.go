@@ -0,0 +1,21 @@
+package scaler
+
+import (
+	"github.com/hydraai/hydra-route/internal/metrics"
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Zone spread actions
+const (
+	ZoneSpreadFloor = "floor"
+	ZoneSpreadWarn  = "warn"
+)
+
+// zoneSpreadFloor returns the replicas that leave the configured minimum in
+// every zone a service spreads over, or 0 when it isn't spread
+func zoneSpreadFloor(cfg config.ZoneSpreadConfig, metricsData *metrics.MetricsData) int32 {
+	if cfg.Action != ZoneSpreadFloor && cfg.Action != ZoneSpreadWarn {
+		return 0
+	}
+	return int32(metricsData.Zones) * cfg.MinReplicasPerZone
+}
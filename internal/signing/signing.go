@@ -0,0 +1,191 @@
+// Package signing signs the decision records of the controller, so that
+// consumers in clusters with several controllers can verify that a scaling
+// action came from HydraRoute. Signatures are base64-encoded ASN.1 ECDSA
+// (SHA-256) or Ed25519 signatures over the record's exact bytes, the format
+// cosign verify-blob checks against a PEM public key.
+package signing
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// Issuer identifies HydraRoute in every record
+const Issuer = "hydra-route"
+
+var signingFailures = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "hydra_route_signing_failures_total",
+	Help: "Decision records that could not be signed and were written unsigned.",
+})
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(signingFailures)
+}
+
+// Record is the signed statement of a scaling decision about one object: a
+// workload scaled to Replicas, or the ScalingRecommendation of a service.
+// Naming the object keeps a record from being replayed onto another one.
+type Record struct {
+	Issuer       string    `json:"issuer"`
+	KeyID        string    `json:"keyId,omitempty"`
+	Kind         string    `json:"kind"`
+	Namespace    string    `json:"namespace"`
+	Name         string    `json:"name"`
+	Service      string    `json:"service"`
+	FromReplicas int32     `json:"fromReplicas"`
+	Replicas     int32     `json:"replicas"`
+	Confidence   string    `json:"confidence,omitempty"`
+	ModelVersion string    `json:"modelVersion,omitempty"`
+	DecidedAt    time.Time `json:"decidedAt"`
+}
+
+// Signer signs payloads with one key
+type Signer interface {
+	// Sign returns the base64-encoded signature of payload
+	Sign(ctx context.Context, payload []byte) (string, error)
+
+	// KeyID returns the configured identifier of the key, possibly empty
+	KeyID() string
+}
+
+// New returns the signer of cfg, or nil when signing is disabled
+func New(cfg config.SigningConfig) (Signer, error) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "file":
+		return newFileSigner(cfg.KeyFile, cfg.KeyID)
+	case "vault":
+		return newVaultSigner(cfg.Vault, cfg.KeyID)
+	default:
+		return nil, fmt.Errorf("unknown signing backend %q", cfg.Backend)
+	}
+}
+
+// SignRecord encodes record, stamped with the issuer and key ID, and signs
+// it. The encoded record is returned with its signature, since the
+// signature only holds for those exact bytes, and also when signing fails.
+func SignRecord(ctx context.Context, signer Signer, record Record) (string, string, error) {
+	record.Issuer = Issuer
+	record.KeyID = signer.KeyID()
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return "", "", err
+	}
+	signature, err := signer.Sign(ctx, payload)
+	if err != nil {
+		signingFailures.Inc()
+		return string(payload), "", err
+	}
+	return string(payload), signature, nil
+}
+
+// fileSigner signs with a private key read from a PEM file
+type fileSigner struct {
+	key   crypto.Signer
+	keyID string
+}
+
+func newFileSigner(path, keyID string) (*fileSigner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block in signing key %s", path)
+	}
+	if block.Type == "ENCRYPTED PRIVATE KEY" || block.Type == "ENCRYPTED SIGSTORE PRIVATE KEY" {
+		return nil, fmt.Errorf("signing key %s is encrypted; export it unencrypted as PKCS#8", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		if key.Curve.Params().Name != "P-256" {
+			return nil, fmt.Errorf("signing key uses %s, expected P-256", key.Curve.Params().Name)
+		}
+		return &fileSigner{key: key, keyID: keyID}, nil
+	case ed25519.PrivateKey:
+		return &fileSigner{key: key, keyID: keyID}, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T, expected ECDSA P-256 or Ed25519", key)
+	}
+}
+
+func (s *fileSigner) Sign(_ context.Context, payload []byte) (string, error) {
+	var signature []byte
+	var err error
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		signature, err = s.key.Sign(nil, payload, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(payload)
+		signature, err = s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+func (s *fileSigner) KeyID() string {
+	return s.keyID
+}
+
+// Verify checks a base64-encoded signature of payload against a PEM public
+// key, ECDSA P-256 or Ed25519
+func Verify(publicKeyPEM, payload []byte, signature string) error {
+	block, _ := pem.Decode(publicKeyPEM)
+	if block == nil {
+		return errors.New("no PEM block in public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %w", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		if !ecdsa.VerifyASN1(key, digest[:], raw) {
+			return errors.New("signature does not match")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, raw) {
+			return errors.New("signature does not match")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	return nil
+}
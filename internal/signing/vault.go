@@ -0,0 +1,92 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hydraai/hydra-route/pkg/config"
+)
+
+// vaultSigner signs with a HashiCorp Vault Transit key, so the private key
+// never leaves Vault
+type vaultSigner struct {
+	cfg      config.VaultSigningConfig
+	keyID    string
+	endpoint string
+	client   *http.Client
+}
+
+func newVaultSigner(cfg config.VaultSigningConfig, keyID string) (*vaultSigner, error) {
+	if os.Getenv(cfg.TokenEnv) == "" {
+		return nil, fmt.Errorf("no Vault token in $%s", cfg.TokenEnv)
+	}
+	return &vaultSigner{
+		cfg:      cfg,
+		keyID:    keyID,
+		endpoint: fmt.Sprintf("%s/v1/%s/sign/%s", strings.TrimSuffix(cfg.Address, "/"), strings.Trim(cfg.Mount, "/"), cfg.Key),
+		client:   &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// vaultSignResponse is the part of a Transit sign response we read
+type vaultSignResponse struct {
+	Data struct {
+		Signature string `json:"signature"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// Sign asks Vault for an SHA-256 signature of payload. ECDSA keys sign the
+// digest in ASN.1, Vault's default marshaling. The token is read on every
+// call, so a token renewed by an agent sidecar is picked up.
+func (s *vaultSigner) Sign(ctx context.Context, payload []byte) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"input":          base64.StdEncoding.EncodeToString(payload),
+		"hash_algorithm": "sha2-256",
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", os.Getenv(s.cfg.TokenEnv))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", err
+	}
+	var answer vaultSignResponse
+	if err := json.Unmarshal(data, &answer); err != nil {
+		return "", fmt.Errorf("invalid Vault response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Vault returned status %d: %s", resp.StatusCode, strings.Join(answer.Errors, "; "))
+	}
+
+	// Signatures come as vault:v<key version>:<base64>
+	parts := strings.SplitN(answer.Data.Signature, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" {
+		return "", fmt.Errorf("unexpected Vault signature %q", answer.Data.Signature)
+	}
+	return parts[2], nil
+}
+
+func (s *vaultSigner) KeyID() string {
+	return s.keyID
+}
@@ -0,0 +1,235 @@
+// Package client is a Go client for the HydraRoute admin API, following the
+// OpenAPI document served at /api/v1/openapi.yaml. It has no dependencies on
+// the controller's internal packages.
+//
+//	c := client.New("http://hydra-route-admin.hydra-route-system:8082")
+//	decision, err := c.WhatIf(ctx, &client.MetricsData{
+//		ServiceName:     "my-app",
+//		Namespace:       "default",
+//		CurrentReplicas: 3,
+//		RequestRate:     500,
+//	})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultTimeout bounds requests made with the default HTTP client
+const defaultTimeout = 30 * time.Second
+
+// Client calls the admin API of a HydraRoute controller
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client requests are made with, e.g. for TLS
+// or authentication through a proxy
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates every request with a tenant's API key, sent as a
+// bearer token
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// New creates a client for the admin API at baseURL, e.g. http://localhost:8082
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is an error response of the admin API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("admin API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 response: an unknown model version,
+// or a report that hasn't been produced yet
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// ListModels returns the registered model versions, without their parameters
+func (c *Client) ListModels(ctx context.Context) ([]ModelVersion, error) {
+	var versions []ModelVersion
+	err := c.do(ctx, http.MethodGet, "/api/v1/models", nil, &versions)
+	return versions, err
+}
+
+// RegisterModel registers an artifact, as written by hydra-train, as a new
+// version in the shadow stage. The artifact is sent unchanged.
+func (c *Client) RegisterModel(ctx context.Context, req RegisterRequest) (*ModelVersion, error) {
+	var mv ModelVersion
+	if err := c.do(ctx, http.MethodPost, "/api/v1/models", req, &mv); err != nil {
+		return nil, err
+	}
+	return &mv, nil
+}
+
+// GetModel returns a model version. Only the descriptive fields of its
+// artifact are decoded; use GetModelArtifact for the parameters.
+func (c *Client) GetModel(ctx context.Context, version string) (*ModelVersion, error) {
+	var mv ModelVersion
+	if err := c.do(ctx, http.MethodGet, "/api/v1/models/"+url.PathEscape(version), nil, &mv); err != nil {
+		return nil, err
+	}
+	return &mv, nil
+}
+
+// GetModelArtifact returns the complete artifact of a model version, as it
+// can be loaded by the controller or registered elsewhere
+func (c *Client) GetModelArtifact(ctx context.Context, version string) (json.RawMessage, error) {
+	var mv struct {
+		Artifact json.RawMessage `json:"artifact"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/models/"+url.PathEscape(version), nil, &mv); err != nil {
+		return nil, err
+	}
+	return mv.Artifact, nil
+}
+
+// PromoteModel moves a model version to another stage
+func (c *Client) PromoteModel(ctx context.Context, version string, req PromoteRequest) (*ModelVersion, error) {
+	var mv ModelVersion
+	if err := c.do(ctx, http.MethodPost, "/api/v1/models/"+url.PathEscape(version)+"/promote", req, &mv); err != nil {
+		return nil, err
+	}
+	return &mv, nil
+}
+
+// RollbackModel reactivates the previously active model version
+func (c *Client) RollbackModel(ctx context.Context) (*ModelVersion, error) {
+	var mv ModelVersion
+	if err := c.do(ctx, http.MethodPost, "/api/v1/models/rollback", nil, &mv); err != nil {
+		return nil, err
+	}
+	return &mv, nil
+}
+
+// GetDrift returns the latest feature drift evaluation
+func (c *Client) GetDrift(ctx context.Context) (*DriftStatus, error) {
+	var status DriftStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/drift", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetRightSizing returns the latest right-sizing report
+func (c *Client) GetRightSizing(ctx context.Context) (*RightSizingReport, error) {
+	var report RightSizingReport
+	if err := c.do(ctx, http.MethodGet, "/api/v1/rightsizing", nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetExperiment returns the latest scaling experiment report
+func (c *Client) GetExperiment(ctx context.Context) (*ExperimentReport, error) {
+	var report ExperimentReport
+	if err := c.do(ctx, http.MethodGet, "/api/v1/experiment", nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetImportance returns the feature importances of the active model, most
+// important first
+func (c *Client) GetImportance(ctx context.Context) (*ImportanceReport, error) {
+	var report ImportanceReport
+	if err := c.do(ctx, http.MethodGet, "/api/v1/importance", nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// WhatIf returns the decision the scaler would make for a metrics sample,
+// without acting on it
+func (c *Client) WhatIf(ctx context.Context, sample *MetricsData) (*ScalingDecision, error) {
+	var decision ScalingDecision
+	if err := c.do(ctx, http.MethodPost, "/api/v1/whatif", sample, &decision); err != nil {
+		return nil, err
+	}
+	return &decision, nil
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var errBody struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&errBody) == nil && errBody.Error != "" {
+			apiErr.Message = errBody.Error
+		} else {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Stage is the rollout stage of a model version
+type Stage string
+
+const (
+	StageShadow  Stage = "shadow"
+	StageCanary  Stage = "canary"
+	StageActive  Stage = "active"
+	StageRetired Stage = "retired"
+)
+
+// RegisterRequest is the body of RegisterModel
+type RegisterRequest struct {
+	Version     string          `json:"version"`
+	Description string          `json:"description,omitempty"`
+	Artifact    json.RawMessage `json:"artifact"`
+}
+
+// PromoteRequest is the body of PromoteModel. Force allows skipping stages
+// or moving a version back.
+type PromoteRequest struct {
+	Stage Stage `json:"stage"`
+	Force bool  `json:"force,omitempty"`
+}
+
+// ModelVersion is a registered model version and its rollout state
+type ModelVersion struct {
+	Version     string         `json:"version"`
+	Stage       Stage          `json:"stage"`
+	Description string         `json:"description,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	ActivatedAt time.Time      `json:"activated_at,omitempty"`
+	Artifact    *ModelArtifact `json:"artifact,omitempty"`
+}
+
+// ModelArtifact holds the descriptive fields of a trained model
+type ModelArtifact struct {
+	FormatVersion       int                `json:"format_version"`
+	ModelType           string             `json:"model_type"`
+	CreatedAt           time.Time          `json:"created_at"`
+	TrainingSamples     int                `json:"training_samples"`
+	TrainingWindowStart time.Time          `json:"training_window_start,omitempty"`
+	TrainingWindowEnd   time.Time          `json:"training_window_end,omitempty"`
+	Hyperparameters     map[string]float64 `json:"hyperparameters,omitempty"`
+	Evaluation          *EvaluationResult  `json:"evaluation,omitempty"`
+	DerivedFeatures     []string           `json:"derived_features,omitempty"`
+	DisabledFeatures    []string           `json:"disabled_features,omitempty"`
+	FeatureWindows      []string           `json:"feature_windows,omitempty"`
+	FeatureImportance   *ImportanceReport  `json:"feature_importance,omitempty"`
+}
+
+// EvaluationResult scores a model on holdout data
+type EvaluationResult struct {
+	Samples           int     `json:"samples"`
+	MSE               float64 `json:"mse"`
+	MAE               float64 `json:"mae"`
+	DirectionAccuracy float64 `json:"direction_accuracy"`
+	Coverage          float64 `json:"coverage"`
+
+	// Scores per scale event type (scale_up, scale_down, hold)
+	ByEvent map[string]EvaluationResult `json:"by_event,omitempty"`
+}
+
+// DriftStatus is the latest feature drift evaluation
+type DriftStatus struct {
+	Drifted      bool                    `json:"drifted"`
+	Method       string                  `json:"method"`
+	Threshold    float64                 `json:"threshold"`
+	Score        float64                 `json:"score"`
+	Feature      string                  `json:"feature,omitempty"`
+	Samples      int                     `json:"samples"`
+	CheckedAt    time.Time               `json:"checked_at"`
+	Features     map[string]FeatureDrift `json:"features,omitempty"`
+	ActionTaken  string                  `json:"action_taken,omitempty"`
+	HasReference bool                    `json:"has_reference"`
+}
+
+// FeatureDrift holds the drift scores of one feature
+type FeatureDrift struct {
+	PSI float64 `json:"psi"`
+	KS  float64 `json:"ks"`
+}
+
+// RightSizingReport lists the services whose provisioning doesn't match their peak
+type RightSizingReport struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	Window      string               `json:"window"`
+	Analyzed    int                  `json:"analyzed"`
+	Services    []ServiceRightSizing `json:"services"`
+}
+
+// ServiceRightSizing holds the right-sizing findings of one service
+type ServiceRightSizing struct {
+	Service                   string   `json:"service"`
+	Samples                   int      `json:"samples"`
+	Idle                      bool     `json:"idle,omitempty"`
+	MinReplicas               int32    `json:"min_replicas"`
+	PeakReplicas              int32    `json:"peak_replicas"`
+	RecommendedMinReplicas    int32    `json:"recommended_min_replicas,omitempty"`
+	PeakCPUUtilization        float64  `json:"peak_cpu_utilization"`
+	CPURequests               float64  `json:"cpu_requests,omitempty"`
+	RecommendedCPURequests    float64  `json:"recommended_cpu_requests,omitempty"`
+	PeakMemoryUtilization     float64  `json:"peak_memory_utilization"`
+	MemoryRequests            float64  `json:"memory_requests,omitempty"`
+	RecommendedMemoryRequests float64  `json:"recommended_memory_requests,omitempty"`
+	Findings                  []string `json:"findings"`
+}
+
+// ExperimentReport compares the cohorts of a scaling experiment
+type ExperimentReport struct {
+	Name               string         `json:"name"`
+	GeneratedAt        time.Time      `json:"generated_at"`
+	Start              time.Time      `json:"start"`
+	End                time.Time      `json:"end"`
+	Completed          bool           `json:"completed"`
+	Cohorts            []CohortResult `json:"cohorts"`
+	SLOAttainmentDelta float64        `json:"slo_attainment_delta"`
+	ReplicasDelta      float64        `json:"replicas_delta"`
+	CostPerHourDelta   float64        `json:"cost_per_hour_delta,omitempty"`
+}
+
+// CohortResult is the outcome of one experiment cohort
+type CohortResult struct {
+	Cohort        string  `json:"cohort"`
+	Services      int     `json:"services"`
+	Samples       int     `json:"samples"`
+	SLOAttainment float64 `json:"slo_attainment"`
+	MeanReplicas  float64 `json:"mean_replicas"`
+	CostPerHour   float64 `json:"cost_per_hour,omitempty"`
+}
+
+// ImportanceReport holds the feature importances of a model, most important first
+type ImportanceReport struct {
+	ModelType  string              `json:"model_type"`
+	ComputedAt time.Time           `json:"computed_at"`
+	Samples    int                 `json:"samples"`
+	BaseMSE    float64             `json:"base_mse"`
+	Features   []FeatureImportance `json:"features"`
+}
+
+// FeatureImportance is the importance of one feature. Coefficient is only
+// set for the linear and quantile models.
+type FeatureImportance struct {
+	Feature     string   `json:"feature"`
+	Permutation float64  `json:"permutation"`
+	Coefficient *float64 `json:"coefficient,omitempty"`
+}
+
+// ScalingDecision is a scaling decision for a service
+type ScalingDecision struct {
+	ServiceName         string             `json:"service_name"`
+	Namespace           string             `json:"namespace"`
+	Timestamp           time.Time          `json:"timestamp"`
+	CurrentReplicas     int32              `json:"current_replicas"`
+	RecommendedReplicas int32              `json:"recommended_replicas"`
+	Confidence          float64            `json:"confidence"`
+	Reasoning           string             `json:"reasoning"`
+	Reasons             []Reason           `json:"reasons"`
+	ModelVersion        string             `json:"model_version,omitempty"`
+	Metrics             *MetricsData       `json:"metrics"`
+	ChangePoint         *ChangePoint       `json:"change_point,omitempty"`
+	SLOEscalation       *SLOEscalation     `json:"slo_escalation,omitempty"`
+	CapacityLimit       *CapacityLimit     `json:"capacity_limit,omitempty"`
+	ScaleFactor         float64            `json:"scale_factor,omitempty"`
+	ScaleFactorBounds   *ScaleFactorBounds `json:"scale_factor_bounds,omitempty"`
+	Cost                *CostEstimate      `json:"cost,omitempty"`
+	Cohort              string             `json:"cohort,omitempty"`
+}
+
+// Reason is a machine-readable reason for a decision
+type Reason struct {
+	Code       string            `json:"code"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// ChangePoint is a regime shift detected on a sample
+type ChangePoint struct {
+	Signal     string    `json:"signal"`
+	Direction  string    `json:"direction"`
+	Baseline   float64   `json:"baseline"`
+	Current    float64   `json:"current"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// SLOEscalation is a step of the replica floor raised for a service
+// breaching its SLO
+type SLOEscalation struct {
+	Breaches     int       `json:"breaches"`
+	FromReplicas int32     `json:"from_replicas"`
+	Replicas     int32     `json:"replicas"`
+	Capped       bool      `json:"capped"`
+	EscalatedAt  time.Time `json:"escalated_at"`
+}
+
+// ScaleFactorBounds is the envelope a predicted scale factor was mapped onto
+type ScaleFactorBounds struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// CapacityLimit reports a service whose recommendations were clamped at its
+// max replicas on consecutive evaluations
+type CapacityLimit struct {
+	Evaluations int   `json:"evaluations"`
+	MaxReplicas int32 `json:"max_replicas"`
+	Unclamped   int32 `json:"unclamped_replicas"`
+}
+
+// CostEstimate is the estimated change in hourly cost and emissions of a decision
+type CostEstimate struct {
+	NodeType      string  `json:"node_type,omitempty"`
+	CostPerHour   float64 `json:"cost_per_hour"`
+	CarbonPerHour float64 `json:"carbon_grams_per_hour"`
+}
+
+// MetricsData is a metrics sample of a service. Unset fields are zero, and a
+// sample without a timestamp counts as current.
+type MetricsData struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ServiceName string    `json:"service_name"`
+	Namespace   string    `json:"namespace"`
+
+	CPUUtilization         float64 `json:"cpu_utilization"`
+	MemoryUtilization      float64 `json:"memory_utilization"`
+	MemoryLimitUtilization float64 `json:"memory_limit_utilization,omitempty"`
+	OOMKills               int     `json:"oom_kills,omitempty"`
+
+	RequestRate       float64 `json:"request_rate"`
+	ResponseTime      float64 `json:"response_time"`
+	ErrorRate         float64 `json:"error_rate"`
+	Concurrency       float64 `json:"concurrency,omitempty"`
+	TokenRate         float64 `json:"token_rate,omitempty"`
+	TimeToFirstToken  float64 `json:"time_to_first_token,omitempty"`
+	LatencyBurnRate5m float64 `json:"latency_burn_rate_5m,omitempty"`
+	LatencyBurnRate1h float64 `json:"latency_burn_rate_1h,omitempty"`
+
+	NetworkBandwidth float64 `json:"network_bandwidth"`
+	IOBandwidth      float64 `json:"io_bandwidth"`
+
+	CurrentReplicas int32  `json:"current_replicas"`
+	DesiredReplicas int32  `json:"desired_replicas"`
+	Revision        string `json:"revision,omitempty"`
+	Image           string `json:"image,omitempty"`
+
+	CPURequests    float64 `json:"cpu_requests,omitempty"`
+	MemoryRequests float64 `json:"memory_requests,omitempty"`
+	NodeType       string  `json:"node_type,omitempty"`
+
+	Maintenance            bool    `json:"maintenance,omitempty"`
+	MaintenancePods        int     `json:"maintenance_pods,omitempty"`
+	CrashLoop              bool    `json:"crash_loop,omitempty"`
+	CrashLoopPods          int     `json:"crash_loop_pods,omitempty"`
+	CPUThrottling          float64 `json:"cpu_throttling,omitempty"`
+	MeasuredCPUUtilization float64 `json:"measured_cpu_utilization,omitempty"`
+	BatchContention        bool    `json:"batch_contention,omitempty"`
+	BatchContentionPods    int     `json:"batch_contention_pods,omitempty"`
+	Zones                  int     `json:"zones,omitempty"`
+	PreemptedPods          int     `json:"preempted_pods,omitempty"`
+	UnschedulablePods      int     `json:"unschedulable_pods,omitempty"`
+	RetryRate              float64 `json:"retry_rate,omitempty"`
+	RetryAmplification     float64 `json:"retry_amplification,omitempty"`
+	MeasuredRequestRate    float64 `json:"measured_request_rate,omitempty"`
+	EjectedEndpoints       int     `json:"ejected_endpoints,omitempty"`
+	NodeTemperature        float64 `json:"node_temperature,omitempty"`
+	NodePower              float64 `json:"node_power,omitempty"`
+	ThermalConstrainedPods int     `json:"thermal_constrained_pods,omitempty"`
+	WindowsPods            int     `json:"windows_pods,omitempty"`
+
+	IngressClass     string            `json:"ingress_class"`
+	LoadBalancerIP   string            `json:"load_balancer_ip"`
+	StalenessSeconds float64           `json:"staleness_seconds,omitempty"`
+	Imputed          []string          `json:"imputed,omitempty"`
+	Windows          []WindowAggregate `json:"windows,omitempty"`
+}
+
+// WindowAggregate holds the aggregates of a sample over one collector window
+type WindowAggregate struct {
+	Window          time.Duration `json:"window"`
+	CPUMean         float64       `json:"cpu_mean"`
+	CPUMax          float64       `json:"cpu_max"`
+	MemoryMean      float64       `json:"memory_mean"`
+	MemoryMax       float64       `json:"memory_max"`
+	RequestRateMean float64       `json:"request_rate_mean"`
+	RequestRateMax  float64       `json:"request_rate_max"`
+}
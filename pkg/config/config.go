@@ -3,9 +3,17 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v2"
+
+	"github.com/hydraai/hydra-route/pkg/expr"
 )
 
 // Config represents the main configuration for HydraRoute
@@ -26,6 +34,25 @@ type MetricsConfig struct {
 	// Prometheus endpoint for additional metrics
 	PrometheusURL string `yaml:"prometheus_url"`
 
+	// Query settings for Prometheus-compatible backends
+	PrometheusQuery PrometheusQueryConfig `yaml:"prometheus_query"`
+
+	// HTTP settings per metrics source, keyed by source name (nginx, prometheus)
+	Sources map[string]SourceConfig `yaml:"sources"`
+
+	// How request metrics reported by several sources are combined, keyed by
+	// field (request_rate, response_time, error_rate)
+	SourceMerge map[string]MergePolicyConfig `yaml:"source_merge"`
+
+	// Backfilling of history from Prometheus for newly seen services
+	Bootstrap BootstrapConfig `yaml:"bootstrap"`
+
+	// Mapping of source series labels onto Kubernetes services and namespaces
+	LabelMapping LabelMappingConfig `yaml:"label_mapping"`
+
+	// Synthetic traffic replacing the request sources, for local development
+	Simulated SimulatedConfig `yaml:"simulated"`
+
 	// Enable custom metrics collection
 	EnableCustomMetrics bool `yaml:"enable_custom_metrics"`
 
@@ -37,6 +64,393 @@ type MetricsConfig struct {
 
 	// Bandwidth monitoring settings
 	BandwidthMonitoring BandwidthConfig `yaml:"bandwidth_monitoring"`
+
+	// Measurement of pods on Windows nodes
+	WindowsNodes WindowsNodesConfig `yaml:"windows_nodes"`
+
+	// How the pods serving a service are found: endpointslices, selector
+	BackendDiscovery string `yaml:"backend_discovery"`
+
+	// Backfilling of metrics from sources that failed transiently
+	Imputation ImputationConfig `yaml:"imputation"`
+
+	// Backoff from failing nginx and Prometheus endpoints
+	SourceBackoff SourceBackoffConfig `yaml:"source_backoff"`
+
+	// Trailing windows over which mean and max of key metrics are aggregated
+	AggregationWindows []time.Duration `yaml:"aggregation_windows"`
+
+	// Handling of samples taken during Kubernetes node maintenance
+	MaintenanceBlackout MaintenanceBlackoutConfig `yaml:"maintenance_blackout"`
+
+	// Detection of batch jobs running on the nodes of managed services
+	BatchContention BatchContentionDetectionConfig `yaml:"batch_contention"`
+
+	// Counting of the zones services with topology spread constraints spread over
+	ZoneSpread ZoneSpreadDetectionConfig `yaml:"zone_spread"`
+
+	// Detection of pods preempted by the scheduler or left unschedulable
+	Preemption PreemptionDetectionConfig `yaml:"preemption"`
+
+	// Reading of endpoints ejected by service mesh outlier detection
+	MeshEjection MeshEjectionDetectionConfig `yaml:"mesh_ejection"`
+
+	// Reading of node temperature and power draw
+	Thermal ThermalConfig `yaml:"thermal"`
+
+	// Correction of CPU utilization capped by CFS throttling
+	CPUThrottling CPUThrottlingConfig `yaml:"cpu_throttling"`
+
+	// Discounting of request rates inflated by retries
+	RetryStorm RetryStormConfig `yaml:"retry_storm"`
+
+	// Detection of crash-looping pods behind each service
+	CrashLoop CrashLoopDetectionConfig `yaml:"crash_loop"`
+
+	// Node label recorded as the node type of each sample, for the cost
+	// model; empty skips the lookup
+	NodeTypeLabel string `yaml:"node_type_label"`
+
+	// Window over which OOM kills of a service's containers are counted; 0 disables
+	OOMWindow time.Duration `yaml:"oom_window"`
+
+	// Application gauge of in-flight requests, summed per service by the
+	// recording rules; empty reads in-flight requests from nginx only
+	ConcurrencyMetric string `yaml:"concurrency_metric"`
+
+	// Request metrics exported by the applications, recorded for services
+	// the nginx ingress controller has no series for, such as services no
+	// ingress routes to
+	ApplicationRequests ApplicationRequestsConfig `yaml:"application_requests"`
+
+	// Token throughput and time to first token of LLM endpoints, from Prometheus
+	LLM LLMMetricsConfig `yaml:"llm"`
+
+	// Burn rates of a request latency SLO, from Prometheus
+	LatencySLO LatencySLOConfig `yaml:"latency_slo"`
+}
+
+// LatencySLOConfig defines a latency SLO whose error budget burn rates are
+// computed from a request duration histogram over a short (5m) and a long
+// (1h) window, the pair SRE burn-rate alerts are built on
+type LatencySLOConfig struct {
+	// Collect the burn rates
+	Enabled bool `yaml:"enabled"`
+
+	// Latency a request must beat to count as good; must be a bucket
+	// boundary of the histogram
+	Threshold time.Duration `yaml:"threshold"`
+
+	// Percentage of requests that must be good
+	Objective float64 `yaml:"objective"`
+
+	// Histogram of request durations, in seconds, without the _bucket suffix
+	Histogram string `yaml:"histogram"`
+}
+
+// LLMMetricsConfig defines how the serving metrics of LLM endpoints, such as
+// those exported by vLLM or TGI, are read from Prometheus
+type LLMMetricsConfig struct {
+	// Collect token throughput and time to first token
+	Enabled bool `yaml:"enabled"`
+
+	// Counter of generated tokens
+	TokenCounter string `yaml:"token_counter"`
+
+	// Histogram of the time to first token, in seconds, without the _bucket suffix
+	TimeToFirstTokenHistogram string `yaml:"ttft_histogram"`
+}
+
+// PrometheusQueryConfig defines how Prometheus-compatible backends such as
+// Thanos, Cortex, Mimir and VictoriaMetrics are queried
+type PrometheusQueryConfig struct {
+	// Extra URL parameters sent with every query, such as Thanos dedup or sharding hints
+	Parameters map[string]string `yaml:"parameters"`
+
+	// Range queries longer than this are split into consecutive queries
+	SplitInterval time.Duration `yaml:"split_interval"`
+}
+
+// ApplicationRequestsConfig names the request metrics of the applications
+type ApplicationRequestsConfig struct {
+	// Counter of handled requests, e.g. http_requests_total; empty disables
+	Counter string `yaml:"counter"`
+
+	// Label matchers selecting the failed requests of the counter
+	ErrorSelector string `yaml:"error_selector"`
+
+	// Histogram of request durations in seconds, without the _bucket
+	// suffix; empty leaves latency to nginx
+	DurationHistogram string `yaml:"duration_histogram"`
+}
+
+// LabelMappingConfig defines how the labels of source series map onto the
+// namespace and name of Kubernetes services
+type LabelMappingConfig struct {
+	// Label holding the service name; defaults to service
+	ServiceLabel string `yaml:"service_label"`
+
+	// Label holding the service namespace; defaults to namespace
+	NamespaceLabel string `yaml:"namespace_label"`
+
+	// Rewrites applied in order once the service and namespace labels are copied
+	Rules []RelabelRule `yaml:"rules"`
+}
+
+// RelabelRule rewrites the service or namespace label from another label,
+// with the semantics of PromQL label_replace
+type RelabelRule struct {
+	// Label the value is read from
+	SourceLabel string `yaml:"source_label"`
+
+	// Fully anchored regular expression matched against the value; defaults to (.*)
+	Regex string `yaml:"regex"`
+
+	// Label written: service or namespace
+	TargetLabel string `yaml:"target_label"`
+
+	// Replacement, which may reference regex groups; defaults to $1
+	Replacement string `yaml:"replacement"`
+}
+
+// BootstrapConfig defines how the history of a service seen for the first
+// time is loaded from Prometheus
+type BootstrapConfig struct {
+	// Query the history of new services through range queries
+	Enabled bool `yaml:"enabled"`
+
+	// How far back to load; defaults to the AI model historical window
+	Window time.Duration `yaml:"window"`
+}
+
+// MergePolicyConfig defines how one metric reported by several sources is combined
+type MergePolicyConfig struct {
+	// Policy: prefer-source, max, average, freshest
+	Policy string `yaml:"policy"`
+
+	// Source used by prefer-source while it reports data; defaults to prometheus
+	Source string `yaml:"source"`
+}
+
+// SimulatedConfig defines the simulated metrics source, which generates
+// synthetic traffic per service in place of nginx and Prometheus
+type SimulatedConfig struct {
+	// Replace the nginx and Prometheus request sources with generated traffic
+	Enabled bool `yaml:"enabled"`
+
+	// Seed of the noise generator; 0 seeds from the clock
+	Seed int64 `yaml:"seed"`
+
+	// Curve of services without an entry in services
+	Default TrafficCurveConfig `yaml:"default"`
+
+	// Curves per service (namespace/service); unset fields come from default
+	Services map[string]TrafficCurveConfig `yaml:"services"`
+}
+
+// TrafficCurveConfig defines a synthetic traffic curve
+type TrafficCurveConfig struct {
+	// Curve shape: constant, sine, ramp, step, spike
+	Shape string `yaml:"shape"`
+
+	// Baseline request rate (requests per second)
+	Base float64 `yaml:"base"`
+
+	// Request rate added at the peak of the curve
+	Amplitude float64 `yaml:"amplitude"`
+
+	// Length of one cycle of the curve
+	Period time.Duration `yaml:"period"`
+
+	// Relative standard deviation of the noise applied to the request rate
+	Noise float64 `yaml:"noise"`
+
+	// Response time (ms) and error rate (percentage) while not overloaded
+	ResponseTime float64 `yaml:"response_time"`
+	ErrorRate    float64 `yaml:"error_rate"`
+
+	// Requests per second one replica serves at 100% CPU; when set, CPU and
+	// memory utilization are simulated too and overload raises latency and errors
+	RequestsPerReplica float64 `yaml:"requests_per_replica"`
+
+	// Memory utilization (percentage) reported when resources are simulated
+	MemoryUtilization float64 `yaml:"memory_utilization"`
+}
+
+// SourceConfig defines the HTTP settings of a metrics source
+type SourceConfig struct {
+	// Headers added to every request to the source
+	Headers map[string]string `yaml:"headers"`
+
+	// Tenant sent in the X-Scope-OrgID header to multi-tenant backends
+	TenantID string `yaml:"tenant_id"`
+
+	// Time limit of each request, including reading the response; 0 uses 10s
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Idle connections kept open to the source, and how long they are kept;
+	// 0 uses 10 connections and 90s
+	MaxIdleConnections    int           `yaml:"max_idle_connections"`
+	IdleConnectionTimeout time.Duration `yaml:"idle_connection_timeout"`
+
+	// Interval of TCP keep-alive probes on open connections; 0 uses 30s and
+	// a negative value disables them
+	KeepAlive time.Duration `yaml:"keep_alive"`
+}
+
+// MaintenanceBlackoutConfig defines how samples taken while pods of a
+// service run on cordoned or draining nodes are treated
+type MaintenanceBlackoutConfig struct {
+	// Detect cordoned nodes and evicted pods behind each service
+	Enabled bool `yaml:"enabled"`
+
+	// Replace latency and error rate during maintenance with the last values seen outside it
+	CorrectLatency bool `yaml:"correct_latency"`
+}
+
+// BatchContentionDetectionConfig defines how samples taken while large batch
+// jobs share nodes with the pods of a service are flagged. Node-level CPU
+// contention inflates the per-pod CPU utilization of the service.
+type BatchContentionDetectionConfig struct {
+	// Check the nodes behind each service for Job pods
+	Enabled bool `yaml:"enabled"`
+
+	// CPU cores requested by Job pods on a node above which it is contended
+	MinCPU float64 `yaml:"min_cpu"`
+}
+
+// ZoneSpreadDetectionConfig defines how the zones a service spreads its
+// pods over are counted. Only services whose pod template has a topology
+// spread constraint on the topology key are counted.
+type ZoneSpreadDetectionConfig struct {
+	// Count the zones of schedulable nodes matching each pod template's node selector
+	Enabled bool `yaml:"enabled"`
+
+	// Node label whose values are the zones
+	TopologyKey string `yaml:"topology_key"`
+}
+
+// PreemptionDetectionConfig defines how pods of a service that count towards
+// its replicas without serving are detected: pods the scheduler is evicting
+// for higher-priority pods, and pending pods it can't place
+type PreemptionDetectionConfig struct {
+	// Check the pods of each service for preemption and failed scheduling
+	Enabled bool `yaml:"enabled"`
+}
+
+// MeshEjectionDetectionConfig defines how the endpoints of a service that
+// service mesh outlier detection has ejected from load balancing are read.
+// Ejected pods are running but receive no traffic, so they add no capacity.
+type MeshEjectionDetectionConfig struct {
+	// Read ejected endpoints from prometheus_url
+	Enabled bool `yaml:"enabled"`
+
+	// Service mesh whose metrics are recorded: istio, linkerd
+	Mesh string `yaml:"mesh"`
+}
+
+// ThermalConfig defines how the temperature and power draw of nodes are
+// read, such as node-exporter hwmon or DCGM GPU series. Nodes running hot
+// throttle their CPUs and GPUs, so new replicas placed there add less
+// capacity than their requests suggest.
+type ThermalConfig struct {
+	// Read node temperature and power from prometheus_url
+	Enabled bool `yaml:"enabled"`
+
+	// Gauge of temperatures in degrees Celsius
+	TemperatureMetric string `yaml:"temperature_metric"`
+
+	// Gauge of power draw in watts; empty skips power
+	PowerMetric string `yaml:"power_metric"`
+
+	// Label of those series naming the node; a :port suffix is dropped
+	NodeLabel string `yaml:"node_label"`
+
+	// Temperature in degrees Celsius from which a node is thermally constrained
+	MaxTemperature float64 `yaml:"max_temperature"`
+
+	// Power draw in watts from which a node is constrained; 0 ignores power
+	MaxPower float64 `yaml:"max_power"`
+}
+
+// CPUThrottlingConfig defines how CPU utilization is corrected for CFS
+// throttling. Throttled containers can't use more CPU than their quota, so
+// their utilization under-reports demand while latency climbs.
+type CPUThrottlingConfig struct {
+	// Read the throttled share of CFS periods from prometheus_url
+	Enabled bool `yaml:"enabled"`
+
+	// Throttled percentage of CFS periods from which utilization is corrected
+	Threshold float64 `yaml:"threshold"`
+
+	// Largest factor by which utilization is raised
+	MaxCorrection float64 `yaml:"max_correction"`
+}
+
+// RetryStormConfig defines how request rates inflated by retries are
+// detected and discounted. Retried requests add load without adding demand,
+// so scaling on them feeds the failures that cause the retries.
+type RetryStormConfig struct {
+	// Detect retry storms and discount the request rate
+	Enabled bool `yaml:"enabled"`
+
+	// Counter of retried requests, mapped onto services through
+	// label_mapping and read from prometheus_url; empty infers retries from
+	// request bursts that bring no more successful requests
+	RetryMetric string `yaml:"retry_metric"`
+
+	// Error rate percentage from which retries are inferred
+	MinErrorRate float64 `yaml:"min_error_rate"`
+
+	// Ratio of requests to first attempts from which a sample is discounted
+	MinAmplification float64 `yaml:"min_amplification"`
+}
+
+// CrashLoopDetectionConfig defines how crash-looping pods are detected.
+// Requests drop while pods crash, so scale-downs are blocked for services
+// flagged this way.
+type CrashLoopDetectionConfig struct {
+	// Check the containers of each service's pods for crash loops
+	Enabled bool `yaml:"enabled"`
+
+	// Restarts after which a container that last terminated within the
+	// window counts as crash-looping, even between back-offs
+	MinRestarts int32 `yaml:"min_restarts"`
+
+	// How recently such a container must have terminated
+	Window time.Duration `yaml:"window"`
+}
+
+// ImputationConfig defines how metrics of failed sources are backfilled
+type ImputationConfig struct {
+	// Backfill method: none, locf, linear
+	Method string `yaml:"method"`
+
+	// Share of the carried-forward deviation or trend kept each cycle
+	Decay float64 `yaml:"decay"`
+
+	// Time since a source last succeeded after which its metrics are no longer backfilled
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+// SourceBackoffConfig defines how scrapes of a failing nginx or Prometheus
+// endpoint are backed off. After enough consecutive failures the endpoint's
+// circuit opens and its scrapes are skipped until a single recovery probe is
+// due; each failed probe doubles the wait up to the maximum.
+type SourceBackoffConfig struct {
+	// Back off from failing endpoints instead of scraping them every cycle
+	Enabled bool `yaml:"enabled"`
+
+	// Consecutive failed scrapes of an endpoint that open its circuit
+	FailureThreshold int `yaml:"failure_threshold"`
+
+	// Wait before the first recovery probe, and the most it grows to
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+
+	// Random share of each wait added or removed, so replicas of the
+	// controller don't probe in step
+	Jitter float64 `yaml:"jitter"`
 }
 
 // BandwidthConfig defines bandwidth monitoring settings
@@ -54,6 +468,15 @@ type BandwidthConfig struct {
 	NetworkInterface string `yaml:"network_interface"`
 }
 
+// WindowsNodesConfig defines how pods on Windows nodes, which have no
+// cAdvisor metrics, are measured
+type WindowsNodesConfig struct {
+	// Label selector of the container usage series Prometheus scrapes from
+	// the kubelet resource endpoint, used in the recording rules for pods
+	// without cAdvisor series; empty disables the fallback
+	ResourceMetricsSelector string `yaml:"resource_metrics_selector"`
+}
+
 // ScalingConfig defines AI-based scaling parameters
 type ScalingConfig struct {
 	// Enable AI-based scaling
@@ -68,6 +491,15 @@ type ScalingConfig struct {
 	// Scaling evaluation interval
 	EvaluationInterval time.Duration `yaml:"evaluation_interval"`
 
+	// Share of the evaluation interval by which each service's evaluations
+	// are randomly moved, so services don't evaluate in lockstep
+	EvaluationJitter float64 `yaml:"evaluation_jitter"`
+
+	// Reproducible decisions for identical inputs: model initialization,
+	// hyperparameter search and evaluation jitter draw from sources seeded
+	// from ai_model.seed (1 when unset)
+	Deterministic bool `yaml:"deterministic"`
+
 	// Scale up threshold settings
 	ScaleUpThresholds ThresholdConfig `yaml:"scale_up_thresholds"`
 
@@ -82,6 +514,99 @@ type ScalingConfig struct {
 
 	// Prediction settings
 	Prediction PredictionConfig `yaml:"prediction"`
+
+	// Handling of services whose latest metrics are too old
+	StaleMetrics StaleMetricsConfig `yaml:"stale_metrics"`
+
+	// Operator approval of large replica changes
+	Approval ApprovalConfig `yaml:"approval"`
+
+	// Limits on how many scaling actions each service receives
+	ActionBudget ActionBudgetConfig `yaml:"action_budget"`
+
+	// How model output becomes replicas: factor (of current replicas), absolute,
+	// concurrency (from in-flight requests instead of the model)
+	TargetMode string `yaml:"target_mode"`
+
+	// Requests per second one replica serves at its target load; converts
+	// capacity targets to replicas in the absolute target mode
+	RequestsPerReplica float64 `yaml:"requests_per_replica"`
+
+	// Replicas sized from in-flight requests in the concurrency target mode
+	Concurrency ConcurrencyConfig `yaml:"concurrency"`
+
+	// Replicas of LLM endpoints sized from token throughput and time to first token
+	LLM LLMScalingConfig `yaml:"llm"`
+
+	// Response to a fast burn of the latency SLO error budget
+	LatencySLO LatencySLOScalingConfig `yaml:"latency_slo"`
+
+	// Response to batch jobs sharing nodes with a service
+	BatchContention BatchContentionConfig `yaml:"batch_contention"`
+
+	// Response to recurring OOM kills
+	OOM OOMConfig `yaml:"oom"`
+
+	// Placement hints steering new replicas away from thermally constrained nodes
+	ThermalPlacement ThermalPlacementConfig `yaml:"thermal_placement"`
+
+	// Rising replica floor while a service keeps breaching its SLO
+	SLOEscalation SLOEscalationConfig `yaml:"slo_escalation"`
+
+	// Feedback from the Ready transitions of the pods a scale-up adds
+	PodReadiness PodReadinessConfig `yaml:"pod_readiness"`
+
+	// Alert for services whose recommendations keep being clamped at their
+	// max replicas
+	CapacityAlert CapacityAlertConfig `yaml:"capacity_alert"`
+
+	// Replicas kept so scale-downs don't empty a zone
+	ZoneSpread ZoneSpreadConfig `yaml:"zone_spread"`
+
+	// Response to preempted and unschedulable pods
+	Preemption PreemptionConfig `yaml:"preemption"`
+
+	// Response to endpoints ejected by service mesh outlier detection
+	MeshEjection MeshEjectionConfig `yaml:"mesh_ejection"`
+
+	// Cost and carbon estimates attached to decisions
+	Cost CostConfig `yaml:"cost"`
+
+	// Periodic report of over-provisioned and idle services
+	RightSizing RightSizingConfig `yaml:"right_sizing"`
+
+	// Comparison of model-driven and threshold scaling on cohorts of services
+	Experiment ExperimentConfig `yaml:"experiment"`
+
+	// Periodic summary of scaling activity as ScalingReport resources and digests
+	SummaryReport SummaryReportConfig `yaml:"summary_report"`
+
+	// How replicas are divided between the deployments of one service: proportional, weighted
+	DeploymentSplit string `yaml:"deployment_split"`
+
+	// Policy rules applied to model predictions, in order
+	Policies []PolicyConfig `yaml:"policies"`
+}
+
+// DerivedFeatureConfig defines a feature computed from MetricsData by an expression
+type DerivedFeatureConfig struct {
+	// Feature name
+	Name string `yaml:"name"`
+
+	// Expression such as "request_rate / max(current_replicas, 1)"
+	Expression string `yaml:"expression"`
+}
+
+// PolicyConfig defines a rule that adjusts model predictions
+type PolicyConfig struct {
+	// Name used in logs and decision reasoning
+	Name string `yaml:"name"`
+
+	// Rule such as "if error_rate > 5 then scale_factor = max(model, 1.5)"
+	Rule string `yaml:"rule"`
+
+	// Services (namespace/service) the rule applies to; empty for all services
+	Services []string `yaml:"services"`
 }
 
 // ThresholdConfig defines threshold values for scaling decisions
@@ -110,7 +635,7 @@ type ThresholdConfig struct {
 
 // AIModelConfig defines AI model parameters
 type AIModelConfig struct {
-	// Model type (linear, neural_network, ensemble)
+	// Model type (linear, neural_network, quantile, gru, littles_law, ensemble)
 	ModelType string `yaml:"model_type"`
 
 	// Learning rate for adaptive models
@@ -119,151 +644,1569 @@ type AIModelConfig struct {
 	// Historical data window for training
 	HistoricalWindow time.Duration `yaml:"historical_window"`
 
+	// Training samples kept in memory for retraining, shared equally
+	// between services
+	MaxTrainingSamples int `yaml:"max_training_samples"`
+
 	// Feature weights for different metrics
 	FeatureWeights FeatureWeights `yaml:"feature_weights"`
 
 	// Enable online learning
 	EnableOnlineLearning bool `yaml:"enable_online_learning"`
 
-	// Model retrain interval
+	// Interval at which the model is retrained with online learning,
+	// whatever the amount of new training data; 0 leaves retraining to the
+	// data-count, drift and change-point triggers
 	RetrainInterval time.Duration `yaml:"retrain_interval"`
-}
 
-// FeatureWeights defines importance weights for different metrics
-type FeatureWeights struct {
-	CPUUtilization    float64 `yaml:"cpu_utilization"`
-	MemoryUtilization float64 `yaml:"memory_utilization"`
-	RequestRate       float64 `yaml:"request_rate"`
-	NetworkBandwidth  float64 `yaml:"network_bandwidth"`
-	IOBandwidth       float64 `yaml:"io_bandwidth"`
-	ResponseTime      float64 `yaml:"response_time"`
-	ErrorRate         float64 `yaml:"error_rate"`
-}
+	// Time without a successful training after which the model is reported
+	// stale; 0 disables the check
+	FreshnessSLA time.Duration `yaml:"freshness_sla"`
 
-// CooldownConfig defines cooldown periods
-type CooldownConfig struct {
-	// Scale up cooldown period
-	ScaleUpCooldown time.Duration `yaml:"scale_up_cooldown"`
+	// Path to a pre-trained model artifact produced by hydra-train (optional)
+	ModelArtifactPath string `yaml:"model_artifact_path"`
 
-	// Scale down cooldown period
-	ScaleDownCooldown time.Duration `yaml:"scale_down_cooldown"`
-}
+	// File the trained model is written to on shutdown and loaded from, in
+	// preference to model_artifact_path, on the next start; empty disables
+	CheckpointPath string `yaml:"checkpoint_path"`
 
-// PredictionConfig defines prediction settings
-type PredictionConfig struct {
-	// Enable predictive scaling
-	EnablePredictiveScaling bool `yaml:"enable_predictive_scaling"`
+	// Versioned model registry settings
+	Registry ModelRegistryConfig `yaml:"registry"`
 
-	// Prediction horizon
-	PredictionHorizon time.Duration `yaml:"prediction_horizon"`
+	// Feature drift detection settings
+	DriftDetection DriftDetectionConfig `yaml:"drift_detection"`
 
-	// Prediction confidence threshold
-	ConfidenceThreshold float64 `yaml:"confidence_threshold"`
+	// Online detection of per-service regime shifts
+	ChangePoint ChangePointConfig `yaml:"change_point"`
 
-	// Seasonality detection
-	EnableSeasonalityDetection bool `yaml:"enable_seasonality_detection"`
-}
+	// Weighting of training samples by deployment revision
+	RevisionWeighting RevisionWeightingConfig `yaml:"revision_weighting"`
 
-// GeneralConfig defines general settings
-type GeneralConfig struct {
-	// Log level
-	LogLevel string `yaml:"log_level"`
+	// Exclusion of flagged and outlying training samples
+	TrainingQuality TrainingQualityConfig `yaml:"training_quality"`
 
-	// Ingress class to watch
-	IngressClass string `yaml:"ingress_class"`
+	// Boosting of the rare scale events among training samples
+	ClassBalance ClassBalanceConfig `yaml:"class_balance"`
 
-	// Namespaces to watch (empty for all)
-	WatchNamespaces []string `yaml:"watch_namespaces"`
+	// Model hyperparameters and optional periodic search
+	Hyperparameters HyperparameterConfig `yaml:"hyperparameters"`
 
-	// Enable dry run mode
-	DryRun bool `yaml:"dry_run"`
+	// Seed of the random weight initialization and hyperparameter search;
+	// 0 seeds them from the clock
+	Seed int64 `yaml:"seed"`
 
-	// Leader election settings
-	LeaderElection LeaderElectionConfig `yaml:"leader_election"`
+	// Config-defined features appended to the feature vector
+	DerivedFeatures []DerivedFeatureConfig `yaml:"derived_features"`
 
-	// Health check settings
-	HealthCheck HealthCheckConfig `yaml:"health_check"`
-}
+	// Base features zeroed for every service, e.g. io_bandwidth when no
+	// service does meaningful disk IO. Policies can disable more.
+	DisabledFeatures []string `yaml:"disabled_features"`
 
-// LeaderElectionConfig defines leader election settings
-type LeaderElectionConfig struct {
-	// Enable leader election
-	Enabled bool `yaml:"enabled"`
+	// Dates (YYYY-MM-DD) whose samples carry the holiday feature, such as
+	// public holidays and sales events
+	Holidays []string `yaml:"holidays"`
 
-	// Lease duration
-	LeaseDuration time.Duration `yaml:"lease_duration"`
+	// Planned downtime whose samples and outcomes are left out of training
+	// and prediction accuracy. Policies can declare more per service.
+	MaintenanceWindows []MaintenanceWindowConfig `yaml:"maintenance_windows"`
+}
 
-	// Renew deadline
-	RenewDeadline time.Duration `yaml:"renew_deadline"`
+// MaintenanceWindowConfig defines a planned downtime: once, from an RFC
+// 3339 start, or every day or listed weekday, from a wall-clock start
+type MaintenanceWindowConfig struct {
+	// "namespace/service" the window applies to; empty applies to all
+	Services []string `yaml:"services"`
 
-	// Retry period
-	RetryPeriod time.Duration `yaml:"retry_period"`
-}
+	// RFC 3339 time of a one-off window, or HH:MM of a recurring one
+	Start string `yaml:"start"`
 
-// HealthCheckConfig defines health check settings
-type HealthCheckConfig struct {
-	// Health check interval
-	Interval time.Duration `yaml:"interval"`
+	// Weekdays (Monday or Mon) a recurring window opens on; empty is every day
+	Days []string `yaml:"days"`
 
-	// Health check timeout
-	Timeout time.Duration `yaml:"timeout"`
+	// How long the window lasts
+	Duration time.Duration `yaml:"duration"`
 
-	// Failure threshold
-	FailureThreshold int `yaml:"failure_threshold"`
+	// IANA time zone of a recurring start; UTC when empty
+	Timezone string `yaml:"timezone"`
 }
 
-// LoadConfig loads configuration from a YAML file
-func LoadConfig(path string) (*Config, error) {
-	data, err := ioutil.ReadFile(path)
+// AppliesTo reports whether the window covers the service key (namespace/name)
+func (w MaintenanceWindowConfig) AppliesTo(key string) bool {
+	if len(w.Services) == 0 {
+		return true
+	}
+	for _, service := range w.Services {
+		if service == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether t falls inside the window. Invalid windows,
+// which validation rejects, contain nothing.
+func (w MaintenanceWindowConfig) Contains(t time.Time) bool {
+	if start, err := time.Parse(time.RFC3339, w.Start); err == nil {
+		return !t.Before(start) && t.Before(start.Add(w.Duration))
+	}
+	clock, err := time.Parse("15:04", w.Start)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return false
+	}
+	location, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return false
+	}
+	days, err := parseWeekdays(w.Days)
+	if err != nil {
+		return false
 	}
 
-	config := &Config{}
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	// Windows opened on the days before may still be open
+	local := t.In(location)
+	for back := 0; back <= int(w.Duration/(24*time.Hour))+1; back++ {
+		day := local.AddDate(0, 0, -back)
+		if len(days) > 0 && !days[day.Weekday()] {
+			continue
+		}
+		open := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+		if !local.Before(open) && local.Before(open.Add(w.Duration)) {
+			return true
+		}
 	}
+	return false
+}
 
-	// Set defaults
+// Validate checks the start, days, duration and time zone of the window
+func (w MaintenanceWindowConfig) Validate() error {
+	if w.Duration <= 0 {
+		return fmt.Errorf("maintenance window starting %q needs a positive duration", w.Start)
+	}
+	if _, err := time.Parse(time.RFC3339, w.Start); err == nil {
+		if len(w.Days) > 0 {
+			return fmt.Errorf("maintenance window starting %q is one-off and takes no days", w.Start)
+		}
+		return nil
+	}
+	if _, err := time.Parse("15:04", w.Start); err != nil {
+		return fmt.Errorf("invalid maintenance window start %q, expected RFC 3339 or HH:MM", w.Start)
+	}
+	if _, err := parseWeekdays(w.Days); err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return fmt.Errorf("invalid maintenance window timezone %q: %w", w.Timezone, err)
+	}
+	return nil
+}
+
+// parseWeekdays parses full or three-letter English weekday names
+func parseWeekdays(names []string) (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool, len(names))
+	for _, name := range names {
+		found := false
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(name, day.String()) || strings.EqualFold(name, day.String()[:3]) {
+				days[day] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid maintenance window day %q", name)
+		}
+	}
+	return days, nil
+}
+
+// HyperparameterConfig defines hyperparameters of the built-in models
+type HyperparameterConfig struct {
+	// Hidden layer width of the neural network
+	HiddenUnits int `yaml:"hidden_units"`
+
+	// Training epochs of the neural network
+	Epochs int `yaml:"epochs"`
+
+	// L2 (ridge) regularization strength of the linear and quantile models
+	Regularization float64 `yaml:"regularization"`
+
+	// Quantile of required capacity predicted by the quantile model (0-1)
+	Quantile float64 `yaml:"quantile"`
+
+	// Number of consecutive samples read by the GRU model
+	SequenceLength int `yaml:"sequence_length"`
+
+	// Concurrent requests per replica the Little's Law model sizes for
+	TargetConcurrency float64 `yaml:"target_concurrency"`
+
+	// Periodic hyperparameter search
+	Search HyperparameterSearchConfig `yaml:"search"`
+}
+
+// HyperparameterSearchConfig defines a lightweight periodic search over a
+// small hyperparameter space, scored on held-out training data
+type HyperparameterSearchConfig struct {
+	// Enable periodic search
+	Enabled bool `yaml:"enabled"`
+
+	// How often the search runs
+	Interval time.Duration `yaml:"interval"`
+
+	// Search strategy (grid, random)
+	Strategy string `yaml:"strategy"`
+
+	// Number of sampled candidates for the random strategy
+	Trials int `yaml:"trials"`
+
+	// Fraction of the most recent samples used to score candidates
+	HoldoutFraction float64 `yaml:"holdout_fraction"`
+
+	// Minimum training samples before a search runs
+	MinSamples int `yaml:"min_samples"`
+
+	// Candidate values; empty lists keep the configured value
+	LearningRates  []float64 `yaml:"learning_rates"`
+	Regularization []float64 `yaml:"regularization"`
+	HiddenUnits    []int     `yaml:"hidden_units"`
+}
+
+// DriftDetectionConfig defines how live feature distributions are compared
+// with the distributions the model was trained on
+type DriftDetectionConfig struct {
+	// Enable drift detection
+	Enabled bool `yaml:"enabled"`
+
+	// Statistic used to score drift (psi, ks)
+	Method string `yaml:"method"`
+
+	// Score above which the model is considered drifted
+	Threshold float64 `yaml:"threshold"`
+
+	// Number of recent feature vectors compared with the training distribution
+	WindowSize int `yaml:"window_size"`
+
+	// Minimum recent feature vectors before drift is evaluated
+	MinSamples int `yaml:"min_samples"`
+
+	// How often drift is evaluated
+	CheckInterval time.Duration `yaml:"check_interval"`
+
+	// Response to detected drift (none, retrain, fallback)
+	Action string `yaml:"action"`
+}
+
+// ChangePointConfig defines Page-Hinkley change-point detection on the cost
+// per request and request rate of each service
+type ChangePointConfig struct {
+	// Enable change-point detection
+	Enabled bool `yaml:"enabled"`
+
+	// Cumulative relative deviation from the running mean that signals a shift
+	Threshold float64 `yaml:"threshold"`
+
+	// Relative deviation per sample tolerated as noise
+	Tolerance float64 `yaml:"tolerance"`
+
+	// Samples of a service observed before shifts are reported
+	MinSamples int `yaml:"min_samples"`
+
+	// Response to a detected shift (none, retrain, reset)
+	Action string `yaml:"action"`
+}
+
+// RevisionWeightingConfig defines how training samples from older deployment
+// revisions are down-weighted or discarded
+type RevisionWeightingConfig struct {
+	// Enable revision weighting
+	Enabled bool `yaml:"enabled"`
+
+	// Weight multiplier applied per revision of age (0-1)
+	Decay float64 `yaml:"decay"`
+
+	// Number of most recent revisions whose samples are kept; 0 keeps all
+	KeepRevisions int `yaml:"keep_revisions"`
+}
+
+// TrainingQualityConfig defines which training samples are dropped before
+// training. Samples are always flagged; filtering applies when enabled.
+type TrainingQualityConfig struct {
+	// Enable filtering
+	Enabled bool `yaml:"enabled"`
+
+	// Flags whose samples are dropped (incident, rollout, collector_outage)
+	Exclude []string `yaml:"exclude"`
+
+	// Error rate (percent) from which a sample counts as collected during an incident
+	IncidentErrorRate float64 `yaml:"incident_error_rate"`
+
+	// Samples whose scale factor lies more than this many median absolute
+	// deviations from their service's median are trimmed; 0 disables
+	OutlierThreshold float64 `yaml:"outlier_threshold"`
+}
+
+// ClassBalanceConfig defines how training compensates for scale-up and
+// scale-down samples being rare next to samples that need no scaling
+type ClassBalanceConfig struct {
+	// Enable balancing
+	Enabled bool `yaml:"enabled"`
+
+	// How rare event types are boosted (weight, oversample)
+	Method string `yaml:"method"`
+
+	// Largest boost of an event type
+	MaxWeight float64 `yaml:"max_weight"`
+}
+
+// ModelRegistryConfig defines where versioned model artifacts are stored
+type ModelRegistryConfig struct {
+	// Storage backend (configmap, directory); empty disables the registry
+	Backend string `yaml:"backend"`
+
+	// Name of the registry, used to label ConfigMaps
+	Name string `yaml:"name"`
+
+	// Namespace holding registry ConfigMaps
+	Namespace string `yaml:"namespace"`
+
+	// Directory holding model versions for the directory backend
+	Directory string `yaml:"directory"`
+
+	// How often the active, canary and shadow versions are reloaded from the backend
+	SyncInterval time.Duration `yaml:"sync_interval"`
+
+	// Canary rollout settings
+	Canary CanaryConfig `yaml:"canary"`
+}
+
+// CanaryConfig defines which services a canary model version serves and
+// when it is promoted automatically
+type CanaryConfig struct {
+	// Percentage of managed services served by the canary version (0-100)
+	Percentage float64 `yaml:"percentage"`
+
+	// Explicit "namespace/service" allowlist; overrides Percentage when set
+	Services []string `yaml:"services"`
+
+	// Promote or retire the canary automatically based on prediction error
+	AutoPromote bool `yaml:"auto_promote"`
+
+	// Minimum scored predictions for both versions before comparing them
+	MinSamples int `yaml:"min_samples"`
+
+	// Promote when canary error <= stable error * MaxErrorRatio, retire otherwise
+	MaxErrorRatio float64 `yaml:"max_error_ratio"`
+}
+
+// FeatureWeights defines importance weights for different metrics
+type FeatureWeights struct {
+	CPUUtilization    float64 `yaml:"cpu_utilization"`
+	MemoryUtilization float64 `yaml:"memory_utilization"`
+	RequestRate       float64 `yaml:"request_rate"`
+	NetworkBandwidth  float64 `yaml:"network_bandwidth"`
+	IOBandwidth       float64 `yaml:"io_bandwidth"`
+	ResponseTime      float64 `yaml:"response_time"`
+	ErrorRate         float64 `yaml:"error_rate"`
+}
+
+// CooldownConfig defines cooldown periods
+type CooldownConfig struct {
+	// Scale up cooldown period
+	ScaleUpCooldown time.Duration `yaml:"scale_up_cooldown"`
+
+	// Scale down cooldown period
+	ScaleDownCooldown time.Duration `yaml:"scale_down_cooldown"`
+}
+
+// StaleMetricsConfig defines how decisions are gated on metrics age
+type StaleMetricsConfig struct {
+	// Age of the latest sample after which metrics are considered stale
+	MaxAge time.Duration `yaml:"max_age"`
+
+	// Action on stale metrics: skip, dampen
+	Action string `yaml:"action"`
+
+	// Share of the model's scale change kept when dampening
+	Dampening float64 `yaml:"dampening"`
+}
+
+// ApprovalConfig defines which replica changes wait for an operator to
+// approve them on the service's ScalingRecommendation
+type ApprovalConfig struct {
+	// Relative replica change above which approval is required, e.g. 0.5
+	// for changes of more than 50%; 0 disables approvals
+	Threshold float64 `yaml:"threshold"`
+
+	// How long a pending approval stays open before it expires
+	Expiry time.Duration `yaml:"expiry"`
+
+	// Endpoint notified with a JSON payload when approval is requested
+	NotifyURL string `yaml:"notify_url"`
+}
+
+// ActionBudgetConfig limits the scaling actions per service, on top of the
+// cooldowns. Each limit is a token bucket that refills evenly over its window.
+type ActionBudgetConfig struct {
+	// Scaling actions per service per hour; 0 for no limit
+	PerHour int `yaml:"per_hour"`
+
+	// Scaling actions per service per day; 0 for no limit
+	PerDay int `yaml:"per_day"`
+}
+
+// ConcurrencyConfig sizes services by their in-flight requests, like the
+// Knative pod autoscaler: the stable window sets the replicas, and a burst
+// in the panic window scales up at once without allowing scale-downs
+type ConcurrencyConfig struct {
+	// In-flight requests per replica to size for
+	Target float64 `yaml:"target"`
+
+	// Window over which in-flight requests are averaged
+	StableWindow time.Duration `yaml:"stable_window"`
+
+	// Short window that detects bursts; the latest sample is always included
+	PanicWindow time.Duration `yaml:"panic_window"`
+
+	// Ratio of panic window replicas to current replicas that starts panic mode
+	PanicThreshold float64 `yaml:"panic_threshold"`
+}
+
+// LLMScalingConfig sizes LLM endpoints in the units model-serving teams
+// plan in. Either target replaces the model for services whose LLM metrics
+// are collected; with both, the larger replica count wins.
+type LLMScalingConfig struct {
+	// Generated tokens per second one replica serves; 0 disables
+	TargetTokensPerReplica float64 `yaml:"target_tokens_per_replica"`
+
+	// 95th percentile time to first token to hold; 0 disables
+	TimeToFirstTokenSLO time.Duration `yaml:"ttft_slo"`
+}
+
+// LatencySLOScalingConfig defines how decisions respond to the burn rates
+// of metrics.latency_slo. Like a multi-window burn-rate alert, the response
+// needs both windows above the threshold: the long one shows the burn is
+// significant, the short one that it is still going on.
+type LatencySLOScalingConfig struct {
+	// Burn rate both windows must reach to add replicas; 0 disables. 14.4
+	// spends 2% of a 30-day error budget in an hour.
+	BurnRateThreshold float64 `yaml:"burn_rate_threshold"`
+
+	// Factor of the current replicas kept as a floor while the budget burns
+	ScaleFactor float64 `yaml:"scale_factor"`
+}
+
+// BatchContentionConfig defines how decisions respond to samples flagged by
+// metrics.batch_contention
+type BatchContentionConfig struct {
+	// Response: none, relax (raise the CPU target), prescale (add replicas up front)
+	Action string `yaml:"action"`
+
+	// Share by which the CPU utilization target is raised while contended
+	Relax float64 `yaml:"relax"`
+
+	// Factor of the replicas at the start of contention kept as a floor until it ends
+	PrescaleFactor float64 `yaml:"prescale_factor"`
+}
+
+// ZoneSpreadConfig defines how decisions respect the zones counted by
+// metrics.zone_spread
+type ZoneSpreadConfig struct {
+	// Response: none, floor (keep enough replicas for every zone), warn
+	// (flag decisions that leave too few)
+	Action string `yaml:"action"`
+
+	// Replicas wanted in every zone
+	MinReplicasPerZone int32 `yaml:"min_replicas_per_zone"`
+}
+
+// PreemptionConfig defines how decisions respond to the preempted and
+// unschedulable pods counted by metrics.preemption
+type PreemptionConfig struct {
+	// Response: none, compensate (size from the serving replicas and keep
+	// replacements for the others), wait (hold replicas until they settle)
+	Action string `yaml:"action"`
+}
+
+// MeshEjectionConfig defines how decisions respond to the ejected endpoints
+// read by metrics.mesh_ejection
+type MeshEjectionConfig struct {
+	// Response: none, compensate (size from the endpoints receiving traffic
+	// and keep replacements for the ejected ones)
+	Action string `yaml:"action"`
+
+	// maxEjectionPercent set on the Istio DestinationRules of a service while
+	// a scale-up completes with endpoints ejected, restored afterwards; 0
+	// leaves the rules alone
+	RelaxedMaxEjectionPercent int `yaml:"relaxed_max_ejection_percent"`
+}
+
+// OOMConfig defines how recurring OOM kills of a service's containers are
+// treated, independently of its request metrics
+type OOMConfig struct {
+	// Response: scale_up, vertical (recommend a larger memory limit only), none
+	Action string `yaml:"action"`
+
+	// OOM kills within metrics.oom_window that count as recurring
+	MinKills int `yaml:"min_kills"`
+
+	// Factor applied to the replicas for every new OOM kill while recurring
+	ScaleFactor float64 `yaml:"scale_factor"`
+}
+
+// ThermalPlacementConfig defines the node affinity hint written on the
+// workloads of a service scaling up while nodes are thermally constrained
+type ThermalPlacementConfig struct {
+	// Write placement hints on scale-ups
+	Enabled bool `yaml:"enabled"`
+
+	// Weight of the preferred node affinity term (1-100)
+	Weight int32 `yaml:"weight"`
+}
+
+// SLOEscalationConfig defines the replica floor raised, step by step, while
+// a service breaches its SLO evaluation after evaluation despite scaling.
+// The floor overrides cooldowns, which would otherwise hold a degraded
+// service at its replicas.
+type SLOEscalationConfig struct {
+	// Raise floors for services breaching the SLO
+	Enabled bool `yaml:"enabled"`
+
+	// Response time in milliseconds and error rate percentage above which
+	// an evaluation breaches the SLO
+	SLOResponseTime float64 `yaml:"slo_response_time"`
+	SLOErrorRate    float64 `yaml:"slo_error_rate"`
+
+	// Consecutive breaching evaluations before each step
+	BreachEvaluations int `yaml:"breach_evaluations"`
+
+	// Factor each step multiplies the replicas by; a step adds at least one
+	StepFactor float64 `yaml:"step_factor"`
+
+	// Highest floor; 0 caps at the service's max replicas
+	MaxReplicas int32 `yaml:"max_replicas"`
+
+	// Consecutive evaluations within the SLO that release the floor
+	RecoveryEvaluations int `yaml:"recovery_evaluations"`
+}
+
+// PodReadinessConfig defines how the controller follows the pods a scale-up
+// adds until they are Ready, from pod lifecycle events, so the outcome of
+// the decision is scored once the new capacity serves
+type PodReadinessConfig struct {
+	// Watch the pods of scaled services for Ready transitions
+	Enabled bool `yaml:"enabled"`
+
+	// How long the new pods may take to become Ready before the outcome of
+	// the scale-up is dropped
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// CapacityAlertConfig defines when recommendations clamped at a service's
+// max replicas raise a capacity alert: the service needs more than its
+// limit allows. How often min, max and step limits clamp recommendations
+// is exported either way.
+type CapacityAlertConfig struct {
+	// Raise capacity alerts
+	Enabled bool `yaml:"enabled"`
+
+	// Consecutive evaluations clamped at max that raise the alert
+	Evaluations int `yaml:"evaluations"`
+}
+
+// CostConfig defines the pricing and energy model that estimates how much a
+// decision changes the hourly cost and emissions of a service, from the
+// resources its pods request
+type CostConfig struct {
+	// Estimate every decision
+	Enabled bool `yaml:"enabled"`
+
+	// Annotate the deployments a decision scales with its estimate
+	AnnotateDeployments bool `yaml:"annotate_deployments"`
+
+	// Rates per node type, keyed by the value of metrics.node_type_label;
+	// "default" applies to node types without an entry
+	NodeTypes map[string]NodeTypeCost `yaml:"node_types"`
+}
+
+// NodeTypeCost holds the price and energy use of requested resources on a
+// node type, and the carbon intensity of the grid it runs on
+type NodeTypeCost struct {
+	// Price of a requested CPU core per hour
+	CPUCoreHour float64 `yaml:"cpu_core_hour"`
+
+	// Price of a requested GiB of memory per hour
+	MemoryGiBHour float64 `yaml:"memory_gib_hour"`
+
+	// Power drawn per requested CPU core, in watts
+	WattsPerCore float64 `yaml:"watts_per_core"`
+
+	// Power drawn per requested GiB of memory, in watts
+	WattsPerGiB float64 `yaml:"watts_per_gib"`
+
+	// Grams of CO2-equivalent emitted per kWh, including datacenter overhead
+	CarbonIntensity float64 `yaml:"carbon_intensity"`
+}
+
+// RightSizingConfig defines the periodic analysis that compares each
+// service's provisioning with its observed peak over a window and
+// recommends lower minimum replicas and resource requests
+type RightSizingConfig struct {
+	// Run the analysis
+	Enabled bool `yaml:"enabled"`
+
+	// Time between analyses
+	Interval time.Duration `yaml:"interval"`
+
+	// History analyzed, read from Prometheus when configured and from the
+	// in-memory history otherwise
+	Window time.Duration `yaml:"window"`
+
+	// Share of the window the samples must span for a service to be analyzed
+	MinCoverage float64 `yaml:"min_coverage"`
+
+	// Margin kept above the observed peak in recommendations, e.g. 0.2 for 20%
+	Headroom float64 `yaml:"headroom"`
+
+	// Peak utilization of requests, in percent, below which requests are
+	// reported as oversized
+	RequestUtilizationThreshold float64 `yaml:"request_utilization_threshold"`
+
+	// Peak CPU utilization, in percent, below which a service that served no
+	// requests in the window is reported as idle
+	IdleCPUUtilization float64 `yaml:"idle_cpu_utilization"`
+
+	// Endpoint POSTed the report when it has findings
+	NotifyURL string `yaml:"notify_url"`
+}
+
+// ExperimentConfig defines an A/B comparison of scaling paths. For the
+// duration of the experiment, services in the threshold cohort are scaled
+// by the scale-up and scale-down thresholds instead of the model, and the
+// SLO attainment and cost of both cohorts are compared.
+type ExperimentConfig struct {
+	// Experiment name, which also salts the cohort assignment; empty disables
+	Name string `yaml:"name"`
+
+	// Time the cohorts take effect (RFC 3339)
+	Start string `yaml:"start"`
+
+	// How long services stay in their cohorts
+	Duration time.Duration `yaml:"duration"`
+
+	// Percentage of managed services in the threshold cohort (0-100)
+	ThresholdPercentage float64 `yaml:"threshold_percentage"`
+
+	// Explicit "namespace/service" threshold cohort; overrides
+	// ThresholdPercentage when set
+	ThresholdServices []string `yaml:"threshold_services"`
+
+	// Response time in milliseconds and error rate percentage a sample must
+	// stay within to attain the SLO
+	SLOResponseTime float64 `yaml:"slo_response_time"`
+	SLOErrorRate    float64 `yaml:"slo_error_rate"`
+
+	// Time between comparisons
+	Interval time.Duration `yaml:"interval"`
+
+	// Endpoint POSTed the final comparison when the experiment ends
+	NotifyURL string `yaml:"notify_url"`
+}
+
+// SummaryReportConfig defines the periodic summary of scaling activity:
+// actions taken, SLO breaches avoided, estimated savings and model accuracy.
+// Each summary is written as a ScalingReport and optionally sent as a digest.
+type SummaryReportConfig struct {
+	// Write summaries
+	Enabled bool `yaml:"enabled"`
+
+	// Period each summary covers
+	Interval time.Duration `yaml:"interval"`
+
+	// Namespace the ScalingReports are created in
+	Namespace string `yaml:"namespace"`
+
+	// ScalingReports kept; older ones are deleted
+	Retain int `yaml:"retain"`
+
+	// Response time in milliseconds and error rate percentage a sample must
+	// stay within to meet the SLO
+	SLOResponseTime float64 `yaml:"slo_response_time"`
+	SLOErrorRate    float64 `yaml:"slo_error_rate"`
+
+	// Services listed by scaling actions
+	TopServices int `yaml:"top_services"`
+
+	// Endpoint POSTed every summary as JSON
+	NotifyURL string `yaml:"notify_url"`
+
+	// Email digest of every summary
+	Email EmailDigestConfig `yaml:"email"`
+}
+
+// EmailDigestConfig defines how summaries are mailed
+type EmailDigestConfig struct {
+	// SMTP server as host:port; empty disables the digest
+	SMTPAddress string `yaml:"smtp_address"`
+
+	// Sender and recipients
+	From string   `yaml:"from"`
+	To   []string `yaml:"to"`
+
+	// SMTP username, and the environment variable holding its password;
+	// no authentication when the username is empty
+	Username    string `yaml:"username"`
+	PasswordEnv string `yaml:"password_env"`
+}
+
+// PredictionConfig defines prediction settings
+type PredictionConfig struct {
+	// Enable predictive scaling
+	EnablePredictiveScaling bool `yaml:"enable_predictive_scaling"`
+
+	// Prediction horizon
+	PredictionHorizon time.Duration `yaml:"prediction_horizon"`
+
+	// Prediction confidence threshold
+	ConfidenceThreshold float64 `yaml:"confidence_threshold"`
+
+	// Seasonality detection
+	EnableSeasonalityDetection bool `yaml:"enable_seasonality_detection"`
+
+	// Metrics staleness after which prediction confidence is halved
+	StalenessHalfLife time.Duration `yaml:"staleness_half_life"`
+
+	// Envelope the models' scale factor, 0.5 to 2.0, is mapped onto: a
+	// factor below 1 and one above 1, which the strongest scale-down and
+	// scale-up predictions reach
+	MinScaleFactor float64 `yaml:"min_scale_factor"`
+	MaxScaleFactor float64 `yaml:"max_scale_factor"`
+}
+
+// GeneralConfig defines general settings
+type GeneralConfig struct {
+	// Settings profile filling in unset values: "" or "edge" for small
+	// nodes, which favors light models, longer intervals and less memory
+	Profile string `yaml:"profile"`
+
+	// Log level
+	LogLevel string `yaml:"log_level"`
+
+	// Ingress class to watch
+	IngressClass string `yaml:"ingress_class"`
+
+	// Namespaces to watch (empty for all)
+	WatchNamespaces []string `yaml:"watch_namespaces"`
+
+	// Scale services enabled with the hydra-route.ai/enabled annotation or
+	// a HydraRoutePolicy naming them, without any ingress routing to them
+	ServiceTargets bool `yaml:"service_targets"`
+
+	// Enable HydraRoute for every ingress in namespaces labeled
+	// hydra-route.ai/enabled=true, unless the ingress opts out with its
+	// hydra-route.ai/enabled annotation
+	NamespaceOptIn bool `yaml:"namespace_opt_in"`
+
+	// Enable dry run mode
+	DryRun bool `yaml:"dry_run"`
+
+	// Record the latest decision per service as a ScalingRecommendation resource
+	RecordRecommendations bool `yaml:"record_recommendations"`
+
+	// Reconcile HydraRoutePolicy resources (requires the HydraRoutePolicy CRD)
+	EnablePolicies bool `yaml:"enable_policies"`
+
+	// Coexistence with GitOps tools that manage deployment manifests
+	GitOps GitOpsConfig `yaml:"gitops"`
+
+	// Scaling of Argo Rollouts in place of the deployments of a service
+	ArgoRollouts ArgoRolloutsConfig `yaml:"argo_rollouts"`
+
+	// External approval hook called before every scaling action
+	VetoWebhook VetoWebhookConfig `yaml:"veto_webhook"`
+
+	// Open Policy Agent query made before every scaling action
+	OPA OPAConfig `yaml:"opa"`
+
+	// Restriction of outbound connections for air-gapped environments
+	AirGapped AirGappedConfig `yaml:"air_gapped"`
+
+	// Signing of decision records, so their origin can be verified
+	Signing SigningConfig `yaml:"signing"`
+
+	// Cluster-wide limit on the rate of scaling actions
+	ActuationRateLimit ActuationRateLimitConfig `yaml:"actuation_rate_limit"`
+
+	// Verification that applied replicas took effect before scaling again
+	ReplicaParity ReplicaParityConfig `yaml:"replica_parity"`
+
+	// Isolation of services whose evaluations keep failing
+	Quarantine QuarantineConfig `yaml:"quarantine"`
+
+	// Leader election settings
+	LeaderElection LeaderElectionConfig `yaml:"leader_election"`
+
+	// Health check settings
+	HealthCheck HealthCheckConfig `yaml:"health_check"`
+
+	// How long in-flight evaluations, scaling actions and the model
+	// checkpoint may take to finish after SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// Profiling and Go runtime tuning for large clusters
+	Runtime RuntimeConfig `yaml:"runtime"`
+
+	// Admin API settings
+	AdminAPI AdminAPIConfig `yaml:"admin_api"`
+
+	// Refuse to start unless the binary uses FIPS-validated crypto, i.e.
+	// was built with GOEXPERIMENT=boringcrypto
+	FIPSRequired bool `yaml:"fips_required"`
+
+	// prometheus-operator monitors managed by the controller
+	ServiceMonitors ServiceMonitorsConfig `yaml:"service_monitors"`
+}
+
+// VetoWebhookConfig defines a synchronous webhook that can veto scaling
+// actions, for change-management systems and custom safety checks
+type VetoWebhookConfig struct {
+	// Endpoint the decision is POSTed to; empty disables the hook
+	URL string `yaml:"url"`
+
+	// Headers added to every request, e.g. Authorization
+	Headers map[string]string `yaml:"headers"`
+
+	// How long to wait for an answer
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Outcome when the hook can't be reached or times out: deny, allow
+	FailurePolicy string `yaml:"failure_policy"`
+}
+
+// OPAConfig defines a governance check of every scaling action against a
+// Rego policy bundle, evaluated by an Open Policy Agent server such as a
+// sidecar through its Data API
+type OPAConfig struct {
+	// Base URL of the OPA server, e.g. http://localhost:8181; empty disables the check
+	URL string `yaml:"url"`
+
+	// Path of the rule queried, e.g. hydraroute/scaling/decision
+	Path string `yaml:"path"`
+
+	// Headers added to every request, e.g. Authorization
+	Headers map[string]string `yaml:"headers"`
+
+	// How long to wait for an answer
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Outcome when OPA can't be reached, times out or leaves the rule
+	// undefined: deny, allow
+	FailurePolicy string `yaml:"failure_policy"`
+
+	// IANA time zone of the time of day given to the policy
+	Timezone string `yaml:"timezone"`
+}
+
+// SigningConfig defines how decision records are signed. Each workload
+// the controller scales, and each ScalingRecommendation, carries the record
+// and its signature, and veto webhook requests carry a signature header.
+type SigningConfig struct {
+	// Key backend (file, vault); empty disables signing
+	Backend string `yaml:"backend"`
+
+	// Unencrypted PEM private key, ECDSA P-256 or Ed25519, for the file backend
+	KeyFile string `yaml:"key_file"`
+
+	// Identifier of the key, included in every record so verifiers can pick
+	// the public key, e.g. after a rotation
+	KeyID string `yaml:"key_id"`
+
+	// HashiCorp Vault Transit settings for the vault backend
+	Vault VaultSigningConfig `yaml:"vault"`
+}
+
+// VaultSigningConfig defines a HashiCorp Vault Transit key that signs
+// records without the private key leaving Vault
+type VaultSigningConfig struct {
+	// Vault address, e.g. https://vault.vault.svc:8200
+	Address string `yaml:"address"`
+
+	// Mount path of the transit secrets engine
+	Mount string `yaml:"mount"`
+
+	// Name of the transit key, of type ecdsa-p256 or ed25519
+	Key string `yaml:"key"`
+
+	// Environment variable holding the Vault token
+	TokenEnv string `yaml:"token_env"`
+
+	// How long to wait for a signature
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// AirGappedConfig restricts outbound connections to the Kubernetes API
+// server and an allow-list, for regulated environments without egress.
+// Configured endpoints that are not allowed fail validation at startup, and
+// connections to any other address are refused at runtime.
+type AirGappedConfig struct {
+	// Enable the restriction
+	Enabled bool `yaml:"enabled"`
+
+	// Hosts, or host:port pairs, the controller may connect to besides the
+	// API server, e.g. prometheus.monitoring.svc.cluster.local:9090
+	AllowedEndpoints []string `yaml:"allowed_endpoints"`
+}
+
+// Allows reports whether address (host:port) may be connected to. An entry
+// without a port allows every port of its host.
+func (c AirGappedConfig) Allows(address string) bool {
+	if !c.Enabled {
+		return true
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host, port = address, ""
+	}
+	for _, entry := range c.AllowedEndpoints {
+		entryHost, entryPort, err := net.SplitHostPort(entry)
+		if err != nil {
+			entryHost, entryPort = strings.Trim(entry, "[]"), ""
+		}
+		if strings.EqualFold(strings.TrimSuffix(entryHost, "."), strings.TrimSuffix(host, ".")) && (entryPort == "" || entryPort == port) {
+			return true
+		}
+	}
+	return false
+}
+
+// OutboundEndpoints returns the addresses (host:port) the configuration
+// makes the controller connect to, by setting, leaving out the API server
+func OutboundEndpoints(config *Config) map[string]string {
+	endpoints := make(map[string]string)
+	addURL := func(setting, raw string) {
+		if raw == "" {
+			return
+		}
+		target, err := url.Parse(raw)
+		if err != nil || target.Hostname() == "" {
+			endpoints[setting] = raw
+			return
+		}
+		port := target.Port()
+		if port == "" {
+			port = "80"
+			if target.Scheme == "https" {
+				port = "443"
+			}
+		}
+		endpoints[setting] = net.JoinHostPort(target.Hostname(), port)
+	}
+	addURL("metrics.nginx_metrics_url", config.Metrics.NginxMetricsURL)
+	addURL("metrics.prometheus_url", config.Metrics.PrometheusURL)
+	addURL("scaling.approval.notify_url", config.Scaling.Approval.NotifyURL)
+	addURL("scaling.right_sizing.notify_url", config.Scaling.RightSizing.NotifyURL)
+	addURL("scaling.experiment.notify_url", config.Scaling.Experiment.NotifyURL)
+	addURL("scaling.summary_report.notify_url", config.Scaling.SummaryReport.NotifyURL)
+	addURL("general.veto_webhook.url", config.General.VetoWebhook.URL)
+	addURL("general.opa.url", config.General.OPA.URL)
+	if config.General.Signing.Backend == "vault" {
+		addURL("general.signing.vault.address", config.General.Signing.Vault.Address)
+	}
+	if address := config.Scaling.SummaryReport.Email.SMTPAddress; address != "" {
+		endpoints["scaling.summary_report.email.smtp_address"] = address
+	}
+	return endpoints
+}
+
+// ActuationRateLimitConfig limits deployment updates across all services.
+// Queued actions are applied with the largest SLO risk first.
+type ActuationRateLimitConfig struct {
+	// Scaling actions applied per minute; 0 for no limit
+	UpdatesPerMinute int `yaml:"updates_per_minute"`
+
+	// Actions that may be applied back to back after a quiet period
+	Burst int `yaml:"burst"`
+}
+
+// ReplicaParityConfig defines how the controller checks that the replicas it
+// applied are still in place, and backs off when another actor reverts them
+type ReplicaParityConfig struct {
+	// Check parity before each scaling action
+	Enabled bool `yaml:"enabled"`
+
+	// How long pods may take to converge on applied replicas before the
+	// controller acts again anyway
+	ConvergenceTimeout time.Duration `yaml:"convergence_timeout"`
+
+	// Wait after the first conflict, doubled for each consecutive one
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+
+	// Longest wait between attempts to apply replicas
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+}
+
+// QuarantineConfig defines the error budget of each service's evaluations.
+// A service that exhausts it, such as one with broken metrics or without a
+// deployment, is left out of collection and evaluation for a while, so a
+// few broken services don't fill the logs every cycle.
+type QuarantineConfig struct {
+	// Quarantine services that exhaust their error budget
+	Enabled bool `yaml:"enabled"`
+
+	// Window the failed evaluations are counted over
+	Window time.Duration `yaml:"window"`
+
+	// Share of failed evaluations in the window that exhausts the budget
+	MaxErrorRatio float64 `yaml:"max_error_ratio"`
+
+	// Fewest evaluations in the window before the budget applies
+	MinEvaluations int `yaml:"min_evaluations"`
+
+	// Length of the first quarantine, doubled for each failed probe
+	Duration time.Duration `yaml:"duration"`
+
+	// Longest quarantine
+	MaxDuration time.Duration `yaml:"max_duration"`
+}
+
+// ArgoRolloutsConfig defines how services backed by Argo Rollouts are scaled
+type ArgoRolloutsConfig struct {
+	// Scale the Argo Rollouts a service selects, including rollouts
+	// referencing a deployment through workloadRef, instead of its deployments
+	Enabled bool `yaml:"enabled"`
+
+	// Allow scaling while a canary step or background analysis runs; by
+	// default actions are held until it completes
+	ScaleDuringAnalysis bool `yaml:"scale_during_analysis"`
+}
+
+// GitOpsConfig defines how replicas are written so that Argo CD or Flux and
+// HydraRoute do not revert each other
+type GitOpsConfig struct {
+	// How replicas are written: "" (plain update), annotation, server-side-apply
+	Mode string `yaml:"mode"`
+
+	// Field manager used for server-side apply
+	FieldManager string `yaml:"field_manager"`
+}
+
+// AdminAPIConfig defines the HTTP admin API settings
+type AdminAPIConfig struct {
+	// Enable the admin API
+	Enabled bool `yaml:"enabled"`
+
+	// Address the admin API listens on
+	BindAddress string `yaml:"bind_address"`
+
+	// TLS settings; plain HTTP without a certificate
+	TLS AdminAPITLSConfig `yaml:"tls"`
+
+	// Per-tenant API keys; with any configured, every request but the
+	// OpenAPI document needs one
+	APIKeys []AdminAPIKeyConfig `yaml:"api_keys"`
+}
+
+// AdminAPIKeyConfig defines the API key of one team or tenant, its scope
+// and its request quota. The key itself is read from the environment or a
+// file, never from the config file.
+type AdminAPIKeyConfig struct {
+	// Name of the tenant, used in logs and metrics
+	Name string `yaml:"name"`
+
+	// Environment variable holding the key
+	KeyEnv string `yaml:"key_env"`
+
+	// File holding the key, e.g. mounted from a secret, instead of key_env
+	KeyFile string `yaml:"key_file"`
+
+	// Operations allowed: read (reports, models and what-if queries), write
+	// (also register, promote and roll back models) or force (also
+	// promotions that skip stages)
+	Scope string `yaml:"scope"`
+
+	// Requests per minute; 0 for no quota
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+
+	// Requests that may be made back to back after a quiet period;
+	// defaults to requests_per_minute
+	Burst int `yaml:"burst"`
+}
+
+// AdminAPITLSConfig defines TLS for the admin API. Files are read again
+// when they change, so rotated certificates are picked up without a restart.
+type AdminAPITLSConfig struct {
+	// PEM certificate chain and private key of the server
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+
+	// PEM bundle of the CAs client certificates must be signed by; empty
+	// accepts clients without a certificate
+	ClientCAFile string `yaml:"client_ca_file"`
+
+	// Lowest TLS version accepted (1.2, 1.3)
+	MinVersion string `yaml:"min_version"`
+}
+
+// RuntimeConfig defines the profiling endpoint and the Go runtime and
+// workqueue tuning. Zero values leave the Go defaults in place; the
+// matching command-line flags override them.
+type RuntimeConfig struct {
+	// Address the pprof endpoints listen on, e.g. ":6060"; empty disables them
+	PprofBindAddress string `yaml:"pprof_bind_address"`
+
+	// Maximum number of CPUs executing Go code at once (GOMAXPROCS)
+	GOMAXPROCS int `yaml:"gomaxprocs"`
+
+	// Heap growth that triggers a collection, in percent (GOGC); -1 turns
+	// the collector off and leaves memory_limit_mib in charge
+	GCPercent int `yaml:"gc_percent"`
+
+	// Soft limit on the memory of the Go runtime in MiB (GOMEMLIMIT)
+	MemoryLimitMiB int64 `yaml:"memory_limit_mib"`
+
+	// Ingresses and policies reconciled in parallel by each controller
+	MaxConcurrentReconciles int `yaml:"max_concurrent_reconciles"`
+
+	// Slowing down when the controller nears its own resource limits
+	SelfThrottling SelfThrottlingConfig `yaml:"self_throttling"`
+}
+
+// SelfThrottlingConfig defines how the controller sheds work when its own
+// memory or CPU use nears the limits of its container, rather than being
+// OOM-killed or throttled during cluster-wide load spikes
+type SelfThrottlingConfig struct {
+	// Watch the controller's own resource use
+	Enabled bool `yaml:"enabled"`
+
+	// Share of the memory limit from which the controller throttles itself
+	MemoryThreshold float64 `yaml:"memory_threshold"`
+
+	// Share of the CPU limit from which the controller throttles itself
+	CPUThreshold float64 `yaml:"cpu_threshold"`
+
+	// Throttling ends once use is this share below both thresholds
+	RecoveryMargin float64 `yaml:"recovery_margin"`
+
+	// Factor the collection interval is stretched by while throttled
+	CollectionSlowdown float64 `yaml:"collection_slowdown"`
+
+	// How often resource use is checked
+	CheckInterval time.Duration `yaml:"check_interval"`
+}
+
+// ServiceMonitorsConfig defines the prometheus-operator ServiceMonitors and
+// PodMonitors the controller keeps in place for its own metrics and for the
+// exporters it reads, so scrape configuration needs no separate manifests
+type ServiceMonitorsConfig struct {
+	// Create and update the monitors (requires the prometheus-operator CRDs)
+	Enabled bool `yaml:"enabled"`
+
+	// Namespace the monitors are created in
+	Namespace string `yaml:"namespace"`
+
+	// Labels added to every monitor, such as the ones the Prometheus
+	// serviceMonitorSelector and podMonitorSelector match
+	Labels map[string]string `yaml:"labels"`
+
+	// Scrape interval of the monitored endpoints
+	Interval time.Duration `yaml:"interval"`
+
+	// Labels of the controller's metrics service in the monitors' namespace; empty skips it
+	ControllerSelector map[string]string `yaml:"controller_selector"`
+
+	// Port of the controller's metrics service
+	ControllerPort string `yaml:"controller_port"`
+
+	// Monitor the nginx ingress controller service behind nginx_metrics_url
+	Nginx bool `yaml:"nginx"`
+
+	// Container port serving /metrics on LLM endpoints, monitored with a
+	// PodMonitor per service while metrics.llm is enabled; empty skips them
+	LLMPort string `yaml:"llm_port"`
+}
+
+// LeaderElectionConfig defines leader election settings
+type LeaderElectionConfig struct {
+	// Enable leader election
+	Enabled bool `yaml:"enabled"`
+
+	// Lease duration
+	LeaseDuration time.Duration `yaml:"lease_duration"`
+
+	// Renew deadline
+	RenewDeadline time.Duration `yaml:"renew_deadline"`
+
+	// Retry period
+	RetryPeriod time.Duration `yaml:"retry_period"`
+}
+
+// HealthCheckConfig defines health check settings
+type HealthCheckConfig struct {
+	// Health check interval
+	Interval time.Duration `yaml:"interval"`
+
+	// Health check timeout
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Failure threshold
+	FailureThreshold int `yaml:"failure_threshold"`
+}
+
+// LoadConfig loads configuration from a YAML file
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	// Set defaults
 	setDefaults(config)
 
 	// Validate configuration
 	if err := validateConfig(config); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
-
-	return config, nil
-}
-
-// setDefaults sets default values for configuration
-func setDefaults(config *Config) {
-	if config.Metrics.CollectionInterval == 0 {
-		config.Metrics.CollectionInterval = 30 * time.Second
+
+	return config, nil
+}
+
+// DefaultConfig returns a configuration populated with default values
+func DefaultConfig() *Config {
+	config := &Config{}
+	setDefaults(config)
+	return config
+}
+
+// setDefaults sets default values for configuration
+func setDefaults(config *Config) {
+	applyProfile(config)
+
+	if config.Metrics.CollectionInterval == 0 {
+		config.Metrics.CollectionInterval = 30 * time.Second
+	}
+	if config.Metrics.RetentionPeriod == 0 {
+		config.Metrics.RetentionPeriod = 24 * time.Hour
+	}
+	if config.Metrics.BackendDiscovery == "" {
+		config.Metrics.BackendDiscovery = "endpointslices"
+	}
+	if config.Metrics.PrometheusQuery.SplitInterval == 0 {
+		config.Metrics.PrometheusQuery.SplitInterval = 24 * time.Hour
+	}
+	if config.Metrics.Simulated.Default.Shape == "" {
+		config.Metrics.Simulated.Default.Shape = "sine"
+	}
+	if config.Metrics.Simulated.Default.Base == 0 {
+		config.Metrics.Simulated.Default.Base = 50
+	}
+	if config.Metrics.Simulated.Default.Amplitude == 0 {
+		config.Metrics.Simulated.Default.Amplitude = 40
+	}
+	if config.Metrics.Simulated.Default.Period == 0 {
+		config.Metrics.Simulated.Default.Period = 30 * time.Minute
+	}
+	if config.Metrics.Simulated.Default.ResponseTime == 0 {
+		config.Metrics.Simulated.Default.ResponseTime = 100
+	}
+	if config.Metrics.Simulated.Default.MemoryUtilization == 0 {
+		config.Metrics.Simulated.Default.MemoryUtilization = 50
+	}
+	if config.Metrics.RequestRateWindow == 0 {
+		config.Metrics.RequestRateWindow = 5 * time.Minute
+	}
+	if config.Metrics.Imputation.Method == "" {
+		config.Metrics.Imputation.Method = "none"
+	}
+	if config.Metrics.Imputation.Decay == 0 {
+		config.Metrics.Imputation.Decay = 0.8
+	}
+	if config.Metrics.Imputation.MaxAge == 0 {
+		config.Metrics.Imputation.MaxAge = 5 * time.Minute
+	}
+	if config.Metrics.SourceBackoff.FailureThreshold == 0 {
+		config.Metrics.SourceBackoff.FailureThreshold = 3
+	}
+	if config.Metrics.SourceBackoff.InitialBackoff == 0 {
+		config.Metrics.SourceBackoff.InitialBackoff = 30 * time.Second
+	}
+	if config.Metrics.SourceBackoff.MaxBackoff == 0 {
+		config.Metrics.SourceBackoff.MaxBackoff = 5 * time.Minute
+	}
+	if config.Metrics.SourceBackoff.Jitter == 0 {
+		config.Metrics.SourceBackoff.Jitter = 0.2
+	}
+	if config.Metrics.BandwidthMonitoring.MeasurementInterval == 0 {
+		config.Metrics.BandwidthMonitoring.MeasurementInterval = 10 * time.Second
+	}
+	if config.Metrics.CPUThrottling.Threshold == 0 {
+		config.Metrics.CPUThrottling.Threshold = 25
+	}
+	if config.Metrics.CPUThrottling.MaxCorrection == 0 {
+		config.Metrics.CPUThrottling.MaxCorrection = 2
+	}
+	if config.Metrics.RetryStorm.MinErrorRate == 0 {
+		config.Metrics.RetryStorm.MinErrorRate = 5
+	}
+	if config.Metrics.RetryStorm.MinAmplification == 0 {
+		config.Metrics.RetryStorm.MinAmplification = 1.5
+	}
+	if config.Metrics.CrashLoop.MinRestarts == 0 {
+		config.Metrics.CrashLoop.MinRestarts = 3
+	}
+	if config.Metrics.CrashLoop.Window == 0 {
+		config.Metrics.CrashLoop.Window = 10 * time.Minute
+	}
+	if config.Metrics.BatchContention.MinCPU == 0 {
+		config.Metrics.BatchContention.MinCPU = 2
+	}
+	if config.Metrics.ZoneSpread.TopologyKey == "" {
+		config.Metrics.ZoneSpread.TopologyKey = "topology.kubernetes.io/zone"
+	}
+	if config.Metrics.MeshEjection.Mesh == "" {
+		config.Metrics.MeshEjection.Mesh = "istio"
+	}
+	if config.Metrics.Thermal.TemperatureMetric == "" {
+		config.Metrics.Thermal.TemperatureMetric = "node_hwmon_temp_celsius"
+	}
+	if config.Metrics.Thermal.NodeLabel == "" {
+		config.Metrics.Thermal.NodeLabel = "instance"
+	}
+	if config.Metrics.Thermal.MaxTemperature == 0 {
+		config.Metrics.Thermal.MaxTemperature = 85
+	}
+	if config.Metrics.ApplicationRequests.ErrorSelector == "" {
+		config.Metrics.ApplicationRequests.ErrorSelector = `code=~"5.."`
+	}
+	if config.Metrics.LLM.TokenCounter == "" {
+		config.Metrics.LLM.TokenCounter = "vllm:generation_tokens_total"
+	}
+	if config.Metrics.LLM.TimeToFirstTokenHistogram == "" {
+		config.Metrics.LLM.TimeToFirstTokenHistogram = "vllm:time_to_first_token_seconds"
+	}
+	if config.Metrics.LatencySLO.Threshold == 0 {
+		config.Metrics.LatencySLO.Threshold = 500 * time.Millisecond
+	}
+	if config.Metrics.LatencySLO.Objective == 0 {
+		config.Metrics.LatencySLO.Objective = 99
+	}
+	if config.Metrics.LatencySLO.Histogram == "" {
+		config.Metrics.LatencySLO.Histogram = "nginx_ingress_controller_request_duration_seconds"
+	}
+
+	if config.Scaling.MinReplicas == 0 {
+		config.Scaling.MinReplicas = 1
+	}
+	if config.Scaling.MaxReplicas == 0 {
+		config.Scaling.MaxReplicas = 10
+	}
+	if config.Scaling.EvaluationInterval == 0 {
+		config.Scaling.EvaluationInterval = 30 * time.Second
+	}
+	if config.Scaling.EvaluationJitter == 0 {
+		config.Scaling.EvaluationJitter = 0.1
+	}
+	if config.Scaling.Deterministic && config.Scaling.AIModel.Seed == 0 {
+		config.Scaling.AIModel.Seed = 1
+	}
+	if config.Scaling.Cooldown.ScaleUpCooldown == 0 {
+		config.Scaling.Cooldown.ScaleUpCooldown = 3 * time.Minute
+	}
+	if config.Scaling.Cooldown.ScaleDownCooldown == 0 {
+		config.Scaling.Cooldown.ScaleDownCooldown = 5 * time.Minute
+	}
+	if config.Scaling.StaleMetrics.MaxAge == 0 {
+		config.Scaling.StaleMetrics.MaxAge = 5 * time.Minute
+	}
+	if config.General.GitOps.FieldManager == "" {
+		config.General.GitOps.FieldManager = "hydra-route"
+	}
+	if config.General.OPA.Timeout == 0 {
+		config.General.OPA.Timeout = 2 * time.Second
+	}
+	if config.General.OPA.FailurePolicy == "" {
+		config.General.OPA.FailurePolicy = "deny"
+	}
+	if config.General.OPA.Timezone == "" {
+		config.General.OPA.Timezone = "UTC"
+	}
+	if config.General.Signing.Vault.Mount == "" {
+		config.General.Signing.Vault.Mount = "transit"
+	}
+	if config.General.Signing.Vault.TokenEnv == "" {
+		config.General.Signing.Vault.TokenEnv = "VAULT_TOKEN"
+	}
+	if config.General.Signing.Vault.Timeout == 0 {
+		config.General.Signing.Vault.Timeout = 5 * time.Second
+	}
+	if config.General.VetoWebhook.Timeout == 0 {
+		config.General.VetoWebhook.Timeout = 5 * time.Second
+	}
+	if config.General.VetoWebhook.FailurePolicy == "" {
+		config.General.VetoWebhook.FailurePolicy = "deny"
+	}
+	if config.General.ActuationRateLimit.Burst == 0 {
+		config.General.ActuationRateLimit.Burst = 1
+	}
+	if config.General.ReplicaParity.ConvergenceTimeout == 0 {
+		config.General.ReplicaParity.ConvergenceTimeout = 5 * time.Minute
+	}
+	if config.General.ReplicaParity.InitialBackoff == 0 {
+		config.General.ReplicaParity.InitialBackoff = time.Minute
+	}
+	if config.General.ReplicaParity.MaxBackoff == 0 {
+		config.General.ReplicaParity.MaxBackoff = 30 * time.Minute
+	}
+	if config.General.Quarantine.Window == 0 {
+		config.General.Quarantine.Window = 10 * time.Minute
+	}
+	if config.General.Quarantine.MaxErrorRatio == 0 {
+		config.General.Quarantine.MaxErrorRatio = 0.5
+	}
+	if config.General.Quarantine.MinEvaluations == 0 {
+		config.General.Quarantine.MinEvaluations = 5
+	}
+	if config.General.Quarantine.Duration == 0 {
+		config.General.Quarantine.Duration = 5 * time.Minute
+	}
+	if config.General.Quarantine.MaxDuration == 0 {
+		config.General.Quarantine.MaxDuration = time.Hour
+	}
+	if config.Scaling.TargetMode == "" {
+		config.Scaling.TargetMode = "factor"
+	}
+	if config.Scaling.OOM.Action == "" {
+		config.Scaling.OOM.Action = "scale_up"
+	}
+	if config.Scaling.LatencySLO.ScaleFactor == 0 {
+		config.Scaling.LatencySLO.ScaleFactor = 1.5
+	}
+	if config.Scaling.OOM.MinKills == 0 {
+		config.Scaling.OOM.MinKills = 2
+	}
+	if config.Scaling.OOM.ScaleFactor == 0 {
+		config.Scaling.OOM.ScaleFactor = 1.5
+	}
+	if config.Scaling.SLOEscalation.SLOResponseTime == 0 {
+		config.Scaling.SLOEscalation.SLOResponseTime = 500
+	}
+	if config.Scaling.SLOEscalation.SLOErrorRate == 0 {
+		config.Scaling.SLOEscalation.SLOErrorRate = 1
+	}
+	if config.Scaling.SLOEscalation.BreachEvaluations == 0 {
+		config.Scaling.SLOEscalation.BreachEvaluations = 3
+	}
+	if config.Scaling.SLOEscalation.StepFactor == 0 {
+		config.Scaling.SLOEscalation.StepFactor = 1.5
+	}
+	if config.Scaling.SLOEscalation.RecoveryEvaluations == 0 {
+		config.Scaling.SLOEscalation.RecoveryEvaluations = 3
+	}
+	if config.Scaling.PodReadiness.Timeout == 0 {
+		config.Scaling.PodReadiness.Timeout = 10 * time.Minute
+	}
+	if config.Scaling.CapacityAlert.Evaluations == 0 {
+		config.Scaling.CapacityAlert.Evaluations = 20
+	}
+	if config.Scaling.BatchContention.Action == "" {
+		config.Scaling.BatchContention.Action = "none"
+	}
+	if config.Scaling.ZoneSpread.Action == "" {
+		config.Scaling.ZoneSpread.Action = "none"
+	}
+	if config.Scaling.Preemption.Action == "" {
+		config.Scaling.Preemption.Action = "none"
+	}
+	if config.Scaling.MeshEjection.Action == "" {
+		config.Scaling.MeshEjection.Action = "none"
+	}
+	if config.Scaling.ThermalPlacement.Weight == 0 {
+		config.Scaling.ThermalPlacement.Weight = 100
+	}
+	if config.Scaling.ZoneSpread.MinReplicasPerZone == 0 {
+		config.Scaling.ZoneSpread.MinReplicasPerZone = 1
+	}
+	if config.Scaling.RightSizing.Interval == 0 {
+		config.Scaling.RightSizing.Interval = 7 * 24 * time.Hour
+	}
+	if config.Scaling.RightSizing.Window == 0 {
+		config.Scaling.RightSizing.Window = 7 * 24 * time.Hour
+	}
+	if config.Scaling.RightSizing.MinCoverage == 0 {
+		config.Scaling.RightSizing.MinCoverage = 0.8
 	}
-	if config.Metrics.RetentionPeriod == 0 {
-		config.Metrics.RetentionPeriod = 24 * time.Hour
+	if config.Scaling.RightSizing.Headroom == 0 {
+		config.Scaling.RightSizing.Headroom = 0.2
 	}
-	if config.Metrics.RequestRateWindow == 0 {
-		config.Metrics.RequestRateWindow = 5 * time.Minute
+	if config.Scaling.RightSizing.RequestUtilizationThreshold == 0 {
+		config.Scaling.RightSizing.RequestUtilizationThreshold = 50
 	}
-	if config.Metrics.BandwidthMonitoring.MeasurementInterval == 0 {
-		config.Metrics.BandwidthMonitoring.MeasurementInterval = 10 * time.Second
+	if config.Scaling.RightSizing.IdleCPUUtilization == 0 {
+		config.Scaling.RightSizing.IdleCPUUtilization = 5
 	}
-
-	if config.Scaling.MinReplicas == 0 {
-		config.Scaling.MinReplicas = 1
+	if config.Scaling.Experiment.Duration == 0 {
+		config.Scaling.Experiment.Duration = 14 * 24 * time.Hour
 	}
-	if config.Scaling.MaxReplicas == 0 {
-		config.Scaling.MaxReplicas = 10
+	if config.Scaling.Experiment.ThresholdPercentage == 0 && len(config.Scaling.Experiment.ThresholdServices) == 0 {
+		config.Scaling.Experiment.ThresholdPercentage = 50
 	}
-	if config.Scaling.EvaluationInterval == 0 {
-		config.Scaling.EvaluationInterval = 30 * time.Second
+	if config.Scaling.Experiment.SLOResponseTime == 0 {
+		config.Scaling.Experiment.SLOResponseTime = config.Scaling.ScaleUpThresholds.ResponseTime
 	}
-	if config.Scaling.Cooldown.ScaleUpCooldown == 0 {
-		config.Scaling.Cooldown.ScaleUpCooldown = 3 * time.Minute
+	if config.Scaling.Experiment.SLOErrorRate == 0 {
+		config.Scaling.Experiment.SLOErrorRate = config.Scaling.ScaleUpThresholds.ErrorRate
 	}
-	if config.Scaling.Cooldown.ScaleDownCooldown == 0 {
-		config.Scaling.Cooldown.ScaleDownCooldown = 5 * time.Minute
+	if config.Scaling.Experiment.Interval == 0 {
+		config.Scaling.Experiment.Interval = time.Hour
+	}
+	if config.Scaling.SummaryReport.Interval == 0 {
+		config.Scaling.SummaryReport.Interval = 7 * 24 * time.Hour
+	}
+	if config.Scaling.SummaryReport.Namespace == "" {
+		config.Scaling.SummaryReport.Namespace = "hydra-route-system"
+	}
+	if config.Scaling.SummaryReport.Retain == 0 {
+		config.Scaling.SummaryReport.Retain = 12
+	}
+	if config.Scaling.SummaryReport.SLOResponseTime == 0 {
+		config.Scaling.SummaryReport.SLOResponseTime = config.Scaling.ScaleUpThresholds.ResponseTime
+	}
+	if config.Scaling.SummaryReport.SLOErrorRate == 0 {
+		config.Scaling.SummaryReport.SLOErrorRate = config.Scaling.ScaleUpThresholds.ErrorRate
+	}
+	if config.Scaling.SummaryReport.TopServices == 0 {
+		config.Scaling.SummaryReport.TopServices = 10
+	}
+	if config.Scaling.BatchContention.Relax == 0 {
+		config.Scaling.BatchContention.Relax = 0.25
+	}
+	if config.Scaling.BatchContention.PrescaleFactor == 0 {
+		config.Scaling.BatchContention.PrescaleFactor = 1.25
+	}
+	if config.Scaling.Concurrency.Target == 0 {
+		config.Scaling.Concurrency.Target = 10
+	}
+	if config.Scaling.Concurrency.StableWindow == 0 {
+		config.Scaling.Concurrency.StableWindow = time.Minute
+	}
+	if config.Scaling.Concurrency.PanicWindow == 0 {
+		config.Scaling.Concurrency.PanicWindow = 6 * time.Second
+	}
+	if config.Scaling.Concurrency.PanicThreshold == 0 {
+		config.Scaling.Concurrency.PanicThreshold = 2
+	}
+	if config.Scaling.DeploymentSplit == "" {
+		config.Scaling.DeploymentSplit = "proportional"
+	}
+	if config.Scaling.StaleMetrics.Action == "" {
+		config.Scaling.StaleMetrics.Action = "skip"
+	}
+	if config.Scaling.StaleMetrics.Dampening == 0 {
+		config.Scaling.StaleMetrics.Dampening = 0.5
+	}
+	if config.Scaling.Approval.Expiry == 0 {
+		config.Scaling.Approval.Expiry = time.Hour
 	}
 	if config.Scaling.AIModel.LearningRate == 0 {
 		config.Scaling.AIModel.LearningRate = 0.01
@@ -271,12 +2214,123 @@ func setDefaults(config *Config) {
 	if config.Scaling.AIModel.HistoricalWindow == 0 {
 		config.Scaling.AIModel.HistoricalWindow = 24 * time.Hour
 	}
+	if config.Scaling.AIModel.MaxTrainingSamples == 0 {
+		config.Scaling.AIModel.MaxTrainingSamples = 10000
+	}
+	if config.Metrics.Bootstrap.Window == 0 {
+		config.Metrics.Bootstrap.Window = config.Scaling.AIModel.HistoricalWindow
+	}
+	if config.Scaling.AIModel.Registry.Name == "" {
+		config.Scaling.AIModel.Registry.Name = "hydra-route-models"
+	}
+	if config.Scaling.AIModel.Registry.Namespace == "" {
+		config.Scaling.AIModel.Registry.Namespace = "hydra-route-system"
+	}
+	if config.Scaling.AIModel.Registry.SyncInterval == 0 {
+		config.Scaling.AIModel.Registry.SyncInterval = time.Minute
+	}
+	if config.Scaling.AIModel.Registry.Canary.MinSamples == 0 {
+		config.Scaling.AIModel.Registry.Canary.MinSamples = 100
+	}
+	if config.Scaling.AIModel.Registry.Canary.MaxErrorRatio == 0 {
+		config.Scaling.AIModel.Registry.Canary.MaxErrorRatio = 1.0
+	}
+	if config.Scaling.AIModel.DriftDetection.Method == "" {
+		config.Scaling.AIModel.DriftDetection.Method = "psi"
+	}
+	if config.Scaling.AIModel.DriftDetection.Threshold == 0 {
+		config.Scaling.AIModel.DriftDetection.Threshold = 0.2
+	}
+	if config.Scaling.AIModel.DriftDetection.WindowSize == 0 {
+		config.Scaling.AIModel.DriftDetection.WindowSize = 1000
+	}
+	if config.Scaling.AIModel.DriftDetection.MinSamples == 0 {
+		config.Scaling.AIModel.DriftDetection.MinSamples = 200
+	}
+	if config.Scaling.AIModel.DriftDetection.CheckInterval == 0 {
+		config.Scaling.AIModel.DriftDetection.CheckInterval = 5 * time.Minute
+	}
+	if config.Scaling.AIModel.DriftDetection.Action == "" {
+		config.Scaling.AIModel.DriftDetection.Action = "retrain"
+	}
+	if config.Scaling.AIModel.ChangePoint.Threshold == 0 {
+		config.Scaling.AIModel.ChangePoint.Threshold = 5
+	}
+	if config.Scaling.AIModel.ChangePoint.Tolerance == 0 {
+		config.Scaling.AIModel.ChangePoint.Tolerance = 0.1
+	}
+	if config.Scaling.AIModel.ChangePoint.MinSamples == 0 {
+		config.Scaling.AIModel.ChangePoint.MinSamples = 30
+	}
+	if config.Scaling.AIModel.ChangePoint.Action == "" {
+		config.Scaling.AIModel.ChangePoint.Action = "retrain"
+	}
+	if config.Scaling.AIModel.RevisionWeighting.Decay == 0 {
+		config.Scaling.AIModel.RevisionWeighting.Decay = 0.5
+	}
+	if config.Scaling.AIModel.RevisionWeighting.KeepRevisions == 0 {
+		config.Scaling.AIModel.RevisionWeighting.KeepRevisions = 3
+	}
+	if config.Scaling.AIModel.TrainingQuality.Exclude == nil {
+		config.Scaling.AIModel.TrainingQuality.Exclude = []string{"incident", "rollout", "collector_outage"}
+	}
+	if config.Scaling.AIModel.TrainingQuality.IncidentErrorRate == 0 {
+		config.Scaling.AIModel.TrainingQuality.IncidentErrorRate = 5
+	}
+	if config.Scaling.AIModel.TrainingQuality.OutlierThreshold == 0 {
+		config.Scaling.AIModel.TrainingQuality.OutlierThreshold = 3.5
+	}
+	if config.Scaling.AIModel.ClassBalance.Method == "" {
+		config.Scaling.AIModel.ClassBalance.Method = "weight"
+	}
+	if config.Scaling.AIModel.ClassBalance.MaxWeight == 0 {
+		config.Scaling.AIModel.ClassBalance.MaxWeight = 10
+	}
+	if config.Scaling.AIModel.Hyperparameters.HiddenUnits == 0 {
+		config.Scaling.AIModel.Hyperparameters.HiddenUnits = 8
+	}
+	if config.Scaling.AIModel.Hyperparameters.Quantile == 0 {
+		config.Scaling.AIModel.Hyperparameters.Quantile = 0.9
+	}
+	if config.Scaling.AIModel.Hyperparameters.SequenceLength == 0 {
+		config.Scaling.AIModel.Hyperparameters.SequenceLength = 6
+	}
+	if config.Scaling.AIModel.Hyperparameters.TargetConcurrency == 0 {
+		config.Scaling.AIModel.Hyperparameters.TargetConcurrency = 10
+	}
+	if config.Scaling.AIModel.Hyperparameters.Epochs == 0 {
+		config.Scaling.AIModel.Hyperparameters.Epochs = 200
+	}
+	if config.Scaling.AIModel.Hyperparameters.Search.Interval == 0 {
+		config.Scaling.AIModel.Hyperparameters.Search.Interval = 24 * time.Hour
+	}
+	if config.Scaling.AIModel.Hyperparameters.Search.Strategy == "" {
+		config.Scaling.AIModel.Hyperparameters.Search.Strategy = "grid"
+	}
+	if config.Scaling.AIModel.Hyperparameters.Search.Trials == 0 {
+		config.Scaling.AIModel.Hyperparameters.Search.Trials = 8
+	}
+	if config.Scaling.AIModel.Hyperparameters.Search.HoldoutFraction == 0 {
+		config.Scaling.AIModel.Hyperparameters.Search.HoldoutFraction = 0.2
+	}
+	if config.Scaling.AIModel.Hyperparameters.Search.MinSamples == 0 {
+		config.Scaling.AIModel.Hyperparameters.Search.MinSamples = 500
+	}
 	if config.Scaling.Prediction.PredictionHorizon == 0 {
 		config.Scaling.Prediction.PredictionHorizon = 10 * time.Minute
 	}
 	if config.Scaling.Prediction.ConfidenceThreshold == 0 {
 		config.Scaling.Prediction.ConfidenceThreshold = 0.8
 	}
+	if config.Scaling.Prediction.StalenessHalfLife == 0 {
+		config.Scaling.Prediction.StalenessHalfLife = 2 * time.Minute
+	}
+	if config.Scaling.Prediction.MinScaleFactor == 0 {
+		config.Scaling.Prediction.MinScaleFactor = 0.5
+	}
+	if config.Scaling.Prediction.MaxScaleFactor == 0 {
+		config.Scaling.Prediction.MaxScaleFactor = 2.0
+	}
 
 	if config.General.LogLevel == "" {
 		config.General.LogLevel = "info"
@@ -293,6 +2347,9 @@ func setDefaults(config *Config) {
 	if config.General.LeaderElection.RetryPeriod == 0 {
 		config.General.LeaderElection.RetryPeriod = 2 * time.Second
 	}
+	if config.General.ShutdownTimeout == 0 {
+		config.General.ShutdownTimeout = 30 * time.Second
+	}
 	if config.General.HealthCheck.Interval == 0 {
 		config.General.HealthCheck.Interval = 30 * time.Second
 	}
@@ -302,6 +2359,51 @@ func setDefaults(config *Config) {
 	if config.General.HealthCheck.FailureThreshold == 0 {
 		config.General.HealthCheck.FailureThreshold = 3
 	}
+	if config.General.Runtime.MaxConcurrentReconciles == 0 {
+		config.General.Runtime.MaxConcurrentReconciles = 1
+	}
+	if config.General.Runtime.SelfThrottling.MemoryThreshold == 0 {
+		config.General.Runtime.SelfThrottling.MemoryThreshold = 0.85
+	}
+	if config.General.Runtime.SelfThrottling.CPUThreshold == 0 {
+		config.General.Runtime.SelfThrottling.CPUThreshold = 0.9
+	}
+	if config.General.Runtime.SelfThrottling.RecoveryMargin == 0 {
+		config.General.Runtime.SelfThrottling.RecoveryMargin = 0.1
+	}
+	if config.General.Runtime.SelfThrottling.CollectionSlowdown == 0 {
+		config.General.Runtime.SelfThrottling.CollectionSlowdown = 2
+	}
+	if config.General.Runtime.SelfThrottling.CheckInterval == 0 {
+		config.General.Runtime.SelfThrottling.CheckInterval = 15 * time.Second
+	}
+	if config.General.AdminAPI.BindAddress == "" {
+		config.General.AdminAPI.BindAddress = ":8082"
+	}
+	if config.General.AdminAPI.TLS.MinVersion == "" {
+		config.General.AdminAPI.TLS.MinVersion = "1.2"
+	}
+	for i := range config.General.AdminAPI.APIKeys {
+		key := &config.General.AdminAPI.APIKeys[i]
+		if key.Scope == "" {
+			key.Scope = "read"
+		}
+		if key.Burst == 0 {
+			key.Burst = key.RequestsPerMinute
+		}
+	}
+	if config.General.ServiceMonitors.Namespace == "" {
+		config.General.ServiceMonitors.Namespace = "hydra-route-system"
+	}
+	if config.General.ServiceMonitors.Interval == 0 {
+		config.General.ServiceMonitors.Interval = 30 * time.Second
+	}
+	if config.General.ServiceMonitors.ControllerSelector == nil {
+		config.General.ServiceMonitors.ControllerSelector = map[string]string{"app": "hydra-route-controller"}
+	}
+	if config.General.ServiceMonitors.ControllerPort == "" {
+		config.General.ServiceMonitors.ControllerPort = "metrics"
+	}
 
 	// Set default feature weights
 	if config.Scaling.AIModel.FeatureWeights.CPUUtilization == 0 {
@@ -327,6 +2429,41 @@ func setDefaults(config *Config) {
 	}
 }
 
+// applyProfile fills in the settings of general.profile that were left
+// unset, before the regular defaults. The edge profile keeps the controller
+// within tens of MiB on small arm64 and k3s nodes: the linear model, a
+// smaller training buffer and in-memory history, less frequent collection
+// and evaluation, and a soft memory limit.
+func applyProfile(config *Config) {
+	if config.General.Profile != "edge" {
+		return
+	}
+	if config.Metrics.CollectionInterval == 0 {
+		config.Metrics.CollectionInterval = time.Minute
+	}
+	if config.Metrics.RetentionPeriod == 0 {
+		config.Metrics.RetentionPeriod = 2 * time.Hour
+	}
+	if config.Scaling.EvaluationInterval == 0 {
+		config.Scaling.EvaluationInterval = time.Minute
+	}
+	if config.Scaling.AIModel.ModelType == "" {
+		config.Scaling.AIModel.ModelType = "linear"
+	}
+	if config.Scaling.AIModel.HistoricalWindow == 0 {
+		config.Scaling.AIModel.HistoricalWindow = 6 * time.Hour
+	}
+	if config.Scaling.AIModel.MaxTrainingSamples == 0 {
+		config.Scaling.AIModel.MaxTrainingSamples = 1000
+	}
+	if config.General.Runtime.GCPercent == 0 {
+		config.General.Runtime.GCPercent = 50
+	}
+	if config.General.Runtime.MemoryLimitMiB == 0 {
+		config.General.Runtime.MemoryLimitMiB = 48
+	}
+}
+
 // validateConfig validates the configuration
 func validateConfig(config *Config) error {
 	if config.Scaling.MinReplicas < 1 {
@@ -335,12 +2472,691 @@ func validateConfig(config *Config) error {
 	if config.Scaling.MaxReplicas < config.Scaling.MinReplicas {
 		return fmt.Errorf("max_replicas must be greater than or equal to min_replicas")
 	}
+	switch config.Scaling.AIModel.ModelType {
+	case "", "linear", "neural_network", "quantile", "gru", "littles_law", "ensemble":
+	default:
+		return fmt.Errorf("unknown model_type %q", config.Scaling.AIModel.ModelType)
+	}
+	switch config.General.Profile {
+	case "":
+	case "edge":
+		switch config.Scaling.AIModel.ModelType {
+		case "neural_network", "gru", "ensemble":
+			return fmt.Errorf("model_type %s is too heavy for the edge profile, use linear, quantile or littles_law", config.Scaling.AIModel.ModelType)
+		}
+		if config.Scaling.AIModel.Hyperparameters.Search.Enabled {
+			return fmt.Errorf("hyperparameter search is not available with the edge profile")
+		}
+	default:
+		return fmt.Errorf("unknown profile %q", config.General.Profile)
+	}
+	if config.Scaling.AIModel.MaxTrainingSamples < 0 {
+		return fmt.Errorf("max_training_samples must not be negative")
+	}
 	if config.Scaling.AIModel.LearningRate <= 0 || config.Scaling.AIModel.LearningRate >= 1 {
 		return fmt.Errorf("learning_rate must be between 0 and 1")
 	}
 	if config.Scaling.Prediction.ConfidenceThreshold <= 0 || config.Scaling.Prediction.ConfidenceThreshold >= 1 {
 		return fmt.Errorf("confidence_threshold must be between 0 and 1")
 	}
+	if prediction := config.Scaling.Prediction; prediction.MinScaleFactor <= 0 || prediction.MinScaleFactor >= 1 || prediction.MaxScaleFactor <= 1 {
+		return fmt.Errorf("min_scale_factor must be between 0 and 1 and max_scale_factor above 1")
+	}
+	switch config.Metrics.BackendDiscovery {
+	case "endpointslices", "selector":
+	default:
+		return fmt.Errorf("unknown backend discovery %q", config.Metrics.BackendDiscovery)
+	}
+	if err := validateSourceURL("nginx_metrics_url", config.Metrics.NginxMetricsURL); err != nil {
+		return err
+	}
+	if err := validateSourceURL("prometheus_url", config.Metrics.PrometheusURL); err != nil {
+		return err
+	}
+	for source, sourceCfg := range config.Metrics.Sources {
+		if source != "nginx" && source != "prometheus" {
+			return fmt.Errorf("unknown metrics source %q", source)
+		}
+		if sourceCfg.Timeout < 0 || sourceCfg.IdleConnectionTimeout < 0 || sourceCfg.MaxIdleConnections < 0 {
+			return fmt.Errorf("metrics source %s timeouts and max_idle_connections must not be negative", source)
+		}
+	}
+	for field, merge := range config.Metrics.SourceMerge {
+		switch field {
+		case "request_rate", "response_time", "error_rate":
+		default:
+			return fmt.Errorf("unknown source_merge field %q", field)
+		}
+		switch merge.Policy {
+		case "prefer-source", "max", "average", "freshest":
+		default:
+			return fmt.Errorf("unknown merge policy %q for %s", merge.Policy, field)
+		}
+		if merge.Source != "" && merge.Source != "nginx" && merge.Source != "prometheus" {
+			return fmt.Errorf("unknown merge source %q for %s", merge.Source, field)
+		}
+	}
+	if err := validateTrafficCurve("default", config.Metrics.Simulated.Default); err != nil {
+		return err
+	}
+	for service, curve := range config.Metrics.Simulated.Services {
+		if !strings.Contains(service, "/") {
+			return fmt.Errorf("simulated service %q must be namespace/service", service)
+		}
+		if err := validateTrafficCurve(service, curve); err != nil {
+			return err
+		}
+	}
+	if config.Metrics.PrometheusQuery.SplitInterval < 0 {
+		return fmt.Errorf("split_interval must not be negative")
+	}
+	if config.Metrics.Bootstrap.Window < 0 {
+		return fmt.Errorf("bootstrap window must not be negative")
+	}
+	for _, rule := range config.Metrics.LabelMapping.Rules {
+		if rule.SourceLabel == "" {
+			return fmt.Errorf("label mapping rule needs a source_label")
+		}
+		if rule.TargetLabel != "service" && rule.TargetLabel != "namespace" {
+			return fmt.Errorf("unknown label mapping target_label %q", rule.TargetLabel)
+		}
+		if _, err := regexp.Compile("^(?:" + rule.Regex + ")$"); err != nil {
+			return fmt.Errorf("invalid label mapping regex %q: %w", rule.Regex, err)
+		}
+	}
+
+	switch config.Metrics.Imputation.Method {
+	case "none", "locf", "linear":
+	default:
+		return fmt.Errorf("unknown imputation method %q", config.Metrics.Imputation.Method)
+	}
+	if config.Metrics.Imputation.Decay < 0 || config.Metrics.Imputation.Decay > 1 {
+		return fmt.Errorf("imputation decay must be between 0 and 1")
+	}
+	if backoff := config.Metrics.SourceBackoff; backoff.Enabled {
+		if backoff.FailureThreshold < 1 {
+			return fmt.Errorf("source_backoff failure_threshold must be at least 1")
+		}
+		if backoff.InitialBackoff < 0 || backoff.MaxBackoff < backoff.InitialBackoff {
+			return fmt.Errorf("source_backoff max_backoff must be at least initial_backoff")
+		}
+		if backoff.Jitter < 0 || backoff.Jitter >= 1 {
+			return fmt.Errorf("source_backoff jitter must be in [0, 1)")
+		}
+	}
+	for _, window := range config.Metrics.AggregationWindows {
+		if window <= 0 {
+			return fmt.Errorf("aggregation windows must be positive")
+		}
+		if window > config.Metrics.RetentionPeriod {
+			return fmt.Errorf("aggregation window %s exceeds retention_period", window)
+		}
+	}
+	switch config.General.GitOps.Mode {
+	case "", "annotation", "server-side-apply":
+	default:
+		return fmt.Errorf("unknown gitops mode %q", config.General.GitOps.Mode)
+	}
+	switch config.General.VetoWebhook.FailurePolicy {
+	case "deny", "allow":
+	default:
+		return fmt.Errorf("unknown veto webhook failure_policy %q", config.General.VetoWebhook.FailurePolicy)
+	}
+	if opa := config.General.OPA; opa.URL != "" {
+		if err := validateSourceURL("opa url", opa.URL); err != nil {
+			return err
+		}
+		if strings.Trim(opa.Path, "/") == "" {
+			return fmt.Errorf("opa path is required with an opa url")
+		}
+	}
+	switch config.General.OPA.FailurePolicy {
+	case "deny", "allow":
+	default:
+		return fmt.Errorf("unknown opa failure_policy %q", config.General.OPA.FailurePolicy)
+	}
+	if config.General.OPA.Timeout < 0 {
+		return fmt.Errorf("opa timeout must not be negative")
+	}
+	if _, err := time.LoadLocation(config.General.OPA.Timezone); err != nil {
+		return fmt.Errorf("invalid opa timezone %q: %v", config.General.OPA.Timezone, err)
+	}
+	switch signing := config.General.Signing; signing.Backend {
+	case "":
+	case "file":
+		if signing.KeyFile == "" {
+			return fmt.Errorf("signing backend file requires key_file")
+		}
+	case "vault":
+		if signing.Vault.Key == "" {
+			return fmt.Errorf("signing backend vault requires vault key")
+		}
+		if err := validateSourceURL("signing vault address", signing.Vault.Address); err != nil {
+			return err
+		}
+		if signing.Vault.Address == "" {
+			return fmt.Errorf("signing backend vault requires vault address")
+		}
+		if signing.Vault.Timeout < 0 {
+			return fmt.Errorf("signing vault timeout must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown signing backend %q", signing.Backend)
+	}
+	if airGapped := config.General.AirGapped; airGapped.Enabled {
+		for _, entry := range airGapped.AllowedEndpoints {
+			if strings.TrimSpace(entry) == "" || strings.Contains(entry, "/") {
+				return fmt.Errorf("invalid air_gapped allowed endpoint %q, expected a host or host:port", entry)
+			}
+		}
+		endpoints := OutboundEndpoints(config)
+		settings := make([]string, 0, len(endpoints))
+		for setting := range endpoints {
+			settings = append(settings, setting)
+		}
+		sort.Strings(settings)
+		for _, setting := range settings {
+			if !airGapped.Allows(endpoints[setting]) {
+				return fmt.Errorf("air_gapped mode: %s connects to %s, which is not in allowed_endpoints", setting, endpoints[setting])
+			}
+		}
+	}
+	if config.General.VetoWebhook.Timeout < 0 {
+		return fmt.Errorf("veto webhook timeout must not be negative")
+	}
+	if config.General.ActuationRateLimit.UpdatesPerMinute < 0 || config.General.ActuationRateLimit.Burst < 0 {
+		return fmt.Errorf("actuation_rate_limit values must not be negative")
+	}
+	if parity := config.General.ReplicaParity; parity.ConvergenceTimeout < 0 || parity.InitialBackoff < 0 || parity.MaxBackoff < parity.InitialBackoff {
+		return fmt.Errorf("replica_parity durations must not be negative and max_backoff must be at least initial_backoff")
+	}
+	if quarantine := config.General.Quarantine; quarantine.Enabled {
+		if quarantine.Window <= 0 || quarantine.Duration <= 0 || quarantine.MaxDuration < quarantine.Duration {
+			return fmt.Errorf("quarantine window and duration must be positive and max_duration at least duration")
+		}
+		if quarantine.MaxErrorRatio <= 0 || quarantine.MaxErrorRatio > 1 {
+			return fmt.Errorf("quarantine max_error_ratio must be above 0 and at most 1")
+		}
+		if quarantine.MinEvaluations < 1 {
+			return fmt.Errorf("quarantine min_evaluations must be at least 1")
+		}
+	}
+	switch config.Scaling.DeploymentSplit {
+	case "proportional", "weighted":
+	default:
+		return fmt.Errorf("unknown deployment split %q", config.Scaling.DeploymentSplit)
+	}
+	switch config.Scaling.TargetMode {
+	case "factor", "absolute", "concurrency":
+	default:
+		return fmt.Errorf("unknown target mode %q", config.Scaling.TargetMode)
+	}
+	if concurrency := config.Scaling.Concurrency; concurrency.Target <= 0 || concurrency.PanicThreshold <= 1 {
+		return fmt.Errorf("concurrency target must be positive and panic_threshold greater than 1")
+	}
+	if concurrency := config.Scaling.Concurrency; concurrency.PanicWindow <= 0 || concurrency.StableWindow < concurrency.PanicWindow {
+		return fmt.Errorf("concurrency panic_window must be positive and no longer than stable_window")
+	}
+	if config.Scaling.RequestsPerReplica < 0 {
+		return fmt.Errorf("requests_per_replica must not be negative")
+	}
+	if config.Scaling.LLM.TargetTokensPerReplica < 0 || config.Scaling.LLM.TimeToFirstTokenSLO < 0 {
+		return fmt.Errorf("llm targets must not be negative")
+	}
+	if llm := config.Scaling.LLM; (llm.TargetTokensPerReplica > 0 || llm.TimeToFirstTokenSLO > 0) && !config.Metrics.LLM.Enabled {
+		return fmt.Errorf("llm targets require metrics.llm.enabled")
+	}
+	switch config.Scaling.OOM.Action {
+	case "none", "scale_up", "vertical":
+	default:
+		return fmt.Errorf("unknown oom action %q", config.Scaling.OOM.Action)
+	}
+	if config.Scaling.OOM.MinKills < 1 || config.Scaling.OOM.ScaleFactor < 1 {
+		return fmt.Errorf("oom min_kills must be at least 1 and scale_factor at least 1")
+	}
+	if escalation := config.Scaling.SLOEscalation; escalation.Enabled {
+		if escalation.SLOResponseTime < 0 || escalation.SLOErrorRate < 0 {
+			return fmt.Errorf("slo_escalation slo_response_time and slo_error_rate must not be negative")
+		}
+		if escalation.BreachEvaluations < 1 || escalation.RecoveryEvaluations < 1 {
+			return fmt.Errorf("slo_escalation breach_evaluations and recovery_evaluations must be at least 1")
+		}
+		if escalation.StepFactor <= 1 {
+			return fmt.Errorf("slo_escalation step_factor must be above 1")
+		}
+		if escalation.MaxReplicas < 0 {
+			return fmt.Errorf("slo_escalation max_replicas must not be negative")
+		}
+	}
+	if config.Scaling.PodReadiness.Timeout < 0 {
+		return fmt.Errorf("pod_readiness timeout must not be negative")
+	}
+	if config.Scaling.CapacityAlert.Evaluations < 1 {
+		return fmt.Errorf("capacity_alert evaluations must be at least 1")
+	}
+	if crashLoop := config.Metrics.CrashLoop; crashLoop.MinRestarts < 1 || crashLoop.Window < 0 {
+		return fmt.Errorf("crash_loop min_restarts must be at least 1 and window must not be negative")
+	}
+	if config.Metrics.OOMWindow < 0 {
+		return fmt.Errorf("oom_window must not be negative")
+	}
+	switch config.Scaling.BatchContention.Action {
+	case "none", "relax", "prescale":
+	default:
+		return fmt.Errorf("unknown batch contention action %q", config.Scaling.BatchContention.Action)
+	}
+	if config.Scaling.BatchContention.Action != "none" && !config.Metrics.BatchContention.Enabled {
+		return fmt.Errorf("batch_contention action requires metrics.batch_contention.enabled")
+	}
+	if batch := config.Scaling.BatchContention; batch.Relax < 0 || batch.PrescaleFactor < 1 {
+		return fmt.Errorf("batch_contention relax must not be negative and prescale_factor must be at least 1")
+	}
+	if config.Metrics.BatchContention.MinCPU < 0 {
+		return fmt.Errorf("batch_contention min_cpu must not be negative")
+	}
+	switch config.Scaling.ZoneSpread.Action {
+	case "none", "floor", "warn":
+	default:
+		return fmt.Errorf("unknown zone spread action %q", config.Scaling.ZoneSpread.Action)
+	}
+	if config.Scaling.ZoneSpread.Action != "none" && !config.Metrics.ZoneSpread.Enabled {
+		return fmt.Errorf("zone_spread action requires metrics.zone_spread.enabled")
+	}
+	if config.Scaling.ZoneSpread.MinReplicasPerZone < 1 {
+		return fmt.Errorf("zone_spread min_replicas_per_zone must be at least 1")
+	}
+	switch config.Scaling.Preemption.Action {
+	case "none", "compensate", "wait":
+	default:
+		return fmt.Errorf("unknown preemption action %q", config.Scaling.Preemption.Action)
+	}
+	if config.Scaling.Preemption.Action != "none" && !config.Metrics.Preemption.Enabled {
+		return fmt.Errorf("preemption action requires metrics.preemption.enabled")
+	}
+	switch config.Metrics.MeshEjection.Mesh {
+	case "istio", "linkerd":
+	default:
+		return fmt.Errorf("unknown service mesh %q", config.Metrics.MeshEjection.Mesh)
+	}
+	if config.Metrics.MeshEjection.Enabled && config.Metrics.PrometheusURL == "" {
+		return fmt.Errorf("metrics.mesh_ejection requires prometheus_url")
+	}
+	if thermal := config.Metrics.Thermal; thermal.Enabled {
+		if config.Metrics.PrometheusURL == "" {
+			return fmt.Errorf("metrics.thermal requires prometheus_url")
+		}
+		if thermal.MaxTemperature < 0 || thermal.MaxPower < 0 {
+			return fmt.Errorf("metrics.thermal max_temperature and max_power must not be negative")
+		}
+	}
+	if placement := config.Scaling.ThermalPlacement; placement.Enabled {
+		if !config.Metrics.Thermal.Enabled {
+			return fmt.Errorf("scaling.thermal_placement requires metrics.thermal.enabled")
+		}
+		if placement.Weight < 1 || placement.Weight > 100 {
+			return fmt.Errorf("scaling.thermal_placement weight must be between 1 and 100")
+		}
+	}
+	switch config.Scaling.MeshEjection.Action {
+	case "none", "compensate":
+	default:
+		return fmt.Errorf("unknown mesh ejection action %q", config.Scaling.MeshEjection.Action)
+	}
+	if config.Scaling.MeshEjection.Action != "none" && !config.Metrics.MeshEjection.Enabled {
+		return fmt.Errorf("mesh_ejection action requires metrics.mesh_ejection.enabled")
+	}
+	if relaxed := config.Scaling.MeshEjection.RelaxedMaxEjectionPercent; relaxed < 0 || relaxed > 100 {
+		return fmt.Errorf("mesh_ejection relaxed_max_ejection_percent must be between 0 and 100")
+	}
+	if config.Scaling.MeshEjection.RelaxedMaxEjectionPercent > 0 && !config.Metrics.MeshEjection.Enabled {
+		return fmt.Errorf("mesh_ejection relaxed_max_ejection_percent requires metrics.mesh_ejection.enabled")
+	}
+	if throttling := config.Metrics.CPUThrottling; throttling.Threshold < 0 || throttling.Threshold >= 100 || throttling.MaxCorrection < 1 {
+		return fmt.Errorf("cpu_throttling threshold must be between 0 and 100 and max_correction at least 1")
+	}
+	if config.Metrics.CPUThrottling.Enabled && config.Metrics.PrometheusURL == "" {
+		return fmt.Errorf("metrics.cpu_throttling requires prometheus_url")
+	}
+	if retry := config.Metrics.RetryStorm; retry.MinErrorRate < 0 || retry.MinErrorRate >= 100 || retry.MinAmplification <= 1 {
+		return fmt.Errorf("retry_storm min_error_rate must be between 0 and 100 and min_amplification above 1")
+	}
+	if config.Metrics.RetryStorm.RetryMetric != "" && config.Metrics.PrometheusURL == "" {
+		return fmt.Errorf("metrics.retry_storm.retry_metric requires prometheus_url")
+	}
+	if config.Metrics.LLM.Enabled && config.Metrics.PrometheusURL == "" {
+		return fmt.Errorf("metrics.llm requires prometheus_url")
+	}
+	if slo := config.Metrics.LatencySLO; slo.Threshold < 0 || slo.Objective <= 0 || slo.Objective >= 100 {
+		return fmt.Errorf("latency_slo threshold must not be negative and objective must be between 0 and 100")
+	}
+	if config.Metrics.LatencySLO.Enabled && config.Metrics.PrometheusURL == "" {
+		return fmt.Errorf("metrics.latency_slo requires prometheus_url")
+	}
+	if burn := config.Scaling.LatencySLO; burn.BurnRateThreshold < 0 || burn.ScaleFactor < 1 {
+		return fmt.Errorf("latency_slo burn_rate_threshold must not be negative and scale_factor must be at least 1")
+	}
+	if config.Scaling.EvaluationJitter < 0 || config.Scaling.EvaluationJitter >= 1 {
+		return fmt.Errorf("evaluation_jitter must be between 0 and 1")
+	}
+	if config.Scaling.LatencySLO.BurnRateThreshold > 0 && !config.Metrics.LatencySLO.Enabled {
+		return fmt.Errorf("scaling.latency_slo requires metrics.latency_slo.enabled")
+	}
+	switch config.Scaling.StaleMetrics.Action {
+	case "skip", "dampen":
+	default:
+		return fmt.Errorf("unknown stale metrics action %q", config.Scaling.StaleMetrics.Action)
+	}
+	if config.Scaling.StaleMetrics.Dampening < 0 || config.Scaling.StaleMetrics.Dampening > 1 {
+		return fmt.Errorf("stale_metrics dampening must be between 0 and 1")
+	}
+	if config.Scaling.ActionBudget.PerHour < 0 || config.Scaling.ActionBudget.PerDay < 0 {
+		return fmt.Errorf("action_budget limits must not be negative")
+	}
+	if config.Scaling.Approval.Threshold < 0 {
+		return fmt.Errorf("approval threshold must not be negative")
+	}
+	if config.Scaling.Approval.Threshold > 0 && !config.General.RecordRecommendations {
+		return fmt.Errorf("approval requires general.record_recommendations, which holds pending approvals")
+	}
+	if config.Scaling.Prediction.StalenessHalfLife < 0 {
+		return fmt.Errorf("staleness_half_life must not be negative")
+	}
+	for _, feature := range config.Scaling.AIModel.DerivedFeatures {
+		if feature.Name == "" {
+			return fmt.Errorf("derived feature name is required")
+		}
+		if _, err := expr.Parse(feature.Expression); err != nil {
+			return fmt.Errorf("invalid derived feature %q: %w", feature.Name, err)
+		}
+	}
+	for _, date := range config.Scaling.AIModel.Holidays {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("invalid holiday %q, expected YYYY-MM-DD", date)
+		}
+	}
+	for _, window := range config.Scaling.AIModel.MaintenanceWindows {
+		if err := window.Validate(); err != nil {
+			return err
+		}
+		for _, service := range window.Services {
+			if namespace, name, ok := strings.Cut(service, "/"); !ok || namespace == "" || name == "" {
+				return fmt.Errorf("invalid maintenance window service %q, expected namespace/service", service)
+			}
+		}
+	}
+	for _, policy := range config.Scaling.Policies {
+		if _, err := expr.ParseRule(policy.Rule); err != nil {
+			return fmt.Errorf("invalid policy %q: %w", policy.Name, err)
+		}
+	}
+	switch config.Scaling.AIModel.Registry.Backend {
+	case "", "configmap":
+	case "directory":
+		if config.Scaling.AIModel.Registry.Directory == "" {
+			return fmt.Errorf("registry directory is required for the directory backend")
+		}
+	default:
+		return fmt.Errorf("unknown registry backend %q", config.Scaling.AIModel.Registry.Backend)
+	}
+	if config.Scaling.AIModel.CheckpointPath != "" && config.Scaling.AIModel.Registry.Backend != "" {
+		return fmt.Errorf("checkpoint_path can't be used with a model registry, which manages the model versions")
+	}
+	switch config.Scaling.AIModel.DriftDetection.Method {
+	case "psi", "ks":
+	default:
+		return fmt.Errorf("unknown drift detection method %q", config.Scaling.AIModel.DriftDetection.Method)
+	}
+	switch config.Scaling.AIModel.DriftDetection.Action {
+	case "none", "retrain", "fallback":
+	default:
+		return fmt.Errorf("unknown drift detection action %q", config.Scaling.AIModel.DriftDetection.Action)
+	}
+	switch config.Scaling.AIModel.ChangePoint.Action {
+	case "none", "retrain", "reset":
+	default:
+		return fmt.Errorf("unknown change point action %q", config.Scaling.AIModel.ChangePoint.Action)
+	}
+	if config.Scaling.AIModel.ChangePoint.Threshold <= 0 || config.Scaling.AIModel.ChangePoint.Tolerance < 0 {
+		return fmt.Errorf("change point threshold must be positive and tolerance non-negative")
+	}
+	if decay := config.Scaling.AIModel.RevisionWeighting.Decay; decay <= 0 || decay > 1 {
+		return fmt.Errorf("revision weighting decay must be between 0 and 1")
+	}
+	if config.Scaling.AIModel.RevisionWeighting.KeepRevisions < 0 {
+		return fmt.Errorf("revision weighting keep_revisions must not be negative")
+	}
+	for _, flag := range config.Scaling.AIModel.TrainingQuality.Exclude {
+		switch flag {
+		case "incident", "rollout", "collector_outage":
+		default:
+			return fmt.Errorf("unknown training quality flag %q", flag)
+		}
+	}
+	if config.Scaling.AIModel.TrainingQuality.IncidentErrorRate < 0 || config.Scaling.AIModel.TrainingQuality.OutlierThreshold < 0 {
+		return fmt.Errorf("training quality incident_error_rate and outlier_threshold must not be negative")
+	}
+	switch config.Scaling.AIModel.ClassBalance.Method {
+	case "weight", "oversample":
+	default:
+		return fmt.Errorf("unknown class balance method %q", config.Scaling.AIModel.ClassBalance.Method)
+	}
+	if config.Scaling.AIModel.ClassBalance.MaxWeight < 1 {
+		return fmt.Errorf("class balance max_weight must be at least 1")
+	}
+	if err := validateHyperparameters(config.Scaling.AIModel.Hyperparameters); err != nil {
+		return err
+	}
+	if canary := config.Scaling.AIModel.Registry.Canary; canary.Percentage < 0 || canary.Percentage > 100 {
+		return fmt.Errorf("canary percentage must be between 0 and 100")
+	}
+	if config.Scaling.Cost.Enabled && len(config.Scaling.Cost.NodeTypes) == 0 {
+		return fmt.Errorf("cost estimates need at least one entry in node_types")
+	}
+	for name, rates := range config.Scaling.Cost.NodeTypes {
+		if rates.CPUCoreHour < 0 || rates.MemoryGiBHour < 0 || rates.WattsPerCore < 0 || rates.WattsPerGiB < 0 || rates.CarbonIntensity < 0 {
+			return fmt.Errorf("cost rates of node type %q must not be negative", name)
+		}
+	}
+	if config.General.ServiceMonitors.Interval < 0 {
+		return fmt.Errorf("service_monitors interval must not be negative")
+	}
+	if rt := config.General.Runtime; rt.GOMAXPROCS < 0 || rt.MemoryLimitMiB < 0 || rt.MaxConcurrentReconciles < 0 {
+		return fmt.Errorf("runtime gomaxprocs, memory_limit_mib and max_concurrent_reconciles must not be negative")
+	}
+	if config.General.Runtime.GCPercent < -1 {
+		return fmt.Errorf("runtime gc_percent must be -1 or above")
+	}
+	if throttling := config.General.Runtime.SelfThrottling; throttling.Enabled {
+		if throttling.MemoryThreshold <= 0 || throttling.MemoryThreshold > 1 || throttling.CPUThreshold <= 0 || throttling.CPUThreshold > 1 {
+			return fmt.Errorf("self_throttling memory_threshold and cpu_threshold must be above 0 and at most 1")
+		}
+		if throttling.RecoveryMargin < 0 || throttling.RecoveryMargin >= math.Min(throttling.MemoryThreshold, throttling.CPUThreshold) {
+			return fmt.Errorf("self_throttling recovery_margin must be at least 0 and below both thresholds")
+		}
+		if throttling.CollectionSlowdown < 1 {
+			return fmt.Errorf("self_throttling collection_slowdown must be at least 1")
+		}
+		if throttling.CheckInterval < 0 {
+			return fmt.Errorf("self_throttling check_interval must not be negative")
+		}
+	}
+	if err := validateBindAddress("admin_api bind_address", config.General.AdminAPI.BindAddress); err != nil {
+		return err
+	}
+	if tls := config.General.AdminAPI.TLS; (tls.CertFile == "") != (tls.KeyFile == "") {
+		return fmt.Errorf("admin_api tls needs both cert_file and key_file")
+	} else if tls.ClientCAFile != "" && tls.CertFile == "" {
+		return fmt.Errorf("admin_api tls client_ca_file requires cert_file and key_file")
+	}
+	tenants := make(map[string]bool)
+	for _, key := range config.General.AdminAPI.APIKeys {
+		if key.Name == "" || tenants[key.Name] {
+			return fmt.Errorf("admin_api api_keys need unique names, got %q", key.Name)
+		}
+		tenants[key.Name] = true
+		if (key.KeyEnv == "") == (key.KeyFile == "") {
+			return fmt.Errorf("admin_api api key %s needs exactly one of key_env and key_file", key.Name)
+		}
+		switch key.Scope {
+		case "read", "write", "force":
+		default:
+			return fmt.Errorf("unknown scope %q of admin_api api key %s", key.Scope, key.Name)
+		}
+		if key.RequestsPerMinute < 0 || key.Burst < 0 {
+			return fmt.Errorf("admin_api api key %s requests_per_minute and burst must not be negative", key.Name)
+		}
+	}
+	switch config.General.AdminAPI.TLS.MinVersion {
+	case "1.2":
+	case "1.3":
+		// The FIPS module of boringcrypto builds only negotiates TLS 1.2
+		if config.General.FIPSRequired {
+			return fmt.Errorf("admin_api tls min_version 1.3 is not available with fips_required")
+		}
+	default:
+		return fmt.Errorf("unknown admin_api tls min_version %q", config.General.AdminAPI.TLS.MinVersion)
+	}
+	if err := validateBindAddress("runtime pprof_bind_address", config.General.Runtime.PprofBindAddress); err != nil {
+		return err
+	}
+	if experiment := config.Scaling.Experiment; experiment.Name != "" {
+		if _, err := time.Parse(time.RFC3339, experiment.Start); err != nil {
+			return fmt.Errorf("invalid experiment start %q, expected RFC 3339", experiment.Start)
+		}
+		if experiment.Duration < 0 || experiment.Interval <= 0 {
+			return fmt.Errorf("experiment duration must not be negative and interval must be positive")
+		}
+		if experiment.ThresholdPercentage < 0 || experiment.ThresholdPercentage > 100 {
+			return fmt.Errorf("experiment threshold_percentage must be between 0 and 100")
+		}
+		if experiment.SLOResponseTime <= 0 || experiment.SLOErrorRate < 0 {
+			return fmt.Errorf("experiment slo_response_time must be positive and slo_error_rate not negative")
+		}
+	}
+	if report := config.Scaling.SummaryReport; report.Enabled {
+		if report.Interval <= 0 || report.Retain < 1 || report.TopServices < 0 {
+			return fmt.Errorf("summary_report interval must be positive, retain at least 1 and top_services not negative")
+		}
+		if report.SLOResponseTime <= 0 || report.SLOErrorRate < 0 {
+			return fmt.Errorf("summary_report slo_response_time must be positive and slo_error_rate not negative")
+		}
+		if email := report.Email; email.SMTPAddress != "" && (email.From == "" || len(email.To) == 0) {
+			return fmt.Errorf("summary_report email requires from and to")
+		}
+	}
+	if rs := config.Scaling.RightSizing; rs.Interval < 0 || rs.Window < 0 {
+		return fmt.Errorf("right_sizing interval and window must not be negative")
+	}
+	if rs := config.Scaling.RightSizing; rs.MinCoverage < 0 || rs.MinCoverage > 1 {
+		return fmt.Errorf("right_sizing min_coverage must be between 0 and 1")
+	}
+	if config.Scaling.RightSizing.Headroom < 0 {
+		return fmt.Errorf("right_sizing headroom must not be negative")
+	}
+
+	return nil
+}
+
+// validateHyperparameters validates model hyperparameters and the search space
+func validateHyperparameters(hp HyperparameterConfig) error {
+	if hp.HiddenUnits < 1 || hp.HiddenUnits > 256 {
+		return fmt.Errorf("hidden_units must be between 1 and 256")
+	}
+	if hp.Epochs < 1 {
+		return fmt.Errorf("epochs must be at least 1")
+	}
+	if hp.Regularization < 0 {
+		return fmt.Errorf("regularization must not be negative")
+	}
+	if hp.Quantile <= 0 || hp.Quantile >= 1 {
+		return fmt.Errorf("quantile must be between 0 and 1")
+	}
+	if hp.SequenceLength < 1 {
+		return fmt.Errorf("sequence_length must be at least 1")
+	}
+	if hp.TargetConcurrency <= 0 {
+		return fmt.Errorf("target_concurrency must be positive")
+	}
+
+	search := hp.Search
+	switch search.Strategy {
+	case "grid", "random":
+	default:
+		return fmt.Errorf("unknown hyperparameter search strategy %q", search.Strategy)
+	}
+	if search.Trials < 1 {
+		return fmt.Errorf("search trials must be at least 1")
+	}
+	if search.HoldoutFraction <= 0 || search.HoldoutFraction >= 1 {
+		return fmt.Errorf("search holdout_fraction must be between 0 and 1")
+	}
+	for _, lr := range search.LearningRates {
+		if lr <= 0 || lr >= 1 {
+			return fmt.Errorf("search learning_rates must be between 0 and 1")
+		}
+	}
+	for _, reg := range search.Regularization {
+		if reg < 0 {
+			return fmt.Errorf("search regularization values must not be negative")
+		}
+	}
+	for _, units := range search.HiddenUnits {
+		if units < 1 || units > 256 {
+			return fmt.Errorf("search hidden_units must be between 1 and 256")
+		}
+	}
+
+	return nil
+}
+
+// validateSourceURL checks an optional metrics source URL. IPv6 literals
+// must be bracketed, as an unbracketed address cannot be told from its port
+func validateSourceURL(field, raw string) error {
+	if raw == "" {
+		return nil
+	}
+	target, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %v", field, raw, err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return fmt.Errorf("%s %q must use http or https", field, raw)
+	}
+	if target.Hostname() == "" {
+		return fmt.Errorf("%s %q has no host", field, raw)
+	}
+	if strings.Count(target.Host, ":") > 1 && !strings.HasPrefix(target.Host, "[") {
+		return fmt.Errorf("%s %q has an unbracketed IPv6 address, use e.g. http://[fd00::1]:9090", field, raw)
+	}
+	return nil
+}
 
+// validateBindAddress checks an optional listen address such as ":8082",
+// "0.0.0.0:8082" or "[::]:8082"
+func validateBindAddress(field, address string) error {
+	if address == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return fmt.Errorf("invalid %s %q, expected host:port with IPv6 hosts in brackets: %v", field, address, err)
+	}
+	return nil
+}
+
+// validateTrafficCurve checks a simulated traffic curve; empty fields are
+// allowed on service curves, which inherit them from the default
+func validateTrafficCurve(name string, curve TrafficCurveConfig) error {
+	switch curve.Shape {
+	case "", "constant", "sine", "ramp", "step", "spike":
+	default:
+		return fmt.Errorf("unknown traffic curve shape %q for %s", curve.Shape, name)
+	}
+	if curve.Base < 0 || curve.Amplitude < 0 || curve.Period < 0 || curve.Noise < 0 ||
+		curve.ResponseTime < 0 || curve.ErrorRate < 0 || curve.RequestsPerReplica < 0 || curve.MemoryUtilization < 0 {
+		return fmt.Errorf("traffic curve for %s must not have negative values", name)
+	}
+	if curve.ErrorRate > 100 || curve.MemoryUtilization > 100 {
+		return fmt.Errorf("traffic curve percentages for %s must not exceed 100", name)
+	}
 	return nil
 }
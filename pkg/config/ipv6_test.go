@@ -0,0 +1,106 @@
+package config
+
+import "testing"
+
+func TestValidateSourceURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{name: "unset", raw: ""},
+		{name: "hostname", raw: "http://prometheus.monitoring:9090"},
+		{name: "ipv4", raw: "http://10.0.0.1:9090"},
+		{name: "bracketed ipv6 with port", raw: "http://[fd00::1]:9090"},
+		{name: "bracketed ipv6 without port", raw: "https://[fd00::1]"},
+		{name: "unbracketed ipv6", raw: "http://fd00::1:9090", wantErr: true},
+		{name: "no host", raw: "http://:9090", wantErr: true},
+		{name: "other scheme", raw: "tcp://[fd00::1]:9090", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSourceURL("prometheus_url", tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSourceURL(%q) error = %v, want error %v", tt.raw, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBindAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{name: "unset", address: ""},
+		{name: "port only", address: ":8082"},
+		{name: "ipv4", address: "0.0.0.0:8082"},
+		{name: "ipv6 any", address: "[::]:8082"},
+		{name: "ipv6 loopback", address: "[::1]:8082"},
+		{name: "unbracketed ipv6", address: "::1:8082", wantErr: true},
+		{name: "no port", address: "[::1]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBindAddress("admin_api bind_address", tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateBindAddress(%q) error = %v, want error %v", tt.address, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAirGappedAllows(t *testing.T) {
+	cfg := AirGappedConfig{
+		Enabled: true,
+		AllowedEndpoints: []string{
+			"prometheus.monitoring.svc.cluster.local:9090",
+			"[fd00::10]:9090",
+			"[fd00::20]",
+			"fd00::30",
+		},
+	}
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{address: "prometheus.monitoring.svc.cluster.local:9090", want: true},
+		{address: "prometheus.monitoring.svc.cluster.local:9091", want: false},
+		{address: "[fd00::10]:9090", want: true},
+		{address: "[fd00::10]:9091", want: false},
+		{address: "[fd00::20]:443", want: true},
+		{address: "[fd00::30]:80", want: true},
+		{address: "[fd00::40]:9090", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := cfg.Allows(tt.address); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutboundEndpoints(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "hostname with port", url: "http://nginx.ingress:10254", want: "nginx.ingress:10254"},
+		{name: "ipv4 default port", url: "http://10.0.0.1", want: "10.0.0.1:80"},
+		{name: "ipv6 with port", url: "http://[fd00::1]:10254", want: "[fd00::1]:10254"},
+		{name: "ipv6 default http port", url: "http://[fd00::1]", want: "[fd00::1]:80"},
+		{name: "ipv6 default https port", url: "https://[fd00::1]/stats", want: "[fd00::1]:443"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Metrics.NginxMetricsURL = tt.url
+			if got := OutboundEndpoints(cfg)["metrics.nginx_metrics_url"]; got != tt.want {
+				t.Errorf("OutboundEndpoints for %q = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
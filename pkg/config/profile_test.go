@@ -0,0 +1,110 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEdgeProfileDefaults(t *testing.T) {
+	cfg := &Config{}
+	cfg.General.Profile = "edge"
+	setDefaults(cfg)
+
+	if got := cfg.Metrics.CollectionInterval; got != time.Minute {
+		t.Errorf("collection_interval = %s, want 1m", got)
+	}
+	if got := cfg.Metrics.RetentionPeriod; got != 2*time.Hour {
+		t.Errorf("retention_period = %s, want 2h", got)
+	}
+	if got := cfg.Scaling.EvaluationInterval; got != time.Minute {
+		t.Errorf("evaluation_interval = %s, want 1m", got)
+	}
+	if got := cfg.Scaling.AIModel.ModelType; got != "linear" {
+		t.Errorf("model_type = %s, want linear", got)
+	}
+	if got := cfg.Scaling.AIModel.HistoricalWindow; got != 6*time.Hour {
+		t.Errorf("historical_window = %s, want 6h", got)
+	}
+	if got := cfg.Scaling.AIModel.MaxTrainingSamples; got != 1000 {
+		t.Errorf("max_training_samples = %d, want 1000", got)
+	}
+	if got := cfg.General.Runtime.GCPercent; got != 50 {
+		t.Errorf("runtime gc_percent = %d, want 50", got)
+	}
+	if got := cfg.General.Runtime.MemoryLimitMiB; got != 48 {
+		t.Errorf("runtime memory_limit_mib = %d, want 48", got)
+	}
+	if err := validateConfig(cfg); err != nil {
+		t.Errorf("edge defaults don't validate: %v", err)
+	}
+}
+
+func TestEdgeProfileKeepsExplicitSettings(t *testing.T) {
+	cfg := &Config{}
+	cfg.General.Profile = "edge"
+	cfg.Metrics.CollectionInterval = 15 * time.Second
+	cfg.Scaling.AIModel.ModelType = "quantile"
+	cfg.General.Runtime.MemoryLimitMiB = 96
+	setDefaults(cfg)
+
+	if got := cfg.Metrics.CollectionInterval; got != 15*time.Second {
+		t.Errorf("collection_interval = %s, want the configured 15s", got)
+	}
+	if got := cfg.Scaling.AIModel.ModelType; got != "quantile" {
+		t.Errorf("model_type = %s, want the configured quantile", got)
+	}
+	if got := cfg.General.Runtime.MemoryLimitMiB; got != 96 {
+		t.Errorf("runtime memory_limit_mib = %d, want the configured 96", got)
+	}
+}
+
+func TestDefaultProfileUnchanged(t *testing.T) {
+	edge := &Config{}
+	edge.General.Profile = "edge"
+	setDefaults(edge)
+	defaults := DefaultConfig()
+
+	if defaults.Metrics.CollectionInterval == edge.Metrics.CollectionInterval {
+		t.Errorf("collection_interval %s without a profile matches the edge profile", defaults.Metrics.CollectionInterval)
+	}
+	if defaults.General.Runtime.MemoryLimitMiB != 0 {
+		t.Errorf("runtime memory_limit_mib = %d without a profile, want unset", defaults.General.Runtime.MemoryLimitMiB)
+	}
+}
+
+func TestEdgeProfileValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "edge defaults", profile: "edge"},
+		{name: "quantile model", profile: "edge", modify: func(cfg *Config) { cfg.Scaling.AIModel.ModelType = "quantile" }},
+		{name: "littles law model", profile: "edge", modify: func(cfg *Config) { cfg.Scaling.AIModel.ModelType = "littles_law" }},
+		{name: "neural network", profile: "edge", modify: func(cfg *Config) { cfg.Scaling.AIModel.ModelType = "neural_network" }, wantErr: "too heavy for the edge profile"},
+		{name: "gru", profile: "edge", modify: func(cfg *Config) { cfg.Scaling.AIModel.ModelType = "gru" }, wantErr: "too heavy for the edge profile"},
+		{name: "ensemble", profile: "edge", modify: func(cfg *Config) { cfg.Scaling.AIModel.ModelType = "ensemble" }, wantErr: "too heavy for the edge profile"},
+		{name: "hyperparameter search", profile: "edge", modify: func(cfg *Config) { cfg.Scaling.AIModel.Hyperparameters.Search.Enabled = true }, wantErr: "not available with the edge profile"},
+		{name: "neural network without a profile", modify: func(cfg *Config) { cfg.Scaling.AIModel.ModelType = "neural_network" }},
+		{name: "unknown profile", profile: "tiny", wantErr: "unknown profile"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.General.Profile = tt.profile
+			setDefaults(cfg)
+			if tt.modify != nil {
+				tt.modify(cfg)
+			}
+			err := validateConfig(cfg)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("validateConfig error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
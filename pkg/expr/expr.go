@@ -0,0 +1,254 @@
+// Package expr implements the small expression language used by scaling
+// policies and derived features. Expressions operate on float64 values:
+// comparisons and logical operators yield 1 for true and 0 for false, and any
+// non-zero value is treated as true.
+//
+//	error_rate > 5 && request_rate / max(current_replicas, 1) > 50
+//
+// Supported operators, from lowest to highest precedence, are || (or),
+// && (and), == !=, < <= > >=, + -, * /, and unary - and ! (not). The built-in
+// functions are min, max, abs, clamp, sqrt and log.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Expr is a parsed expression
+type Expr struct {
+	source string
+	root   node
+}
+
+// Parse parses an expression
+func Parse(source string) (*Expr, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+
+	return &Expr{source: source, root: root}, nil
+}
+
+// String returns the source of the expression
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression against a set of variables
+func (e *Expr) Eval(vars map[string]float64) (float64, error) {
+	return e.root.eval(vars)
+}
+
+// Variables returns the sorted names of the variables the expression references
+func (e *Expr) Variables() []string {
+	seen := make(map[string]bool)
+	e.root.variables(seen)
+
+	return sortedNames(seen)
+}
+
+// Check reports an error if the expression references a variable that is not known
+func (e *Expr) Check(known map[string]bool) error {
+	for _, name := range e.Variables() {
+		if !known[name] {
+			return fmt.Errorf("unknown variable %q", name)
+		}
+	}
+	return nil
+}
+
+type node interface {
+	eval(vars map[string]float64) (float64, error)
+	variables(seen map[string]bool)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(map[string]float64) (float64, error) { return float64(n), nil }
+func (n numberNode) variables(map[string]bool)                {}
+
+type variableNode string
+
+func (n variableNode) eval(vars map[string]float64) (float64, error) {
+	value, ok := vars[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("unknown variable %q", string(n))
+	}
+	return value, nil
+}
+
+func (n variableNode) variables(seen map[string]bool) { seen[string(n)] = true }
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(vars map[string]float64) (float64, error) {
+	value, err := n.operand.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == "-" {
+		return -value, nil
+	}
+	return boolValue(value == 0), nil
+}
+
+func (n *unaryNode) variables(seen map[string]bool) { n.operand.variables(seen) }
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(vars map[string]float64) (float64, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	// Logical operators short-circuit
+	switch n.op {
+	case "&&":
+		if left == 0 {
+			return 0, nil
+		}
+	case "||":
+		if left != 0 {
+			return 1, nil
+		}
+	}
+
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return boolValue(right != 0), nil
+	case "==":
+		return boolValue(left == right), nil
+	case "!=":
+		return boolValue(left != right), nil
+	case "<":
+		return boolValue(left < right), nil
+	case "<=":
+		return boolValue(left <= right), nil
+	case ">":
+		return boolValue(left > right), nil
+	case ">=":
+		return boolValue(left >= right), nil
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/":
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return left / right, nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", n.op)
+}
+
+func (n *binaryNode) variables(seen map[string]bool) {
+	n.left.variables(seen)
+	n.right.variables(seen)
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n *callNode) eval(vars map[string]float64) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = value
+	}
+	return functions[n.name].call(args)
+}
+
+func (n *callNode) variables(seen map[string]bool) {
+	for _, arg := range n.args {
+		arg.variables(seen)
+	}
+}
+
+// function is a built-in function; maxArgs of -1 means variadic
+type function struct {
+	minArgs int
+	maxArgs int
+	call    func(args []float64) (float64, error)
+}
+
+var functions = map[string]function{
+	"min": {minArgs: 1, maxArgs: -1, call: func(args []float64) (float64, error) {
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = math.Min(result, arg)
+		}
+		return result, nil
+	}},
+	"max": {minArgs: 1, maxArgs: -1, call: func(args []float64) (float64, error) {
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = math.Max(result, arg)
+		}
+		return result, nil
+	}},
+	"abs": {minArgs: 1, maxArgs: 1, call: func(args []float64) (float64, error) {
+		return math.Abs(args[0]), nil
+	}},
+	"clamp": {minArgs: 3, maxArgs: 3, call: func(args []float64) (float64, error) {
+		return math.Max(args[1], math.Min(args[2], args[0])), nil
+	}},
+	"sqrt": {minArgs: 1, maxArgs: 1, call: func(args []float64) (float64, error) {
+		if args[0] < 0 {
+			return 0, fmt.Errorf("sqrt of negative value")
+		}
+		return math.Sqrt(args[0]), nil
+	}},
+	"log": {minArgs: 1, maxArgs: 1, call: func(args []float64) (float64, error) {
+		if args[0] <= 0 {
+			return 0, fmt.Errorf("log of non-positive value")
+		}
+		return math.Log(args[0]), nil
+	}},
+}
+
+func sortedNames(seen map[string]bool) []string {
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
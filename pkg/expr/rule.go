@@ -0,0 +1,99 @@
+package expr
+
+import "fmt"
+
+// Rule is a conditional assignment of the form
+//
+//	if <condition> then <target> = <value>
+//
+// The condition is optional; a rule written as "<target> = <value>" always applies.
+type Rule struct {
+	Source    string
+	Condition *Expr // nil when the rule is unconditional
+	Target    string
+	Value     *Expr
+}
+
+// ParseRule parses a rule
+func ParseRule(source string) (*Rule, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	rule := &Rule{Source: source}
+
+	if tok := p.peek(); tok.kind == tokenIdent && tok.text == "if" {
+		p.next()
+		condition, err := p.parseExpression()
+		if err != nil {
+			return nil, fmt.Errorf("invalid condition: %w", err)
+		}
+		if tok := p.next(); tok.kind != tokenIdent || tok.text != "then" {
+			return nil, fmt.Errorf("expected then at position %d", tok.pos)
+		}
+		rule.Condition = &Expr{source: source, root: condition}
+	}
+
+	target := p.next()
+	if target.kind != tokenIdent {
+		return nil, fmt.Errorf("expected assignment target at position %d", target.pos)
+	}
+	if tok := p.next(); tok.kind != tokenOperator || tok.text != "=" {
+		return nil, fmt.Errorf("expected = at position %d", tok.pos)
+	}
+	rule.Target = target.text
+
+	value, err := p.parseExpression()
+	if err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	rule.Value = &Expr{source: source, root: value}
+
+	return rule, nil
+}
+
+// Variables returns the sorted names of the variables the rule reads
+func (r *Rule) Variables() []string {
+	seen := make(map[string]bool)
+	if r.Condition != nil {
+		r.Condition.root.variables(seen)
+	}
+	r.Value.root.variables(seen)
+
+	return sortedNames(seen)
+}
+
+// Check reports an error if the rule reads a variable that is not known
+func (r *Rule) Check(known map[string]bool) error {
+	for _, name := range r.Variables() {
+		if !known[name] {
+			return fmt.Errorf("unknown variable %q", name)
+		}
+	}
+	return nil
+}
+
+// Apply evaluates the rule. It returns the assigned value and true when the
+// condition holds, or false when the rule does not apply.
+func (r *Rule) Apply(vars map[string]float64) (float64, bool, error) {
+	if r.Condition != nil {
+		holds, err := r.Condition.Eval(vars)
+		if err != nil {
+			return 0, false, err
+		}
+		if holds == 0 {
+			return 0, false, nil
+		}
+	}
+
+	value, err := r.Value.Eval(vars)
+	if err != nil {
+		return 0, false, err
+	}
+	return value, true, nil
+}